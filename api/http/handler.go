@@ -1,27 +1,28 @@
 package http
 
 import (
+	"io"
+	"path/filepath"
+	"strings"
+
 	"github.com/gin-gonic/gin"
 
 	"github.com/gavin/airport-pickup/internal/app/dto"
 )
 
-type CreatePassengerReq struct {
-	Name string `json:"name"`
-}
-
 type CreateDriverReq struct {
-	Name   string  `json:"name"`
-	Rating float64 `json:"rating"`
+	Name string `json:"name"`
+	// 评分由乘客评价计算，不接受自行填写；保留字段以便明确拒绝
+	Rating *float64 `json:"rating"`
 }
 
 func (h *Handler) createPassenger(c *gin.Context) {
-	var in CreatePassengerReq
+	var in dto.CreatePassengerInput
 	if err := c.ShouldBindJSON(&in); err != nil {
 		c.JSON(400, gin.H{"error": err.Error()})
 		return
 	}
-	id, err := h.orderApp.CreatePassenger(in.Name)
+	id, err := h.orderApp.CreatePassenger(in)
 	if err != nil {
 		c.JSON(400, gin.H{"error": err.Error()})
 		return
@@ -35,7 +36,11 @@ func (h *Handler) createDriver(c *gin.Context) {
 		c.JSON(400, gin.H{"error": err.Error()})
 		return
 	}
-	id, err := h.orderApp.CreateDriver(in.Name, in.Rating)
+	if in.Rating != nil {
+		c.JSON(400, gin.H{"error": "rating is computed from passenger ratings and cannot be set"})
+		return
+	}
+	id, err := h.orderApp.CreateDriver(in.Name)
 	if err != nil {
 		c.JSON(400, gin.H{"error": err.Error()})
 		return
@@ -57,6 +62,19 @@ func (h *Handler) createPickupRequest(c *gin.Context) {
 	c.JSON(200, gin.H{"id": id})
 }
 
+func (h *Handler) cancelPickupRequest(c *gin.Context) {
+	passengerID := c.Query("passenger_id")
+	if passengerID == "" {
+		c.JSON(400, gin.H{"error": "missing passenger_id"})
+		return
+	}
+	if err := h.orderApp.CancelPickupRequest(c.Param("id"), passengerID); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(204)
+}
+
 func (h *Handler) createDriverOffer(c *gin.Context) {
 	var in dto.CreateDriverOfferInput
 	if err := c.ShouldBindJSON(&in); err != nil {
@@ -80,15 +98,553 @@ func (h *Handler) listBookings(c *gin.Context) {
 	c.JSON(200, list)
 }
 
+func (h *Handler) rateDriver(c *gin.Context) {
+	var in dto.RateDriverInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	in.BookingID = c.Param("id")
+	res, err := h.orderApp.RateDriver(in)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, res)
+}
+
+func (h *Handler) ratePassenger(c *gin.Context) {
+	var in dto.RatePassengerInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	in.BookingID = c.Param("id")
+	res, err := h.orderApp.RatePassenger(in)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, res)
+}
+
+func (h *Handler) createQuote(c *gin.Context) {
+	var in dto.CreateQuoteInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	res, err := h.orderApp.CreateQuote(in)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, res)
+}
+
+func (h *Handler) getSurge(c *gin.Context) {
+	res, err := h.orderApp.GetSurge(c.Query("airport_code"), c.Query("vehicle_type"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, res)
+}
+
 func (h *Handler) completeBooking(c *gin.Context) {
+	var in dto.CompleteBookingInput
+	// 行程信息在可选的请求体中上报
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&in); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if id := c.Query("id"); id != "" {
+		in.ID = id
+	}
+	if in.ID == "" {
+		c.JSON(400, gin.H{"error": "missing id"})
+		return
+	}
+	if err := h.orderApp.CompleteBooking(in); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(204)
+}
+
+func (h *Handler) cancelBooking(c *gin.Context) {
 	id := c.Query("id")
 	if id == "" {
 		c.JSON(400, gin.H{"error": "missing id"})
 		return
 	}
-	if err := h.orderApp.CompleteBooking(id); err != nil {
+	reason := c.Query("reason")
+	if reason == "" {
+		reason = "passenger_cancelled"
+	}
+	if err := h.orderApp.CancelBooking(id, reason); err != nil {
 		c.JSON(400, gin.H{"error": err.Error()})
 		return
 	}
 	c.Status(204)
 }
+
+func (h *Handler) refundBooking(c *gin.Context) {
+	var in dto.RefundBookingInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	id, err := h.settlementApp.RefundBooking(in)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"id": id})
+}
+
+func (h *Handler) listAccountBalances(c *gin.Context) {
+	list, err := h.settlementApp.ListAccountBalances(c.Query("account"), c.Query("type"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, list)
+}
+
+func (h *Handler) checkLedger(c *gin.Context) {
+	res, err := h.settlementApp.CheckLedger()
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, res)
+}
+
+func (h *Handler) revenueReport(c *gin.Context) {
+	res, err := h.settlementApp.RevenueReport()
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, res)
+}
+
+func (h *Handler) revenueAnalytics(c *gin.Context) {
+	q := dto.RevenueAnalyticsQuery{
+		Granularity: c.Query("granularity"),
+		From:        c.Query("from"),
+		To:          c.Query("to"),
+		AirportCode: c.Query("airport"),
+		VehicleType: c.Query("vehicle_type"),
+		Currency:    c.Query("currency"),
+	}
+	for _, d := range strings.Split(c.Query("group_by"), ",") {
+		if d = strings.TrimSpace(d); d != "" {
+			q.GroupBy = append(q.GroupBy, d)
+		}
+	}
+	res, err := h.analyticsApp.RevenueAnalytics(q)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, res)
+}
+
+func (h *Handler) runPayouts(c *gin.Context) {
+	res, err := h.payoutApp.RunPayouts()
+	if err != nil {
+		// 部分打款失败时仍返回本轮结果
+		c.JSON(500, gin.H{"error": err.Error(), "result": res})
+		return
+	}
+	c.JSON(200, res)
+}
+
+func (h *Handler) listPayouts(c *gin.Context) {
+	list, err := h.payoutApp.ListPayouts(c.Query("driver_id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, list)
+}
+
+func (h *Handler) payoutStatement(c *gin.Context) {
+	filename, content, err := h.payoutApp.PayoutStatement(c.Param("id"))
+	if err != nil {
+		c.JSON(404, gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Data(200, "text/csv; charset=utf-8", content)
+}
+
+func (h *Handler) bookingReceipt(c *gin.Context) {
+	h.sendDocument(c, h.invoiceApp.Receipt)
+}
+
+func (h *Handler) invoiceDocument(c *gin.Context) {
+	h.sendDocument(c, h.invoiceApp.InvoiceDocument)
+}
+
+// sendDocument 按 format（html 默认，或 pdf）渲染单据；HTML 在浏览器中直接打开，PDF 作为附件下载。
+func (h *Handler) sendDocument(c *gin.Context, load func(id, format string) (string, []byte, error)) {
+	format := c.DefaultQuery("format", "html")
+	contentType, disposition := "text/html; charset=utf-8", "inline"
+	switch format {
+	case "html":
+	case "pdf":
+		contentType, disposition = "application/pdf", "attachment"
+	default:
+		c.JSON(400, gin.H{"error": "format must be html or pdf"})
+		return
+	}
+	filename, content, err := load(c.Param("id"), format)
+	if err != nil {
+		c.JSON(404, gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Content-Disposition", disposition+`; filename="`+filename+`"`)
+	c.Data(200, contentType, content)
+}
+
+func (h *Handler) generateInvoices(c *gin.Context) {
+	var in dto.GenerateInvoicesInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	list, err := h.invoiceApp.GenerateCorporateInvoices(in)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, list)
+}
+
+func (h *Handler) listInvoices(c *gin.Context) {
+	list, err := h.invoiceApp.ListCorporateInvoices(c.Query("corporate_account"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, list)
+}
+
+// maxStatementBytes 对账单文件大小上限
+const maxStatementBytes = 32 << 20
+
+// importStatement 接收原始请求体或 multipart 表单字段 file；format 未指定时按文件扩展名判断。
+func (h *Handler) importStatement(c *gin.Context) {
+	in := dto.ImportStatementInput{Source: c.Query("source"), Format: c.Query("format"), From: c.Query("from"), To: c.Query("to")}
+	var r io.Reader = c.Request.Body
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		fh, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		f, err := fh.Open()
+		if err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		defer f.Close()
+		r = f
+		if in.Source == "" {
+			in.Source = fh.Filename
+		}
+	}
+	if in.Format == "" {
+		in.Format = strings.TrimPrefix(strings.ToLower(filepath.Ext(in.Source)), ".")
+	}
+	content, err := io.ReadAll(io.LimitReader(r, maxStatementBytes+1))
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	if len(content) > maxStatementBytes {
+		c.JSON(400, gin.H{"error": "statement too large"})
+		return
+	}
+	in.Content = content
+	res, err := h.reconApp.ImportStatement(in)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, res)
+}
+
+func (h *Handler) listReconciliations(c *gin.Context) {
+	list, err := h.reconApp.ListReports()
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, list)
+}
+
+func (h *Handler) getReconciliation(c *gin.Context) {
+	res, err := h.reconApp.GetReport(c.Param("id"), c.Query("status"))
+	if err != nil {
+		c.JSON(404, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, res)
+}
+
+func (h *Handler) resolveReconciliationException(c *gin.Context) {
+	var in dto.ResolveExceptionInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	res, err := h.reconApp.ResolveException(c.Param("id"), c.Param("exception_id"), in)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, res)
+}
+
+func (h *Handler) createPromotion(c *gin.Context) {
+	var in dto.CreatePromotionInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	code, err := h.promotionApp.CreatePromotion(in)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"code": code})
+}
+
+func (h *Handler) listPromotions(c *gin.Context) {
+	list, err := h.promotionApp.ListPromotions()
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, list)
+}
+
+func (h *Handler) getPromotion(c *gin.Context) {
+	res, err := h.promotionApp.GetPromotion(c.Param("code"))
+	if err != nil {
+		c.JSON(404, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, res)
+}
+
+// maxDocumentBytes 证件文件大小上限，与领域层一致
+const maxDocumentBytes = 10 << 20
+
+func (h *Handler) uploadDriverDocument(c *gin.Context) {
+	fh, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	if fh.Size > maxDocumentBytes {
+		c.JSON(400, gin.H{"error": "document too large"})
+		return
+	}
+	f, err := fh.Open()
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	defer f.Close()
+	content, err := io.ReadAll(io.LimitReader(f, maxDocumentBytes+1))
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	in := dto.UploadDriverDocumentInput{
+		DriverID:  c.Param("id"),
+		Type:      c.PostForm("type"),
+		ExpiresAt: c.PostForm("expires_at"),
+		FileName:  fh.Filename,
+		Content:   content,
+	}
+	res, err := h.onboardingApp.UploadDocument(in)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, res)
+}
+
+func (h *Handler) getDriverOnboarding(c *gin.Context) {
+	res, err := h.onboardingApp.GetOnboarding(c.Param("id"))
+	if err != nil {
+		c.JSON(404, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, res)
+}
+
+func (h *Handler) updateDriverStatus(c *gin.Context) {
+	var in dto.UpdateDriverStatusInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	res, err := h.onboardingApp.UpdateDriverStatus(c.Param("id"), in)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, res)
+}
+
+func (h *Handler) listDocumentReviews(c *gin.Context) {
+	list, err := h.onboardingApp.ReviewQueue()
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, list)
+}
+
+func (h *Handler) reviewDriverDocument(c *gin.Context) {
+	var in dto.ReviewDocumentInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	res, err := h.onboardingApp.ReviewDocument(c.Param("id"), in)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, res)
+}
+
+func (h *Handler) driverDocumentFile(c *gin.Context) {
+	filename, contentType, content, err := h.onboardingApp.DocumentFile(c.Param("id"))
+	if err != nil {
+		c.JSON(404, gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Content-Disposition", `inline; filename="`+filename+`"`)
+	c.Data(200, contentType, content)
+}
+
+func (h *Handler) registerVehicle(c *gin.Context) {
+	var in dto.RegisterVehicleInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	res, err := h.onboardingApp.RegisterVehicle(c.Param("id"), in)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, res)
+}
+
+func (h *Handler) listVehicles(c *gin.Context) {
+	list, err := h.onboardingApp.ListVehicles(c.Param("id"))
+	if err != nil {
+		c.JSON(404, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, list)
+}
+
+func (h *Handler) listVehicleReviews(c *gin.Context) {
+	list, err := h.onboardingApp.VehicleQueue()
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, list)
+}
+
+func (h *Handler) verifyVehicle(c *gin.Context) {
+	var in dto.VerifyVehicleInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	res, err := h.onboardingApp.VerifyVehicle(c.Param("id"), in)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, res)
+}
+
+func (h *Handler) getPassenger(c *gin.Context) {
+	res, err := h.passengerApp.GetPassenger(c.Param("id"))
+	if err != nil {
+		c.JSON(404, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, res)
+}
+
+func (h *Handler) updatePassenger(c *gin.Context) {
+	var in dto.UpdatePassengerInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	res, err := h.passengerApp.UpdatePassenger(c.Param("id"), in)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, res)
+}
+
+func (h *Handler) addPaymentMethod(c *gin.Context) {
+	var in dto.AddPaymentMethodInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	res, err := h.passengerApp.AddPaymentMethod(c.Param("id"), in)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, res)
+}
+
+func (h *Handler) listPaymentMethods(c *gin.Context) {
+	list, err := h.passengerApp.ListPaymentMethods(c.Param("id"))
+	if err != nil {
+		c.JSON(404, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, list)
+}
+
+func (h *Handler) setDefaultPaymentMethod(c *gin.Context) {
+	res, err := h.passengerApp.SetDefaultPaymentMethod(c.Param("id"), c.Param("method_id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, res)
+}
+
+func (h *Handler) removePaymentMethod(c *gin.Context) {
+	res, err := h.passengerApp.RemovePaymentMethod(c.Param("id"), c.Param("method_id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, res)
+}
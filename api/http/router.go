@@ -8,11 +8,11 @@ import (
 )
 
 // NewRouter wires all HTTP routes and returns an http.Handler (gin.Engine).
-func NewRouter(orderApp OrderApp, settlementApp SettlementApp) http.Handler {
+func NewRouter(orderApp OrderApp, settlementApp SettlementApp, payoutApp PayoutApp, invoiceApp InvoiceApp, reconApp ReconciliationApp, analyticsApp AnalyticsApp, promotionApp PromotionApp, onboardingApp DriverOnboardingApp, passengerApp PassengerApp) http.Handler {
 	r := gin.New()
 	r.Use(pkghttp.CORS(), pkghttp.Logger(), pkghttp.Recovery())
 
-	h := &Handler{orderApp: orderApp, settlementApp: settlementApp}
+	h := &Handler{orderApp: orderApp, settlementApp: settlementApp, payoutApp: payoutApp, invoiceApp: invoiceApp, reconApp: reconApp, analyticsApp: analyticsApp, promotionApp: promotionApp, onboardingApp: onboardingApp, passengerApp: passengerApp}
 
 	r.GET("/healthz", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
 
@@ -20,11 +20,79 @@ func NewRouter(orderApp OrderApp, settlementApp SettlementApp) http.Handler {
 	r.POST("/passengers", h.createPassenger)
 	r.POST("/drivers", h.createDriver)
 	r.POST("/pickup_requests", h.createPickupRequest)
+	r.POST("/pickup_requests/:id/cancel", h.cancelPickupRequest) // 乘客撤回未撮合的请求（query passenger_id）
 	r.POST("/driver_offers", h.createDriverOffer)
 
-	// bookings: GET list, POST complete (query id)
+	// passenger profiles: GET profile with saved payment methods, PUT update name and contact details;
+	// payment methods: POST save a card (tokenized by the vault), GET list, POST make default, DELETE remove
+	r.GET("/passengers/:id", h.getPassenger)
+	r.PUT("/passengers/:id", h.updatePassenger)
+	r.POST("/passengers/:id/payment_methods", h.addPaymentMethod)
+	r.GET("/passengers/:id/payment_methods", h.listPaymentMethods)
+	r.POST("/passengers/:id/payment_methods/:method_id/default", h.setDefaultPaymentMethod)
+	r.DELETE("/passengers/:id/payment_methods/:method_id", h.removePaymentMethod)
+
+	// driver onboarding: POST upload a document (multipart field "file", form type and expires_at), GET status, documents and
+	// missing documents, POST change account status (active, suspended, offboarded)
+	r.POST("/drivers/:id/documents", h.uploadDriverDocument)
+	r.GET("/drivers/:id/documents", h.getDriverOnboarding)
+	r.POST("/drivers/:id/status", h.updateDriverStatus)
+	// document reviews: GET admin review queue, POST approve or reject, GET the uploaded file
+	r.GET("/document_reviews", h.listDocumentReviews)
+	r.POST("/document_reviews/:id", h.reviewDriverDocument)
+	r.GET("/driver_documents/:id/file", h.driverDocumentFile)
+	// vehicles: POST register a driver's vehicle, GET a driver's vehicles; vehicle reviews: GET verification queue, POST approve or reject
+	r.POST("/drivers/:id/vehicles", h.registerVehicle)
+	r.GET("/drivers/:id/vehicles", h.listVehicles)
+	r.GET("/vehicle_reviews", h.listVehicleReviews)
+	r.POST("/vehicle_reviews/:id", h.verifyVehicle)
+
+	// bookings: GET list, POST complete (query id, optional trip body), POST cancel (query id, reason), GET receipt (query format),
+	// POST rating (passenger rates the driver of a completed booking), POST passenger_rating (driver rates the passenger, or reports a no-show)
 	r.GET("/bookings", h.listBookings)
 	r.POST("/bookings", h.completeBooking)
+	r.POST("/bookings/cancel", h.cancelBooking)
+	r.GET("/bookings/:id/receipt", h.bookingReceipt)
+	r.POST("/bookings/:id/rating", h.rateDriver)
+	r.POST("/bookings/:id/passenger_rating", h.ratePassenger)
+
+	// quotes: POST fare quote from the live order book, GET current surge multiplier and suggested max_price_per_km (query airport_code, vehicle_type)
+	r.POST("/quotes", h.createQuote)
+	r.GET("/quotes/surge", h.getSurge)
+
+	// refunds: POST full or partial refund of a settled booking
+	r.POST("/refunds", h.refundBooking)
+
+	// ledger: GET balances (query account or type), GET invariant check
+	r.GET("/ledger/balances", h.listAccountBalances)
+	r.GET("/ledger/check", h.checkLedger)
+
+	// reports: GET platform revenue converted into the reporting currency
+	r.GET("/reports/revenue", h.revenueReport)
+	// reports: GET GBV, revenue, take rate and trips (query granularity, group_by, from, to, airport, vehicle_type, currency)
+	r.GET("/reports/analytics", h.revenueAnalytics)
+
+	// payouts: POST run now, GET list (query driver_id), GET CSV statement
+	r.POST("/payouts/run", h.runPayouts)
+	r.GET("/payouts", h.listPayouts)
+	r.GET("/payouts/:id/statement", h.payoutStatement)
+
+	// invoices: POST issue a corporate client's monthly invoices, GET list (query corporate_account), GET document (query format)
+	r.POST("/invoices", h.generateInvoices)
+	r.GET("/invoices", h.listInvoices)
+	r.GET("/invoices/:id", h.invoiceDocument)
+
+	// reconciliations: POST import a wallet provider statement (CSV or JSON body, or multipart field "file"), GET list,
+	// GET report with exceptions (query status), POST resolve an exception
+	r.POST("/reconciliations", h.importStatement)
+	r.GET("/reconciliations", h.listReconciliations)
+	r.GET("/reconciliations/:id", h.getReconciliation)
+	r.POST("/reconciliations/:id/exceptions/:exception_id/resolve", h.resolveReconciliationException)
+
+	// promotions: POST create a promo code campaign, GET list, GET one with redemption totals
+	r.POST("/promotions", h.createPromotion)
+	r.GET("/promotions", h.listPromotions)
+	r.GET("/promotions/:code", h.getPromotion)
 
 	return r
 }
@@ -4,21 +4,97 @@ import "github.com/gavin/airport-pickup/internal/app/dto"
 
 // OrderApp is the application service contract the HTTP layer depends on.
 type OrderApp interface {
-	CreatePassenger(name string) (string, error)
-	CreateDriver(name string, rating float64) (string, error)
+	CreatePassenger(in dto.CreatePassengerInput) (string, error)
+	CreateDriver(name string) (string, error)
 	CreatePickupRequest(in dto.CreatePickupRequestInput) (string, error)
+	CancelPickupRequest(id, passengerID string) error
 	CreateDriverOffer(in dto.CreateDriverOfferInput) (string, error)
 	ListBookings() ([]dto.BookingDTO, error)
-	CompleteBooking(id string) error
+	CompleteBooking(in dto.CompleteBookingInput) error
+	CancelBooking(id, reason string) error
+	RateDriver(in dto.RateDriverInput) (dto.DriverRatingDTO, error)
+	RatePassenger(in dto.RatePassengerInput) (dto.PassengerRatingDTO, error)
+	GetSurge(airportCode, vehicleType string) (dto.SurgeDTO, error)
+	CreateQuote(in dto.CreateQuoteInput) (dto.QuoteDTO, error)
 }
 
-// SettlementApp is reserved for future HTTP endpoints (e.g., manual payment trigger).
+// SettlementApp is the settlement contract the HTTP layer depends on.
 type SettlementApp interface {
 	TriggerPayment(bookingID string) error
+	RefundBooking(in dto.RefundBookingInput) (string, error)
+	ListAccountBalances(account, accountType string) ([]dto.AccountBalanceDTO, error)
+	CheckLedger() (dto.LedgerCheckDTO, error)
+	RevenueReport() (dto.RevenueReportDTO, error)
+}
+
+// PayoutApp is the driver payout contract the HTTP layer depends on.
+type PayoutApp interface {
+	RunPayouts() (dto.PayoutRunDTO, error)
+	ListPayouts(driverID string) ([]dto.PayoutDTO, error)
+	PayoutStatement(id string) (filename string, content []byte, err error)
+}
+
+// InvoiceApp is the receipt and invoice contract the HTTP layer depends on.
+type InvoiceApp interface {
+	Receipt(bookingID, format string) (filename string, content []byte, err error)
+	InvoiceDocument(id, format string) (filename string, content []byte, err error)
+	GenerateCorporateInvoices(in dto.GenerateInvoicesInput) ([]dto.InvoiceDTO, error)
+	ListCorporateInvoices(corporateAccount string) ([]dto.InvoiceDTO, error)
+}
+
+// ReconciliationApp is the payment reconciliation contract the HTTP layer depends on.
+type ReconciliationApp interface {
+	ImportStatement(in dto.ImportStatementInput) (dto.ReconciliationReportDTO, error)
+	ListReports() ([]dto.ReconciliationReportDTO, error)
+	GetReport(id, status string) (dto.ReconciliationReportDTO, error)
+	ResolveException(reportID, exceptionID string, in dto.ResolveExceptionInput) (dto.ReconciliationReportDTO, error)
+}
+
+// AnalyticsApp is the revenue analytics contract the HTTP layer depends on.
+type AnalyticsApp interface {
+	RevenueAnalytics(q dto.RevenueAnalyticsQuery) (dto.RevenueAnalyticsDTO, error)
+}
+
+// PromotionApp is the promo code campaign contract the HTTP layer depends on.
+type PromotionApp interface {
+	CreatePromotion(in dto.CreatePromotionInput) (string, error)
+	ListPromotions() ([]dto.PromotionDTO, error)
+	GetPromotion(code string) (dto.PromotionDTO, error)
+}
+
+// DriverOnboardingApp is the driver document and vehicle verification and account status contract the HTTP layer depends on.
+type DriverOnboardingApp interface {
+	UploadDocument(in dto.UploadDriverDocumentInput) (dto.DriverDocumentDTO, error)
+	GetOnboarding(driverID string) (dto.DriverOnboardingDTO, error)
+	ReviewQueue() ([]dto.DriverDocumentDTO, error)
+	ReviewDocument(id string, in dto.ReviewDocumentInput) (dto.DriverDocumentDTO, error)
+	DocumentFile(id string) (filename, contentType string, content []byte, err error)
+	UpdateDriverStatus(driverID string, in dto.UpdateDriverStatusInput) (dto.DriverOnboardingDTO, error)
+	RegisterVehicle(driverID string, in dto.RegisterVehicleInput) (dto.VehicleDTO, error)
+	ListVehicles(driverID string) ([]dto.VehicleDTO, error)
+	VehicleQueue() ([]dto.VehicleDTO, error)
+	VerifyVehicle(id string, in dto.VerifyVehicleInput) (dto.VehicleDTO, error)
+}
+
+// PassengerApp is the passenger profile and saved payment method contract the HTTP layer depends on.
+type PassengerApp interface {
+	GetPassenger(id string) (dto.PassengerDTO, error)
+	UpdatePassenger(id string, in dto.UpdatePassengerInput) (dto.PassengerDTO, error)
+	AddPaymentMethod(passengerID string, in dto.AddPaymentMethodInput) (dto.PaymentMethodDTO, error)
+	ListPaymentMethods(passengerID string) ([]dto.PaymentMethodDTO, error)
+	SetDefaultPaymentMethod(passengerID, methodID string) (dto.PassengerDTO, error)
+	RemovePaymentMethod(passengerID, methodID string) (dto.PassengerDTO, error)
 }
 
 // Handler groups HTTP handlers and holds references to app services.
 type Handler struct {
 	orderApp      OrderApp
 	settlementApp SettlementApp
+	payoutApp     PayoutApp
+	invoiceApp    InvoiceApp
+	reconApp      ReconciliationApp
+	analyticsApp  AnalyticsApp
+	promotionApp  PromotionApp
+	onboardingApp DriverOnboardingApp
+	passengerApp  PassengerApp
 }
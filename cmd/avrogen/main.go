@@ -0,0 +1,178 @@
+// Command avrogen 根据 schema 注册表中各主题的最新版本生成 Go 类型及其与 Avro 通用值的转换方法。
+//
+//	go run ./cmd/avrogen -dir schemas/events -pkg avroevents -out pkg/eventbus/avroevents/events_gen.go
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/gavin/airport-pickup/pkg/avro"
+)
+
+func main() {
+	dir := flag.String("dir", "schemas/events", "schema registry directory")
+	pkg := flag.String("pkg", "avroevents", "generated package name")
+	out := flag.String("out", "pkg/eventbus/avroevents/events_gen.go", "output file")
+	flag.Parse()
+
+	reg, err := avro.LoadRegistry(os.DirFS(*dir))
+	if err != nil {
+		log.Fatalf("load registry failed: %v", err)
+	}
+	src, err := generate(reg, *pkg)
+	if err != nil {
+		log.Fatalf("generate failed: %v", err)
+	}
+	if err := os.WriteFile(*out, src, 0o644); err != nil {
+		log.Fatalf("write %s failed: %v", *out, err)
+	}
+}
+
+func generate(reg *avro.Registry, pkg string) ([]byte, error) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by avrogen. DO NOT EDIT.\n\npackage %s\n\n", pkg)
+	subjects := reg.Subjects()
+	b.WriteString("import (\n\t\"fmt\"\n")
+	if usesTime(reg, subjects) {
+		b.WriteString("\t\"time\"\n")
+	}
+	b.WriteString(")\n\n")
+
+	b.WriteString("// New 按主题名返回空记录，未知主题返回 nil。\nfunc New(subject string) Record {\n\tswitch subject {\n")
+	for _, s := range subjects {
+		fmt.Fprintf(&b, "\tcase %q:\n\t\treturn &%s{}\n", s, s)
+	}
+	b.WriteString("\t}\n\treturn nil\n}\n")
+
+	for _, s := range subjects {
+		v, _ := reg.Latest(s)
+		if err := genRecord(&b, v); err != nil {
+			return nil, err
+		}
+	}
+	return format.Source(b.Bytes())
+}
+
+func usesTime(reg *avro.Registry, subjects []string) bool {
+	for _, s := range subjects {
+		v, _ := reg.Latest(s)
+		for _, f := range v.Schema.Fields {
+			if t, _ := goType(f.Type); strings.HasSuffix(t, "time.Time") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func genRecord(b *bytes.Buffer, v *avro.SchemaVersion) error {
+	s := v.Schema
+	name := s.Name
+	fmt.Fprintf(b, "\n// %s 由 schema %s 生成。\n", name, v.ID())
+	if s.Doc != "" {
+		fmt.Fprintf(b, "// %s\n", s.Doc)
+	}
+	fmt.Fprintf(b, "type %s struct {\n", name)
+	for _, f := range s.Fields {
+		t, err := goType(f.Type)
+		if err != nil {
+			return fmt.Errorf("%s.%s: %w", name, f.Name, err)
+		}
+		if f.Doc != "" {
+			fmt.Fprintf(b, "\t// %s\n", f.Doc)
+		}
+		fmt.Fprintf(b, "\t%s %s `avro:%q`\n", goName(f.Name), t, f.Name)
+	}
+	b.WriteString("}\n\n")
+
+	fmt.Fprintf(b, "// SchemaID 返回生成该类型所用的 schema 版本。\nfunc (*%s) SchemaID() string { return %q }\n\n", name, v.ID())
+
+	fmt.Fprintf(b, "// ToAvro 转换为 Avro 通用值。\nfunc (r *%s) ToAvro() map[string]any {\n\treturn map[string]any{\n", name)
+	for _, f := range s.Fields {
+		fmt.Fprintf(b, "\t\t%q: %s,\n", f.Name, toAvroExpr(f.Type, "r."+goName(f.Name)))
+	}
+	b.WriteString("\t}\n}\n\n")
+
+	fmt.Fprintf(b, "// FromAvro 从按本 schema 解析后的 Avro 通用值填充字段。\nfunc (r *%s) FromAvro(m map[string]any) error {\n", name)
+	for _, f := range s.Fields {
+		t, _ := goType(f.Type)
+		field := goName(f.Name)
+		if opt := optional(f.Type); opt != nil {
+			bt, _ := goType(opt)
+			fmt.Fprintf(b, "\tif v, ok := m[%q]; ok && v != nil {\n\t\tx, ok := v.(%s)\n\t\tif !ok {\n\t\t\treturn fmt.Errorf(\"%s.%s: unexpected type %%T\", v)\n\t\t}\n\t\tr.%s = &x\n\t}\n", f.Name, bt, name, f.Name, field)
+			continue
+		}
+		fmt.Fprintf(b, "\tif v, ok := m[%q].(%s); ok {\n\t\tr.%s = v\n\t} else {\n\t\treturn fmt.Errorf(\"%s.%s: unexpected type %%T\", m[%q])\n\t}\n", f.Name, t, field, name, f.Name, f.Name)
+	}
+	b.WriteString("\treturn nil\n}\n")
+	return nil
+}
+
+// optional 返回 ["null", T] union 的非空分支。
+func optional(s *avro.Schema) *avro.Schema {
+	if s.Type != avro.Union || len(s.Branches) != 2 {
+		return nil
+	}
+	if s.Branches[0].Type == avro.Null {
+		return s.Branches[1]
+	}
+	if s.Branches[1].Type == avro.Null {
+		return s.Branches[0]
+	}
+	return nil
+}
+
+func toAvroExpr(s *avro.Schema, expr string) string {
+	if optional(s) != nil {
+		return fmt.Sprintf("optional(%s)", expr)
+	}
+	return expr
+}
+
+func goType(s *avro.Schema) (string, error) {
+	if opt := optional(s); opt != nil {
+		t, err := goType(opt)
+		return "*" + t, err
+	}
+	switch s.Type {
+	case avro.Boolean:
+		return "bool", nil
+	case avro.Int:
+		return "int32", nil
+	case avro.Long:
+		if s.LogicalType == avro.LogicalTimestampMillis {
+			return "time.Time", nil
+		}
+		return "int64", nil
+	case avro.Float:
+		return "float32", nil
+	case avro.Double:
+		return "float64", nil
+	case avro.String:
+		return "string", nil
+	case avro.Bytes:
+		return "[]byte", nil
+	}
+	return "", fmt.Errorf("unsupported field type %s", s.Type)
+}
+
+// goName 将 snake_case 转换为导出的 Go 标识符，id 等缩写保持大写。
+func goName(s string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(s, "_") {
+		switch part {
+		case "":
+		case "id", "url", "api":
+			b.WriteString(strings.ToUpper(part))
+		default:
+			b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,94 @@
+// Command dlq 查看、重放或丢弃死信 topic 中的事件。
+//
+//	go run ./cmd/dlq -config config/dev.yaml list -limit 20
+//	go run ./cmd/dlq -config config/dev.yaml replay -partition 0 -offset 42
+//	go run ./cmd/dlq -config config/dev.yaml discard -partition 0 -offset -1
+//
+// replay/discard 处理指定分区中截至 offset（含，-1 表示全部）的所有未处理死信。
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/gavin/airport-pickup/internal/config"
+	kbus "github.com/gavin/airport-pickup/pkg/eventbus"
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: dlq [-config path] <list|replay|discard> [flags]\n")
+	flag.PrintDefaults()
+}
+
+func main() {
+	cfgPath := flag.String("config", "config/dev.yaml", "path to config yaml")
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() < 1 {
+		usage()
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load(*cfgPath)
+	if err != nil {
+		log.Fatalf("load config failed: %v", err)
+	}
+	if len(cfg.Kafka.Brokers) == 0 || cfg.Kafka.Topic == "" || cfg.Kafka.GroupID == "" {
+		log.Fatalf("kafka brokers/topic/group not configured")
+	}
+
+	sub := flag.NewFlagSet(flag.Arg(0), flag.ExitOnError)
+	limit := sub.Int("limit", 50, "max messages to list (<=0 for all)")
+	partition := sub.Int("partition", 0, "dead-letter partition")
+	offset := sub.Int64("offset", -1, "handle pending messages up to this offset (inclusive), -1 for all")
+	_ = sub.Parse(flag.Args()[1:])
+
+	admin, err := kbus.NewDeadLetterAdmin(cfg.Kafka.Brokers, cfg.Kafka.Topic, cfg.Kafka.GroupID+"-dlq-admin")
+	if err != nil {
+		log.Fatalf("connect kafka failed: %v", err)
+	}
+	defer admin.Close()
+
+	switch flag.Arg(0) {
+	case "list":
+		lst, err := admin.List(*limit)
+		if err != nil {
+			log.Fatalf("list dead letters failed: %v", err)
+		}
+		codec, err := kbus.NewCodec(kbus.EncodingJSON)
+		if err != nil {
+			log.Fatalf("load event schemas failed: %v", err)
+		}
+		for _, d := range lst {
+			fmt.Printf("partition=%d offset=%d event=%s attempts=%d at=%s error=%q\n  %s\n",
+				d.Partition, d.Offset, d.EventName, d.Attempts, d.Timestamp.Format("2006-01-02T15:04:05Z07:00"), d.LastError, describePayload(codec, d))
+		}
+		fmt.Printf("%d pending dead letter(s)\n", len(lst))
+	case "replay":
+		n, err := admin.Replay(int32(*partition), *offset)
+		if err != nil {
+			log.Fatalf("replay failed after %d message(s): %v", n, err)
+		}
+		fmt.Printf("replayed %d message(s)\n", n)
+	case "discard":
+		n, err := admin.Discard(int32(*partition), *offset)
+		if err != nil {
+			log.Fatalf("discard failed: %v", err)
+		}
+		fmt.Printf("discarded %d message(s)\n", n)
+	default:
+		usage()
+		os.Exit(2)
+	}
+}
+
+// describePayload 以可读形式展示载荷，无法解码时输出原始字节。
+func describePayload(codec *kbus.Codec, d kbus.DeadLetter) string {
+	ev, err := codec.Decode(d.EventName, d.ContentType, d.Payload)
+	if err != nil {
+		return fmt.Sprintf("%q (%s, %v)", d.Payload, d.ContentType, err)
+	}
+	return fmt.Sprintf("%+v", ev)
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/gavin/airport-pickup/internal/domain/money"
+	order "github.com/gavin/airport-pickup/internal/domain/order"
+	orderentity "github.com/gavin/airport-pickup/internal/domain/order/entity"
+	promotion "github.com/gavin/airport-pickup/internal/domain/promotion"
+	promotionentity "github.com/gavin/airport-pickup/internal/domain/promotion/entity"
+	settlement "github.com/gavin/airport-pickup/internal/domain/settlement"
+	settlemententity "github.com/gavin/airport-pickup/internal/domain/settlement/entity"
+	settlesvc "github.com/gavin/airport-pickup/internal/domain/settlement/service"
+	"github.com/gavin/airport-pickup/internal/worker"
+)
+
+// dry-run 装饰器：读操作透传到真实存储，写操作只打印将要产生的状态变更。
+
+type dryRunOrderRepo struct {
+	order.OrderRepository
+	out io.Writer
+}
+
+func (r *dryRunOrderRepo) SavePickupRequest(p *orderentity.PickupRequest) error {
+	fmt.Fprintf(r.out, "  ~ save pickup_request %+v\n", *p)
+	return nil
+}
+func (r *dryRunOrderRepo) UpdatePickupRequest(p *orderentity.PickupRequest) error {
+	return r.SavePickupRequest(p)
+}
+func (r *dryRunOrderRepo) ClosePickupRequest(p *orderentity.PickupRequest) (bool, error) {
+	fmt.Fprintf(r.out, "  ~ close pickup_request %s status=%s\n", p.ID, p.Status)
+	return true, nil
+}
+func (r *dryRunOrderRepo) SaveDriverOffer(o *orderentity.DriverOffer) error {
+	fmt.Fprintf(r.out, "  ~ save driver_offer %+v\n", *o)
+	return nil
+}
+func (r *dryRunOrderRepo) UpdateDriverOffer(o *orderentity.DriverOffer) error {
+	return r.SaveDriverOffer(o)
+}
+func (r *dryRunOrderRepo) UpdateOpenRequestPassengerScores(passengerID string, score float64) error {
+	fmt.Fprintf(r.out, "  ~ update open request passenger scores passenger=%s score=%g\n", passengerID, score)
+	return nil
+}
+func (r *dryRunOrderRepo) CancelOpenDriverOffers(driverID string) error {
+	fmt.Fprintf(r.out, "  ~ cancel open offers driver=%s\n", driverID)
+	return nil
+}
+func (r *dryRunOrderRepo) UpdateOpenOfferRatings(driverID string, rating float64) error {
+	fmt.Fprintf(r.out, "  ~ update open offer ratings driver=%s rating=%g\n", driverID, rating)
+	return nil
+}
+func (r *dryRunOrderRepo) SaveBooking(b *orderentity.Booking) error {
+	fmt.Fprintf(r.out, "  ~ save booking %+v\n", *b)
+	return nil
+}
+func (r *dryRunOrderRepo) UpdateBooking(b *orderentity.Booking) error { return r.SaveBooking(b) }
+func (r *dryRunOrderRepo) SaveQuote(q *orderentity.Quote) error {
+	fmt.Fprintf(r.out, "  ~ save quote %+v\n", *q)
+	return nil
+}
+func (r *dryRunOrderRepo) UpdateAllInTransaction(b *orderentity.Booking, req *orderentity.PickupRequest, o *orderentity.DriverOffer) error {
+	if b != nil {
+		_ = r.SaveBooking(b)
+	}
+	if req != nil {
+		_ = r.SavePickupRequest(req)
+	}
+	if o != nil {
+		_ = r.SaveDriverOffer(o)
+	}
+	return nil
+}
+
+type dryRunSettlementRepo struct {
+	settlement.SettlementRepository
+	out io.Writer
+}
+
+func (r *dryRunSettlementRepo) SavePaymentTransaction(t *settlemententity.PaymentTransaction) error {
+	fmt.Fprintf(r.out, "  ~ save payment_transaction %+v\n", *t)
+	return nil
+}
+func (r *dryRunSettlementRepo) SaveSettlementRecord(s *settlemententity.SettlementRecord) error {
+	fmt.Fprintf(r.out, "  ~ save settlement_record %+v\n", *s)
+	return nil
+}
+func (r *dryRunSettlementRepo) SaveRevenueRecord(rr *settlemententity.RevenueRecord) error {
+	fmt.Fprintf(r.out, "  ~ save revenue_record %+v\n", *rr)
+	return nil
+}
+func (r *dryRunSettlementRepo) SaveAllInTransaction(ptx *settlemententity.PaymentTransaction, sr *settlemententity.SettlementRecord, rr *settlemententity.RevenueRecord, entries []*settlemententity.JournalEntry) error {
+	_ = r.SavePaymentTransaction(ptx)
+	_ = r.SaveSettlementRecord(sr)
+	_ = r.SaveRevenueRecord(rr)
+	r.printJournalEntries(entries)
+	return nil
+}
+
+func (r *dryRunSettlementRepo) printJournalEntries(entries []*settlemententity.JournalEntry) {
+	for _, e := range entries {
+		fmt.Fprintf(r.out, "  ~ post journal_entry %s booking=%s\n", e.Kind, e.BookingID)
+		for _, l := range e.Lines {
+			fmt.Fprintf(r.out, "      %-40s Dr %8d  Cr %8d\n", l.Account, l.DebitCents, l.CreditCents)
+		}
+	}
+}
+
+func (r *dryRunSettlementRepo) SaveSettlementSaga(saga *settlemententity.SettlementSaga) error {
+	fmt.Fprintf(r.out, "  ~ save settlement_saga %+v\n", *saga)
+	return nil
+}
+func (r *dryRunSettlementRepo) SaveRecordsWithSaga(saga *settlemententity.SettlementSaga, ptx *settlemententity.PaymentTransaction, sr *settlemententity.SettlementRecord, rr *settlemententity.RevenueRecord, entries []*settlemententity.JournalEntry) error {
+	_ = r.SaveAllInTransaction(ptx, sr, rr, entries)
+	return r.SaveSettlementSaga(saga)
+}
+
+func (r *dryRunSettlementRepo) ReserveRefund(paymentID string, amountCents int64) (int64, error) {
+	fmt.Fprintf(r.out, "  ~ reserve refund payment=%s amount_cents=%d\n", paymentID, amountCents)
+	p, err := r.GetPaymentTransactionByID(paymentID)
+	if err != nil {
+		return 0, err
+	}
+	return p.RefundedCents, nil
+}
+func (r *dryRunSettlementRepo) ReleaseRefund(paymentID string, amountCents int64) error {
+	fmt.Fprintf(r.out, "  ~ release refund payment=%s amount_cents=%d\n", paymentID, amountCents)
+	return nil
+}
+
+func (r *dryRunSettlementRepo) SaveRefund(payment, refund *settlemententity.PaymentTransaction, sr *settlemententity.SettlementRecord, rr *settlemententity.RevenueRecord, entries []*settlemententity.JournalEntry) error {
+	_ = r.SavePaymentTransaction(payment)
+	_ = r.SavePaymentTransaction(refund)
+	if sr != nil {
+		_ = r.SaveSettlementRecord(sr)
+	}
+	if rr != nil {
+		_ = r.SaveRevenueRecord(rr)
+	}
+	r.printJournalEntries(entries)
+	return nil
+}
+
+func (r *dryRunSettlementRepo) SaveDepositCapture(ptx *settlemententity.PaymentTransaction, rr *settlemententity.RevenueRecord, entries []*settlemententity.JournalEntry) error {
+	_ = r.SavePaymentTransaction(ptx)
+	_ = r.SaveRevenueRecord(rr)
+	r.printJournalEntries(entries)
+	return nil
+}
+
+type dryRunRevenueStatsRepo struct {
+	settlement.RevenueStatsRepository
+	out io.Writer
+}
+
+func (r *dryRunRevenueStatsRepo) ApplyRevenueFacts(facts []*settlemententity.RevenueFact) (int, error) {
+	for _, f := range facts {
+		fmt.Fprintf(r.out, "  ~ apply revenue_fact %+v\n", *f)
+	}
+	return len(facts), nil
+}
+
+type dryRunPromotionRepo struct {
+	promotion.PromotionRepository
+	out io.Writer
+}
+
+func (r *dryRunPromotionRepo) CreatePromotion(p *promotionentity.Promotion) error {
+	fmt.Fprintf(r.out, "  ~ create promotion %+v\n", *p)
+	return nil
+}
+func (r *dryRunPromotionRepo) Redeem(red *promotionentity.Redemption) error {
+	fmt.Fprintf(r.out, "  ~ redeem promotion %+v\n", *red)
+	return nil
+}
+func (r *dryRunPromotionRepo) ReleaseRedemption(bookingID string) error {
+	fmt.Fprintf(r.out, "  ~ release promotion redemption booking=%s\n", bookingID)
+	return nil
+}
+
+type dryRunPayments struct{ out io.Writer }
+
+var _ settlesvc.PaymentService = (*dryRunPayments)(nil)
+
+func (p *dryRunPayments) Authorize(bookingID, passengerID, paymentMethod string, amountCents int64) error {
+	fmt.Fprintf(p.out, "  ~ authorize booking=%s passenger=%s payment_method=%q amount_cents=%d\n", bookingID, passengerID, paymentMethod, amountCents)
+	return nil
+}
+
+func (p *dryRunPayments) Capture(bookingID string, amountCents int64) error {
+	fmt.Fprintf(p.out, "  ~ capture booking=%s amount_cents=%d\n", bookingID, amountCents)
+	return nil
+}
+
+func (p *dryRunPayments) Void(bookingID string) error {
+	fmt.Fprintf(p.out, "  ~ void booking=%s\n", bookingID)
+	return nil
+}
+
+func (p *dryRunPayments) Refund(bookingID, refundID string, amountCents int64) error {
+	fmt.Fprintf(p.out, "  ~ refund booking=%s refund=%s amount_cents=%d\n", bookingID, refundID, amountCents)
+	return nil
+}
+
+type dryRunOrderBooks struct{ out io.Writer }
+
+var _ worker.OrderBookStore = (*dryRunOrderBooks)(nil)
+
+func (s *dryRunOrderBooks) AddPickupRequest(_ context.Context, airport, vehicle string, req any, maxPrice money.Money) error {
+	fmt.Fprintf(s.out, "  ~ redis zadd orderbook:requests:%s:%s score=%v %+v\n", airport, vehicle, maxPrice, req)
+	return nil
+}
+func (s *dryRunOrderBooks) AddDriverOffer(_ context.Context, airport, vehicle string, offer any, price money.Money) error {
+	fmt.Fprintf(s.out, "  ~ redis zadd orderbook:offers:%s:%s score=%v %+v\n", airport, vehicle, price, offer)
+	return nil
+}
+func (s *dryRunOrderBooks) RemovePickupRequest(_ context.Context, airport, vehicle, requestID string) error {
+	fmt.Fprintf(s.out, "  ~ redis zrem orderbook:requests:%s:%s request=%s\n", airport, vehicle, requestID)
+	return nil
+}
+func (s *dryRunOrderBooks) RemoveDriverOffer(_ context.Context, airport, vehicle, offerID string) error {
+	fmt.Fprintf(s.out, "  ~ redis zrem orderbook:offers:%s:%s offer=%s\n", airport, vehicle, offerID)
+	return nil
+}
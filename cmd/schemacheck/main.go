@@ -0,0 +1,51 @@
+// Command schemacheck 校验事件 schema 注册表：相邻版本双向兼容，且已发布版本未被修改或删除。
+//
+//	go run ./cmd/schemacheck
+//	go run ./cmd/schemacheck -write-lock   # 新增版本后更新锁文件
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/gavin/airport-pickup/pkg/avro"
+)
+
+func main() {
+	dir := flag.String("dir", "schemas/events", "schema registry directory")
+	lockPath := flag.String("lock", "schemas/events.lock", "lock file of published schema versions")
+	writeLock := flag.Bool("write-lock", false, "record current versions in the lock file after the compatibility check passes")
+	flag.Parse()
+
+	reg, err := avro.LoadRegistry(os.DirFS(*dir))
+	if err != nil {
+		log.Fatalf("load registry failed: %v", err)
+	}
+	if err := reg.Check(); err != nil {
+		log.Fatalf("incompatible schema change:\n%v", err)
+	}
+	lock, err := os.ReadFile(*lockPath)
+	if err != nil && !os.IsNotExist(err) {
+		log.Fatalf("read lock failed: %v", err)
+	}
+	if *writeLock {
+		// 新版本可以加入锁文件，但已发布版本被改动时拒绝覆盖
+		if err := reg.VerifyLock(lock); err != nil && reg.VerifyLock(append(lock, reg.Lock()...)) != nil {
+			log.Fatalf("published schemas changed:\n%v", err)
+		}
+		if err := os.WriteFile(*lockPath, reg.Lock(), 0o644); err != nil {
+			log.Fatalf("write lock failed: %v", err)
+		}
+		fmt.Printf("lock file %s updated\n", *lockPath)
+		return
+	}
+	if err := reg.VerifyLock(lock); err != nil {
+		log.Fatalf("lock check failed:\n%v", err)
+	}
+	for _, s := range reg.Subjects() {
+		v, _ := reg.Latest(s)
+		fmt.Printf("%-24s %s ok\n", s, v.ID())
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"flag"
+	"fmt"
+	"io"
 	"log"
 	"net/http"
 
@@ -13,30 +15,102 @@ import (
 	evt "github.com/gavin/airport-pickup/internal/domain/eventbus"
 	"github.com/gavin/airport-pickup/internal/domain/order"
 	"github.com/gavin/airport-pickup/internal/domain/order/service"
+	"github.com/gavin/airport-pickup/internal/domain/promotion"
 	"github.com/gavin/airport-pickup/internal/domain/settlement"
 	"github.com/gavin/airport-pickup/internal/domain/user"
 	"github.com/gavin/airport-pickup/internal/worker"
+	"github.com/gavin/airport-pickup/pkg/blobstore"
+	"github.com/gavin/airport-pickup/pkg/documents"
 	kbus "github.com/gavin/airport-pickup/pkg/eventbus"
+	"github.com/gavin/airport-pickup/pkg/notify"
 	"github.com/gavin/airport-pickup/pkg/payments"
 	"github.com/gavin/airport-pickup/pkg/redisstore"
 	mysqlrepo "github.com/gavin/airport-pickup/pkg/repository/mysql"
 )
 
-func buildRepos(cfg *config.Config) (user.PassengerRepository, user.DriverRepository, order.OrderRepository, settlement.SettlementRepository, error) {
+// repositories 聚合 MySQL 仓库实现
+type repositories struct {
+	passenger  user.PassengerRepository
+	methods    user.PaymentMethodRepository
+	driver     user.DriverRepository
+	documents  user.DriverDocumentRepository
+	vehicles   user.VehicleRepository
+	ratings    user.RatingRepository
+	order      order.OrderRepository
+	settlement settlement.SettlementRepository
+	payouts    settlement.PayoutRepository
+	invoices   settlement.InvoiceRepository
+	recon      settlement.ReconciliationRepository
+	stats      settlement.RevenueStatsRepository
+	promotions promotion.PromotionRepository
+	events     evt.EventStore
+}
+
+func buildRepos(cfg *config.Config) (*repositories, error) {
 	if dsn := cfg.Database.DSN; dsn != "" {
 		db, err := mysqlrepo.NewDB(dsn)
 		if err != nil {
-			return nil, nil, nil, nil, err
+			return nil, err
 		}
 		if cfg.Database.AutoMigrate {
 			if err := mysqlrepo.AutoMigrate(db); err != nil {
-				return nil, nil, nil, nil, err
+				return nil, err
 			}
 		}
 		log.Println("using MySQL repositories")
-		return mysqlrepo.NewPassengerRepository(db), mysqlrepo.NewDriverRepository(db), mysqlrepo.NewOrderRepository(db), mysqlrepo.NewSettlementRepository(db), nil
+		return &repositories{
+			passenger:  mysqlrepo.NewPassengerRepository(db),
+			methods:    mysqlrepo.NewPaymentMethodRepository(db),
+			driver:     mysqlrepo.NewDriverRepository(db),
+			documents:  mysqlrepo.NewDriverDocumentRepository(db),
+			vehicles:   mysqlrepo.NewVehicleRepository(db),
+			ratings:    mysqlrepo.NewRatingRepository(db),
+			order:      mysqlrepo.NewOrderRepository(db),
+			settlement: mysqlrepo.NewSettlementRepository(db),
+			payouts:    mysqlrepo.NewPayoutRepository(db),
+			invoices:   mysqlrepo.NewInvoiceRepository(db),
+			recon:      mysqlrepo.NewReconciliationRepository(db),
+			stats:      mysqlrepo.NewRevenueStatsRepository(db),
+			promotions: mysqlrepo.NewPromotionRepository(db),
+			events:     mysqlrepo.NewEventStoreRepository(db),
+		}, nil
 	}
-	return nil, nil, nil, nil, errors.New("connect mysql failed: empty DSN")
+	return nil, errors.New("connect mysql failed: empty DSN")
+}
+
+func buildEventBus(cfg *config.Config) (evt.EventBus, io.Closer, error) {
+	codec, err := kbus.NewCodec(cfg.EventBus.Encoding)
+	if err != nil {
+		return nil, nil, err
+	}
+	switch cfg.EventBus.Driver {
+	case "kafka":
+		k := cfg.Kafka
+		if len(k.Brokers) == 0 || k.Topic == "" || k.GroupID == "" {
+			return nil, nil, errors.New("kafka brokers/topic/group required")
+		}
+		retry := kbus.RetryPolicy{MaxAttempts: k.Retry.MaxAttempts, BaseDelay: k.Retry.BaseDelay}
+		kb, err := kbus.NewKafkaEventBus(k.Brokers, k.Topic, k.GroupID, retry, codec)
+		if err != nil {
+			return nil, nil, err
+		}
+		log.Printf("using Kafka event bus, brokers=%v, topic=%s, group=%s, encoding=%s", k.Brokers, k.Topic, k.GroupID, cfg.EventBus.Encoding)
+		return kb, kb, nil
+	case "redis_streams":
+		rs := cfg.RedisStreams
+		rb, err := kbus.NewRedisStreamsEventBus(kbus.RedisStreamsOptions{
+			Addr: cfg.Redis.Addr, Password: cfg.Redis.Password, DB: cfg.Redis.DB,
+			Stream: rs.Stream, Group: rs.Group, MaxLen: rs.MaxLen, ClaimMinIdle: rs.ClaimMinIdle,
+			Retry: kbus.RetryPolicy{MaxAttempts: rs.Retry.MaxAttempts, BaseDelay: rs.Retry.BaseDelay},
+			Codec: codec,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		log.Printf("using Redis Streams event bus, addr=%s, stream=%s, group=%s, encoding=%s", cfg.Redis.Addr, rs.Stream, rs.Group, cfg.EventBus.Encoding)
+		return rb, rb, nil
+	}
+	return nil, nil, fmt.Errorf("unknown event bus driver %q", cfg.EventBus.Driver)
 }
 
 func main() {
@@ -49,74 +123,153 @@ func main() {
 		log.Fatalf("load config failed: %v", err)
 	}
 
-	// Event bus: Kafka 优先，其次回退至内存
-	var bus evt.EventBus
-
-	brokers := cfg.Kafka.Brokers
-	topic := cfg.Kafka.Topic
-	groupID := cfg.Kafka.GroupID
-
-	var kafkaBus *kbus.KafkaEventBus
-	if len(brokers) > 0 && topic != "" && groupID != "" {
-		kb, err := kbus.NewKafkaEventBus(brokers, topic, groupID)
-		if err != nil {
-			log.Printf("init KafkaEventBus failed, fallback to memory bus: %v", err)
-		} else {
-			kafkaBus = kb
-			bus = kb
-			log.Printf("using Kafka event bus, brokers=%v, topic=%s, group=%s", brokers, topic, groupID)
-		}
+	// Repositories
+	repos, err := buildRepos(cfg)
+	if err != nil {
+		log.Fatalf("repository init failed: %v", err)
 	}
-	if bus == nil {
-		log.Fatalf("event bus init failed: no available event bus (kafka and memorybus both unavailable)")
+
+	// Event bus: 按配置选择 Kafka 或 Redis Streams，发布的事件同时写入事件存储
+	rawBus, busCloser, err := buildEventBus(cfg)
+	if err != nil {
+		log.Fatalf("event bus init failed: %v", err)
 	}
+	bus := kbus.NewPersistingEventBus(rawBus, repos.events)
 
 	// Redis 初始化
-	var rds *redisstore.Client
+	var orderBooks worker.OrderBookStore
+	var sharedOffers *worker.SharedOfferBook
 	{
 		opt := redisstore.Options{Addr: cfg.Redis.Addr, Password: cfg.Redis.Password, DB: cfg.Redis.DB}
-		rds = redisstore.New(opt)
+		rds := redisstore.New(opt)
 		if err := rds.Ping(context.Background()); err != nil {
 			log.Printf("redis ping failed (will continue without redis): %v", err)
-			rds = nil
 		} else {
+			orderBooks = rds
+			sharedOffers = worker.NewSharedOfferBook(rds)
 			log.Printf("redis connected: addr=%s db=%d", opt.Addr, opt.DB)
 		}
 	}
 
 	// Payment client
-	pay := payments.NewWalletClient()
+	pay := payments.NewWalletClientWithBalance(cfg.Payments.Wallet.InitialBalanceCents)
+	payoutProvider := payments.NewLocalPayoutProvider()
+	vault := payments.NewLocalVault()
 
-	// Repositories
-	passRepo, driverRepo, orderRepo, settlementRepo, err := buildRepos(cfg)
+	// Domain services
+	pricing, err := cfg.PricingPolicies()
 	if err != nil {
-		log.Fatalf("repository init failed: %v", err)
+		log.Fatalf("load pricing policies: %v", err)
 	}
+	matching := service.NewMatchingService(repos.order, repos.driver, pricing)
+	surgeRules, err := cfg.SurgeRules()
+	if err != nil {
+		log.Fatalf("load surge rules: %v", err)
+	}
+	// 撮合 worker 维护倍率，订单接口读取
+	surge := service.NewSurgeEngine(surgeRules)
 
-	// Domain services
-	matching := service.NewMatchingService(orderRepo, driverRepo)
+	defaultFare, airportFares, err := cfg.FareRules()
+	if err != nil {
+		log.Fatalf("load fare rules: %v", err)
+	}
 
-	// App services
-	orderApp := app.NewOrderAppService(orderRepo, passRepo, driverRepo, matching, bus)
-	settlementApp := app.NewSettlementAppService(settlementRepo, orderRepo, pay, bus)
+	reputation, err := cfg.ReputationPolicy()
+	if err != nil {
+		log.Fatalf("load reputation policy: %v", err)
+	}
 
 	// Worker service for matching
-	orderWorker := worker.NewOrderWorkerService(orderRepo, matching, bus, rds)
+	orderWorker := worker.NewOrderWorkerService(repos.order, matching, bus, orderBooks).WithSurge(surge)
 
-	// Workers: subscribe to events（首次订阅将启动 Kafka 消费循环）
-	_ = worker.NewEventConsumer(bus, settlementApp, orderWorker)
+	// 报价读取各实例共同维护的 Redis 订单簿；没有 Redis 时退回本实例的内存订单簿，只能单实例部署
+	var offerSource app.OpenOfferSource = orderWorker
+	if sharedOffers != nil {
+		offerSource = sharedOffers
+	} else {
+		log.Printf("quotes read the in-process order book; run a single instance without redis")
+	}
 
-	// 优雅关闭（Kafka 模式）
-	if kafkaBus != nil {
-		defer func() {
-			if err := kafkaBus.Close(); err != nil {
-				log.Printf("close Kafka bus error: %v", err)
-			}
-		}()
+	// App services
+	orderApp := app.NewOrderAppService(repos.order, repos.passenger, repos.driver, repos.vehicles, matching, bus).
+		WithAirportCurrencies(cfg.Currency.Default, cfg.AirportCurrencies()).
+		WithPromotions(repos.promotions).
+		WithSurge(surge).
+		WithQuotes(pricing, cfg.Quotes.TTL, offerSource).
+		WithFareRules(defaultFare, airportFares).
+		WithRatings(repos.ratings, reputation).
+		WithDeposits(pay, cfg.Ratings.PassengerDepositBelow, cfg.Ratings.PassengerDepositCents).
+		WithRequestExpiry(cfg.Requests.ExpireAfter).
+		WithPaymentMethods(repos.methods)
+	settlementApp := app.NewSettlementAppService(repos.settlement, repos.order, pay, bus).
+		WithSagaMaxAttempts(cfg.Settlement.Saga.MaxAttempts).
+		WithPromotions(repos.promotions).
+		WithPaymentMethods(repos.methods)
+	if cfg.Currency.RatesFile != "" {
+		rates, err := config.LoadRates(cfg.Currency.RatesFile)
+		if err != nil {
+			log.Fatalf("load rates: %v", err)
+		}
+		settlementApp.WithRates(rates)
 	}
+	settlementApp.WithFareRules(defaultFare, airportFares)
+	payoutApp := app.NewPayoutAppService(repos.payouts, payoutProvider).WithFeeCents(cfg.Payouts.FeeCents)
+	renderer, err := documents.NewRenderer()
+	if err != nil {
+		log.Fatalf("load document templates: %v", err)
+	}
+	defaultEntity, airportEntities, err := cfg.LegalEntities()
+	if err != nil {
+		log.Fatalf("load legal entities: %v", err)
+	}
+	invoiceApp := app.NewInvoiceAppService(repos.invoices, repos.settlement, repos.order, renderer).
+		WithLegalEntities(defaultEntity, airportEntities)
+	reconApp := app.NewReconciliationAppService(repos.recon)
+	analyticsApp := app.NewAnalyticsAppService(repos.stats, repos.order)
+	promotionApp := app.NewPromotionAppService(repos.promotions)
+	documentStore, err := blobstore.NewLocalStore(cfg.Onboarding.DocumentsDir)
+	if err != nil {
+		log.Fatalf("open driver document store: %v", err)
+	}
+	onboardingApp := app.NewDriverOnboardingAppService(repos.driver, repos.documents, repos.vehicles, documentStore, repos.order, bus).
+		WithSuspendBefore(cfg.Onboarding.SuspendBefore)
+	passengerApp := app.NewPassengerAppService(repos.passenger, repos.methods, vault, repos.order)
+
+	// Workers: subscribe to events（首次订阅将启动消费循环）
+	_ = worker.NewEventConsumer(bus, settlementApp, orderWorker, orderApp)
+	worker.SubscribeRevenueStats(bus, analyticsApp)
+	worker.SubscribeSurgeNotifications(bus, repos.order, notify.NewLogNotifier(), cfg.Surge.NotifyLookback)
+	// 收入统计回填：计入订阅前已产生的结算记录
+	go func() {
+		n, err := analyticsApp.CatchUp()
+		if err != nil {
+			log.Printf("revenue stats catch-up error: %v", err)
+		}
+		log.Printf("revenue stats catch-up: %d settlement record(s) projected", n)
+	}()
+
+	// 结算 saga 恢复：续跑崩溃或重试耗尽后卡住的结算
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go worker.NewSettlementRecoveryWorker(settlementApp, cfg.Settlement.Saga.RecoveryInterval, cfg.Settlement.Saga.StaleAfter).Run(ctx)
+	// 司机打款：定期汇总未打款的结算记录
+	go worker.NewPayoutWorker(payoutApp, cfg.Payouts.Interval).Run(ctx)
+	// 企业客户月度发票：每月开具上一个自然月的发票
+	go worker.NewInvoiceWorker(invoiceApp, cfg.Invoicing.Interval).Run(ctx)
+	// 司机证件到期检查：暂停证件即将到期的司机
+	go worker.NewDriverComplianceWorker(onboardingApp, cfg.Onboarding.CheckInterval).Run(ctx)
+	// 接机请求过期：关闭期望接机时间已过仍未撮合的请求并释放押金
+	go worker.NewRequestExpiryWorker(orderApp, cfg.Requests.CheckInterval).Run(ctx)
+
+	// 优雅关闭
+	defer func() {
+		if err := busCloser.Close(); err != nil {
+			log.Printf("close event bus error: %v", err)
+		}
+	}()
 
 	// HTTP router
-	r := httpapi.NewRouter(orderApp, settlementApp)
+	r := httpapi.NewRouter(orderApp, settlementApp, payoutApp, invoiceApp, reconApp, analyticsApp, promotionApp, onboardingApp, passengerApp)
 
 	log.Printf("server listening on %s", cfg.Server.Addr)
 	if err := http.ListenAndServe(cfg.Server.Addr, r); err != nil {
@@ -0,0 +1,12 @@
+-- 领域事件存储（追加写）
+
+CREATE TABLE IF NOT EXISTS domain_events (
+    seq BIGINT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    aggregate_key VARCHAR(128) NOT NULL,
+    payload TEXT NOT NULL,
+    occurred_at DATETIME NOT NULL,
+    INDEX idx_event_name (name),
+    INDEX idx_event_aggregate (aggregate_key),
+    INDEX idx_event_occurred (occurred_at)
+);
@@ -0,0 +1,17 @@
+-- 结算 saga：每个订单一条，记录扣款 -> 落库 -> 发布事件的进度
+
+CREATE TABLE IF NOT EXISTS settlement_sagas (
+    id VARCHAR(64) PRIMARY KEY,
+    booking_id VARCHAR(64) NOT NULL,
+    driver_id VARCHAR(64) NOT NULL,
+    passenger_id VARCHAR(64) NOT NULL,
+    amount_cents BIGINT NOT NULL,
+    platform_revenue_cents BIGINT NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    last_error VARCHAR(500),
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE INDEX idx_settlement_sagas_booking_id (booking_id),
+    INDEX idx_saga_status_updated (status, updated_at)
+);
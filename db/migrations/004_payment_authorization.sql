@@ -0,0 +1,12 @@
+-- 支付流程改为预授权 -> 扣款：payment_transactions 每个订单一条，记录各状态时间
+
+ALTER TABLE payment_transactions
+    ADD COLUMN captured_cents BIGINT NOT NULL DEFAULT 0 AFTER amount_cents,
+    ADD COLUMN refunded_cents BIGINT NOT NULL DEFAULT 0 AFTER captured_cents,
+    ADD COLUMN failure_reason VARCHAR(255) AFTER status,
+    ADD COLUMN authorized_at DATETIME NULL AFTER failure_reason,
+    ADD COLUMN captured_at DATETIME NULL AFTER authorized_at,
+    ADD COLUMN voided_at DATETIME NULL AFTER captured_at,
+    ADD COLUMN refunded_at DATETIME NULL AFTER voided_at,
+    ADD COLUMN failed_at DATETIME NULL AFTER refunded_at,
+    ADD INDEX idx_payment_transactions_booking_id (booking_id);
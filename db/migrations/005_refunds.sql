@@ -0,0 +1,5 @@
+-- 退款：每次退款写一条 kind = 'refund' 的支付流水，订单支付流水累计 refunded_cents
+
+ALTER TABLE payment_transactions
+    ADD COLUMN kind VARCHAR(20) NOT NULL DEFAULT 'payment' AFTER booking_id,
+    ADD COLUMN reason_code VARCHAR(50) AFTER failure_reason;
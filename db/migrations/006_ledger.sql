@@ -0,0 +1,21 @@
+-- 复式记账：每笔分录借方合计等于贷方合计
+-- 账户：passenger_receivable:<passenger_id>、driver_payable:<driver_id>、platform_revenue、payment_clearing
+
+CREATE TABLE IF NOT EXISTS journal_entries (
+    id VARCHAR(64) PRIMARY KEY,
+    booking_id VARCHAR(64) NOT NULL,
+    kind VARCHAR(20) NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_journal_entries_booking_id (booking_id)
+);
+
+CREATE TABLE IF NOT EXISTS journal_lines (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    entry_id VARCHAR(64) NOT NULL,
+    account VARCHAR(100) NOT NULL,
+    debit_cents BIGINT NOT NULL DEFAULT 0,
+    credit_cents BIGINT NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_journal_lines_entry_id (entry_id),
+    INDEX idx_journal_lines_account (account)
+);
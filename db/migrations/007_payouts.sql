@@ -0,0 +1,40 @@
+-- 司机打款：按司机汇总未打款的结算记录生成打款单，settlement_records.payout_id 为空表示未打款
+-- 打款失败时清空 payout_id，结算记录回到未打款状态
+
+CREATE TABLE IF NOT EXISTS payouts (
+    id VARCHAR(64) PRIMARY KEY,
+    driver_id VARCHAR(64) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    gross_cents BIGINT NOT NULL,
+    commission_cents BIGINT NOT NULL,
+    fee_cents BIGINT NOT NULL,
+    net_cents BIGINT NOT NULL,
+    provider_ref VARCHAR(128),
+    failure_reason VARCHAR(255),
+    paid_at DATETIME NULL,
+    failed_at DATETIME NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    INDEX idx_payouts_driver_id (driver_id),
+    INDEX idx_payouts_status (status)
+);
+
+CREATE TABLE IF NOT EXISTS payout_lines (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    payout_id VARCHAR(64) NOT NULL,
+    settlement_record_id VARCHAR(64) NOT NULL,
+    booking_id VARCHAR(64) NOT NULL,
+    amount_cents BIGINT NOT NULL,
+    commission_cents BIGINT NOT NULL,
+    net_cents BIGINT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_payout_lines_payout_id (payout_id)
+);
+
+ALTER TABLE settlement_records
+    ADD COLUMN payout_id VARCHAR(64) NOT NULL DEFAULT '' AFTER platform_revenue_cents,
+    ADD INDEX idx_settlement_records_payout_id (payout_id);
+
+ALTER TABLE journal_entries
+    ADD COLUMN payout_id VARCHAR(64) NOT NULL DEFAULT '' AFTER booking_id,
+    ADD INDEX idx_journal_entries_payout_id (payout_id);
@@ -0,0 +1,12 @@
+-- 金额字段由 DOUBLE 改为 DECIMAL(10,2)，精确到分
+-- 先按四舍五入修正历史数据中的浮点误差（如 2.2999999999999998 -> 2.30），再修改列类型
+
+UPDATE pickup_requests SET max_price_per_km = ROUND(max_price_per_km, 2);
+UPDATE driver_offers SET price_per_km = ROUND(price_per_km, 2);
+UPDATE bookings SET price_per_km = ROUND(price_per_km, 2), platform_margin_per_km = ROUND(platform_margin_per_km, 2);
+
+ALTER TABLE pickup_requests MODIFY COLUMN max_price_per_km DECIMAL(10,2) NOT NULL;
+ALTER TABLE driver_offers MODIFY COLUMN price_per_km DECIMAL(10,2) NOT NULL;
+ALTER TABLE bookings
+    MODIFY COLUMN price_per_km DECIMAL(10,2) NOT NULL,
+    MODIFY COLUMN platform_margin_per_km DECIMAL(10,2) NOT NULL;
@@ -0,0 +1,17 @@
+-- 结算币种：报价、订单、支付、结算与收入记录、分录及打款均带 ISO 4217 币种代码
+-- 历史数据均为人民币结算，默认值 CNY
+
+ALTER TABLE pickup_requests ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'CNY';
+ALTER TABLE driver_offers ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'CNY';
+ALTER TABLE bookings ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'CNY';
+ALTER TABLE payment_transactions ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'CNY';
+ALTER TABLE settlement_records ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'CNY';
+ALTER TABLE revenue_records ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'CNY';
+ALTER TABLE settlement_sagas ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'CNY';
+ALTER TABLE journal_entries ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'CNY';
+ALTER TABLE payouts ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'CNY';
+
+-- 分录行冗余分录币种，余额按账户与币种分组
+ALTER TABLE journal_lines
+    ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'CNY',
+    ADD INDEX idx_journal_lines_account_currency (account, currency);
@@ -0,0 +1,28 @@
+-- 车费明细：订单记录机场与行程信息（里程、等候时长、过路费），结算记录保存逐项明细及司机/平台拆分
+
+ALTER TABLE bookings
+    ADD COLUMN airport_code VARCHAR(10) NOT NULL DEFAULT '',
+    ADD COLUMN distance_km DOUBLE NOT NULL DEFAULT 0,
+    ADD COLUMN waiting_minutes INT NOT NULL DEFAULT 0,
+    ADD COLUMN tolls DECIMAL(10,2) NOT NULL DEFAULT 0;
+
+-- 历史订单的机场代码取自对应的接机需求
+UPDATE bookings b JOIN pickup_requests r ON r.id = b.request_id SET b.airport_code = r.airport_code;
+
+-- saga 在落库前暂存车费明细（JSON）
+ALTER TABLE settlement_sagas ADD COLUMN fare_items TEXT;
+
+CREATE TABLE IF NOT EXISTS settlement_fare_items (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    settlement_record_id VARCHAR(64) NOT NULL,
+    booking_id VARCHAR(64) NOT NULL,
+    seq INT NOT NULL,
+    kind VARCHAR(20) NOT NULL,
+    description VARCHAR(100),
+    amount_cents BIGINT NOT NULL,
+    driver_cents BIGINT NOT NULL,
+    platform_cents BIGINT NOT NULL,
+    created_at DATETIME NOT NULL,
+    INDEX idx_settlement_fare_items_record (settlement_record_id),
+    INDEX idx_settlement_fare_items_booking (booking_id)
+);
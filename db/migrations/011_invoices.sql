@@ -0,0 +1,53 @@
+-- 收据与企业客户月度发票：单据开具后保存内容快照，编号按开票主体连续分配
+
+-- 乘客所属企业客户，为空表示个人乘客
+ALTER TABLE passengers
+    ADD COLUMN corporate_account VARCHAR(64) NOT NULL DEFAULT '',
+    ADD INDEX idx_passengers_corporate_account (corporate_account);
+
+CREATE TABLE IF NOT EXISTS invoices (
+    id VARCHAR(64) PRIMARY KEY,
+    kind VARCHAR(20) NOT NULL,
+    number VARCHAR(64) NOT NULL,
+    seq BIGINT NOT NULL,
+    -- 收据每单一张；企业发票每个企业客户、账期、开票主体与币种一张
+    unique_key VARCHAR(255) NOT NULL,
+    legal_entity VARCHAR(32) NOT NULL,
+    issuer_name VARCHAR(200) NOT NULL,
+    issuer_address VARCHAR(255),
+    issuer_tax_id VARCHAR(64),
+    currency CHAR(3) NOT NULL DEFAULT 'CNY',
+    booking_id VARCHAR(64),
+    passenger_id VARCHAR(64),
+    payment_id VARCHAR(64),
+    paid_at DATETIME NULL,
+    corporate_account VARCHAR(64),
+    period_start DATETIME NULL,
+    period_end DATETIME NULL,
+    total_cents BIGINT NOT NULL,
+    tax_cents BIGINT NOT NULL,
+    issued_at DATETIME NOT NULL,
+    UNIQUE INDEX idx_invoices_number (number),
+    UNIQUE INDEX idx_invoices_unique_key (unique_key),
+    INDEX idx_invoices_booking_id (booking_id),
+    INDEX idx_invoices_corporate_account (corporate_account)
+);
+
+CREATE TABLE IF NOT EXISTS invoice_lines (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    invoice_id VARCHAR(64) NOT NULL,
+    seq INT NOT NULL,
+    kind VARCHAR(20) NOT NULL,
+    booking_id VARCHAR(64),
+    description VARCHAR(255),
+    amount_cents BIGINT NOT NULL,
+    tax_cents BIGINT NOT NULL,
+    date DATETIME NOT NULL,
+    INDEX idx_invoice_lines_invoice_id (invoice_id)
+);
+
+-- 每个开票主体最后分配的序号；分配时行锁保证编号连续
+CREATE TABLE IF NOT EXISTS invoice_sequences (
+    legal_entity VARCHAR(32) PRIMARY KEY,
+    last_seq BIGINT NOT NULL
+);
@@ -0,0 +1,40 @@
+-- 支付对账：导入钱包服务商对账单与本地支付流水逐笔核对，保存报告与待处理的差异
+
+CREATE TABLE IF NOT EXISTS reconciliation_reports (
+    id VARCHAR(64) PRIMARY KEY,
+    source VARCHAR(255),
+    status VARCHAR(20) NOT NULL,
+    -- 账期 [period_start, period_end)
+    period_start DATETIME NOT NULL,
+    period_end DATETIME NOT NULL,
+    statement_lines INT NOT NULL,
+    transactions INT NOT NULL,
+    matched_lines INT NOT NULL,
+    statement_cents BIGINT NOT NULL,
+    transaction_cents BIGINT NOT NULL,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    INDEX idx_reconciliation_reports_status (status)
+);
+
+CREATE TABLE IF NOT EXISTS reconciliation_exceptions (
+    id VARCHAR(64) PRIMARY KEY,
+    report_id VARCHAR(64) NOT NULL,
+    seq INT NOT NULL,
+    kind VARCHAR(30) NOT NULL,
+    booking_id VARCHAR(64) NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    transaction_id VARCHAR(64),
+    statement_ref VARCHAR(128),
+    statement_line_no INT,
+    expected_cents BIGINT,
+    statement_cents BIGINT,
+    currency CHAR(3) NOT NULL DEFAULT 'CNY',
+    status VARCHAR(20) NOT NULL,
+    resolution VARCHAR(30),
+    note VARCHAR(500),
+    resolved_at DATETIME NULL,
+    INDEX idx_reconciliation_exceptions_report_id (report_id),
+    INDEX idx_reconciliation_exceptions_booking_id (booking_id),
+    INDEX idx_reconciliation_exceptions_status (status)
+);
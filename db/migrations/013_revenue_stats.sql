@@ -0,0 +1,22 @@
+-- 收入统计聚合表：按日（UTC）、机场、车型与币种累计订单总额、平台收入与单量，由结算事件增量维护
+
+CREATE TABLE IF NOT EXISTS revenue_daily_stats (
+    day DATETIME NOT NULL,
+    airport_code VARCHAR(10) NOT NULL,
+    vehicle_type VARCHAR(50) NOT NULL,
+    currency CHAR(3) NOT NULL,
+    trips BIGINT NOT NULL,
+    gross_cents BIGINT NOT NULL,
+    revenue_cents BIGINT NOT NULL,
+    updated_at DATETIME NOT NULL,
+    PRIMARY KEY (day, airport_code, vehicle_type, currency)
+);
+
+-- 已计入统计的结算记录，保证事件重复投递时不重复累加
+CREATE TABLE IF NOT EXISTS revenue_stat_sources (
+    settlement_record_id VARCHAR(64) PRIMARY KEY,
+    day DATETIME NOT NULL,
+    created_at DATETIME NOT NULL
+);
+
+-- 历史结算记录由服务启动时的回填任务计入
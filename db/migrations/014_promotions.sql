@@ -0,0 +1,42 @@
+-- 优惠活动：乘客在接机请求中附带优惠码，结算时核销，优惠由平台分成承担
+
+CREATE TABLE IF NOT EXISTS promotions (
+    code VARCHAR(32) PRIMARY KEY,
+    description VARCHAR(255),
+    kind VARCHAR(20) NOT NULL,
+    rate DOUBLE NOT NULL,
+    amount_cents BIGINT NOT NULL,
+    max_discount_cents BIGINT NOT NULL,
+    currency CHAR(3) NOT NULL,
+    -- 逗号分隔，为空表示所有机场
+    airport_codes VARCHAR(255),
+    -- 有效期 [starts_at, ends_at)
+    starts_at DATETIME NOT NULL,
+    ends_at DATETIME NOT NULL,
+    -- 次数与预算上限为 0 表示不限
+    per_passenger_limit INT NOT NULL,
+    total_limit INT NOT NULL,
+    budget_cents BIGINT NOT NULL,
+    -- 有效核销的次数与优惠合计，核销时按上限条件更新
+    redeemed_count INT NOT NULL,
+    spent_cents BIGINT NOT NULL,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+
+-- 核销记录，每个订单最多一条；结算失败或补偿退款后标记为 released 并退回次数与预算
+CREATE TABLE IF NOT EXISTS promo_redemptions (
+    id VARCHAR(64) PRIMARY KEY,
+    code VARCHAR(32) NOT NULL,
+    booking_id VARCHAR(64) NOT NULL,
+    passenger_id VARCHAR(64) NOT NULL,
+    amount_cents BIGINT NOT NULL,
+    currency CHAR(3) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    created_at DATETIME NOT NULL,
+    released_at DATETIME NULL,
+    UNIQUE INDEX idx_promo_redemptions_booking_id (booking_id),
+    INDEX idx_redemption_code_passenger (code, passenger_id)
+);
+
+ALTER TABLE pickup_requests ADD COLUMN promo_code VARCHAR(32);
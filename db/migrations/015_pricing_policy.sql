@@ -0,0 +1,6 @@
+-- 计价策略：订单记录乘客每公里价格与成交时的计价策略快照（JSON），结算按订单上的结果计费
+-- 历史订单 fare_per_km 为 0，结算时按司机报价计费
+
+ALTER TABLE bookings
+    ADD COLUMN fare_per_km DECIMAL(10,2) NOT NULL DEFAULT 0,
+    ADD COLUMN pricing_terms TEXT NULL;
@@ -0,0 +1,4 @@
+-- 溢价：订单记录成交时订单簿的溢价倍率，历史订单为 1（无溢价）
+
+ALTER TABLE bookings
+    ADD COLUMN surge_multiplier DOUBLE NOT NULL DEFAULT 1;
@@ -0,0 +1,27 @@
+-- 报价：按实时订单簿给出的报价，有效期内以报价 ID 创建的接机请求沿用报价的出价与计价策略
+
+CREATE TABLE IF NOT EXISTS quotes (
+    id VARCHAR(64) NOT NULL PRIMARY KEY,
+    passenger_id VARCHAR(64) NOT NULL DEFAULT '',
+    airport_code VARCHAR(10) NOT NULL,
+    vehicle_type VARCHAR(50) NOT NULL,
+    desired_time DATETIME NOT NULL,
+    distance_km DOUBLE NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    max_price_per_km DECIMAL(10,2) NOT NULL,
+    best_offer_per_km DECIMAL(10,2) NOT NULL,
+    percentiles TEXT NOT NULL,
+    available_offers INT NOT NULL,
+    match_probability DOUBLE NOT NULL,
+    surge_multiplier DOUBLE NOT NULL DEFAULT 1,
+    fare_per_km DECIMAL(10,2) NOT NULL,
+    platform_margin_per_km DECIMAL(10,2) NOT NULL,
+    estimated_fare DECIMAL(10,2) NOT NULL,
+    pricing_terms TEXT NOT NULL,
+    created_at DATETIME NOT NULL,
+    expires_at DATETIME NOT NULL,
+    INDEX idx_quotes_expires_at (expires_at)
+);
+
+ALTER TABLE pickup_requests
+    ADD COLUMN quote_id VARCHAR(64) NULL;
@@ -0,0 +1,17 @@
+-- 行程评价：乘客在订单完成后评价司机，司机评分由评价计算，不再由司机自行填写
+
+CREATE TABLE IF NOT EXISTS trip_ratings (
+    id VARCHAR(64) NOT NULL PRIMARY KEY,
+    booking_id VARCHAR(64) NOT NULL,
+    target VARCHAR(20) NOT NULL,
+    rater_id VARCHAR(64) NOT NULL,
+    ratee_id VARCHAR(64) NOT NULL,
+    score INT NOT NULL,
+    comment VARCHAR(2000) NULL,
+    created_at DATETIME NOT NULL,
+    UNIQUE INDEX idx_rating_booking_target (booking_id, target),
+    INDEX idx_rating_ratee (ratee_id, target)
+);
+
+ALTER TABLE drivers
+    ADD COLUMN rating_count INT NOT NULL DEFAULT 0;
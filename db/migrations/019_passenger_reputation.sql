@@ -0,0 +1,16 @@
+-- 乘客声誉：司机在订单完成或取消后评价乘客，报价可设置最低乘客分，低分乘客创建请求需预授权押金
+
+ALTER TABLE passengers
+    ADD COLUMN reputation_score DOUBLE NOT NULL DEFAULT 0,
+    ADD COLUMN rating_count INT NOT NULL DEFAULT 0,
+    ADD COLUMN no_show_count INT NOT NULL DEFAULT 0;
+
+ALTER TABLE trip_ratings
+    ADD COLUMN no_show BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE pickup_requests
+    ADD COLUMN passenger_score DOUBLE NOT NULL DEFAULT 0,
+    ADD COLUMN deposit_cents BIGINT NOT NULL DEFAULT 0;
+
+ALTER TABLE driver_offers
+    ADD COLUMN min_passenger_score DOUBLE NOT NULL DEFAULT 0;
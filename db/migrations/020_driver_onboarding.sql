@@ -0,0 +1,24 @@
+-- 司机入驻：证件上传与审核、账号状态；已有司机视为已激活，新司机以 pending 创建
+
+ALTER TABLE drivers
+    ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'active',
+    ADD COLUMN status_reason VARCHAR(200) NULL,
+    ADD INDEX idx_drivers_status (status);
+
+CREATE TABLE IF NOT EXISTS driver_documents (
+    id VARCHAR(64) NOT NULL PRIMARY KEY,
+    driver_id VARCHAR(64) NOT NULL,
+    type VARCHAR(32) NOT NULL,
+    blob_key VARCHAR(255) NOT NULL,
+    file_name VARCHAR(255) NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    expires_at DATETIME NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    reviewed_by VARCHAR(64) NULL,
+    review_note VARCHAR(500) NULL,
+    reviewed_at DATETIME NULL,
+    uploaded_at DATETIME NOT NULL,
+    INDEX idx_driver_documents_driver_id (driver_id),
+    INDEX idx_document_status_expires (status, expires_at)
+);
@@ -0,0 +1,27 @@
+-- 车辆登记：司机登记车辆并经后台核验，报价须使用已核验的车辆，车型取自车辆等级；订单记录接送车辆
+
+CREATE TABLE IF NOT EXISTS vehicles (
+    id VARCHAR(64) NOT NULL PRIMARY KEY,
+    driver_id VARCHAR(64) NOT NULL,
+    plate VARCHAR(16) NOT NULL,
+    make VARCHAR(50) NOT NULL,
+    model VARCHAR(50) NOT NULL,
+    colour VARCHAR(30) NOT NULL,
+    class VARCHAR(50) NOT NULL,
+    seats INT NOT NULL,
+    luggage_capacity INT NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    verified_by VARCHAR(64) NULL,
+    review_note VARCHAR(500) NULL,
+    reviewed_at DATETIME NULL,
+    created_at DATETIME NOT NULL,
+    INDEX idx_vehicles_driver_id (driver_id),
+    INDEX idx_vehicles_plate (plate),
+    INDEX idx_vehicles_status (status)
+);
+
+ALTER TABLE driver_offers
+    ADD COLUMN vehicle_id VARCHAR(64) NOT NULL DEFAULT '';
+
+ALTER TABLE bookings
+    ADD COLUMN vehicle_id VARCHAR(64) NOT NULL DEFAULT '';
@@ -0,0 +1,32 @@
+-- 乘客资料与支付方式：乘客的联系方式与偏好语言；卡号由支付方式保险库令牌化，平台只保存令牌与展示信息；
+-- 接机请求与订单记录所选支付方式，结算 saga 记录扣款所用的令牌
+
+ALTER TABLE passengers
+    ADD COLUMN phone VARCHAR(20) NOT NULL DEFAULT '',
+    ADD COLUMN email VARCHAR(254) NOT NULL DEFAULT '',
+    ADD COLUMN preferred_language VARCHAR(35) NOT NULL DEFAULT '',
+    ADD COLUMN default_payment_method_id VARCHAR(64) NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS payment_methods (
+    id VARCHAR(64) NOT NULL PRIMARY KEY,
+    passenger_id VARCHAR(64) NOT NULL,
+    token VARCHAR(128) NOT NULL,
+    brand VARCHAR(20) NOT NULL,
+    last4 VARCHAR(4) NOT NULL,
+    exp_month INT NOT NULL,
+    exp_year INT NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    created_at DATETIME NOT NULL,
+    removed_at DATETIME NULL,
+    UNIQUE INDEX idx_payment_methods_token (token),
+    INDEX idx_payment_methods_passenger_id (passenger_id)
+);
+
+ALTER TABLE pickup_requests
+    ADD COLUMN payment_method_id VARCHAR(64) NOT NULL DEFAULT '';
+
+ALTER TABLE bookings
+    ADD COLUMN payment_method_id VARCHAR(64) NOT NULL DEFAULT '';
+
+ALTER TABLE settlement_sagas
+    ADD COLUMN payment_method VARCHAR(128) NOT NULL DEFAULT '';
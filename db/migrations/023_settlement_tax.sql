@@ -0,0 +1,11 @@
+-- 代收税费：税费不再计入平台收入，单独记在结算记录、车费明细与结算 saga 上，
+-- 记账时贷记应交税费（tax_payable）。已有记录的税费仍包含在平台收入中，不做回填
+
+ALTER TABLE settlement_records
+    ADD COLUMN tax_cents BIGINT NOT NULL DEFAULT 0 AFTER platform_revenue_cents;
+
+ALTER TABLE settlement_fare_items
+    ADD COLUMN tax_cents BIGINT NOT NULL DEFAULT 0 AFTER platform_cents;
+
+ALTER TABLE settlement_sagas
+    ADD COLUMN tax_cents BIGINT NOT NULL DEFAULT 0 AFTER platform_revenue_cents;
@@ -0,0 +1,197 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gavin/airport-pickup/internal/app/dto"
+	"github.com/gavin/airport-pickup/internal/domain/money"
+
+	order "github.com/gavin/airport-pickup/internal/domain/order"
+	settlement "github.com/gavin/airport-pickup/internal/domain/settlement"
+	settlemententity "github.com/gavin/airport-pickup/internal/domain/settlement/entity"
+	settlesvc "github.com/gavin/airport-pickup/internal/domain/settlement/service"
+)
+
+// 收入报表查询区间（天）
+const (
+	defaultAnalyticsDays = 30
+	maxAnalyticsDays     = 366 * 3
+)
+
+// catchUpBatchSize 回填时每批处理的结算记录数。
+const catchUpBatchSize = 500
+
+// AnalyticsAppService 维护收入统计聚合表并提供收入报表查询。
+// 聚合表由 SettlementCreated / RevenueUpdated 事件增量更新，每条结算记录只计入一次；
+// 报表查询只读聚合表，不扫描结算记录。
+type AnalyticsAppService struct {
+	repo      settlement.RevenueStatsRepository
+	orderRepo order.OrderRepository
+
+	statsService *settlesvc.RevenueStatsService
+}
+
+func NewAnalyticsAppService(repo settlement.RevenueStatsRepository, orderRepo order.OrderRepository) *AnalyticsAppService {
+	return &AnalyticsAppService{
+		repo:         repo,
+		orderRepo:    orderRepo,
+		statsService: settlesvc.NewRevenueStatsService(),
+	}
+}
+
+// ProjectBooking 把订单尚未计入统计的结算记录（含退款冲减）计入聚合表；可重复执行。
+func (s *AnalyticsAppService) ProjectBooking(bookingID string) error {
+	records, err := s.repo.ListSettlementRecordsByBooking(bookingID)
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		return nil
+	}
+	facts, err := s.buildFacts(records, map[string][2]string{})
+	if err != nil {
+		return err
+	}
+	_, err = s.repo.ApplyRevenueFacts(facts)
+	return err
+}
+
+// CatchUp 计入所有尚未计入统计的结算记录，用于首次上线回填及补齐事件丢失造成的缺口。
+func (s *AnalyticsAppService) CatchUp() (int, error) {
+	total := 0
+	dims := map[string][2]string{}
+	for {
+		records, err := s.repo.ListUnprojectedSettlementRecords(catchUpBatchSize)
+		if err != nil || len(records) == 0 {
+			return total, err
+		}
+		facts, err := s.buildFacts(records, dims)
+		if err != nil {
+			return total, err
+		}
+		n, err := s.repo.ApplyRevenueFacts(facts)
+		total += n
+		if err != nil || len(records) < catchUpBatchSize {
+			return total, err
+		}
+	}
+}
+
+// RevenueAnalytics 按时间粒度与维度汇总订单总额、平台收入、抽成比例与单量。
+func (s *AnalyticsAppService) RevenueAnalytics(q dto.RevenueAnalyticsQuery) (dto.RevenueAnalyticsDTO, error) {
+	granularity := q.Granularity
+	if granularity == "" {
+		granularity = settlemententity.GranularityDay
+	}
+	from, to, err := analyticsRange(q.From, q.To)
+	if err != nil {
+		return dto.RevenueAnalyticsDTO{}, err
+	}
+	daily, err := s.repo.ListDailyRevenueStats(settlement.RevenueStatsFilter{
+		From: from, To: to,
+		AirportCode: strings.ToUpper(strings.TrimSpace(q.AirportCode)),
+		VehicleType: strings.TrimSpace(q.VehicleType),
+		Currency:    money.NormalizeCurrency(q.Currency),
+	})
+	if err != nil {
+		return dto.RevenueAnalyticsDTO{}, err
+	}
+	rows, err := s.statsService.Rollup(daily, granularity, q.GroupBy)
+	if err != nil {
+		return dto.RevenueAnalyticsDTO{}, err
+	}
+	res := dto.RevenueAnalyticsDTO{
+		Granularity: granularity,
+		From:        from.Format("2006-01-02"),
+		To:          to.AddDate(0, 0, -1).Format("2006-01-02"),
+		Rows:        make([]dto.RevenueStatDTO, 0, len(rows)),
+	}
+	var currencies []string
+	totals := make(map[string]*settlemententity.RevenueStat)
+	for _, r := range rows {
+		res.Rows = append(res.Rows, toRevenueStatDTO(r))
+		t, ok := totals[r.Currency]
+		if !ok {
+			t = &settlemententity.RevenueStat{Period: from, Currency: r.Currency}
+			totals[r.Currency] = t
+			currencies = append(currencies, r.Currency)
+		}
+		t.Add(r)
+	}
+	sort.Strings(currencies)
+	res.Totals = make([]dto.RevenueStatDTO, 0, len(currencies))
+	for _, c := range currencies {
+		res.Totals = append(res.Totals, toRevenueStatDTO(*totals[c]))
+	}
+	return res, nil
+}
+
+// buildFacts 按订单查询机场与车型；dims 缓存订单 ID -> [机场, 车型]。
+func (s *AnalyticsAppService) buildFacts(records []*settlemententity.SettlementRecord, dims map[string][2]string) ([]*settlemententity.RevenueFact, error) {
+	facts := make([]*settlemententity.RevenueFact, 0, len(records))
+	for _, r := range records {
+		d, ok := dims[r.BookingID]
+		if !ok {
+			b, err := s.orderRepo.GetBookingByID(r.BookingID)
+			if err != nil {
+				return nil, fmt.Errorf("get booking %s: %w", r.BookingID, err)
+			}
+			req, err := s.orderRepo.GetPickupRequestByID(b.RequestID)
+			if err != nil {
+				return nil, fmt.Errorf("get pickup request %s: %w", b.RequestID, err)
+			}
+			d = [2]string{b.AirportCode, req.VehicleType}
+			if d[0] == "" {
+				d[0] = req.AirportCode
+			}
+			dims[r.BookingID] = d
+		}
+		f, err := s.statsService.BuildFact(r, d[0], d[1])
+		if err != nil {
+			return nil, err
+		}
+		facts = append(facts, f)
+	}
+	return facts, nil
+}
+
+// analyticsRange 返回 [from, to)，from 与 to 为 UTC 日期且均包含在内。
+func analyticsRange(from, to string) (time.Time, time.Time, error) {
+	now := time.Now().UTC()
+	end := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1)
+	if to != "" {
+		t, err := time.Parse("2006-01-02", to)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("to must be YYYY-MM-DD")
+		}
+		end = t.AddDate(0, 0, 1)
+	}
+	start := end.AddDate(0, 0, -defaultAnalyticsDays)
+	if from != "" {
+		t, err := time.Parse("2006-01-02", from)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("from must be YYYY-MM-DD")
+		}
+		start = t
+	}
+	if !start.Before(end) {
+		return time.Time{}, time.Time{}, errors.New("from must not be after to")
+	}
+	if end.Sub(start) > maxAnalyticsDays*24*time.Hour {
+		return time.Time{}, time.Time{}, fmt.Errorf("date range must not exceed %d days", maxAnalyticsDays)
+	}
+	return start, end, nil
+}
+
+func toRevenueStatDTO(r settlemententity.RevenueStat) dto.RevenueStatDTO {
+	return dto.RevenueStatDTO{
+		Period: r.Period.Format("2006-01-02"), AirportCode: r.AirportCode, VehicleType: r.VehicleType, Currency: r.Currency,
+		Trips: r.Trips, GrossCents: r.GrossCents, RevenueCents: r.RevenueCents,
+		TakeRate: math.Round(r.TakeRate()*10000) / 10000,
+	}
+}
@@ -1,33 +1,509 @@
 package dto
 
+import "github.com/gavin/airport-pickup/internal/domain/money"
+
 // CreatePickupRequestInput represents passenger's pickup request creation input.
 type CreatePickupRequestInput struct {
-	PassengerID      string  `json:"passenger_id"`
-	AirportCode      string  `json:"airport_code"`
-	VehicleType      string  `json:"vehicle_type"`
-	DesiredTime      string  `json:"desired_time"` // RFC3339
-	MaxPricePerKm    float64 `json:"max_price_per_km"`
-	PreferHighRating bool    `json:"prefer_high_rating"`
+	PassengerID      string      `json:"passenger_id"`
+	AirportCode      string      `json:"airport_code"`
+	VehicleType      string      `json:"vehicle_type"`
+	DesiredTime      string      `json:"desired_time"` // RFC3339
+	MaxPricePerKm    money.Money `json:"max_price_per_km"`
+	Currency         string      `json:"currency"` // 可选，须与机场结算币种一致
+	PreferHighRating bool        `json:"prefer_high_rating"`
+	PromoCode        string      `json:"promo_code"`        // 可选，结算时抵扣
+	QuoteID          string      `json:"quote_id"`          // 可选，沿用报价的出价与计价策略；未给出的出价与期望时间取自报价
+	PaymentMethodID  string      `json:"payment_method_id"` // 可选，乘客保存的支付方式，为空使用默认支付方式
 }
 
 // CreateDriverOfferInput represents driver offer creation input.
 type CreateDriverOfferInput struct {
-	DriverID      string  `json:"driver_id"`
-	AirportCode   string  `json:"airport_code"`
-	VehicleType   string  `json:"vehicle_type"`
-	AvailableFrom string  `json:"available_from"` // RFC3339
-	AvailableTo   string  `json:"available_to"`   // RFC3339
-	PricePerKm    float64 `json:"price_per_km"`
+	DriverID      string      `json:"driver_id"`
+	AirportCode   string      `json:"airport_code"`
+	VehicleID     string      `json:"vehicle_id"`     // 已核验的车辆，报价车型取自车辆的 class
+	VehicleType   string      `json:"vehicle_type"`   // 可选，须与车辆的 class 一致
+	AvailableFrom string      `json:"available_from"` // RFC3339
+	AvailableTo   string      `json:"available_to"`   // RFC3339
+	PricePerKm    money.Money `json:"price_per_km"`
+	Currency      string      `json:"currency"` // 可选，须与机场结算币种一致
+	// 可选，只接受声誉分不低于该值的乘客，0 表示不限制
+	MinPassengerScore float64 `json:"min_passenger_score"`
+}
+
+// CompleteBookingInput carries the trip details reported when a booking completes; all but ID are optional.
+type CompleteBookingInput struct {
+	ID             string      `json:"id"`
+	DistanceKm     float64     `json:"distance_km"` // 0 表示未上报，按默认里程计费
+	WaitingMinutes int         `json:"waiting_minutes"`
+	Tolls          money.Money `json:"tolls"`
+}
+
+// RateDriverInput 乘客在订单完成后评价司机。
+type RateDriverInput struct {
+	BookingID   string `json:"-"` // 取自路径
+	PassengerID string `json:"passenger_id"`
+	Score       int    `json:"score"` // 1~5 星
+	Comment     string `json:"comment"`
+}
+
+// DriverRatingDTO 评价结果与司机更新后的声誉分。
+type DriverRatingDTO struct {
+	RatingID    string  `json:"rating_id"`
+	BookingID   string  `json:"booking_id"`
+	DriverID    string  `json:"driver_id"`
+	Score       int     `json:"score"`
+	Rating      float64 `json:"driver_rating"`
+	RatingCount int     `json:"driver_rating_count"`
+}
+
+// RatePassengerInput 司机评价订单的乘客。
+type RatePassengerInput struct {
+	BookingID string `json:"-"` // 取自路径
+	DriverID  string `json:"driver_id"`
+	Score     int    `json:"score"` // 1~5 星；标记未到场时可省略，按 1 星计
+	Comment   string `json:"comment"`
+	NoShow    bool   `json:"no_show"` // 乘客未到场，仅限已取消的订单
+}
+
+// PassengerRatingDTO 评价结果与乘客更新后的声誉分。
+type PassengerRatingDTO struct {
+	RatingID        string  `json:"rating_id"`
+	BookingID       string  `json:"booking_id"`
+	PassengerID     string  `json:"passenger_id"`
+	Score           int     `json:"score"`
+	NoShow          bool    `json:"no_show"`
+	ReputationScore float64 `json:"passenger_reputation_score"`
+	RatingCount     int     `json:"passenger_rating_count"`
+	NoShowCount     int     `json:"passenger_no_show_count"`
 }
 
 // BookingDTO is a simplified read model for bookings.
 type BookingDTO struct {
-	ID                  string  `json:"id"`
-	RequestID           string  `json:"request_id"`
-	OfferID             string  `json:"offer_id"`
-	PassengerID         string  `json:"passenger_id"`
-	DriverID            string  `json:"driver_id"`
-	PricePerKm          float64 `json:"price_per_km"`
-	PlatformMarginPerKm float64 `json:"platform_margin_per_km"`
-	Status              string  `json:"status"`
+	ID                  string      `json:"id"`
+	RequestID           string      `json:"request_id"`
+	OfferID             string      `json:"offer_id"`
+	PassengerID         string      `json:"passenger_id"`
+	DriverID            string      `json:"driver_id"`
+	PricePerKm          money.Money `json:"price_per_km"`
+	FarePerKm           money.Money `json:"fare_per_km"`
+	PlatformMarginPerKm money.Money `json:"platform_margin_per_km"`
+	Pricing             *PricingDTO `json:"pricing,omitempty"` // 计价策略上线前的订单为空
+	SurgeMultiplier     float64     `json:"surge_multiplier"`
+	Currency            string      `json:"currency"`
+	Status              string      `json:"status"`
+	Vehicle             *VehicleDTO `json:"vehicle,omitempty"`           // 接送车辆，车辆登记上线前的订单为空
+	PaymentMethodID     string      `json:"payment_method_id,omitempty"` // 结算扣款的支付方式，为空从乘客钱包扣款
+}
+
+// PricingDTO 订单成交时采用的计价策略及参数。
+type PricingDTO struct {
+	Policy           string              `json:"policy"`
+	FeePerKm         money.Money         `json:"fee_per_km,omitempty"`
+	FeeRate          float64             `json:"fee_rate,omitempty"`
+	PlatformShare    float64             `json:"platform_share,omitempty"`
+	CommissionRate   float64             `json:"commission_rate,omitempty"`
+	Tiers            []CommissionTierDTO `json:"tiers,omitempty"`
+	VolumeWindowDays int                 `json:"volume_window_days,omitempty"`
+	DriverTrips      int                 `json:"driver_trips,omitempty"`
+}
+
+type CommissionTierDTO struct {
+	MinTrips int     `json:"min_trips"`
+	Rate     float64 `json:"rate"`
+}
+
+// SurgeDTO 订单簿当前的溢价情况与建议乘客出价。
+type SurgeDTO struct {
+	AirportCode            string       `json:"airport_code"`
+	VehicleType            string       `json:"vehicle_type"`
+	Multiplier             float64      `json:"multiplier"`
+	TargetMultiplier       float64      `json:"target_multiplier"`
+	OpenRequests           int          `json:"open_requests"`
+	OpenOffers             int          `json:"open_offers"`
+	SuggestedMaxPricePerKm *money.Money `json:"suggested_max_price_per_km,omitempty"` // 最低报价 × 当前倍率，尚无报价时为空
+	Currency               string       `json:"currency"`
+	UpdatedAt              string       `json:"updated_at,omitempty"` // RFC3339
+}
+
+// CreateQuoteInput 报价请求；未给出出价时按最低可用报价与当前溢价倍率建议出价。
+type CreateQuoteInput struct {
+	PassengerID   string      `json:"passenger_id"` // 可选，指定后报价只能由该乘客使用
+	AirportCode   string      `json:"airport_code"`
+	VehicleType   string      `json:"vehicle_type"`
+	DesiredTime   string      `json:"desired_time"` // RFC3339
+	DistanceKm    float64     `json:"distance_km"`
+	MaxPricePerKm money.Money `json:"max_price_per_km"` // 可选，估计该出价的成交概率
+	Currency      string      `json:"currency"`         // 可选，须与机场结算币种一致
+}
+
+// QuoteDTO 按实时订单簿的报价，有效期内可用 quote_id 创建接机请求。
+type QuoteDTO struct {
+	QuoteID          string               `json:"quote_id"`
+	AirportCode      string               `json:"airport_code"`
+	VehicleType      string               `json:"vehicle_type"`
+	DesiredTime      string               `json:"desired_time"`
+	DistanceKm       float64              `json:"distance_km"`
+	Currency         string               `json:"currency"`
+	BestOfferPerKm   *money.Money         `json:"best_offer_per_km,omitempty"` // 期望时间没有可用报价时为空
+	Percentiles      *PricePercentilesDTO `json:"percentiles,omitempty"`
+	AvailableOffers  int                  `json:"available_offers"`
+	MaxPricePerKm    money.Money          `json:"max_price_per_km"`
+	MatchProbability float64              `json:"match_probability"`
+	SurgeMultiplier  float64              `json:"surge_multiplier"`
+	FarePerKm        money.Money          `json:"fare_per_km"`
+	EstimatedFare    money.Money          `json:"estimated_fare"`
+	Pricing          *PricingDTO          `json:"pricing,omitempty"`
+	ExpiresAt        string               `json:"expires_at"` // RFC3339
+}
+
+// PricePercentilesDTO 可用司机报价（每公里）的分位数。
+type PricePercentilesDTO struct {
+	P10 money.Money `json:"p10"`
+	P25 money.Money `json:"p25"`
+	P50 money.Money `json:"p50"`
+	P75 money.Money `json:"p75"`
+	P90 money.Money `json:"p90"`
+}
+
+// RefundBookingInput represents a support agent's refund request.
+type RefundBookingInput struct {
+	BookingID   string `json:"booking_id"`
+	AmountCents int64  `json:"amount_cents"` // 0 表示退还全部剩余可退金额
+	ReasonCode  string `json:"reason_code"`
+}
+
+// AccountBalanceDTO is a ledger account balance; BalanceCents follows the account's normal side.
+type AccountBalanceDTO struct {
+	Account      string `json:"account"`
+	Currency     string `json:"currency"`
+	DebitCents   int64  `json:"debit_cents"`
+	CreditCents  int64  `json:"credit_cents"`
+	BalanceCents int64  `json:"balance_cents"`
+}
+
+// RevenueReportDTO is platform revenue converted into the reporting currency.
+type RevenueReportDTO struct {
+	ReportingCurrency string               `json:"reporting_currency"`
+	TotalCents        int64                `json:"total_cents"`
+	ByCurrency        []CurrencyRevenueDTO `json:"by_currency"`
+}
+
+// CurrencyRevenueDTO is the revenue collected in one settlement currency.
+type CurrencyRevenueDTO struct {
+	Currency       string  `json:"currency"`
+	AmountCents    int64   `json:"amount_cents"`
+	Rate           float64 `json:"rate"`
+	ConvertedCents int64   `json:"converted_cents"`
+}
+
+// LedgerCheckDTO reports the double-entry invariant check.
+type LedgerCheckDTO struct {
+	DebitCents        int64    `json:"debit_cents"`
+	CreditCents       int64    `json:"credit_cents"`
+	Balanced          bool     `json:"balanced"`
+	UnbalancedEntries []string `json:"unbalanced_entries"`
+}
+
+// PayoutDTO is a driver payout summary.
+type PayoutDTO struct {
+	ID              string `json:"id"`
+	DriverID        string `json:"driver_id"`
+	Status          string `json:"status"`
+	GrossCents      int64  `json:"gross_cents"`
+	CommissionCents int64  `json:"commission_cents"`
+	FeeCents        int64  `json:"fee_cents"`
+	NetCents        int64  `json:"net_cents"`
+	Currency        string `json:"currency"`
+	ProviderRef     string `json:"provider_ref,omitempty"`
+	FailureReason   string `json:"failure_reason,omitempty"`
+	CreatedAt       string `json:"created_at"` // RFC3339
+}
+
+// PayoutRunDTO summarizes one payout run.
+type PayoutRunDTO struct {
+	Paid    int         `json:"paid"`
+	Failed  int         `json:"failed"`
+	Pending int         `json:"pending"` // 打款结果未知，下一轮重试
+	Payouts []PayoutDTO `json:"payouts"`
+}
+
+// CreatePassengerInput creates a passenger; CorporateAccount links the passenger to a corporate client for monthly invoicing.
+type CreatePassengerInput struct {
+	Name              string `json:"name"`
+	CorporateAccount  string `json:"corporate_account"`
+	Phone             string `json:"phone"`              // 可选，E.164 格式
+	Email             string `json:"email"`              // 可选
+	PreferredLanguage string `json:"preferred_language"` // 可选，BCP 47 语言标签，如 zh-CN
+}
+
+// GenerateInvoicesInput issues the monthly invoices of a corporate client.
+type GenerateInvoicesInput struct {
+	CorporateAccount string `json:"corporate_account"`
+	Month            string `json:"month"` // YYYY-MM（UTC）
+}
+
+// InvoiceDTO is an issued receipt or corporate invoice without its lines.
+type InvoiceDTO struct {
+	ID               string `json:"id"`
+	Number           string `json:"number"`
+	Kind             string `json:"kind"` // receipt, corporate
+	LegalEntity      string `json:"legal_entity"`
+	Currency         string `json:"currency"`
+	BookingID        string `json:"booking_id,omitempty"`
+	CorporateAccount string `json:"corporate_account,omitempty"`
+	Period           string `json:"period,omitempty"` // YYYY-MM
+	TotalCents       int64  `json:"total_cents"`
+	TaxCents         int64  `json:"tax_cents"`
+	IssuedAt         string `json:"issued_at"` // RFC3339
+}
+
+// InvoiceRunDTO summarizes one monthly invoicing run.
+type InvoiceRunDTO struct {
+	Period   string       `json:"period"`
+	Invoices []InvoiceDTO `json:"invoices"` // 本轮新开具的发票
+}
+
+// ImportStatementInput imports a wallet provider statement file for reconciliation.
+type ImportStatementInput struct {
+	Source  string // 文件名
+	Format  string // csv, json
+	From    string // 账期开始日期 YYYY-MM-DD（UTC），为空时取对账单最早结算日
+	To      string // 账期结束日期（含），为空时取对账单最晚结算日
+	Content []byte
+}
+
+// ReconciliationReportDTO is a reconciliation report; OpenExceptions and Exceptions are omitted in list responses.
+type ReconciliationReportDTO struct {
+	ID               string                       `json:"id"`
+	Source           string                       `json:"source"`
+	Status           string                       `json:"status"` // open, reconciled
+	PeriodStart      string                       `json:"period_start"`
+	PeriodEnd        string                       `json:"period_end"` // 不含
+	StatementLines   int                          `json:"statement_lines"`
+	Transactions     int                          `json:"transactions"`
+	MatchedLines     int                          `json:"matched_lines"`
+	StatementCents   int64                        `json:"statement_cents"`
+	TransactionCents int64                        `json:"transaction_cents"`
+	OpenExceptions   int                          `json:"open_exceptions,omitempty"`
+	Exceptions       []ReconciliationExceptionDTO `json:"exceptions,omitempty"`
+	CreatedAt        string                       `json:"created_at"` // RFC3339
+}
+
+// ReconciliationExceptionDTO is one mismatch between the statement and the payment ledger.
+type ReconciliationExceptionDTO struct {
+	ID              string `json:"id"`
+	Kind            string `json:"kind"` // missing_in_statement, missing_in_ledger, duplicate, amount_mismatch
+	BookingID       string `json:"booking_id"`
+	Type            string `json:"type"` // payment, refund
+	TransactionID   string `json:"transaction_id,omitempty"`
+	StatementRef    string `json:"statement_ref,omitempty"`
+	StatementLineNo int    `json:"statement_line_no,omitempty"`
+	ExpectedCents   int64  `json:"expected_cents"`
+	StatementCents  int64  `json:"statement_cents"`
+	Currency        string `json:"currency"`
+	Status          string `json:"status"` // open, resolved
+	Resolution      string `json:"resolution,omitempty"`
+	Note            string `json:"note,omitempty"`
+	ResolvedAt      string `json:"resolved_at,omitempty"`
+}
+
+// ResolveExceptionInput closes a reconciliation exception.
+type ResolveExceptionInput struct {
+	Resolution string `json:"resolution"` // write_off, provider_corrected, ledger_corrected, false_positive
+	Note       string `json:"note"`
+}
+
+// RevenueAnalyticsQuery filters and groups the revenue analytics report.
+type RevenueAnalyticsQuery struct {
+	Granularity string   // day（默认）, week, month
+	GroupBy     []string // airport, vehicle_type；币种始终参与分组
+	From        string   // YYYY-MM-DD（UTC），默认为 To 之前 30 天
+	To          string   // YYYY-MM-DD（含），默认为今天
+	AirportCode string
+	VehicleType string
+	Currency    string
+}
+
+// RevenueStatDTO is one row of the revenue analytics report.
+type RevenueStatDTO struct {
+	Period       string  `json:"period"` // 时间段起始日 YYYY-MM-DD
+	AirportCode  string  `json:"airport_code,omitempty"`
+	VehicleType  string  `json:"vehicle_type,omitempty"`
+	Currency     string  `json:"currency"`
+	Trips        int64   `json:"trips"`
+	GrossCents   int64   `json:"gross_cents"`   // 订单总额（GBV），已扣除退款
+	RevenueCents int64   `json:"revenue_cents"` // 平台收入
+	TakeRate     float64 `json:"take_rate"`     // revenue_cents / gross_cents
+}
+
+// RevenueAnalyticsDTO is the revenue analytics report with per-currency totals.
+type RevenueAnalyticsDTO struct {
+	Granularity string           `json:"granularity"`
+	From        string           `json:"from"`
+	To          string           `json:"to"`
+	Rows        []RevenueStatDTO `json:"rows"`
+	Totals      []RevenueStatDTO `json:"totals"` // 按币种合计，period 为 from
+}
+
+// CreatePromotionInput creates a promo code campaign; limits and budget of 0 mean unlimited.
+type CreatePromotionInput struct {
+	Code              string   `json:"code"`
+	Description       string   `json:"description"`
+	Kind              string   `json:"kind"`               // percentage, fixed
+	Rate              float64  `json:"rate"`               // percentage：折扣比例 (0, 1]
+	AmountCents       int64    `json:"amount_cents"`       // fixed：立减金额
+	MaxDiscountCents  int64    `json:"max_discount_cents"` // percentage：单次优惠上限
+	Currency          string   `json:"currency"`
+	AirportCodes      []string `json:"airport_codes"` // 为空表示所有机场
+	StartsAt          string   `json:"starts_at"`     // RFC3339
+	EndsAt            string   `json:"ends_at"`       // RFC3339，不含
+	PerPassengerLimit int      `json:"per_passenger_limit"`
+	TotalLimit        int      `json:"total_limit"`
+	BudgetCents       int64    `json:"budget_cents"`
+}
+
+// PromotionDTO is a promo code campaign with its redemption totals.
+type PromotionDTO struct {
+	Code              string   `json:"code"`
+	Description       string   `json:"description,omitempty"`
+	Kind              string   `json:"kind"`
+	Rate              float64  `json:"rate,omitempty"`
+	AmountCents       int64    `json:"amount_cents,omitempty"`
+	MaxDiscountCents  int64    `json:"max_discount_cents,omitempty"`
+	Currency          string   `json:"currency"`
+	AirportCodes      []string `json:"airport_codes"`
+	StartsAt          string   `json:"starts_at"`
+	EndsAt            string   `json:"ends_at"`
+	PerPassengerLimit int      `json:"per_passenger_limit"`
+	TotalLimit        int      `json:"total_limit"`
+	BudgetCents       int64    `json:"budget_cents"`
+	RedeemedCount     int      `json:"redeemed_count"`
+	SpentCents        int64    `json:"spent_cents"`
+	RemainingCents    int64    `json:"remaining_cents,omitempty"` // 剩余预算，不限预算时省略
+	CreatedAt         string   `json:"created_at"`
+}
+
+// UploadDriverDocumentInput is a driver document file uploaded for review.
+type UploadDriverDocumentInput struct {
+	DriverID  string
+	Type      string // licence, insurance, vehicle_registration
+	ExpiresAt string // RFC3339，或 YYYY-MM-DD 表示当天（UTC）结束时到期
+	FileName  string
+	Content   []byte
+}
+
+// DriverDocumentDTO is an uploaded driver document without its file content.
+type DriverDocumentDTO struct {
+	ID          string `json:"id"`
+	DriverID    string `json:"driver_id"`
+	Type        string `json:"type"`
+	FileName    string `json:"file_name"`
+	ContentType string `json:"content_type"`
+	SizeBytes   int64  `json:"size_bytes"`
+	ExpiresAt   string `json:"expires_at"`
+	Status      string `json:"status"` // pending_review, approved, rejected
+	ReviewedBy  string `json:"reviewed_by,omitempty"`
+	ReviewNote  string `json:"review_note,omitempty"`
+	ReviewedAt  string `json:"reviewed_at,omitempty"`
+	UploadedAt  string `json:"uploaded_at"`
+}
+
+// ReviewDocumentInput is an admin's decision on a document in the review queue.
+type ReviewDocumentInput struct {
+	Decision string `json:"decision"` // approve, reject
+	Reviewer string `json:"reviewer"`
+	Note     string `json:"note"` // 驳回时必填
+}
+
+// UpdateDriverStatusInput is an admin's change to a driver's account status.
+type UpdateDriverStatusInput struct {
+	Status string `json:"status"` // active, suspended, offboarded
+	Reason string `json:"reason"` // 暂停时必填
+}
+
+// DriverOnboardingDTO is a driver's account status with their documents.
+type DriverOnboardingDTO struct {
+	DriverID         string              `json:"driver_id"`
+	Status           string              `json:"status"`
+	StatusReason     string              `json:"status_reason,omitempty"`
+	MissingDocuments []string            `json:"missing_documents"` // 激活所需、尚无有效审核通过证件的类型
+	Documents        []DriverDocumentDTO `json:"documents"`
+}
+
+// RegisterVehicleInput registers a vehicle for a driver; it serves offers once verified.
+type RegisterVehicleInput struct {
+	Plate           string `json:"plate"`
+	Make            string `json:"make"`
+	Model           string `json:"model"`
+	Colour          string `json:"colour"`
+	Class           string `json:"class"` // 即报价与接机请求的 vehicle_type，如 sedan
+	Seats           int    `json:"seats"`
+	LuggageCapacity int    `json:"luggage_capacity"`
+}
+
+// VehicleDTO is a registered vehicle; bookings show it so passengers know what car to look for.
+type VehicleDTO struct {
+	ID              string `json:"id"`
+	DriverID        string `json:"driver_id"`
+	Plate           string `json:"plate"`
+	Make            string `json:"make"`
+	Model           string `json:"model"`
+	Colour          string `json:"colour"`
+	Class           string `json:"class"`
+	Seats           int    `json:"seats"`
+	LuggageCapacity int    `json:"luggage_capacity"`
+	Status          string `json:"status"` // pending_verification, verified, rejected
+	VerifiedBy      string `json:"verified_by,omitempty"`
+	ReviewNote      string `json:"review_note,omitempty"`
+	ReviewedAt      string `json:"reviewed_at,omitempty"`
+	CreatedAt       string `json:"created_at"`
+}
+
+// VerifyVehicleInput is an admin's decision on a vehicle awaiting verification.
+type VerifyVehicleInput struct {
+	Decision string `json:"decision"` // approve, reject
+	Reviewer string `json:"reviewer"`
+	Note     string `json:"note"` // 驳回时必填
+}
+
+// UpdatePassengerInput changes a passenger's profile; omitted fields are unchanged and empty strings clear them.
+type UpdatePassengerInput struct {
+	Name              *string `json:"name"`
+	Phone             *string `json:"phone"`
+	Email             *string `json:"email"`
+	PreferredLanguage *string `json:"preferred_language"`
+}
+
+// PassengerDTO is a passenger's profile with their saved payment methods.
+type PassengerDTO struct {
+	ID                     string             `json:"id"`
+	Name                   string             `json:"name"`
+	CorporateAccount       string             `json:"corporate_account,omitempty"`
+	Phone                  string             `json:"phone,omitempty"`
+	Email                  string             `json:"email,omitempty"`
+	PreferredLanguage      string             `json:"preferred_language,omitempty"`
+	ReputationScore        float64            `json:"reputation_score"`
+	RatingCount            int                `json:"rating_count"`
+	DefaultPaymentMethodID string             `json:"default_payment_method_id,omitempty"` // 为空时从乘客钱包扣款
+	PaymentMethods         []PaymentMethodDTO `json:"payment_methods"`                     // 未删除的支付方式
+}
+
+// AddPaymentMethodInput is a card to save for a passenger. The client tokenizes the card with the vault;
+// the platform only ever receives the token.
+type AddPaymentMethodInput struct {
+	Token       string `json:"token"`        // 保险库签发的令牌
+	MakeDefault bool   `json:"make_default"` // 第一个支付方式总是设为默认
+}
+
+// PaymentMethodDTO is a saved payment method showing only the card brand, last four digits and expiry.
+type PaymentMethodDTO struct {
+	ID        string `json:"id"`
+	Brand     string `json:"brand"`
+	Last4     string `json:"last4"`
+	ExpMonth  int    `json:"exp_month"`
+	ExpYear   int    `json:"exp_year"`
+	Status    string `json:"status"` // active, removed
+	Expired   bool   `json:"expired"`
+	Default   bool   `json:"default"`
+	CreatedAt string `json:"created_at"`
 }
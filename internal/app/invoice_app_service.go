@@ -0,0 +1,283 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gavin/airport-pickup/internal/app/dto"
+	"github.com/gavin/airport-pickup/pkg/documents"
+	"github.com/gavin/airport-pickup/pkg/util"
+
+	order "github.com/gavin/airport-pickup/internal/domain/order"
+	settlement "github.com/gavin/airport-pickup/internal/domain/settlement"
+	settlemententity "github.com/gavin/airport-pickup/internal/domain/settlement/entity"
+	settlesvc "github.com/gavin/airport-pickup/internal/domain/settlement/service"
+)
+
+// 单据下载格式
+const (
+	DocumentHTML = "html"
+	DocumentPDF  = "pdf"
+)
+
+// defaultLegalEntity 未配置开票主体时使用。
+var defaultLegalEntity = settlemententity.LegalEntity{Code: "AP", Name: "Airport Pickup"}
+
+// InvoiceAppService 开具并渲染乘客收据与企业客户月度发票，内容取自已落库的结算记录与支付流水。
+// 开票主体按订单所在机场确定，同一主体下的单据编号连续。
+type InvoiceAppService struct {
+	repo           settlement.InvoiceRepository
+	settlementRepo settlement.SettlementRepository
+	orderRepo      order.OrderRepository
+	renderer       *documents.Renderer
+
+	invoiceService  *settlesvc.InvoiceService
+	defaultEntity   settlemententity.LegalEntity
+	airportEntities map[string]settlemententity.LegalEntity // 机场代码 -> 开票主体
+	mu              sync.Mutex                              // 同一进程内的月度开票任务串行执行
+}
+
+func NewInvoiceAppService(repo settlement.InvoiceRepository, settlementRepo settlement.SettlementRepository, orderRepo order.OrderRepository, renderer *documents.Renderer) *InvoiceAppService {
+	return &InvoiceAppService{
+		repo:            repo,
+		settlementRepo:  settlementRepo,
+		orderRepo:       orderRepo,
+		renderer:        renderer,
+		invoiceService:  settlesvc.NewInvoiceService(),
+		defaultEntity:   defaultLegalEntity,
+		airportEntities: map[string]settlemententity.LegalEntity{},
+	}
+}
+
+// WithLegalEntities 设置默认开票主体及各机场的开票主体。
+func (s *InvoiceAppService) WithLegalEntities(defaultEntity settlemententity.LegalEntity, byAirport map[string]settlemententity.LegalEntity) *InvoiceAppService {
+	if defaultEntity.Code != "" {
+		s.defaultEntity = defaultEntity
+	}
+	for code, e := range byAirport {
+		s.airportEntities[strings.ToUpper(code)] = e
+	}
+	return s
+}
+
+// Receipt 返回订单收据。收据在首次下载时开具并保存，之后按保存的内容渲染，编号不变。
+func (s *InvoiceAppService) Receipt(bookingID, format string) (filename string, content []byte, err error) {
+	inv, err := s.repo.GetReceiptByBookingID(bookingID)
+	if err != nil {
+		return "", nil, err
+	}
+	if inv == nil {
+		if inv, err = s.issueReceipt(bookingID); err != nil {
+			return "", nil, err
+		}
+	}
+	return s.render(inv, format)
+}
+
+// InvoiceDocument 渲染已开具的单据。
+func (s *InvoiceAppService) InvoiceDocument(id, format string) (filename string, content []byte, err error) {
+	inv, err := s.repo.GetInvoice(id)
+	if err != nil {
+		return "", nil, err
+	}
+	if inv == nil {
+		return "", nil, errors.New("invoice not found")
+	}
+	return s.render(inv, format)
+}
+
+// ListCorporateInvoices 按账期倒序返回企业客户的发票。
+func (s *InvoiceAppService) ListCorporateInvoices(corporateAccount string) ([]dto.InvoiceDTO, error) {
+	if corporateAccount == "" {
+		return nil, errors.New("corporate_account required")
+	}
+	list, err := s.repo.ListCorporateInvoices(corporateAccount)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]dto.InvoiceDTO, 0, len(list))
+	for _, inv := range list {
+		res = append(res, toInvoiceDTO(inv))
+	}
+	return res, nil
+}
+
+// GenerateCorporateInvoices 为企业客户开具指定月份（UTC）的发票，按开票主体与币种各一张；
+// 已开具的不会重复开具。返回该月的全部发票。
+func (s *InvoiceAppService) GenerateCorporateInvoices(in dto.GenerateInvoicesInput) ([]dto.InvoiceDTO, error) {
+	if in.CorporateAccount == "" {
+		return nil, errors.New("corporate_account required")
+	}
+	start, err := time.Parse("2006-01", in.Month)
+	if err != nil {
+		return nil, errors.New("month must be YYYY-MM")
+	}
+	if start.AddDate(0, 1, 0).After(time.Now()) {
+		return nil, errors.New("invoice period not closed")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.issueCorporateInvoices(in.CorporateAccount, start); err != nil {
+		return nil, err
+	}
+	list, err := s.ListCorporateInvoices(in.CorporateAccount)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]dto.InvoiceDTO, 0)
+	for _, d := range list {
+		if d.Period == in.Month {
+			res = append(res, d)
+		}
+	}
+	return res, nil
+}
+
+// RunMonthlyInvoices 为所有企业客户开具上一个自然月（UTC）的发票；已开具的跳过，可重复执行。
+func (s *InvoiceAppService) RunMonthlyInvoices() (dto.InvoiceRunDTO, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now().UTC()
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -1, 0)
+	res := dto.InvoiceRunDTO{Period: start.Format("2006-01"), Invoices: []dto.InvoiceDTO{}}
+	accounts, err := s.repo.ListCorporateAccounts()
+	if err != nil {
+		return res, err
+	}
+	var errs []error
+	for _, account := range accounts {
+		issued, err := s.issueCorporateInvoices(account, start)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("corporate account %s: %w", account, err))
+		}
+		for _, inv := range issued {
+			res.Invoices = append(res.Invoices, toInvoiceDTO(inv))
+		}
+	}
+	return res, errors.Join(errs...)
+}
+
+func (s *InvoiceAppService) issueReceipt(bookingID string) (*settlemententity.Invoice, error) {
+	records, err := s.repo.ListSettlementRecordsByBooking(bookingID)
+	if err != nil {
+		return nil, err
+	}
+	var record *settlemententity.SettlementRecord
+	for _, r := range records {
+		if r.AmountCents >= 0 {
+			record = r
+			break
+		}
+	}
+	if record == nil {
+		return nil, errors.New("booking not settled")
+	}
+	payment, err := s.settlementRepo.GetPaymentTransactionByBookingID(bookingID)
+	if err != nil {
+		return nil, err
+	}
+	refunds, err := s.settlementRepo.ListRefundTransactions(bookingID)
+	if err != nil {
+		return nil, err
+	}
+	inv, err := s.invoiceService.BuildReceipt(&settlesvc.BuildReceiptCmd{
+		Issuer: s.legalEntity(bookingID), Record: record, Payment: payment, Refunds: refunds,
+	})
+	if err != nil {
+		return nil, err
+	}
+	inv.ID = util.NewID()
+	if err := s.repo.CreateInvoice(inv); err != nil {
+		if errors.Is(err, settlement.ErrInvoiceExists) {
+			// 并发请求已开具
+			return s.repo.GetReceiptByBookingID(bookingID)
+		}
+		return nil, err
+	}
+	log.Printf("[invoice] receipt %s issued booking=%s", inv.Number, bookingID)
+	return inv, nil
+}
+
+// issueCorporateInvoices 按开票主体与币种分组开具账期内的发票，返回新开具的发票。
+func (s *InvoiceAppService) issueCorporateInvoices(account string, start time.Time) ([]*settlemententity.Invoice, error) {
+	end := start.AddDate(0, 1, 0)
+	records, err := s.repo.ListCorporateSettlementRecords(account, start, end)
+	if err != nil {
+		return nil, err
+	}
+	type invoiceKey struct{ legalEntity, currency string }
+	var keys []invoiceKey
+	issuers := make(map[string]settlemententity.LegalEntity)
+	byKey := make(map[invoiceKey][]*settlemententity.SettlementRecord)
+	for _, r := range records {
+		e := s.legalEntity(r.BookingID)
+		issuers[e.Code] = e
+		k := invoiceKey{e.Code, r.Currency}
+		if _, ok := byKey[k]; !ok {
+			keys = append(keys, k)
+		}
+		byKey[k] = append(byKey[k], r)
+	}
+	var issued []*settlemententity.Invoice
+	var errs []error
+	for _, k := range keys {
+		inv, err := s.invoiceService.BuildCorporateInvoice(&settlesvc.BuildCorporateInvoiceCmd{
+			Issuer: issuers[k.legalEntity], CorporateAccount: account, PeriodStart: start, PeriodEnd: end, Records: byKey[k],
+		})
+		if err == nil {
+			inv.ID = util.NewID()
+			err = s.repo.CreateInvoice(inv)
+		}
+		if errors.Is(err, settlement.ErrInvoiceExists) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s/%s: %w", k.legalEntity, k.currency, err))
+			continue
+		}
+		log.Printf("[invoice] invoice %s issued corporate_account=%s period=%s", inv.Number, account, start.Format("2006-01"))
+		issued = append(issued, inv)
+	}
+	return issued, errors.Join(errs...)
+}
+
+// legalEntity 按订单所在机场确定开票主体；未配置的机场与早期无机场代码的订单使用默认主体。
+func (s *InvoiceAppService) legalEntity(bookingID string) settlemententity.LegalEntity {
+	b, err := s.orderRepo.GetBookingByID(bookingID)
+	if err != nil || b == nil {
+		return s.defaultEntity
+	}
+	if e, ok := s.airportEntities[strings.ToUpper(b.AirportCode)]; ok {
+		return e
+	}
+	return s.defaultEntity
+}
+
+func (s *InvoiceAppService) render(inv *settlemententity.Invoice, format string) (string, []byte, error) {
+	name := inv.Kind + "-" + inv.Number
+	switch format {
+	case DocumentHTML, "":
+		b, err := s.renderer.HTML(inv)
+		return name + ".html", b, err
+	case DocumentPDF:
+		b, err := s.renderer.PDF(inv)
+		return name + ".pdf", b, err
+	}
+	return "", nil, fmt.Errorf("unsupported format %q", format)
+}
+
+func toInvoiceDTO(inv *settlemententity.Invoice) dto.InvoiceDTO {
+	d := dto.InvoiceDTO{
+		ID: inv.ID, Number: inv.Number, Kind: inv.Kind, LegalEntity: inv.Issuer.Code, Currency: inv.Currency,
+		BookingID: inv.BookingID, CorporateAccount: inv.CorporateAccount,
+		TotalCents: inv.TotalCents, TaxCents: inv.TaxCents, IssuedAt: inv.IssuedAt.Format(time.RFC3339),
+	}
+	if !inv.PeriodStart.IsZero() {
+		d.Period = inv.PeriodStart.UTC().Format("2006-01")
+	}
+	return d
+}
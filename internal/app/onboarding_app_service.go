@@ -0,0 +1,441 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gavin/airport-pickup/internal/app/dto"
+	evt "github.com/gavin/airport-pickup/internal/domain/eventbus"
+	order "github.com/gavin/airport-pickup/internal/domain/order"
+	user "github.com/gavin/airport-pickup/internal/domain/user"
+	userentity "github.com/gavin/airport-pickup/internal/domain/user/entity"
+	userservice "github.com/gavin/airport-pickup/internal/domain/user/service"
+	"github.com/gavin/airport-pickup/pkg/util"
+)
+
+// DriverOnboardingAppService 管理司机入驻：上传证件、登记车辆、后台审核、账号状态变更，以及证件到期前自动暂停。
+// 司机的必需证件全部审核通过后自动激活；被暂停或退出平台时取消其 open 报价并发布 DriverStatusChanged。
+type DriverOnboardingAppService struct {
+	drivers   user.DriverRepository
+	documents user.DriverDocumentRepository
+	vehicles  user.VehicleRepository
+	blobs     userservice.BlobStore
+	orderRepo order.OrderRepository
+	bus       evt.EventBus
+
+	onboarding    *userservice.OnboardingService
+	vehicleSvc    *userservice.VehicleService
+	suspendBefore time.Duration // 证件在该时长内到期即暂停司机
+}
+
+func NewDriverOnboardingAppService(drivers user.DriverRepository, documents user.DriverDocumentRepository, vehicles user.VehicleRepository,
+	blobs userservice.BlobStore, orderRepo order.OrderRepository, bus evt.EventBus) *DriverOnboardingAppService {
+	return &DriverOnboardingAppService{
+		drivers:       drivers,
+		documents:     documents,
+		vehicles:      vehicles,
+		blobs:         blobs,
+		orderRepo:     orderRepo,
+		bus:           bus,
+		onboarding:    &userservice.OnboardingService{},
+		vehicleSvc:    &userservice.VehicleService{},
+		suspendBefore: 24 * time.Hour,
+	}
+}
+
+// WithSuspendBefore 设置证件到期前多久暂停司机；激活时同样要求证件在该时长后仍有效，避免刚激活即被暂停。
+func (s *DriverOnboardingAppService) WithSuspendBefore(d time.Duration) *DriverOnboardingAppService {
+	if d >= 0 {
+		s.suspendBefore = d
+	}
+	return s
+}
+
+// UploadDocument 保存司机上传的证件并进入审核队列；文件类型按内容识别，只接受 PDF、JPEG 与 PNG。
+func (s *DriverOnboardingAppService) UploadDocument(in dto.UploadDriverDocumentInput) (dto.DriverDocumentDTO, error) {
+	d, err := s.driver(in.DriverID)
+	if err != nil {
+		return dto.DriverDocumentDTO{}, err
+	}
+	if d.Status == userentity.DriverStatusOffboarded {
+		return dto.DriverDocumentDTO{}, errors.New("driver is offboarded")
+	}
+	expiresAt, err := parseExpiry(in.ExpiresAt)
+	if err != nil {
+		return dto.DriverDocumentDTO{}, err
+	}
+	doc, err := s.onboarding.CreateDocument(&userservice.CreateDocumentCmd{
+		DriverID:    d.ID,
+		Type:        in.Type,
+		FileName:    in.FileName,
+		ContentType: http.DetectContentType(in.Content),
+		SizeBytes:   int64(len(in.Content)),
+		ExpiresAt:   expiresAt,
+		UploadedAt:  time.Now(),
+	})
+	if err != nil {
+		return dto.DriverDocumentDTO{}, err
+	}
+	doc.ID = util.NewID()
+	doc.BlobKey = userservice.DocumentBlobKey(doc)
+	// 先写文件再写记录：记录存在时文件一定存在，失败只会留下无人引用的文件
+	if err := s.blobs.Put(doc.BlobKey, in.Content, doc.ContentType); err != nil {
+		return dto.DriverDocumentDTO{}, err
+	}
+	if err := s.documents.SaveDocument(doc); err != nil {
+		return dto.DriverDocumentDTO{}, err
+	}
+	log.Printf("[onboarding] document %s uploaded driver=%s type=%s", doc.ID, doc.DriverID, doc.Type)
+	return toDriverDocumentDTO(doc), nil
+}
+
+// GetOnboarding 返回司机的账号状态、全部证件与激活仍缺少的证件。
+func (s *DriverOnboardingAppService) GetOnboarding(driverID string) (dto.DriverOnboardingDTO, error) {
+	d, err := s.driver(driverID)
+	if err != nil {
+		return dto.DriverOnboardingDTO{}, err
+	}
+	docs, err := s.documents.ListDocuments(d.ID)
+	if err != nil {
+		return dto.DriverOnboardingDTO{}, err
+	}
+	return s.toOnboardingDTO(d, docs), nil
+}
+
+// ReviewQueue 待审核证件，先传先审。
+func (s *DriverOnboardingAppService) ReviewQueue() ([]dto.DriverDocumentDTO, error) {
+	docs, err := s.documents.ListPendingReview()
+	if err != nil {
+		return nil, err
+	}
+	res := make([]dto.DriverDocumentDTO, 0, len(docs))
+	for _, doc := range docs {
+		res = append(res, toDriverDocumentDTO(doc))
+	}
+	return res, nil
+}
+
+// ReviewDocument 审核通过或驳回证件。通过后若司机的必需证件齐全，入驻中或因证件到期被暂停的司机自动激活；
+// 后台手动暂停的司机需通过 UpdateDriverStatus 恢复。
+func (s *DriverOnboardingAppService) ReviewDocument(id string, in dto.ReviewDocumentInput) (dto.DriverDocumentDTO, error) {
+	doc, err := s.documents.GetDocument(id)
+	if err != nil {
+		return dto.DriverDocumentDTO{}, err
+	}
+	if doc == nil {
+		return dto.DriverDocumentDTO{}, errors.New("document not found")
+	}
+	if in.Reviewer == "" {
+		return dto.DriverDocumentDTO{}, errors.New("reviewer required")
+	}
+	now := time.Now()
+	switch in.Decision {
+	case "approve":
+		err = doc.Approve(in.Reviewer, in.Note, now)
+	case "reject":
+		err = doc.Reject(in.Reviewer, in.Note, now)
+	default:
+		err = errors.New("decision must be approve or reject")
+	}
+	if err != nil {
+		return dto.DriverDocumentDTO{}, err
+	}
+	if err := s.documents.SaveDocument(doc); err != nil {
+		return dto.DriverDocumentDTO{}, err
+	}
+	log.Printf("[onboarding] document %s %s by %s driver=%s", doc.ID, doc.Status, doc.ReviewedBy, doc.DriverID)
+	if doc.Status == userentity.DocumentApproved {
+		if err := s.activateIfComplete(doc.DriverID, now); err != nil {
+			return dto.DriverDocumentDTO{}, err
+		}
+	}
+	return toDriverDocumentDTO(doc), nil
+}
+
+func (s *DriverOnboardingAppService) activateIfComplete(driverID string, now time.Time) error {
+	d, err := s.driver(driverID)
+	if err != nil {
+		return err
+	}
+	autoResume := d.Status == userentity.DriverStatusSuspended && d.StatusReason == userentity.SuspendReasonDocumentsExpiring
+	if d.Status != userentity.DriverStatusPending && !autoResume {
+		return nil
+	}
+	docs, err := s.documents.ListDocuments(d.ID)
+	if err != nil {
+		return err
+	}
+	if len(s.onboarding.MissingDocuments(docs, now.Add(s.suspendBefore))) > 0 {
+		return nil
+	}
+	if err := d.Activate(); err != nil {
+		return err
+	}
+	return s.saveStatus(d, now)
+}
+
+// DocumentFile 返回证件文件内容，供审核时查看。
+func (s *DriverOnboardingAppService) DocumentFile(id string) (filename, contentType string, content []byte, err error) {
+	doc, err := s.documents.GetDocument(id)
+	if err != nil {
+		return "", "", nil, err
+	}
+	if doc == nil {
+		return "", "", nil, errors.New("document not found")
+	}
+	content, err = s.blobs.Get(doc.BlobKey)
+	if err != nil {
+		return "", "", nil, err
+	}
+	return doc.FileName, doc.ContentType, content, nil
+}
+
+// RegisterVehicle 为司机登记车辆，核验通过后才能用于报价；同一车牌只能登记在一辆未驳回的车辆上。
+func (s *DriverOnboardingAppService) RegisterVehicle(driverID string, in dto.RegisterVehicleInput) (dto.VehicleDTO, error) {
+	d, err := s.driver(driverID)
+	if err != nil {
+		return dto.VehicleDTO{}, err
+	}
+	if d.Status == userentity.DriverStatusOffboarded {
+		return dto.VehicleDTO{}, errors.New("driver is offboarded")
+	}
+	v, err := s.vehicleSvc.RegisterVehicle(&userservice.RegisterVehicleCmd{
+		DriverID: d.ID, Plate: in.Plate, Make: in.Make, Model: in.Model, Colour: in.Colour, Class: in.Class,
+		Seats: in.Seats, LuggageCapacity: in.LuggageCapacity, CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return dto.VehicleDTO{}, err
+	}
+	v.ID = util.NewID()
+	if err := s.vehicles.SaveVehicle(v); err != nil {
+		return dto.VehicleDTO{}, err
+	}
+	log.Printf("[onboarding] vehicle %s registered driver=%s plate=%s class=%s", v.ID, v.DriverID, v.Plate, v.Class)
+	return toVehicleDTO(v), nil
+}
+
+// ListVehicles 司机登记的全部车辆。
+func (s *DriverOnboardingAppService) ListVehicles(driverID string) ([]dto.VehicleDTO, error) {
+	d, err := s.driver(driverID)
+	if err != nil {
+		return nil, err
+	}
+	list, err := s.vehicles.ListVehicles(d.ID)
+	if err != nil {
+		return nil, err
+	}
+	return toVehicleDTOs(list), nil
+}
+
+// VehicleQueue 待核验车辆，先登记先核验。
+func (s *DriverOnboardingAppService) VehicleQueue() ([]dto.VehicleDTO, error) {
+	list, err := s.vehicles.ListPendingVerification()
+	if err != nil {
+		return nil, err
+	}
+	return toVehicleDTOs(list), nil
+}
+
+// VerifyVehicle 核验通过或驳回车辆；驳回的车辆不能用于报价，其车牌可重新登记。
+func (s *DriverOnboardingAppService) VerifyVehicle(id string, in dto.VerifyVehicleInput) (dto.VehicleDTO, error) {
+	v, err := s.vehicles.GetVehicle(id)
+	if err != nil {
+		return dto.VehicleDTO{}, err
+	}
+	if v == nil {
+		return dto.VehicleDTO{}, errors.New("vehicle not found")
+	}
+	if in.Reviewer == "" {
+		return dto.VehicleDTO{}, errors.New("reviewer required")
+	}
+	now := time.Now()
+	switch in.Decision {
+	case "approve":
+		err = v.Verify(in.Reviewer, in.Note, now)
+	case "reject":
+		err = v.Reject(in.Reviewer, in.Note, now)
+	default:
+		err = errors.New("decision must be approve or reject")
+	}
+	if err != nil {
+		return dto.VehicleDTO{}, err
+	}
+	if err := s.vehicles.SaveVehicle(v); err != nil {
+		return dto.VehicleDTO{}, err
+	}
+	log.Printf("[onboarding] vehicle %s %s by %s driver=%s", v.ID, v.Status, v.VerifiedBy, v.DriverID)
+	return toVehicleDTO(v), nil
+}
+
+// UpdateDriverStatus 后台变更司机账号状态：激活（须证件齐全）、暂停（须说明原因）或退出平台。
+func (s *DriverOnboardingAppService) UpdateDriverStatus(driverID string, in dto.UpdateDriverStatusInput) (dto.DriverOnboardingDTO, error) {
+	d, err := s.driver(driverID)
+	if err != nil {
+		return dto.DriverOnboardingDTO{}, err
+	}
+	docs, err := s.documents.ListDocuments(d.ID)
+	if err != nil {
+		return dto.DriverOnboardingDTO{}, err
+	}
+	now := time.Now()
+	switch in.Status {
+	case userentity.DriverStatusActive:
+		if missing := s.onboarding.MissingDocuments(docs, now.Add(s.suspendBefore)); len(missing) > 0 {
+			return dto.DriverOnboardingDTO{}, fmt.Errorf("missing valid documents: %v", missing)
+		}
+		err = d.Activate()
+	case userentity.DriverStatusSuspended:
+		err = d.Suspend(in.Reason)
+	case userentity.DriverStatusOffboarded:
+		err = d.Offboard(in.Reason)
+	default:
+		err = errors.New("status must be active, suspended or offboarded")
+	}
+	if err != nil {
+		return dto.DriverOnboardingDTO{}, err
+	}
+	if err := s.saveStatus(d, now); err != nil {
+		return dto.DriverOnboardingDTO{}, err
+	}
+	return s.toOnboardingDTO(d, docs), nil
+}
+
+// SuspendExpiringDrivers 暂停必需证件将在 suspendBefore 内到期、且没有续期证件审核通过的 active 司机，返回暂停人数。
+// 单个司机失败不影响其他司机，错误合并返回，下一轮重试。
+func (s *DriverOnboardingAppService) SuspendExpiringDrivers() (int, error) {
+	now := time.Now()
+	deadline := now.Add(s.suspendBefore)
+	expiring, err := s.documents.ListApprovedExpiringBefore(deadline)
+	if err != nil {
+		return 0, err
+	}
+	seen := make(map[string]bool)
+	suspended := 0
+	var errs []error
+	for _, doc := range expiring {
+		if seen[doc.DriverID] {
+			continue
+		}
+		seen[doc.DriverID] = true
+		ok, err := s.suspendIfExpiring(doc.DriverID, now, deadline)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("driver %s: %w", doc.DriverID, err))
+			continue
+		}
+		if ok {
+			suspended++
+		}
+	}
+	return suspended, errors.Join(errs...)
+}
+
+func (s *DriverOnboardingAppService) suspendIfExpiring(driverID string, now, deadline time.Time) (bool, error) {
+	d, err := s.driver(driverID)
+	if err != nil {
+		return false, err
+	}
+	if !d.IsActive() {
+		return false, nil
+	}
+	docs, err := s.documents.ListDocuments(d.ID)
+	if err != nil {
+		return false, err
+	}
+	missing := s.onboarding.MissingDocuments(docs, deadline)
+	if len(missing) == 0 {
+		return false, nil
+	}
+	if err := d.Suspend(userentity.SuspendReasonDocumentsExpiring); err != nil {
+		return false, err
+	}
+	if err := s.saveStatus(d, now); err != nil {
+		return false, err
+	}
+	log.Printf("[onboarding] driver %s suspended, documents expiring before %s: %v", d.ID, deadline.UTC().Format(time.RFC3339), missing)
+	return true, nil
+}
+
+// saveStatus 保存司机状态；不再 active 时取消其 open 报价，撮合 worker 收到事件后移出订单簿。
+func (s *DriverOnboardingAppService) saveStatus(d *userentity.Driver, now time.Time) error {
+	if err := s.drivers.Save(d); err != nil {
+		return err
+	}
+	if d.Status == userentity.DriverStatusSuspended || d.Status == userentity.DriverStatusOffboarded {
+		if err := s.orderRepo.CancelOpenDriverOffers(d.ID); err != nil {
+			return err
+		}
+	}
+	s.bus.Publish(evt.DriverStatusChanged{DriverID: d.ID, Status: d.Status, Reason: d.StatusReason, OccurredAt: now})
+	return nil
+}
+
+func (s *DriverOnboardingAppService) driver(id string) (*userentity.Driver, error) {
+	d, err := s.drivers.GetByID(id)
+	if err != nil || d == nil {
+		return nil, errors.New("driver not found")
+	}
+	return d, nil
+}
+
+func (s *DriverOnboardingAppService) toOnboardingDTO(d *userentity.Driver, docs []*userentity.DriverDocument) dto.DriverOnboardingDTO {
+	res := dto.DriverOnboardingDTO{
+		DriverID:         d.ID,
+		Status:           d.Status,
+		StatusReason:     d.StatusReason,
+		MissingDocuments: s.onboarding.MissingDocuments(docs, time.Now().Add(s.suspendBefore)),
+		Documents:        make([]dto.DriverDocumentDTO, 0, len(docs)),
+	}
+	if res.MissingDocuments == nil {
+		res.MissingDocuments = []string{}
+	}
+	for _, doc := range docs {
+		res.Documents = append(res.Documents, toDriverDocumentDTO(doc))
+	}
+	return res
+}
+
+func toVehicleDTOs(list []*userentity.Vehicle) []dto.VehicleDTO {
+	res := make([]dto.VehicleDTO, 0, len(list))
+	for _, v := range list {
+		res = append(res, toVehicleDTO(v))
+	}
+	return res
+}
+
+func toVehicleDTO(v *userentity.Vehicle) dto.VehicleDTO {
+	res := dto.VehicleDTO{
+		ID: v.ID, DriverID: v.DriverID, Plate: v.Plate, Make: v.Make, Model: v.Model, Colour: v.Colour, Class: v.Class,
+		Seats: v.Seats, LuggageCapacity: v.LuggageCapacity, Status: v.Status, VerifiedBy: v.VerifiedBy, ReviewNote: v.ReviewNote,
+		CreatedAt: v.CreatedAt.UTC().Format(time.RFC3339),
+	}
+	if v.ReviewedAt != nil {
+		res.ReviewedAt = v.ReviewedAt.UTC().Format(time.RFC3339)
+	}
+	return res
+}
+
+func toDriverDocumentDTO(d *userentity.DriverDocument) dto.DriverDocumentDTO {
+	res := dto.DriverDocumentDTO{
+		ID: d.ID, DriverID: d.DriverID, Type: d.Type, FileName: d.FileName, ContentType: d.ContentType, SizeBytes: d.SizeBytes,
+		ExpiresAt: d.ExpiresAt.UTC().Format(time.RFC3339), Status: d.Status, ReviewedBy: d.ReviewedBy, ReviewNote: d.ReviewNote,
+		UploadedAt: d.UploadedAt.UTC().Format(time.RFC3339),
+	}
+	if d.ReviewedAt != nil {
+		res.ReviewedAt = d.ReviewedAt.UTC().Format(time.RFC3339)
+	}
+	return res
+}
+
+// parseExpiry 解析证件有效期：RFC3339 时间，或 YYYY-MM-DD 表示当天（UTC）结束时到期。
+func parseExpiry(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t.AddDate(0, 0, 1), nil
+	}
+	return time.Time{}, errors.New("invalid expires_at, want RFC3339 or YYYY-MM-DD")
+}
@@ -2,12 +2,21 @@ package app
 
 import (
 	"errors"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/gavin/airport-pickup/internal/app/dto"
 	evt "github.com/gavin/airport-pickup/internal/domain/eventbus"
+	"github.com/gavin/airport-pickup/internal/domain/money"
 	order "github.com/gavin/airport-pickup/internal/domain/order"
+	orderentity "github.com/gavin/airport-pickup/internal/domain/order/entity"
 	orderservice "github.com/gavin/airport-pickup/internal/domain/order/service"
+	promotion "github.com/gavin/airport-pickup/internal/domain/promotion"
+	promosvc "github.com/gavin/airport-pickup/internal/domain/promotion/service"
+	settlesvc "github.com/gavin/airport-pickup/internal/domain/settlement/service"
 	user "github.com/gavin/airport-pickup/internal/domain/user"
+	userentity "github.com/gavin/airport-pickup/internal/domain/user/entity"
 	userservice "github.com/gavin/airport-pickup/internal/domain/user/service"
 	"github.com/gavin/airport-pickup/pkg/util"
 )
@@ -16,6 +25,8 @@ type OrderAppService struct {
 	orderRepo  order.OrderRepository
 	passRepo   user.PassengerRepository
 	driverRepo user.DriverRepository
+	vehicles   user.VehicleRepository
+	methods    user.PaymentMethodRepository // 未设置时只能从乘客钱包扣款
 	matching   orderservice.MatchingService
 	bus        evt.EventBus
 
@@ -23,24 +34,294 @@ type OrderAppService struct {
 	driverService        *userservice.DriverService
 	pickupRequestService *orderservice.PickupRequestService
 	driverOfferService   *orderservice.DriverOfferService
+
+	defaultCurrency   string
+	airportCurrencies map[string]string             // 机场代码 -> 结算币种
+	promotions        promotion.PromotionRepository // 未设置时不接受优惠码
+	surge             *orderservice.SurgeEngine     // 与撮合 worker 共享，未设置时倍率恒为 1
+
+	ratings    user.RatingRepository // 未设置时不接受评价
+	reputation userservice.ReputationPolicy
+
+	deposits     settlesvc.PaymentService // 未设置时不收押金
+	depositBelow float64                  // 乘客声誉分低于该值时创建请求需预授权押金
+	depositCents int64
+
+	requestExpireAfter time.Duration // 期望接机时间过后多久仍未撮合的请求过期
+
+	quotes           *orderservice.QuoteService // 未设置时不提供报价
+	openOffers       OpenOfferSource
+	quoteTTL         time.Duration
+	fareCalculator   *settlesvc.FareCalculator
+	defaultFareRules settlesvc.FareRules
+	fareRules        map[string]settlesvc.FareRules // 机场代码 -> 计费规则，用于估算报价的总车费
 }
 
-func NewOrderAppService(orderRepo order.OrderRepository, passRepo user.PassengerRepository, driverRepo user.DriverRepository, matching orderservice.MatchingService, bus evt.EventBus) *OrderAppService {
+func NewOrderAppService(orderRepo order.OrderRepository, passRepo user.PassengerRepository, driverRepo user.DriverRepository, vehicles user.VehicleRepository,
+	matching orderservice.MatchingService, bus evt.EventBus) *OrderAppService {
 	return &OrderAppService{
 		orderRepo:            orderRepo,
 		passRepo:             passRepo,
 		driverRepo:           driverRepo,
+		vehicles:             vehicles,
 		matching:             matching,
 		bus:                  bus,
 		passengerService:     &userservice.PassengerService{},
 		driverService:        &userservice.DriverService{},
 		pickupRequestService: &orderservice.PickupRequestService{},
 		driverOfferService:   &orderservice.DriverOfferService{},
+		defaultCurrency:      money.DefaultCurrency,
+		airportCurrencies:    map[string]string{},
+		reputation:           userservice.DefaultReputationPolicy,
+		fareCalculator:       settlesvc.NewFareCalculator(),
+		fareRules:            map[string]settlesvc.FareRules{},
+	}
+}
+
+// WithAirportCurrencies 设置各机场的结算币种；未配置的机场使用 defaultCurrency。
+func (a *OrderAppService) WithAirportCurrencies(defaultCurrency string, byAirport map[string]string) *OrderAppService {
+	if c := money.NormalizeCurrency(defaultCurrency); money.ValidCurrency(c) {
+		a.defaultCurrency = c
+	}
+	for code, c := range byAirport {
+		a.airportCurrencies[strings.ToUpper(code)] = money.NormalizeCurrency(c)
+	}
+	return a
+}
+
+// WithPromotions 允许乘客在接机请求中附带优惠码，创建请求时校验优惠码是否可用。
+func (a *OrderAppService) WithPromotions(repo promotion.PromotionRepository) *OrderAppService {
+	a.promotions = repo
+	return a
+}
+
+// WithSurge 读取撮合 worker 维护的溢价倍率，用于查询当前溢价与建议出价。
+func (a *OrderAppService) WithSurge(engine *orderservice.SurgeEngine) *OrderAppService {
+	a.surge = engine
+	return a
+}
+
+// WithRatings 允许乘客在订单完成后评价司机，司机声誉分按 policy 计算，新司机的初始分为 policy 的先验平均分。
+func (a *OrderAppService) WithRatings(repo user.RatingRepository, policy userservice.ReputationPolicy) *OrderAppService {
+	a.ratings = repo
+	a.reputation = policy
+	return a
+}
+
+// WithPaymentMethods 允许乘客在接机请求中选择保存的支付方式，未选择时使用乘客的默认支付方式。
+func (a *OrderAppService) WithPaymentMethods(repo user.PaymentMethodRepository) *OrderAppService {
+	a.methods = repo
+	return a
+}
+
+// WithDeposits 要求声誉分低于 below 的乘客在创建接机请求时预授权 amountCents 押金。
+// 订单完成、取消或请求撤回、过期时释放；乘客未到场取消订单时扣收。
+func (a *OrderAppService) WithDeposits(pay settlesvc.PaymentService, below float64, amountCents int64) *OrderAppService {
+	a.deposits = pay
+	a.depositBelow = below
+	a.depositCents = amountCents
+	return a
+}
+
+// WithRequestExpiry 期望接机时间过后 after 仍未撮合的请求由 ExpirePickupRequests 关闭。
+func (a *OrderAppService) WithRequestExpiry(after time.Duration) *OrderAppService {
+	a.requestExpireAfter = after
+	return a
+}
+
+// OpenOfferSource 提供订单簿中的报价：worker.SharedOfferBook 读取各实例共同维护的 Redis 订单簿，
+// worker.OrderWorkerService 读取本实例的内存订单簿（仅限单实例部署）。
+type OpenOfferSource interface {
+	OpenDriverOffers(airportCode, vehicleType string) ([]*orderentity.DriverOffer, error)
+}
+
+// WithQuotes 启用报价，pricing 须与撮合使用的计价策略一致；报价在 ttl 内有效。
+// 供给取自 book，即撮合 worker 维护的实时订单簿，与撮合看到的报价一致。
+func (a *OrderAppService) WithQuotes(pricing orderservice.PricingPolicies, ttl time.Duration, book OpenOfferSource) *OrderAppService {
+	a.quotes = orderservice.NewQuoteService(pricing)
+	a.quoteTTL = ttl
+	a.openOffers = book
+	return a
+}
+
+// WithFareRules 设置各机场的计费规则，用于估算报价的总车费；未配置的机场使用 defaultRules。
+func (a *OrderAppService) WithFareRules(defaultRules settlesvc.FareRules, byAirport map[string]settlesvc.FareRules) *OrderAppService {
+	a.defaultFareRules = defaultRules
+	for code, r := range byAirport {
+		a.fareRules[strings.ToUpper(code)] = r
+	}
+	return a
+}
+
+// CreateQuote 按机场与车型的实时订单簿报价：最低可用报价、价格分位数、按出价估计的成交概率与预估总车费。
+// 报价保存后在有效期内可用于创建接机请求。
+func (a *OrderAppService) CreateQuote(in dto.CreateQuoteInput) (dto.QuoteDTO, error) {
+	if a.quotes == nil {
+		return dto.QuoteDTO{}, errors.New("quotes are not enabled")
+	}
+	if in.AirportCode == "" || in.VehicleType == "" {
+		return dto.QuoteDTO{}, errors.New("airport_code and vehicle_type required")
+	}
+	desired, err := time.Parse(time.RFC3339, in.DesiredTime)
+	if err != nil {
+		return dto.QuoteDTO{}, errors.New("invalid desired_time")
+	}
+	currency, err := a.airportCurrency(in.AirportCode, in.Currency)
+	if err != nil {
+		return dto.QuoteDTO{}, err
+	}
+	offers, err := a.openOffers.OpenDriverOffers(in.AirportCode, in.VehicleType)
+	if err != nil {
+		return dto.QuoteDTO{}, err
+	}
+	multiplier := 1.0
+	if a.surge != nil {
+		multiplier = a.surge.Current(evt.BookKey(in.AirportCode, in.VehicleType)).Multiplier
+	}
+	q, err := a.quotes.Quote(&orderservice.CreateQuoteCmd{
+		PassengerID: in.PassengerID, AirportCode: in.AirportCode, VehicleType: in.VehicleType, DesiredTime: desired,
+		DistanceKm: in.DistanceKm, Currency: currency, MaxPricePerKm: in.MaxPricePerKm, SurgeMultiplier: multiplier,
+	}, offers)
+	if err != nil {
+		return dto.QuoteDTO{}, err
+	}
+	rules, ok := a.fareRules[strings.ToUpper(q.AirportCode)]
+	if !ok {
+		rules = a.defaultFareRules
+	}
+	fare, err := a.fareCalculator.Calculate(&settlesvc.CalculateFareCmd{
+		Currency: q.Currency, PricePerKm: q.FarePerKm, PlatformMarginPerKm: q.PlatformMarginPerKm, DistanceKm: q.DistanceKm, Rules: rules,
+	})
+	if err != nil {
+		return dto.QuoteDTO{}, err
+	}
+	q.EstimatedFare = money.FromCents(fare.TotalCents)
+	q.ID = util.NewID()
+	q.CreatedAt = time.Now()
+	q.ExpiresAt = q.CreatedAt.Add(a.quoteTTL)
+	if err := a.orderRepo.SaveQuote(q); err != nil {
+		return dto.QuoteDTO{}, err
+	}
+	return toQuoteDTO(q), nil
+}
+
+func toQuoteDTO(q *orderentity.Quote) dto.QuoteDTO {
+	res := dto.QuoteDTO{
+		QuoteID: q.ID, AirportCode: q.AirportCode, VehicleType: q.VehicleType, DesiredTime: q.DesiredTime.UTC().Format(time.RFC3339),
+		DistanceKm: q.DistanceKm, Currency: q.Currency, AvailableOffers: q.AvailableOffers, MaxPricePerKm: q.MaxPricePerKm,
+		MatchProbability: q.MatchProbability, SurgeMultiplier: q.SurgeMultiplier, FarePerKm: q.FarePerKm, EstimatedFare: q.EstimatedFare,
+		Pricing: toPricingDTO(q.Pricing), ExpiresAt: q.ExpiresAt.UTC().Format(time.RFC3339),
+	}
+	if q.AvailableOffers > 0 {
+		best := q.BestOfferPerKm
+		res.BestOfferPerKm = &best
+		p := q.Percentiles
+		res.Percentiles = &dto.PricePercentilesDTO{P10: p.P10, P25: p.P25, P50: p.P50, P75: p.P75, P90: p.P90}
+	}
+	return res
+}
+
+// quotedRequest 校验请求引用的报价仍然有效且与请求一致，并用报价补全未给出的出价与期望时间。
+func (a *OrderAppService) quotedRequest(in *dto.CreatePickupRequestInput, currency string) error {
+	q, err := a.orderRepo.GetQuoteByID(in.QuoteID)
+	if err != nil {
+		return err
+	}
+	if q == nil {
+		return errors.New("quote not found")
+	}
+	if q.Expired(time.Now()) {
+		return errors.New("quote expired")
+	}
+	if q.PassengerID != "" && q.PassengerID != in.PassengerID {
+		return errors.New("quote belongs to another passenger")
+	}
+	if q.AirportCode != in.AirportCode || q.VehicleType != in.VehicleType || q.Currency != currency {
+		return errors.New("quote does not match airport_code, vehicle_type and currency")
+	}
+	if in.MaxPricePerKm.IsZero() {
+		in.MaxPricePerKm = q.MaxPricePerKm
+	} else if in.MaxPricePerKm != q.MaxPricePerKm {
+		return errors.New("max_price_per_km differs from quote")
+	}
+	if in.DesiredTime == "" {
+		in.DesiredTime = q.DesiredTime.UTC().Format(time.RFC3339)
+	}
+	return nil
+}
+
+// GetSurge 返回机场与车型订单簿当前的溢价倍率与建议乘客出价。
+func (a *OrderAppService) GetSurge(airportCode, vehicleType string) (dto.SurgeDTO, error) {
+	if airportCode == "" || vehicleType == "" {
+		return dto.SurgeDTO{}, errors.New("airport_code and vehicle_type required")
+	}
+	s := orderservice.Surge{Multiplier: 1, TargetMultiplier: 1}
+	if a.surge != nil {
+		s = a.surge.Current(evt.BookKey(airportCode, vehicleType))
+	}
+	res := dto.SurgeDTO{
+		AirportCode: airportCode, VehicleType: vehicleType, Multiplier: s.Multiplier, TargetMultiplier: s.TargetMultiplier,
+		OpenRequests: s.OpenRequests, OpenOffers: s.OpenOffers, Currency: s.Currency,
+	}
+	if !s.SuggestedBidPerKm.IsZero() {
+		bid := s.SuggestedBidPerKm
+		res.SuggestedMaxPricePerKm = &bid
+	}
+	if res.Currency == "" {
+		c, err := a.airportCurrency(airportCode, "")
+		if err != nil {
+			return dto.SurgeDTO{}, err
+		}
+		res.Currency = c
+	}
+	if !s.UpdatedAt.IsZero() {
+		res.UpdatedAt = s.UpdatedAt.UTC().Format(time.RFC3339)
 	}
+	return res, nil
 }
 
-func (a *OrderAppService) CreatePassenger(name string) (string, error) {
-	cmd := &userservice.CreatePassengerCmd{Name: name}
+// promoCode 校验乘客附带的优惠码在该机场与币种下当前可用，返回规范化的优惠码；
+// 结算时按核销结果为准，此处仅提前拒绝无效的优惠码。
+func (a *OrderAppService) promoCode(code, passengerID, airportCode, currency string) (string, error) {
+	code = promosvc.NormalizeCode(code)
+	if code == "" {
+		return "", nil
+	}
+	if a.promotions == nil {
+		return "", errors.New("promo codes are not enabled")
+	}
+	p, err := a.promotions.GetPromotion(code)
+	if err != nil {
+		return "", err
+	}
+	if p == nil {
+		return "", errors.New("promo_code not found")
+	}
+	used, err := a.promotions.CountPassengerRedemptions(code, passengerID)
+	if err != nil {
+		return "", err
+	}
+	if err := p.CheckEligible(airportCode, currency, time.Now(), used); err != nil {
+		return "", err
+	}
+	return code, nil
+}
+
+// airportCurrency 返回机场的结算币种；请求指定的币种与之不符时拒绝，为空则使用机场币种。
+func (a *OrderAppService) airportCurrency(airportCode, requested string) (string, error) {
+	c, ok := a.airportCurrencies[strings.ToUpper(airportCode)]
+	if !ok {
+		c = a.defaultCurrency
+	}
+	if r := money.NormalizeCurrency(requested); r != "" && r != c {
+		return "", fmt.Errorf("%w: airport %s settles in %s, got %s", money.ErrCurrencyMismatch, airportCode, c, r)
+	}
+	return c, nil
+}
+
+func (a *OrderAppService) CreatePassenger(in dto.CreatePassengerInput) (string, error) {
+	cmd := &userservice.CreatePassengerCmd{Name: in.Name, CorporateAccount: in.CorporateAccount, InitialScore: a.reputation.Score(nil, time.Now()),
+		Phone: in.Phone, Email: in.Email, PreferredLanguage: in.PreferredLanguage}
 	p, err := a.passengerService.CreatePassenger(cmd)
 	if err != nil {
 		return "", err
@@ -49,8 +330,10 @@ func (a *OrderAppService) CreatePassenger(name string) (string, error) {
 	return p.ID, a.passRepo.Save(p)
 }
 
-func (a *OrderAppService) CreateDriver(name string, rating float64) (string, error) {
-	cmd := &userservice.CreateDriverCmd{Name: name, Rating: rating}
+// CreateDriver 创建入驻中（pending）的司机，证件审核通过后才能发布报价；
+// 评分不由司机填写，新司机以声誉策略的先验平均分起步，之后由乘客评价决定。
+func (a *OrderAppService) CreateDriver(name string) (string, error) {
+	cmd := &userservice.CreateDriverCmd{Name: name, InitialRating: a.reputation.Score(nil, time.Now())}
 	d, err := a.driverService.CreateDriver(cmd)
 	if err != nil {
 		return "", err
@@ -64,34 +347,192 @@ func (a *OrderAppService) CreatePickupRequest(in dto.CreatePickupRequestInput) (
 	if ok, err := a.orderRepo.HasOngoingPickupRequest(in.PassengerID); err == nil && ok {
 		return "", errors.New("ongoing pickup request exists")
 	}
+	currency, err := a.airportCurrency(in.AirportCode, in.Currency)
+	if err != nil {
+		return "", err
+	}
+	promo, err := a.promoCode(in.PromoCode, in.PassengerID, in.AirportCode, currency)
+	if err != nil {
+		return "", err
+	}
+	if in.QuoteID != "" {
+		if err := a.quotedRequest(&in, currency); err != nil {
+			return "", err
+		}
+	}
+	passenger, err := a.passRepo.GetByID(in.PassengerID)
+	if err != nil || passenger == nil {
+		return "", errors.New("passenger not found")
+	}
+	method, err := a.paymentMethod(passenger, in.PaymentMethodID)
+	if err != nil {
+		return "", err
+	}
 	cmd := &orderservice.CreatePickupRequestCmd{
 		PassengerID:      in.PassengerID,
 		AirportCode:      in.AirportCode,
 		VehicleType:      in.VehicleType,
 		DesiredTime:      in.DesiredTime,
 		MaxPricePerKm:    in.MaxPricePerKm,
+		Currency:         currency,
 		PreferHighRating: in.PreferHighRating,
+		PromoCode:        promo,
+		PassengerScore:   a.passengerScore(passenger),
 	}
 	req, err := a.pickupRequestService.CreatePickupRequest(cmd)
 	if err != nil {
 		return "", err
 	}
+	req.QuoteID = in.QuoteID
 	req.ID = util.NewID()
+	token := ""
+	if method != nil {
+		req.PaymentMethodID, token = method.ID, method.Token
+	}
+	if a.depositRequired(req) {
+		req.DepositCents = a.depositCents
+	}
 	if err := a.orderRepo.SavePickupRequest(req); err != nil {
 		return "", err
 	}
+	// 先保存请求再预授权押金：预授权失败时关闭请求，由结算侧按请求释放可能已冻结的押金；
+	// 关闭失败的请求留在 open 状态，到期后由过期任务关闭
+	if err := a.holdDeposit(req, token); err != nil {
+		if cerr := req.MarkCancelled(); cerr != nil {
+			return "", errors.Join(err, cerr)
+		}
+		return "", errors.Join(err, a.closePickupRequest(req))
+	}
 	// 发布领域事件：创建接机请求
 	a.bus.Publish(evt.PickupRequestCreated{RequestID: req.ID, PassengerID: req.PassengerID, AirportCode: req.AirportCode,
-		VehicleType: req.VehicleType, MaxPricePerKm: req.MaxPricePerKm, PreferHighRating: req.PreferHighRating,
-		DesiredTime: req.DesiredTime, Status: req.Status})
+		VehicleType: req.VehicleType, MaxPricePerKm: req.MaxPricePerKm, Currency: req.Currency, PreferHighRating: req.PreferHighRating,
+		DesiredTime: req.DesiredTime, Status: req.Status, PromoCode: req.PromoCode, QuoteID: req.QuoteID,
+		PassengerScore: req.PassengerScore, DepositCents: req.DepositCents, PaymentMethodID: req.PaymentMethodID})
 	return req.ID, nil
 }
 
+// paymentMethod 返回请求扣款的支付方式：乘客指定的，否则为乘客的默认支付方式；都没有时返回 nil，从乘客钱包扣款。
+func (a *OrderAppService) paymentMethod(p *userentity.Passenger, id string) (*userentity.PaymentMethod, error) {
+	if id == "" {
+		id = p.DefaultPaymentMethodID
+	}
+	if id == "" {
+		return nil, nil
+	}
+	if a.methods == nil {
+		return nil, errors.New("payment methods are not enabled")
+	}
+	m, err := a.methods.GetPaymentMethod(id)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil || m.PassengerID != p.ID {
+		return nil, errors.New("payment method not found")
+	}
+	if m.Status != userentity.PaymentMethodActive {
+		return nil, fmt.Errorf("payment method %s was removed, choose another payment method", m.ID)
+	}
+	if m.ExpiredAt(time.Now()) {
+		return nil, fmt.Errorf("payment method %s is expired, choose another payment method", m.ID)
+	}
+	return m, nil
+}
+
+// passengerScore 返回乘客当前的声誉分；声誉分上线前创建、尚无评价的乘客按先验平均分计。
+func (a *OrderAppService) passengerScore(p *userentity.Passenger) float64 {
+	if p.RatingCount == 0 {
+		return a.reputation.Score(nil, time.Now())
+	}
+	return p.ReputationScore
+}
+
+// depositRequired 声誉分低于阈值的乘客创建请求时须预授权押金。
+func (a *OrderAppService) depositRequired(req *orderentity.PickupRequest) bool {
+	return a.deposits != nil && a.depositCents > 0 && req.PassengerScore < a.depositBelow
+}
+
+// holdDeposit 按请求预授权押金（从请求选择的支付方式冻结），余额不足时拒绝创建请求。
+// 押金的扣收与释放由结算侧在订单取消、完成或请求关闭时处理。
+func (a *OrderAppService) holdDeposit(req *orderentity.PickupRequest, token string) error {
+	if req.DepositCents == 0 {
+		return nil
+	}
+	if err := a.deposits.Authorize(req.DepositHoldKey(), req.PassengerID, token, req.DepositCents); err != nil {
+		if errors.Is(err, settlesvc.ErrPaymentDeclined) {
+			return fmt.Errorf("deposit of %d required for passenger reputation below %g: %w", req.DepositCents, a.depositBelow, err)
+		}
+		return err
+	}
+	return nil
+}
+
+// CancelPickupRequest 乘客撤回尚未撮合的接机请求并发布 PickupRequestClosed，撮合 worker 据此移出订单簿，结算侧释放押金。
+// 已撤回的请求重复撤回直接返回成功；已撮合的请求须取消订单。
+func (a *OrderAppService) CancelPickupRequest(id, passengerID string) error {
+	req, err := a.orderRepo.GetPickupRequestByID(id)
+	if err != nil || req == nil {
+		return errors.New("pickup request not found")
+	}
+	if req.PassengerID != passengerID {
+		return errors.New("only the request's passenger can cancel it")
+	}
+	if req.Status == "cancelled" {
+		return nil
+	}
+	if req.Status != "open" {
+		return fmt.Errorf("pickup request is %s, cancel the booking instead", req.Status)
+	}
+	if err := req.MarkCancelled(); err != nil {
+		return err
+	}
+	return a.closePickupRequest(req)
+}
+
+// ExpirePickupRequests 关闭期望接机时间已过 requestExpireAfter 仍未撮合的请求，返回关闭的请求数；押金由结算侧按 PickupRequestClosed 释放。
+func (a *OrderAppService) ExpirePickupRequests() (int, error) {
+	reqs, err := a.orderRepo.ListExpiredPickupRequests(time.Now().Add(-a.requestExpireAfter), 100)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	var errs []error
+	for _, req := range reqs {
+		if err := req.MarkExpired(); err != nil {
+			errs = append(errs, fmt.Errorf("request %s: %w", req.ID, err))
+			continue
+		}
+		if err := a.closePickupRequest(req); err != nil {
+			errs = append(errs, fmt.Errorf("request %s: %w", req.ID, err))
+			continue
+		}
+		n++
+	}
+	return n, errors.Join(errs...)
+}
+
+// closePickupRequest 条件保存请求的关闭状态；请求已被撮合时返回错误，押金留待订单结束时处理。
+func (a *OrderAppService) closePickupRequest(req *orderentity.PickupRequest) error {
+	ok, err := a.orderRepo.ClosePickupRequest(req)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("pickup request has already been matched")
+	}
+	a.bus.Publish(evt.PickupRequestClosed{RequestID: req.ID, PassengerID: req.PassengerID, AirportCode: req.AirportCode,
+		VehicleType: req.VehicleType, Status: req.Status, OccurredAt: time.Now()})
+	return nil
+}
+
 func (a *OrderAppService) CreateDriverOffer(in dto.CreateDriverOfferInput) (string, error) {
 	// 使用仓库方法判断是否存在进行中的报价（status in: open, matched）
 	if ok, err := a.orderRepo.HasOngoingDriverOffer(in.DriverID); err == nil && ok {
 		return "", errors.New("ongoing driver offer exists")
 	}
+	currency, err := a.airportCurrency(in.AirportCode, in.Currency)
+	if err != nil {
+		return "", err
+	}
 	driver, err := a.driverRepo.GetByID(in.DriverID)
 	if err != nil {
 		return "", err
@@ -99,14 +540,24 @@ func (a *OrderAppService) CreateDriverOffer(in dto.CreateDriverOfferInput) (stri
 	if driver == nil {
 		return "", errors.New("driver not found")
 	}
+	if !driver.IsActive() {
+		return "", fmt.Errorf("driver is %s, only active drivers can post offers", driver.Status)
+	}
+	vehicle, err := a.offerVehicle(in)
+	if err != nil {
+		return "", err
+	}
 	cmd := &orderservice.CreateDriverOfferCmd{
-		DriverID:      in.DriverID,
-		AirportCode:   in.AirportCode,
-		VehicleType:   in.VehicleType,
-		AvailableFrom: in.AvailableFrom,
-		AvailableTo:   in.AvailableTo,
-		PricePerKm:    in.PricePerKm,
-		Rating:        driver.Rating,
+		DriverID:          in.DriverID,
+		AirportCode:       in.AirportCode,
+		VehicleType:       vehicle.Class,
+		VehicleID:         vehicle.ID,
+		AvailableFrom:     in.AvailableFrom,
+		AvailableTo:       in.AvailableTo,
+		PricePerKm:        in.PricePerKm,
+		Currency:          currency,
+		Rating:            driver.Rating,
+		MinPassengerScore: in.MinPassengerScore,
 	}
 	o, err := a.driverOfferService.CreateDriverOffer(cmd)
 	if err != nil {
@@ -117,28 +568,77 @@ func (a *OrderAppService) CreateDriverOffer(in dto.CreateDriverOfferInput) (stri
 		return "", err
 	}
 	// 发布领域事件：创建司机报价
-	a.bus.Publish(evt.DriverOfferCreated{OfferID: o.ID, DriverID: o.DriverID, AirportCode: o.AirportCode, VehicleType: o.VehicleType,
-		AvailableFrom: o.AvailableFrom, AvailableTo: o.AvailableTo, PricePerKm: o.PricePerKm, Rating: o.Rating, Status: o.Status})
+	a.bus.Publish(evt.DriverOfferCreated{OfferID: o.ID, DriverID: o.DriverID, AirportCode: o.AirportCode, VehicleType: o.VehicleType, VehicleID: o.VehicleID,
+		AvailableFrom: o.AvailableFrom, AvailableTo: o.AvailableTo, PricePerKm: o.PricePerKm, Currency: o.Currency, Rating: o.Rating, Status: o.Status,
+		MinPassengerScore: o.MinPassengerScore})
 	return o.ID, nil
 }
 
+// offerVehicle 报价须使用司机本人已核验的车辆，车型取自车辆的 class；填写 vehicle_type 时须与之一致。
+func (a *OrderAppService) offerVehicle(in dto.CreateDriverOfferInput) (*userentity.Vehicle, error) {
+	if in.VehicleID == "" {
+		return nil, errors.New("vehicle_id required")
+	}
+	v, err := a.vehicles.GetVehicle(in.VehicleID)
+	if err != nil {
+		return nil, err
+	}
+	if v == nil || v.DriverID != in.DriverID {
+		return nil, errors.New("vehicle not found")
+	}
+	if !v.IsVerified() {
+		return nil, fmt.Errorf("vehicle is %s, only verified vehicles can serve offers", v.Status)
+	}
+	if in.VehicleType != "" && !strings.EqualFold(strings.TrimSpace(in.VehicleType), v.Class) {
+		return nil, fmt.Errorf("vehicle_type %q does not match vehicle class %q", in.VehicleType, v.Class)
+	}
+	return v, nil
+}
+
+// ListBookings 返回订单及接送车辆信息，乘客据此找到车辆。
 func (a *OrderAppService) ListBookings() ([]dto.BookingDTO, error) {
 	list, err := a.orderRepo.ListBookings()
 	if err != nil {
 		return nil, err
 	}
+	vehicles := make(map[string]*dto.VehicleDTO)
 	res := make([]dto.BookingDTO, 0, len(list))
 	for _, b := range list {
-		res = append(res, dto.BookingDTO{ID: b.ID, RequestID: b.RequestID, OfferID: b.OfferID, PassengerID: b.PassengerID, DriverID: b.DriverID, PricePerKm: b.PricePerKm, PlatformMarginPerKm: b.PlatformMarginPerKm, Status: b.Status})
+		item := dto.BookingDTO{ID: b.ID, RequestID: b.RequestID, OfferID: b.OfferID, PassengerID: b.PassengerID, DriverID: b.DriverID, PricePerKm: b.PricePerKm,
+			FarePerKm: b.PassengerPricePerKm(), PlatformMarginPerKm: b.PlatformMarginPerKm, Pricing: toPricingDTO(b.Pricing), SurgeMultiplier: b.SurgeMultiplier, Currency: b.Currency, Status: b.Status,
+			PaymentMethodID: b.PaymentMethodID}
+		if b.VehicleID != "" {
+			v, ok := vehicles[b.VehicleID]
+			if !ok {
+				found, err := a.vehicles.GetVehicle(b.VehicleID)
+				if err != nil {
+					return nil, err
+				}
+				if found != nil {
+					d := toVehicleDTO(found)
+					v = &d
+				}
+				vehicles[b.VehicleID] = v
+			}
+			item.Vehicle = v
+		}
+		res = append(res, item)
 	}
 	return res, nil
 }
 
-func (a *OrderAppService) CompleteBooking(id string) error {
+// CompleteBooking 记录行程信息（里程、等候时长、过路费）并完成订单，结算按这些信息计算车费明细。
+func (a *OrderAppService) CompleteBooking(in dto.CompleteBookingInput) error {
+	id := in.ID
 	b, err := a.orderRepo.GetBookingByID(id)
 	if err != nil || b == nil {
 		return errors.New("booking not found")
 	}
+	if b.Status == "created" {
+		if err := b.RecordTrip(in.DistanceKm, in.WaitingMinutes, in.Tolls); err != nil {
+			return err
+		}
+	}
 	if err := b.MarkCompleted(); err != nil {
 		return errors.New("booking mark completed failed: " + err.Error())
 	}
@@ -177,3 +677,200 @@ func (a *OrderAppService) CompleteBooking(id string) error {
 	a.bus.Publish(evt.OrderCompleted{BookingID: id})
 	return nil
 }
+
+// RateDriver 乘客评价已完成订单的司机，每个订单只能评价一次。按司机收到的全部评价重新计算声誉分，
+// 同步到其 open 报价并发布 DriverRatingUpdated，撮合 worker 据此更新内存订单簿。
+func (a *OrderAppService) RateDriver(in dto.RateDriverInput) (dto.DriverRatingDTO, error) {
+	if a.ratings == nil {
+		return dto.DriverRatingDTO{}, errors.New("ratings are not enabled")
+	}
+	b, err := a.orderRepo.GetBookingByID(in.BookingID)
+	if err != nil || b == nil {
+		return dto.DriverRatingDTO{}, errors.New("booking not found")
+	}
+	if b.PassengerID != in.PassengerID {
+		return dto.DriverRatingDTO{}, errors.New("only the booking's passenger can rate the driver")
+	}
+	if b.Status != "completed" {
+		return dto.DriverRatingDTO{}, errors.New("booking must be completed before rating")
+	}
+	r := &userentity.TripRating{
+		ID: util.NewID(), BookingID: b.ID, Target: userentity.RatingTargetDriver, RaterID: b.PassengerID, RateeID: b.DriverID,
+		Score: in.Score, Comment: strings.TrimSpace(in.Comment), CreatedAt: time.Now(),
+	}
+	if err := r.Validate(); err != nil {
+		return dto.DriverRatingDTO{}, err
+	}
+	// 评价保存前确认司机存在，避免留下无法计入声誉分的评价
+	if d, err := a.driverRepo.GetByID(b.DriverID); err != nil || d == nil {
+		return dto.DriverRatingDTO{}, errors.New("driver not found")
+	}
+	if err := a.ratings.SaveRating(r); err != nil {
+		return dto.DriverRatingDTO{}, err
+	}
+	d, err := a.refreshDriverRating(b.DriverID)
+	if err != nil {
+		return dto.DriverRatingDTO{}, err
+	}
+	return dto.DriverRatingDTO{RatingID: r.ID, BookingID: b.ID, DriverID: d.ID, Score: r.Score, Rating: d.Rating, RatingCount: d.RatingCount}, nil
+}
+
+// refreshDriverRating 按司机收到的全部评价重新计算声誉分；重复执行结果相同，评价已保存而更新失败时可再次执行。
+func (a *OrderAppService) refreshDriverRating(driverID string) (*userentity.Driver, error) {
+	d, err := a.driverRepo.GetByID(driverID)
+	if err != nil {
+		return nil, err
+	}
+	if d == nil {
+		return nil, errors.New("driver not found")
+	}
+	ratings, err := a.ratings.ListRatings(driverID, userentity.RatingTargetDriver)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	d.Rating = a.reputation.Score(ratings, now)
+	d.RatingCount = len(ratings)
+	if err := a.driverRepo.Save(d); err != nil {
+		return nil, err
+	}
+	if err := a.orderRepo.UpdateOpenOfferRatings(d.ID, d.Rating); err != nil {
+		return nil, err
+	}
+	a.bus.Publish(evt.DriverRatingUpdated{DriverID: d.ID, Rating: d.Rating, RatingCount: d.RatingCount, OccurredAt: now})
+	return d, nil
+}
+
+// RatePassenger 司机评价订单的乘客，每个订单只能评价一次。已完成或已取消的订单都可以评价，
+// 乘客未到场（no_show）只能用于已取消的订单并按 1 星计。按乘客收到的全部评价重新计算声誉分与未到场次数，
+// 同步到其 open 请求并发布 PassengerRatingUpdated，撮合 worker 据此更新内存订单簿。
+func (a *OrderAppService) RatePassenger(in dto.RatePassengerInput) (dto.PassengerRatingDTO, error) {
+	if a.ratings == nil {
+		return dto.PassengerRatingDTO{}, errors.New("ratings are not enabled")
+	}
+	b, err := a.orderRepo.GetBookingByID(in.BookingID)
+	if err != nil || b == nil {
+		return dto.PassengerRatingDTO{}, errors.New("booking not found")
+	}
+	if b.DriverID != in.DriverID {
+		return dto.PassengerRatingDTO{}, errors.New("only the booking's driver can rate the passenger")
+	}
+	if in.NoShow {
+		if b.Status != "cancelled" {
+			return dto.PassengerRatingDTO{}, errors.New("no_show applies to cancelled bookings only")
+		}
+		if in.Score == 0 {
+			in.Score = 1
+		}
+	} else if b.Status != "completed" && b.Status != "cancelled" {
+		return dto.PassengerRatingDTO{}, errors.New("booking must be completed or cancelled before rating")
+	}
+	r := &userentity.TripRating{
+		ID: util.NewID(), BookingID: b.ID, Target: userentity.RatingTargetPassenger, RaterID: b.DriverID, RateeID: b.PassengerID,
+		Score: in.Score, Comment: strings.TrimSpace(in.Comment), NoShow: in.NoShow, CreatedAt: time.Now(),
+	}
+	if err := r.Validate(); err != nil {
+		return dto.PassengerRatingDTO{}, err
+	}
+	// 评价保存前确认乘客存在，避免留下无法计入声誉分的评价
+	if p, err := a.passRepo.GetByID(b.PassengerID); err != nil || p == nil {
+		return dto.PassengerRatingDTO{}, errors.New("passenger not found")
+	}
+	if err := a.ratings.SaveRating(r); err != nil {
+		return dto.PassengerRatingDTO{}, err
+	}
+	p, err := a.refreshPassengerReputation(b.PassengerID)
+	if err != nil {
+		return dto.PassengerRatingDTO{}, err
+	}
+	return dto.PassengerRatingDTO{RatingID: r.ID, BookingID: b.ID, PassengerID: p.ID, Score: r.Score, NoShow: r.NoShow,
+		ReputationScore: p.ReputationScore, RatingCount: p.RatingCount, NoShowCount: p.NoShowCount}, nil
+}
+
+// refreshPassengerReputation 按乘客收到的全部评价重新计算声誉分与未到场次数，可重复执行，同 refreshDriverRating。
+func (a *OrderAppService) refreshPassengerReputation(passengerID string) (*userentity.Passenger, error) {
+	p, err := a.passRepo.GetByID(passengerID)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, errors.New("passenger not found")
+	}
+	ratings, err := a.ratings.ListRatings(passengerID, userentity.RatingTargetPassenger)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	p.ReputationScore = a.reputation.Score(ratings, now)
+	p.RatingCount = len(ratings)
+	p.NoShowCount = 0
+	for _, r := range ratings {
+		if r.NoShow {
+			p.NoShowCount++
+		}
+	}
+	if err := a.passRepo.Save(p); err != nil {
+		return nil, err
+	}
+	if err := a.orderRepo.UpdateOpenRequestPassengerScores(p.ID, p.ReputationScore); err != nil {
+		return nil, err
+	}
+	a.bus.Publish(evt.PassengerRatingUpdated{PassengerID: p.ID, ReputationScore: p.ReputationScore, RatingCount: p.RatingCount,
+		NoShowCount: p.NoShowCount, OccurredAt: now})
+	return p, nil
+}
+
+// CancelBooking 取消尚未完成的订单，同时取消对应的接机请求与司机报价，并发布 OrderCancelled。
+// 结算侧据此撤销预授权，原因为乘客未到场（passenger_no_show）时扣收请求的押金，否则释放。已取消的订单重复取消直接返回成功。
+func (a *OrderAppService) CancelBooking(id, reason string) error {
+	b, err := a.orderRepo.GetBookingByID(id)
+	if err != nil || b == nil {
+		return errors.New("booking not found")
+	}
+	if b.Status == "cancelled" {
+		return nil
+	}
+	if err := b.MarkCancelled(); err != nil {
+		return errors.New("booking mark cancelled failed: " + err.Error())
+	}
+
+	req, err := a.orderRepo.GetPickupRequestByID(b.RequestID)
+	if err != nil {
+		return errors.New("get pickup request failed: " + err.Error())
+	}
+	if req != nil {
+		if err := req.MarkCancelled(); err != nil {
+			return errors.New("pickup request mark cancelled failed: " + err.Error())
+		}
+	}
+
+	ofr, err := a.orderRepo.GetDriverOfferByID(b.OfferID)
+	if err != nil {
+		return errors.New("get driver offer failed: " + err.Error())
+	}
+	if ofr != nil {
+		if err := ofr.MarkCancelled(); err != nil {
+			return errors.New("driver offer mark cancelled failed: " + err.Error())
+		}
+	}
+
+	if err := a.orderRepo.UpdateAllInTransaction(b, req, ofr); err != nil {
+		return err
+	}
+	a.bus.Publish(evt.OrderCancelled{BookingID: id, Reason: reason})
+	return nil
+}
+
+func toPricingDTO(t orderentity.PricingTerms) *dto.PricingDTO {
+	if t.Policy == "" {
+		return nil
+	}
+	d := &dto.PricingDTO{
+		Policy: t.Policy, FeePerKm: t.FeePerKm, FeeRate: t.FeeRate, PlatformShare: t.PlatformShare,
+		CommissionRate: t.CommissionRate, VolumeWindowDays: t.VolumeWindowDays, DriverTrips: t.DriverTrips,
+	}
+	for _, tier := range t.Tiers {
+		d.Tiers = append(d.Tiers, dto.CommissionTierDTO{MinTrips: tier.MinTrips, Rate: tier.Rate})
+	}
+	return d
+}
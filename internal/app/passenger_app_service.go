@@ -0,0 +1,236 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gavin/airport-pickup/internal/app/dto"
+	order "github.com/gavin/airport-pickup/internal/domain/order"
+	user "github.com/gavin/airport-pickup/internal/domain/user"
+	userentity "github.com/gavin/airport-pickup/internal/domain/user/entity"
+	userservice "github.com/gavin/airport-pickup/internal/domain/user/service"
+	"github.com/gavin/airport-pickup/pkg/util"
+)
+
+// PassengerAppService 管理乘客资料与保存的支付方式。客户端向支付方式保险库提交卡号换取令牌，平台只接收令牌，
+// 并保存令牌与从保险库查到的卡的展示信息；
+// 乘客的第一个支付方式自动设为默认，未指定支付方式的接机请求从默认支付方式扣款。
+type PassengerAppService struct {
+	passengers user.PassengerRepository
+	methods    user.PaymentMethodRepository
+	vault      userservice.PaymentMethodVault
+	orderRepo  order.OrderRepository
+
+	passengerService *userservice.PassengerService
+	methodService    *userservice.PaymentMethodService
+}
+
+func NewPassengerAppService(passengers user.PassengerRepository, methods user.PaymentMethodRepository, vault userservice.PaymentMethodVault,
+	orderRepo order.OrderRepository) *PassengerAppService {
+	return &PassengerAppService{
+		passengers:       passengers,
+		methods:          methods,
+		vault:            vault,
+		orderRepo:        orderRepo,
+		passengerService: &userservice.PassengerService{},
+		methodService:    &userservice.PaymentMethodService{},
+	}
+}
+
+// GetPassenger 乘客资料及未删除的支付方式。
+func (s *PassengerAppService) GetPassenger(id string) (dto.PassengerDTO, error) {
+	p, err := s.passenger(id)
+	if err != nil {
+		return dto.PassengerDTO{}, err
+	}
+	return s.toPassengerDTO(p)
+}
+
+// UpdatePassenger 修改乘客的姓名与联系方式，未给出的字段保持不变。
+func (s *PassengerAppService) UpdatePassenger(id string, in dto.UpdatePassengerInput) (dto.PassengerDTO, error) {
+	p, err := s.passenger(id)
+	if err != nil {
+		return dto.PassengerDTO{}, err
+	}
+	if err := s.passengerService.UpdateProfile(p, &userservice.UpdateProfileCmd{
+		Name: in.Name, Phone: in.Phone, Email: in.Email, PreferredLanguage: in.PreferredLanguage,
+	}); err != nil {
+		return dto.PassengerDTO{}, err
+	}
+	if err := s.passengers.Save(p); err != nil {
+		return dto.PassengerDTO{}, err
+	}
+	return s.toPassengerDTO(p)
+}
+
+// AddPaymentMethod 按保险库令牌保存支付方式，卡组织、后四位与有效期取自保险库；
+// 乘客还没有默认支付方式或要求设为默认时设为默认。
+func (s *PassengerAppService) AddPaymentMethod(passengerID string, in dto.AddPaymentMethodInput) (dto.PaymentMethodDTO, error) {
+	p, err := s.passenger(passengerID)
+	if err != nil {
+		return dto.PaymentMethodDTO{}, err
+	}
+	token := strings.TrimSpace(in.Token)
+	if token == "" {
+		return dto.PaymentMethodDTO{}, errors.New("payment token required")
+	}
+	card, err := s.vault.Lookup(token)
+	if err != nil {
+		return dto.PaymentMethodDTO{}, err
+	}
+	if card.PassengerID != "" && card.PassengerID != p.ID {
+		return dto.PaymentMethodDTO{}, errors.New("payment token belongs to another passenger")
+	}
+	existing, err := s.methods.ListPaymentMethods(p.ID)
+	if err != nil {
+		return dto.PaymentMethodDTO{}, err
+	}
+	for _, m := range existing {
+		if m.Token == card.Token {
+			return dto.PaymentMethodDTO{}, fmt.Errorf("payment token already used by payment method %s", m.ID)
+		}
+	}
+	m, err := s.methodService.CreatePaymentMethod(p.ID, card, time.Now())
+	if err != nil {
+		return dto.PaymentMethodDTO{}, err
+	}
+	m.ID = util.NewID()
+	if err := s.methods.SavePaymentMethod(m); err != nil {
+		return dto.PaymentMethodDTO{}, err
+	}
+	if p.DefaultPaymentMethodID == "" || in.MakeDefault {
+		p.DefaultPaymentMethodID = m.ID
+		if err := s.passengers.Save(p); err != nil {
+			return dto.PaymentMethodDTO{}, err
+		}
+	}
+	log.Printf("[passenger] payment method %s added passenger=%s brand=%s last4=%s", m.ID, p.ID, m.Brand, m.Last4)
+	return toPaymentMethodDTO(m, p.DefaultPaymentMethodID), nil
+}
+
+// ListPaymentMethods 乘客未删除的支付方式，按添加时间升序。
+func (s *PassengerAppService) ListPaymentMethods(passengerID string) ([]dto.PaymentMethodDTO, error) {
+	p, err := s.passenger(passengerID)
+	if err != nil {
+		return nil, err
+	}
+	return s.paymentMethodDTOs(p)
+}
+
+// SetDefaultPaymentMethod 设置乘客的默认支付方式，已过期的卡不能设为默认。
+func (s *PassengerAppService) SetDefaultPaymentMethod(passengerID, methodID string) (dto.PassengerDTO, error) {
+	p, m, err := s.paymentMethod(passengerID, methodID)
+	if err != nil {
+		return dto.PassengerDTO{}, err
+	}
+	if !m.UsableAt(time.Now()) {
+		return dto.PassengerDTO{}, fmt.Errorf("payment method %s is expired", m.ID)
+	}
+	p.DefaultPaymentMethodID = m.ID
+	if err := s.passengers.Save(p); err != nil {
+		return dto.PassengerDTO{}, err
+	}
+	return s.toPassengerDTO(p)
+}
+
+// RemovePaymentMethod 删除支付方式并作废其令牌；进行中的接机请求使用的支付方式不能删除。
+// 删除默认支付方式时改用最近添加的可用支付方式，没有时改为从乘客钱包扣款。
+func (s *PassengerAppService) RemovePaymentMethod(passengerID, methodID string) (dto.PassengerDTO, error) {
+	p, m, err := s.paymentMethod(passengerID, methodID)
+	if err != nil {
+		return dto.PassengerDTO{}, err
+	}
+	inUse, err := s.orderRepo.HasOngoingPickupRequestWithPaymentMethod(m.ID)
+	if err != nil {
+		return dto.PassengerDTO{}, err
+	}
+	if inUse {
+		return dto.PassengerDTO{}, errors.New("payment method is used by an ongoing pickup request")
+	}
+	now := time.Now()
+	if err := m.Remove(now); err != nil {
+		return dto.PassengerDTO{}, err
+	}
+	if err := s.methods.SavePaymentMethod(m); err != nil {
+		return dto.PassengerDTO{}, err
+	}
+	s.removeToken(m.Token)
+	if p.DefaultPaymentMethodID == m.ID {
+		list, err := s.methods.ListPaymentMethods(p.ID)
+		if err != nil {
+			return dto.PassengerDTO{}, err
+		}
+		p.DefaultPaymentMethodID = s.methodService.NextDefault(list, now)
+		if err := s.passengers.Save(p); err != nil {
+			return dto.PassengerDTO{}, err
+		}
+	}
+	log.Printf("[passenger] payment method %s removed passenger=%s default=%q", m.ID, p.ID, p.DefaultPaymentMethodID)
+	return s.toPassengerDTO(p)
+}
+
+// removeToken 作废保险库中的令牌；失败只记录日志，令牌已不再被任何支付方式引用。
+func (s *PassengerAppService) removeToken(token string) {
+	if err := s.vault.Remove(token); err != nil {
+		log.Printf("[passenger] remove vault token failed: %v", err)
+	}
+}
+
+func (s *PassengerAppService) passenger(id string) (*userentity.Passenger, error) {
+	p, err := s.passengers.GetByID(id)
+	if err != nil || p == nil {
+		return nil, errors.New("passenger not found")
+	}
+	return p, nil
+}
+
+// paymentMethod 返回乘客及其名下未删除的支付方式。
+func (s *PassengerAppService) paymentMethod(passengerID, methodID string) (*userentity.Passenger, *userentity.PaymentMethod, error) {
+	p, err := s.passenger(passengerID)
+	if err != nil {
+		return nil, nil, err
+	}
+	m, err := s.methods.GetPaymentMethod(methodID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if m == nil || m.PassengerID != p.ID || m.Status != userentity.PaymentMethodActive {
+		return nil, nil, errors.New("payment method not found")
+	}
+	return p, m, nil
+}
+
+func (s *PassengerAppService) paymentMethodDTOs(p *userentity.Passenger) ([]dto.PaymentMethodDTO, error) {
+	list, err := s.methods.ListPaymentMethods(p.ID)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]dto.PaymentMethodDTO, 0, len(list))
+	for _, m := range list {
+		if m.Status == userentity.PaymentMethodActive {
+			res = append(res, toPaymentMethodDTO(m, p.DefaultPaymentMethodID))
+		}
+	}
+	return res, nil
+}
+
+func (s *PassengerAppService) toPassengerDTO(p *userentity.Passenger) (dto.PassengerDTO, error) {
+	methods, err := s.paymentMethodDTOs(p)
+	if err != nil {
+		return dto.PassengerDTO{}, err
+	}
+	return dto.PassengerDTO{
+		ID: p.ID, Name: p.Name, CorporateAccount: p.CorporateAccount, Phone: p.Phone, Email: p.Email, PreferredLanguage: p.PreferredLanguage,
+		ReputationScore: p.ReputationScore, RatingCount: p.RatingCount, DefaultPaymentMethodID: p.DefaultPaymentMethodID, PaymentMethods: methods,
+	}, nil
+}
+
+func toPaymentMethodDTO(m *userentity.PaymentMethod, defaultID string) dto.PaymentMethodDTO {
+	return dto.PaymentMethodDTO{
+		ID: m.ID, Brand: m.Brand, Last4: m.Last4, ExpMonth: m.ExpMonth, ExpYear: m.ExpYear, Status: m.Status,
+		Expired: m.ExpiredAt(time.Now()), Default: m.ID == defaultID, CreatedAt: m.CreatedAt.UTC().Format(time.RFC3339),
+	}
+}
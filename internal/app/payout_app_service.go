@@ -0,0 +1,218 @@
+package app
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gavin/airport-pickup/internal/app/dto"
+	"github.com/gavin/airport-pickup/pkg/util"
+
+	settlement "github.com/gavin/airport-pickup/internal/domain/settlement"
+	settlemententity "github.com/gavin/airport-pickup/internal/domain/settlement/entity"
+	settlesvc "github.com/gavin/airport-pickup/internal/domain/settlement/service"
+)
+
+// defaultPayoutListLimit 为打款列表默认返回条数。
+const defaultPayoutListLimit = 100
+
+// PayoutAppService 定期把司机未打款的结算记录汇总成打款单，通过 PayoutProvider 打款并记账。
+type PayoutAppService struct {
+	repo     settlement.PayoutRepository
+	provider settlesvc.PayoutProvider
+
+	payoutService *settlesvc.PayoutService
+	ledgerService *settlesvc.LedgerService
+	feeCents      int64
+	mu            sync.Mutex // 同一进程内的打款任务串行执行
+}
+
+func NewPayoutAppService(repo settlement.PayoutRepository, provider settlesvc.PayoutProvider) *PayoutAppService {
+	return &PayoutAppService{
+		repo:          repo,
+		provider:      provider,
+		payoutService: settlesvc.NewPayoutService(),
+		ledgerService: settlesvc.NewLedgerService(),
+	}
+}
+
+// WithFeeCents 设置每笔打款收取的手续费（分）。
+func (s *PayoutAppService) WithFeeCents(n int64) *PayoutAppService {
+	if n >= 0 {
+		s.feeCents = n
+	}
+	return s
+}
+
+// RunPayouts 执行一轮打款：先重试上一轮结果未知的 pending 打款单，
+// 再按司机与币种汇总未打款的结算记录生成打款单并打款。
+// 净额不大于手续费（如退款冲减多于收入）的司机顺延到下一轮；
+// 渠道拒绝的打款单标记失败，结算记录回到未打款状态。
+func (s *PayoutAppService) RunPayouts() (dto.PayoutRunDTO, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var res dto.PayoutRunDTO
+	var errs []error
+	now := time.Now()
+
+	pending, err := s.repo.ListPendingPayouts(now)
+	if err != nil {
+		return res, err
+	}
+	for _, p := range pending {
+		log.Printf("[payout] resume payout=%s driver=%s", p.ID, p.DriverID)
+		err := s.pay(p)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("payout %s: %w", p.ID, err))
+		}
+		addPayoutResult(&res, p, err)
+	}
+
+	records, err := s.repo.ListUnpaidSettlementRecords(now)
+	if err != nil {
+		return res, errors.Join(append(errs, err)...)
+	}
+	type payoutKey struct{ driverID, currency string }
+	var keys []payoutKey
+	byKey := make(map[payoutKey][]*settlemententity.SettlementRecord)
+	for _, r := range records {
+		k := payoutKey{r.DriverID, r.Currency}
+		if _, ok := byKey[k]; !ok {
+			keys = append(keys, k)
+		}
+		byKey[k] = append(byKey[k], r)
+	}
+	for _, k := range keys {
+		driverID := k.driverID
+		p, err := s.payoutService.BuildPayout(&settlesvc.BuildPayoutCmd{DriverID: driverID, Records: byKey[k], FeeCents: s.feeCents})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("driver %s: %w", driverID, err))
+			continue
+		}
+		if p.NetCents <= 0 {
+			log.Printf("[payout] skip driver=%s currency=%s net_cents=%d, carried forward", driverID, k.currency, p.NetCents)
+			continue
+		}
+		p.ID = util.NewID()
+		if err := s.repo.CreatePayout(p); err != nil {
+			errs = append(errs, fmt.Errorf("driver %s: %w", driverID, err))
+			continue
+		}
+		err = s.pay(p)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("payout %s: %w", p.ID, err))
+		}
+		addPayoutResult(&res, p, err)
+	}
+	return res, errors.Join(errs...)
+}
+
+// ListPayouts 按创建时间倒序返回打款单；driverID 为空返回全部司机。
+func (s *PayoutAppService) ListPayouts(driverID string) ([]dto.PayoutDTO, error) {
+	list, err := s.repo.ListPayouts(driverID, defaultPayoutListLimit)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]dto.PayoutDTO, 0, len(list))
+	for _, p := range list {
+		res = append(res, toPayoutDTO(p))
+	}
+	return res, nil
+}
+
+// PayoutStatement 生成打款对账单（CSV），每条结算记录一行，末尾为汇总行。
+func (s *PayoutAppService) PayoutStatement(id string) (filename string, content []byte, err error) {
+	p, err := s.repo.GetPayout(id)
+	if err != nil {
+		return "", nil, err
+	}
+	if p == nil {
+		return "", nil, errors.New("payout not found")
+	}
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	rows := [][]string{
+		{"payout_id", p.ID},
+		{"driver_id", p.DriverID},
+		{"status", p.Status},
+		{"currency", p.Currency},
+		{"created_at", p.CreatedAt.Format(time.RFC3339)},
+		{"provider_ref", p.ProviderRef},
+		{},
+		{"settlement_record_id", "booking_id", "amount_cents", "commission_cents", "net_cents"},
+	}
+	for _, l := range p.Lines {
+		rows = append(rows, []string{l.SettlementRecordID, l.BookingID, cents(l.AmountCents), cents(l.CommissionCents), cents(l.NetCents)})
+	}
+	rows = append(rows,
+		[]string{},
+		[]string{"gross_cents", cents(p.GrossCents)},
+		[]string{"commission_cents", cents(p.CommissionCents)},
+		[]string{"fee_cents", cents(p.FeeCents)},
+		[]string{"net_cents", cents(p.NetCents)},
+	)
+	if err := w.WriteAll(rows); err != nil {
+		return "", nil, err
+	}
+	return fmt.Sprintf("payout-%s.csv", p.ID), buf.Bytes(), nil
+}
+
+// pay 以打款单 ID 为幂等键调用打款渠道：成功则记账并标记 paid；
+// 渠道拒绝则标记 failed 并释放结算记录；其他错误保持 pending，由下一轮重试。
+func (s *PayoutAppService) pay(p *settlemententity.Payout) error {
+	ref, err := s.provider.Pay(p.ID, p.DriverID, p.NetCents, p.Currency)
+	if errors.Is(err, settlesvc.ErrPayoutRejected) {
+		log.Printf("[payout] rejected payout=%s driver=%s: %v", p.ID, p.DriverID, err)
+		if err := p.MarkFailed(err.Error(), time.Now()); err != nil {
+			return err
+		}
+		return s.repo.FailPayout(p)
+	}
+	if err != nil {
+		return err
+	}
+	if err := p.MarkPaid(ref, time.Now()); err != nil {
+		return err
+	}
+	entries, err := s.ledgerService.PostPayout(p)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		e.ID = util.NewID()
+	}
+	return s.repo.CompletePayout(p, entries)
+}
+
+// addPayoutResult 汇总一笔打款的结果；pay 返回错误时打款单在库中仍为 pending。
+func addPayoutResult(res *dto.PayoutRunDTO, p *settlemententity.Payout, payErr error) {
+	switch {
+	case payErr != nil:
+		res.Pending++
+	case p.Status == settlemententity.PayoutPaid:
+		res.Paid++
+	case p.Status == settlemententity.PayoutFailed:
+		res.Failed++
+	}
+	d := toPayoutDTO(p)
+	if payErr != nil {
+		d.Status = settlemententity.PayoutPending
+	}
+	res.Payouts = append(res.Payouts, d)
+}
+
+func toPayoutDTO(p *settlemententity.Payout) dto.PayoutDTO {
+	return dto.PayoutDTO{
+		ID: p.ID, DriverID: p.DriverID, Status: p.Status,
+		GrossCents: p.GrossCents, CommissionCents: p.CommissionCents, FeeCents: p.FeeCents, NetCents: p.NetCents, Currency: p.Currency,
+		ProviderRef: p.ProviderRef, FailureReason: p.FailureReason,
+		CreatedAt: p.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func cents(n int64) string { return strconv.FormatInt(n, 10) }
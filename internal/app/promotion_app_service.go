@@ -0,0 +1,89 @@
+package app
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gavin/airport-pickup/internal/app/dto"
+
+	promotion "github.com/gavin/airport-pickup/internal/domain/promotion"
+	promotionentity "github.com/gavin/airport-pickup/internal/domain/promotion/entity"
+	promosvc "github.com/gavin/airport-pickup/internal/domain/promotion/service"
+)
+
+// PromotionAppService 管理优惠活动；核销在结算时由 SettlementAppService 完成。
+type PromotionAppService struct {
+	repo promotion.PromotionRepository
+
+	promotionService *promosvc.PromotionService
+}
+
+func NewPromotionAppService(repo promotion.PromotionRepository) *PromotionAppService {
+	return &PromotionAppService{repo: repo, promotionService: promosvc.NewPromotionService()}
+}
+
+// CreatePromotion 创建优惠活动，返回规范化（大写）的优惠码。
+func (s *PromotionAppService) CreatePromotion(in dto.CreatePromotionInput) (string, error) {
+	p, err := s.promotionService.CreatePromotion(&promosvc.CreatePromotionCmd{
+		Code:              in.Code,
+		Description:       in.Description,
+		Kind:              in.Kind,
+		Rate:              in.Rate,
+		AmountCents:       in.AmountCents,
+		MaxDiscountCents:  in.MaxDiscountCents,
+		Currency:          in.Currency,
+		AirportCodes:      in.AirportCodes,
+		StartsAt:          in.StartsAt,
+		EndsAt:            in.EndsAt,
+		PerPassengerLimit: in.PerPassengerLimit,
+		TotalLimit:        in.TotalLimit,
+		BudgetCents:       in.BudgetCents,
+	})
+	if err != nil {
+		return "", err
+	}
+	if err := s.repo.CreatePromotion(p); err != nil {
+		return "", err
+	}
+	return p.Code, nil
+}
+
+func (s *PromotionAppService) ListPromotions() ([]dto.PromotionDTO, error) {
+	ps, err := s.repo.ListPromotions()
+	if err != nil {
+		return nil, err
+	}
+	res := make([]dto.PromotionDTO, 0, len(ps))
+	for _, p := range ps {
+		res = append(res, toPromotionDTO(p))
+	}
+	return res, nil
+}
+
+func (s *PromotionAppService) GetPromotion(code string) (dto.PromotionDTO, error) {
+	p, err := s.repo.GetPromotion(promosvc.NormalizeCode(code))
+	if err != nil {
+		return dto.PromotionDTO{}, err
+	}
+	if p == nil {
+		return dto.PromotionDTO{}, errors.New("promotion not found")
+	}
+	return toPromotionDTO(p), nil
+}
+
+func toPromotionDTO(p *promotionentity.Promotion) dto.PromotionDTO {
+	d := dto.PromotionDTO{
+		Code: p.Code, Description: p.Description, Kind: p.Kind, Rate: p.Rate, AmountCents: p.AmountCents,
+		MaxDiscountCents: p.MaxDiscountCents, Currency: p.Currency, AirportCodes: p.AirportCodes,
+		StartsAt: p.StartsAt.Format(time.RFC3339), EndsAt: p.EndsAt.Format(time.RFC3339),
+		PerPassengerLimit: p.PerPassengerLimit, TotalLimit: p.TotalLimit, BudgetCents: p.BudgetCents,
+		RedeemedCount: p.RedeemedCount, SpentCents: p.SpentCents, CreatedAt: p.CreatedAt.Format(time.RFC3339),
+	}
+	if d.AirportCodes == nil {
+		d.AirportCodes = []string{}
+	}
+	if p.BudgetCents > 0 {
+		d.RemainingCents = p.BudgetCents - p.SpentCents
+	}
+	return d
+}
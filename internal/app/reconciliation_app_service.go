@@ -0,0 +1,237 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gavin/airport-pickup/internal/app/dto"
+	"github.com/gavin/airport-pickup/pkg/payments"
+	"github.com/gavin/airport-pickup/pkg/util"
+
+	settlement "github.com/gavin/airport-pickup/internal/domain/settlement"
+	settlemententity "github.com/gavin/airport-pickup/internal/domain/settlement/entity"
+	settlesvc "github.com/gavin/airport-pickup/internal/domain/settlement/service"
+)
+
+// defaultReconciliationListLimit 为对账报告列表默认返回条数。
+const defaultReconciliationListLimit = 50
+
+// ReconciliationAppService 导入钱包服务商对账单，与本地支付流水逐笔核对并保存对账报告；
+// 运营人员逐条处理差异，全部处理后报告转为 reconciled。
+type ReconciliationAppService struct {
+	repo settlement.ReconciliationRepository
+
+	reconciliationService *settlesvc.ReconciliationService
+	mu                    sync.Mutex // 同一报告的差异处理串行执行
+}
+
+func NewReconciliationAppService(repo settlement.ReconciliationRepository) *ReconciliationAppService {
+	return &ReconciliationAppService{
+		repo:                  repo,
+		reconciliationService: settlesvc.NewReconciliationService(),
+	}
+}
+
+// ImportStatement 解析对账单并与账期内的支付流水核对。账期为 UTC 自然日，
+// 未指定时取对账单中最早与最晚的结算日。
+func (s *ReconciliationAppService) ImportStatement(in dto.ImportStatementInput) (dto.ReconciliationReportDTO, error) {
+	lines, err := payments.ParseStatement(in.Format, bytes.NewReader(in.Content))
+	if err != nil {
+		return dto.ReconciliationReportDTO{}, err
+	}
+	if len(lines) == 0 {
+		return dto.ReconciliationReportDTO{}, errors.New("empty statement")
+	}
+	start, end, err := statementPeriod(lines, in.From, in.To)
+	if err != nil {
+		return dto.ReconciliationReportDTO{}, err
+	}
+	txs, err := s.repo.ListSettledPaymentTransactions(start, end)
+	if err != nil {
+		return dto.ReconciliationReportDTO{}, err
+	}
+	related, err := s.relatedTransactions(lines, txs)
+	if err != nil {
+		return dto.ReconciliationReportDTO{}, err
+	}
+	rep, err := s.reconciliationService.Reconcile(&settlesvc.ReconcileCmd{
+		Source: strings.TrimSpace(in.Source), PeriodStart: start, PeriodEnd: end,
+		Lines: lines, Transactions: txs, Related: related,
+	})
+	if err != nil {
+		return dto.ReconciliationReportDTO{}, err
+	}
+	rep.ID = util.NewID()
+	for i := range rep.Exceptions {
+		rep.Exceptions[i].ID = util.NewID()
+	}
+	if err := s.repo.SaveReconciliationReport(rep); err != nil {
+		return dto.ReconciliationReportDTO{}, err
+	}
+	log.Printf("[reconciliation] report %s source=%q lines=%d matched=%d exceptions=%d",
+		rep.ID, rep.Source, rep.StatementLines, rep.MatchedLines, len(rep.Exceptions))
+	return toReconciliationReportDTO(rep, true), nil
+}
+
+// ListReports 按导入时间倒序返回对账报告，不含差异明细。
+func (s *ReconciliationAppService) ListReports() ([]dto.ReconciliationReportDTO, error) {
+	list, err := s.repo.ListReconciliationReports(defaultReconciliationListLimit)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]dto.ReconciliationReportDTO, 0, len(list))
+	for _, r := range list {
+		res = append(res, toReconciliationReportDTO(r, false))
+	}
+	return res, nil
+}
+
+// GetReport 返回对账报告及差异；status 非空时只返回该状态的差异。
+func (s *ReconciliationAppService) GetReport(id, status string) (dto.ReconciliationReportDTO, error) {
+	rep, err := s.getReport(id)
+	if err != nil {
+		return dto.ReconciliationReportDTO{}, err
+	}
+	d := toReconciliationReportDTO(rep, true)
+	if status != "" {
+		filtered := make([]dto.ReconciliationExceptionDTO, 0, len(d.Exceptions))
+		for _, e := range d.Exceptions {
+			if e.Status == status {
+				filtered = append(filtered, e)
+			}
+		}
+		d.Exceptions = filtered
+	}
+	return d, nil
+}
+
+// ResolveException 记录差异的处理结果，返回更新后的报告。
+func (s *ReconciliationAppService) ResolveException(reportID, exceptionID string, in dto.ResolveExceptionInput) (dto.ReconciliationReportDTO, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rep, err := s.getReport(reportID)
+	if err != nil {
+		return dto.ReconciliationReportDTO{}, err
+	}
+	var e *settlemententity.ReconciliationException
+	for i := range rep.Exceptions {
+		if rep.Exceptions[i].ID == exceptionID {
+			e = &rep.Exceptions[i]
+			break
+		}
+	}
+	if e == nil {
+		return dto.ReconciliationReportDTO{}, errors.New("reconciliation exception not found")
+	}
+	if err := e.Resolve(in.Resolution, strings.TrimSpace(in.Note), time.Now()); err != nil {
+		return dto.ReconciliationReportDTO{}, err
+	}
+	rep.RefreshStatus()
+	if err := s.repo.ResolveReconciliationException(rep, e); err != nil {
+		return dto.ReconciliationReportDTO{}, err
+	}
+	log.Printf("[reconciliation] exception %s resolved report=%s resolution=%s", e.ID, rep.ID, e.Resolution)
+	return toReconciliationReportDTO(rep, true), nil
+}
+
+func (s *ReconciliationAppService) getReport(id string) (*settlemententity.ReconciliationReport, error) {
+	rep, err := s.repo.GetReconciliationReport(id)
+	if err != nil {
+		return nil, err
+	}
+	if rep == nil {
+		return nil, errors.New("reconciliation report not found")
+	}
+	return rep, nil
+}
+
+// relatedTransactions 返回对账单中出现的订单在账期外的流水。
+func (s *ReconciliationAppService) relatedTransactions(lines []settlemententity.StatementLine, txs []*settlemententity.PaymentTransaction) ([]*settlemententity.PaymentTransaction, error) {
+	inPeriod := make(map[string]bool, len(txs))
+	for _, t := range txs {
+		inPeriod[t.ID] = true
+	}
+	seen := make(map[string]bool)
+	var bookingIDs []string
+	for _, l := range lines {
+		if !seen[l.BookingID] {
+			seen[l.BookingID] = true
+			bookingIDs = append(bookingIDs, l.BookingID)
+		}
+	}
+	all, err := s.repo.ListPaymentTransactionsByBookings(bookingIDs)
+	if err != nil {
+		return nil, err
+	}
+	var res []*settlemententity.PaymentTransaction
+	for _, t := range all {
+		if !inPeriod[t.ID] {
+			res = append(res, t)
+		}
+	}
+	return res, nil
+}
+
+// statementPeriod 返回 [start, end)，from 与 to 为 UTC 日期且均包含在内。
+func statementPeriod(lines []settlemententity.StatementLine, from, to string) (time.Time, time.Time, error) {
+	var start, end time.Time
+	for _, l := range lines {
+		day := l.SettledAt.UTC().Truncate(24 * time.Hour)
+		if start.IsZero() || day.Before(start) {
+			start = day
+		}
+		if end.IsZero() || !day.Before(end) {
+			end = day.AddDate(0, 0, 1)
+		}
+	}
+	if from != "" {
+		t, err := time.Parse("2006-01-02", from)
+		if err != nil {
+			return start, end, errors.New("from must be YYYY-MM-DD")
+		}
+		start = t
+	}
+	if to != "" {
+		t, err := time.Parse("2006-01-02", to)
+		if err != nil {
+			return start, end, errors.New("to must be YYYY-MM-DD")
+		}
+		end = t.AddDate(0, 0, 1)
+	}
+	if !start.Before(end) {
+		return start, end, errors.New("from must not be after to")
+	}
+	return start, end, nil
+}
+
+func toReconciliationReportDTO(r *settlemententity.ReconciliationReport, withExceptions bool) dto.ReconciliationReportDTO {
+	d := dto.ReconciliationReportDTO{
+		ID: r.ID, Source: r.Source, Status: r.Status,
+		PeriodStart: r.PeriodStart.UTC().Format("2006-01-02"), PeriodEnd: r.PeriodEnd.UTC().Format("2006-01-02"),
+		StatementLines: r.StatementLines, Transactions: r.Transactions, MatchedLines: r.MatchedLines,
+		StatementCents: r.StatementCents, TransactionCents: r.TransactionCents, CreatedAt: r.CreatedAt.Format(time.RFC3339),
+	}
+	if !withExceptions {
+		return d
+	}
+	d.Exceptions = make([]dto.ReconciliationExceptionDTO, 0, len(r.Exceptions))
+	for _, e := range r.Exceptions {
+		ed := dto.ReconciliationExceptionDTO{
+			ID: e.ID, Kind: e.Kind, BookingID: e.BookingID, Type: e.Type, TransactionID: e.TransactionID,
+			StatementRef: e.StatementRef, StatementLineNo: e.StatementLineNo, ExpectedCents: e.ExpectedCents,
+			StatementCents: e.StatementCents, Currency: e.Currency, Status: e.Status, Resolution: e.Resolution, Note: e.Note,
+		}
+		if e.ResolvedAt != nil {
+			ed.ResolvedAt = e.ResolvedAt.Format(time.RFC3339)
+		}
+		if e.Status == settlemententity.ExceptionOpen {
+			d.OpenExceptions++
+		}
+		d.Exceptions = append(d.Exceptions, ed)
+	}
+	return d
+}
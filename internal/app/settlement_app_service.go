@@ -2,14 +2,35 @@ package app
 
 import (
 	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gavin/airport-pickup/internal/app/dto"
 	"github.com/gavin/airport-pickup/pkg/util"
 
 	evt "github.com/gavin/airport-pickup/internal/domain/eventbus"
+	"github.com/gavin/airport-pickup/internal/domain/money"
 	order "github.com/gavin/airport-pickup/internal/domain/order"
+	orderentity "github.com/gavin/airport-pickup/internal/domain/order/entity"
 	settlement "github.com/gavin/airport-pickup/internal/domain/settlement"
+	settlemententity "github.com/gavin/airport-pickup/internal/domain/settlement/entity"
 	settlesvc "github.com/gavin/airport-pickup/internal/domain/settlement/service"
+	user "github.com/gavin/airport-pickup/internal/domain/user"
+
+	promotion "github.com/gavin/airport-pickup/internal/domain/promotion"
+	promotionentity "github.com/gavin/airport-pickup/internal/domain/promotion/entity"
+	promosvc "github.com/gavin/airport-pickup/internal/domain/promotion/service"
 )
 
+// defaultSagaMaxAttempts 为扣款或落库步骤转入失败/补偿前允许的失败次数。
+const defaultSagaMaxAttempts = 3
+
+// promoRedeemAttempts 并发核销用掉预算时按剩余预算重新计算优惠的次数。
+const promoRedeemAttempts = 3
+
 type SettlementAppService struct {
 	repo      settlement.SettlementRepository
 	orderRepo order.OrderRepository
@@ -18,6 +39,15 @@ type SettlementAppService struct {
 
 	paymentTxService  *settlesvc.PaymentTransactionService
 	settlementService settlesvc.SettlementService
+	ledgerService     *settlesvc.LedgerService
+	fareCalculator    *settlesvc.FareCalculator
+	maxAttempts       int
+	rates             *money.RateTable // 收入报表折算汇率，未设置时不折算
+	defaultFareRules  settlesvc.FareRules
+	fareRules         map[string]settlesvc.FareRules // 机场代码 -> 计费规则
+	promotions        promotion.PromotionRepository  // 未设置时不核销优惠码
+	methods           user.PaymentMethodRepository   // 未设置时只能从乘客钱包扣款
+	promotionService  *promosvc.PromotionService
 }
 
 func NewSettlementAppService(repo settlement.SettlementRepository, orderRepo order.OrderRepository, pay settlesvc.PaymentService, bus evt.EventBus) *SettlementAppService {
@@ -28,69 +58,848 @@ func NewSettlementAppService(repo settlement.SettlementRepository, orderRepo ord
 		bus:               bus,
 		paymentTxService:  settlesvc.NewPaymentTransactionService(),
 		settlementService: settlesvc.NewSettlementService(),
+		ledgerService:     settlesvc.NewLedgerService(),
+		fareCalculator:    settlesvc.NewFareCalculator(),
+		maxAttempts:       defaultSagaMaxAttempts,
+		fareRules:         map[string]settlesvc.FareRules{},
+		promotionService:  promosvc.NewPromotionService(),
 	}
 }
 
+// WithSagaMaxAttempts 设置扣款与落库步骤的最大失败次数。
+func (s *SettlementAppService) WithSagaMaxAttempts(n int) *SettlementAppService {
+	if n > 0 {
+		s.maxAttempts = n
+	}
+	return s
+}
+
+// WithFareRules 设置各机场的计费规则；未配置的机场使用 defaultRules。
+func (s *SettlementAppService) WithFareRules(defaultRules settlesvc.FareRules, byAirport map[string]settlesvc.FareRules) *SettlementAppService {
+	s.defaultFareRules = defaultRules
+	for code, rules := range byAirport {
+		s.fareRules[strings.ToUpper(code)] = rules
+	}
+	return s
+}
+
+// WithRates 设置收入报表折算到报表币种所用的汇率表。
+func (s *SettlementAppService) WithRates(rates *money.RateTable) *SettlementAppService {
+	s.rates = rates
+	return s
+}
+
+// WithPromotions 启用优惠码：结算时核销接机请求所附的优惠码，优惠由平台分成承担。
+func (s *SettlementAppService) WithPromotions(repo promotion.PromotionRepository) *SettlementAppService {
+	s.promotions = repo
+	return s
+}
+
+// WithPaymentMethods 按订单所选的支付方式扣款，未选择的订单从乘客钱包扣款。
+func (s *SettlementAppService) WithPaymentMethods(repo user.PaymentMethodRepository) *SettlementAppService {
+	s.methods = repo
+	return s
+}
+
 func (s *SettlementAppService) TriggerPayment(bookingID string) error {
 	return s.OnOrderCompleted(bookingID)
 }
 
-// OnOrderCompleted orchestrates payment -> settlement -> revenue update
-func (s *SettlementAppService) OnOrderCompleted(bookingID string) error {
+// OnOrderMatched 按预估车费对订单的支付方式预授权（冻结），每个订单只预授权一次。
+// 余额不足等拒绝时记录失败流水并发布 PaymentAuthorizationFailed，由订单侧取消订单；
+// 其他错误返回给事件总线重试。
+func (s *SettlementAppService) OnOrderMatched(bookingID string) error {
+	ptx, err := s.repo.GetPaymentTransactionByBookingID(bookingID)
+	if err != nil {
+		return err
+	}
+	if ptx != nil {
+		return nil
+	}
 	b, err := s.orderRepo.GetBookingByID(bookingID)
 	if err != nil || b == nil {
 		return errors.New("booking not found")
 	}
-	// naive amount calculation: assume 10km for demo only
-	amountCents := int64(b.PricePerKm * 100.0 * 10.0)
-	platformRevenueCents := int64(b.PlatformMarginPerKm * 100.0 * 10.0)
-	if amountCents < 0 {
-		amountCents = 0
+	// 按默认里程、不计优惠预估车费预授权，实际车费超出时在扣款前重新预授权
+	fare, err := s.fare(b, 0)
+	if err != nil {
+		return err
+	}
+	token, err := s.paymentToken(b)
+	if err != nil {
+		return err
 	}
-	if platformRevenueCents < 0 {
-		platformRevenueCents = 0
+	amountCents := fare.TotalCents
+	ptx, err = s.authorize(nil, bookingID, b.PassengerID, token, amountCents, fare.Currency)
+	if ptx != nil && errors.Is(err, settlesvc.ErrPaymentDeclined) {
+		log.Printf("[settlement] authorization declined booking=%s: %v", bookingID, err)
+		s.bus.Publish(evt.PaymentAuthorizationFailed{BookingID: bookingID, AmountCents: amountCents, Reason: ptx.FailureReason})
+		return nil
 	}
-	// charge
-	if err := s.pay.Charge(bookingID, amountCents); err != nil {
+	return err
+}
+
+// OnOrderCancelled 撤销订单尚未扣款的预授权，释放冻结金额；
+// 请求收取了押金时，原因为乘客未到场（passenger_no_show）扣收押金，否则释放。
+func (s *SettlementAppService) OnOrderCancelled(bookingID, reason string) error {
+	if err := s.voidAuthorization(bookingID); err != nil {
 		return err
 	}
-	// persist payment (通过领域服务)
-	ptx, err := s.paymentTxService.CreatePaymentTransaction(&settlesvc.CreatePaymentTransactionCmd{
-		BookingID:   bookingID,
+	b, err := s.orderRepo.GetBookingByID(bookingID)
+	if err != nil || b == nil {
+		return errors.New("booking not found")
+	}
+	return s.settleDeposit(b.RequestID, bookingID, reason == orderentity.CancelReasonPassengerNoShow)
+}
+
+// OnPickupRequestClosed 接机请求被撤回或过期时释放其押金。
+func (s *SettlementAppService) OnPickupRequestClosed(requestID string) error {
+	return s.settleDeposit(requestID, "", false)
+}
+
+// OnOrderCompleted 以 saga 编排结算：payment pending -> charged -> records saved -> events published。
+// 扣款步骤对匹配时的预授权按实际金额 Capture；缺少有效预授权时先补做预授权。
+// 每一步完成后持久化 saga 状态；重复投递的事件会从已记录的步骤继续，不会重复扣款。
+// 步骤失败时记录失败次数并返回错误，由事件总线或恢复任务重试；
+// 扣款多次失败则 saga 失败并撤销预授权，扣款后落库多次失败则通过 PaymentService 退款补偿。
+func (s *SettlementAppService) OnOrderCompleted(bookingID string) error {
+	saga, err := s.repo.GetSettlementSagaByBookingID(bookingID)
+	if err != nil {
+		return err
+	}
+	if saga == nil {
+		if saga, err = s.startSaga(bookingID); err != nil {
+			return err
+		}
+	}
+	if err := s.advance(saga); err != nil {
+		return err
+	}
+	b, err := s.orderRepo.GetBookingByID(bookingID)
+	if err != nil || b == nil {
+		return errors.New("booking not found")
+	}
+	return s.settleDeposit(b.RequestID, bookingID, false)
+}
+
+// RefundBooking 对已结算的订单全额或部分退款，返回退款流水 ID。
+// 先在数据库中条件更新预占退款金额（累计退款不超过已扣款金额，防止并发超退），
+// 再调用支付服务退款（退款流水 ID 作为幂等键），失败时释放预占；成功后原子写入退款流水、负向结算记录与收入记录。
+// 按原结算的平台抽成与税费比例拆分司机、平台承担与冲减税费的部分。
+func (s *SettlementAppService) RefundBooking(in dto.RefundBookingInput) (string, error) {
+	if !settlemententity.ValidRefundReason(in.ReasonCode) {
+		return "", errors.New("invalid reason_code")
+	}
+	if in.AmountCents < 0 {
+		return "", errors.New("amount_cents must be >= 0")
+	}
+	saga, err := s.repo.GetSettlementSagaByBookingID(in.BookingID)
+	if err != nil {
+		return "", err
+	}
+	if saga == nil || (saga.Status != settlemententity.SagaRecordsSaved && saga.Status != settlemententity.SagaCompleted) {
+		return "", errors.New("booking has not been settled")
+	}
+	payment, err := s.repo.GetPaymentTransactionByBookingID(in.BookingID)
+	if err != nil {
+		return "", err
+	}
+	if payment == nil {
+		return "", errors.New("payment transaction not found")
+	}
+	amountCents := in.AmountCents
+	if amountCents == 0 {
+		amountCents = payment.RefundableCents()
+	}
+	if amountCents == 0 {
+		return "", errors.New("nothing left to refund")
+	}
+	refundedBefore, err := s.repo.ReserveRefund(payment.ID, amountCents)
+	if err != nil {
+		return "", err
+	}
+	refund, sr, rr, entry, err := s.buildRefund(saga, payment, in, amountCents, refundedBefore)
+	if err == nil {
+		if err = s.pay.Refund(in.BookingID, refund.ID, amountCents); err != nil {
+			err = fmt.Errorf("refund: %w", err)
+		}
+	}
+	if err != nil {
+		if rerr := s.repo.ReleaseRefund(payment.ID, amountCents); rerr != nil {
+			log.Printf("[settlement] release refund reservation booking=%s amount_cents=%d: %v", in.BookingID, amountCents, rerr)
+		}
+		return "", err
+	}
+	if err := s.repo.SaveRefund(payment, refund, sr, rr, []*settlemententity.JournalEntry{entry}); err != nil {
+		// 支付侧已退款但未落库，预占保留，记录退款 ID 以便人工核对
+		log.Printf("[settlement] refund %s booking=%s amount_cents=%d succeeded but save failed: %v", refund.ID, in.BookingID, amountCents, err)
+		return "", err
+	}
+	s.bus.Publish(evt.RevenueUpdated{BookingID: in.BookingID, DeltaCents: rr.DeltaCents})
+	return refund.ID, nil
+}
+
+// buildRefund 按预占前的累计退款金额生成退款流水、负向结算与收入记录及退款分录。
+func (s *SettlementAppService) buildRefund(saga *settlemententity.SettlementSaga, payment *settlemententity.PaymentTransaction, in dto.RefundBookingInput, amountCents, refundedBefore int64) (
+	*settlemententity.PaymentTransaction, *settlemententity.SettlementRecord, *settlemententity.RevenueRecord, *settlemententity.JournalEntry, error) {
+	now := time.Now()
+	payment.RefundedCents = refundedBefore
+	if err := payment.MarkRefunded(amountCents, now); err != nil {
+		return nil, nil, nil, nil, err
+	}
+	refund, err := s.paymentTxService.CreateRefundTransaction(&settlesvc.CreateRefundTransactionCmd{
+		BookingID:   in.BookingID,
 		AmountCents: amountCents,
-		Status:      "success",
+		Currency:    saga.Currency,
+		ReasonCode:  in.ReasonCode,
+	})
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	refund.ID = util.NewID()
+	refund.RefundedAt = &now
+	sr, rr, err := s.settlementService.CreateRefundAdjustment(&settlesvc.CreateRefundAdjustmentCmd{
+		BookingID:            saga.BookingID,
+		DriverID:             saga.DriverID,
+		PassengerID:          saga.PassengerID,
+		AmountCents:          saga.AmountCents,
+		PlatformRevenueCents: saga.PlatformRevenueCents,
+		TaxCents:             saga.TaxCents,
+		RefundedBeforeCents:  refundedBefore,
+		RefundCents:          amountCents,
+		Currency:             saga.Currency,
 	})
 	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	sr.ID = util.NewID()
+	rr.ID = util.NewID()
+	entry, err := s.ledgerService.PostRefund(&settlesvc.PostRefundCmd{
+		BookingID:     in.BookingID,
+		DriverID:      saga.DriverID,
+		RefundCents:   amountCents,
+		PlatformCents: -rr.DeltaCents,
+		TaxCents:      -sr.TaxCents,
+		Currency:      saga.Currency,
+	})
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	entry.ID = util.NewID()
+	return refund, sr, rr, entry, nil
+}
+
+// ListAccountBalances 按账户与币种查询余额；account 非空时只查该账户，否则按 accountType 过滤（为空返回全部）。
+func (s *SettlementAppService) ListAccountBalances(account, accountType string) ([]dto.AccountBalanceDTO, error) {
+	var balances []settlemententity.AccountBalance
+	var err error
+	if account != "" {
+		balances, err = s.repo.GetAccountBalances(account)
+	} else {
+		balances, err = s.repo.ListAccountBalances(accountType)
+	}
+	if err != nil {
+		return nil, err
+	}
+	res := make([]dto.AccountBalanceDTO, 0, len(balances))
+	for _, b := range balances {
+		res = append(res, dto.AccountBalanceDTO{Account: b.Account, Currency: b.Currency, DebitCents: b.DebitCents, CreditCents: b.CreditCents, BalanceCents: b.BalanceCents()})
+	}
+	return res, nil
+}
+
+// CheckLedger 校验复式记账不变量：全部借方合计等于贷方合计，且每笔分录借贷平衡。
+func (s *SettlementAppService) CheckLedger() (dto.LedgerCheckDTO, error) {
+	debits, credits, err := s.repo.LedgerTotals()
+	if err != nil {
+		return dto.LedgerCheckDTO{}, err
+	}
+	unbalanced, err := s.repo.ListUnbalancedJournalEntries()
+	if err != nil {
+		return dto.LedgerCheckDTO{}, err
+	}
+	res := dto.LedgerCheckDTO{
+		DebitCents:        debits,
+		CreditCents:       credits,
+		Balanced:          debits == credits && len(unbalanced) == 0,
+		UnbalancedEntries: unbalanced,
+	}
+	if !res.Balanced {
+		log.Printf("[settlement] ledger invariant violated: debits=%d credits=%d unbalanced_entries=%v", debits, credits, unbalanced)
+	}
+	return res, nil
+}
+
+// RevenueReport 按币种汇总平台收入，并按汇率表折算到报表币种；未配置汇率表时以默认币种报表。
+// 汇率表缺少某币种时返回错误，避免报表漏算。
+func (s *SettlementAppService) RevenueReport() (dto.RevenueReportDTO, error) {
+	records, err := s.repo.ListRevenueRecords()
+	if err != nil {
+		return dto.RevenueReportDTO{}, err
+	}
+	var currencies []string
+	sums := make(map[string]money.Money)
+	for _, r := range records {
+		c := r.Currency
+		if c == "" {
+			c = money.DefaultCurrency
+		}
+		if _, ok := sums[c]; !ok {
+			currencies = append(currencies, c)
+		}
+		sums[c] = sums[c].Add(money.FromCents(r.DeltaCents))
+	}
+	sort.Strings(currencies)
+	rates := s.rates
+	if rates == nil {
+		if rates, err = money.NewRateTable(money.DefaultCurrency, nil); err != nil {
+			return dto.RevenueReportDTO{}, err
+		}
+	}
+	res := dto.RevenueReportDTO{ReportingCurrency: rates.ReportingCurrency, ByCurrency: make([]dto.CurrencyRevenueDTO, 0, len(currencies))}
+	var total money.Money
+	for _, c := range currencies {
+		rate, err := rates.Rate(c)
+		if err != nil {
+			return dto.RevenueReportDTO{}, err
+		}
+		converted, err := rates.Convert(sums[c], c)
+		if err != nil {
+			return dto.RevenueReportDTO{}, err
+		}
+		total = total.Add(converted)
+		res.ByCurrency = append(res.ByCurrency, dto.CurrencyRevenueDTO{
+			Currency: c, AmountCents: sums[c].Cents(), Rate: rate, ConvertedCents: converted.Cents(),
+		})
+	}
+	res.TotalCents = total.Cents()
+	return res, nil
+}
+
+// ResumeStuckSagas 续跑 updated_at 早于 before 的未完成 saga（如进程在步骤之间崩溃），返回处理的数量。
+func (s *SettlementAppService) ResumeStuckSagas(before time.Time, limit int) (int, error) {
+	sagas, err := s.repo.ListStuckSettlementSagas(before, limit)
+	if err != nil {
+		return 0, err
+	}
+	var errs []error
+	for _, saga := range sagas {
+		log.Printf("[settlement] resume saga booking=%s status=%s attempts=%d", saga.BookingID, saga.Status, saga.Attempts)
+		if err := s.advance(saga); err != nil {
+			errs = append(errs, fmt.Errorf("booking %s: %w", saga.BookingID, err))
+		}
+	}
+	return len(sagas), errors.Join(errs...)
+}
+
+func (s *SettlementAppService) startSaga(bookingID string) (*settlemententity.SettlementSaga, error) {
+	b, err := s.orderRepo.GetBookingByID(bookingID)
+	if err != nil || b == nil {
+		return nil, errors.New("booking not found")
+	}
+	fare, err := s.fare(b, 0)
+	if err != nil {
+		return nil, err
+	}
+	discount, err := s.redeemPromotion(b, fare)
+	if err != nil {
+		return nil, err
+	}
+	if discount > 0 {
+		if fare, err = s.fare(b, discount); err != nil {
+			return nil, err
+		}
+	}
+	token, err := s.paymentToken(b)
+	if err != nil {
+		return nil, err
+	}
+	saga, err := settlemententity.NewSettlementSaga(util.NewID(), bookingID, b.DriverID, b.PassengerID, fare.TotalCents, fare.PlatformCents)
+	if err != nil {
+		return nil, err
+	}
+	saga.PaymentMethod = token
+	saga.TaxCents = fare.TaxCents
+	saga.Currency = fare.Currency
+	saga.FareItems = fare.Items
+	if err := s.repo.SaveSettlementSaga(saga); err != nil {
+		return nil, err
+	}
+	return saga, nil
+}
+
+// advance 依次执行 saga 的剩余步骤，直到终态或某一步失败。
+func (s *SettlementAppService) advance(saga *settlemententity.SettlementSaga) error {
+	for !saga.Finished() {
+		var err error
+		switch saga.Status {
+		case settlemententity.SagaPaymentPending:
+			err = s.charge(saga)
+		case settlemententity.SagaCharged:
+			err = s.saveRecords(saga)
+		case settlemententity.SagaRecordsSaved:
+			err = s.publish(saga)
+		case settlemententity.SagaCompensating:
+			err = s.refund(saga)
+		default:
+			return fmt.Errorf("unknown settlement saga status %q", saga.Status)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if saga.Status != settlemententity.SagaCompleted {
+		log.Printf("[settlement] saga booking=%s ended as %s: %s", saga.BookingID, saga.Status, saga.LastError)
+	}
+	if saga.Status == settlemententity.SagaFailed {
+		// 扣款失败，释放冻结金额（尽力而为）
+		if err := s.voidAuthorization(saga.BookingID); err != nil {
+			log.Printf("[settlement] void authorization booking=%s error: %v", saga.BookingID, err)
+		}
+	}
+	if saga.Status != settlemententity.SagaCompleted && s.promotions != nil {
+		// 未成功结算，撤销优惠核销并退回次数与预算（尽力而为）
+		if err := s.promotions.ReleaseRedemption(saga.BookingID); err != nil {
+			log.Printf("[settlement] release promotion booking=%s error: %v", saga.BookingID, err)
+		}
+	}
+	return nil
+}
+
+// redeemPromotion 核销订单所附的优惠码并返回优惠金额，最多抵扣平台在税前车费中的分成。
+// 订单已核销时沿用原金额；优惠码不存在、已失效或超出次数与预算时记录日志并按原价结算。
+// 有效期按乘客下单时间判断。
+func (s *SettlementAppService) redeemPromotion(b *orderentity.Booking, fare *settlemententity.FareBreakdown) (int64, error) {
+	if s.promotions == nil {
+		return 0, nil
+	}
+	red, err := s.promotions.GetRedemptionByBooking(b.ID)
+	if err != nil {
+		return 0, err
+	}
+	if red != nil {
+		if red.Status != promotionentity.RedemptionRedeemed {
+			return 0, nil
+		}
+		return red.AmountCents, nil
+	}
+	req, err := s.orderRepo.GetPickupRequestByID(b.RequestID)
+	if err != nil {
+		return 0, fmt.Errorf("get pickup request %s: %w", b.RequestID, err)
+	}
+	if req.PromoCode == "" {
+		return 0, nil
+	}
+	cmd := &promosvc.RedeemCmd{
+		BookingID:   b.ID,
+		PassengerID: b.PassengerID,
+		AirportCode: b.AirportCode,
+		Currency:    fare.Currency,
+		At:          req.CreatedAt,
+	}
+	if cmd.AirportCode == "" {
+		cmd.AirportCode = req.AirportCode
+	}
+	if cmd.At.IsZero() {
+		cmd.At = time.Now()
+	}
+	cmd.AmountCents, cmd.MaxCents = fare.DiscountableCents()
+	for attempt := 1; ; attempt++ {
+		p, err := s.promotions.GetPromotion(req.PromoCode)
+		if err != nil {
+			return 0, err
+		}
+		if p == nil {
+			log.Printf("[settlement] promotion %s not found booking=%s", req.PromoCode, b.ID)
+			return 0, nil
+		}
+		if cmd.PassengerRedemptions, err = s.promotions.CountPassengerRedemptions(p.Code, b.PassengerID); err != nil {
+			return 0, err
+		}
+		red, err = s.promotionService.Redeem(p, cmd)
+		if err == nil && red == nil {
+			return 0, nil
+		}
+		if err == nil {
+			red.ID = util.NewID()
+			if err = s.promotions.Redeem(red); err == nil {
+				return red.AmountCents, nil
+			}
+			// 计算后预算被并发核销用掉：按剩余预算重新计算
+			if errors.Is(err, promotionentity.ErrPromotionBudgetExceeded) && attempt < promoRedeemAttempts {
+				continue
+			}
+		}
+		if promotionentity.IsIneligible(err) {
+			log.Printf("[settlement] promotion %s not applied booking=%s: %v", req.PromoCode, b.ID, err)
+			return 0, nil
+		}
+		return 0, err
+	}
+}
+
+func (s *SettlementAppService) charge(saga *settlemententity.SettlementSaga) error {
+	ptx, err := s.repo.GetPaymentTransactionByBookingID(saga.BookingID)
+	if err != nil {
+		return s.fail(saga, err)
+	}
+	if ptx != nil && ptx.Status == settlemententity.PaymentAuthorized && ptx.AmountCents < saga.AmountCents {
+		// 实际车费（等候费、过路费、里程超出预估等）超过预授权：撤销后按实际金额重新预授权
+		if err := s.voidAuthorization(saga.BookingID); err != nil {
+			return s.fail(saga, fmt.Errorf("void: %w", err))
+		}
+		if ptx, err = s.repo.GetPaymentTransactionByBookingID(saga.BookingID); err != nil {
+			return s.fail(saga, err)
+		}
+	}
+	if ptx == nil || ptx.Status == settlemententity.PaymentFailed || ptx.Status == settlemententity.PaymentVoided {
+		// 匹配时未能预授权：完成时补做一次
+		if _, err := s.authorize(ptx, saga.BookingID, saga.PassengerID, saga.PaymentMethod, saga.AmountCents, saga.Currency); err != nil {
+			return s.fail(saga, fmt.Errorf("authorize: %w", err))
+		}
+	}
+	err = s.pay.Capture(saga.BookingID, saga.AmountCents)
+	if errors.Is(err, settlesvc.ErrAuthorizationNotFound) {
+		// 网关侧预授权已失效（过期或被撤销），重新预授权后再扣款
+		if err = s.pay.Authorize(saga.BookingID, saga.PassengerID, saga.PaymentMethod, saga.AmountCents); err == nil {
+			err = s.pay.Capture(saga.BookingID, saga.AmountCents)
+		}
+	}
+	if err != nil {
+		return s.fail(saga, fmt.Errorf("capture: %w", err))
+	}
+	if err := saga.MarkCharged(); err != nil {
 		return err
 	}
+	// 扣款成功但状态未保存时，重试会再次扣款，依赖 PaymentService 按订单幂等
+	return s.repo.SaveSettlementSaga(saga)
+}
+
+func (s *SettlementAppService) saveRecords(saga *settlemententity.SettlementSaga) error {
+	// 支付流水标记为已扣款，与结算记录一起落库
+	ptx, err := s.capturedTransaction(saga)
+	if err != nil {
+		return s.fail(saga, err)
+	}
 	// settlement record (通过领域服务)
 	sr, err := s.settlementService.CreateSettlementRecord(&settlesvc.CreateSettlementRecordCmd{
-		BookingID:            bookingID,
-		DriverID:             b.DriverID,
-		PassengerID:          b.PassengerID,
-		AmountCents:          amountCents,
-		PlatformRevenueCents: platformRevenueCents,
+		BookingID:            saga.BookingID,
+		DriverID:             saga.DriverID,
+		PassengerID:          saga.PassengerID,
+		AmountCents:          saga.AmountCents,
+		PlatformRevenueCents: saga.PlatformRevenueCents,
+		TaxCents:             saga.TaxCents,
+		Currency:             saga.Currency,
+		FareItems:            saga.FareItems,
 	})
 	if err != nil {
-		return err
+		return s.fail(saga, err)
 	}
 	// revenue record (通过领域服务)
 	rr, err := s.settlementService.CreateRevenueRecord(&settlesvc.CreateRevenueRecordCmd{
-		BookingID:  bookingID,
-		DeltaCents: platformRevenueCents,
+		BookingID:  saga.BookingID,
+		DeltaCents: saga.PlatformRevenueCents,
+		Currency:   saga.Currency,
 	})
 	if err != nil {
-		return err
+		return s.fail(saga, err)
+	}
+	// 复式记账分录，与结算记录同一事务落库
+	entries, err := s.ledgerService.PostSettlement(&settlesvc.PostSettlementCmd{
+		BookingID:            saga.BookingID,
+		DriverID:             saga.DriverID,
+		PassengerID:          saga.PassengerID,
+		AmountCents:          saga.AmountCents,
+		PlatformRevenueCents: saga.PlatformRevenueCents,
+		TaxCents:             saga.TaxCents,
+		Currency:             saga.Currency,
+	})
+	if err != nil {
+		return s.fail(saga, err)
 	}
-	ptx.ID = util.NewID()
 	sr.ID = util.NewID()
 	rr.ID = util.NewID()
-	if err := s.repo.SaveAllInTransaction(ptx, sr, rr); err != nil {
+	for _, e := range entries {
+		e.ID = util.NewID()
+	}
+	next := *saga
+	if err := next.MarkRecordsSaved(); err != nil {
 		return err
 	}
-	// publish events
-	s.bus.Publish(evt.PaymentSucceeded{BookingID: bookingID, AmountCents: amountCents})
-	s.bus.Publish(evt.SettlementCreated{BookingID: bookingID})
-	s.bus.Publish(evt.RevenueUpdated{BookingID: bookingID, DeltaCents: platformRevenueCents})
+	if err := s.repo.SaveRecordsWithSaga(&next, ptx, sr, rr, entries); err != nil {
+		return s.fail(saga, fmt.Errorf("save records: %w", err))
+	}
+	*saga = next
 	return nil
 }
+
+func (s *SettlementAppService) publish(saga *settlemententity.SettlementSaga) error {
+	// 事件发布后、状态保存前崩溃会导致事件重复发布，消费方需幂等
+	s.bus.Publish(evt.PaymentSucceeded{BookingID: saga.BookingID, AmountCents: saga.AmountCents})
+	s.bus.Publish(evt.SettlementCreated{BookingID: saga.BookingID})
+	s.bus.Publish(evt.RevenueUpdated{BookingID: saga.BookingID, DeltaCents: saga.PlatformRevenueCents})
+	if err := saga.MarkCompleted(); err != nil {
+		return err
+	}
+	return s.repo.SaveSettlementSaga(saga)
+}
+
+func (s *SettlementAppService) refund(saga *settlemententity.SettlementSaga) error {
+	// saga ID 作为退款幂等键
+	if err := s.pay.Refund(saga.BookingID, saga.ID, saga.AmountCents); err != nil {
+		return s.fail(saga, fmt.Errorf("refund: %w", err))
+	}
+	if err := s.saveCompensationRefund(saga); err != nil {
+		log.Printf("[settlement] save refund transaction error: %v", err)
+	}
+	if err := saga.MarkRefunded(); err != nil {
+		return err
+	}
+	return s.repo.SaveSettlementSaga(saga)
+}
+
+// saveCompensationRefund 补偿退款后更新支付流水并写入退款流水（以 saga ID 为 ID，重复执行幂等）。
+// 结算记录未落库，无需冲减。
+func (s *SettlementAppService) saveCompensationRefund(saga *settlemententity.SettlementSaga) error {
+	ptx, err := s.capturedTransaction(saga)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if ptx.Status == settlemententity.PaymentCaptured {
+		if err := ptx.MarkRefunded(saga.AmountCents, now); err != nil {
+			return err
+		}
+		if err := s.repo.SavePaymentTransaction(ptx); err != nil {
+			return err
+		}
+	}
+	if saga.AmountCents == 0 {
+		return nil
+	}
+	refund, err := s.paymentTxService.CreateRefundTransaction(&settlesvc.CreateRefundTransactionCmd{
+		BookingID:   saga.BookingID,
+		AmountCents: saga.AmountCents,
+		Currency:    saga.Currency,
+		ReasonCode:  settlemententity.RefundReasonCompensation,
+	})
+	if err != nil {
+		return err
+	}
+	refund.ID = saga.ID
+	refund.RefundedAt = &now
+	return s.repo.SavePaymentTransaction(refund)
+}
+
+// authorize 预授权并保存支付流水；prev 为此前失败或撤销的流水时沿用其 ID。
+// 拒绝（ErrPaymentDeclined）时保存 failed 流水并一并返回。
+func (s *SettlementAppService) authorize(prev *settlemententity.PaymentTransaction, bookingID, passengerID, token string, amountCents int64, currency string) (*settlemententity.PaymentTransaction, error) {
+	payErr := s.pay.Authorize(bookingID, passengerID, token, amountCents)
+	if payErr != nil && !errors.Is(payErr, settlesvc.ErrPaymentDeclined) {
+		return nil, payErr
+	}
+	ptx, err := s.paymentTxService.CreatePaymentTransaction(&settlesvc.CreatePaymentTransactionCmd{
+		BookingID:   bookingID,
+		AmountCents: amountCents,
+		Currency:    currency,
+		Status:      settlemententity.PaymentAuthorized,
+	})
+	if err != nil {
+		return nil, err
+	}
+	ptx.ID = util.NewID()
+	if prev != nil {
+		ptx.ID, ptx.CreatedAt = prev.ID, prev.CreatedAt
+	}
+	now := time.Now()
+	if payErr != nil {
+		_ = ptx.MarkFailed(payErr.Error(), now)
+	} else {
+		ptx.AuthorizedAt = &now
+	}
+	if err := s.repo.SavePaymentTransaction(ptx); err != nil {
+		return nil, errors.Join(payErr, err)
+	}
+	return ptx, payErr
+}
+
+// paymentToken 返回订单所选支付方式的保险库令牌，未选择时返回空字符串（从乘客钱包扣款）。
+// 支付方式在订单进行中不能删除；之后被删除的令牌照常交给支付网关，由网关拒绝。
+func (s *SettlementAppService) paymentToken(b *orderentity.Booking) (string, error) {
+	if b.PaymentMethodID == "" {
+		return "", nil
+	}
+	if s.methods == nil {
+		return "", errors.New("payment methods are not enabled")
+	}
+	m, err := s.methods.GetPaymentMethod(b.PaymentMethodID)
+	if err != nil {
+		return "", err
+	}
+	if m == nil {
+		return "", fmt.Errorf("payment method %s not found", b.PaymentMethodID)
+	}
+	return m.Token, nil
+}
+
+// capturedTransaction 返回标记为已扣款的支付流水；早于预授权流程的订单没有流水时补建一条。
+func (s *SettlementAppService) capturedTransaction(saga *settlemententity.SettlementSaga) (*settlemententity.PaymentTransaction, error) {
+	ptx, err := s.repo.GetPaymentTransactionByBookingID(saga.BookingID)
+	if err != nil {
+		return nil, err
+	}
+	if ptx == nil {
+		if ptx, err = s.paymentTxService.CreatePaymentTransaction(&settlesvc.CreatePaymentTransactionCmd{
+			BookingID:   saga.BookingID,
+			AmountCents: saga.AmountCents,
+			Currency:    saga.Currency,
+			Status:      settlemententity.PaymentAuthorized,
+		}); err != nil {
+			return nil, err
+		}
+		ptx.ID = util.NewID()
+	}
+	if ptx.Status == settlemententity.PaymentAuthorized {
+		if err := ptx.MarkCaptured(saga.AmountCents, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+	return ptx, nil
+}
+
+func (s *SettlementAppService) voidAuthorization(bookingID string) error {
+	ptx, err := s.repo.GetPaymentTransactionByBookingID(bookingID)
+	if err != nil {
+		return err
+	}
+	if ptx == nil || ptx.Status != settlemententity.PaymentAuthorized {
+		return nil
+	}
+	if err := s.pay.Void(bookingID); err != nil {
+		return err
+	}
+	if err := ptx.MarkVoided(time.Now()); err != nil {
+		return err
+	}
+	return s.repo.SavePaymentTransaction(ptx)
+}
+
+// settleDeposit 乘客未到场时扣收请求的押金（capture 为 true），否则释放，并记录押金流水；
+// 扣收同时写入平台收入记录与复式记账分录。按押金流水状态幂等，支付网关的扣收与撤销按幂等键幂等，
+// 失败时返回错误由事件总线重试，重试会从支付网关调用重新开始。
+func (s *SettlementAppService) settleDeposit(requestID, bookingID string, capture bool) error {
+	req, err := s.orderRepo.GetPickupRequestByID(requestID)
+	if err != nil {
+		return err
+	}
+	if req == nil || req.DepositCents == 0 {
+		return nil
+	}
+	key := req.DepositHoldKey()
+	ptx, err := s.repo.GetDepositTransaction(key)
+	if err != nil {
+		return err
+	}
+	if ptx != nil && ptx.Status != settlemententity.PaymentAuthorized {
+		return nil
+	}
+	currency := req.Currency
+	if currency == "" {
+		currency = money.DefaultCurrency
+	}
+	if ptx == nil {
+		if ptx, err = s.paymentTxService.CreateDepositTransaction(&settlesvc.CreateDepositTransactionCmd{
+			HoldKey:     key,
+			AmountCents: req.DepositCents,
+			Currency:    currency,
+		}); err != nil {
+			return err
+		}
+		ptx.ID = util.NewID()
+		ptx.AuthorizedAt = &req.CreatedAt
+	}
+	now := time.Now()
+	if !capture {
+		if err := s.pay.Void(key); err != nil {
+			return err
+		}
+		if err := ptx.MarkVoided(now); err != nil {
+			return err
+		}
+		return s.repo.SavePaymentTransaction(ptx)
+	}
+	if err := s.pay.Capture(key, req.DepositCents); err != nil {
+		if !errors.Is(err, settlesvc.ErrAuthorizationNotFound) {
+			return err
+		}
+		// 押金预授权已失效，重试无法扣收，记录失败流水留待人工处理
+		log.Printf("[settlement] deposit authorization not found request=%s booking=%s", req.ID, bookingID)
+		if err := ptx.MarkFailed(err.Error(), now); err != nil {
+			return err
+		}
+		return s.repo.SavePaymentTransaction(ptx)
+	}
+	if err := ptx.MarkCaptured(req.DepositCents, now); err != nil {
+		return err
+	}
+	rr, err := s.settlementService.CreateRevenueRecord(&settlesvc.CreateRevenueRecordCmd{
+		BookingID:  bookingID,
+		DeltaCents: req.DepositCents,
+		Currency:   currency,
+	})
+	if err != nil {
+		return err
+	}
+	entry, err := s.ledgerService.PostDepositCapture(&settlesvc.PostDepositCaptureCmd{
+		BookingID:   bookingID,
+		AmountCents: req.DepositCents,
+		Currency:    currency,
+	})
+	if err != nil {
+		return err
+	}
+	rr.ID = util.NewID()
+	entry.ID = util.NewID()
+	return s.repo.SaveDepositCapture(ptx, rr, []*settlemententity.JournalEntry{entry})
+}
+
+// bookingCurrency 返回订单的结算币种；币种字段上线前的订单按默认币种结算。
+func bookingCurrency(b *orderentity.Booking) string {
+	if b.Currency == "" {
+		return money.DefaultCurrency
+	}
+	return b.Currency
+}
+
+// fare 按订单所在机场的计费规则计算车费明细；匹配时行程信息尚未上报，按默认里程估算。
+// 每公里价格与平台收入取自订单成交时按计价策略确定的结果，规则变更不影响已成交订单。
+func (s *SettlementAppService) fare(b *orderentity.Booking, discountCents int64) (*settlemententity.FareBreakdown, error) {
+	rules, ok := s.fareRules[strings.ToUpper(b.AirportCode)]
+	if !ok {
+		rules = s.defaultFareRules
+	}
+	return s.fareCalculator.Calculate(&settlesvc.CalculateFareCmd{
+		Currency:            bookingCurrency(b),
+		PricePerKm:          b.PassengerPricePerKm(),
+		PlatformMarginPerKm: b.PlatformMarginPerKm,
+		DistanceKm:          b.DistanceKm,
+		WaitingMinutes:      b.WaitingMinutes,
+		TollsCents:          b.Tolls.Cents(),
+		DiscountCents:       discountCents,
+		Rules:               rules,
+	})
+}
+
+// fail 记录步骤失败并保存 saga。转入终态（failed）或补偿（compensating）时返回 nil，
+// 由 advance 继续推进；否则返回原错误等待重试。
+func (s *SettlementAppService) fail(saga *settlemententity.SettlementSaga, cause error) error {
+	transited := saga.RecordFailure(cause, s.maxAttempts)
+	log.Printf("[settlement] saga booking=%s step failed (attempt %d): %v", saga.BookingID, saga.Attempts, cause)
+	if err := s.repo.SaveSettlementSaga(saga); err != nil {
+		return errors.Join(cause, err)
+	}
+	if transited {
+		return nil
+	}
+	return cause
+}
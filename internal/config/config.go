@@ -3,7 +3,15 @@ package config
 import (
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
+	"github.com/gavin/airport-pickup/internal/domain/money"
+	orderentity "github.com/gavin/airport-pickup/internal/domain/order/entity"
+	ordersvc "github.com/gavin/airport-pickup/internal/domain/order/service"
+	settlemententity "github.com/gavin/airport-pickup/internal/domain/settlement/entity"
+	settlesvc "github.com/gavin/airport-pickup/internal/domain/settlement/service"
+	usersvc "github.com/gavin/airport-pickup/internal/domain/user/service"
 	"gopkg.in/yaml.v3"
 )
 
@@ -20,12 +28,108 @@ type Config struct {
 		AutoMigrate bool   `yaml:"auto_migrate"` // MySQL 模式下是否自动迁移
 	} `yaml:"database"`
 
+	EventBus struct {
+		Driver string `yaml:"driver"` // kafka（默认）或 redis_streams
+		// Encoding 为发布事件的载荷编码：json（默认）或 avro；消费端按消息 content-type 自动识别。
+		Encoding string `yaml:"encoding"`
+	} `yaml:"event_bus"`
+
 	Kafka struct {
 		Brokers []string `yaml:"brokers"` // 为空则使用内存事件总线
 		Topic   string   `yaml:"topic"`
 		GroupID string   `yaml:"group"`
+		// 处理失败的事件按指数退避投递到重试 topic，超过次数后进入死信 topic
+		Retry RetryConfig `yaml:"retry"`
 	} `yaml:"kafka"`
 
+	// Redis Streams 事件总线，连接复用 Redis 配置
+	RedisStreams struct {
+		Stream       string        `yaml:"stream"`
+		Group        string        `yaml:"group"`
+		MaxLen       int64         `yaml:"max_len"`        // XADD 近似裁剪长度，0 表示不裁剪
+		ClaimMinIdle time.Duration `yaml:"claim_min_idle"` // 接管崩溃消费者消息前的最小空闲时间
+		Retry        RetryConfig   `yaml:"retry"`
+	} `yaml:"redis_streams"`
+
+	Settlement struct {
+		Saga struct {
+			MaxAttempts      int           `yaml:"max_attempts"`      // 扣款/落库失败多少次后转入失败或退款补偿
+			RecoveryInterval time.Duration `yaml:"recovery_interval"` // 恢复任务扫描间隔
+			StaleAfter       time.Duration `yaml:"stale_after"`       // 超过该时长未推进的 saga 视为卡住
+		} `yaml:"saga"`
+	} `yaml:"settlement"`
+
+	Payments struct {
+		// 本地钱包模拟器：新乘客的初始余额（分）
+		Wallet struct {
+			InitialBalanceCents int64 `yaml:"initial_balance_cents"`
+		} `yaml:"wallet"`
+	} `yaml:"payments"`
+
+	// 司机打款：定期汇总未打款的结算记录
+	Payouts struct {
+		Interval time.Duration `yaml:"interval"`  // 打款任务执行间隔，默认 24h
+		FeeCents int64         `yaml:"fee_cents"` // 每笔打款收取的手续费（分）
+	} `yaml:"payouts"`
+
+	// 币种：每个机场一个结算币种，收入报表按汇率表折算到报表币种
+	Currency struct {
+		Default   string `yaml:"default"`    // 未单独配置的机场使用的结算币种，默认 CNY
+		RatesFile string `yaml:"rates_file"` // 汇率表文件（见 config/fx_rates.yaml），为空时报表以默认币种统计
+	} `yaml:"currency"`
+
+	// 开票：乘客收据与企业客户月度发票，单据编号按开票主体连续
+	Invoicing struct {
+		Interval           time.Duration                `yaml:"interval"`             // 月度开票检查间隔，默认 24h
+		DefaultLegalEntity string                       `yaml:"default_legal_entity"` // 未单独配置的机场使用的开票主体
+		LegalEntities      map[string]LegalEntityConfig `yaml:"legal_entities"`       // 开票主体代码 -> 主体信息
+	} `yaml:"invoicing"`
+
+	// 默认计费规则，未单独配置 fare 的机场使用；为空时只按里程计费
+	Fare FareConfig `yaml:"fare"`
+
+	// 默认计价策略，决定成交价格在乘客、司机与平台之间的分配；未单独配置 pricing 的机场使用，为空时为 spread
+	Pricing PricingConfig `yaml:"pricing"`
+
+	// 溢价：按订单簿（机场 + 车型）请求数与报价数之比计算倍率，用于建议乘客出价并通知司机
+	Surge struct {
+		Sensitivity     float64       `yaml:"sensitivity"`      // 供需比每超出 1，倍率增加的幅度
+		MaxMultiplier   float64       `yaml:"max_multiplier"`   // 倍率上限，<= 1 表示不启用
+		HalfLife        time.Duration `yaml:"half_life"`        // 倍率平滑半衰期，例如 5m
+		NotifyThreshold float64       `yaml:"notify_threshold"` // 目标倍率达到该值时通知附近司机，0 表示不通知
+		NotifyLookback  time.Duration `yaml:"notify_lookback"`  // 通知最近多久在该机场发布过报价的司机，默认 24h
+	} `yaml:"surge"`
+
+	// 司机与乘客声誉分：对方评价的贝叶斯平均，评价权重随时间衰减
+	Ratings struct {
+		PriorMean   float64       `yaml:"prior_mean"`   // 先验平均分，也是新司机、新乘客的初始分，默认 4.5
+		PriorWeight float64       `yaml:"prior_weight"` // 先验相当于多少条评价，默认 5
+		HalfLife    time.Duration `yaml:"half_life"`    // 评价权重的半衰期，默认 4320h（180 天）
+		// 乘客声誉分低于该值时，创建接机请求需预授权押金；0 表示不收押金
+		PassengerDepositBelow float64 `yaml:"passenger_deposit_below"`
+		PassengerDepositCents int64   `yaml:"passenger_deposit_cents"` // 押金金额（最小货币单位）
+	} `yaml:"ratings"`
+
+	Quotes struct {
+		TTL time.Duration `yaml:"ttl"` // 报价有效期，默认 5m
+	} `yaml:"quotes"`
+
+	// 接机请求：期望接机时间过后仍未撮合的请求自动过期并释放押金
+	Requests struct {
+		ExpireAfter   time.Duration `yaml:"expire_after"`   // 期望接机时间过后多久过期，默认 30m
+		CheckInterval time.Duration `yaml:"check_interval"` // 过期检查间隔，默认 1m
+	} `yaml:"requests"`
+
+	// 司机入驻：证件文件存储与到期检查
+	Onboarding struct {
+		DocumentsDir  string        `yaml:"documents_dir"`  // 证件文件本地存储目录，默认 data/driver_documents
+		CheckInterval time.Duration `yaml:"check_interval"` // 证件到期检查间隔，默认 1h
+		SuspendBefore time.Duration `yaml:"suspend_before"` // 证件到期前多久暂停司机，默认 24h
+	} `yaml:"onboarding"`
+
+	// 按机场代码配置，如 SFO: {currency: USD}
+	Airports map[string]AirportConfig `yaml:"airports"`
+
 	Redis struct {
 		Addr     string `yaml:"addr"`
 		Password string `yaml:"password"`
@@ -33,6 +137,203 @@ type Config struct {
 	} `yaml:"redis"`
 }
 
+// AirportConfig 单个机场的配置
+type AirportConfig struct {
+	Currency    string         `yaml:"currency"`     // 结算币种，为空使用 currency.default
+	Fare        *FareConfig    `yaml:"fare"`         // 计费规则，为空使用顶层 fare
+	LegalEntity string         `yaml:"legal_entity"` // 开票主体代码，为空使用 invoicing.default_legal_entity
+	Pricing     *PricingConfig `yaml:"pricing"`      // 计价策略，为空使用顶层 pricing
+}
+
+// LegalEntityConfig 开票主体
+type LegalEntityConfig struct {
+	Name         string `yaml:"name"`
+	Address      string `yaml:"address"`
+	TaxID        string `yaml:"tax_id"`
+	NumberPrefix string `yaml:"number_prefix"` // 单据编号前缀，为空时为 "<代码>-"
+}
+
+// LegalEntities 返回默认开票主体与按机场配置的开票主体；引用了未定义的主体时返回错误。
+// 未配置任何主体时返回零值，由调用方使用内置默认主体。
+func (c *Config) LegalEntities() (settlemententity.LegalEntity, map[string]settlemententity.LegalEntity, error) {
+	lookup := func(code string) (settlemententity.LegalEntity, error) {
+		e, ok := c.Invoicing.LegalEntities[code]
+		if !ok || e.Name == "" {
+			return settlemententity.LegalEntity{}, fmt.Errorf("legal entity %q not defined in invoicing.legal_entities", code)
+		}
+		return settlemententity.LegalEntity{Code: code, Name: e.Name, Address: e.Address, TaxID: e.TaxID, NumberPrefix: e.NumberPrefix}, nil
+	}
+	var def settlemententity.LegalEntity
+	if code := c.Invoicing.DefaultLegalEntity; code != "" {
+		e, err := lookup(code)
+		if err != nil {
+			return def, nil, err
+		}
+		def = e
+	}
+	byAirport := make(map[string]settlemententity.LegalEntity)
+	for airport, a := range c.Airports {
+		if a.LegalEntity == "" {
+			continue
+		}
+		e, err := lookup(a.LegalEntity)
+		if err != nil {
+			return def, nil, fmt.Errorf("airport %s: %w", airport, err)
+		}
+		byAirport[airport] = e
+	}
+	return def, byAirport, nil
+}
+
+// FareConfig 车费明细的计费规则，金额单位为机场结算币种的分，*_share 为平台分成比例（0~1）
+type FareConfig struct {
+	BaseFareCents         int64             `yaml:"base_fare_cents"`
+	BasePlatformShare     float64           `yaml:"base_platform_share"`
+	FreeWaitingMinutes    int               `yaml:"free_waiting_minutes"`
+	WaitingPerMinuteCents int64             `yaml:"waiting_per_minute_cents"`
+	WaitingPlatformShare  float64           `yaml:"waiting_platform_share"`
+	Surcharges            []SurchargeConfig `yaml:"surcharges"`
+	TaxName               string            `yaml:"tax_name"`
+	TaxRate               float64           `yaml:"tax_rate"`
+}
+
+// SurchargeConfig 固定金额附加费，如机场接机费
+type SurchargeConfig struct {
+	Name          string  `yaml:"name"`
+	AmountCents   int64   `yaml:"amount_cents"`
+	PlatformShare float64 `yaml:"platform_share"`
+}
+
+func (f FareConfig) rules() settlesvc.FareRules {
+	r := settlesvc.FareRules{
+		BaseFareCents:         f.BaseFareCents,
+		BasePlatformShare:     f.BasePlatformShare,
+		FreeWaitingMinutes:    f.FreeWaitingMinutes,
+		WaitingPerMinuteCents: f.WaitingPerMinuteCents,
+		WaitingPlatformShare:  f.WaitingPlatformShare,
+		TaxName:               f.TaxName,
+		TaxRate:               f.TaxRate,
+	}
+	for _, s := range f.Surcharges {
+		r.Surcharges = append(r.Surcharges, settlesvc.SurchargeRule{Name: s.Name, AmountCents: s.AmountCents, PlatformShare: s.PlatformShare})
+	}
+	return r
+}
+
+// FareRules 返回默认计费规则与按机场覆盖的计费规则，任一规则不合法时返回错误。
+func (c *Config) FareRules() (settlesvc.FareRules, map[string]settlesvc.FareRules, error) {
+	def := c.Fare.rules()
+	if err := def.Validate(); err != nil {
+		return def, nil, fmt.Errorf("invalid fare rules: %w", err)
+	}
+	byAirport := make(map[string]settlesvc.FareRules)
+	for code, a := range c.Airports {
+		if a.Fare == nil {
+			continue
+		}
+		r := a.Fare.rules()
+		if err := r.Validate(); err != nil {
+			return def, nil, fmt.Errorf("invalid fare rules for airport %s: %w", code, err)
+		}
+		byAirport[code] = r
+	}
+	return def, byAirport, nil
+}
+
+// PricingConfig 计价策略：spread、offer_plus_fee、split_spread、commission 或 tiered_commission
+type PricingConfig struct {
+	Policy           string                 `yaml:"policy"`
+	FeePerKmCents    int64                  `yaml:"fee_per_km_cents"`   // offer_plus_fee
+	FeeRate          float64                `yaml:"fee_rate"`           // offer_plus_fee
+	PlatformShare    float64                `yaml:"platform_share"`     // split_spread
+	CommissionRate   float64                `yaml:"commission_rate"`    // commission
+	Tiers            []CommissionTierConfig `yaml:"tiers"`              // tiered_commission
+	VolumeWindowDays int                    `yaml:"volume_window_days"` // tiered_commission，默认 30
+}
+
+// CommissionTierConfig 司机统计窗口内完成单量不少于 min_trips 时按 rate 抽成
+type CommissionTierConfig struct {
+	MinTrips int     `yaml:"min_trips"`
+	Rate     float64 `yaml:"rate"`
+}
+
+func (p PricingConfig) policy() (ordersvc.PricingPolicy, error) {
+	t := orderentity.PricingTerms{
+		Policy: p.Policy, FeePerKm: money.FromCents(p.FeePerKmCents), FeeRate: p.FeeRate, PlatformShare: p.PlatformShare,
+		CommissionRate: p.CommissionRate, VolumeWindowDays: p.VolumeWindowDays,
+	}
+	for _, tier := range p.Tiers {
+		t.Tiers = append(t.Tiers, orderentity.CommissionTier{MinTrips: tier.MinTrips, Rate: tier.Rate})
+	}
+	return ordersvc.NewPricingPolicy(t)
+}
+
+// PricingPolicies 返回默认计价策略与按机场覆盖的计价策略，任一策略不合法时返回错误。
+func (c *Config) PricingPolicies() (ordersvc.PricingPolicies, error) {
+	def, err := c.Pricing.policy()
+	if err != nil {
+		return ordersvc.PricingPolicies{}, fmt.Errorf("invalid pricing: %w", err)
+	}
+	res := ordersvc.PricingPolicies{Default: def, ByAirport: make(map[string]ordersvc.PricingPolicy)}
+	for code, a := range c.Airports {
+		if a.Pricing == nil {
+			continue
+		}
+		p, err := a.Pricing.policy()
+		if err != nil {
+			return ordersvc.PricingPolicies{}, fmt.Errorf("invalid pricing for airport %s: %w", code, err)
+		}
+		res.ByAirport[strings.ToUpper(code)] = p
+	}
+	return res, nil
+}
+
+// SurgeRules 返回溢价规则，规则不合法时返回错误。
+func (c *Config) SurgeRules() (ordersvc.SurgeRules, error) {
+	s := c.Surge
+	r := ordersvc.SurgeRules{Sensitivity: s.Sensitivity, MaxMultiplier: s.MaxMultiplier, HalfLife: s.HalfLife, NotifyThreshold: s.NotifyThreshold}
+	if err := r.Validate(); err != nil {
+		return r, fmt.Errorf("invalid surge rules: %w", err)
+	}
+	return r, nil
+}
+
+// ReputationPolicy 返回司机声誉分的计算策略，参数不合法时返回错误。
+func (c *Config) ReputationPolicy() (usersvc.ReputationPolicy, error) {
+	r := c.Ratings
+	p := usersvc.ReputationPolicy{PriorMean: r.PriorMean, PriorWeight: r.PriorWeight, HalfLife: r.HalfLife}
+	if err := p.Validate(); err != nil {
+		return p, fmt.Errorf("invalid ratings: %w", err)
+	}
+	return p, nil
+}
+
+// AirportCurrencies 返回机场代码到结算币种的映射，未配置币种的机场不包含在内。
+func (c *Config) AirportCurrencies() map[string]string {
+	res := make(map[string]string, len(c.Airports))
+	for code, a := range c.Airports {
+		if a.Currency != "" {
+			res[code] = a.Currency
+		}
+	}
+	return res
+}
+
+// RetryConfig 描述失败事件的重试策略
+type RetryConfig struct {
+	MaxAttempts int           `yaml:"max_attempts"`
+	BaseDelay   time.Duration `yaml:"base_delay"` // 例如 1s，第 n 次重试延迟 base_delay * 2^(n-1)
+}
+
+func (r *RetryConfig) setDefaults() {
+	if r.MaxAttempts <= 0 {
+		r.MaxAttempts = 3
+	}
+	if r.BaseDelay <= 0 {
+		r.BaseDelay = time.Second
+	}
+}
+
 // Load 从给定的 YAML 文件路径加载配置
 func Load(path string) (*Config, error) {
 	b, err := os.ReadFile(path)
@@ -47,5 +348,102 @@ func Load(path string) (*Config, error) {
 	if cfg.Server.Addr == "" {
 		cfg.Server.Addr = ":8080"
 	}
+	if cfg.EventBus.Driver == "" {
+		cfg.EventBus.Driver = "kafka"
+	}
+	if cfg.EventBus.Encoding == "" {
+		cfg.EventBus.Encoding = "json"
+	}
+	cfg.Kafka.Retry.setDefaults()
+	cfg.RedisStreams.Retry.setDefaults()
+	if cfg.RedisStreams.ClaimMinIdle <= 0 {
+		cfg.RedisStreams.ClaimMinIdle = 30 * time.Second
+	}
+	saga := &cfg.Settlement.Saga
+	if saga.MaxAttempts <= 0 {
+		saga.MaxAttempts = 3
+	}
+	if saga.RecoveryInterval <= 0 {
+		saga.RecoveryInterval = 30 * time.Second
+	}
+	if saga.StaleAfter <= 0 {
+		saga.StaleAfter = 2 * time.Minute
+	}
+	if cfg.Payments.Wallet.InitialBalanceCents <= 0 {
+		cfg.Payments.Wallet.InitialBalanceCents = 100000
+	}
+	if cfg.Payouts.Interval <= 0 {
+		cfg.Payouts.Interval = 24 * time.Hour
+	}
+	if cfg.Invoicing.Interval <= 0 {
+		cfg.Invoicing.Interval = 24 * time.Hour
+	}
+	if cfg.Surge.NotifyLookback <= 0 {
+		cfg.Surge.NotifyLookback = 24 * time.Hour
+	}
+	if cfg.Ratings.PriorMean == 0 {
+		cfg.Ratings.PriorMean = usersvc.DefaultReputationPolicy.PriorMean
+	}
+	if cfg.Ratings.PriorWeight == 0 {
+		cfg.Ratings.PriorWeight = usersvc.DefaultReputationPolicy.PriorWeight
+	}
+	if cfg.Ratings.HalfLife == 0 {
+		cfg.Ratings.HalfLife = usersvc.DefaultReputationPolicy.HalfLife
+	}
+	if cfg.Quotes.TTL <= 0 {
+		cfg.Quotes.TTL = 5 * time.Minute
+	}
+	if cfg.Payouts.FeeCents < 0 {
+		cfg.Payouts.FeeCents = 0
+	}
+	if cfg.Ratings.PassengerDepositCents < 0 {
+		cfg.Ratings.PassengerDepositCents = 0
+	}
+	if cfg.Onboarding.DocumentsDir == "" {
+		cfg.Onboarding.DocumentsDir = "data/driver_documents"
+	}
+	if cfg.Requests.ExpireAfter <= 0 {
+		cfg.Requests.ExpireAfter = 30 * time.Minute
+	}
+	if cfg.Requests.CheckInterval <= 0 {
+		cfg.Requests.CheckInterval = time.Minute
+	}
+	if cfg.Onboarding.CheckInterval <= 0 {
+		cfg.Onboarding.CheckInterval = time.Hour
+	}
+	if cfg.Onboarding.SuspendBefore <= 0 {
+		cfg.Onboarding.SuspendBefore = 24 * time.Hour
+	}
+	cfg.Currency.Default = money.NormalizeCurrency(cfg.Currency.Default)
+	if cfg.Currency.Default == "" {
+		cfg.Currency.Default = money.DefaultCurrency
+	}
+	if !money.ValidCurrency(cfg.Currency.Default) {
+		return nil, fmt.Errorf("invalid currency.default %q", cfg.Currency.Default)
+	}
+	for code, a := range cfg.Airports {
+		if a.Currency != "" && !money.ValidCurrency(money.NormalizeCurrency(a.Currency)) {
+			return nil, fmt.Errorf("invalid currency %q for airport %s", a.Currency, code)
+		}
+	}
 	return &cfg, nil
 }
+
+// ratesFile 汇率表文件格式：rates 为 1 单位该币种折合多少报表币种
+type ratesFile struct {
+	ReportingCurrency string             `yaml:"reporting_currency"`
+	Rates             map[string]float64 `yaml:"rates"`
+}
+
+// LoadRates 从本地 YAML 文件加载收入报表使用的汇率表
+func LoadRates(path string) (*money.RateTable, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read rates file failed: %w", err)
+	}
+	var f ratesFile
+	if err := yaml.Unmarshal(b, &f); err != nil {
+		return nil, fmt.Errorf("unmarshal rates file failed: %w", err)
+	}
+	return money.NewRateTable(f.ReportingCurrency, f.Rates)
+}
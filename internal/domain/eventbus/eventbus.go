@@ -1,27 +1,61 @@
 package eventbus
 
-import "time"
+import (
+	"time"
+
+	"github.com/gavin/airport-pickup/internal/domain/money"
+)
 
 // Event is a domain event marker.
 type Event interface {
 	Name() string
 }
 
+// Keyed is implemented by events that belong to an aggregate. Events with the
+// same aggregate key are delivered to consumers in publish order.
+type Keyed interface {
+	AggregateKey() string
+}
+
+// AggregateKey returns the ordering key of an event, falling back to its name.
+func AggregateKey(e Event) string {
+	if k, ok := e.(Keyed); ok && k.AggregateKey() != "" {
+		return k.AggregateKey()
+	}
+	return e.Name()
+}
+
+// BookKey is the aggregate key of an order book (airport + vehicle type).
+func BookKey(airport, vehicle string) string { return airport + ":" + vehicle }
+
+// Handler handles a domain event. A non-nil error marks the delivery as failed,
+// and the bus is responsible for retrying or dead-lettering it.
+// Handlers must be idempotent because a failed event is redelivered to every
+// handler subscribed to it.
+type Handler func(Event) error
+
 // EventBus defines pub/sub interface for domain events.
 type EventBus interface {
 	Publish(evt Event)
-	Subscribe(eventName string, handler func(Event))
+	Subscribe(eventName string, handler Handler)
 }
 
 // Common domain events
 const (
-	EventOrderMatched         = "OrderMatched"
-	EventOrderCompleted       = "OrderCompleted"
-	EventPaymentSucceeded     = "PaymentSucceeded"
-	EventSettlementCreated    = "SettlementCreated"
-	EventRevenueUpdated       = "RevenueUpdated"
-	EventPickupRequestCreated = "PickupRequestCreated"
-	EventDriverOfferCreated   = "DriverOfferCreated"
+	EventOrderMatched           = "OrderMatched"
+	EventOrderCompleted         = "OrderCompleted"
+	EventPaymentSucceeded       = "PaymentSucceeded"
+	EventSettlementCreated      = "SettlementCreated"
+	EventRevenueUpdated         = "RevenueUpdated"
+	EventPickupRequestCreated   = "PickupRequestCreated"
+	EventDriverOfferCreated     = "DriverOfferCreated"
+	EventOrderCancelled         = "OrderCancelled"
+	EventPaymentAuthFailed      = "PaymentAuthorizationFailed"
+	EventSurgeStarted           = "SurgeStarted"
+	EventDriverRatingUpdated    = "DriverRatingUpdated"
+	EventPassengerRatingUpdated = "PassengerRatingUpdated"
+	EventDriverStatusChanged    = "DriverStatusChanged"
+	EventPickupRequestClosed    = "PickupRequestClosed"
 )
 
 // OrderMatched payload
@@ -32,7 +66,8 @@ type OrderMatched struct {
 	DriverOfferID string
 }
 
-func (e OrderMatched) Name() string { return EventOrderMatched }
+func (e OrderMatched) Name() string         { return EventOrderMatched }
+func (e OrderMatched) AggregateKey() string { return e.BookingID }
 
 // OrderCompleted payload
 // Emitted when a booking is completed.
@@ -40,7 +75,29 @@ type OrderCompleted struct {
 	BookingID string
 }
 
-func (e OrderCompleted) Name() string { return EventOrderCompleted }
+func (e OrderCompleted) Name() string         { return EventOrderCompleted }
+func (e OrderCompleted) AggregateKey() string { return e.BookingID }
+
+// OrderCancelled payload
+// Emitted when a booking is cancelled before completion.
+type OrderCancelled struct {
+	BookingID string
+	Reason    string
+}
+
+func (e OrderCancelled) Name() string         { return EventOrderCancelled }
+func (e OrderCancelled) AggregateKey() string { return e.BookingID }
+
+// PaymentAuthorizationFailed payload
+// Emitted when the expected fare cannot be held on the passenger's wallet.
+type PaymentAuthorizationFailed struct {
+	BookingID   string
+	AmountCents int64
+	Reason      string
+}
+
+func (e PaymentAuthorizationFailed) Name() string         { return EventPaymentAuthFailed }
+func (e PaymentAuthorizationFailed) AggregateKey() string { return e.BookingID }
 
 // PaymentSucceeded payload
 // Emitted when payment succeeds for a booking.
@@ -49,7 +106,8 @@ type PaymentSucceeded struct {
 	AmountCents int64
 }
 
-func (e PaymentSucceeded) Name() string { return EventPaymentSucceeded }
+func (e PaymentSucceeded) Name() string         { return EventPaymentSucceeded }
+func (e PaymentSucceeded) AggregateKey() string { return e.BookingID }
 
 // SettlementCreated payload
 // Emitted when settlement is created for a booking.
@@ -57,7 +115,8 @@ type SettlementCreated struct {
 	BookingID string
 }
 
-func (e SettlementCreated) Name() string { return EventSettlementCreated }
+func (e SettlementCreated) Name() string         { return EventSettlementCreated }
+func (e SettlementCreated) AggregateKey() string { return e.BookingID }
 
 // RevenueUpdated payload
 // Emitted when platform revenue is updated.
@@ -66,7 +125,8 @@ type RevenueUpdated struct {
 	DeltaCents int64
 }
 
-func (e RevenueUpdated) Name() string { return EventRevenueUpdated }
+func (e RevenueUpdated) Name() string         { return EventRevenueUpdated }
+func (e RevenueUpdated) AggregateKey() string { return e.BookingID }
 
 // PickupRequestCreated payload
 type PickupRequestCreated struct {
@@ -74,13 +134,34 @@ type PickupRequestCreated struct {
 	PassengerID      string
 	AirportCode      string
 	VehicleType      string
-	MaxPricePerKm    float64
+	MaxPricePerKm    money.Money
+	Currency         string
 	PreferHighRating bool
 	DesiredTime      time.Time
-	Status           string // open, matched, cancelled
+	Status           string  // open, matched, cancelled
+	PromoCode        string  // 结算时核销的优惠码
+	QuoteID          string  // 创建请求所用的报价，成交时沿用报价的计价策略
+	PassengerScore   float64 // 乘客声誉分，撮合时与报价的最低乘客分比较
+	DepositCents     int64   // 低声誉乘客预授权的押金
+	PaymentMethodID  string  // 结算扣款的支付方式，为空从乘客钱包扣款
+}
+
+func (e PickupRequestCreated) Name() string         { return EventPickupRequestCreated }
+func (e PickupRequestCreated) AggregateKey() string { return BookKey(e.AirportCode, e.VehicleType) }
+
+// PickupRequestClosed payload
+// Emitted when an unmatched pickup request is withdrawn by the passenger or expires.
+type PickupRequestClosed struct {
+	RequestID   string
+	PassengerID string
+	AirportCode string
+	VehicleType string
+	Status      string // cancelled, expired
+	OccurredAt  time.Time
 }
 
-func (e PickupRequestCreated) Name() string { return EventPickupRequestCreated }
+func (e PickupRequestClosed) Name() string         { return EventPickupRequestClosed }
+func (e PickupRequestClosed) AggregateKey() string { return BookKey(e.AirportCode, e.VehicleType) }
 
 // DriverOfferCreated payload
 type DriverOfferCreated struct {
@@ -88,11 +169,71 @@ type DriverOfferCreated struct {
 	DriverID      string
 	AirportCode   string
 	VehicleType   string
+	VehicleID     string
 	AvailableFrom time.Time
 	AvailableTo   time.Time
-	PricePerKm    float64
+	PricePerKm    money.Money
+	Currency      string
 	Rating        float64
 	Status        string // open, matched, cancelled
+	// 司机接受的最低乘客声誉分，0 表示不限制
+	MinPassengerScore float64
+}
+
+func (e DriverOfferCreated) Name() string         { return EventDriverOfferCreated }
+func (e DriverOfferCreated) AggregateKey() string { return BookKey(e.AirportCode, e.VehicleType) }
+
+// SurgeStarted payload
+// Emitted when the target surge multiplier of an order book rises to the notify threshold,
+// so that drivers who recently served the airport can be asked to publish offers.
+type SurgeStarted struct {
+	AirportCode       string
+	VehicleType       string
+	Multiplier        float64 // 平滑后的当前倍率
+	TargetMultiplier  float64
+	OpenRequests      int
+	OpenOffers        int
+	SuggestedBidPerKm money.Money
+	Currency          string
+	OccurredAt        time.Time
+}
+
+func (e SurgeStarted) Name() string         { return EventSurgeStarted }
+func (e SurgeStarted) AggregateKey() string { return BookKey(e.AirportCode, e.VehicleType) }
+
+// DriverRatingUpdated payload
+// Emitted when a driver's reputation score is recomputed after a passenger rating.
+type DriverRatingUpdated struct {
+	DriverID    string
+	Rating      float64
+	RatingCount int
+	OccurredAt  time.Time
+}
+
+func (e DriverRatingUpdated) Name() string         { return EventDriverRatingUpdated }
+func (e DriverRatingUpdated) AggregateKey() string { return e.DriverID }
+
+// PassengerRatingUpdated payload
+// Emitted when a passenger's reputation score is recomputed after a driver rating.
+type PassengerRatingUpdated struct {
+	PassengerID     string
+	ReputationScore float64
+	RatingCount     int
+	NoShowCount     int
+	OccurredAt      time.Time
+}
+
+func (e PassengerRatingUpdated) Name() string         { return EventPassengerRatingUpdated }
+func (e PassengerRatingUpdated) AggregateKey() string { return e.PassengerID }
+
+// DriverStatusChanged payload
+// Emitted when a driver's account status changes during onboarding, suspension or offboarding.
+type DriverStatusChanged struct {
+	DriverID   string
+	Status     string // pending, active, suspended, offboarded
+	Reason     string
+	OccurredAt time.Time
 }
 
-func (e DriverOfferCreated) Name() string { return EventDriverOfferCreated }
+func (e DriverStatusChanged) Name() string         { return EventDriverStatusChanged }
+func (e DriverStatusChanged) AggregateKey() string { return e.DriverID }
@@ -0,0 +1,27 @@
+package eventbus
+
+import "time"
+
+// StoredEvent is a domain event persisted in the append-only event store.
+type StoredEvent struct {
+	Seq          int64 // assigned by the store, increases with append order
+	Name         string
+	AggregateKey string
+	Payload      []byte
+	OccurredAt   time.Time
+}
+
+// EventFilter selects stored events; zero-value fields are ignored.
+type EventFilter struct {
+	From         time.Time
+	To           time.Time
+	Names        []string
+	AggregateKey string
+}
+
+// EventStore is an append-only log of published domain events.
+type EventStore interface {
+	Append(e *StoredEvent) error
+	// List returns matching events ordered by Seq.
+	List(filter EventFilter) ([]*StoredEvent, error)
+}
@@ -0,0 +1,80 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// DefaultCurrency 为未配置币种的机场与历史数据使用的结算币种。
+const DefaultCurrency = "CNY"
+
+// ErrCurrencyMismatch 不同币种的金额不能直接比较或相加。
+var ErrCurrencyMismatch = errors.New("currency mismatch")
+
+// ValidCurrency 校验 ISO 4217 形式的三位大写字母币种代码。
+// 所有金额均以"主单位的百分之一"保存，零小数位币种（如 JPY）同样按两位小数表示。
+func ValidCurrency(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for i := 0; i < 3; i++ {
+		if code[i] < 'A' || code[i] > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// NormalizeCurrency 去除空白并转为大写，如 " usd" -> "USD"。
+func NormalizeCurrency(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// RateTable 汇率表：Rates[c] 为 1 单位币种 c 折合多少报告币种。
+type RateTable struct {
+	ReportingCurrency string
+	Rates             map[string]float64
+}
+
+// NewRateTable 校验并创建汇率表，报告币种自身的汇率固定为 1。
+func NewRateTable(reporting string, rates map[string]float64) (*RateTable, error) {
+	reporting = NormalizeCurrency(reporting)
+	if !ValidCurrency(reporting) {
+		return nil, fmt.Errorf("invalid reporting currency %q", reporting)
+	}
+	t := &RateTable{ReportingCurrency: reporting, Rates: map[string]float64{reporting: 1}}
+	for c, r := range rates {
+		c = NormalizeCurrency(c)
+		if !ValidCurrency(c) {
+			return nil, fmt.Errorf("invalid currency %q", c)
+		}
+		if r <= 0 || math.IsNaN(r) || math.IsInf(r, 0) {
+			return nil, fmt.Errorf("invalid rate for %s", c)
+		}
+		if c == reporting && r != 1 {
+			return nil, fmt.Errorf("rate for reporting currency %s must be 1", c)
+		}
+		t.Rates[c] = r
+	}
+	return t, nil
+}
+
+// Rate 返回币种折合报告币种的汇率。
+func (t *RateTable) Rate(currency string) (float64, error) {
+	r, ok := t.Rates[currency]
+	if !ok {
+		return 0, fmt.Errorf("no exchange rate for %s", currency)
+	}
+	return r, nil
+}
+
+// Convert 将 currency 币种的金额折算为报告币种，四舍五入到分。
+func (t *RateTable) Convert(m Money, currency string) (Money, error) {
+	r, err := t.Rate(currency)
+	if err != nil {
+		return Money{}, err
+	}
+	return m.Mul(r), nil
+}
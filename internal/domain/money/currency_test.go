@@ -0,0 +1,47 @@
+package money
+
+import "testing"
+
+func TestValidCurrency(t *testing.T) {
+	for _, c := range []string{"CNY", "USD", "JPY"} {
+		if !ValidCurrency(c) {
+			t.Errorf("%s should be valid", c)
+		}
+	}
+	for _, c := range []string{"", "cny", "US", "USDT", "U1D"} {
+		if ValidCurrency(c) {
+			t.Errorf("%q should be invalid", c)
+		}
+	}
+	if NormalizeCurrency(" usd ") != "USD" {
+		t.Errorf("normalize failed")
+	}
+}
+
+func TestRateTable_Convert(t *testing.T) {
+	rt, err := NewRateTable("CNY", map[string]float64{"usd": 7.1, "JPY": 0.047})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := rt.Convert(MustParse("10.00"), "USD")
+	if err != nil || got != MustParse("71.00") {
+		t.Errorf("Convert USD = %s, %v", got, err)
+	}
+	got, _ = rt.Convert(MustParse("1234"), "JPY")
+	if got != MustParse("58.00") {
+		t.Errorf("Convert JPY = %s", got)
+	}
+	got, _ = rt.Convert(MustParse("3.21"), "CNY")
+	if got != MustParse("3.21") {
+		t.Errorf("reporting currency should convert 1:1, got %s", got)
+	}
+	if _, err := rt.Convert(MustParse("1"), "EUR"); err == nil {
+		t.Errorf("expected error for missing rate")
+	}
+	if _, err := NewRateTable("CNY", map[string]float64{"USD": 0}); err == nil {
+		t.Errorf("expected error for zero rate")
+	}
+	if _, err := NewRateTable("CNY", map[string]float64{"CNY": 2}); err == nil {
+		t.Errorf("expected error for reporting currency rate != 1")
+	}
+}
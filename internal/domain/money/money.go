@@ -0,0 +1,205 @@
+// Package money 提供精确的金额值对象，以最小货币单位（分）的整数保存，避免 float64 的精度误差。
+package money
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Scale 为小数位数：金额精确到分。
+const Scale = 2
+
+// Money 是不可变的金额值对象，零值表示 0.00。
+// 舍入规则：超过两位小数的输入按四舍五入（远离零方向）舍入到分。
+type Money struct {
+	cents int64
+}
+
+// FromCents 以分创建金额。
+func FromCents(cents int64) Money { return Money{cents: cents} }
+
+// FromFloat 以元创建金额：按 float64 的最短十进制表示解析（2.3 即 "2.3"），再四舍五入到分，
+// 因此 2.3 得到 230 分而不是截断后的 229 分。仅用于与外部 float 格式（如 Avro double）互转。
+func FromFloat(f float64) Money {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return Money{}
+	}
+	m, _ := Parse(strconv.FormatFloat(f, 'f', -1, 64))
+	return m
+}
+
+// MustParse 与 Parse 相同，解析失败时 panic，用于常量与测试。
+func MustParse(s string) Money {
+	m, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// Parse 解析十进制金额字符串，如 "12"、"-2.3"、"2.345"（四舍五入为 2.35）。
+func Parse(s string) (Money, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Money{}, errors.New("money: empty amount")
+	}
+	neg := false
+	switch s[0] {
+	case '-':
+		neg = true
+		s = s[1:]
+	case '+':
+		s = s[1:]
+	}
+	intPart, frac, _ := strings.Cut(s, ".")
+	if intPart == "" && frac == "" {
+		return Money{}, fmt.Errorf("money: invalid amount %q", s)
+	}
+	if intPart == "" {
+		intPart = "0"
+	}
+	if !allDigits(intPart) || !allDigits(frac) {
+		return Money{}, fmt.Errorf("money: invalid amount %q", s)
+	}
+	units, err := strconv.ParseInt(intPart, 10, 64)
+	if err != nil || units > math.MaxInt64/100-1 {
+		return Money{}, fmt.Errorf("money: amount out of range %q", s)
+	}
+	// 取两位小数，第三位决定是否进位
+	roundUp := len(frac) > Scale && frac[Scale] >= '5'
+	for len(frac) < Scale+1 {
+		frac += "0"
+	}
+	sub, _ := strconv.ParseInt(frac[:Scale], 10, 64)
+	cents := units*100 + sub
+	if roundUp {
+		cents++
+	}
+	if neg {
+		cents = -cents
+	}
+	return Money{cents: cents}, nil
+}
+
+func allDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Cents 返回以分表示的金额。
+func (m Money) Cents() int64 { return m.cents }
+
+// Float64 返回以元表示的近似值，仅用于展示或与外部 float 格式互转。
+func (m Money) Float64() float64 { return float64(m.cents) / 100 }
+
+// String 返回两位小数的十进制表示，如 "2.30"、"-0.05"。
+func (m Money) String() string {
+	c := m.cents
+	sign := ""
+	if c < 0 {
+		sign = "-"
+		c = -c
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, c/100, c%100)
+}
+
+func (m Money) Add(o Money) Money { return Money{cents: m.cents + o.cents} }
+func (m Money) Sub(o Money) Money { return Money{cents: m.cents - o.cents} }
+
+// MulInt 乘以整数数量，如每公里价格 × 公里数。
+func (m Money) MulInt(n int64) Money { return Money{cents: m.cents * n} }
+
+// Mul 乘以系数并四舍五入到分（远离零方向），如每公里价格 × 实际里程、附加倍率。
+func (m Money) Mul(factor float64) Money {
+	return Money{cents: int64(math.Round(float64(m.cents) * factor))}
+}
+
+// Cmp 比较两个金额：m < o 返回 -1，相等返回 0，m > o 返回 1。
+func (m Money) Cmp(o Money) int {
+	switch {
+	case m.cents < o.cents:
+		return -1
+	case m.cents > o.cents:
+		return 1
+	}
+	return 0
+}
+
+func (m Money) LessThan(o Money) bool    { return m.cents < o.cents }
+func (m Money) GreaterThan(o Money) bool { return m.cents > o.cents }
+func (m Money) IsZero() bool             { return m.cents == 0 }
+func (m Money) IsPositive() bool         { return m.cents > 0 }
+func (m Money) IsNegative() bool         { return m.cents < 0 }
+
+// Max 返回较大的金额。
+func Max(a, b Money) Money {
+	if a.cents >= b.cents {
+		return a
+	}
+	return b
+}
+
+// MarshalJSON 输出两位小数的 JSON 数字，如 2.30。
+func (m Money) MarshalJSON() ([]byte, error) { return []byte(m.String()), nil }
+
+// UnmarshalJSON 接受 JSON 数字或字符串，按十进制文本解析，不经过 float64。
+func (m *Money) UnmarshalJSON(b []byte) error {
+	s := string(b)
+	if s == "null" {
+		return nil
+	}
+	s = strings.Trim(s, `"`)
+	if strings.ContainsAny(s, "eE") { // 科学计数法由 float 兜底
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return fmt.Errorf("money: invalid amount %q", s)
+		}
+		*m = FromFloat(f)
+		return nil
+	}
+	v, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*m = v
+	return nil
+}
+
+// Value 以 DECIMAL 文本写入数据库。
+func (m Money) Value() (driver.Value, error) { return m.String(), nil }
+
+// Scan 读取 DECIMAL 列；兼容驱动返回的 []byte、string、float64 与 int64。
+func (m *Money) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*m = Money{}
+	case []byte:
+		return m.scanString(string(v))
+	case string:
+		return m.scanString(v)
+	case float64:
+		*m = FromFloat(v)
+	case int64:
+		*m = Money{cents: v * 100}
+	default:
+		return fmt.Errorf("money: cannot scan %T", src)
+	}
+	return nil
+}
+
+func (m *Money) scanString(s string) error {
+	v, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*m = v
+	return nil
+}
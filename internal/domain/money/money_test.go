@@ -0,0 +1,101 @@
+package money
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParse_Rounding(t *testing.T) {
+	cases := map[string]int64{
+		"2.3":    230,
+		"2":      200,
+		".5":     50,
+		"2.345":  235,
+		"2.344":  234,
+		"-2.345": -235,
+		"0.005":  1,
+		"+1.10":  110,
+	}
+	for in, want := range cases {
+		got, err := Parse(in)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", in, err)
+		}
+		if got.Cents() != want {
+			t.Errorf("Parse(%q) = %d, want %d", in, got.Cents(), want)
+		}
+	}
+	for _, bad := range []string{"", "abc", "1.2.3", "1e3", "-", "."} {
+		if _, err := Parse(bad); err == nil {
+			t.Errorf("Parse(%q) expected error", bad)
+		}
+	}
+}
+
+func TestFromFloat_NoTruncation(t *testing.T) {
+	// int64(2.3 * 100) == 229，Money 必须得到 230
+	if got := FromFloat(2.3).Cents(); got != 230 {
+		t.Errorf("FromFloat(2.3) = %d, want 230", got)
+	}
+	if got := FromFloat(1.005).Cents(); got != 101 {
+		t.Errorf("FromFloat(1.005) = %d, want 101", got)
+	}
+	if got := FromFloat(-0.1).Cents(); got != -10 {
+		t.Errorf("FromFloat(-0.1) = %d, want -10", got)
+	}
+}
+
+func TestMoney_Arithmetic(t *testing.T) {
+	a := MustParse("2.30")
+	b := MustParse("0.45")
+	if got := a.Add(b).String(); got != "2.75" {
+		t.Errorf("Add = %s", got)
+	}
+	if got := b.Sub(a).String(); got != "-1.85" {
+		t.Errorf("Sub = %s", got)
+	}
+	if got := a.MulInt(10).Cents(); got != 2300 {
+		t.Errorf("MulInt = %d", got)
+	}
+	if got := MustParse("0.05").Mul(1.5).Cents(); got != 8 {
+		t.Errorf("Mul should round half away from zero, got %d", got)
+	}
+	if !b.LessThan(a) || a.Cmp(a) != 0 || Max(a, b) != a {
+		t.Errorf("comparison mismatch")
+	}
+}
+
+func TestMoney_JSON(t *testing.T) {
+	var v struct {
+		Price Money `json:"price"`
+	}
+	if err := json.Unmarshal([]byte(`{"price": 2.3}`), &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.Price.Cents() != 230 {
+		t.Errorf("unmarshal number = %d", v.Price.Cents())
+	}
+	if err := json.Unmarshal([]byte(`{"price": "12.50"}`), &v); err != nil || v.Price.Cents() != 1250 {
+		t.Errorf("unmarshal string = %d, %v", v.Price.Cents(), err)
+	}
+	b, _ := json.Marshal(v)
+	if string(b) != `{"price":12.50}` {
+		t.Errorf("marshal = %s", b)
+	}
+}
+
+func TestMoney_Scan(t *testing.T) {
+	var m Money
+	for _, src := range []any{[]byte("2.30"), "2.3", 2.3} {
+		if err := m.Scan(src); err != nil || m.Cents() != 230 {
+			t.Errorf("Scan(%v) = %d, %v", src, m.Cents(), err)
+		}
+	}
+	if err := m.Scan(int64(3)); err != nil || m.Cents() != 300 {
+		t.Errorf("Scan(int64) = %d", m.Cents())
+	}
+	v, _ := MustParse("2.3").Value()
+	if v != "2.30" {
+		t.Errorf("Value = %v", v)
+	}
+}
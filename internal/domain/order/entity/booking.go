@@ -3,6 +3,8 @@ package entity
 import (
 	"errors"
 	"time"
+
+	"github.com/gavin/airport-pickup/internal/domain/money"
 )
 
 // Booking represents a matched order (成交单).
@@ -12,11 +14,30 @@ type Booking struct {
 	OfferID             string
 	PassengerID         string
 	DriverID            string
-	PricePerKm          float64
-	PlatformMarginPerKm float64
+	VehicleID           string       // 司机报价所用车辆，车辆登记上线前的订单为空
+	PaymentMethodID     string       // 取自请求，结算时从该支付方式扣款；为空从乘客钱包扣款
+	PricePerKm          money.Money  // 司机报价
+	FarePerKm           money.Money  // 乘客每公里价格，由计价策略确定
+	PlatformMarginPerKm money.Money  // 每公里平台收入，司机实得 FarePerKm - PlatformMarginPerKm
+	Pricing             PricingTerms // 成交时的计价策略
+	SurgeMultiplier     float64      // 成交时订单簿的溢价倍率，1 表示无溢价
+	Currency            string       // 与请求、报价一致
+	AirportCode         string
 	Status              string // created, completed, cancelled
-	CreatedAt           time.Time
-	UpdatedAt           time.Time
+	// 行程信息，完成订单时记录，用于计算车费明细
+	DistanceKm     float64 // 0 表示未上报，按默认里程计费
+	WaitingMinutes int
+	Tolls          money.Money // 司机垫付的过路费，原样向乘客收取
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// PassengerPricePerKm 乘客每公里价格；计价策略上线前的订单没有 FarePerKm，按司机报价计费。
+func (b *Booking) PassengerPricePerKm() money.Money {
+	if b.FarePerKm.IsZero() {
+		return b.PricePerKm
+	}
+	return b.FarePerKm
 }
 
 // MarkCompleted 将订单状态从 created 变为 completed，仅允许 created->completed
@@ -27,3 +48,29 @@ func (b *Booking) MarkCompleted() error {
 	b.Status = "completed"
 	return nil
 }
+
+// RecordTrip 记录行程里程、等候时长与过路费，仅允许在完成前记录
+func (b *Booking) RecordTrip(distanceKm float64, waitingMinutes int, tolls money.Money) error {
+	if b.Status != "created" {
+		return errors.New("booking status must be 'created' to record trip")
+	}
+	if distanceKm < 0 || waitingMinutes < 0 || tolls.IsNegative() {
+		return errors.New("distance_km, waiting_minutes and tolls must be >= 0")
+	}
+	b.DistanceKm = distanceKm
+	b.WaitingMinutes = waitingMinutes
+	b.Tolls = tolls
+	return nil
+}
+
+// CancelReasonPassengerNoShow 乘客未到场时取消订单的原因，请求预授权的押金被扣收而不是释放。
+const CancelReasonPassengerNoShow = "passenger_no_show"
+
+// MarkCancelled 取消订单，仅允许 created->cancelled
+func (b *Booking) MarkCancelled() error {
+	if b.Status != "created" {
+		return errors.New("booking status must be 'created' to mark as 'cancelled'")
+	}
+	b.Status = "cancelled"
+	return nil
+}
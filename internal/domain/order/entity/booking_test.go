@@ -2,6 +2,8 @@ package entity
 
 import (
 	"testing"
+
+	"github.com/gavin/airport-pickup/internal/domain/money"
 )
 
 func TestBooking_MarkCompleted(t *testing.T) {
@@ -20,3 +22,36 @@ func TestBooking_MarkCompleted(t *testing.T) {
 		t.Errorf("expected error for invalid status, got nil")
 	}
 }
+
+func TestBooking_MarkCancelled(t *testing.T) {
+	b := &Booking{Status: "created"}
+	if err := b.MarkCancelled(); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if b.Status != "cancelled" {
+		t.Errorf("expected status 'cancelled', got %v", b.Status)
+	}
+
+	b2 := &Booking{Status: "completed"}
+	if err := b2.MarkCancelled(); err == nil {
+		t.Errorf("expected error for invalid status, got nil")
+	}
+}
+
+func TestBooking_RecordTrip(t *testing.T) {
+	b := &Booking{Status: "created"}
+	if err := b.RecordTrip(18.5, 12, money.MustParse("25")); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if b.DistanceKm != 18.5 || b.WaitingMinutes != 12 || b.Tolls != money.MustParse("25") {
+		t.Errorf("trip not recorded: %+v", b)
+	}
+	if err := b.RecordTrip(-1, 0, money.Money{}); err == nil {
+		t.Errorf("expected error for negative distance, got nil")
+	}
+
+	b2 := &Booking{Status: "completed"}
+	if err := b2.RecordTrip(10, 0, money.Money{}); err == nil {
+		t.Errorf("expected error for invalid status, got nil")
+	}
+}
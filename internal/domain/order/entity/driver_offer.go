@@ -3,6 +3,8 @@ package entity
 import (
 	"errors"
 	"time"
+
+	"github.com/gavin/airport-pickup/internal/domain/money"
 )
 
 // DriverOffer represents a driver's offer to serve airport pickup.
@@ -10,14 +12,18 @@ type DriverOffer struct {
 	ID            string
 	DriverID      string
 	AirportCode   string
-	VehicleType   string
+	VehicleType   string // 由车辆的车型等级决定
+	VehicleID     string // 已核验的车辆
 	AvailableFrom time.Time
 	AvailableTo   time.Time
-	PricePerKm    float64
+	PricePerKm    money.Money
+	Currency      string // 机场结算币种，如 CNY
 	Rating        float64
-	Status        string // open, matched, cancelled
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
+	// MinPassengerScore 司机接受的最低乘客声誉分，0 表示不限制
+	MinPassengerScore float64
+	Status            string // open, matched, cancelled
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
 }
 
 // MarkMatched 将报价状态从 open 变为 matched，仅允许 open->matched
@@ -37,3 +43,12 @@ func (o *DriverOffer) MarkCompleted() error {
 	o.Status = "completed"
 	return nil
 }
+
+// MarkCancelled 取消报价，允许 open/matched->cancelled
+func (o *DriverOffer) MarkCancelled() error {
+	if o.Status != "open" && o.Status != "matched" {
+		return errors.New("driver offer status must be 'open' or 'matched' to mark as 'cancelled'")
+	}
+	o.Status = "cancelled"
+	return nil
+}
@@ -37,3 +37,18 @@ func TestDriverOffer_MarkCompleted(t *testing.T) {
 		t.Errorf("expected error for invalid status, got nil")
 	}
 }
+
+func TestDriverOffer_MarkCancelled(t *testing.T) {
+	o := &DriverOffer{Status: "open"}
+	if err := o.MarkCancelled(); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if o.Status != "cancelled" {
+		t.Errorf("expected status 'cancelled', got %v", o.Status)
+	}
+
+	o2 := &DriverOffer{Status: "completed"}
+	if err := o2.MarkCancelled(); err == nil {
+		t.Errorf("expected error for invalid status, got nil")
+	}
+}
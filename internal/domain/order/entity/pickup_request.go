@@ -3,6 +3,8 @@ package entity
 import (
 	"errors"
 	"time"
+
+	"github.com/gavin/airport-pickup/internal/domain/money"
 )
 
 // PickupRequest represents a passenger's request for airport pickup.
@@ -12,13 +14,22 @@ type PickupRequest struct {
 	AirportCode      string
 	VehicleType      string
 	DesiredTime      time.Time
-	MaxPricePerKm    float64
+	MaxPricePerKm    money.Money
+	Currency         string // 机场结算币种，如 CNY
 	PreferHighRating bool
-	Status           string // open, matched, cancelled
+	PromoCode        string  // 乘客附带的优惠码，结算时核销
+	QuoteID          string  // 创建时引用的报价，成交按报价锁定的计价条款
+	PassengerScore   float64 // 乘客声誉分，司机报价可设置最低乘客分
+	DepositCents     int64   // 声誉分低于阈值时预授权的押金（最小货币单位），0 表示无押金
+	PaymentMethodID  string  // 乘客选择的支付方式，为空表示从乘客钱包扣款
+	Status           string  // open, matched, completed, cancelled, expired
 	CreatedAt        time.Time
 	UpdatedAt        time.Time
 }
 
+// DepositHoldKey 押金预授权在支付网关中的幂等键，与订单车费的预授权区分开。
+func (r *PickupRequest) DepositHoldKey() string { return "deposit-" + r.ID }
+
 // MarkMatched 将请求状态从 open 变为 matched，仅允许 open->matched
 func (r *PickupRequest) MarkMatched() error {
 	if r.Status != "open" {
@@ -36,3 +47,21 @@ func (r *PickupRequest) MarkCompleted() error {
 	r.Status = "completed"
 	return nil
 }
+
+// MarkCancelled 取消请求，允许 open/matched->cancelled
+func (r *PickupRequest) MarkCancelled() error {
+	if r.Status != "open" && r.Status != "matched" {
+		return errors.New("pickup request status must be 'open' or 'matched' to mark as 'cancelled'")
+	}
+	r.Status = "cancelled"
+	return nil
+}
+
+// MarkExpired 过了期望接机时间仍未撮合的请求过期，仅允许 open->expired
+func (r *PickupRequest) MarkExpired() error {
+	if r.Status != "open" {
+		return errors.New("pickup request status must be 'open' to mark as 'expired'")
+	}
+	r.Status = "expired"
+	return nil
+}
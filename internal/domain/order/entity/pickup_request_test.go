@@ -37,3 +37,33 @@ func TestPickupRequest_MarkCompleted(t *testing.T) {
 		t.Errorf("expected error for invalid status, got nil")
 	}
 }
+
+func TestPickupRequest_MarkCancelled(t *testing.T) {
+	r := &PickupRequest{Status: "matched"}
+	if err := r.MarkCancelled(); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if r.Status != "cancelled" {
+		t.Errorf("expected status 'cancelled', got %v", r.Status)
+	}
+
+	r2 := &PickupRequest{Status: "completed"}
+	if err := r2.MarkCancelled(); err == nil {
+		t.Errorf("expected error for invalid status, got nil")
+	}
+}
+
+func TestPickupRequest_MarkExpired(t *testing.T) {
+	r := &PickupRequest{Status: "open"}
+	if err := r.MarkExpired(); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if r.Status != "expired" {
+		t.Errorf("expected status 'expired', got %v", r.Status)
+	}
+
+	r2 := &PickupRequest{Status: "matched"}
+	if err := r2.MarkExpired(); err == nil {
+		t.Errorf("expected error for invalid status, got nil")
+	}
+}
@@ -0,0 +1,32 @@
+package entity
+
+import (
+	"github.com/gavin/airport-pickup/internal/domain/money"
+)
+
+// 计价策略
+const (
+	PricingSpread           = "spread"            // 乘客按最高出价付费，平台取全部差价（默认）
+	PricingOfferPlusFee     = "offer_plus_fee"    // 乘客按司机报价加服务费付费，平台取服务费
+	PricingSplitSpread      = "split_spread"      // 平台取差价的一部分，其余让利乘客
+	PricingCommission       = "commission"        // 乘客按司机报价付费，平台按比例抽成
+	PricingTieredCommission = "tiered_commission" // 按司机近期完成单量分档抽成
+)
+
+// CommissionTier 分档抽成的一档：司机统计窗口内完成单量不少于 MinTrips 时按 Rate 抽成。
+type CommissionTier struct {
+	MinTrips int     `json:"min_trips"`
+	Rate     float64 `json:"rate"`
+}
+
+// PricingTerms 成交时采用的计价策略及参数，连同计价结果记录在 Booking 上，结算与核对可据此复现。
+type PricingTerms struct {
+	Policy           string           `json:"policy"`
+	FeePerKm         money.Money      `json:"fee_per_km,omitempty"`         // offer_plus_fee：每公里固定服务费
+	FeeRate          float64          `json:"fee_rate,omitempty"`           // offer_plus_fee：按司机报价比例加收
+	PlatformShare    float64          `json:"platform_share,omitempty"`     // split_spread：平台分得差价的比例
+	CommissionRate   float64          `json:"commission_rate,omitempty"`    // commission；tiered_commission 为实际适用的档位
+	Tiers            []CommissionTier `json:"tiers,omitempty"`              // tiered_commission，按 MinTrips 升序
+	VolumeWindowDays int              `json:"volume_window_days,omitempty"` // tiered_commission：统计司机单量的天数
+	DriverTrips      int              `json:"driver_trips,omitempty"`       // tiered_commission：成交时司机在窗口内的完成单量
+}
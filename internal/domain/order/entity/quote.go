@@ -0,0 +1,45 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/gavin/airport-pickup/internal/domain/money"
+)
+
+// PricePercentiles 订单簿中可用司机报价（每公里）的分位数。
+type PricePercentiles struct {
+	P10 money.Money `json:"p10"`
+	P25 money.Money `json:"p25"`
+	P50 money.Money `json:"p50"`
+	P75 money.Money `json:"p75"`
+	P90 money.Money `json:"p90"`
+}
+
+// Quote 按实时订单簿给乘客的报价。有效期内以报价 ID 创建的接机请求沿用报价的出价与计价条款。
+type Quote struct {
+	ID               string
+	PassengerID      string // 可选，指定后只有该乘客可使用此报价
+	AirportCode      string
+	VehicleType      string
+	DesiredTime      time.Time
+	DistanceKm       float64
+	Currency         string
+	MaxPricePerKm    money.Money // 报价锁定的乘客出价
+	BestOfferPerKm   money.Money // 当前可用的最低司机报价，订单簿为空时为零值
+	Percentiles      PricePercentiles
+	AvailableOffers  int     // 期望时间可接单的司机报价数
+	MatchProbability float64 // 按出价估计的成交概率（0~1）
+	SurgeMultiplier  float64
+	FarePerKm        money.Money // 按计价策略估计的乘客每公里价格
+	// PlatformMarginPerKm 估计的每公里平台收入，仅用于计算预估车费
+	PlatformMarginPerKm money.Money
+	EstimatedFare       money.Money // 含起步价、附加费与税费的预估总车费
+	Pricing             PricingTerms
+	CreatedAt           time.Time
+	ExpiresAt           time.Time
+}
+
+// Expired 报价在 at 时刻是否已过期。
+func (q *Quote) Expired(at time.Time) bool {
+	return !at.Before(q.ExpiresAt)
+}
@@ -1,9 +1,15 @@
 package order
 
 import (
+	"errors"
+	"time"
+
 	orderentity "github.com/gavin/airport-pickup/internal/domain/order/entity"
 )
 
+// ErrPickupRequestClosed 接机请求已被乘客撤回或已过期，不能再撮合。
+var ErrPickupRequestClosed = errors.New("pickup request is no longer open")
+
 type OrderRepository interface {
 	// pickup requests
 	SavePickupRequest(r *orderentity.PickupRequest) error
@@ -12,6 +18,14 @@ type OrderRepository interface {
 	UpdatePickupRequest(r *orderentity.PickupRequest) error
 	// 是否存在进行中的接机请求（status in: open, matched）
 	HasOngoingPickupRequest(passengerID string) (bool, error)
+	// 是否存在使用该支付方式的进行中接机请求，进行中的请求扣款前不能删除其支付方式
+	HasOngoingPickupRequestWithPaymentMethod(paymentMethodID string) (bool, error)
+	// 乘客声誉分更新后同步到其 open 请求，撮合按请求上的乘客分检查报价的最低乘客分
+	UpdateOpenRequestPassengerScores(passengerID string, score float64) error
+	// 仅当请求仍为 open 时保存其新状态（cancelled 或 expired），已被撮合或关闭时返回 false
+	ClosePickupRequest(r *orderentity.PickupRequest) (bool, error)
+	// 期望接机时间早于 desiredBefore 的 open 请求，按期望时间排序，供过期任务关闭
+	ListExpiredPickupRequests(desiredBefore time.Time, limit int) ([]*orderentity.PickupRequest, error)
 
 	// driver offers
 	SaveDriverOffer(o *orderentity.DriverOffer) error
@@ -20,12 +34,25 @@ type OrderRepository interface {
 	UpdateDriverOffer(o *orderentity.DriverOffer) error
 	// 是否存在进行中的司机报价（status in: open, matched）
 	HasOngoingDriverOffer(driverID string) (bool, error)
+	// 司机声誉分更新后同步到其 open 报价，撮合按报价上的评分排序
+	UpdateOpenOfferRatings(driverID string, rating float64) error
+	// 司机被暂停或退出平台时取消其 open 报价
+	CancelOpenDriverOffers(driverID string) error
+	// 自 since 起在该机场发布过该车型报价、且当前没有进行中报价的司机，用于溢价时通知附近司机
+	ListIdleDriversAtAirport(airportCode, vehicleType string, since time.Time) ([]string, error)
 
 	// bookings
 	SaveBooking(b *orderentity.Booking) error
 	GetBookingByID(id string) (*orderentity.Booking, error)
 	ListBookings() ([]*orderentity.Booking, error)
 	UpdateBooking(b *orderentity.Booking) error
-	// 新增：原子更新三对象
+	// 司机自 since 起完成的订单数，用于按单量分档抽成
+	CountCompletedBookingsByDriver(driverID string, since time.Time) (int, error)
+	// quotes
+	SaveQuote(q *orderentity.Quote) error
+	// 报价不存在时返回 nil, nil
+	GetQuoteByID(id string) (*orderentity.Quote, error)
+
+	// 新增：原子更新三对象；请求标记为 matched 时若已被撤回或过期返回 ErrPickupRequestClosed
 	UpdateAllInTransaction(b *orderentity.Booking, r *orderentity.PickupRequest, o *orderentity.DriverOffer) error
 }
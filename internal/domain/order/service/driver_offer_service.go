@@ -4,6 +4,7 @@ import (
 	"errors"
 	"time"
 
+	"github.com/gavin/airport-pickup/internal/domain/money"
 	orderentity "github.com/gavin/airport-pickup/internal/domain/order/entity"
 )
 
@@ -15,10 +16,14 @@ type CreateDriverOfferCmd struct {
 	DriverID      string
 	AirportCode   string
 	VehicleType   string
+	VehicleID     string
 	AvailableFrom string
 	AvailableTo   string
-	PricePerKm    float64
+	PricePerKm    money.Money
+	Currency      string
 	Rating        float64
+	// MinPassengerScore 最低乘客声誉分，0 表示不限制
+	MinPassengerScore float64
 }
 
 // CreateDriverOffer 校验输入并创建司机报价领域对象（不生成ID，由上层或仓库负责）
@@ -32,6 +37,9 @@ func (s *DriverOfferService) CreateDriverOffer(cmd *CreateDriverOfferCmd) (*orde
 	if cmd.VehicleType == "" {
 		return nil, errors.New("vehicle_type required")
 	}
+	if cmd.VehicleID == "" {
+		return nil, errors.New("vehicle_id required")
+	}
 	from, err := time.Parse(time.RFC3339, cmd.AvailableFrom)
 	if err != nil {
 		return nil, errors.New("invalid available_from")
@@ -43,21 +51,30 @@ func (s *DriverOfferService) CreateDriverOffer(cmd *CreateDriverOfferCmd) (*orde
 	if !to.After(from) {
 		return nil, errors.New("available_to must be after available_from")
 	}
-	if cmd.PricePerKm <= 0 {
+	if !cmd.PricePerKm.IsPositive() {
 		return nil, errors.New("price_per_km required")
 	}
+	if !money.ValidCurrency(cmd.Currency) {
+		return nil, errors.New("invalid currency")
+	}
 	if cmd.Rating < 0 || cmd.Rating > 5 {
 		return nil, errors.New("invalid rating")
 	}
+	if cmd.MinPassengerScore < 0 || cmd.MinPassengerScore > 5 {
+		return nil, errors.New("min_passenger_score must be between 0 and 5")
+	}
 	return &orderentity.DriverOffer{
-		ID:            "",
-		DriverID:      cmd.DriverID,
-		AirportCode:   cmd.AirportCode,
-		VehicleType:   cmd.VehicleType,
-		AvailableFrom: from,
-		AvailableTo:   to,
-		PricePerKm:    cmd.PricePerKm,
-		Rating:        cmd.Rating,
-		Status:        "open",
+		ID:                "",
+		DriverID:          cmd.DriverID,
+		AirportCode:       cmd.AirportCode,
+		VehicleType:       cmd.VehicleType,
+		VehicleID:         cmd.VehicleID,
+		AvailableFrom:     from,
+		AvailableTo:       to,
+		PricePerKm:        cmd.PricePerKm,
+		Currency:          cmd.Currency,
+		Rating:            cmd.Rating,
+		MinPassengerScore: cmd.MinPassengerScore,
+		Status:            "open",
 	}, nil
 }
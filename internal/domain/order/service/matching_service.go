@@ -13,17 +13,19 @@ import (
 type MatchingService interface {
 	// MatchFromCandidates matches using provided candidates (e.g., from in-memory order book) without hitting repository.
 	MatchFromCandidates(req *orderentity.PickupRequest, candidates []*orderentity.DriverOffer) (*orderentity.DriverOffer, error)
-	// CreateBooking 根据请求和报价生成 Booking 领域对象
-	CreateBooking(req *orderentity.PickupRequest, offer *orderentity.DriverOffer, idGen func() string) *orderentity.Booking
+	// CreateBooking 根据请求和报价生成 Booking 领域对象，按机场的计价策略确定乘客价格与平台收入；
+	// 请求引用了报价时按报价锁定的计价策略
+	CreateBooking(req *orderentity.PickupRequest, offer *orderentity.DriverOffer, idGen func() string) (*orderentity.Booking, error)
 }
 
 type matchingService struct {
 	orderRepo order.OrderRepository
 	userRepo  user.DriverRepository
+	pricing   PricingPolicies
 }
 
-func NewMatchingService(orderRepo order.OrderRepository, userRepo user.DriverRepository) MatchingService {
-	return &matchingService{orderRepo: orderRepo, userRepo: userRepo}
+func NewMatchingService(orderRepo order.OrderRepository, userRepo user.DriverRepository, pricing PricingPolicies) MatchingService {
+	return &matchingService{orderRepo: orderRepo, userRepo: userRepo, pricing: pricing}
 }
 
 func (s *matchingService) MatchFromCandidates(req *orderentity.PickupRequest, candidates []*orderentity.DriverOffer) (*orderentity.DriverOffer, error) {
@@ -42,7 +44,15 @@ func (s *matchingService) MatchFromCandidates(req *orderentity.PickupRequest, ca
 		if !timeInRange(req.DesiredTime, o.AvailableFrom, o.AvailableTo) {
 			continue
 		}
-		if o.PricePerKm > req.MaxPricePerKm {
+		// 不同币种的价格不可比较
+		if o.Currency != req.Currency {
+			continue
+		}
+		if o.PricePerKm.GreaterThan(req.MaxPricePerKm) {
+			continue
+		}
+		// 司机设置了最低乘客分时，声誉分不足的乘客不撮合
+		if o.MinPassengerScore > 0 && req.PassengerScore < o.MinPassengerScore {
 			continue
 		}
 		filtered = append(filtered, o)
@@ -72,7 +82,7 @@ func (s *matchingService) rankAndPick(req *orderentity.PickupRequest, candidates
 	if req.PreferHighRating {
 		sort.Slice(rankedList, func(i, j int) bool {
 			if rankedList[i].rating == rankedList[j].rating {
-				return rankedList[i].offer.PricePerKm < rankedList[j].offer.PricePerKm
+				return rankedList[i].offer.PricePerKm.LessThan(rankedList[j].offer.PricePerKm)
 			}
 			return rankedList[i].rating > rankedList[j].rating
 		})
@@ -81,7 +91,7 @@ func (s *matchingService) rankAndPick(req *orderentity.PickupRequest, candidates
 			if rankedList[i].offer.PricePerKm == rankedList[j].offer.PricePerKm {
 				return rankedList[i].rating > rankedList[j].rating
 			}
-			return rankedList[i].offer.PricePerKm < rankedList[j].offer.PricePerKm
+			return rankedList[i].offer.PricePerKm.LessThan(rankedList[j].offer.PricePerKm)
 		})
 	}
 
@@ -89,21 +99,58 @@ func (s *matchingService) rankAndPick(req *orderentity.PickupRequest, candidates
 }
 
 // CreateBooking 根据请求和报价生成 Booking 领域对象
-func (s *matchingService) CreateBooking(req *orderentity.PickupRequest, offer *orderentity.DriverOffer, idGen func() string) *orderentity.Booking {
-	margin := req.MaxPricePerKm - offer.PricePerKm
-	if margin < 0 {
-		margin = 0
+func (s *matchingService) CreateBooking(req *orderentity.PickupRequest, offer *orderentity.DriverOffer, idGen func() string) (*orderentity.Booking, error) {
+	policy, err := s.policyFor(req)
+	if err != nil {
+		return nil, err
+	}
+	in := PriceInput{MaxPricePerKm: req.MaxPricePerKm, OfferPricePerKm: offer.PricePerKm}
+	if window := policy.VolumeWindow(); window > 0 {
+		if s.orderRepo == nil {
+			return nil, errors.New("order repository required for volume-based pricing")
+		}
+		trips, err := s.orderRepo.CountCompletedBookingsByDriver(offer.DriverID, time.Now().Add(-window))
+		if err != nil {
+			return nil, err
+		}
+		in.DriverTrips = trips
 	}
+	q := policy.Price(in)
 	return &orderentity.Booking{
 		ID:                  idGen(),
 		RequestID:           req.ID,
 		OfferID:             offer.ID,
 		PassengerID:         req.PassengerID,
 		DriverID:            offer.DriverID,
+		VehicleID:           offer.VehicleID,
+		PaymentMethodID:     req.PaymentMethodID,
 		PricePerKm:          offer.PricePerKm,
-		PlatformMarginPerKm: margin,
+		FarePerKm:           q.FarePerKm,
+		PlatformMarginPerKm: q.PlatformMarginPerKm,
+		Pricing:             q.Terms,
+		Currency:            offer.Currency,
+		AirportCode:         req.AirportCode,
 		Status:              "created",
+	}, nil
+}
+
+// policyFor 返回请求适用的计价策略：引用报价的请求沿用报价锁定的条款（即使报价已过期，创建请求时已校验有效期），
+// 否则使用机场当前配置的策略。
+func (s *matchingService) policyFor(req *orderentity.PickupRequest) (PricingPolicy, error) {
+	if req.QuoteID == "" {
+		return s.pricing.For(req.AirportCode), nil
+	}
+	if s.orderRepo == nil {
+		return nil, errors.New("order repository required for quoted requests")
+	}
+	q, err := s.orderRepo.GetQuoteByID(req.QuoteID)
+	if err != nil {
+		return nil, err
+	}
+	if q == nil {
+		return nil, errors.New("quote not found")
 	}
+	return NewPricingPolicy(q.Pricing)
 }
 
 func timeInRange(t, from, to time.Time) bool {
@@ -1,6 +1,8 @@
 package service
 
 import (
+	"github.com/gavin/airport-pickup/internal/domain/money"
+	order "github.com/gavin/airport-pickup/internal/domain/order"
 	"github.com/gavin/airport-pickup/internal/domain/order/entity"
 	"testing"
 	"time"
@@ -16,21 +18,21 @@ func TestMatchingService_MatchFromCandidates(t *testing.T) {
 		AirportCode:      "PVG",
 		VehicleType:      "sedan",
 		DesiredTime:      time.Date(2025, 11, 8, 10, 0, 0, 0, time.UTC),
-		MaxPricePerKm:    10,
+		MaxPricePerKm:    money.MustParse("10"),
 		PreferHighRating: true,
 	}
 	candidates := []*entity.DriverOffer{
 		{
-			ID: "1", AirportCode: "PVG", VehicleType: "sedan", AvailableFrom: time.Date(2025, 11, 8, 9, 0, 0, 0, time.UTC), AvailableTo: time.Date(2025, 11, 8, 12, 0, 0, 0, time.UTC), PricePerKm: 8, Rating: 4.9,
+			ID: "1", AirportCode: "PVG", VehicleType: "sedan", AvailableFrom: time.Date(2025, 11, 8, 9, 0, 0, 0, time.UTC), AvailableTo: time.Date(2025, 11, 8, 12, 0, 0, 0, time.UTC), PricePerKm: money.MustParse("8"), Rating: 4.9,
 		},
 		{
-			ID: "2", AirportCode: "PVG", VehicleType: "sedan", AvailableFrom: time.Date(2025, 11, 8, 8, 0, 0, 0, time.UTC), AvailableTo: time.Date(2025, 11, 8, 11, 0, 0, 0, time.UTC), PricePerKm: 9, Rating: 4.7,
+			ID: "2", AirportCode: "PVG", VehicleType: "sedan", AvailableFrom: time.Date(2025, 11, 8, 8, 0, 0, 0, time.UTC), AvailableTo: time.Date(2025, 11, 8, 11, 0, 0, 0, time.UTC), PricePerKm: money.MustParse("9"), Rating: 4.7,
 		},
 		{
-			ID: "3", AirportCode: "SHA", VehicleType: "sedan", AvailableFrom: time.Date(2025, 11, 8, 9, 0, 0, 0, time.UTC), AvailableTo: time.Date(2025, 11, 8, 12, 0, 0, 0, time.UTC), PricePerKm: 7, Rating: 4.8,
+			ID: "3", AirportCode: "SHA", VehicleType: "sedan", AvailableFrom: time.Date(2025, 11, 8, 9, 0, 0, 0, time.UTC), AvailableTo: time.Date(2025, 11, 8, 12, 0, 0, 0, time.UTC), PricePerKm: money.MustParse("7"), Rating: 4.8,
 		},
 		{
-			ID: "4", AirportCode: "PVG", VehicleType: "van", AvailableFrom: time.Date(2025, 11, 8, 9, 0, 0, 0, time.UTC), AvailableTo: time.Date(2025, 11, 8, 12, 0, 0, 0, time.UTC), PricePerKm: 8, Rating: 4.6,
+			ID: "4", AirportCode: "PVG", VehicleType: "van", AvailableFrom: time.Date(2025, 11, 8, 9, 0, 0, 0, time.UTC), AvailableTo: time.Date(2025, 11, 8, 12, 0, 0, 0, time.UTC), PricePerKm: money.MustParse("8"), Rating: 4.6,
 		},
 	}
 	best, err := svc.MatchFromCandidates(req, candidates)
@@ -42,7 +44,7 @@ func TestMatchingService_MatchFromCandidates(t *testing.T) {
 	}
 
 	// 测试无匹配
-	req2 := &entity.PickupRequest{AirportCode: "PVG", VehicleType: "suv", DesiredTime: time.Date(2025, 11, 8, 10, 0, 0, 0, time.UTC), MaxPricePerKm: 10}
+	req2 := &entity.PickupRequest{AirportCode: "PVG", VehicleType: "suv", DesiredTime: time.Date(2025, 11, 8, 10, 0, 0, 0, time.UTC), MaxPricePerKm: money.MustParse("10")}
 	best2, err2 := svc.MatchFromCandidates(req2, candidates)
 	if err2 == nil {
 		t.Errorf("expected error for no match, got nil")
@@ -54,17 +56,21 @@ func TestMatchingService_MatchFromCandidates(t *testing.T) {
 
 func TestMatchingService_CreateBooking(t *testing.T) {
 	svc := &matchingService{}
-	req := &entity.PickupRequest{ID: "req1", PassengerID: "p1", MaxPricePerKm: 10}
-	offer := &entity.DriverOffer{ID: "off1", DriverID: "d1", PricePerKm: 8}
+	req := &entity.PickupRequest{ID: "req1", PassengerID: "p1", MaxPricePerKm: money.MustParse("10"), PaymentMethodID: "pm1"}
+	offer := &entity.DriverOffer{ID: "off1", DriverID: "d1", VehicleID: "v1", PricePerKm: money.MustParse("8")}
 	idGen := func() string { return "bk1" }
-	bk := svc.CreateBooking(req, offer, idGen)
+	bk, err := svc.CreateBooking(req, offer, idGen)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
 	if bk == nil {
 		t.Fatalf("expected booking, got nil")
 	}
-	if bk.ID != "bk1" || bk.RequestID != "req1" || bk.OfferID != "off1" || bk.PassengerID != "p1" || bk.DriverID != "d1" {
+	if bk.ID != "bk1" || bk.RequestID != "req1" || bk.OfferID != "off1" || bk.PassengerID != "p1" || bk.DriverID != "d1" || bk.VehicleID != "v1" ||
+		bk.PaymentMethodID != "pm1" {
 		t.Errorf("booking fields not set correctly: %+v", bk)
 	}
-	if bk.PlatformMarginPerKm != 2 {
+	if bk.PlatformMarginPerKm != money.MustParse("2") {
 		t.Errorf("expected margin 2, got %v", bk.PlatformMarginPerKm)
 	}
 	if bk.Status != "created" {
@@ -72,9 +78,160 @@ func TestMatchingService_CreateBooking(t *testing.T) {
 	}
 
 	// 测试 margin < 0
-	offer2 := &entity.DriverOffer{ID: "off2", DriverID: "d2", PricePerKm: 12}
-	bk2 := svc.CreateBooking(req, offer2, idGen)
-	if bk2.PlatformMarginPerKm != 0 {
+	offer2 := &entity.DriverOffer{ID: "off2", DriverID: "d2", PricePerKm: money.MustParse("12")}
+	bk2, err := svc.CreateBooking(req, offer2, idGen)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if bk2.PlatformMarginPerKm != money.MustParse("0") {
 		t.Errorf("expected margin 0, got %v", bk2.PlatformMarginPerKm)
 	}
 }
+
+func TestCreateBooking_ExactMargin(t *testing.T) {
+	svc := &matchingService{}
+	req := &entity.PickupRequest{ID: "req1", PassengerID: "p1", MaxPricePerKm: money.MustParse("2.3")}
+	offer := &entity.DriverOffer{ID: "off1", DriverID: "d1", PricePerKm: money.MustParse("2.15")}
+	bk, err := svc.CreateBooking(req, offer, func() string { return "bk1" })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// float64 下 2.3 - 2.15 = 0.14999999999999991
+	if bk.PlatformMarginPerKm != money.MustParse("0.15") {
+		t.Errorf("expected margin 0.15, got %s", bk.PlatformMarginPerKm)
+	}
+}
+
+func TestMatchingService_SkipsOtherCurrencies(t *testing.T) {
+	svc := &matchingService{}
+	from, to := time.Date(2025, 11, 8, 9, 0, 0, 0, time.UTC), time.Date(2025, 11, 8, 12, 0, 0, 0, time.UTC)
+	req := &entity.PickupRequest{AirportCode: "HKG", VehicleType: "sedan", DesiredTime: time.Date(2025, 11, 8, 10, 0, 0, 0, time.UTC), MaxPricePerKm: money.MustParse("10"), Currency: "HKD"}
+	candidates := []*entity.DriverOffer{
+		// 数值更低但币种不同，不参与比较
+		{ID: "1", AirportCode: "HKG", VehicleType: "sedan", AvailableFrom: from, AvailableTo: to, PricePerKm: money.MustParse("1"), Currency: "USD"},
+		{ID: "2", AirportCode: "HKG", VehicleType: "sedan", AvailableFrom: from, AvailableTo: to, PricePerKm: money.MustParse("8"), Currency: "HKD"},
+	}
+	best, err := svc.MatchFromCandidates(req, candidates)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if best.ID != "2" {
+		t.Errorf("expected offer '2', got %s", best.ID)
+	}
+	bk, err := svc.CreateBooking(req, best, func() string { return "bk1" })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if bk.Currency != "HKD" {
+		t.Errorf("expected booking currency HKD, got %s", bk.Currency)
+	}
+
+	candidates[1].Currency = "USD"
+	if _, err := svc.MatchFromCandidates(req, candidates); err == nil {
+		t.Errorf("expected no match across currencies")
+	}
+}
+
+func TestMatchingService_EnforcesMinPassengerScore(t *testing.T) {
+	svc := &matchingService{}
+	from, to := time.Date(2025, 11, 8, 9, 0, 0, 0, time.UTC), time.Date(2025, 11, 8, 12, 0, 0, 0, time.UTC)
+	req := &entity.PickupRequest{AirportCode: "PVG", VehicleType: "sedan", DesiredTime: time.Date(2025, 11, 8, 10, 0, 0, 0, time.UTC),
+		MaxPricePerKm: money.MustParse("10"), Currency: "CNY", PassengerScore: 3.8}
+	candidates := []*entity.DriverOffer{
+		// 更便宜，但要求乘客分不低于 4
+		{ID: "1", AirportCode: "PVG", VehicleType: "sedan", AvailableFrom: from, AvailableTo: to, PricePerKm: money.MustParse("3"), Currency: "CNY", MinPassengerScore: 4},
+		{ID: "2", AirportCode: "PVG", VehicleType: "sedan", AvailableFrom: from, AvailableTo: to, PricePerKm: money.MustParse("5"), Currency: "CNY", MinPassengerScore: 3.5},
+	}
+	best, err := svc.MatchFromCandidates(req, candidates)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if best.ID != "2" {
+		t.Errorf("expected offer '2', got %s", best.ID)
+	}
+
+	req.PassengerScore = 3.2
+	if _, err := svc.MatchFromCandidates(req, candidates); err == nil {
+		t.Errorf("expected no match below every offer's minimum passenger score")
+	}
+}
+
+// tripCountRepo 只实现按司机统计完成单量
+type tripCountRepo struct {
+	order.OrderRepository
+	trips    int
+	driverID string
+	since    time.Time
+}
+
+func (r *tripCountRepo) CountCompletedBookingsByDriver(driverID string, since time.Time) (int, error) {
+	r.driverID, r.since = driverID, since
+	return r.trips, nil
+}
+
+func TestCreateBooking_AppliesAirportPricingPolicy(t *testing.T) {
+	tiered, err := NewPricingPolicy(entity.PricingTerms{Policy: entity.PricingTieredCommission, VolumeWindowDays: 7,
+		Tiers: []entity.CommissionTier{{MinTrips: 0, Rate: 0.2}, {MinTrips: 20, Rate: 0.1}}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	repo := &tripCountRepo{trips: 25}
+	svc := NewMatchingService(repo, nil, PricingPolicies{ByAirport: map[string]PricingPolicy{"SFO": tiered}})
+	req := &entity.PickupRequest{ID: "req1", PassengerID: "p1", AirportCode: "SFO", MaxPricePerKm: money.MustParse("10")}
+	offer := &entity.DriverOffer{ID: "off1", DriverID: "d1", PricePerKm: money.MustParse("8"), Currency: "USD"}
+
+	bk, err := svc.CreateBooking(req, offer, func() string { return "bk1" })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.driverID != "d1" || time.Since(repo.since) < 7*24*time.Hour-time.Minute {
+		t.Errorf("expected trips counted for d1 over 7 days, got %s since %v", repo.driverID, repo.since)
+	}
+	if bk.PricePerKm != money.MustParse("8") || bk.FarePerKm != money.MustParse("8") || bk.PlatformMarginPerKm != money.MustParse("0.8") {
+		t.Errorf("unexpected pricing: price %s fare %s margin %s", bk.PricePerKm, bk.FarePerKm, bk.PlatformMarginPerKm)
+	}
+	if bk.Pricing.Policy != entity.PricingTieredCommission || bk.Pricing.CommissionRate != 0.1 || bk.Pricing.DriverTrips != 25 {
+		t.Errorf("expected pricing terms recorded on booking, got %+v", bk.Pricing)
+	}
+
+	// 其他机场使用默认 spread
+	req.AirportCode = "PVG"
+	bk, err = svc.CreateBooking(req, offer, func() string { return "bk2" })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if bk.FarePerKm != money.MustParse("10") || bk.PlatformMarginPerKm != money.MustParse("2") || bk.Pricing.Policy != entity.PricingSpread {
+		t.Errorf("expected spread pricing, got fare %s margin %s %+v", bk.FarePerKm, bk.PlatformMarginPerKm, bk.Pricing)
+	}
+}
+
+// quoteRepo 只实现按 ID 读取报价
+type quoteRepo struct {
+	order.OrderRepository
+	quotes map[string]*entity.Quote
+}
+
+func (r *quoteRepo) GetQuoteByID(id string) (*entity.Quote, error) { return r.quotes[id], nil }
+
+func TestCreateBooking_UsesQuotedPricingTerms(t *testing.T) {
+	repo := &quoteRepo{quotes: map[string]*entity.Quote{
+		"q1": {ID: "q1", Pricing: entity.PricingTerms{Policy: entity.PricingCommission, CommissionRate: 0.15}},
+	}}
+	// 机场当前配置为 spread，报价锁定的是 commission
+	svc := NewMatchingService(repo, nil, PricingPolicies{})
+	req := &entity.PickupRequest{ID: "req1", AirportCode: "PVG", MaxPricePerKm: money.MustParse("10"), QuoteID: "q1"}
+	offer := &entity.DriverOffer{ID: "off1", DriverID: "d1", PricePerKm: money.MustParse("8"), Currency: "CNY"}
+
+	bk, err := svc.CreateBooking(req, offer, func() string { return "bk1" })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if bk.FarePerKm != money.MustParse("8") || bk.PlatformMarginPerKm != money.MustParse("1.2") || bk.Pricing.Policy != entity.PricingCommission {
+		t.Errorf("expected quoted commission pricing, got fare %s margin %s %+v", bk.FarePerKm, bk.PlatformMarginPerKm, bk.Pricing)
+	}
+
+	req.QuoteID = "missing"
+	if _, err := svc.CreateBooking(req, offer, func() string { return "bk2" }); err == nil {
+		t.Errorf("expected error for unknown quote")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"time"
 
+	"github.com/gavin/airport-pickup/internal/domain/money"
 	orderentity "github.com/gavin/airport-pickup/internal/domain/order/entity"
 )
 
@@ -16,8 +17,11 @@ type CreatePickupRequestCmd struct {
 	AirportCode      string
 	VehicleType      string
 	DesiredTime      string
-	MaxPricePerKm    float64
+	MaxPricePerKm    money.Money
+	Currency         string
 	PreferHighRating bool
+	PromoCode        string
+	PassengerScore   float64
 }
 
 // CreatePickupRequest 校验输入并创建接机请求领域对象（不生成ID，由上层或仓库负责）
@@ -35,9 +39,12 @@ func (s *PickupRequestService) CreatePickupRequest(cmd *CreatePickupRequestCmd)
 	if err != nil {
 		return nil, errors.New("invalid desired_time")
 	}
-	if cmd.MaxPricePerKm <= 0 {
+	if !cmd.MaxPricePerKm.IsPositive() {
 		return nil, errors.New("max_price_per_km required")
 	}
+	if !money.ValidCurrency(cmd.Currency) {
+		return nil, errors.New("invalid currency")
+	}
 	return &orderentity.PickupRequest{
 		ID:               "",
 		PassengerID:      cmd.PassengerID,
@@ -45,7 +52,10 @@ func (s *PickupRequestService) CreatePickupRequest(cmd *CreatePickupRequestCmd)
 		VehicleType:      cmd.VehicleType,
 		DesiredTime:      t,
 		MaxPricePerKm:    cmd.MaxPricePerKm,
+		Currency:         cmd.Currency,
 		PreferHighRating: cmd.PreferHighRating,
+		PromoCode:        cmd.PromoCode,
+		PassengerScore:   cmd.PassengerScore,
 		Status:           "open",
 	}, nil
 }
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gavin/airport-pickup/internal/domain/money"
+	orderentity "github.com/gavin/airport-pickup/internal/domain/order/entity"
+)
+
+// defaultVolumeWindowDays 分档抽成未配置统计窗口时统计司机近 30 天的完成单量。
+const defaultVolumeWindowDays = 30
+
+// PriceInput 计价输入，金额均为每公里价格。
+type PriceInput struct {
+	MaxPricePerKm   money.Money // 乘客最高出价
+	OfferPricePerKm money.Money // 司机报价
+	DriverTrips     int         // 司机在统计窗口内的完成单量，仅分档抽成使用
+}
+
+// PriceQuote 计价结果：乘客每公里价格与其中平台的部分，司机实得两者之差。
+type PriceQuote struct {
+	FarePerKm           money.Money
+	PlatformMarginPerKm money.Money
+	Terms               orderentity.PricingTerms // 记录在订单上的策略快照
+}
+
+// PricingPolicy 决定成交价格在乘客、司机与平台之间的分配。
+// 乘客每公里价格不超过其最高出价（司机报价高于出价时按报价），平台部分不超过乘客价格与司机报价之差或报价本身。
+type PricingPolicy interface {
+	Terms() orderentity.PricingTerms
+	// VolumeWindow 需要司机近期完成单量时返回统计窗口，否则为 0
+	VolumeWindow() time.Duration
+	Price(in PriceInput) PriceQuote
+}
+
+// NewPricingPolicy 校验策略参数并返回对应的计价策略；Policy 为空时使用 spread。
+func NewPricingPolicy(t orderentity.PricingTerms) (PricingPolicy, error) {
+	if t.Policy == "" {
+		t.Policy = orderentity.PricingSpread
+	}
+	switch t.Policy {
+	case orderentity.PricingSpread:
+		return spreadPolicy{terms: orderentity.PricingTerms{Policy: t.Policy}}, nil
+	case orderentity.PricingOfferPlusFee:
+		if t.FeePerKm.IsNegative() || !validRate(t.FeeRate) || (t.FeePerKm.IsZero() && t.FeeRate == 0) {
+			return nil, errors.New("offer_plus_fee requires fee_per_km >= 0 and fee_rate in [0, 1], not both zero")
+		}
+		return offerPlusFeePolicy{terms: orderentity.PricingTerms{Policy: t.Policy, FeePerKm: t.FeePerKm, FeeRate: t.FeeRate}}, nil
+	case orderentity.PricingSplitSpread:
+		if !validRate(t.PlatformShare) {
+			return nil, errors.New("split_spread requires platform_share in [0, 1]")
+		}
+		return splitSpreadPolicy{terms: orderentity.PricingTerms{Policy: t.Policy, PlatformShare: t.PlatformShare}}, nil
+	case orderentity.PricingCommission:
+		if !validRate(t.CommissionRate) {
+			return nil, errors.New("commission requires commission_rate in [0, 1]")
+		}
+		return commissionPolicy{terms: orderentity.PricingTerms{Policy: t.Policy, CommissionRate: t.CommissionRate}}, nil
+	case orderentity.PricingTieredCommission:
+		if len(t.Tiers) == 0 {
+			return nil, errors.New("tiered_commission requires tiers")
+		}
+		tiers := append([]orderentity.CommissionTier(nil), t.Tiers...)
+		sort.Slice(tiers, func(i, j int) bool { return tiers[i].MinTrips < tiers[j].MinTrips })
+		for i, tier := range tiers {
+			if tier.MinTrips < 0 || !validRate(tier.Rate) || (i > 0 && tier.MinTrips == tiers[i-1].MinTrips) {
+				return nil, fmt.Errorf("invalid commission tier min_trips=%d rate=%g", tier.MinTrips, tier.Rate)
+			}
+		}
+		days := t.VolumeWindowDays
+		if days < 0 {
+			return nil, errors.New("volume_window_days must be >= 0")
+		}
+		if days == 0 {
+			days = defaultVolumeWindowDays
+		}
+		return tieredCommissionPolicy{terms: orderentity.PricingTerms{Policy: t.Policy, Tiers: tiers, VolumeWindowDays: days}}, nil
+	}
+	return nil, fmt.Errorf("unknown pricing policy %q", t.Policy)
+}
+
+func validRate(f float64) bool { return f >= 0 && f <= 1 }
+
+// spread 乘客按最高出价付费，平台取出价与报价之差，司机实得报价。
+type spreadPolicy struct{ terms orderentity.PricingTerms }
+
+func (p spreadPolicy) Terms() orderentity.PricingTerms { return p.terms }
+func (p spreadPolicy) VolumeWindow() time.Duration     { return 0 }
+func (p spreadPolicy) Price(in PriceInput) PriceQuote {
+	return PriceQuote{
+		FarePerKm:           money.Max(in.MaxPricePerKm, in.OfferPricePerKm),
+		PlatformMarginPerKm: spread(in),
+		Terms:               p.terms,
+	}
+}
+
+// offer_plus_fee 乘客按司机报价加服务费付费，服务费 = fee_per_km + 报价 * fee_rate，不超过差价。
+type offerPlusFeePolicy struct{ terms orderentity.PricingTerms }
+
+func (p offerPlusFeePolicy) Terms() orderentity.PricingTerms { return p.terms }
+func (p offerPlusFeePolicy) VolumeWindow() time.Duration     { return 0 }
+func (p offerPlusFeePolicy) Price(in PriceInput) PriceQuote {
+	fee := p.terms.FeePerKm.Add(in.OfferPricePerKm.Mul(p.terms.FeeRate))
+	if s := spread(in); s.LessThan(fee) {
+		fee = s
+	}
+	return PriceQuote{FarePerKm: in.OfferPricePerKm.Add(fee), PlatformMarginPerKm: fee, Terms: p.terms}
+}
+
+// split_spread 平台按 platform_share 分得差价，其余让利乘客，司机实得报价。
+type splitSpreadPolicy struct{ terms orderentity.PricingTerms }
+
+func (p splitSpreadPolicy) Terms() orderentity.PricingTerms { return p.terms }
+func (p splitSpreadPolicy) VolumeWindow() time.Duration     { return 0 }
+func (p splitSpreadPolicy) Price(in PriceInput) PriceQuote {
+	margin := spread(in).Mul(p.terms.PlatformShare)
+	return PriceQuote{FarePerKm: in.OfferPricePerKm.Add(margin), PlatformMarginPerKm: margin, Terms: p.terms}
+}
+
+// commission 乘客按司机报价付费，平台按比例从报价中抽成。
+type commissionPolicy struct{ terms orderentity.PricingTerms }
+
+func (p commissionPolicy) Terms() orderentity.PricingTerms { return p.terms }
+func (p commissionPolicy) VolumeWindow() time.Duration     { return 0 }
+func (p commissionPolicy) Price(in PriceInput) PriceQuote {
+	return commissionQuote(in.OfferPricePerKm, p.terms.CommissionRate, p.terms)
+}
+
+// tiered_commission 按司机统计窗口内的完成单量选取不少于 min_trips 的最高一档抽成；低于第一档时按第一档。
+type tieredCommissionPolicy struct{ terms orderentity.PricingTerms }
+
+func (p tieredCommissionPolicy) Terms() orderentity.PricingTerms { return p.terms }
+func (p tieredCommissionPolicy) VolumeWindow() time.Duration {
+	return time.Duration(p.terms.VolumeWindowDays) * 24 * time.Hour
+}
+func (p tieredCommissionPolicy) Price(in PriceInput) PriceQuote {
+	rate := p.terms.Tiers[0].Rate
+	for _, tier := range p.terms.Tiers {
+		if in.DriverTrips >= tier.MinTrips {
+			rate = tier.Rate
+		}
+	}
+	terms := p.terms
+	terms.CommissionRate = rate
+	terms.DriverTrips = in.DriverTrips
+	return commissionQuote(in.OfferPricePerKm, rate, terms)
+}
+
+func commissionQuote(offer money.Money, rate float64, terms orderentity.PricingTerms) PriceQuote {
+	return PriceQuote{FarePerKm: offer, PlatformMarginPerKm: offer.Mul(rate), Terms: terms}
+}
+
+// spread 乘客最高出价与司机报价之差，不小于 0。
+func spread(in PriceInput) money.Money {
+	return money.Max(in.MaxPricePerKm.Sub(in.OfferPricePerKm), money.Money{})
+}
+
+// PricingPolicies 按机场选择计价策略，未单独配置的机场使用 Default；零值对所有机场使用 spread。
+type PricingPolicies struct {
+	Default   PricingPolicy
+	ByAirport map[string]PricingPolicy // 机场代码（大写） -> 计价策略
+}
+
+// For 返回机场适用的计价策略。
+func (p PricingPolicies) For(airportCode string) PricingPolicy {
+	if policy, ok := p.ByAirport[strings.ToUpper(airportCode)]; ok {
+		return policy
+	}
+	if p.Default != nil {
+		return p.Default
+	}
+	return spreadPolicy{terms: orderentity.PricingTerms{Policy: orderentity.PricingSpread}}
+}
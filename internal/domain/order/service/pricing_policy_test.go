@@ -0,0 +1,99 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/gavin/airport-pickup/internal/domain/money"
+	"github.com/gavin/airport-pickup/internal/domain/order/entity"
+)
+
+func mustPolicy(t *testing.T, terms entity.PricingTerms) PricingPolicy {
+	t.Helper()
+	p, err := NewPricingPolicy(terms)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return p
+}
+
+func TestPricingPolicies(t *testing.T) {
+	in := PriceInput{MaxPricePerKm: money.MustParse("10"), OfferPricePerKm: money.MustParse("8")}
+	cases := []struct {
+		name   string
+		terms  entity.PricingTerms
+		in     PriceInput
+		fare   string
+		margin string
+	}{
+		{"spread", entity.PricingTerms{}, in, "10", "2"},
+		{"spread offer above max", entity.PricingTerms{}, PriceInput{MaxPricePerKm: money.MustParse("10"), OfferPricePerKm: money.MustParse("12")}, "12", "0"},
+		{"offer plus fee", entity.PricingTerms{Policy: entity.PricingOfferPlusFee, FeePerKm: money.MustParse("0.5"), FeeRate: 0.1}, in, "9.3", "1.3"},
+		// 服务费不超过差价，乘客价格不超过最高出价
+		{"offer plus fee capped", entity.PricingTerms{Policy: entity.PricingOfferPlusFee, FeePerKm: money.MustParse("3")}, in, "10", "2"},
+		{"split spread", entity.PricingTerms{Policy: entity.PricingSplitSpread, PlatformShare: 0.25}, in, "8.5", "0.5"},
+		{"commission", entity.PricingTerms{Policy: entity.PricingCommission, CommissionRate: 0.15}, in, "8", "1.2"},
+	}
+	for _, c := range cases {
+		q := mustPolicy(t, c.terms).Price(c.in)
+		if q.FarePerKm != money.MustParse(c.fare) || q.PlatformMarginPerKm != money.MustParse(c.margin) {
+			t.Errorf("%s: expected fare %s margin %s, got %s %s", c.name, c.fare, c.margin, q.FarePerKm, q.PlatformMarginPerKm)
+		}
+		if q.Terms.Policy == "" {
+			t.Errorf("%s: expected policy recorded on quote", c.name)
+		}
+	}
+}
+
+func TestTieredCommission(t *testing.T) {
+	p := mustPolicy(t, entity.PricingTerms{Policy: entity.PricingTieredCommission, Tiers: []entity.CommissionTier{
+		{MinTrips: 50, Rate: 0.1}, {MinTrips: 0, Rate: 0.2}, {MinTrips: 10, Rate: 0.15},
+	}})
+	if p.VolumeWindow() <= 0 {
+		t.Fatalf("expected default volume window")
+	}
+	for trips, want := range map[int]struct {
+		rate   float64
+		margin string
+	}{0: {0.2, "2"}, 9: {0.2, "2"}, 10: {0.15, "1.5"}, 49: {0.15, "1.5"}, 120: {0.1, "1"}} {
+		margin := want.margin
+		q := p.Price(PriceInput{MaxPricePerKm: money.MustParse("12"), OfferPricePerKm: money.MustParse("10"), DriverTrips: trips})
+		if q.FarePerKm != money.MustParse("10") || q.PlatformMarginPerKm != money.MustParse(margin) {
+			t.Errorf("trips %d: expected margin %s, got fare %s margin %s", trips, margin, q.FarePerKm, q.PlatformMarginPerKm)
+		}
+		// 快照记录实际适用的档位与单量，结算可据此复现
+		if q.Terms.DriverTrips != trips || q.Terms.CommissionRate != want.rate {
+			t.Errorf("trips %d: unexpected terms %+v", trips, q.Terms)
+		}
+		if q.Terms.Tiers[0].MinTrips != 0 {
+			t.Errorf("expected tiers sorted by min_trips, got %+v", q.Terms.Tiers)
+		}
+	}
+}
+
+func TestNewPricingPolicy_Invalid(t *testing.T) {
+	for _, terms := range []entity.PricingTerms{
+		{Policy: "auction"},
+		{Policy: entity.PricingOfferPlusFee},
+		{Policy: entity.PricingOfferPlusFee, FeePerKm: money.MustParse("-1")},
+		{Policy: entity.PricingSplitSpread, PlatformShare: 1.5},
+		{Policy: entity.PricingCommission, CommissionRate: -0.1},
+		{Policy: entity.PricingTieredCommission},
+		{Policy: entity.PricingTieredCommission, Tiers: []entity.CommissionTier{{MinTrips: 5, Rate: 0.1}, {MinTrips: 5, Rate: 0.2}}},
+		{Policy: entity.PricingTieredCommission, Tiers: []entity.CommissionTier{{MinTrips: 0, Rate: 0.1}}, VolumeWindowDays: -1},
+	} {
+		if _, err := NewPricingPolicy(terms); err == nil {
+			t.Errorf("expected error for %+v", terms)
+		}
+	}
+}
+
+func TestPricingPolicies_ForAirport(t *testing.T) {
+	commission := mustPolicy(t, entity.PricingTerms{Policy: entity.PricingCommission, CommissionRate: 0.2})
+	p := PricingPolicies{ByAirport: map[string]PricingPolicy{"SFO": commission}}
+	if got := p.For("sfo").Terms().Policy; got != entity.PricingCommission {
+		t.Errorf("expected commission for SFO, got %s", got)
+	}
+	if got := p.For("PVG").Terms().Policy; got != entity.PricingSpread {
+		t.Errorf("expected spread by default, got %s", got)
+	}
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/gavin/airport-pickup/internal/domain/money"
+	orderentity "github.com/gavin/airport-pickup/internal/domain/order/entity"
+)
+
+// QuoteService 根据实时订单簿中的司机报价为乘客报价。
+type QuoteService struct {
+	pricing PricingPolicies
+}
+
+func NewQuoteService(pricing PricingPolicies) *QuoteService {
+	return &QuoteService{pricing: pricing}
+}
+
+// CreateQuoteCmd 封装报价参数；未给出出价时按最低可用报价 × 溢价倍率建议出价。
+type CreateQuoteCmd struct {
+	PassengerID     string
+	AirportCode     string
+	VehicleType     string
+	DesiredTime     time.Time
+	DistanceKm      float64
+	Currency        string
+	MaxPricePerKm   money.Money // 可选
+	SurgeMultiplier float64     // 当前溢价倍率，<1 按 1 计
+}
+
+// Quote 从订单簿 offers 中筛选同机场、车型、币种且在期望时间可接单的 open 报价，计算价格分位数、
+// 按出价估计的成交概率（可用报价中不高于出价的比例）以及按机场计价策略的乘客每公里价格。
+// 不生成 ID、有效期与总车费，由上层负责。
+func (s *QuoteService) Quote(cmd *CreateQuoteCmd, offers []*orderentity.DriverOffer) (*orderentity.Quote, error) {
+	if cmd.AirportCode == "" || cmd.VehicleType == "" {
+		return nil, errors.New("airport_code and vehicle_type required")
+	}
+	if cmd.DesiredTime.IsZero() {
+		return nil, errors.New("desired_time required")
+	}
+	if cmd.DistanceKm <= 0 {
+		return nil, errors.New("distance_km must be > 0")
+	}
+	if cmd.MaxPricePerKm.IsNegative() {
+		return nil, errors.New("max_price_per_km must be >= 0")
+	}
+	if !money.ValidCurrency(cmd.Currency) {
+		return nil, errors.New("invalid currency")
+	}
+	prices := make([]money.Money, 0, len(offers))
+	for _, o := range offers {
+		if o == nil || o.Status != "open" || o.AirportCode != cmd.AirportCode || o.VehicleType != cmd.VehicleType || o.Currency != cmd.Currency {
+			continue
+		}
+		if !timeInRange(cmd.DesiredTime, o.AvailableFrom, o.AvailableTo) {
+			continue
+		}
+		prices = append(prices, o.PricePerKm)
+	}
+	sort.Slice(prices, func(i, j int) bool { return prices[i].LessThan(prices[j]) })
+
+	multiplier := max(cmd.SurgeMultiplier, 1)
+	q := &orderentity.Quote{
+		PassengerID:     cmd.PassengerID,
+		AirportCode:     cmd.AirportCode,
+		VehicleType:     cmd.VehicleType,
+		DesiredTime:     cmd.DesiredTime,
+		DistanceKm:      cmd.DistanceKm,
+		Currency:        cmd.Currency,
+		MaxPricePerKm:   cmd.MaxPricePerKm,
+		AvailableOffers: len(prices),
+		SurgeMultiplier: multiplier,
+	}
+	if len(prices) > 0 {
+		q.BestOfferPerKm = prices[0]
+		q.Percentiles = orderentity.PricePercentiles{
+			P10: percentile(prices, 10), P25: percentile(prices, 25), P50: percentile(prices, 50),
+			P75: percentile(prices, 75), P90: percentile(prices, 90),
+		}
+	}
+	if !q.MaxPricePerKm.IsPositive() {
+		if len(prices) == 0 {
+			return nil, errors.New("no available offers, max_price_per_km required")
+		}
+		q.MaxPricePerKm = q.BestOfferPerKm.Mul(multiplier)
+	}
+	matched := sort.Search(len(prices), func(i int) bool { return prices[i].GreaterThan(q.MaxPricePerKm) })
+	if len(prices) > 0 {
+		q.MatchProbability = math.Round(float64(matched)/float64(len(prices))*100) / 100
+	}
+
+	// 订单簿为空时假设司机按出价接单来估计车费
+	offer := q.MaxPricePerKm
+	if matched > 0 {
+		offer = prices[0]
+	}
+	policy := s.pricing.For(cmd.AirportCode)
+	price := policy.Price(PriceInput{MaxPricePerKm: q.MaxPricePerKm, OfferPricePerKm: offer})
+	q.FarePerKm, q.PlatformMarginPerKm = price.FarePerKm, price.PlatformMarginPerKm
+	// 锁定策略本身而非本次计价结果，成交时按实际司机报价与单量重新计价
+	q.Pricing = policy.Terms()
+	return q, nil
+}
+
+// percentile 最近秩法求升序 prices 的第 p 百分位。
+func percentile(prices []money.Money, p int) money.Money {
+	idx := int(math.Ceil(float64(p)/100*float64(len(prices)))) - 1
+	return prices[min(max(idx, 0), len(prices)-1)]
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gavin/airport-pickup/internal/domain/money"
+	"github.com/gavin/airport-pickup/internal/domain/order/entity"
+)
+
+func quoteBook() []*entity.DriverOffer {
+	from, to := time.Date(2025, 11, 8, 9, 0, 0, 0, time.UTC), time.Date(2025, 11, 8, 12, 0, 0, 0, time.UTC)
+	offer := func(id, price string) *entity.DriverOffer {
+		return &entity.DriverOffer{ID: id, AirportCode: "PVG", VehicleType: "sedan", AvailableFrom: from, AvailableTo: to,
+			PricePerKm: money.MustParse(price), Currency: "CNY", Status: "open"}
+	}
+	late := offer("late", "1")
+	late.AvailableFrom, late.AvailableTo = to, to.Add(time.Hour)
+	other := offer("usd", "1")
+	other.Currency = "USD"
+	matched := offer("matched", "1")
+	matched.Status = "matched"
+	return []*entity.DriverOffer{offer("a", "9"), offer("b", "6"), offer("c", "8"), offer("d", "7"), late, other, matched}
+}
+
+func TestQuoteService_PercentilesAndProbability(t *testing.T) {
+	svc := NewQuoteService(PricingPolicies{})
+	cmd := &CreateQuoteCmd{AirportCode: "PVG", VehicleType: "sedan", DesiredTime: time.Date(2025, 11, 8, 10, 0, 0, 0, time.UTC),
+		DistanceKm: 20, Currency: "CNY", MaxPricePerKm: money.MustParse("7.5")}
+	q, err := svc.Quote(cmd, quoteBook())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// 不可用时间、其他币种与已成交的报价不计入
+	if q.AvailableOffers != 4 || q.BestOfferPerKm != money.MustParse("6") {
+		t.Errorf("expected 4 offers best 6, got %d best %s", q.AvailableOffers, q.BestOfferPerKm)
+	}
+	want := entity.PricePercentiles{P10: money.MustParse("6"), P25: money.MustParse("6"), P50: money.MustParse("7"),
+		P75: money.MustParse("8"), P90: money.MustParse("9")}
+	if q.Percentiles != want {
+		t.Errorf("unexpected percentiles %+v", q.Percentiles)
+	}
+	if q.MatchProbability != 0.5 {
+		t.Errorf("expected match probability 0.5, got %v", q.MatchProbability)
+	}
+	// spread：乘客按出价付费，平台取与最低报价之差
+	if q.FarePerKm != money.MustParse("7.5") || q.PlatformMarginPerKm != money.MustParse("1.5") || q.Pricing.Policy != entity.PricingSpread {
+		t.Errorf("unexpected pricing fare %s margin %s %+v", q.FarePerKm, q.PlatformMarginPerKm, q.Pricing)
+	}
+}
+
+func TestQuoteService_SuggestsBidFromSurge(t *testing.T) {
+	svc := NewQuoteService(PricingPolicies{})
+	cmd := &CreateQuoteCmd{AirportCode: "PVG", VehicleType: "sedan", DesiredTime: time.Date(2025, 11, 8, 10, 0, 0, 0, time.UTC),
+		DistanceKm: 20, Currency: "CNY", SurgeMultiplier: 1.5}
+	q, err := svc.Quote(cmd, quoteBook())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if q.MaxPricePerKm != money.MustParse("9") || q.MatchProbability != 1 || q.SurgeMultiplier != 1.5 {
+		t.Errorf("expected bid 9 with probability 1, got %s %v", q.MaxPricePerKm, q.MatchProbability)
+	}
+
+	// 订单簿为空且未给出价时无法报价
+	cmd.AirportCode = "SFO"
+	if _, err := svc.Quote(cmd, quoteBook()); err == nil {
+		t.Errorf("expected error without offers or bid")
+	}
+	cmd.MaxPricePerKm = money.MustParse("5")
+	q, err = svc.Quote(cmd, quoteBook())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if q.AvailableOffers != 0 || q.MatchProbability != 0 || q.FarePerKm != money.MustParse("5") {
+		t.Errorf("unexpected empty-book quote %+v", q)
+	}
+}
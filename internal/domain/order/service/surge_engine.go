@@ -0,0 +1,155 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/gavin/airport-pickup/internal/domain/money"
+)
+
+// SurgeRules 溢价倍率规则。MaxMultiplier <= 1 表示不启用溢价，倍率恒为 1。
+type SurgeRules struct {
+	Sensitivity     float64       // 供需比每超出 1，目标倍率增加的幅度
+	MaxMultiplier   float64       // 倍率上限
+	HalfLife        time.Duration // 平滑半衰期：倍率向目标倍率收敛，经过一个半衰期差距减半；0 表示不平滑
+	NotifyThreshold float64       // 目标倍率达到该值时通知司机；0 表示不通知
+}
+
+// Enabled 是否启用溢价。
+func (r SurgeRules) Enabled() bool { return r.MaxMultiplier > 1 }
+
+func (r SurgeRules) Validate() error {
+	if r.MaxMultiplier < 0 || r.Sensitivity < 0 || r.HalfLife < 0 || r.NotifyThreshold < 0 {
+		return errors.New("surge rules must be >= 0")
+	}
+	if r.Enabled() && r.Sensitivity == 0 {
+		return errors.New("sensitivity must be > 0 when max_multiplier > 1")
+	}
+	if r.NotifyThreshold > 0 && r.NotifyThreshold <= 1 {
+		return errors.New("notify_threshold must be > 1")
+	}
+	return nil
+}
+
+// BookSnapshot 某个订单簿（机场 + 车型）当前的供需情况。
+type BookSnapshot struct {
+	OpenRequests   int
+	OpenOffers     int
+	BestOfferPerKm money.Money // 最低司机报价，没有报价时为零值
+	Currency       string      // 最低报价的币种
+}
+
+// Surge 订单簿的溢价情况。
+type Surge struct {
+	Multiplier       float64 // 平滑后的当前倍率
+	TargetMultiplier float64 // 按当前供需比计算的倍率
+	OpenRequests     int
+	OpenOffers       int
+	// SuggestedBidPerKm 建议乘客出价 = 最近一次观察到的最低司机报价 × 当前倍率；从未有过报价时为零值
+	SuggestedBidPerKm money.Money
+	Currency          string    // 建议出价的币种
+	UpdatedAt         time.Time // 最近一次观察订单簿的时间
+}
+
+type surgeState struct {
+	multiplier float64 // updatedAt 时的平滑倍率
+	target     float64
+	snapshot   BookSnapshot
+	bestOffer  money.Money // 最近一次观察到的最低报价
+	currency   string
+	notified   bool
+	updatedAt  time.Time
+}
+
+// SurgeEngine 按订单簿的供需比维护溢价倍率，线程安全。撮合 worker 在订单簿变化时调用 Observe，
+// 查询方通过 Current 读取；倍率只存在于内存中，重启后从 1 重新开始。
+type SurgeEngine struct {
+	rules SurgeRules
+	now   func() time.Time
+
+	mu    sync.Mutex
+	books map[string]*surgeState // key: airport:vehicle
+}
+
+func NewSurgeEngine(rules SurgeRules) *SurgeEngine {
+	return &SurgeEngine{rules: rules, now: time.Now, books: make(map[string]*surgeState)}
+}
+
+// target 目标倍率 = 1 + Sensitivity × (请求数 / 报价数 - 1)，没有报价时按 1 个报价计算，取值范围 [1, MaxMultiplier]。
+func (e *SurgeEngine) target(snap BookSnapshot) float64 {
+	if !e.rules.Enabled() {
+		return 1
+	}
+	ratio := float64(snap.OpenRequests) / float64(max(snap.OpenOffers, 1))
+	return min(max(1+e.rules.Sensitivity*(ratio-1), 1), e.rules.MaxMultiplier)
+}
+
+// smoothed 返回 at 时刻的平滑倍率：自上次观察起按半衰期向目标倍率指数收敛。
+func (e *SurgeEngine) smoothed(st *surgeState, at time.Time) float64 {
+	if e.rules.HalfLife <= 0 {
+		return st.target
+	}
+	elapsed := at.Sub(st.updatedAt)
+	if elapsed <= 0 {
+		return st.multiplier
+	}
+	decay := math.Pow(0.5, float64(elapsed)/float64(e.rules.HalfLife))
+	return st.target + (st.multiplier-st.target)*decay
+}
+
+// Observe 记录订单簿最新的供需情况并返回溢价情况。目标倍率由低于通知阈值升至阈值以上时 notify 为 true，
+// 回落到阈值以下后才会再次通知。
+func (e *SurgeEngine) Observe(key string, snap BookSnapshot) (s Surge, notify bool) {
+	now := e.now()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	st, ok := e.books[key]
+	if !ok {
+		st = &surgeState{multiplier: 1, target: 1, updatedAt: now}
+		e.books[key] = st
+	}
+	st.multiplier = e.smoothed(st, now)
+	st.target = e.target(snap)
+	st.snapshot = snap
+	if !snap.BestOfferPerKm.IsZero() {
+		st.bestOffer, st.currency = snap.BestOfferPerKm, snap.Currency
+	}
+	st.updatedAt = now
+	if e.rules.NotifyThreshold > 0 {
+		above := st.target >= e.rules.NotifyThreshold
+		notify = above && !st.notified
+		st.notified = above
+	}
+	return e.surge(st, now), notify
+}
+
+// Current 返回订单簿当前的溢价情况；未观察过的订单簿倍率为 1。
+func (e *SurgeEngine) Current(key string) Surge {
+	now := e.now()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	st, ok := e.books[key]
+	if !ok {
+		return Surge{Multiplier: 1, TargetMultiplier: 1}
+	}
+	return e.surge(st, now)
+}
+
+func (e *SurgeEngine) surge(st *surgeState, at time.Time) Surge {
+	// 倍率保留两位小数，便于展示与记录
+	m := math.Round(e.smoothed(st, at)*100) / 100
+	s := Surge{
+		Multiplier:       m,
+		TargetMultiplier: math.Round(st.target*100) / 100,
+		OpenRequests:     st.snapshot.OpenRequests,
+		OpenOffers:       st.snapshot.OpenOffers,
+		Currency:         st.currency,
+		UpdatedAt:        st.updatedAt,
+	}
+	if !st.bestOffer.IsZero() {
+		s.SuggestedBidPerKm = st.bestOffer.Mul(m)
+	}
+	return s
+}
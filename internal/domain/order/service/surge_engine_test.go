@@ -0,0 +1,102 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gavin/airport-pickup/internal/domain/money"
+)
+
+func newTestSurgeEngine(rules SurgeRules) (*SurgeEngine, *time.Time) {
+	now := time.Date(2025, 11, 8, 10, 0, 0, 0, time.UTC)
+	e := NewSurgeEngine(rules)
+	e.now = func() time.Time { return now }
+	return e, &now
+}
+
+func TestSurgeEngine_TargetCappedByRules(t *testing.T) {
+	e, _ := newTestSurgeEngine(SurgeRules{Sensitivity: 0.5, MaxMultiplier: 2})
+	cases := []struct {
+		requests, offers int
+		want             float64
+	}{
+		{0, 0, 1},
+		{3, 5, 1}, // 供大于求不打折
+		{4, 2, 1.5},
+		{4, 0, 2}, // 没有报价按 1 个计算：1 + 0.5 × 3 = 2.5，封顶 2
+		{100, 1, 2},
+	}
+	for _, c := range cases {
+		s, _ := e.Observe("PVG:sedan", BookSnapshot{OpenRequests: c.requests, OpenOffers: c.offers})
+		if s.TargetMultiplier != c.want || s.Multiplier != c.want {
+			t.Errorf("requests %d offers %d: expected %g, got target %g multiplier %g", c.requests, c.offers, c.want, s.TargetMultiplier, s.Multiplier)
+		}
+	}
+	if got := e.Current("SHA:sedan").Multiplier; got != 1 {
+		t.Errorf("expected 1 for unknown book, got %g", got)
+	}
+}
+
+func TestSurgeEngine_Disabled(t *testing.T) {
+	e, _ := newTestSurgeEngine(SurgeRules{})
+	s, notify := e.Observe("PVG:sedan", BookSnapshot{OpenRequests: 50})
+	if s.Multiplier != 1 || notify {
+		t.Errorf("expected no surge when disabled, got %+v notify=%v", s, notify)
+	}
+}
+
+func TestSurgeEngine_SmoothsOverHalfLife(t *testing.T) {
+	e, now := newTestSurgeEngine(SurgeRules{Sensitivity: 1, MaxMultiplier: 3, HalfLife: time.Minute})
+	s, _ := e.Observe("PVG:sedan", BookSnapshot{OpenRequests: 3, OpenOffers: 1, BestOfferPerKm: money.MustParse("8"), Currency: "CNY"})
+	if s.TargetMultiplier != 3 || s.Multiplier != 1 {
+		t.Fatalf("expected target 3 starting from 1, got %+v", s)
+	}
+	*now = now.Add(time.Minute)
+	if got := e.Current("PVG:sedan"); got.Multiplier != 2 || got.SuggestedBidPerKm != money.MustParse("16") || got.Currency != "CNY" {
+		t.Errorf("expected multiplier 2 and bid 16 after one half-life, got %+v", got)
+	}
+	// 报价补充后目标回到 1，倍率从当前值继续平滑回落；最低报价沿用最近一次观察到的值
+	s, _ = e.Observe("PVG:sedan", BookSnapshot{OpenRequests: 0, OpenOffers: 2})
+	if s.TargetMultiplier != 1 || s.Multiplier != 2 {
+		t.Errorf("expected target 1 from multiplier 2, got %+v", s)
+	}
+	*now = now.Add(2 * time.Minute)
+	if got := e.Current("PVG:sedan"); got.Multiplier != 1.25 || got.SuggestedBidPerKm != money.MustParse("10") {
+		t.Errorf("expected multiplier 1.25 and bid 10, got %+v", got)
+	}
+}
+
+func TestSurgeEngine_NotifiesOnceAboveThreshold(t *testing.T) {
+	e, _ := newTestSurgeEngine(SurgeRules{Sensitivity: 0.5, MaxMultiplier: 3, NotifyThreshold: 1.6})
+	steps := []struct {
+		requests, offers int
+		notify           bool
+	}{
+		{2, 1, false}, // 1.5
+		{3, 1, true},  // 2
+		{5, 1, false}, // 已通知
+		{1, 1, false}, // 回落
+		{4, 1, true},  // 再次升至阈值以上
+	}
+	for i, st := range steps {
+		if _, notify := e.Observe("PVG:sedan", BookSnapshot{OpenRequests: st.requests, OpenOffers: st.offers}); notify != st.notify {
+			t.Errorf("step %d: expected notify=%v", i, st.notify)
+		}
+	}
+}
+
+func TestSurgeRules_Validate(t *testing.T) {
+	for _, r := range []SurgeRules{
+		{MaxMultiplier: 2},
+		{Sensitivity: -1, MaxMultiplier: 2},
+		{Sensitivity: 0.5, MaxMultiplier: 2, NotifyThreshold: 1},
+		{Sensitivity: 0.5, MaxMultiplier: 2, HalfLife: -time.Second},
+	} {
+		if err := r.Validate(); err == nil {
+			t.Errorf("expected error for %+v", r)
+		}
+	}
+	if err := (SurgeRules{}).Validate(); err != nil {
+		t.Errorf("zero rules should be valid: %v", err)
+	}
+}
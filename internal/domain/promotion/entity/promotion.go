@@ -0,0 +1,171 @@
+package entity
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gavin/airport-pickup/internal/domain/money"
+)
+
+// 优惠类型
+const (
+	PromotionPercentage = "percentage" // 按比例折扣，可设单次封顶
+	PromotionFixed      = "fixed"      // 固定金额立减
+)
+
+// 核销状态
+const (
+	RedemptionRedeemed = "redeemed"
+	RedemptionReleased = "released" // 结算失败或补偿退款后撤销，退回次数与预算
+)
+
+var (
+	ErrPromotionNotStarted     = errors.New("promotion has not started")
+	ErrPromotionExpired        = errors.New("promotion has expired")
+	ErrPromotionAirport        = errors.New("promotion is not valid at this airport")
+	ErrPromotionCurrency       = errors.New("promotion currency does not match booking currency")
+	ErrPromotionLimitReached   = errors.New("promotion usage limit reached")
+	ErrPassengerLimitReached   = errors.New("promotion usage limit reached for passenger")
+	ErrPromotionBudgetExceeded = errors.New("promotion budget exhausted")
+)
+
+// IsIneligible 是否为订单不能使用优惠的业务原因（而非存储等错误）。
+func IsIneligible(err error) bool {
+	for _, e := range []error{ErrPromotionNotStarted, ErrPromotionExpired, ErrPromotionAirport, ErrPromotionCurrency,
+		ErrPromotionLimitReached, ErrPassengerLimitReached, ErrPromotionBudgetExceeded} {
+		if errors.Is(err, e) {
+			return true
+		}
+	}
+	return false
+}
+
+// Promotion 优惠活动，以优惠码标识。优惠在结算时从平台收入中抵扣，不影响司机收入。
+// 次数与预算上限为 0 表示不限。
+type Promotion struct {
+	Code              string // 大写，唯一
+	Description       string
+	Kind              string  // percentage, fixed
+	Rate              float64 // percentage：折扣比例 (0, 1]
+	AmountCents       int64   // fixed：立减金额
+	MaxDiscountCents  int64   // percentage：单次优惠上限，0 不限
+	Currency          string  // 金额与预算的币种，只能用于该币种结算的订单
+	AirportCodes      []string
+	StartsAt          time.Time
+	EndsAt            time.Time // 开区间
+	PerPassengerLimit int
+	TotalLimit        int
+	BudgetCents       int64
+	RedeemedCount     int   // 有效核销次数
+	SpentCents        int64 // 有效核销的优惠合计
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+// Validate 校验优惠类型与金额、有效期及上限。
+func (p *Promotion) Validate() error {
+	if p.Code == "" {
+		return errors.New("code required")
+	}
+	switch p.Kind {
+	case PromotionPercentage:
+		if p.Rate <= 0 || p.Rate > 1 {
+			return errors.New("rate must be in (0, 1]")
+		}
+		if p.MaxDiscountCents < 0 {
+			return errors.New("max_discount_cents must be >= 0")
+		}
+	case PromotionFixed:
+		if p.AmountCents <= 0 {
+			return errors.New("amount_cents must be > 0")
+		}
+	default:
+		return errors.New("invalid promotion kind")
+	}
+	if !money.ValidCurrency(p.Currency) {
+		return errors.New("invalid currency")
+	}
+	if p.StartsAt.IsZero() || p.EndsAt.IsZero() || !p.StartsAt.Before(p.EndsAt) {
+		return errors.New("starts_at must be before ends_at")
+	}
+	if p.PerPassengerLimit < 0 || p.TotalLimit < 0 || p.BudgetCents < 0 {
+		return errors.New("limits and budget must be >= 0")
+	}
+	return nil
+}
+
+// AppliesToAirport 未限定机场时对所有机场有效。
+func (p *Promotion) AppliesToAirport(airportCode string) bool {
+	if len(p.AirportCodes) == 0 {
+		return true
+	}
+	for _, c := range p.AirportCodes {
+		if strings.EqualFold(c, airportCode) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckEligible 校验订单能否使用该优惠：at 须在有效期内，机场与币种匹配，
+// 且活动与乘客（已核销 passengerRedemptions 次）均未达次数上限、预算未用完。
+func (p *Promotion) CheckEligible(airportCode, currency string, at time.Time, passengerRedemptions int) error {
+	if at.Before(p.StartsAt) {
+		return ErrPromotionNotStarted
+	}
+	if !at.Before(p.EndsAt) {
+		return ErrPromotionExpired
+	}
+	if !p.AppliesToAirport(airportCode) {
+		return ErrPromotionAirport
+	}
+	if currency != p.Currency {
+		return ErrPromotionCurrency
+	}
+	if p.TotalLimit > 0 && p.RedeemedCount >= p.TotalLimit {
+		return ErrPromotionLimitReached
+	}
+	if p.PerPassengerLimit > 0 && passengerRedemptions >= p.PerPassengerLimit {
+		return ErrPassengerLimitReached
+	}
+	if p.BudgetCents > 0 && p.SpentCents >= p.BudgetCents {
+		return ErrPromotionBudgetExceeded
+	}
+	return nil
+}
+
+// DiscountFor 对可优惠金额 amountCents 计算优惠，不超过该金额、单次上限及剩余预算。
+func (p *Promotion) DiscountFor(amountCents int64) int64 {
+	if amountCents <= 0 {
+		return 0
+	}
+	var d int64
+	switch p.Kind {
+	case PromotionPercentage:
+		d = money.FromCents(amountCents).Mul(p.Rate).Cents()
+		if p.MaxDiscountCents > 0 {
+			d = min(d, p.MaxDiscountCents)
+		}
+	case PromotionFixed:
+		d = p.AmountCents
+	}
+	d = min(d, amountCents)
+	if p.BudgetCents > 0 {
+		d = min(d, p.BudgetCents-p.SpentCents)
+	}
+	return max(d, 0)
+}
+
+// Redemption 一次优惠核销，每个订单最多一条。
+type Redemption struct {
+	ID          string
+	Code        string
+	BookingID   string
+	PassengerID string
+	AmountCents int64
+	Currency    string
+	Status      string // redeemed, released
+	CreatedAt   time.Time
+	ReleasedAt  *time.Time
+}
@@ -0,0 +1,94 @@
+package entity
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTestPromotion() *Promotion {
+	start := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	return &Promotion{
+		Code: "PVG10", Kind: PromotionPercentage, Rate: 0.1, MaxDiscountCents: 1500, Currency: "CNY",
+		AirportCodes: []string{"PVG"}, StartsAt: start, EndsAt: start.AddDate(0, 1, 0),
+		PerPassengerLimit: 1, TotalLimit: 100, BudgetCents: 10000,
+	}
+}
+
+func TestPromotion_Validate(t *testing.T) {
+	if err := newTestPromotion().Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	bad := []func(p *Promotion){
+		func(p *Promotion) { p.Rate = 0 },
+		func(p *Promotion) { p.Rate = 1.5 },
+		func(p *Promotion) { p.Kind = PromotionFixed },
+		func(p *Promotion) { p.Kind = "bogo" },
+		func(p *Promotion) { p.Currency = "" },
+		func(p *Promotion) { p.EndsAt = p.StartsAt },
+		func(p *Promotion) { p.BudgetCents = -1 },
+	}
+	for i, mutate := range bad {
+		p := newTestPromotion()
+		mutate(p)
+		if err := p.Validate(); err == nil {
+			t.Errorf("case %d: expected validation error", i)
+		}
+	}
+}
+
+func TestPromotion_CheckEligible(t *testing.T) {
+	p := newTestPromotion()
+	at := p.StartsAt.Add(time.Hour)
+	if err := p.CheckEligible("pvg", "CNY", at, 0); err != nil {
+		t.Fatalf("CheckEligible: %v", err)
+	}
+	cases := []struct {
+		name    string
+		mutate  func(p *Promotion)
+		airport string
+		at      time.Time
+		used    int
+		want    error
+	}{
+		{"not started", nil, "PVG", p.StartsAt.Add(-time.Second), 0, ErrPromotionNotStarted},
+		{"expired", nil, "PVG", p.EndsAt, 0, ErrPromotionExpired},
+		{"airport", nil, "SHA", at, 0, ErrPromotionAirport},
+		{"passenger limit", nil, "PVG", at, 1, ErrPassengerLimitReached},
+		{"total limit", func(p *Promotion) { p.RedeemedCount = 100 }, "PVG", at, 0, ErrPromotionLimitReached},
+		{"budget", func(p *Promotion) { p.SpentCents = 10000 }, "PVG", at, 0, ErrPromotionBudgetExceeded},
+	}
+	for _, c := range cases {
+		p := newTestPromotion()
+		if c.mutate != nil {
+			c.mutate(p)
+		}
+		err := p.CheckEligible(c.airport, "CNY", c.at, c.used)
+		if !errors.Is(err, c.want) || !IsIneligible(err) {
+			t.Errorf("%s: got %v, want %v", c.name, err, c.want)
+		}
+	}
+	if err := p.CheckEligible("PVG", "USD", at, 0); !errors.Is(err, ErrPromotionCurrency) {
+		t.Errorf("currency: got %v", err)
+	}
+}
+
+func TestPromotion_DiscountFor(t *testing.T) {
+	p := newTestPromotion()
+	if got := p.DiscountFor(8000); got != 800 {
+		t.Errorf("10%% of 8000: got %d", got)
+	}
+	// 单次上限
+	if got := p.DiscountFor(50000); got != 1500 {
+		t.Errorf("capped: got %d", got)
+	}
+	// 剩余预算不足时只优惠剩余部分
+	p.SpentCents = 9700
+	if got := p.DiscountFor(8000); got != 300 {
+		t.Errorf("remaining budget: got %d", got)
+	}
+	f := &Promotion{Kind: PromotionFixed, AmountCents: 2000}
+	if got := f.DiscountFor(1200); got != 1200 {
+		t.Errorf("fixed capped at amount: got %d", got)
+	}
+}
@@ -0,0 +1,33 @@
+package promotion
+
+import (
+	"errors"
+
+	promotionentity "github.com/gavin/airport-pickup/internal/domain/promotion/entity"
+)
+
+// ErrPromotionExists 优惠码已被使用。
+var ErrPromotionExists = errors.New("promotion code already exists")
+
+// ErrRedemptionExists 订单已有核销记录（并发结算同一订单）。
+var ErrRedemptionExists = errors.New("booking already redeemed a promotion")
+
+// PromotionRepository 优惠活动与核销持久化。核销时在同一事务中按条件累加活动的核销次数与已用预算，
+// 保证并发下单不会超出总次数、乘客次数与预算。
+type PromotionRepository interface {
+	// 写入新活动；优惠码已存在时返回 ErrPromotionExists
+	CreatePromotion(p *promotionentity.Promotion) error
+	// 不存在时返回 (nil, nil)
+	GetPromotion(code string) (*promotionentity.Promotion, error)
+	// 按创建时间倒序
+	ListPromotions() ([]*promotionentity.Promotion, error)
+	// 乘客在该活动下的有效核销次数
+	CountPassengerRedemptions(code, passengerID string) (int, error)
+	// 订单的核销记录（含已撤销），不存在时返回 (nil, nil)
+	GetRedemptionByBooking(bookingID string) (*promotionentity.Redemption, error)
+	// 原子核销：超出总次数、预算或乘客次数时分别返回 ErrPromotionLimitReached、ErrPromotionBudgetExceeded、
+	// ErrPassengerLimitReached，订单已有核销记录时返回 ErrRedemptionExists
+	Redeem(r *promotionentity.Redemption) error
+	// 原子撤销订单的有效核销并退回次数与预算；没有有效核销时不做处理
+	ReleaseRedemption(bookingID string) error
+}
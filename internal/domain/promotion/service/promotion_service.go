@@ -0,0 +1,110 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gavin/airport-pickup/internal/domain/money"
+	promotionentity "github.com/gavin/airport-pickup/internal/domain/promotion/entity"
+)
+
+// PromotionService 创建优惠活动并计算订单的优惠核销。
+type PromotionService struct{}
+
+func NewPromotionService() *PromotionService {
+	return &PromotionService{}
+}
+
+type CreatePromotionCmd struct {
+	Code              string
+	Description       string
+	Kind              string
+	Rate              float64
+	AmountCents       int64
+	MaxDiscountCents  int64
+	Currency          string
+	AirportCodes      []string
+	StartsAt          string // RFC3339
+	EndsAt            string // RFC3339
+	PerPassengerLimit int
+	TotalLimit        int
+	BudgetCents       int64
+}
+
+// CreatePromotion 规范化优惠码与机场代码（大写）并校验活动参数。
+func (s *PromotionService) CreatePromotion(cmd *CreatePromotionCmd) (*promotionentity.Promotion, error) {
+	starts, err := time.Parse(time.RFC3339, cmd.StartsAt)
+	if err != nil {
+		return nil, errors.New("invalid starts_at")
+	}
+	ends, err := time.Parse(time.RFC3339, cmd.EndsAt)
+	if err != nil {
+		return nil, errors.New("invalid ends_at")
+	}
+	p := &promotionentity.Promotion{
+		Code:              NormalizeCode(cmd.Code),
+		Description:       strings.TrimSpace(cmd.Description),
+		Kind:              cmd.Kind,
+		Rate:              cmd.Rate,
+		AmountCents:       cmd.AmountCents,
+		MaxDiscountCents:  cmd.MaxDiscountCents,
+		Currency:          money.NormalizeCurrency(cmd.Currency),
+		StartsAt:          starts,
+		EndsAt:            ends,
+		PerPassengerLimit: cmd.PerPassengerLimit,
+		TotalLimit:        cmd.TotalLimit,
+		BudgetCents:       cmd.BudgetCents,
+	}
+	for _, c := range cmd.AirportCodes {
+		if c = strings.ToUpper(strings.TrimSpace(c)); c != "" {
+			p.AirportCodes = append(p.AirportCodes, c)
+		}
+	}
+	if strings.ContainsAny(p.Code, " \t,") || len(p.Code) > 32 {
+		return nil, errors.New("code must be at most 32 characters without spaces or commas")
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// NormalizeCode 优惠码不区分大小写，统一存为大写。
+func NormalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+type RedeemCmd struct {
+	BookingID            string
+	PassengerID          string
+	AirportCode          string
+	Currency             string
+	At                   time.Time // 有效期按乘客下单时间判断
+	PassengerRedemptions int       // 乘客在该活动下已有的有效核销次数
+	AmountCents          int64     // 可优惠金额（税前、不含过路费）
+	MaxCents             int64     // 平台在可优惠金额中的分成，优惠不超过该值
+}
+
+// Redeem 校验订单能否使用该优惠并生成核销记录（不生成ID）；优惠金额为 0 时返回 (nil, nil)。
+func (s *PromotionService) Redeem(p *promotionentity.Promotion, cmd *RedeemCmd) (*promotionentity.Redemption, error) {
+	if cmd.BookingID == "" || cmd.PassengerID == "" {
+		return nil, errors.New("booking_id and passenger_id required")
+	}
+	if err := p.CheckEligible(cmd.AirportCode, cmd.Currency, cmd.At, cmd.PassengerRedemptions); err != nil {
+		return nil, err
+	}
+	d := min(p.DiscountFor(cmd.AmountCents), cmd.MaxCents)
+	if d <= 0 {
+		return nil, nil
+	}
+	return &promotionentity.Redemption{
+		Code:        p.Code,
+		BookingID:   cmd.BookingID,
+		PassengerID: cmd.PassengerID,
+		AmountCents: d,
+		Currency:    p.Currency,
+		Status:      promotionentity.RedemptionRedeemed,
+		CreatedAt:   time.Now(),
+	}, nil
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	promotionentity "github.com/gavin/airport-pickup/internal/domain/promotion/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPromotionService_CreatePromotion(t *testing.T) {
+	p, err := NewPromotionService().CreatePromotion(&CreatePromotionCmd{
+		Code: " launch20 ", Kind: promotionentity.PromotionFixed, AmountCents: 2000, Currency: "cny",
+		AirportCodes: []string{"pvg", " ", "sha"}, StartsAt: "2026-05-01T00:00:00Z", EndsAt: "2026-06-01T00:00:00Z",
+		TotalLimit: 500, BudgetCents: 500000,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "LAUNCH20", p.Code)
+	assert.Equal(t, "CNY", p.Currency)
+	assert.Equal(t, []string{"PVG", "SHA"}, p.AirportCodes)
+
+	_, err = NewPromotionService().CreatePromotion(&CreatePromotionCmd{
+		Code: "BAD CODE", Kind: promotionentity.PromotionFixed, AmountCents: 2000, Currency: "CNY",
+		StartsAt: "2026-05-01T00:00:00Z", EndsAt: "2026-06-01T00:00:00Z",
+	})
+	assert.Error(t, err)
+	_, err = NewPromotionService().CreatePromotion(&CreatePromotionCmd{
+		Code: "X", Kind: promotionentity.PromotionFixed, AmountCents: 2000, Currency: "CNY", StartsAt: "tomorrow", EndsAt: "2026-06-01T00:00:00Z",
+	})
+	assert.EqualError(t, err, "invalid starts_at")
+}
+
+func TestPromotionService_Redeem(t *testing.T) {
+	start := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	p := &promotionentity.Promotion{
+		Code: "PVG50", Kind: promotionentity.PromotionPercentage, Rate: 0.5, Currency: "CNY",
+		StartsAt: start, EndsAt: start.AddDate(0, 1, 0), PerPassengerLimit: 2,
+	}
+	cmd := &RedeemCmd{BookingID: "b1", PassengerID: "p1", AirportCode: "PVG", Currency: "CNY", At: start.Add(time.Hour), AmountCents: 4000, MaxCents: 600}
+	red, err := NewPromotionService().Redeem(p, cmd)
+	require.NoError(t, err)
+	// 50% 为 2000，但优惠只能由平台分成承担
+	assert.Equal(t, int64(600), red.AmountCents)
+	assert.Equal(t, promotionentity.RedemptionRedeemed, red.Status)
+	assert.Equal(t, "CNY", red.Currency)
+
+	cmd.MaxCents = 0
+	red, err = NewPromotionService().Redeem(p, cmd)
+	require.NoError(t, err)
+	assert.Nil(t, red)
+
+	cmd.PassengerRedemptions = 2
+	_, err = NewPromotionService().Redeem(p, cmd)
+	assert.ErrorIs(t, err, promotionentity.ErrPassengerLimitReached)
+}
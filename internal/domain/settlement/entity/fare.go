@@ -0,0 +1,57 @@
+package entity
+
+// 车费明细项类型
+const (
+	FareBase      = "base"      // 起步价
+	FareDistance  = "distance"  // 里程费
+	FareWaiting   = "waiting"   // 等候费
+	FareSurcharge = "surcharge" // 附加费，如机场接机费
+	FareToll      = "toll"      // 过路费（司机垫付，全额归司机）
+	FareTax       = "tax"       // 税费（由平台代收代缴，不计入平台收入）
+	FareDiscount  = "discount"  // 优惠（负数，由平台承担）
+)
+
+// FareLineItem 车费明细中的一项，AmountCents = DriverCents + PlatformCents + TaxCents。
+type FareLineItem struct {
+	Kind          string
+	Description   string
+	AmountCents   int64
+	DriverCents   int64
+	PlatformCents int64
+	TaxCents      int64 // 代收税费，只有税费项非 0
+}
+
+// FareBreakdown 一次行程的车费明细及司机、平台与代收税费的分账合计。
+type FareBreakdown struct {
+	Currency      string
+	Items         []FareLineItem
+	TotalCents    int64
+	DriverCents   int64
+	PlatformCents int64
+	TaxCents      int64
+}
+
+// NewFareBreakdown 汇总明细项生成车费明细。
+func NewFareBreakdown(currency string, items []FareLineItem) *FareBreakdown {
+	b := &FareBreakdown{Currency: currency, Items: items}
+	for _, it := range items {
+		b.TotalCents += it.AmountCents
+		b.DriverCents += it.DriverCents
+		b.PlatformCents += it.PlatformCents
+		b.TaxCents += it.TaxCents
+	}
+	return b
+}
+
+// DiscountableCents 可参与优惠的税前金额（不含过路费、税费与已有优惠）及其中平台的分成。
+func (b *FareBreakdown) DiscountableCents() (amountCents, platformCents int64) {
+	for _, it := range b.Items {
+		switch it.Kind {
+		case FareToll, FareTax, FareDiscount:
+			continue
+		}
+		amountCents += it.AmountCents
+		platformCents += it.PlatformCents
+	}
+	return amountCents, platformCents
+}
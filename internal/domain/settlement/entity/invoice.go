@@ -0,0 +1,78 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+)
+
+// 单据类型
+const (
+	InvoiceReceipt   = "receipt"   // 单笔订单收据
+	InvoiceCorporate = "corporate" // 企业客户月度合并发票
+)
+
+// 单据行类型：车费明细项沿用 Fare* 常量，另有整单车费与退款
+const (
+	InvoiceLineFare   = "fare"   // 整单车费（企业发票每单一行，或无明细的早期订单）
+	InvoiceLineRefund = "refund" // 退款（负数）
+)
+
+// LegalEntity 开票主体；同一主体下的收据与发票共用一个连续编号序列。
+type LegalEntity struct {
+	Code         string
+	Name         string
+	Address      string
+	TaxID        string
+	NumberPrefix string // 单据编号前缀，为空时使用 "<Code>-"
+}
+
+// Invoice 收据或企业月度发票。单据开具后内容不再变化，重新下载时按保存的内容渲染。
+type Invoice struct {
+	ID               string
+	Kind             string // receipt, corporate
+	Number           string // 落库时按开票主体分配
+	Seq              int64
+	Issuer           LegalEntity
+	Currency         string
+	BookingID        string // 收据
+	PassengerID      string // 收据
+	PaymentID        string // 收据：支付流水
+	PaidAt           *time.Time
+	CorporateAccount string    // 企业发票
+	PeriodStart      time.Time // 企业发票：账期 [PeriodStart, PeriodEnd)
+	PeriodEnd        time.Time
+	Lines            []InvoiceLine
+	TotalCents       int64 // 含税合计，扣除退款
+	TaxCents         int64
+	IssuedAt         time.Time
+}
+
+// InvoiceLine 单据行；TaxCents 为该行金额中包含的税额。
+type InvoiceLine struct {
+	Kind        string
+	BookingID   string
+	Description string
+	AmountCents int64
+	TaxCents    int64
+	Date        time.Time
+}
+
+// SubtotalCents 不含税金额
+func (i *Invoice) SubtotalCents() int64 { return i.TotalCents - i.TaxCents }
+
+// AddLine 追加一行并累计合计与税额。
+func (i *Invoice) AddLine(l InvoiceLine) {
+	i.Lines = append(i.Lines, l)
+	i.TotalCents += l.AmountCents
+	i.TaxCents += l.TaxCents
+}
+
+// AssignNumber 按开票主体的序号生成单据编号，如 SHPD-000042。
+func (i *Invoice) AssignNumber(seq int64) {
+	prefix := i.Issuer.NumberPrefix
+	if prefix == "" {
+		prefix = i.Issuer.Code + "-"
+	}
+	i.Seq = seq
+	i.Number = fmt.Sprintf("%s%06d", prefix, seq)
+}
@@ -0,0 +1,23 @@
+package entity
+
+import "testing"
+
+func TestInvoice_AddLineAndNumber(t *testing.T) {
+	inv := &Invoice{Issuer: LegalEntity{Code: "CN01", Name: "Pickup Co."}}
+	inv.AddLine(InvoiceLine{Kind: FareDistance, AmountCents: 2000})
+	inv.AddLine(InvoiceLine{Kind: FareTax, AmountCents: 120, TaxCents: 120})
+	inv.AddLine(InvoiceLine{Kind: InvoiceLineRefund, AmountCents: -500})
+	if inv.TotalCents != 1620 || inv.TaxCents != 120 || inv.SubtotalCents() != 1500 {
+		t.Errorf("unexpected totals: total=%d tax=%d subtotal=%d", inv.TotalCents, inv.TaxCents, inv.SubtotalCents())
+	}
+
+	inv.AssignNumber(7)
+	if inv.Number != "CN01-000007" || inv.Seq != 7 {
+		t.Errorf("unexpected number %s", inv.Number)
+	}
+	inv.Issuer.NumberPrefix = "R2025/"
+	inv.AssignNumber(8)
+	if inv.Number != "R2025/000008" {
+		t.Errorf("unexpected number %s", inv.Number)
+	}
+}
@@ -0,0 +1,138 @@
+package entity
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// 账户：乘客与司机账户按 ID 细分，如 driver_payable:<driverID>
+const (
+	AccountPassengerReceivable = "passenger_receivable" // 乘客应收（资产）
+	AccountDriverPayable       = "driver_payable"       // 应付司机（负债）
+	AccountPlatformRevenue     = "platform_revenue"     // 平台收入
+	AccountTaxPayable          = "tax_payable"          // 应交税费（负债，代收的税费）
+	AccountPaymentClearing     = "payment_clearing"     // 支付清算（资产，已从支付渠道收到的款项）
+)
+
+// 分录类型
+const (
+	JournalSettlement = "settlement" // 确认车费：乘客应收 -> 应付司机
+	JournalPayment    = "payment"    // 扣款入账：清算 <- 乘客应收
+	JournalFee        = "fee"        // 平台抽成：应付司机 -> 平台收入
+	JournalTax        = "tax"        // 代收税费：应付司机 -> 应交税费
+	JournalRefund     = "refund"     // 退款：冲减应付司机、平台收入与应交税费，清算付出
+	JournalPayout     = "payout"     // 打款：应付司机 -> 清算付出
+	JournalDeposit    = "deposit"    // 扣收押金：清算 -> 平台收入
+)
+
+// PassengerReceivableAccount 乘客应收账户
+func PassengerReceivableAccount(passengerID string) string {
+	return AccountPassengerReceivable + ":" + passengerID
+}
+
+// DriverPayableAccount 应付司机账户
+func DriverPayableAccount(driverID string) string {
+	return AccountDriverPayable + ":" + driverID
+}
+
+// AccountType 返回账户所属类型，如 driver_payable:d1 -> driver_payable
+func AccountType(account string) string {
+	t, _, _ := strings.Cut(account, ":")
+	return t
+}
+
+// DebitNormal 账户余额是否以借方为正（资产类账户）。
+func DebitNormal(account string) bool {
+	switch AccountType(account) {
+	case AccountPassengerReceivable, AccountPaymentClearing:
+		return true
+	}
+	return false
+}
+
+// JournalLine 分录行，借贷二选一
+type JournalLine struct {
+	Account     string
+	DebitCents  int64
+	CreditCents int64
+}
+
+// JournalEntry 一笔记账凭证，借方合计必须等于贷方合计。
+// 订单相关分录关联 BookingID，打款相关分录关联 PayoutID。
+type JournalEntry struct {
+	ID        string
+	BookingID string
+	PayoutID  string
+	Kind      string // settlement, payment, fee, tax, refund, payout
+	Currency  string // 分录内各行同一币种
+	Lines     []JournalLine
+	CreatedAt time.Time
+}
+
+// NewJournalEntry 校验并创建订单分录；金额为 0 的行被忽略。
+func NewJournalEntry(id, bookingID, kind string, lines ...JournalLine) (*JournalEntry, error) {
+	if bookingID == "" {
+		return nil, errors.New("booking_id required")
+	}
+	return newJournalEntry(&JournalEntry{ID: id, BookingID: bookingID, Kind: kind}, lines)
+}
+
+// NewPayoutJournalEntry 校验并创建打款分录；金额为 0 的行被忽略。
+func NewPayoutJournalEntry(id, payoutID, kind string, lines ...JournalLine) (*JournalEntry, error) {
+	if payoutID == "" {
+		return nil, errors.New("payout_id required")
+	}
+	return newJournalEntry(&JournalEntry{ID: id, PayoutID: payoutID, Kind: kind}, lines)
+}
+
+func newJournalEntry(e *JournalEntry, lines []JournalLine) (*JournalEntry, error) {
+	if e.Kind == "" {
+		return nil, errors.New("kind required")
+	}
+	var debits, credits int64
+	for _, l := range lines {
+		if l.Account == "" {
+			return nil, errors.New("journal line account required")
+		}
+		if l.DebitCents < 0 || l.CreditCents < 0 || (l.DebitCents > 0 && l.CreditCents > 0) {
+			return nil, errors.New("journal line must have either a positive debit or a positive credit")
+		}
+		if l.DebitCents == 0 && l.CreditCents == 0 {
+			continue
+		}
+		debits += l.DebitCents
+		credits += l.CreditCents
+		e.Lines = append(e.Lines, l)
+	}
+	if debits != credits {
+		return nil, errors.New("journal entry debits must equal credits")
+	}
+	return e, nil
+}
+
+// Debit 借记行
+func Debit(account string, cents int64) JournalLine {
+	return JournalLine{Account: account, DebitCents: cents}
+}
+
+// Credit 贷记行
+func Credit(account string, cents int64) JournalLine {
+	return JournalLine{Account: account, CreditCents: cents}
+}
+
+// AccountBalance 账户借贷发生额
+type AccountBalance struct {
+	Account     string
+	Currency    string
+	DebitCents  int64
+	CreditCents int64
+}
+
+// BalanceCents 按账户正常余额方向计算的余额：资产类为借方减贷方，其余为贷方减借方。
+func (b AccountBalance) BalanceCents() int64 {
+	if DebitNormal(b.Account) {
+		return b.DebitCents - b.CreditCents
+	}
+	return b.CreditCents - b.DebitCents
+}
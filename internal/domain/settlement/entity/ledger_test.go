@@ -0,0 +1,50 @@
+package entity
+
+import "testing"
+
+func TestNewJournalEntry_Balanced(t *testing.T) {
+	e, err := NewJournalEntry("j1", "b1", JournalSettlement,
+		Debit(PassengerReceivableAccount("p1"), 1000),
+		Credit(DriverPayableAccount("d1"), 900),
+		Credit(AccountPlatformRevenue, 100),
+		Credit(AccountPlatformRevenue, 0),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(e.Lines) != 3 {
+		t.Errorf("expected zero lines dropped, got %d lines", len(e.Lines))
+	}
+}
+
+func TestNewJournalEntry_Invalid(t *testing.T) {
+	if _, err := NewJournalEntry("j1", "b1", JournalSettlement, Debit("a", 100), Credit("b", 90)); err == nil {
+		t.Errorf("expected error for unbalanced entry")
+	}
+	if _, err := NewJournalEntry("j1", "b1", JournalSettlement, JournalLine{Account: "a", DebitCents: 10, CreditCents: 10}); err == nil {
+		t.Errorf("expected error for line with both debit and credit")
+	}
+	if _, err := NewJournalEntry("j1", "b1", JournalSettlement, Debit("a", -10), Credit("b", -10)); err == nil {
+		t.Errorf("expected error for negative amounts")
+	}
+	if _, err := NewJournalEntry("j1", "", JournalSettlement); err == nil {
+		t.Errorf("expected error for empty booking id")
+	}
+	if _, err := NewPayoutJournalEntry("j1", "", JournalPayout); err == nil {
+		t.Errorf("expected error for empty payout id")
+	}
+}
+
+func TestAccountBalance_NormalSide(t *testing.T) {
+	clearing := AccountBalance{Account: AccountPaymentClearing, DebitCents: 1000, CreditCents: 300}
+	if got := clearing.BalanceCents(); got != 700 {
+		t.Errorf("expected debit-normal balance 700, got %d", got)
+	}
+	driver := AccountBalance{Account: DriverPayableAccount("d1"), DebitCents: 100, CreditCents: 1000}
+	if got := driver.BalanceCents(); got != 900 {
+		t.Errorf("expected credit-normal balance 900, got %d", got)
+	}
+	if AccountType(DriverPayableAccount("d1")) != AccountDriverPayable {
+		t.Errorf("unexpected account type")
+	}
+}
@@ -1,12 +1,121 @@
 package entity
 
-import "time"
+import (
+	"errors"
+	"time"
+)
 
+// 支付流水状态
+const (
+	PaymentAuthorized = "authorized" // 已预授权（冻结预估车费）
+	PaymentCaptured   = "captured"   // 已按实际金额扣款
+	PaymentVoided     = "voided"     // 预授权已撤销
+	PaymentRefunded   = "refunded"   // 已退款
+	PaymentFailed     = "failed"     // 预授权失败
+)
+
+// 支付流水类型
+const (
+	PaymentKindPayment = "payment" // 订单支付，每个订单一条
+	PaymentKindRefund  = "refund"  // 单次退款，每次退款一条
+	PaymentKindDeposit = "deposit" // 接机请求押金，BookingID 为押金预授权的幂等键（deposit-<请求 ID>）
+)
+
+// 退款原因
+const (
+	RefundReasonServiceIssue = "service_issue"           // 服务质量问题
+	RefundReasonOvercharge   = "overcharge"              // 多收费
+	RefundReasonDriverNoShow = "driver_no_show"          // 司机未到
+	RefundReasonGoodwill     = "goodwill"                // 客诉安抚
+	RefundReasonCompensation = "settlement_compensation" // 结算落库失败的自动补偿，不可人工发起
+)
+
+// ValidRefundReason 是否为客服可发起的退款原因。
+func ValidRefundReason(code string) bool {
+	switch code {
+	case RefundReasonServiceIssue, RefundReasonOvercharge, RefundReasonDriverNoShow, RefundReasonGoodwill:
+		return true
+	}
+	return false
+}
+
+// PaymentTransaction 记录一个订单的支付生命周期：
+// authorized -> captured -> refunded，authorized -> voided，或预授权失败直接 failed。
+// Kind 为 refund 的流水记录单次退款（AmountCents 为退款金额，状态固定为 refunded），
+// 订单支付流水的 RefundedCents 为累计退款。
 type PaymentTransaction struct {
-	ID          string
-	BookingID   string
-	AmountCents int64
-	Status      string // pending, success, failed
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
+	ID            string
+	BookingID     string
+	Kind          string // payment, refund, deposit
+	AmountCents   int64  // 预授权金额；退款流水为退款金额
+	CapturedCents int64
+	RefundedCents int64
+	Currency      string
+	Status        string // authorized, captured, voided, refunded, failed
+	FailureReason string
+	ReasonCode    string // 退款原因
+	AuthorizedAt  *time.Time
+	CapturedAt    *time.Time
+	VoidedAt      *time.Time
+	RefundedAt    *time.Time
+	FailedAt      *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// MarkCaptured authorized -> captured，扣款金额不得超过预授权金额
+func (t *PaymentTransaction) MarkCaptured(amountCents int64, at time.Time) error {
+	if t.Status != PaymentAuthorized {
+		return errors.New("payment transaction status must be 'authorized' to mark as 'captured'")
+	}
+	if amountCents < 0 || amountCents > t.AmountCents {
+		return errors.New("captured amount must be between 0 and the authorized amount")
+	}
+	t.Status = PaymentCaptured
+	t.CapturedCents = amountCents
+	t.CapturedAt = &at
+	return nil
+}
+
+// MarkVoided authorized -> voided
+func (t *PaymentTransaction) MarkVoided(at time.Time) error {
+	if t.Status != PaymentAuthorized {
+		return errors.New("payment transaction status must be 'authorized' to mark as 'voided'")
+	}
+	t.Status = PaymentVoided
+	t.VoidedAt = &at
+	return nil
+}
+
+// RefundableCents 剩余可退金额
+func (t *PaymentTransaction) RefundableCents() int64 {
+	if t.Status != PaymentCaptured && t.Status != PaymentRefunded {
+		return 0
+	}
+	return t.CapturedCents - t.RefundedCents
+}
+
+// MarkRefunded captured -> refunded，累计退款不得超过已扣款金额
+func (t *PaymentTransaction) MarkRefunded(amountCents int64, at time.Time) error {
+	if t.Status != PaymentCaptured && t.Status != PaymentRefunded {
+		return errors.New("payment transaction status must be 'captured' to mark as 'refunded'")
+	}
+	if amountCents <= 0 || t.RefundedCents+amountCents > t.CapturedCents {
+		return errors.New("refund amount must be positive and not exceed the captured amount")
+	}
+	t.Status = PaymentRefunded
+	t.RefundedCents += amountCents
+	t.RefundedAt = &at
+	return nil
+}
+
+// MarkFailed 预授权失败
+func (t *PaymentTransaction) MarkFailed(reason string, at time.Time) error {
+	if t.Status != "" && t.Status != PaymentAuthorized {
+		return errors.New("payment transaction status must be 'authorized' to mark as 'failed'")
+	}
+	t.Status = PaymentFailed
+	t.FailureReason = reason
+	t.FailedAt = &at
+	return nil
 }
@@ -0,0 +1,70 @@
+package entity
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPaymentTransaction_CaptureAndRefund(t *testing.T) {
+	now := time.Now()
+	tx := &PaymentTransaction{BookingID: "b1", AmountCents: 1000, Status: PaymentAuthorized, AuthorizedAt: &now}
+	if err := tx.MarkCaptured(1200, now); err == nil {
+		t.Errorf("expected error capturing more than authorized")
+	}
+	if err := tx.MarkCaptured(800, now); err != nil {
+		t.Fatalf("MarkCaptured: %v", err)
+	}
+	if tx.Status != PaymentCaptured || tx.CapturedCents != 800 || tx.CapturedAt == nil {
+		t.Errorf("unexpected transaction %+v", tx)
+	}
+	if err := tx.MarkVoided(now); err == nil {
+		t.Errorf("expected error voiding a captured transaction")
+	}
+	if err := tx.MarkRefunded(300, now); err != nil {
+		t.Fatalf("MarkRefunded: %v", err)
+	}
+	if got := tx.RefundableCents(); got != 500 {
+		t.Errorf("expected 500 refundable, got %d", got)
+	}
+	if err := tx.MarkRefunded(600, now); err == nil {
+		t.Errorf("expected error refunding more than captured")
+	}
+	if err := tx.MarkRefunded(500, now); err != nil || tx.RefundedCents != 800 || tx.Status != PaymentRefunded {
+		t.Errorf("unexpected transaction %+v, %v", tx, err)
+	}
+}
+
+func TestPaymentTransaction_Void(t *testing.T) {
+	now := time.Now()
+	tx := &PaymentTransaction{BookingID: "b1", AmountCents: 1000, Status: PaymentAuthorized}
+	if err := tx.MarkVoided(now); err != nil {
+		t.Fatalf("MarkVoided: %v", err)
+	}
+	if tx.Status != PaymentVoided || tx.VoidedAt == nil {
+		t.Errorf("unexpected transaction %+v", tx)
+	}
+	if err := tx.MarkCaptured(100, now); err == nil {
+		t.Errorf("expected error capturing a voided transaction")
+	}
+}
+
+func TestPaymentTransaction_MarkFailed(t *testing.T) {
+	tx := &PaymentTransaction{BookingID: "b1", AmountCents: 1000}
+	if err := tx.MarkFailed("insufficient funds", time.Now()); err != nil {
+		t.Fatalf("MarkFailed: %v", err)
+	}
+	if tx.Status != PaymentFailed || tx.FailureReason != "insufficient funds" || tx.FailedAt == nil {
+		t.Errorf("unexpected transaction %+v", tx)
+	}
+}
+
+func TestValidRefundReason(t *testing.T) {
+	if !ValidRefundReason(RefundReasonOvercharge) {
+		t.Errorf("expected %q to be valid", RefundReasonOvercharge)
+	}
+	for _, code := range []string{"", "whatever", RefundReasonCompensation} {
+		if ValidRefundReason(code) {
+			t.Errorf("expected %q to be invalid", code)
+		}
+	}
+}
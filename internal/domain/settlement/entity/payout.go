@@ -0,0 +1,64 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+// 司机打款状态
+const (
+	PayoutPending = "pending" // 已生成，待打款渠道确认
+	PayoutPaid    = "paid"
+	PayoutFailed  = "failed" // 打款失败，结算记录已退回未打款
+)
+
+// Payout 一次向司机的打款，汇总该司机若干未打款的结算记录。
+// NetCents = GrossCents - CommissionCents - FeeCents。
+type Payout struct {
+	ID              string
+	DriverID        string
+	Status          string // pending, paid, failed
+	GrossCents      int64  // 不含代收税费的车费合计（含退款冲减）
+	CommissionCents int64  // 平台抽成合计
+	FeeCents        int64  // 打款手续费
+	NetCents        int64
+	Currency        string // 同一打款单只含一种币种的结算记录
+	Lines           []PayoutLine
+	ProviderRef     string
+	FailureReason   string
+	PaidAt          *time.Time
+	FailedAt        *time.Time
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+// PayoutLine 打款明细，每条对应一条结算记录
+type PayoutLine struct {
+	SettlementRecordID string
+	BookingID          string
+	AmountCents        int64 // 不含代收税费
+	CommissionCents    int64
+	NetCents           int64
+}
+
+// MarkPaid pending -> paid
+func (p *Payout) MarkPaid(providerRef string, at time.Time) error {
+	if p.Status != PayoutPending {
+		return errors.New("payout status must be 'pending' to mark as 'paid'")
+	}
+	p.Status = PayoutPaid
+	p.ProviderRef = providerRef
+	p.PaidAt = &at
+	return nil
+}
+
+// MarkFailed pending -> failed
+func (p *Payout) MarkFailed(reason string, at time.Time) error {
+	if p.Status != PayoutPending {
+		return errors.New("payout status must be 'pending' to mark as 'failed'")
+	}
+	p.Status = PayoutFailed
+	p.FailureReason = reason
+	p.FailedAt = &at
+	return nil
+}
@@ -0,0 +1,32 @@
+package entity
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPayout_MarkPaid(t *testing.T) {
+	p := &Payout{ID: "po1", DriverID: "d1", Status: PayoutPending, NetCents: 900}
+	if err := p.MarkPaid("ref-1", time.Now()); err != nil {
+		t.Fatalf("MarkPaid: %v", err)
+	}
+	if p.Status != PayoutPaid || p.ProviderRef != "ref-1" || p.PaidAt == nil {
+		t.Errorf("unexpected payout %+v", p)
+	}
+	if err := p.MarkFailed("late failure", time.Now()); err == nil {
+		t.Errorf("expected error failing a paid payout")
+	}
+}
+
+func TestPayout_MarkFailed(t *testing.T) {
+	p := &Payout{ID: "po1", DriverID: "d1", Status: PayoutPending, NetCents: 900}
+	if err := p.MarkFailed("account closed", time.Now()); err != nil {
+		t.Fatalf("MarkFailed: %v", err)
+	}
+	if p.Status != PayoutFailed || p.FailureReason != "account closed" || p.FailedAt == nil {
+		t.Errorf("unexpected payout %+v", p)
+	}
+	if err := p.MarkPaid("ref-1", time.Now()); err == nil {
+		t.Errorf("expected error paying a failed payout")
+	}
+}
@@ -0,0 +1,121 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+// 对账单行类型
+const (
+	StatementPayment = "payment" // 扣款
+	StatementRefund  = "refund"  // 退款
+)
+
+// 对账差异类型
+const (
+	ExceptionMissingInStatement = "missing_in_statement" // 本地已扣款/退款，对账单中没有
+	ExceptionMissingInLedger    = "missing_in_ledger"    // 对账单中有，本地没有对应流水
+	ExceptionDuplicate          = "duplicate"            // 对账单中重复出现
+	ExceptionAmountMismatch     = "amount_mismatch"      // 金额或币种不一致
+)
+
+// 对账差异处理结果
+const (
+	ResolutionWriteOff          = "write_off"          // 确认差异并核销
+	ResolutionProviderCorrected = "provider_corrected" // 钱包服务商已更正
+	ResolutionLedgerCorrected   = "ledger_corrected"   // 本地流水已更正
+	ResolutionFalsePositive     = "false_positive"     // 误报，如跨账期的时间差
+)
+
+// ValidResolution 是否为可用的处理结果。
+func ValidResolution(code string) bool {
+	switch code {
+	case ResolutionWriteOff, ResolutionProviderCorrected, ResolutionLedgerCorrected, ResolutionFalsePositive:
+		return true
+	}
+	return false
+}
+
+// 对账报告状态
+const (
+	ReconciliationOpen       = "open"       // 有未处理的差异
+	ReconciliationReconciled = "reconciled" // 全部匹配或差异均已处理
+)
+
+// 差异状态
+const (
+	ExceptionOpen     = "open"
+	ExceptionResolved = "resolved"
+)
+
+// StatementLine 钱包服务商对账单中的一行，金额为正数。
+type StatementLine struct {
+	LineNo      int    // 文件中的行号（从 1 开始，不含表头）
+	Reference   string // 服务商流水号
+	BookingID   string
+	Type        string // payment, refund
+	AmountCents int64
+	Currency    string
+	SettledAt   time.Time
+}
+
+// ReconciliationReport 一次对账单导入的结果。
+type ReconciliationReport struct {
+	ID               string
+	Source           string // 对账单文件名
+	Status           string // open, reconciled
+	PeriodStart      time.Time
+	PeriodEnd        time.Time // 开区间
+	StatementLines   int
+	Transactions     int // 账期内本地的扣款与退款流水数
+	MatchedLines     int
+	StatementCents   int64 // 对账单净额（扣款 - 退款）
+	TransactionCents int64 // 本地净额
+	Exceptions       []ReconciliationException
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
+
+// ReconciliationException 一条对账差异。
+type ReconciliationException struct {
+	ID              string
+	Kind            string // missing_in_statement, missing_in_ledger, duplicate, amount_mismatch
+	BookingID       string
+	Type            string // payment, refund
+	TransactionID   string // 本地流水，missing_in_ledger 与 duplicate 时可能为空
+	StatementRef    string
+	StatementLineNo int
+	ExpectedCents   int64 // 本地金额
+	StatementCents  int64 // 对账单金额
+	Currency        string
+	Status          string // open, resolved
+	Resolution      string
+	Note            string
+	ResolvedAt      *time.Time
+}
+
+// Resolve open -> resolved
+func (e *ReconciliationException) Resolve(resolution, note string, at time.Time) error {
+	if e.Status != ExceptionOpen {
+		return errors.New("reconciliation exception status must be 'open' to resolve")
+	}
+	if !ValidResolution(resolution) {
+		return errors.New("invalid resolution")
+	}
+	e.Status = ExceptionResolved
+	e.Resolution = resolution
+	e.Note = note
+	e.ResolvedAt = &at
+	return nil
+}
+
+// RefreshStatus 差异全部处理后报告转为 reconciled。
+func (r *ReconciliationReport) RefreshStatus() {
+	r.Status = ReconciliationReconciled
+	for _, e := range r.Exceptions {
+		if e.Status == ExceptionOpen {
+			r.Status = ReconciliationOpen
+			return
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package entity
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReconciliationException_Resolve(t *testing.T) {
+	r := &ReconciliationReport{Exceptions: []ReconciliationException{
+		{ID: "e1", Status: ExceptionOpen},
+		{ID: "e2", Status: ExceptionOpen},
+	}}
+	r.RefreshStatus()
+	if r.Status != ReconciliationOpen {
+		t.Fatalf("expected open, got %s", r.Status)
+	}
+
+	if err := r.Exceptions[0].Resolve("ignore", "", time.Now()); err == nil {
+		t.Error("expected invalid resolution error")
+	}
+	if err := r.Exceptions[0].Resolve(ResolutionWriteOff, "fee absorbed", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Exceptions[0].Resolve(ResolutionWriteOff, "", time.Now()); err == nil {
+		t.Error("expected error resolving twice")
+	}
+	r.RefreshStatus()
+	if r.Status != ReconciliationOpen {
+		t.Fatalf("expected open, got %s", r.Status)
+	}
+
+	if err := r.Exceptions[1].Resolve(ResolutionFalsePositive, "", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	r.RefreshStatus()
+	if r.Status != ReconciliationReconciled || r.Exceptions[1].ResolvedAt == nil {
+		t.Errorf("expected reconciled, got %s", r.Status)
+	}
+}
@@ -6,6 +6,7 @@ type RevenueRecord struct {
 	ID         string
 	BookingID  string
 	DeltaCents int64
+	Currency   string
 	CreatedAt  time.Time
 	UpdatedAt  time.Time
 }
@@ -0,0 +1,73 @@
+package entity
+
+import "time"
+
+// 收入统计的时间粒度
+const (
+	GranularityDay   = "day"
+	GranularityWeek  = "week" // ISO 周，周一开始
+	GranularityMonth = "month"
+)
+
+// 收入统计的分组维度
+const (
+	DimensionAirport     = "airport"
+	DimensionVehicleType = "vehicle_type"
+)
+
+// UnknownDimension 早期订单缺少机场或车型时的取值。
+const UnknownDimension = "unknown"
+
+// RevenueFact 一条结算记录对收入统计的贡献，按结算记录的创建日（UTC）计入。
+// 订单结算计 1 单；退款冲减记录金额为负，不计单量。
+type RevenueFact struct {
+	RecordID     string
+	BookingID    string
+	Day          time.Time
+	AirportCode  string
+	VehicleType  string
+	Currency     string
+	Trips        int64
+	GrossCents   int64 // 乘客支付的订单总额（GBV）
+	RevenueCents int64 // 平台收入
+}
+
+// RevenueStat 按时间段、机场、车型与币种汇总的收入统计。
+// Period 为时间段的起始日；未参与分组的维度为空。
+type RevenueStat struct {
+	Period       time.Time
+	AirportCode  string
+	VehicleType  string
+	Currency     string
+	Trips        int64
+	GrossCents   int64
+	RevenueCents int64
+}
+
+// Add 累加另一条统计的数值。
+func (s *RevenueStat) Add(o RevenueStat) {
+	s.Trips += o.Trips
+	s.GrossCents += o.GrossCents
+	s.RevenueCents += o.RevenueCents
+}
+
+// TakeRate 平台收入占订单总额的比例；总额不为正时返回 0。
+func (s RevenueStat) TakeRate() float64 {
+	if s.GrossCents <= 0 {
+		return 0
+	}
+	return float64(s.RevenueCents) / float64(s.GrossCents)
+}
+
+// PeriodStart 返回 day 所在时间段的起始日（UTC）。
+func PeriodStart(day time.Time, granularity string) time.Time {
+	u := day.UTC()
+	d := time.Date(u.Year(), u.Month(), u.Day(), 0, 0, 0, 0, time.UTC)
+	switch granularity {
+	case GranularityWeek:
+		return d.AddDate(0, 0, -((int(d.Weekday()) + 6) % 7))
+	case GranularityMonth:
+		return d.AddDate(0, 0, 1-d.Day())
+	}
+	return d
+}
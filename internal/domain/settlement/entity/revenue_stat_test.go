@@ -0,0 +1,42 @@
+package entity
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPeriodStart(t *testing.T) {
+	// 2026-03-05 为周四
+	day := time.Date(2026, 3, 5, 23, 30, 0, 0, time.UTC)
+	cases := map[string]string{
+		GranularityDay:   "2026-03-05",
+		GranularityWeek:  "2026-03-02",
+		GranularityMonth: "2026-03-01",
+	}
+	for g, want := range cases {
+		if got := PeriodStart(day, g).Format("2006-01-02"); got != want {
+			t.Errorf("%s: got %s, want %s", g, got, want)
+		}
+	}
+	// 周日归入前一个周一开始的周
+	sunday := time.Date(2026, 3, 8, 1, 0, 0, 0, time.UTC)
+	if got := PeriodStart(sunday, GranularityWeek).Format("2006-01-02"); got != "2026-03-02" {
+		t.Errorf("sunday week start: got %s", got)
+	}
+	// 按 UTC 取日期
+	local := time.Date(2026, 3, 1, 2, 0, 0, 0, time.FixedZone("CST", 8*3600))
+	if got := PeriodStart(local, GranularityDay).Format("2006-01-02"); got != "2026-02-28" {
+		t.Errorf("utc day: got %s", got)
+	}
+}
+
+func TestRevenueStat_TakeRate(t *testing.T) {
+	s := RevenueStat{Trips: 1, GrossCents: 2000, RevenueCents: 400}
+	s.Add(RevenueStat{GrossCents: -1000, RevenueCents: -200})
+	if s.TakeRate() != 0.2 || s.Trips != 1 {
+		t.Errorf("unexpected stat %+v take rate %v", s, s.TakeRate())
+	}
+	if (RevenueStat{RevenueCents: 10}).TakeRate() != 0 {
+		t.Error("take rate of zero gross must be 0")
+	}
+}
@@ -9,6 +9,10 @@ type SettlementRecord struct {
 	PassengerID          string
 	AmountCents          int64
 	PlatformRevenueCents int64
+	TaxCents             int64 // 代收税费，不计入平台收入，也不打款给司机
+	Currency             string
+	PayoutID             string         // 为空表示尚未打款给司机
+	FareItems            []FareLineItem // 车费明细；退款冲减记录与早期记录为空
 	CreatedAt            time.Time
 	UpdatedAt            time.Time
 }
@@ -0,0 +1,119 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+// 结算 saga 状态
+const (
+	SagaPaymentPending = "payment_pending" // 待扣款
+	SagaCharged        = "charged"         // 已扣款，待落库
+	SagaRecordsSaved   = "records_saved"   // 已落库，待发布事件
+	SagaCompleted      = "completed"       // 事件已发布
+	SagaCompensating   = "compensating"    // 落库失败，待退款
+	SagaRefunded       = "refunded"        // 已退款（补偿完成）
+	SagaFailed         = "failed"          // 扣款失败，无需补偿
+)
+
+// SettlementSaga 记录一次订单结算的进度，每个订单一条。
+// 步骤：payment_pending -> charged -> records_saved -> completed；
+// 扣款多次失败进入 failed，扣款后落库多次失败进入 compensating -> refunded。
+// 金额与支付方式在创建时确定，重试与恢复沿用同一金额和支付方式。
+type SettlementSaga struct {
+	ID                   string
+	BookingID            string
+	DriverID             string
+	PassengerID          string
+	PaymentMethod        string // 扣款的支付方式保险库令牌，为空从乘客钱包扣款
+	AmountCents          int64
+	PlatformRevenueCents int64
+	TaxCents             int64 // 代收税费，不含在平台收入中
+	Currency             string
+	FareItems            []FareLineItem // 创建时计算的车费明细，落库时随结算记录保存
+	Status               string
+	Attempts             int // 当前步骤已失败的次数
+	LastError            string
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+}
+
+// NewSettlementSaga 创建处于 payment_pending 状态的 saga。
+func NewSettlementSaga(id, bookingID, driverID, passengerID string, amountCents, platformRevenueCents int64) (*SettlementSaga, error) {
+	if bookingID == "" {
+		return nil, errors.New("booking_id required")
+	}
+	if amountCents < 0 || platformRevenueCents < 0 {
+		return nil, errors.New("amount_cents and platform_revenue_cents must be >= 0")
+	}
+	return &SettlementSaga{
+		ID:                   id,
+		BookingID:            bookingID,
+		DriverID:             driverID,
+		PassengerID:          passengerID,
+		AmountCents:          amountCents,
+		PlatformRevenueCents: platformRevenueCents,
+		Status:               SagaPaymentPending,
+	}, nil
+}
+
+// Finished 是否已到达终态。
+func (s *SettlementSaga) Finished() bool {
+	return s.Status == SagaCompleted || s.Status == SagaRefunded || s.Status == SagaFailed
+}
+
+// MarkCharged payment_pending -> charged
+func (s *SettlementSaga) MarkCharged() error {
+	return s.transit(SagaPaymentPending, SagaCharged)
+}
+
+// MarkRecordsSaved charged -> records_saved
+func (s *SettlementSaga) MarkRecordsSaved() error {
+	return s.transit(SagaCharged, SagaRecordsSaved)
+}
+
+// MarkCompleted records_saved -> completed
+func (s *SettlementSaga) MarkCompleted() error {
+	return s.transit(SagaRecordsSaved, SagaCompleted)
+}
+
+// MarkRefunded compensating -> refunded，保留触发补偿的错误便于排查
+func (s *SettlementSaga) MarkRefunded() error {
+	cause := s.LastError
+	if err := s.transit(SagaCompensating, SagaRefunded); err != nil {
+		return err
+	}
+	s.LastError = cause
+	return nil
+}
+
+// RecordFailure 记录当前步骤的一次失败；失败次数达到 maxAttempts 时：
+// 扣款步骤进入 failed，落库步骤进入 compensating。补偿（退款）步骤不设上限，持续重试。
+// 返回是否发生了状态转换。
+func (s *SettlementSaga) RecordFailure(cause error, maxAttempts int) bool {
+	s.Attempts++
+	s.LastError = cause.Error()
+	if s.Attempts < maxAttempts {
+		return false
+	}
+	switch s.Status {
+	case SagaPaymentPending:
+		s.Status = SagaFailed
+	case SagaCharged:
+		s.Status = SagaCompensating
+		s.Attempts = 0
+	default:
+		return false
+	}
+	return true
+}
+
+func (s *SettlementSaga) transit(from, to string) error {
+	if s.Status != from {
+		return errors.New("settlement saga status must be '" + from + "' to become '" + to + "'")
+	}
+	s.Status = to
+	s.Attempts = 0
+	s.LastError = ""
+	return nil
+}
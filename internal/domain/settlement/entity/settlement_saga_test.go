@@ -0,0 +1,79 @@
+package entity
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewSettlementSaga(t *testing.T) {
+	s, err := NewSettlementSaga("s1", "b1", "d1", "p1", 1000, 100)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.Status != SagaPaymentPending || s.Finished() {
+		t.Errorf("expected pending saga, got %+v", s)
+	}
+	if _, err := NewSettlementSaga("s2", "", "d1", "p1", 1000, 100); err == nil {
+		t.Errorf("expected error for empty booking id")
+	}
+	if _, err := NewSettlementSaga("s3", "b1", "d1", "p1", -1, 0); err == nil {
+		t.Errorf("expected error for negative amount")
+	}
+}
+
+func TestSettlementSaga_HappyPath(t *testing.T) {
+	s, _ := NewSettlementSaga("s1", "b1", "d1", "p1", 1000, 100)
+	s.RecordFailure(errors.New("gateway timeout"), 3)
+	if err := s.MarkCharged(); err != nil {
+		t.Fatalf("MarkCharged: %v", err)
+	}
+	if s.Attempts != 0 || s.LastError != "" {
+		t.Errorf("expected failure state reset after transition, got %+v", s)
+	}
+	if err := s.MarkRecordsSaved(); err != nil {
+		t.Fatalf("MarkRecordsSaved: %v", err)
+	}
+	if err := s.MarkCompleted(); err != nil {
+		t.Fatalf("MarkCompleted: %v", err)
+	}
+	if !s.Finished() {
+		t.Errorf("expected finished saga")
+	}
+	if err := s.MarkCharged(); err == nil {
+		t.Errorf("expected error charging a completed saga")
+	}
+}
+
+func TestSettlementSaga_ChargeFailuresEndInFailed(t *testing.T) {
+	s, _ := NewSettlementSaga("s1", "b1", "d1", "p1", 1000, 100)
+	if s.RecordFailure(errors.New("declined"), 2) {
+		t.Errorf("first failure should not change status")
+	}
+	if !s.RecordFailure(errors.New("declined"), 2) || s.Status != SagaFailed {
+		t.Errorf("expected failed after max attempts, got %+v", s)
+	}
+	if !s.Finished() || s.LastError != "declined" {
+		t.Errorf("unexpected saga %+v", s)
+	}
+}
+
+func TestSettlementSaga_SaveFailuresTriggerCompensation(t *testing.T) {
+	s, _ := NewSettlementSaga("s1", "b1", "d1", "p1", 1000, 100)
+	_ = s.MarkCharged()
+	s.RecordFailure(errors.New("db down"), 2)
+	if !s.RecordFailure(errors.New("db down"), 2) || s.Status != SagaCompensating {
+		t.Fatalf("expected compensating, got %+v", s)
+	}
+	// 退款失败不设上限
+	for i := 0; i < 5; i++ {
+		if s.RecordFailure(errors.New("refund failed"), 2) {
+			t.Fatalf("refund failures must not change status")
+		}
+	}
+	if err := s.MarkRefunded(); err != nil || !s.Finished() {
+		t.Errorf("expected refunded, got %+v, %v", s, err)
+	}
+	if s.LastError != "refund failed" {
+		t.Errorf("expected last error kept after refund, got %q", s.LastError)
+	}
+}
@@ -1,12 +1,34 @@
 package settlement
 
 import (
+	"errors"
+	"time"
+
 	settlemententity "github.com/gavin/airport-pickup/internal/domain/settlement/entity"
 )
 
+// ErrRefundExceedsCaptured 累计退款将超过已扣款金额。
+var ErrRefundExceedsCaptured = errors.New("refund exceeds captured amount")
+
 type SettlementRepository interface {
 	SavePaymentTransaction(t *settlemententity.PaymentTransaction) error
 	GetPaymentTransactionByID(id string) (*settlemententity.PaymentTransaction, error)
+	// 订单的支付流水（每单一条），不存在时返回 (nil, nil)
+	GetPaymentTransactionByBookingID(bookingID string) (*settlemententity.PaymentTransaction, error)
+	// 订单的退款流水，按创建时间排序
+	ListRefundTransactions(bookingID string) ([]*settlemententity.PaymentTransaction, error)
+	// 在支付流水上预占退款金额（累加 refunded_cents），超出已扣款金额时返回 ErrRefundExceedsCaptured；
+	// 返回预占前的累计退款金额
+	ReserveRefund(paymentID string, amountCents int64) (refundedBeforeCents int64, err error)
+	// 释放 ReserveRefund 预占的退款金额，用于支付服务退款失败时
+	ReleaseRefund(paymentID string, amountCents int64) error
+	// 原子保存一次已预占的退款：更新支付流水状态，写入退款流水、负向的结算与收入记录及退款分录；sr、rr 可为 nil
+	SaveRefund(payment, refund *settlemententity.PaymentTransaction, sr *settlemententity.SettlementRecord, rr *settlemententity.RevenueRecord, entries []*settlemententity.JournalEntry) error
+
+	// 押金预授权的流水，holdKey 为预授权幂等键；不存在时返回 (nil, nil)
+	GetDepositTransaction(holdKey string) (*settlemententity.PaymentTransaction, error)
+	// 原子保存已扣收的押金流水、收入记录及记账分录
+	SaveDepositCapture(ptx *settlemententity.PaymentTransaction, rr *settlemententity.RevenueRecord, entries []*settlemententity.JournalEntry) error
 
 	SaveSettlementRecord(r *settlemententity.SettlementRecord) error
 	GetSettlementRecordByID(id string) (*settlemententity.SettlementRecord, error)
@@ -14,6 +36,112 @@ type SettlementRepository interface {
 	SaveRevenueRecord(r *settlemententity.RevenueRecord) error
 	ListRevenueRecords() ([]*settlemententity.RevenueRecord, error)
 
-	// 原子保存三对象
-	SaveAllInTransaction(ptx *settlemententity.PaymentTransaction, sr *settlemententity.SettlementRecord, rr *settlemententity.RevenueRecord) error
+	// 原子保存三对象及对应的记账分录
+	SaveAllInTransaction(ptx *settlemententity.PaymentTransaction, sr *settlemententity.SettlementRecord, rr *settlemententity.RevenueRecord, entries []*settlemententity.JournalEntry) error
+
+	// settlement saga
+	SaveSettlementSaga(s *settlemententity.SettlementSaga) error
+	// 订单尚无 saga 时返回 (nil, nil)
+	GetSettlementSagaByBookingID(bookingID string) (*settlemententity.SettlementSaga, error)
+	// 未到达终态且 updated_at 早于 before 的 saga，供恢复任务续跑
+	ListStuckSettlementSagas(before time.Time, limit int) ([]*settlemententity.SettlementSaga, error)
+	// 原子保存三对象、记账分录并推进 saga 状态
+	SaveRecordsWithSaga(saga *settlemententity.SettlementSaga, ptx *settlemententity.PaymentTransaction, sr *settlemententity.SettlementRecord, rr *settlemententity.RevenueRecord, entries []*settlemententity.JournalEntry) error
+
+	// 复式记账
+	ListJournalEntries(bookingID string) ([]*settlemententity.JournalEntry, error)
+	// 账户在各币种下的余额，每个币种一条；无发生额时返回空
+	GetAccountBalances(account string) ([]settlemententity.AccountBalance, error)
+	// accountType 为空返回全部账户，否则返回该类型下的账户（如 driver_payable 返回各司机账户），按账户与币种分组
+	ListAccountBalances(accountType string) ([]settlemententity.AccountBalance, error)
+	// 全部分录行的借方与贷方合计
+	LedgerTotals() (debitCents, creditCents int64, err error)
+	// 借贷不平的分录 ID
+	ListUnbalancedJournalEntries() ([]string, error)
+}
+
+// ErrSettlementRecordClaimed 结算记录已被其他打款单占用。
+var ErrSettlementRecordClaimed = errors.New("settlement record already claimed by another payout")
+
+// ErrPayoutNotPending 打款单已不是 pending（已被其他任务完成或失败）。
+var ErrPayoutNotPending = errors.New("payout is not pending")
+
+// PayoutRepository 司机打款持久化。结算记录的 payout_id 为空表示未打款，
+// 创建打款单时占用、打款失败时释放，保证同一记录不会被重复打款。
+type PayoutRepository interface {
+	// 创建时间早于 before 且尚未打款的结算记录，按司机、币种与创建时间排序
+	ListUnpaidSettlementRecords(before time.Time) ([]*settlemententity.SettlementRecord, error)
+	// 原子写入 pending 打款单及明细并占用对应结算记录；任一记录已被占用时返回 ErrSettlementRecordClaimed
+	CreatePayout(p *settlemententity.Payout) error
+	// 原子标记打款成功并写入打款分录；打款单已不是 pending 时返回 ErrPayoutNotPending
+	CompletePayout(p *settlemententity.Payout, entries []*settlemententity.JournalEntry) error
+	// 原子标记打款失败并释放结算记录，使其回到未打款状态；打款单已不是 pending 时返回 ErrPayoutNotPending
+	FailPayout(p *settlemententity.Payout) error
+	// 不存在时返回 (nil, nil)，包含明细
+	GetPayout(id string) (*settlemententity.Payout, error)
+	// driverID 为空返回全部司机，按创建时间倒序，不含明细
+	ListPayouts(driverID string, limit int) ([]*settlemententity.Payout, error)
+	// 创建时间早于 before 仍为 pending 的打款单（进程在打款中途退出），包含明细
+	ListPendingPayouts(before time.Time) ([]*settlemententity.Payout, error)
+}
+
+// ErrInvoiceExists 同一订单的收据或同一企业客户、账期、开票主体与币种的发票已开具。
+var ErrInvoiceExists = errors.New("invoice already issued")
+
+// InvoiceRepository 收据与企业发票持久化。单据编号在写入时按开票主体原子分配，连续且不重复。
+type InvoiceRepository interface {
+	// 订单的全部结算记录（含退款冲减记录），按创建时间排序
+	ListSettlementRecordsByBooking(bookingID string) ([]*settlemententity.SettlementRecord, error)
+	// 企业客户名下乘客在 [from, to) 内创建的结算记录，按创建时间排序，包含车费明细
+	ListCorporateSettlementRecords(corporateAccount string, from, to time.Time) ([]*settlemententity.SettlementRecord, error)
+	// 有乘客归属的企业客户
+	ListCorporateAccounts() ([]string, error)
+	// 分配编号并写入单据及明细；已开具时返回 ErrInvoiceExists
+	CreateInvoice(inv *settlemententity.Invoice) error
+	// 不存在时返回 (nil, nil)，包含明细
+	GetInvoice(id string) (*settlemententity.Invoice, error)
+	// 订单的收据，不存在时返回 (nil, nil)，包含明细
+	GetReceiptByBookingID(bookingID string) (*settlemententity.Invoice, error)
+	// 企业客户的发票，按账期倒序，不含明细
+	ListCorporateInvoices(corporateAccount string) ([]*settlemententity.Invoice, error)
+}
+
+// ErrExceptionAlreadyResolved 对账差异已被处理。
+var ErrExceptionAlreadyResolved = errors.New("reconciliation exception already resolved")
+
+// ReconciliationRepository 支付对账持久化。
+type ReconciliationRepository interface {
+	// 扣款时间在 [from, to) 内的支付与押金流水，及创建时间在 [from, to) 内的退款流水
+	ListSettledPaymentTransactions(from, to time.Time) ([]*settlemententity.PaymentTransaction, error)
+	// 指定订单的全部已扣款支付流水与退款流水
+	ListPaymentTransactionsByBookings(bookingIDs []string) ([]*settlemententity.PaymentTransaction, error)
+	// 写入对账报告及差异
+	SaveReconciliationReport(r *settlemententity.ReconciliationReport) error
+	// 不存在时返回 (nil, nil)，包含差异
+	GetReconciliationReport(id string) (*settlemententity.ReconciliationReport, error)
+	// 按创建时间倒序，不含差异
+	ListReconciliationReports(limit int) ([]*settlemententity.ReconciliationReport, error)
+	// 原子保存差异的处理结果与报告状态；差异已被处理时返回 ErrExceptionAlreadyResolved
+	ResolveReconciliationException(r *settlemententity.ReconciliationReport, e *settlemententity.ReconciliationException) error
+}
+
+// RevenueStatsFilter 收入统计查询条件，日期为 UTC，区间 [From, To)；字符串条件为空表示不过滤。
+type RevenueStatsFilter struct {
+	From        time.Time
+	To          time.Time
+	AirportCode string
+	VehicleType string
+	Currency    string
+}
+
+// RevenueStatsRepository 收入统计聚合表，由结算事件增量维护。
+type RevenueStatsRepository interface {
+	// 订单的结算记录（含退款冲减），按创建时间排序
+	ListSettlementRecordsByBooking(bookingID string) ([]*settlemententity.SettlementRecord, error)
+	// 尚未计入统计的结算记录，按创建时间排序
+	ListUnprojectedSettlementRecords(limit int) ([]*settlemententity.SettlementRecord, error)
+	// 在同一事务中把增量累加到日统计并记录已计入的结算记录；已计入的跳过，返回实际计入的条数
+	ApplyRevenueFacts(facts []*settlemententity.RevenueFact) (int, error)
+	// 按日、机场、车型与币种的统计行
+	ListDailyRevenueStats(f RevenueStatsFilter) ([]settlemententity.RevenueStat, error)
 }
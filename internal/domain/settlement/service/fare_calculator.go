@@ -0,0 +1,153 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gavin/airport-pickup/internal/domain/money"
+	settlemententity "github.com/gavin/airport-pickup/internal/domain/settlement/entity"
+)
+
+// DefaultDistanceKm 行程未上报里程时的计费里程（演示用）。
+const DefaultDistanceKm = 10
+
+// FareRules 一个机场的计费规则，金额单位为该机场结算币种的分；PlatformShare 为该项中平台分成的比例（0~1）。
+// 零值规则只收里程费，与引入明细前的计费一致。
+type FareRules struct {
+	BaseFareCents         int64
+	BasePlatformShare     float64
+	FreeWaitingMinutes    int // 免费等候分钟数
+	WaitingPerMinuteCents int64
+	WaitingPlatformShare  float64
+	Surcharges            []SurchargeRule
+	TaxName               string  // 如 VAT
+	TaxRate               float64 // 对优惠后的税前合计计税（过路费不计税），税额由平台代收代缴
+}
+
+// SurchargeRule 固定金额的附加费，如机场接机费。
+type SurchargeRule struct {
+	Name          string
+	AmountCents   int64
+	PlatformShare float64
+}
+
+// Validate 校验金额非负、分成比例与税率在 [0, 1] 之间。
+func (r FareRules) Validate() error {
+	if r.BaseFareCents < 0 || r.WaitingPerMinuteCents < 0 || r.FreeWaitingMinutes < 0 {
+		return errors.New("fare amounts must be >= 0")
+	}
+	if !validShare(r.BasePlatformShare) || !validShare(r.WaitingPlatformShare) || !validShare(r.TaxRate) {
+		return errors.New("platform shares and tax rate must be between 0 and 1")
+	}
+	for _, s := range r.Surcharges {
+		if s.Name == "" || s.AmountCents < 0 || !validShare(s.PlatformShare) {
+			return fmt.Errorf("invalid surcharge %q", s.Name)
+		}
+	}
+	return nil
+}
+
+func validShare(f float64) bool { return f >= 0 && f <= 1 }
+
+type CalculateFareCmd struct {
+	Currency            string
+	PricePerKm          money.Money // 司机报价
+	PlatformMarginPerKm money.Money // 每公里平台差价
+	DistanceKm          float64     // 0 按 DefaultDistanceKm 计
+	WaitingMinutes      int
+	TollsCents          int64
+	DiscountCents       int64 // 由平台承担，最多抵扣平台在税前各项中的分成
+	Rules               FareRules
+}
+
+// FareCalculator 按机场计费规则生成车费明细，并把每一项拆分为司机与平台两部分。
+type FareCalculator struct{}
+
+func NewFareCalculator() *FareCalculator {
+	return &FareCalculator{}
+}
+
+// Calculate 依次计算起步价、里程费、等候费、附加费、优惠、税费与过路费；金额为 0 的项不列出。
+func (c *FareCalculator) Calculate(cmd *CalculateFareCmd) (*settlemententity.FareBreakdown, error) {
+	if !money.ValidCurrency(cmd.Currency) {
+		return nil, errors.New("invalid currency")
+	}
+	if cmd.DistanceKm < 0 || cmd.WaitingMinutes < 0 || cmd.TollsCents < 0 || cmd.DiscountCents < 0 {
+		return nil, errors.New("distance_km, waiting_minutes, tolls_cents and discount_cents must be >= 0")
+	}
+	if cmd.PricePerKm.IsNegative() || cmd.PlatformMarginPerKm.IsNegative() {
+		return nil, errors.New("price_per_km and platform_margin_per_km must be >= 0")
+	}
+	rules := cmd.Rules
+	if err := rules.Validate(); err != nil {
+		return nil, err
+	}
+	km := cmd.DistanceKm
+	if km == 0 {
+		km = DefaultDistanceKm
+	}
+
+	var items []settlemententity.FareLineItem
+	add := func(it settlemententity.FareLineItem) {
+		if it.AmountCents != 0 {
+			items = append(items, it)
+		}
+	}
+	add(splitItem(settlemententity.FareBase, "Base fare", rules.BaseFareCents, rules.BasePlatformShare))
+
+	// 里程费：乘客按司机报价付费，平台取每公里差价
+	distance := cmd.PricePerKm.Mul(km).Cents()
+	platform := min(cmd.PlatformMarginPerKm.Mul(km).Cents(), distance)
+	add(settlemententity.FareLineItem{
+		Kind:          settlemententity.FareDistance,
+		Description:   fmt.Sprintf("%g km x %s", km, cmd.PricePerKm),
+		AmountCents:   distance,
+		DriverCents:   distance - platform,
+		PlatformCents: platform,
+	})
+
+	if billable := cmd.WaitingMinutes - rules.FreeWaitingMinutes; billable > 0 {
+		add(splitItem(settlemententity.FareWaiting, fmt.Sprintf("%d min waiting", billable),
+			int64(billable)*rules.WaitingPerMinuteCents, rules.WaitingPlatformShare))
+	}
+	for _, s := range rules.Surcharges {
+		add(splitItem(settlemententity.FareSurcharge, s.Name, s.AmountCents, s.PlatformShare))
+	}
+
+	var taxable, platformShare int64
+	for _, it := range items {
+		taxable += it.AmountCents
+		platformShare += it.PlatformCents
+	}
+	if d := min(cmd.DiscountCents, platformShare); d > 0 {
+		add(settlemententity.FareLineItem{Kind: settlemententity.FareDiscount, Description: "Discount", AmountCents: -d, PlatformCents: -d})
+		taxable -= d
+	}
+	if rules.TaxRate > 0 {
+		name := rules.TaxName
+		if name == "" {
+			name = "Tax"
+		}
+		tax := money.FromCents(taxable).Mul(rules.TaxRate).Cents()
+		add(settlemententity.FareLineItem{
+			Kind:        settlemententity.FareTax,
+			Description: fmt.Sprintf("%s %.4g%%", name, rules.TaxRate*100),
+			AmountCents: tax,
+			TaxCents:    tax,
+		})
+	}
+	add(settlemententity.FareLineItem{Kind: settlemententity.FareToll, Description: "Tolls", AmountCents: cmd.TollsCents, DriverCents: cmd.TollsCents})
+	return settlemententity.NewFareBreakdown(cmd.Currency, items), nil
+}
+
+// splitItem 按平台分成比例拆分一项，平台部分四舍五入到分，余下归司机。
+func splitItem(kind, description string, amountCents int64, platformShare float64) settlemententity.FareLineItem {
+	platform := money.FromCents(amountCents).Mul(platformShare).Cents()
+	return settlemententity.FareLineItem{
+		Kind:          kind,
+		Description:   description,
+		AmountCents:   amountCents,
+		DriverCents:   amountCents - platform,
+		PlatformCents: platform,
+	}
+}
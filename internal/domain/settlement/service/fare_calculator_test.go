@@ -0,0 +1,83 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/gavin/airport-pickup/internal/domain/money"
+	settlemententity "github.com/gavin/airport-pickup/internal/domain/settlement/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFareCalculator_DefaultRulesMatchDistanceOnly(t *testing.T) {
+	b, err := NewFareCalculator().Calculate(&CalculateFareCmd{
+		Currency: "CNY", PricePerKm: money.MustParse("2.15"), PlatformMarginPerKm: money.MustParse("0.15"),
+	})
+	require.NoError(t, err)
+	require.Len(t, b.Items, 1)
+	assert.Equal(t, settlemententity.FareDistance, b.Items[0].Kind)
+	assert.Equal(t, int64(2150), b.TotalCents)
+	assert.Equal(t, int64(150), b.PlatformCents)
+	assert.Equal(t, int64(2000), b.DriverCents)
+}
+
+func TestFareCalculator_Itemized(t *testing.T) {
+	rules := FareRules{
+		BaseFareCents: 500, BasePlatformShare: 0.2,
+		FreeWaitingMinutes: 5, WaitingPerMinuteCents: 50,
+		Surcharges: []SurchargeRule{{Name: "Airport pickup", AmountCents: 1000, PlatformShare: 1}},
+		TaxName:    "VAT", TaxRate: 0.06,
+	}
+	b, err := NewFareCalculator().Calculate(&CalculateFareCmd{
+		Currency: "USD", PricePerKm: money.MustParse("2"), PlatformMarginPerKm: money.MustParse("0.5"),
+		DistanceKm: 12.5, WaitingMinutes: 8, TollsCents: 700, DiscountCents: 300, Rules: rules,
+	})
+	require.NoError(t, err)
+	kinds := make([]string, 0, len(b.Items))
+	for _, it := range b.Items {
+		kinds = append(kinds, it.Kind)
+		assert.Equal(t, it.AmountCents, it.DriverCents+it.PlatformCents+it.TaxCents, it.Kind)
+	}
+	assert.Equal(t, []string{
+		settlemententity.FareBase, settlemententity.FareDistance, settlemententity.FareWaiting, settlemententity.FareSurcharge,
+		settlemententity.FareDiscount, settlemententity.FareTax, settlemententity.FareToll,
+	}, kinds)
+
+	// 税前：500 + 2500 + 150 + 1000 - 300 = 3850，税 231
+	assert.Equal(t, int64(231), b.Items[5].AmountCents)
+	assert.Equal(t, int64(231), b.Items[5].TaxCents)
+	assert.Equal(t, int64(0), b.Items[5].PlatformCents)
+	assert.Equal(t, int64(3850+231+700), b.TotalCents)
+	// 平台：100 + 625 + 0 + 1000 - 300，税费单独列出不计入平台收入
+	assert.Equal(t, int64(1425), b.PlatformCents)
+	assert.Equal(t, int64(231), b.TaxCents)
+	assert.Equal(t, b.TotalCents, b.DriverCents+b.PlatformCents+b.TaxCents)
+	assert.Equal(t, "USD", b.Currency)
+	// 可优惠金额不含优惠、税费与过路费
+	amount, platform := b.DiscountableCents()
+	assert.Equal(t, int64(4150), amount)
+	assert.Equal(t, int64(1725), platform)
+}
+
+func TestFareCalculator_DiscountCappedAtPlatformShare(t *testing.T) {
+	b, err := NewFareCalculator().Calculate(&CalculateFareCmd{
+		Currency: "CNY", PricePerKm: money.MustParse("2"), PlatformMarginPerKm: money.MustParse("0.1"), DiscountCents: 5000,
+	})
+	require.NoError(t, err)
+	// 优惠只抵扣平台的 100，司机收入不受影响
+	assert.Equal(t, int64(0), b.PlatformCents)
+	assert.Equal(t, int64(1900), b.DriverCents)
+	assert.Equal(t, int64(1900), b.TotalCents)
+}
+
+func TestFareCalculator_Validation(t *testing.T) {
+	calc := NewFareCalculator()
+	_, err := calc.Calculate(&CalculateFareCmd{Currency: "cny"})
+	assert.Error(t, err)
+	_, err = calc.Calculate(&CalculateFareCmd{Currency: "CNY", WaitingMinutes: -1})
+	assert.Error(t, err)
+	_, err = calc.Calculate(&CalculateFareCmd{Currency: "CNY", Rules: FareRules{TaxRate: 1.5}})
+	assert.Error(t, err)
+	_, err = calc.Calculate(&CalculateFareCmd{Currency: "CNY", Rules: FareRules{Surcharges: []SurchargeRule{{Name: "x", AmountCents: -1}}}})
+	assert.Error(t, err)
+}
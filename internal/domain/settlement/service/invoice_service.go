@@ -0,0 +1,155 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gavin/airport-pickup/internal/domain/money"
+	settlemententity "github.com/gavin/airport-pickup/internal/domain/settlement/entity"
+)
+
+// InvoiceService 由已落库的结算记录与支付流水生成收据和企业月度发票，编号由仓储落库时分配。
+type InvoiceService struct{}
+
+func NewInvoiceService() *InvoiceService {
+	return &InvoiceService{}
+}
+
+type BuildReceiptCmd struct {
+	Issuer  settlemententity.LegalEntity
+	Record  *settlemententity.SettlementRecord   // 订单的结算记录（非退款冲减记录）
+	Payment *settlemententity.PaymentTransaction // 订单的支付流水，须已扣款
+	Refunds []*settlemententity.PaymentTransaction
+}
+
+// BuildReceipt 生成单笔订单收据：按车费明细逐项列出（无明细的早期订单为一行），其后为各笔退款。
+func (s *InvoiceService) BuildReceipt(cmd *BuildReceiptCmd) (*settlemententity.Invoice, error) {
+	if err := validIssuer(cmd.Issuer); err != nil {
+		return nil, err
+	}
+	r, p := cmd.Record, cmd.Payment
+	if r == nil || p == nil {
+		return nil, errors.New("settlement record and payment required")
+	}
+	if r.AmountCents < 0 {
+		return nil, errors.New("settlement record is a refund adjustment")
+	}
+	if p.BookingID != r.BookingID || p.Kind != settlemententity.PaymentKindPayment {
+		return nil, errors.New("payment does not belong to booking")
+	}
+	if p.CapturedAt == nil {
+		return nil, errors.New("payment not captured")
+	}
+	if p.Currency != r.Currency {
+		return nil, money.ErrCurrencyMismatch
+	}
+	inv := &settlemententity.Invoice{
+		Kind:        settlemententity.InvoiceReceipt,
+		Issuer:      cmd.Issuer,
+		Currency:    r.Currency,
+		BookingID:   r.BookingID,
+		PassengerID: r.PassengerID,
+		PaymentID:   p.ID,
+		PaidAt:      p.CapturedAt,
+	}
+	if len(r.FareItems) == 0 {
+		inv.AddLine(settlemententity.InvoiceLine{Kind: settlemententity.InvoiceLineFare, BookingID: r.BookingID, Description: "Airport pickup", AmountCents: r.AmountCents, Date: *p.CapturedAt})
+	}
+	for _, it := range r.FareItems {
+		l := settlemententity.InvoiceLine{Kind: it.Kind, BookingID: r.BookingID, Description: it.Description, AmountCents: it.AmountCents, Date: *p.CapturedAt}
+		if it.Kind == settlemententity.FareTax {
+			l.TaxCents = it.AmountCents
+		}
+		inv.AddLine(l)
+	}
+	for _, rf := range cmd.Refunds {
+		if rf.BookingID != r.BookingID || rf.Kind != settlemententity.PaymentKindRefund {
+			return nil, errors.New("refund does not belong to booking")
+		}
+		if rf.Currency != r.Currency {
+			return nil, money.ErrCurrencyMismatch
+		}
+		inv.AddLine(settlemententity.InvoiceLine{
+			Kind:        settlemententity.InvoiceLineRefund,
+			BookingID:   r.BookingID,
+			Description: refundDescription(rf.ReasonCode),
+			AmountCents: -rf.AmountCents,
+			Date:        rf.CreatedAt,
+		})
+	}
+	return inv, nil
+}
+
+type BuildCorporateInvoiceCmd struct {
+	Issuer           settlemententity.LegalEntity
+	CorporateAccount string
+	PeriodStart      time.Time
+	PeriodEnd        time.Time
+	Records          []*settlemententity.SettlementRecord // 账期内创建的结算记录，含退款冲减记录
+}
+
+// BuildCorporateInvoice 生成企业客户的月度合并发票：每条结算记录一行，退款冲减记录为负数行。
+// 结算记录须为同一币种，不同币种分别开票。
+func (s *InvoiceService) BuildCorporateInvoice(cmd *BuildCorporateInvoiceCmd) (*settlemententity.Invoice, error) {
+	if err := validIssuer(cmd.Issuer); err != nil {
+		return nil, err
+	}
+	if cmd.CorporateAccount == "" {
+		return nil, errors.New("corporate_account required")
+	}
+	if !cmd.PeriodStart.Before(cmd.PeriodEnd) {
+		return nil, errors.New("period_start must be before period_end")
+	}
+	if len(cmd.Records) == 0 {
+		return nil, errors.New("records required")
+	}
+	inv := &settlemententity.Invoice{
+		Kind:             settlemententity.InvoiceCorporate,
+		Issuer:           cmd.Issuer,
+		Currency:         cmd.Records[0].Currency,
+		CorporateAccount: cmd.CorporateAccount,
+		PeriodStart:      cmd.PeriodStart,
+		PeriodEnd:        cmd.PeriodEnd,
+	}
+	for _, r := range cmd.Records {
+		if r.Currency != inv.Currency {
+			return nil, money.ErrCurrencyMismatch
+		}
+		if r.CreatedAt.Before(cmd.PeriodStart) || !r.CreatedAt.Before(cmd.PeriodEnd) {
+			return nil, fmt.Errorf("settlement record %s outside invoice period", r.ID)
+		}
+		l := settlemententity.InvoiceLine{
+			Kind:        settlemententity.InvoiceLineFare,
+			BookingID:   r.BookingID,
+			Description: "Airport pickup " + r.BookingID,
+			AmountCents: r.AmountCents,
+			Date:        r.CreatedAt,
+		}
+		if r.AmountCents < 0 {
+			l.Kind = settlemententity.InvoiceLineRefund
+			l.Description = "Refund " + r.BookingID
+		}
+		for _, it := range r.FareItems {
+			if it.Kind == settlemententity.FareTax {
+				l.TaxCents += it.AmountCents
+			}
+		}
+		inv.AddLine(l)
+	}
+	return inv, nil
+}
+
+func validIssuer(e settlemententity.LegalEntity) error {
+	if e.Code == "" || e.Name == "" {
+		return errors.New("legal entity code and name required")
+	}
+	return nil
+}
+
+func refundDescription(reasonCode string) string {
+	if reasonCode == "" {
+		return "Refund"
+	}
+	return "Refund (" + reasonCode + ")"
+}
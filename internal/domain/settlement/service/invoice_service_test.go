@@ -0,0 +1,85 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gavin/airport-pickup/internal/domain/money"
+	settlemententity "github.com/gavin/airport-pickup/internal/domain/settlement/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testIssuer = settlemententity.LegalEntity{Code: "US01", Name: "Pickup US Inc."}
+
+func TestBuildReceipt_FareItemsAndRefunds(t *testing.T) {
+	captured := time.Date(2025, 11, 5, 10, 0, 0, 0, time.UTC)
+	record := &settlemententity.SettlementRecord{
+		ID: "sr1", BookingID: "b1", PassengerID: "p1", AmountCents: 2645, PlatformRevenueCents: 645, Currency: "USD",
+		FareItems: []settlemententity.FareLineItem{
+			{Kind: settlemententity.FareDistance, Description: "10 km x 2.00", AmountCents: 2000, DriverCents: 1500, PlatformCents: 500},
+			{Kind: settlemententity.FareSurcharge, Description: "Airport pickup fee", AmountCents: 500, PlatformCents: 500},
+			{Kind: settlemententity.FareTax, Description: "Sales tax 5.6%", AmountCents: 145, TaxCents: 145},
+		},
+	}
+	payment := &settlemententity.PaymentTransaction{ID: "pay1", BookingID: "b1", Kind: settlemententity.PaymentKindPayment, CapturedCents: 2645, Currency: "USD", CapturedAt: &captured}
+	refund := &settlemententity.PaymentTransaction{ID: "rf1", BookingID: "b1", Kind: settlemententity.PaymentKindRefund, AmountCents: 500, ReasonCode: "overcharge", Currency: "USD"}
+
+	inv, err := NewInvoiceService().BuildReceipt(&BuildReceiptCmd{Issuer: testIssuer, Record: record, Payment: payment, Refunds: []*settlemententity.PaymentTransaction{refund}})
+	require.NoError(t, err)
+	assert.Equal(t, settlemententity.InvoiceReceipt, inv.Kind)
+	require.Len(t, inv.Lines, 4)
+	assert.Equal(t, "Refund (overcharge)", inv.Lines[3].Description)
+	assert.Equal(t, int64(-500), inv.Lines[3].AmountCents)
+	assert.Equal(t, int64(2145), inv.TotalCents)
+	assert.Equal(t, int64(145), inv.TaxCents)
+	assert.Equal(t, "pay1", inv.PaymentID)
+}
+
+func TestBuildReceipt_Validation(t *testing.T) {
+	svc := NewInvoiceService()
+	record := &settlemententity.SettlementRecord{BookingID: "b1", AmountCents: 1000, Currency: "CNY"}
+	uncaptured := &settlemententity.PaymentTransaction{BookingID: "b1", Kind: settlemententity.PaymentKindPayment, Currency: "CNY"}
+	_, err := svc.BuildReceipt(&BuildReceiptCmd{Issuer: testIssuer, Record: record, Payment: uncaptured})
+	assert.Error(t, err)
+
+	now := time.Now()
+	usd := &settlemententity.PaymentTransaction{BookingID: "b1", Kind: settlemententity.PaymentKindPayment, Currency: "USD", CapturedAt: &now}
+	_, err = svc.BuildReceipt(&BuildReceiptCmd{Issuer: testIssuer, Record: record, Payment: usd})
+	assert.ErrorIs(t, err, money.ErrCurrencyMismatch)
+
+	// 无车费明细的早期记录为一行
+	ok := &settlemententity.PaymentTransaction{BookingID: "b1", Kind: settlemententity.PaymentKindPayment, Currency: "CNY", CapturedAt: &now}
+	inv, err := svc.BuildReceipt(&BuildReceiptCmd{Issuer: testIssuer, Record: record, Payment: ok})
+	require.NoError(t, err)
+	require.Len(t, inv.Lines, 1)
+	assert.Equal(t, int64(1000), inv.TotalCents)
+
+	_, err = svc.BuildReceipt(&BuildReceiptCmd{Issuer: settlemententity.LegalEntity{}, Record: record, Payment: ok})
+	assert.Error(t, err)
+}
+
+func TestBuildCorporateInvoice(t *testing.T) {
+	start := time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, 0)
+	records := []*settlemententity.SettlementRecord{
+		{ID: "sr1", BookingID: "b1", AmountCents: 2120, Currency: "CNY", CreatedAt: start.Add(time.Hour),
+			FareItems: []settlemententity.FareLineItem{{Kind: settlemententity.FareDistance, AmountCents: 2000}, {Kind: settlemententity.FareTax, AmountCents: 120}}},
+		{ID: "sr2", BookingID: "b0", AmountCents: -300, Currency: "CNY", CreatedAt: start.Add(2 * time.Hour)},
+	}
+	svc := NewInvoiceService()
+	inv, err := svc.BuildCorporateInvoice(&BuildCorporateInvoiceCmd{Issuer: testIssuer, CorporateAccount: "ACME", PeriodStart: start, PeriodEnd: end, Records: records})
+	require.NoError(t, err)
+	require.Len(t, inv.Lines, 2)
+	assert.Equal(t, settlemententity.InvoiceLineRefund, inv.Lines[1].Kind)
+	assert.Equal(t, int64(1820), inv.TotalCents)
+	assert.Equal(t, int64(120), inv.TaxCents)
+
+	outside := &settlemententity.SettlementRecord{ID: "sr3", BookingID: "b3", AmountCents: 100, Currency: "CNY", CreatedAt: end}
+	_, err = svc.BuildCorporateInvoice(&BuildCorporateInvoiceCmd{Issuer: testIssuer, CorporateAccount: "ACME", PeriodStart: start, PeriodEnd: end, Records: append(records, outside)})
+	assert.Error(t, err)
+
+	usd := &settlemententity.SettlementRecord{ID: "sr4", BookingID: "b4", AmountCents: 100, Currency: "USD", CreatedAt: start}
+	_, err = svc.BuildCorporateInvoice(&BuildCorporateInvoiceCmd{Issuer: testIssuer, CorporateAccount: "ACME", PeriodStart: start, PeriodEnd: end, Records: append(records, usd)})
+	assert.ErrorIs(t, err, money.ErrCurrencyMismatch)
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/gavin/airport-pickup/internal/domain/money"
+	settlemententity "github.com/gavin/airport-pickup/internal/domain/settlement/entity"
+)
+
+// LedgerService 定义结算与退款的复式记账规则，分录 ID 由调用方分配。
+type LedgerService struct{}
+
+func NewLedgerService() *LedgerService {
+	return &LedgerService{}
+}
+
+type PostSettlementCmd struct {
+	BookingID            string
+	DriverID             string
+	PassengerID          string
+	AmountCents          int64
+	PlatformRevenueCents int64
+	TaxCents             int64
+	Currency             string
+}
+
+// PostSettlement 一次结算产生四笔分录：
+// settlement 借乘客应收、贷应付司机（全额车费）；
+// payment 借支付清算、贷乘客应收（扣款到账）；
+// fee 借应付司机、贷平台收入（平台抽成）；
+// tax 借应付司机、贷应交税费（代收税费）。
+func (s *LedgerService) PostSettlement(cmd *PostSettlementCmd) ([]*settlemententity.JournalEntry, error) {
+	if cmd.DriverID == "" || cmd.PassengerID == "" {
+		return nil, errors.New("driver_id, passenger_id required")
+	}
+	if cmd.AmountCents < 0 || cmd.PlatformRevenueCents < 0 || cmd.TaxCents < 0 || cmd.PlatformRevenueCents+cmd.TaxCents > cmd.AmountCents {
+		return nil, errors.New("platform_revenue_cents and tax_cents must be >= 0 and not exceed amount_cents")
+	}
+	if !money.ValidCurrency(cmd.Currency) {
+		return nil, errors.New("invalid currency")
+	}
+	receivable := settlemententity.PassengerReceivableAccount(cmd.PassengerID)
+	payable := settlemententity.DriverPayableAccount(cmd.DriverID)
+	fare, err := settlemententity.NewJournalEntry("", cmd.BookingID, settlemententity.JournalSettlement,
+		settlemententity.Debit(receivable, cmd.AmountCents),
+		settlemententity.Credit(payable, cmd.AmountCents))
+	if err != nil {
+		return nil, err
+	}
+	payment, err := settlemententity.NewJournalEntry("", cmd.BookingID, settlemententity.JournalPayment,
+		settlemententity.Debit(settlemententity.AccountPaymentClearing, cmd.AmountCents),
+		settlemententity.Credit(receivable, cmd.AmountCents))
+	if err != nil {
+		return nil, err
+	}
+	fee, err := settlemententity.NewJournalEntry("", cmd.BookingID, settlemententity.JournalFee,
+		settlemententity.Debit(payable, cmd.PlatformRevenueCents),
+		settlemententity.Credit(settlemententity.AccountPlatformRevenue, cmd.PlatformRevenueCents))
+	if err != nil {
+		return nil, err
+	}
+	tax, err := settlemententity.NewJournalEntry("", cmd.BookingID, settlemententity.JournalTax,
+		settlemententity.Debit(payable, cmd.TaxCents),
+		settlemententity.Credit(settlemententity.AccountTaxPayable, cmd.TaxCents))
+	if err != nil {
+		return nil, err
+	}
+	// 金额为 0 的分录（如免费行程、零抽成、免税）不记账
+	entries := make([]*settlemententity.JournalEntry, 0, 4)
+	for _, e := range []*settlemententity.JournalEntry{fare, payment, fee, tax} {
+		if len(e.Lines) > 0 {
+			e.Currency = cmd.Currency
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+type PostRefundCmd struct {
+	BookingID     string
+	DriverID      string
+	RefundCents   int64
+	PlatformCents int64 // 退款中由平台承担的部分
+	TaxCents      int64 // 退款中冲减的代收税费，其余冲减应付司机
+	Currency      string
+}
+
+// PostRefund 退款分录：借应付司机（司机承担部分）、借平台收入（平台承担部分）、借应交税费（冲减税费），贷支付清算（全额）。
+func (s *LedgerService) PostRefund(cmd *PostRefundCmd) (*settlemententity.JournalEntry, error) {
+	if cmd.DriverID == "" {
+		return nil, errors.New("driver_id required")
+	}
+	if cmd.RefundCents <= 0 || cmd.PlatformCents < 0 || cmd.TaxCents < 0 || cmd.PlatformCents+cmd.TaxCents > cmd.RefundCents {
+		return nil, errors.New("platform_cents and tax_cents must be >= 0 and not exceed refund_cents")
+	}
+	if !money.ValidCurrency(cmd.Currency) {
+		return nil, errors.New("invalid currency")
+	}
+	e, err := settlemententity.NewJournalEntry("", cmd.BookingID, settlemententity.JournalRefund,
+		settlemententity.Debit(settlemententity.DriverPayableAccount(cmd.DriverID), cmd.RefundCents-cmd.PlatformCents-cmd.TaxCents),
+		settlemententity.Debit(settlemententity.AccountPlatformRevenue, cmd.PlatformCents),
+		settlemententity.Debit(settlemententity.AccountTaxPayable, cmd.TaxCents),
+		settlemententity.Credit(settlemententity.AccountPaymentClearing, cmd.RefundCents))
+	if err != nil {
+		return nil, err
+	}
+	e.Currency = cmd.Currency
+	return e, nil
+}
+
+type PostDepositCaptureCmd struct {
+	BookingID   string
+	AmountCents int64
+	Currency    string
+}
+
+// PostDepositCapture 乘客未到场扣收押金的分录：借支付清算，贷平台收入。
+func (s *LedgerService) PostDepositCapture(cmd *PostDepositCaptureCmd) (*settlemententity.JournalEntry, error) {
+	if cmd.AmountCents <= 0 {
+		return nil, errors.New("amount_cents must be > 0")
+	}
+	if !money.ValidCurrency(cmd.Currency) {
+		return nil, errors.New("invalid currency")
+	}
+	e, err := settlemententity.NewJournalEntry("", cmd.BookingID, settlemententity.JournalDeposit,
+		settlemententity.Debit(settlemententity.AccountPaymentClearing, cmd.AmountCents),
+		settlemententity.Credit(settlemententity.AccountPlatformRevenue, cmd.AmountCents))
+	if err != nil {
+		return nil, err
+	}
+	e.Currency = cmd.Currency
+	return e, nil
+}
+
+// PostPayout 打款成功后的分录：fee 借应付司机、贷平台收入（打款手续费）；
+// payout 借应付司机、贷支付清算（实付金额）。平台抽成已在结算时记账。
+func (s *LedgerService) PostPayout(p *settlemententity.Payout) ([]*settlemententity.JournalEntry, error) {
+	if p.NetCents <= 0 || p.FeeCents < 0 {
+		return nil, errors.New("payout net_cents must be > 0 and fee_cents >= 0")
+	}
+	if !money.ValidCurrency(p.Currency) {
+		return nil, errors.New("invalid currency")
+	}
+	payable := settlemententity.DriverPayableAccount(p.DriverID)
+	entries := make([]*settlemententity.JournalEntry, 0, 2)
+	if p.FeeCents > 0 {
+		fee, err := settlemententity.NewPayoutJournalEntry("", p.ID, settlemententity.JournalFee,
+			settlemententity.Debit(payable, p.FeeCents),
+			settlemententity.Credit(settlemententity.AccountPlatformRevenue, p.FeeCents))
+		if err != nil {
+			return nil, err
+		}
+		fee.Currency = p.Currency
+		entries = append(entries, fee)
+	}
+	payout, err := settlemententity.NewPayoutJournalEntry("", p.ID, settlemententity.JournalPayout,
+		settlemententity.Debit(payable, p.NetCents),
+		settlemententity.Credit(settlemententity.AccountPaymentClearing, p.NetCents))
+	if err != nil {
+		return nil, err
+	}
+	payout.Currency = p.Currency
+	return append(entries, payout), nil
+}
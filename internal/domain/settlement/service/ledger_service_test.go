@@ -0,0 +1,97 @@
+package service
+
+import (
+	"testing"
+
+	settlemententity "github.com/gavin/airport-pickup/internal/domain/settlement/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// balances 汇总分录后各账户按正常方向的余额
+func balances(entries ...*settlemententity.JournalEntry) map[string]int64 {
+	sums := map[string]*settlemententity.AccountBalance{}
+	for _, e := range entries {
+		for _, l := range e.Lines {
+			b, ok := sums[l.Account]
+			if !ok {
+				b = &settlemententity.AccountBalance{Account: l.Account}
+				sums[l.Account] = b
+			}
+			b.DebitCents += l.DebitCents
+			b.CreditCents += l.CreditCents
+		}
+	}
+	res := map[string]int64{}
+	for acc, b := range sums {
+		res[acc] = b.BalanceCents()
+	}
+	return res
+}
+
+func TestLedgerService_SettlementAndRefund(t *testing.T) {
+	svc := NewLedgerService()
+	entries, err := svc.PostSettlement(&PostSettlementCmd{BookingID: "b1", DriverID: "d1", PassengerID: "p1", AmountCents: 3000, PlatformRevenueCents: 500, Currency: "CNY"})
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+
+	got := balances(entries...)
+	assert.Equal(t, int64(0), got[settlemententity.PassengerReceivableAccount("p1")])
+	assert.Equal(t, int64(2500), got[settlemententity.DriverPayableAccount("d1")])
+	assert.Equal(t, int64(500), got[settlemententity.AccountPlatformRevenue])
+	assert.Equal(t, int64(3000), got[settlemententity.AccountPaymentClearing])
+
+	refund, err := svc.PostRefund(&PostRefundCmd{BookingID: "b1", DriverID: "d1", RefundCents: 3000, PlatformCents: 500, Currency: "CNY"})
+	require.NoError(t, err)
+	got = balances(append(entries, refund)...)
+	for acc, bal := range got {
+		assert.Equal(t, int64(0), bal, acc)
+	}
+}
+
+func TestLedgerService_TaxPostedToTaxPayable(t *testing.T) {
+	svc := NewLedgerService()
+	entries, err := svc.PostSettlement(&PostSettlementCmd{BookingID: "b1", DriverID: "d1", PassengerID: "p1", AmountCents: 3180, PlatformRevenueCents: 500, TaxCents: 180, Currency: "CNY"})
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+	assert.Equal(t, settlemententity.JournalTax, entries[3].Kind)
+
+	got := balances(entries...)
+	assert.Equal(t, int64(2500), got[settlemententity.DriverPayableAccount("d1")])
+	assert.Equal(t, int64(500), got[settlemententity.AccountPlatformRevenue], "tax is not platform revenue")
+	assert.Equal(t, int64(180), got[settlemententity.AccountTaxPayable])
+
+	refund, err := svc.PostRefund(&PostRefundCmd{BookingID: "b1", DriverID: "d1", RefundCents: 3180, PlatformCents: 500, TaxCents: 180, Currency: "CNY"})
+	require.NoError(t, err)
+	got = balances(append(entries, refund)...)
+	for acc, bal := range got {
+		assert.Equal(t, int64(0), bal, acc)
+	}
+}
+
+func TestLedgerService_PostDepositCapture(t *testing.T) {
+	svc := NewLedgerService()
+	e, err := svc.PostDepositCapture(&PostDepositCaptureCmd{BookingID: "b1", AmountCents: 2000, Currency: "CNY"})
+	require.NoError(t, err)
+	assert.Equal(t, settlemententity.JournalDeposit, e.Kind)
+	assert.Equal(t, "CNY", e.Currency)
+	got := balances(e)
+	assert.Equal(t, int64(2000), got[settlemententity.AccountPaymentClearing])
+	assert.Equal(t, int64(2000), got[settlemententity.AccountPlatformRevenue])
+
+	_, err = svc.PostDepositCapture(&PostDepositCaptureCmd{BookingID: "b1", AmountCents: 0, Currency: "CNY"})
+	assert.Error(t, err)
+}
+
+func TestLedgerService_Validation(t *testing.T) {
+	svc := NewLedgerService()
+	_, err := svc.PostSettlement(&PostSettlementCmd{BookingID: "b1", DriverID: "d1", PassengerID: "p1", AmountCents: 100, PlatformRevenueCents: 200})
+	assert.Error(t, err)
+	entries, err := svc.PostSettlement(&PostSettlementCmd{BookingID: "b1", DriverID: "d1", PassengerID: "p1", AmountCents: 100, Currency: "CNY"})
+	require.NoError(t, err)
+	assert.Len(t, entries, 2, "zero fee entry is skipped")
+	_, err = svc.PostRefund(&PostRefundCmd{BookingID: "b1", DriverID: "d1", RefundCents: 100, PlatformCents: 200})
+	assert.Error(t, err)
+	_, err = svc.PostSettlement(&PostSettlementCmd{BookingID: "b1", DriverID: "d1", PassengerID: "p1", AmountCents: 100, PlatformRevenueCents: 60, TaxCents: 60, Currency: "CNY"})
+	assert.Error(t, err)
+}
@@ -2,17 +2,36 @@ package service
 
 import (
 	"errors"
+
+	"github.com/gavin/airport-pickup/internal/domain/money"
 	settlemententity "github.com/gavin/airport-pickup/internal/domain/settlement/entity"
 )
 
+// 支付网关返回的业务错误；其余错误视为临时故障，可重试。
+var (
+	ErrPaymentDeclined       = errors.New("payment declined")
+	ErrAuthorizationNotFound = errors.New("payment authorization not found")
+)
+
 // PaymentService defines interaction with external wallet/payment gateway.
+// 流程：匹配成功时 Authorize 冻结预估车费，行程完成时 Capture 实际金额，取消时 Void 释放冻结。
+// bookingID 作为幂等键：同一订单重复预授权、扣款或撤销只生效一次，saga 重试与恢复依赖这一点。
 type PaymentService interface {
-	Charge(bookingID string, amountCents int64) error
+	// Authorize 冻结乘客支付方式中的金额；paymentMethod 为支付方式保险库令牌，为空时使用乘客钱包。
+	// 余额不足或卡被拒返回 ErrPaymentDeclined。
+	Authorize(bookingID, passengerID, paymentMethod string, amountCents int64) error
+	// Capture 按实际金额扣款（不超过预授权金额），剩余冻结部分释放；无预授权返回 ErrAuthorizationNotFound。
+	Capture(bookingID string, amountCents int64) error
+	// Void 撤销未扣款的预授权。
+	Void(bookingID string) error
+	// Refund 退还订单已扣款项；refundID 为本次退款的幂等键，累计退款不超过已扣款金额。
+	Refund(bookingID, refundID string, amountCents int64) error
 }
 
 type CreatePaymentTransactionCmd struct {
 	BookingID   string
 	AmountCents int64
+	Currency    string
 	Status      string
 }
 
@@ -32,10 +51,75 @@ func (s *PaymentTransactionService) CreatePaymentTransaction(cmd *CreatePaymentT
 	if cmd.Status == "" {
 		return nil, errors.New("status required")
 	}
+	if !money.ValidCurrency(cmd.Currency) {
+		return nil, errors.New("invalid currency")
+	}
 	return &settlemententity.PaymentTransaction{
 		ID:          "",
 		BookingID:   cmd.BookingID,
+		Kind:        settlemententity.PaymentKindPayment,
 		AmountCents: cmd.AmountCents,
+		Currency:    cmd.Currency,
 		Status:      cmd.Status,
 	}, nil
 }
+
+type CreateRefundTransactionCmd struct {
+	BookingID   string
+	AmountCents int64
+	Currency    string
+	ReasonCode  string
+}
+
+// CreateRefundTransaction 创建单次退款流水。
+func (s *PaymentTransactionService) CreateRefundTransaction(cmd *CreateRefundTransactionCmd) (*settlemententity.PaymentTransaction, error) {
+	if cmd.BookingID == "" {
+		return nil, errors.New("booking_id required")
+	}
+	if cmd.AmountCents <= 0 {
+		return nil, errors.New("amount_cents must be > 0")
+	}
+	if cmd.ReasonCode == "" {
+		return nil, errors.New("reason_code required")
+	}
+	if !money.ValidCurrency(cmd.Currency) {
+		return nil, errors.New("invalid currency")
+	}
+	return &settlemententity.PaymentTransaction{
+		ID:            "",
+		BookingID:     cmd.BookingID,
+		Kind:          settlemententity.PaymentKindRefund,
+		AmountCents:   cmd.AmountCents,
+		RefundedCents: cmd.AmountCents,
+		Currency:      cmd.Currency,
+		Status:        settlemententity.PaymentRefunded,
+		ReasonCode:    cmd.ReasonCode,
+	}, nil
+}
+
+type CreateDepositTransactionCmd struct {
+	HoldKey     string // 押金预授权的幂等键
+	AmountCents int64
+	Currency    string
+}
+
+// CreateDepositTransaction 创建已预授权的押金流水，随后标记为扣收或撤销。
+func (s *PaymentTransactionService) CreateDepositTransaction(cmd *CreateDepositTransactionCmd) (*settlemententity.PaymentTransaction, error) {
+	if cmd.HoldKey == "" {
+		return nil, errors.New("hold_key required")
+	}
+	if cmd.AmountCents <= 0 {
+		return nil, errors.New("amount_cents must be > 0")
+	}
+	if !money.ValidCurrency(cmd.Currency) {
+		return nil, errors.New("invalid currency")
+	}
+	return &settlemententity.PaymentTransaction{
+		ID:          "",
+		BookingID:   cmd.HoldKey,
+		Kind:        settlemententity.PaymentKindDeposit,
+		AmountCents: cmd.AmountCents,
+		Currency:    cmd.Currency,
+		Status:      settlemententity.PaymentAuthorized,
+	}, nil
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/gavin/airport-pickup/internal/domain/money"
+	settlemententity "github.com/gavin/airport-pickup/internal/domain/settlement/entity"
+)
+
+// ErrPayoutRejected 打款渠道明确拒绝（如收款账户无效），打款单失败并释放结算记录；
+// 其他错误视为结果未知，打款单保持 pending，下一轮以相同 payoutID 重试。
+var ErrPayoutRejected = errors.New("payout rejected")
+
+// PayoutProvider defines interaction with the bank/wallet transfer channel used to pay drivers.
+// payoutID 作为幂等键：同一打款重复提交只转账一次，返回渠道流水号。
+type PayoutProvider interface {
+	Pay(payoutID, driverID string, amountCents int64, currency string) (reference string, err error)
+}
+
+// PayoutService 汇总司机未打款的结算记录生成打款单。
+type PayoutService struct{}
+
+func NewPayoutService() *PayoutService {
+	return &PayoutService{}
+}
+
+type BuildPayoutCmd struct {
+	DriverID string
+	Records  []*settlemententity.SettlementRecord
+	FeeCents int64 // 每次打款收取的手续费
+}
+
+// BuildPayout 生成 pending 状态的打款单；每条结算记录一行，净额 = 车费 - 平台抽成，退款冲减记录为负数。
+// 结算记录须为同一币种，不同币种分别打款。
+// 净额合计可能不大于 0（如退款多于收入），由调用方决定是否顺延。
+func (s *PayoutService) BuildPayout(cmd *BuildPayoutCmd) (*settlemententity.Payout, error) {
+	if cmd.DriverID == "" {
+		return nil, errors.New("driver_id required")
+	}
+	if len(cmd.Records) == 0 {
+		return nil, errors.New("records required")
+	}
+	if cmd.FeeCents < 0 {
+		return nil, errors.New("fee_cents must be >= 0")
+	}
+	currency := cmd.Records[0].Currency
+	if !money.ValidCurrency(currency) {
+		return nil, errors.New("invalid currency")
+	}
+	p := &settlemententity.Payout{DriverID: cmd.DriverID, Status: settlemententity.PayoutPending, FeeCents: cmd.FeeCents, Currency: currency}
+	for _, r := range cmd.Records {
+		if r.DriverID != cmd.DriverID {
+			return nil, errors.New("settlement record belongs to another driver")
+		}
+		if r.PayoutID != "" {
+			return nil, errors.New("settlement record already paid out")
+		}
+		if r.Currency != currency {
+			return nil, money.ErrCurrencyMismatch
+		}
+		// 代收税费不属于司机，也不是平台抽成，不计入打款单
+		line := settlemententity.PayoutLine{
+			SettlementRecordID: r.ID,
+			BookingID:          r.BookingID,
+			AmountCents:        r.AmountCents - r.TaxCents,
+			CommissionCents:    r.PlatformRevenueCents,
+			NetCents:           r.AmountCents - r.TaxCents - r.PlatformRevenueCents,
+		}
+		p.Lines = append(p.Lines, line)
+		p.GrossCents += line.AmountCents
+		p.CommissionCents += line.CommissionCents
+	}
+	p.NetCents = p.GrossCents - p.CommissionCents - p.FeeCents
+	return p, nil
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/gavin/airport-pickup/internal/domain/money"
+	settlemententity "github.com/gavin/airport-pickup/internal/domain/settlement/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPayoutService_BuildPayout(t *testing.T) {
+	records := []*settlemententity.SettlementRecord{
+		{ID: "sr1", BookingID: "b1", DriverID: "d1", AmountCents: 3000, PlatformRevenueCents: 500, Currency: "CNY"},
+		{ID: "sr2", BookingID: "b2", DriverID: "d1", AmountCents: 2000, PlatformRevenueCents: 300, Currency: "CNY"},
+		// 退款冲减
+		{ID: "sr3", BookingID: "b1", DriverID: "d1", AmountCents: -1000, PlatformRevenueCents: -166, Currency: "CNY"},
+	}
+	p, err := NewPayoutService().BuildPayout(&BuildPayoutCmd{DriverID: "d1", Records: records, FeeCents: 50})
+	require.NoError(t, err)
+	assert.Equal(t, settlemententity.PayoutPending, p.Status)
+	assert.Len(t, p.Lines, 3)
+	assert.Equal(t, int64(4000), p.GrossCents)
+	assert.Equal(t, int64(634), p.CommissionCents)
+	assert.Equal(t, int64(4000-634-50), p.NetCents)
+	assert.Equal(t, int64(-834), p.Lines[2].NetCents)
+	assert.Equal(t, "CNY", p.Currency)
+}
+
+func TestPayoutService_BuildPayout_ExcludesTax(t *testing.T) {
+	records := []*settlemententity.SettlementRecord{
+		{ID: "sr1", BookingID: "b1", DriverID: "d1", AmountCents: 3180, PlatformRevenueCents: 500, TaxCents: 180, Currency: "CNY"},
+	}
+	p, err := NewPayoutService().BuildPayout(&BuildPayoutCmd{DriverID: "d1", Records: records})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3000), p.GrossCents)
+	assert.Equal(t, int64(500), p.CommissionCents)
+	assert.Equal(t, int64(2500), p.NetCents)
+}
+
+func TestPayoutService_BuildPayout_Validation(t *testing.T) {
+	svc := NewPayoutService()
+	_, err := svc.BuildPayout(&BuildPayoutCmd{DriverID: "d1"})
+	assert.Error(t, err)
+	_, err = svc.BuildPayout(&BuildPayoutCmd{DriverID: "d1", Records: []*settlemententity.SettlementRecord{{ID: "sr1", DriverID: "d2"}}})
+	assert.Error(t, err)
+	_, err = svc.BuildPayout(&BuildPayoutCmd{DriverID: "d1", Records: []*settlemententity.SettlementRecord{{ID: "sr1", DriverID: "d1", PayoutID: "po0", Currency: "CNY"}}})
+	assert.Error(t, err)
+	_, err = svc.BuildPayout(&BuildPayoutCmd{DriverID: "d1", Records: []*settlemententity.SettlementRecord{
+		{ID: "sr1", DriverID: "d1", Currency: "CNY"}, {ID: "sr2", DriverID: "d1", Currency: "USD"},
+	}})
+	assert.ErrorIs(t, err, money.ErrCurrencyMismatch)
+}
+
+func TestLedgerService_PostPayout(t *testing.T) {
+	p := &settlemententity.Payout{ID: "po1", DriverID: "d1", NetCents: 900, FeeCents: 50, Currency: "CNY"}
+	entries, err := NewLedgerService().PostPayout(p)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	got := balances(entries...)
+	// 应付司机被借记（减少）950
+	assert.Equal(t, int64(-950), got[settlemententity.DriverPayableAccount("d1")])
+	assert.Equal(t, int64(50), got[settlemententity.AccountPlatformRevenue])
+	assert.Equal(t, int64(-900), got[settlemententity.AccountPaymentClearing])
+
+	_, err = NewLedgerService().PostPayout(&settlemententity.Payout{ID: "po2", DriverID: "d1"})
+	assert.Error(t, err)
+}
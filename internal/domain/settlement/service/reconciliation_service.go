@@ -0,0 +1,192 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	settlemententity "github.com/gavin/airport-pickup/internal/domain/settlement/entity"
+)
+
+// ReconciliationService 把钱包服务商对账单与本地支付流水按订单与金额逐笔核对，生成对账报告。
+type ReconciliationService struct{}
+
+func NewReconciliationService() *ReconciliationService {
+	return &ReconciliationService{}
+}
+
+type ReconcileCmd struct {
+	Source      string
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+	Lines       []settlemententity.StatementLine
+	// 账期内的流水：扣款时间在账期内的支付流水、创建时间在账期内的退款流水
+	Transactions []*settlemententity.PaymentTransaction
+	// 对账单中出现的订单在账期外的流水；与之金额一致的行视为跨账期的时间差，不算差异
+	Related []*settlemententity.PaymentTransaction
+}
+
+// Reconcile 核对规则：
+//   - 对账单中流水号重复的行为 duplicate；同一订单出现多笔扣款时，多出的行也为 duplicate。
+//   - 扣款行与订单支付流水的扣款金额比较，退款行优先匹配同一订单金额相同的退款流水；
+//     金额或币种不一致为 amount_mismatch。
+//   - 对账单中有、本地没有对应流水的行为 missing_in_ledger；账期内本地有、对账单中没有的流水为 missing_in_statement。
+func (s *ReconciliationService) Reconcile(cmd *ReconcileCmd) (*settlemententity.ReconciliationReport, error) {
+	if !cmd.PeriodStart.Before(cmd.PeriodEnd) {
+		return nil, errors.New("period_start must be before period_end")
+	}
+	r := &settlemententity.ReconciliationReport{
+		Source:         cmd.Source,
+		PeriodStart:    cmd.PeriodStart,
+		PeriodEnd:      cmd.PeriodEnd,
+		StatementLines: len(cmd.Lines),
+		Transactions:   len(cmd.Transactions),
+	}
+	for _, l := range cmd.Lines {
+		if l.BookingID == "" || l.AmountCents < 0 || (l.Type != settlemententity.StatementPayment && l.Type != settlemententity.StatementRefund) {
+			return nil, errors.New("invalid statement line")
+		}
+		r.StatementCents += signedCents(l.Type, l.AmountCents)
+	}
+	for _, t := range cmd.Transactions {
+		r.TransactionCents += signedCents(statementType(t), expectedCents(t))
+	}
+
+	m := newMatcher(cmd.Transactions, cmd.Related)
+	seenRefs := make(map[string]bool)
+	var pendingRefunds []settlemententity.StatementLine
+	for _, l := range cmd.Lines {
+		if l.Reference != "" && seenRefs[l.Reference] {
+			r.Exceptions = append(r.Exceptions, lineException(settlemententity.ExceptionDuplicate, l, nil))
+			continue
+		}
+		seenRefs[l.Reference] = true
+		if l.Type == settlemententity.StatementRefund {
+			if t := m.take(l, true); t != nil {
+				r.MatchedLines++
+			} else {
+				pendingRefunds = append(pendingRefunds, l)
+			}
+			continue
+		}
+		switch t, matched := m.takePayment(l); {
+		case matched:
+			r.MatchedLines++
+		case t != nil && m.seen[t.ID]:
+			r.Exceptions = append(r.Exceptions, lineException(settlemententity.ExceptionDuplicate, l, t))
+		case t != nil:
+			m.seen[t.ID] = true
+			r.Exceptions = append(r.Exceptions, lineException(settlemententity.ExceptionAmountMismatch, l, t))
+		default:
+			r.Exceptions = append(r.Exceptions, lineException(settlemententity.ExceptionMissingInLedger, l, nil))
+		}
+	}
+	// 金额相同的退款都匹配完后，再把剩余退款行与同一订单剩余的退款流水配对
+	for _, l := range pendingRefunds {
+		if t := m.take(l, false); t != nil {
+			r.Exceptions = append(r.Exceptions, lineException(settlemententity.ExceptionAmountMismatch, l, t))
+		} else {
+			r.Exceptions = append(r.Exceptions, lineException(settlemententity.ExceptionMissingInLedger, l, nil))
+		}
+	}
+	for _, t := range cmd.Transactions {
+		if !m.seen[t.ID] {
+			r.Exceptions = append(r.Exceptions, settlemententity.ReconciliationException{
+				Kind: settlemententity.ExceptionMissingInStatement, BookingID: t.BookingID, Type: statementType(t),
+				TransactionID: t.ID, ExpectedCents: expectedCents(t), Currency: t.Currency, Status: settlemententity.ExceptionOpen,
+			})
+		}
+	}
+	r.RefreshStatus()
+	return r, nil
+}
+
+// matcher 记录已被对账单行占用的流水。
+type matcher struct {
+	expected []*settlemententity.PaymentTransaction
+	related  []*settlemententity.PaymentTransaction
+	seen     map[string]bool
+}
+
+func newMatcher(expected, related []*settlemententity.PaymentTransaction) *matcher {
+	return &matcher{expected: expected, related: related, seen: make(map[string]bool)}
+}
+
+// takePayment 返回订单的支付或押金流水及是否与该行完全一致；完全一致时占用该流水。
+func (m *matcher) takePayment(l settlemententity.StatementLine) (*settlemententity.PaymentTransaction, bool) {
+	var found *settlemententity.PaymentTransaction
+	for _, list := range [][]*settlemententity.PaymentTransaction{m.expected, m.related} {
+		for _, t := range list {
+			if t.BookingID != l.BookingID || t.Kind == settlemententity.PaymentKindRefund {
+				continue
+			}
+			if !m.seen[t.ID] && sameAmount(l, t) {
+				m.seen[t.ID] = true
+				return t, true
+			}
+			if found == nil {
+				found = t
+			}
+		}
+	}
+	return found, false
+}
+
+// take 占用并返回订单中第一条未被占用的退款流水；exact 为 true 时要求金额与币种一致。
+// 金额不一致的配对只在账期内的流水中查找。
+func (m *matcher) take(l settlemententity.StatementLine, exact bool) *settlemententity.PaymentTransaction {
+	lists := [][]*settlemententity.PaymentTransaction{m.expected}
+	if exact {
+		lists = append(lists, m.related)
+	}
+	for _, list := range lists {
+		for _, t := range list {
+			if t.BookingID != l.BookingID || t.Kind != settlemententity.PaymentKindRefund || m.seen[t.ID] {
+				continue
+			}
+			if exact && !sameAmount(l, t) {
+				continue
+			}
+			m.seen[t.ID] = true
+			return t
+		}
+	}
+	return nil
+}
+
+func sameAmount(l settlemententity.StatementLine, t *settlemententity.PaymentTransaction) bool {
+	return l.AmountCents == expectedCents(t) && l.Currency == t.Currency
+}
+
+// expectedCents 支付与押金流水为实际扣款金额，退款流水为退款金额。
+func expectedCents(t *settlemententity.PaymentTransaction) int64 {
+	if t.Kind == settlemententity.PaymentKindRefund {
+		return t.AmountCents
+	}
+	return t.CapturedCents
+}
+
+func statementType(t *settlemententity.PaymentTransaction) string {
+	if t.Kind == settlemententity.PaymentKindRefund {
+		return settlemententity.StatementRefund
+	}
+	return settlemententity.StatementPayment
+}
+
+func signedCents(typ string, cents int64) int64 {
+	if typ == settlemententity.StatementRefund {
+		return -cents
+	}
+	return cents
+}
+
+func lineException(kind string, l settlemententity.StatementLine, t *settlemententity.PaymentTransaction) settlemententity.ReconciliationException {
+	e := settlemententity.ReconciliationException{
+		Kind: kind, BookingID: l.BookingID, Type: l.Type, StatementRef: l.Reference, StatementLineNo: l.LineNo,
+		StatementCents: l.AmountCents, Currency: l.Currency, Status: settlemententity.ExceptionOpen,
+	}
+	if t != nil {
+		e.TransactionID = t.ID
+		e.ExpectedCents = expectedCents(t)
+	}
+	return e
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	settlemententity "github.com/gavin/airport-pickup/internal/domain/settlement/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	reconStart = time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	reconEnd   = time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+)
+
+func reconPayment(id, bookingID string, cents int64) *settlemententity.PaymentTransaction {
+	return &settlemententity.PaymentTransaction{ID: id, BookingID: bookingID, Kind: settlemententity.PaymentKindPayment,
+		AmountCents: cents + 500, CapturedCents: cents, Currency: "USD", Status: settlemententity.PaymentCaptured}
+}
+
+func reconRefund(id, bookingID string, cents int64) *settlemententity.PaymentTransaction {
+	return &settlemententity.PaymentTransaction{ID: id, BookingID: bookingID, Kind: settlemententity.PaymentKindRefund,
+		AmountCents: cents, Currency: "USD", Status: settlemententity.PaymentRefunded}
+}
+
+func reconLine(n int, ref, bookingID, typ string, cents int64) settlemententity.StatementLine {
+	return settlemententity.StatementLine{LineNo: n, Reference: ref, BookingID: bookingID, Type: typ, AmountCents: cents, Currency: "USD", SettledAt: reconStart}
+}
+
+func exceptionKinds(r *settlemententity.ReconciliationReport) map[string][]string {
+	res := make(map[string][]string)
+	for _, e := range r.Exceptions {
+		res[e.Kind] = append(res[e.Kind], e.BookingID)
+	}
+	return res
+}
+
+func TestReconcile_AllMatched(t *testing.T) {
+	r, err := NewReconciliationService().Reconcile(&ReconcileCmd{
+		Source: "wallet-20260301.csv", PeriodStart: reconStart, PeriodEnd: reconEnd,
+		Lines: []settlemententity.StatementLine{
+			reconLine(1, "w1", "b1", settlemententity.StatementPayment, 2000),
+			reconLine(2, "w2", "b1", settlemententity.StatementRefund, 300),
+			reconLine(3, "w3", "b1", settlemententity.StatementRefund, 200),
+		},
+		Transactions: []*settlemententity.PaymentTransaction{
+			reconPayment("p1", "b1", 2000), reconRefund("r1", "b1", 200), reconRefund("r2", "b1", 300),
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, settlemententity.ReconciliationReconciled, r.Status)
+	assert.Equal(t, 3, r.MatchedLines)
+	assert.Empty(t, r.Exceptions)
+	assert.Equal(t, int64(1500), r.StatementCents)
+	assert.Equal(t, int64(1500), r.TransactionCents)
+}
+
+func TestReconcile_DepositCaptureMatched(t *testing.T) {
+	deposit := &settlemententity.PaymentTransaction{ID: "d1", BookingID: "deposit-q1", Kind: settlemententity.PaymentKindDeposit,
+		AmountCents: 2000, CapturedCents: 2000, Currency: "USD", Status: settlemententity.PaymentCaptured}
+	r, err := NewReconciliationService().Reconcile(&ReconcileCmd{
+		Source: "wallet-20260301.csv", PeriodStart: reconStart, PeriodEnd: reconEnd,
+		Lines:        []settlemententity.StatementLine{reconLine(1, "w1", "deposit-q1", settlemententity.StatementPayment, 2000)},
+		Transactions: []*settlemententity.PaymentTransaction{deposit},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, settlemententity.ReconciliationReconciled, r.Status)
+	assert.Equal(t, 1, r.MatchedLines)
+	assert.Empty(t, r.Exceptions)
+}
+
+func TestReconcile_Exceptions(t *testing.T) {
+	r, err := NewReconciliationService().Reconcile(&ReconcileCmd{
+		PeriodStart: reconStart, PeriodEnd: reconEnd,
+		Lines: []settlemententity.StatementLine{
+			reconLine(1, "w1", "b1", settlemententity.StatementPayment, 2000),
+			reconLine(2, "w1", "b1", settlemententity.StatementPayment, 2000), // 流水号重复
+			reconLine(3, "w3", "b1", settlemententity.StatementPayment, 2000), // 同一订单第二笔扣款
+			reconLine(4, "w4", "b2", settlemententity.StatementPayment, 1800), // 本地扣款 1500
+			reconLine(5, "w5", "b2", settlemententity.StatementRefund, 400),   // 本地退款 300
+			reconLine(6, "w6", "b9", settlemententity.StatementPayment, 900),  // 本地没有
+		},
+		Transactions: []*settlemententity.PaymentTransaction{
+			reconPayment("p1", "b1", 2000), reconPayment("p2", "b2", 1500), reconRefund("r2", "b2", 300),
+			reconPayment("p3", "b3", 700), // 对账单中没有
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, settlemententity.ReconciliationOpen, r.Status)
+	assert.Equal(t, 1, r.MatchedLines)
+	assert.Equal(t, map[string][]string{
+		settlemententity.ExceptionDuplicate:          {"b1", "b1"},
+		settlemententity.ExceptionAmountMismatch:     {"b2", "b2"},
+		settlemententity.ExceptionMissingInLedger:    {"b9"},
+		settlemententity.ExceptionMissingInStatement: {"b3"},
+	}, exceptionKinds(r))
+
+	for _, e := range r.Exceptions {
+		assert.Equal(t, settlemententity.ExceptionOpen, e.Status)
+		switch {
+		case e.Kind == settlemententity.ExceptionAmountMismatch && e.Type == settlemententity.StatementPayment:
+			assert.Equal(t, "p2", e.TransactionID)
+			assert.Equal(t, int64(1500), e.ExpectedCents)
+			assert.Equal(t, int64(1800), e.StatementCents)
+		case e.Kind == settlemententity.ExceptionAmountMismatch:
+			assert.Equal(t, "r2", e.TransactionID)
+			assert.Equal(t, int64(300), e.ExpectedCents)
+			assert.Equal(t, 5, e.StatementLineNo)
+		case e.Kind == settlemententity.ExceptionMissingInStatement:
+			assert.Equal(t, "p3", e.TransactionID)
+			assert.Equal(t, int64(700), e.ExpectedCents)
+		}
+	}
+}
+
+func TestReconcile_CurrencyMismatch(t *testing.T) {
+	l := reconLine(1, "w1", "b1", settlemententity.StatementPayment, 2000)
+	l.Currency = "CNY"
+	r, err := NewReconciliationService().Reconcile(&ReconcileCmd{
+		PeriodStart: reconStart, PeriodEnd: reconEnd, Lines: []settlemententity.StatementLine{l},
+		Transactions: []*settlemententity.PaymentTransaction{reconPayment("p1", "b1", 2000)},
+	})
+	require.NoError(t, err)
+	require.Len(t, r.Exceptions, 1)
+	assert.Equal(t, settlemententity.ExceptionAmountMismatch, r.Exceptions[0].Kind)
+}
+
+func TestReconcile_RelatedOutsidePeriod(t *testing.T) {
+	// 账期前一天扣款、账期内才结算的订单不算差异；金额不一致仍报 amount_mismatch
+	r, err := NewReconciliationService().Reconcile(&ReconcileCmd{
+		PeriodStart: reconStart, PeriodEnd: reconEnd,
+		Lines: []settlemententity.StatementLine{
+			reconLine(1, "w1", "b1", settlemententity.StatementPayment, 2000),
+			reconLine(2, "w2", "b1", settlemententity.StatementRefund, 100),
+			reconLine(3, "w3", "b2", settlemententity.StatementPayment, 999),
+		},
+		Related: []*settlemententity.PaymentTransaction{
+			reconPayment("p1", "b1", 2000), reconRefund("r1", "b1", 100), reconPayment("p2", "b2", 1000),
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, r.MatchedLines)
+	assert.Equal(t, 0, r.Transactions)
+	assert.Equal(t, map[string][]string{settlemententity.ExceptionAmountMismatch: {"b2"}}, exceptionKinds(r))
+}
+
+func TestReconcile_InvalidInput(t *testing.T) {
+	_, err := NewReconciliationService().Reconcile(&ReconcileCmd{PeriodStart: reconEnd, PeriodEnd: reconStart})
+	assert.Error(t, err)
+
+	_, err = NewReconciliationService().Reconcile(&ReconcileCmd{
+		PeriodStart: reconStart, PeriodEnd: reconEnd,
+		Lines: []settlemententity.StatementLine{reconLine(1, "w1", "", settlemententity.StatementPayment, 100)},
+	})
+	assert.Error(t, err)
+}
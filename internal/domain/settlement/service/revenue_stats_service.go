@@ -0,0 +1,106 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	settlemententity "github.com/gavin/airport-pickup/internal/domain/settlement/entity"
+)
+
+// RevenueStatsService 把结算记录转换为按日统计的增量，并把日统计汇总为报表所需的时间粒度与维度。
+type RevenueStatsService struct{}
+
+func NewRevenueStatsService() *RevenueStatsService {
+	return &RevenueStatsService{}
+}
+
+// BuildFact 生成结算记录的统计增量；机场与车型为空时计为 unknown。
+func (s *RevenueStatsService) BuildFact(r *settlemententity.SettlementRecord, airportCode, vehicleType string) (*settlemententity.RevenueFact, error) {
+	if r.ID == "" || r.BookingID == "" {
+		return nil, errors.New("settlement record id and booking_id required")
+	}
+	f := &settlemententity.RevenueFact{
+		RecordID:     r.ID,
+		BookingID:    r.BookingID,
+		Day:          settlemententity.PeriodStart(r.CreatedAt, settlemententity.GranularityDay),
+		AirportCode:  strings.ToUpper(strings.TrimSpace(airportCode)),
+		VehicleType:  strings.TrimSpace(vehicleType),
+		Currency:     r.Currency,
+		GrossCents:   r.AmountCents,
+		RevenueCents: r.PlatformRevenueCents,
+	}
+	if f.AirportCode == "" {
+		f.AirportCode = settlemententity.UnknownDimension
+	}
+	if f.VehicleType == "" {
+		f.VehicleType = settlemententity.UnknownDimension
+	}
+	if r.AmountCents >= 0 {
+		f.Trips = 1
+	}
+	return f, nil
+}
+
+// Rollup 把日统计汇总到 granularity 指定的时间段，只保留 dimensions 中的维度，币种始终参与分组。
+// 结果按时间段、机场、车型、币种排序。
+func (s *RevenueStatsService) Rollup(daily []settlemententity.RevenueStat, granularity string, dimensions []string) ([]settlemententity.RevenueStat, error) {
+	switch granularity {
+	case settlemententity.GranularityDay, settlemententity.GranularityWeek, settlemententity.GranularityMonth:
+	default:
+		return nil, fmt.Errorf("invalid granularity %q", granularity)
+	}
+	var byAirport, byVehicle bool
+	for _, d := range dimensions {
+		switch d {
+		case settlemententity.DimensionAirport:
+			byAirport = true
+		case settlemententity.DimensionVehicleType:
+			byVehicle = true
+		default:
+			return nil, fmt.Errorf("invalid dimension %q", d)
+		}
+	}
+	type statKey struct {
+		period                         time.Time
+		airport, vehicleType, currency string
+	}
+	var keys []statKey
+	sums := make(map[statKey]*settlemententity.RevenueStat)
+	for _, d := range daily {
+		k := statKey{period: settlemententity.PeriodStart(d.Period, granularity), currency: d.Currency}
+		if byAirport {
+			k.airport = d.AirportCode
+		}
+		if byVehicle {
+			k.vehicleType = d.VehicleType
+		}
+		st, ok := sums[k]
+		if !ok {
+			st = &settlemententity.RevenueStat{Period: k.period, AirportCode: k.airport, VehicleType: k.vehicleType, Currency: k.currency}
+			sums[k] = st
+			keys = append(keys, k)
+		}
+		st.Add(d)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if !a.period.Equal(b.period) {
+			return a.period.Before(b.period)
+		}
+		if a.airport != b.airport {
+			return a.airport < b.airport
+		}
+		if a.vehicleType != b.vehicleType {
+			return a.vehicleType < b.vehicleType
+		}
+		return a.currency < b.currency
+	})
+	res := make([]settlemententity.RevenueStat, 0, len(keys))
+	for _, k := range keys {
+		res = append(res, *sums[k])
+	}
+	return res, nil
+}
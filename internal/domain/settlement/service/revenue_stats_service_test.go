@@ -0,0 +1,69 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	settlemententity "github.com/gavin/airport-pickup/internal/domain/settlement/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildFact(t *testing.T) {
+	svc := NewRevenueStatsService()
+	at := time.Date(2026, 3, 5, 18, 0, 0, 0, time.UTC)
+	f, err := svc.BuildFact(&settlemententity.SettlementRecord{ID: "sr1", BookingID: "b1", AmountCents: 2500, PlatformRevenueCents: 500, Currency: "USD", CreatedAt: at}, "sfo", "sedan")
+	require.NoError(t, err)
+	assert.Equal(t, &settlemententity.RevenueFact{
+		RecordID: "sr1", BookingID: "b1", Day: time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC), AirportCode: "SFO", VehicleType: "sedan",
+		Currency: "USD", Trips: 1, GrossCents: 2500, RevenueCents: 500,
+	}, f)
+
+	// 退款冲减不计单量，缺少维度时计为 unknown
+	f, err = svc.BuildFact(&settlemententity.SettlementRecord{ID: "sr2", BookingID: "b1", AmountCents: -500, PlatformRevenueCents: -100, Currency: "USD", CreatedAt: at}, "", "")
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), f.Trips)
+	assert.Equal(t, int64(-500), f.GrossCents)
+	assert.Equal(t, settlemententity.UnknownDimension, f.AirportCode)
+	assert.Equal(t, settlemententity.UnknownDimension, f.VehicleType)
+
+	_, err = svc.BuildFact(&settlemententity.SettlementRecord{BookingID: "b1"}, "SFO", "sedan")
+	assert.Error(t, err)
+}
+
+func TestRollup(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC) }
+	daily := []settlemententity.RevenueStat{
+		{Period: day(2), AirportCode: "SFO", VehicleType: "sedan", Currency: "USD", Trips: 2, GrossCents: 5000, RevenueCents: 1000},
+		{Period: day(3), AirportCode: "SFO", VehicleType: "suv", Currency: "USD", Trips: 1, GrossCents: 4000, RevenueCents: 600},
+		{Period: day(9), AirportCode: "JFK", VehicleType: "sedan", Currency: "USD", Trips: 1, GrossCents: 3000, RevenueCents: 900},
+		{Period: day(3), AirportCode: "PEK", VehicleType: "sedan", Currency: "CNY", Trips: 1, GrossCents: 8000, RevenueCents: 800},
+	}
+	svc := NewRevenueStatsService()
+
+	weekly, err := svc.Rollup(daily, settlemententity.GranularityWeek, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []settlemententity.RevenueStat{
+		{Period: day(2), Currency: "CNY", Trips: 1, GrossCents: 8000, RevenueCents: 800},
+		{Period: day(2), Currency: "USD", Trips: 3, GrossCents: 9000, RevenueCents: 1600},
+		{Period: day(9), Currency: "USD", Trips: 1, GrossCents: 3000, RevenueCents: 900},
+	}, weekly)
+
+	byAirport, err := svc.Rollup(daily, settlemententity.GranularityMonth, []string{settlemententity.DimensionAirport})
+	require.NoError(t, err)
+	require.Len(t, byAirport, 3)
+	assert.Equal(t, "JFK", byAirport[0].AirportCode)
+	assert.Equal(t, "SFO", byAirport[2].AirportCode)
+	assert.Equal(t, int64(9000), byAirport[2].GrossCents)
+	assert.Empty(t, byAirport[2].VehicleType)
+
+	byVehicle, err := svc.Rollup(daily, settlemententity.GranularityMonth, []string{settlemententity.DimensionVehicleType})
+	require.NoError(t, err)
+	require.Len(t, byVehicle, 3) // sedan/CNY, sedan/USD, suv/USD
+	assert.Equal(t, int64(3), byVehicle[1].Trips)
+
+	_, err = svc.Rollup(daily, "year", nil)
+	assert.Error(t, err)
+	_, err = svc.Rollup(daily, settlemententity.GranularityDay, []string{"driver"})
+	assert.Error(t, err)
+}
@@ -2,6 +2,8 @@ package service
 
 import (
 	"errors"
+
+	"github.com/gavin/airport-pickup/internal/domain/money"
 	settlemententity "github.com/gavin/airport-pickup/internal/domain/settlement/entity"
 )
 
@@ -9,6 +11,8 @@ import (
 type SettlementService interface {
 	CreateSettlementRecord(cmd *CreateSettlementRecordCmd) (*settlemententity.SettlementRecord, error)
 	CreateRevenueRecord(cmd *CreateRevenueRecordCmd) (*settlemententity.RevenueRecord, error)
+	// CreateRefundAdjustment 按原结算的平台抽成与税费比例拆分退款，生成负向的结算记录与收入记录。
+	CreateRefundAdjustment(cmd *CreateRefundAdjustmentCmd) (*settlemententity.SettlementRecord, *settlemententity.RevenueRecord, error)
 }
 
 type settlementService struct{}
@@ -21,11 +25,29 @@ type CreateSettlementRecordCmd struct {
 	PassengerID          string
 	AmountCents          int64
 	PlatformRevenueCents int64
+	TaxCents             int64 // 代收税费，不计入平台收入
+	Currency             string
+	FareItems            []settlemententity.FareLineItem // 可为空；非空时合计须与金额一致
 }
 
+// CreateRevenueRecordCmd DeltaCents 为负表示冲减收入（如退款）。
 type CreateRevenueRecordCmd struct {
 	BookingID  string
 	DeltaCents int64
+	Currency   string
+}
+
+// CreateRefundAdjustmentCmd 原结算金额与本次之前的累计退款用于计算本次退款中平台承担与冲减税费的部分。
+type CreateRefundAdjustmentCmd struct {
+	BookingID            string
+	DriverID             string
+	PassengerID          string
+	AmountCents          int64 // 原结算金额
+	PlatformRevenueCents int64 // 原平台收入
+	TaxCents             int64 // 原代收税费
+	RefundedBeforeCents  int64
+	RefundCents          int64
+	Currency             string
 }
 
 func (s *settlementService) CreateSettlementRecord(cmd *CreateSettlementRecordCmd) (*settlemententity.SettlementRecord, error) {
@@ -35,8 +57,20 @@ func (s *settlementService) CreateSettlementRecord(cmd *CreateSettlementRecordCm
 	if cmd.AmountCents < 0 {
 		return nil, errors.New("amount_cents must be >= 0")
 	}
-	if cmd.PlatformRevenueCents < 0 {
-		return nil, errors.New("platform_revenue_cents must be >= 0")
+	if cmd.PlatformRevenueCents < 0 || cmd.TaxCents < 0 {
+		return nil, errors.New("platform_revenue_cents and tax_cents must be >= 0")
+	}
+	if cmd.PlatformRevenueCents+cmd.TaxCents > cmd.AmountCents {
+		return nil, errors.New("platform_revenue_cents plus tax_cents must not exceed amount_cents")
+	}
+	if !money.ValidCurrency(cmd.Currency) {
+		return nil, errors.New("invalid currency")
+	}
+	if len(cmd.FareItems) > 0 {
+		fare := settlemententity.NewFareBreakdown(cmd.Currency, cmd.FareItems)
+		if fare.TotalCents != cmd.AmountCents || fare.PlatformCents != cmd.PlatformRevenueCents || fare.TaxCents != cmd.TaxCents {
+			return nil, errors.New("fare items do not add up to amount_cents, platform_revenue_cents and tax_cents")
+		}
 	}
 	return &settlemententity.SettlementRecord{
 		ID:                   "",
@@ -45,6 +79,9 @@ func (s *settlementService) CreateSettlementRecord(cmd *CreateSettlementRecordCm
 		PassengerID:          cmd.PassengerID,
 		AmountCents:          cmd.AmountCents,
 		PlatformRevenueCents: cmd.PlatformRevenueCents,
+		TaxCents:             cmd.TaxCents,
+		Currency:             cmd.Currency,
+		FareItems:            cmd.FareItems,
 	}, nil
 }
 
@@ -52,12 +89,58 @@ func (s *settlementService) CreateRevenueRecord(cmd *CreateRevenueRecordCmd) (*s
 	if cmd.BookingID == "" {
 		return nil, errors.New("booking_id required")
 	}
-	if cmd.DeltaCents < 0 {
-		return nil, errors.New("delta_cents must be >= 0")
+	if !money.ValidCurrency(cmd.Currency) {
+		return nil, errors.New("invalid currency")
 	}
 	return &settlemententity.RevenueRecord{
 		ID:         "",
 		BookingID:  cmd.BookingID,
 		DeltaCents: cmd.DeltaCents,
+		Currency:   cmd.Currency,
 	}, nil
 }
+
+func (s *settlementService) CreateRefundAdjustment(cmd *CreateRefundAdjustmentCmd) (*settlemententity.SettlementRecord, *settlemententity.RevenueRecord, error) {
+	if cmd.BookingID == "" || cmd.DriverID == "" || cmd.PassengerID == "" {
+		return nil, nil, errors.New("booking_id, driver_id, passenger_id required")
+	}
+	if cmd.RefundCents <= 0 {
+		return nil, nil, errors.New("refund_cents must be > 0")
+	}
+	if cmd.RefundedBeforeCents < 0 || cmd.RefundedBeforeCents+cmd.RefundCents > cmd.AmountCents {
+		return nil, nil, errors.New("total refunds must not exceed amount_cents")
+	}
+	if !money.ValidCurrency(cmd.Currency) {
+		return nil, nil, errors.New("invalid currency")
+	}
+	// 按累计退款计算平台承担部分与冲减的税费，多次部分退款的合计与一次全额退款一致
+	platformCents := platformShare(cmd.PlatformRevenueCents, cmd.AmountCents, cmd.RefundedBeforeCents+cmd.RefundCents) -
+		platformShare(cmd.PlatformRevenueCents, cmd.AmountCents, cmd.RefundedBeforeCents)
+	taxCents := platformShare(cmd.TaxCents, cmd.AmountCents, cmd.RefundedBeforeCents+cmd.RefundCents) -
+		platformShare(cmd.TaxCents, cmd.AmountCents, cmd.RefundedBeforeCents)
+	sr := &settlemententity.SettlementRecord{
+		ID:                   "",
+		BookingID:            cmd.BookingID,
+		DriverID:             cmd.DriverID,
+		PassengerID:          cmd.PassengerID,
+		AmountCents:          -cmd.RefundCents,
+		PlatformRevenueCents: -platformCents,
+		TaxCents:             -taxCents,
+		Currency:             cmd.Currency,
+	}
+	rr := &settlemententity.RevenueRecord{
+		ID:         "",
+		BookingID:  cmd.BookingID,
+		DeltaCents: -platformCents,
+		Currency:   cmd.Currency,
+	}
+	return sr, rr, nil
+}
+
+// platformShare 退款 refundedCents 时 partCents（平台收入或税费）按比例冲减的金额（向下取整）。
+func platformShare(partCents, amountCents, refundedCents int64) int64 {
+	if amountCents == 0 {
+		return 0
+	}
+	return partCents * refundedCents / amountCents
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"testing"
+
+	settlemententity "github.com/gavin/airport-pickup/internal/domain/settlement/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateRefundAdjustment_SplitsProportionally(t *testing.T) {
+	svc := NewSettlementService()
+	cmd := &CreateRefundAdjustmentCmd{BookingID: "b1", DriverID: "d1", PassengerID: "p1", AmountCents: 3000, PlatformRevenueCents: 500, Currency: "CNY"}
+
+	cmd.RefundCents = 1000
+	sr, rr, err := svc.CreateRefundAdjustment(cmd)
+	require.NoError(t, err)
+	assert.Equal(t, int64(-1000), sr.AmountCents)
+	assert.Equal(t, int64(-166), sr.PlatformRevenueCents)
+	assert.Equal(t, int64(-166), rr.DeltaCents)
+
+	// 剩余部分退款后，平台冲减合计等于原平台收入
+	cmd.RefundedBeforeCents, cmd.RefundCents = 1000, 2000
+	sr, rr, err = svc.CreateRefundAdjustment(cmd)
+	require.NoError(t, err)
+	assert.Equal(t, int64(-2000), sr.AmountCents)
+	assert.Equal(t, int64(-334), sr.PlatformRevenueCents)
+	assert.Equal(t, int64(-334), rr.DeltaCents)
+}
+
+func TestCreateRefundAdjustment_SplitsTax(t *testing.T) {
+	sr, rr, err := NewSettlementService().CreateRefundAdjustment(&CreateRefundAdjustmentCmd{
+		BookingID: "b1", DriverID: "d1", PassengerID: "p1", AmountCents: 3180, PlatformRevenueCents: 500, TaxCents: 180, RefundCents: 1590, Currency: "CNY",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(-250), sr.PlatformRevenueCents)
+	assert.Equal(t, int64(-90), sr.TaxCents)
+	assert.Equal(t, int64(-250), rr.DeltaCents, "revenue excludes tax")
+}
+
+func TestCreateRefundAdjustment_Validation(t *testing.T) {
+	svc := NewSettlementService()
+	_, _, err := svc.CreateRefundAdjustment(&CreateRefundAdjustmentCmd{BookingID: "b1", DriverID: "d1", PassengerID: "p1", AmountCents: 1000, RefundedBeforeCents: 600, RefundCents: 500})
+	assert.Error(t, err)
+	_, _, err = svc.CreateRefundAdjustment(&CreateRefundAdjustmentCmd{BookingID: "b1", DriverID: "d1", PassengerID: "p1", AmountCents: 1000, RefundCents: 0})
+	assert.Error(t, err)
+}
+
+func TestCreateRevenueRecord_AllowsNegativeDelta(t *testing.T) {
+	rr, err := NewSettlementService().CreateRevenueRecord(&CreateRevenueRecordCmd{BookingID: "b1", DeltaCents: -100, Currency: "CNY"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(-100), rr.DeltaCents)
+}
+
+func TestCreateSettlementRecord_FareItemsMustAddUp(t *testing.T) {
+	svc := NewSettlementService()
+	items := []settlemententity.FareLineItem{
+		{Kind: settlemententity.FareDistance, AmountCents: 2000, DriverCents: 1800, PlatformCents: 200},
+		{Kind: settlemententity.FareSurcharge, AmountCents: 500, PlatformCents: 500},
+	}
+	sr, err := svc.CreateSettlementRecord(&CreateSettlementRecordCmd{BookingID: "b1", DriverID: "d1", PassengerID: "p1", AmountCents: 2500, PlatformRevenueCents: 700, Currency: "CNY", FareItems: items})
+	require.NoError(t, err)
+	assert.Len(t, sr.FareItems, 2)
+
+	_, err = svc.CreateSettlementRecord(&CreateSettlementRecordCmd{BookingID: "b1", DriverID: "d1", PassengerID: "p1", AmountCents: 2500, PlatformRevenueCents: 200, Currency: "CNY", FareItems: items})
+	assert.Error(t, err)
+
+	// 税费单独核对，不能混入平台收入
+	items = append(items, settlemententity.FareLineItem{Kind: settlemententity.FareTax, AmountCents: 150, TaxCents: 150})
+	sr, err = svc.CreateSettlementRecord(&CreateSettlementRecordCmd{BookingID: "b1", DriverID: "d1", PassengerID: "p1", AmountCents: 2650, PlatformRevenueCents: 700, TaxCents: 150, Currency: "CNY", FareItems: items})
+	require.NoError(t, err)
+	assert.Equal(t, int64(150), sr.TaxCents)
+	_, err = svc.CreateSettlementRecord(&CreateSettlementRecordCmd{BookingID: "b1", DriverID: "d1", PassengerID: "p1", AmountCents: 2650, PlatformRevenueCents: 850, Currency: "CNY", FareItems: items})
+	assert.Error(t, err)
+}
@@ -1,11 +1,64 @@
 package entity
 
-import "time"
+import (
+	"errors"
+	"time"
+)
+
+// 司机账号状态
+const (
+	DriverStatusPending    = "pending"    // 入驻中，证件未全部审核通过
+	DriverStatusActive     = "active"     // 可以发布报价
+	DriverStatusSuspended  = "suspended"  // 暂停接单，如证件即将过期
+	DriverStatusOffboarded = "offboarded" // 已退出平台，不可恢复
+)
+
+// SuspendReasonDocumentsExpiring 证件即将过期导致的自动暂停，续期证件审核通过后自动恢复。
+const SuspendReasonDocumentsExpiring = "documents_expiring"
 
 type Driver struct {
-	ID        string
-	Name      string
-	Rating    float64
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID           string
+	Name         string
+	Rating       float64 // 由乘客评价按声誉策略计算，不接受司机自行填写
+	RatingCount  int     // 收到的评价数
+	Status       string  // 见 DriverStatus*
+	StatusReason string  // 暂停或退出的原因
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// IsActive 只有 active 状态的司机可以发布报价。
+func (d *Driver) IsActive() bool { return d.Status == DriverStatusActive }
+
+// Activate 证件齐全后激活，允许 pending/suspended->active
+func (d *Driver) Activate() error {
+	if d.Status != DriverStatusPending && d.Status != DriverStatusSuspended {
+		return errors.New("driver status must be 'pending' or 'suspended' to activate")
+	}
+	d.Status = DriverStatusActive
+	d.StatusReason = ""
+	return nil
+}
+
+// Suspend 暂停接单，仅允许 active->suspended
+func (d *Driver) Suspend(reason string) error {
+	if d.Status != DriverStatusActive {
+		return errors.New("driver status must be 'active' to suspend")
+	}
+	if reason == "" {
+		return errors.New("suspend reason required")
+	}
+	d.Status = DriverStatusSuspended
+	d.StatusReason = reason
+	return nil
+}
+
+// Offboard 退出平台，除已退出外的任何状态均可
+func (d *Driver) Offboard(reason string) error {
+	if d.Status == DriverStatusOffboarded {
+		return errors.New("driver already offboarded")
+	}
+	d.Status = DriverStatusOffboarded
+	d.StatusReason = reason
+	return nil
 }
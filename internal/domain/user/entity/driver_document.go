@@ -0,0 +1,81 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+// 司机入驻需要提交的证件类型
+const (
+	DocumentTypeLicence             = "licence"              // 驾驶证
+	DocumentTypeInsurance           = "insurance"            // 车辆保险
+	DocumentTypeVehicleRegistration = "vehicle_registration" // 行驶证
+)
+
+// RequiredDocumentTypes 激活司机前必须审核通过且未过期的证件。
+var RequiredDocumentTypes = []string{DocumentTypeLicence, DocumentTypeInsurance, DocumentTypeVehicleRegistration}
+
+// 证件审核状态
+const (
+	DocumentPendingReview = "pending_review"
+	DocumentApproved      = "approved"
+	DocumentRejected      = "rejected"
+)
+
+// DriverDocument 司机上传的证件，文件内容保存在 blob 存储中，以 BlobKey 引用。
+type DriverDocument struct {
+	ID          string
+	DriverID    string
+	Type        string // 见 DocumentType*
+	BlobKey     string
+	FileName    string
+	ContentType string
+	SizeBytes   int64
+	ExpiresAt   time.Time // 证件有效期截止
+	Status      string    // pending_review, approved, rejected
+	ReviewedBy  string
+	ReviewNote  string
+	ReviewedAt  *time.Time
+	UploadedAt  time.Time
+}
+
+// ValidDocumentType 是否为支持的证件类型。
+func ValidDocumentType(t string) bool {
+	for _, r := range RequiredDocumentTypes {
+		if r == t {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidAt 证件已审核通过且在 at 时刻仍在有效期内。
+func (d *DriverDocument) ValidAt(at time.Time) bool {
+	return d.Status == DocumentApproved && d.ExpiresAt.After(at)
+}
+
+// Approve 审核通过，仅允许 pending_review->approved
+func (d *DriverDocument) Approve(reviewer, note string, at time.Time) error {
+	if d.Status != DocumentPendingReview {
+		return errors.New("document status must be 'pending_review' to approve")
+	}
+	if !d.ExpiresAt.After(at) {
+		return errors.New("document already expired")
+	}
+	d.Status = DocumentApproved
+	d.ReviewedBy, d.ReviewNote, d.ReviewedAt = reviewer, note, &at
+	return nil
+}
+
+// Reject 审核驳回，须说明原因，仅允许 pending_review->rejected
+func (d *DriverDocument) Reject(reviewer, note string, at time.Time) error {
+	if d.Status != DocumentPendingReview {
+		return errors.New("document status must be 'pending_review' to reject")
+	}
+	if note == "" {
+		return errors.New("reject note required")
+	}
+	d.Status = DocumentRejected
+	d.ReviewedBy, d.ReviewNote, d.ReviewedAt = reviewer, note, &at
+	return nil
+}
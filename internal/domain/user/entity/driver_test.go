@@ -0,0 +1,67 @@
+package entity
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDriver_StatusTransitions(t *testing.T) {
+	d := &Driver{Status: DriverStatusPending}
+	if err := d.Suspend("late"); err == nil {
+		t.Errorf("expected error suspending a pending driver")
+	}
+	if err := d.Activate(); err != nil || !d.IsActive() {
+		t.Fatalf("expected active driver, got %s: %v", d.Status, err)
+	}
+	if err := d.Suspend(""); err == nil {
+		t.Errorf("expected error suspending without reason")
+	}
+	if err := d.Suspend(SuspendReasonDocumentsExpiring); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d.IsActive() || d.StatusReason != SuspendReasonDocumentsExpiring {
+		t.Errorf("unexpected driver %+v", d)
+	}
+	if err := d.Activate(); err != nil || d.StatusReason != "" {
+		t.Fatalf("expected reinstated driver without reason, got %+v: %v", d, err)
+	}
+	if err := d.Offboard("left the platform"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := d.Activate(); err == nil {
+		t.Errorf("expected error activating an offboarded driver")
+	}
+	if err := d.Offboard("again"); err == nil {
+		t.Errorf("expected error offboarding twice")
+	}
+}
+
+func TestDriverDocument_Review(t *testing.T) {
+	now := time.Date(2025, 11, 8, 10, 0, 0, 0, time.UTC)
+	d := &DriverDocument{Status: DocumentPendingReview, ExpiresAt: now.AddDate(1, 0, 0)}
+	if d.ValidAt(now) {
+		t.Errorf("pending document must not be valid")
+	}
+	if err := d.Approve("admin", "", now); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !d.ValidAt(now) || d.ValidAt(d.ExpiresAt) {
+		t.Errorf("expected document valid until it expires")
+	}
+	if err := d.Reject("admin", "blurry", now); err == nil {
+		t.Errorf("expected error rejecting a reviewed document")
+	}
+
+	r := &DriverDocument{Status: DocumentPendingReview, ExpiresAt: now.AddDate(1, 0, 0)}
+	if err := r.Reject("admin", "", now); err == nil {
+		t.Errorf("expected error rejecting without note")
+	}
+	if err := r.Reject("admin", "blurry", now); err != nil || r.Status != DocumentRejected || r.ReviewedAt == nil {
+		t.Errorf("unexpected rejected document %+v: %v", r, err)
+	}
+
+	expired := &DriverDocument{Status: DocumentPendingReview, ExpiresAt: now.Add(-time.Hour)}
+	if err := expired.Approve("admin", "", now); err == nil {
+		t.Errorf("expected error approving an expired document")
+	}
+}
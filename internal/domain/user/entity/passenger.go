@@ -3,8 +3,17 @@ package entity
 import "time"
 
 type Passenger struct {
-	ID        string
-	Name      string
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID               string
+	Name             string
+	CorporateAccount string  // 所属企业客户，为空表示个人乘客；企业客户按月合并开票
+	ReputationScore  float64 // 由司机评价按声誉策略计算
+	RatingCount      int     // 收到的评价数
+	NoShowCount      int     // 被司机标记为未到场的次数
+	// 联系方式，均可为空；手机号为 E.164 格式，语言为 BCP 47 标签（如 zh-CN），为空时使用平台默认语言
+	Phone                  string
+	Email                  string
+	PreferredLanguage      string
+	DefaultPaymentMethodID string // 未指定支付方式的接机请求使用默认支付方式，为空时从乘客钱包扣款
+	CreatedAt              time.Time
+	UpdatedAt              time.Time
 }
@@ -0,0 +1,47 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+// 支付方式状态
+const (
+	PaymentMethodActive  = "active"
+	PaymentMethodRemoved = "removed"
+)
+
+// PaymentMethod 乘客保存的支付方式。卡号等敏感信息只保存在支付方式保险库中，平台只保存令牌与可展示的信息。
+type PaymentMethod struct {
+	ID          string
+	PassengerID string
+	Token       string // 保险库令牌，扣款时交给支付网关
+	Brand       string // visa, mastercard, amex, unionpay
+	Last4       string
+	ExpMonth    int
+	ExpYear     int
+	Status      string // active, removed
+	CreatedAt   time.Time
+	RemovedAt   *time.Time
+}
+
+// ExpiredAt 卡片在 at 时刻是否已过有效期（有效期到 ExpYear 年 ExpMonth 月月底）。
+func (m *PaymentMethod) ExpiredAt(at time.Time) bool {
+	end := time.Date(m.ExpYear, time.Month(m.ExpMonth)+1, 1, 0, 0, 0, 0, time.UTC)
+	return !at.Before(end)
+}
+
+// UsableAt 未删除且未过期，可用于新的接机请求。
+func (m *PaymentMethod) UsableAt(at time.Time) bool {
+	return m.Status == PaymentMethodActive && !m.ExpiredAt(at)
+}
+
+// Remove 删除支付方式，仅允许 active->removed
+func (m *PaymentMethod) Remove(at time.Time) error {
+	if m.Status != PaymentMethodActive {
+		return errors.New("payment method already removed")
+	}
+	m.Status = PaymentMethodRemoved
+	m.RemovedAt = &at
+	return nil
+}
@@ -0,0 +1,27 @@
+package entity
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPaymentMethod_Usable(t *testing.T) {
+	m := &PaymentMethod{Status: PaymentMethodActive, ExpMonth: 12, ExpYear: 2025}
+	if !m.UsableAt(time.Date(2025, 12, 31, 23, 0, 0, 0, time.UTC)) {
+		t.Errorf("card must be usable until the end of its expiry month")
+	}
+	if m.UsableAt(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("card must expire after its expiry month")
+	}
+
+	at := time.Date(2025, 11, 8, 10, 0, 0, 0, time.UTC)
+	if err := m.Remove(at); err != nil || m.Status != PaymentMethodRemoved || m.RemovedAt == nil {
+		t.Fatalf("expected removed payment method, got %+v: %v", m, err)
+	}
+	if m.UsableAt(at) {
+		t.Errorf("removed payment method must not be usable")
+	}
+	if err := m.Remove(at); err == nil {
+		t.Errorf("expected error removing twice")
+	}
+}
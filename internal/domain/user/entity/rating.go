@@ -0,0 +1,47 @@
+package entity
+
+import (
+	"errors"
+	"time"
+	"unicode/utf8"
+)
+
+// 评价对象
+const (
+	RatingTargetDriver    = "driver"    // 乘客评价司机
+	RatingTargetPassenger = "passenger" // 司机评价乘客
+)
+
+// MaxRatingCommentLength 评价内容的最大字符数。
+const MaxRatingCommentLength = 500
+
+// TripRating 行程结束后对另一方的评价，每个订单每个方向只能评价一次。
+type TripRating struct {
+	ID        string
+	BookingID string
+	Target    string // 被评价的一方，见 RatingTarget*
+	RaterID   string
+	RateeID   string
+	Score     int // 1~5 星
+	Comment   string
+	NoShow    bool // 司机评价乘客时标记乘客未到场，按 1 星计
+	CreatedAt time.Time
+}
+
+// Validate 校验评分为 1~5 星、评价内容不超过 MaxRatingCommentLength 个字符；
+// 只有对乘客的评价可以标记未到场，且必须为 1 星。
+func (r *TripRating) Validate() error {
+	if r.BookingID == "" || r.RaterID == "" || r.RateeID == "" {
+		return errors.New("booking_id, rater and ratee required")
+	}
+	if r.Score < 1 || r.Score > 5 {
+		return errors.New("score must be between 1 and 5")
+	}
+	if utf8.RuneCountInString(r.Comment) > MaxRatingCommentLength {
+		return errors.New("comment too long")
+	}
+	if r.NoShow && (r.Target != RatingTargetPassenger || r.Score != 1) {
+		return errors.New("no_show applies to passenger ratings with score 1")
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+// 车辆核验状态
+const (
+	VehiclePendingVerification = "pending_verification"
+	VehicleVerified            = "verified"
+	VehicleRejected            = "rejected"
+)
+
+// Vehicle 司机登记的车辆，一个司机可以登记多辆；核验通过后才能用于报价，报价的车型取自 Class。
+type Vehicle struct {
+	ID              string
+	DriverID        string
+	Plate           string // 车牌号，已规范化为大写、无空格
+	Make            string // 品牌，如 Toyota
+	Model           string // 车型，如 Camry
+	Colour          string
+	Class           string // 车型等级，即报价与接机请求的 vehicle_type，如 sedan
+	Seats           int    // 乘客座位数，不含司机
+	LuggageCapacity int    // 可放行李件数
+	Status          string // pending_verification, verified, rejected
+	VerifiedBy      string
+	ReviewNote      string
+	ReviewedAt      *time.Time
+	CreatedAt       time.Time
+}
+
+// IsVerified 车辆已核验，可用于报价。
+func (v *Vehicle) IsVerified() bool {
+	return v.Status == VehicleVerified
+}
+
+// Verify 核验通过，仅允许 pending_verification->verified
+func (v *Vehicle) Verify(reviewer, note string, at time.Time) error {
+	if v.Status != VehiclePendingVerification {
+		return errors.New("vehicle status must be 'pending_verification' to verify")
+	}
+	v.Status = VehicleVerified
+	v.VerifiedBy, v.ReviewNote, v.ReviewedAt = reviewer, note, &at
+	return nil
+}
+
+// Reject 核验驳回，须说明原因，仅允许 pending_verification->rejected
+func (v *Vehicle) Reject(reviewer, note string, at time.Time) error {
+	if v.Status != VehiclePendingVerification {
+		return errors.New("vehicle status must be 'pending_verification' to reject")
+	}
+	if note == "" {
+		return errors.New("reject note required")
+	}
+	v.Status = VehicleRejected
+	v.VerifiedBy, v.ReviewNote, v.ReviewedAt = reviewer, note, &at
+	return nil
+}
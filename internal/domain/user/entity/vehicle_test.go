@@ -0,0 +1,31 @@
+package entity
+
+import (
+	"testing"
+	"time"
+)
+
+func TestVehicle_Verification(t *testing.T) {
+	at := time.Date(2025, 11, 8, 10, 0, 0, 0, time.UTC)
+	v := &Vehicle{Status: VehiclePendingVerification}
+	if v.IsVerified() {
+		t.Fatalf("pending vehicle must not be verified")
+	}
+	if err := v.Verify("ops", "", at); err != nil || !v.IsVerified() || v.ReviewedAt == nil {
+		t.Fatalf("expected verified vehicle, got %+v: %v", v, err)
+	}
+	if err := v.Reject("ops", "wrong plate", at); err == nil {
+		t.Errorf("expected error rejecting a verified vehicle")
+	}
+
+	r := &Vehicle{Status: VehiclePendingVerification}
+	if err := r.Reject("ops", "", at); err == nil {
+		t.Errorf("expected error rejecting without note")
+	}
+	if err := r.Reject("ops", "photo does not match", at); err != nil || r.Status != VehicleRejected {
+		t.Fatalf("expected rejected vehicle, got %+v: %v", r, err)
+	}
+	if err := r.Verify("ops", "", at); err == nil {
+		t.Errorf("expected error verifying a rejected vehicle")
+	}
+}
@@ -1,6 +1,17 @@
 package user
 
-import "github.com/gavin/airport-pickup/internal/domain/user/entity"
+import (
+	"errors"
+	"time"
+
+	"github.com/gavin/airport-pickup/internal/domain/user/entity"
+)
+
+// ErrAlreadyRated 订单的该方向已有评价。
+var ErrAlreadyRated = errors.New("booking already rated")
+
+// ErrPlateRegistered 车牌已被另一辆未驳回的车辆登记。
+var ErrPlateRegistered = errors.New("vehicle plate already registered")
 
 type PassengerRepository interface {
 	Save(p *entity.Passenger) error
@@ -11,3 +22,47 @@ type DriverRepository interface {
 	Save(d *entity.Driver) error
 	GetByID(id string) (*entity.Driver, error)
 }
+
+// RatingRepository 行程评价持久化。
+type RatingRepository interface {
+	// 同一订单同一方向已有评价时返回 ErrAlreadyRated
+	SaveRating(r *entity.TripRating) error
+	// 不存在时返回 (nil, nil)
+	GetRating(bookingID, target string) (*entity.TripRating, error)
+	// 某人在某一方向收到的全部评价，按时间升序
+	ListRatings(rateeID, target string) ([]*entity.TripRating, error)
+}
+
+// DriverDocumentRepository 司机证件记录持久化，文件内容保存在 BlobStore。
+type DriverDocumentRepository interface {
+	SaveDocument(d *entity.DriverDocument) error
+	// 不存在时返回 (nil, nil)
+	GetDocument(id string) (*entity.DriverDocument, error)
+	// 司机的全部证件，按上传时间升序
+	ListDocuments(driverID string) ([]*entity.DriverDocument, error)
+	// 待审核证件，按上传时间升序，先传先审
+	ListPendingReview() ([]*entity.DriverDocument, error)
+	// 审核通过且在 before 之前到期的证件，用于到期前自动暂停司机
+	ListApprovedExpiringBefore(before time.Time) ([]*entity.DriverDocument, error)
+}
+
+// VehicleRepository 司机车辆登记持久化。
+type VehicleRepository interface {
+	// 车牌已被另一辆未驳回的车辆登记时返回 ErrPlateRegistered
+	SaveVehicle(v *entity.Vehicle) error
+	// 不存在时返回 (nil, nil)
+	GetVehicle(id string) (*entity.Vehicle, error)
+	// 司机的全部车辆，按登记时间升序
+	ListVehicles(driverID string) ([]*entity.Vehicle, error)
+	// 待核验车辆，按登记时间升序
+	ListPendingVerification() ([]*entity.Vehicle, error)
+}
+
+// PaymentMethodRepository 乘客支付方式持久化，只保存保险库令牌。
+type PaymentMethodRepository interface {
+	SavePaymentMethod(m *entity.PaymentMethod) error
+	// 不存在时返回 (nil, nil)
+	GetPaymentMethod(id string) (*entity.PaymentMethod, error)
+	// 乘客的全部支付方式（含已删除），按添加时间升序
+	ListPaymentMethods(passengerID string) ([]*entity.PaymentMethod, error)
+}
@@ -8,16 +8,17 @@ import (
 type DriverService struct{}
 
 type CreateDriverCmd struct {
-	Name   string
-	Rating float64
+	Name          string
+	InitialRating float64 // 没有评价时的声誉分，即声誉策略的先验平均分
 }
 
 func (s *DriverService) CreateDriver(cmd *CreateDriverCmd) (*entity.Driver, error) {
 	if cmd.Name == "" {
 		return nil, errors.New("name required")
 	}
-	if cmd.Rating < 0 || cmd.Rating > 5 {
+	if cmd.InitialRating < 0 || cmd.InitialRating > 5 {
 		return nil, errors.New("invalid rating")
 	}
-	return &entity.Driver{ID: "", Name: cmd.Name, Rating: cmd.Rating}, nil
+	// 新司机须提交证件并审核通过后才能发布报价
+	return &entity.Driver{ID: "", Name: cmd.Name, Rating: cmd.InitialRating, Status: entity.DriverStatusPending}, nil
 }
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"errors"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/gavin/airport-pickup/internal/domain/user/entity"
+)
+
+// ErrBlobNotFound blob 存储中不存在该 key。
+var ErrBlobNotFound = errors.New("blob not found")
+
+// BlobStore 保存上传的文件（如司机证件），由存储实现（本地为 blobstore.LocalStore）。
+// key 由调用方生成，以 / 分隔层级；重复写入同一 key 覆盖原内容。
+type BlobStore interface {
+	Put(key string, content []byte, contentType string) error
+	// 不存在时返回 ErrBlobNotFound
+	Get(key string) ([]byte, error)
+}
+
+// MaxDocumentBytes 单个证件文件的大小上限。
+const MaxDocumentBytes = 10 << 20
+
+// documentExtensions 允许上传的证件文件类型及保存时使用的扩展名。
+var documentExtensions = map[string]string{
+	"application/pdf": ".pdf",
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+}
+
+// OnboardingService 校验司机证件并判断入驻资料是否齐全。
+type OnboardingService struct{}
+
+type CreateDocumentCmd struct {
+	DriverID    string
+	Type        string
+	FileName    string
+	ContentType string // 按文件内容识别的类型，不信任客户端声明
+	SizeBytes   int64
+	ExpiresAt   time.Time
+	UploadedAt  time.Time
+}
+
+// CreateDocument 校验并创建待审核的证件（不生成 ID 与 BlobKey，由上层负责）。
+func (s *OnboardingService) CreateDocument(cmd *CreateDocumentCmd) (*entity.DriverDocument, error) {
+	if cmd.DriverID == "" {
+		return nil, errors.New("driver_id required")
+	}
+	if !entity.ValidDocumentType(cmd.Type) {
+		return nil, errors.New("type must be one of " + strings.Join(entity.RequiredDocumentTypes, ", "))
+	}
+	if cmd.SizeBytes <= 0 {
+		return nil, errors.New("file required")
+	}
+	if cmd.SizeBytes > MaxDocumentBytes {
+		return nil, errors.New("file too large")
+	}
+	if _, ok := documentExtensions[cmd.ContentType]; !ok {
+		return nil, errors.New("file must be a PDF, JPEG or PNG")
+	}
+	if !cmd.ExpiresAt.After(cmd.UploadedAt) {
+		return nil, errors.New("expires_at must be in the future")
+	}
+	return &entity.DriverDocument{
+		DriverID:    cmd.DriverID,
+		Type:        cmd.Type,
+		FileName:    path.Base(cmd.FileName),
+		ContentType: cmd.ContentType,
+		SizeBytes:   cmd.SizeBytes,
+		ExpiresAt:   cmd.ExpiresAt,
+		Status:      entity.DocumentPendingReview,
+		UploadedAt:  cmd.UploadedAt,
+	}, nil
+}
+
+// DocumentBlobKey 证件文件在 blob 存储中的 key：drivers/{司机}/documents/{证件}{扩展名}。
+func DocumentBlobKey(d *entity.DriverDocument) string {
+	return "drivers/" + d.DriverID + "/documents/" + d.ID + documentExtensions[d.ContentType]
+}
+
+// MissingDocuments 返回在 at 时刻没有审核通过且未过期证件的必需证件类型，为空表示资料齐全。
+func (s *OnboardingService) MissingDocuments(docs []*entity.DriverDocument, at time.Time) []string {
+	var missing []string
+	for _, t := range entity.RequiredDocumentTypes {
+		ok := false
+		for _, d := range docs {
+			if d.Type == t && d.ValidAt(at) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			missing = append(missing, t)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gavin/airport-pickup/internal/domain/user/entity"
+)
+
+func TestOnboardingService_CreateDocument(t *testing.T) {
+	s := &OnboardingService{}
+	now := time.Date(2025, 11, 8, 10, 0, 0, 0, time.UTC)
+	valid := CreateDocumentCmd{DriverID: "d1", Type: entity.DocumentTypeLicence, FileName: "../../licence.pdf", ContentType: "application/pdf",
+		SizeBytes: 2048, ExpiresAt: now.AddDate(1, 0, 0), UploadedAt: now}
+	d, err := s.CreateDocument(&valid)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d.Status != entity.DocumentPendingReview || d.FileName != "licence.pdf" {
+		t.Errorf("unexpected document %+v", d)
+	}
+	d.ID = "doc1"
+	if key := DocumentBlobKey(d); key != "drivers/d1/documents/doc1.pdf" {
+		t.Errorf("unexpected blob key %s", key)
+	}
+
+	cases := map[string]func(c *CreateDocumentCmd){
+		"unknown type":  func(c *CreateDocumentCmd) { c.Type = "passport" },
+		"empty file":    func(c *CreateDocumentCmd) { c.SizeBytes = 0 },
+		"too large":     func(c *CreateDocumentCmd) { c.SizeBytes = MaxDocumentBytes + 1 },
+		"content type":  func(c *CreateDocumentCmd) { c.ContentType = "text/html; charset=utf-8" },
+		"already past":  func(c *CreateDocumentCmd) { c.ExpiresAt = now.Add(-time.Hour) },
+		"missing owner": func(c *CreateDocumentCmd) { c.DriverID = "" },
+	}
+	for name, mutate := range cases {
+		cmd := valid
+		mutate(&cmd)
+		if _, err := s.CreateDocument(&cmd); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestOnboardingService_MissingDocuments(t *testing.T) {
+	s := &OnboardingService{}
+	now := time.Date(2025, 11, 8, 10, 0, 0, 0, time.UTC)
+	docs := []*entity.DriverDocument{
+		{Type: entity.DocumentTypeLicence, Status: entity.DocumentApproved, ExpiresAt: now.AddDate(2, 0, 0)},
+		// 保险即将过期，续期的保险尚未审核
+		{Type: entity.DocumentTypeInsurance, Status: entity.DocumentApproved, ExpiresAt: now.Add(12 * time.Hour)},
+		{Type: entity.DocumentTypeInsurance, Status: entity.DocumentPendingReview, ExpiresAt: now.AddDate(1, 0, 0)},
+		{Type: entity.DocumentTypeVehicleRegistration, Status: entity.DocumentRejected, ExpiresAt: now.AddDate(1, 0, 0)},
+	}
+	missing := s.MissingDocuments(docs, now)
+	if len(missing) != 1 || missing[0] != entity.DocumentTypeVehicleRegistration {
+		t.Errorf("expected vehicle_registration missing, got %v", missing)
+	}
+	missing = s.MissingDocuments(docs, now.Add(24*time.Hour))
+	if len(missing) != 2 || missing[0] != entity.DocumentTypeInsurance {
+		t.Errorf("expected insurance and vehicle_registration missing a day later, got %v", missing)
+	}
+
+	docs[2].Status = entity.DocumentApproved
+	docs[3].Status = entity.DocumentApproved
+	if missing := s.MissingDocuments(docs, now.Add(24*time.Hour)); len(missing) != 0 {
+		t.Errorf("expected no missing documents after renewal, got %v", missing)
+	}
+}
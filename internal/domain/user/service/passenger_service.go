@@ -2,18 +2,105 @@ package service
 
 import (
 	"errors"
+	"net/mail"
+	"regexp"
+	"strings"
+
 	"github.com/gavin/airport-pickup/internal/domain/user/entity"
 )
 
 type PassengerService struct{}
 
 type CreatePassengerCmd struct {
-	Name string
+	Name              string
+	CorporateAccount  string
+	InitialScore      float64 // 没有评价时的声誉分，即声誉策略的先验平均分
+	Phone             string
+	Email             string
+	PreferredLanguage string
 }
 
 func (s *PassengerService) CreatePassenger(cmd *CreatePassengerCmd) (*entity.Passenger, error) {
 	if cmd.Name == "" {
 		return nil, errors.New("name required")
 	}
-	return &entity.Passenger{ID: "", Name: cmd.Name}, nil
+	if cmd.InitialScore < 0 || cmd.InitialScore > 5 {
+		return nil, errors.New("invalid reputation score")
+	}
+	phone, email, lang, err := normalizeContact(cmd.Phone, cmd.Email, cmd.PreferredLanguage)
+	if err != nil {
+		return nil, err
+	}
+	return &entity.Passenger{ID: "", Name: cmd.Name, CorporateAccount: cmd.CorporateAccount, ReputationScore: cmd.InitialScore,
+		Phone: phone, Email: email, PreferredLanguage: lang}, nil
+}
+
+// UpdateProfileCmd 修改乘客资料，nil 字段保持不变，空字符串表示清空（姓名除外）。
+type UpdateProfileCmd struct {
+	Name              *string
+	Phone             *string
+	Email             *string
+	PreferredLanguage *string
+}
+
+// UpdateProfile 校验并修改乘客资料，校验失败时乘客不变。
+func (s *PassengerService) UpdateProfile(p *entity.Passenger, cmd *UpdateProfileCmd) error {
+	name, phone, email, lang := p.Name, p.Phone, p.Email, p.PreferredLanguage
+	if cmd.Name != nil {
+		name = strings.TrimSpace(*cmd.Name)
+		if name == "" {
+			return errors.New("name required")
+		}
+	}
+	if cmd.Phone != nil {
+		phone = *cmd.Phone
+	}
+	if cmd.Email != nil {
+		email = *cmd.Email
+	}
+	if cmd.PreferredLanguage != nil {
+		lang = *cmd.PreferredLanguage
+	}
+	phone, email, lang, err := normalizeContact(phone, email, lang)
+	if err != nil {
+		return err
+	}
+	p.Name, p.Phone, p.Email, p.PreferredLanguage = name, phone, email, lang
+	return nil
+}
+
+var (
+	e164Pattern     = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+	languagePattern = regexp.MustCompile(`^[a-zA-Z]{2,3}(-[a-zA-Z0-9]{2,8})*$`)
+)
+
+// normalizeContact 校验并规范化联系方式：手机号去掉空格和连字符后须为 E.164 格式，
+// 邮箱转为小写，语言标签按 BCP 47 大小写习惯（zh-CN）。均允许为空。
+func normalizeContact(phone, email, lang string) (string, string, string, error) {
+	phone = strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(phone))
+	if phone != "" && !e164Pattern.MatchString(phone) {
+		return "", "", "", errors.New("phone must be in E.164 format, e.g. +8613800138000")
+	}
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email != "" {
+		addr, err := mail.ParseAddress(email)
+		if err != nil || addr.Address != email {
+			return "", "", "", errors.New("invalid email")
+		}
+	}
+	lang = strings.TrimSpace(lang)
+	if lang != "" {
+		if !languagePattern.MatchString(lang) {
+			return "", "", "", errors.New("invalid preferred_language")
+		}
+		parts := strings.Split(lang, "-")
+		parts[0] = strings.ToLower(parts[0])
+		for i := 1; i < len(parts); i++ {
+			if len(parts[i]) == 2 {
+				parts[i] = strings.ToUpper(parts[i])
+			}
+		}
+		lang = strings.Join(parts, "-")
+	}
+	return phone, email, lang, nil
 }
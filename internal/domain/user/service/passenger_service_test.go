@@ -0,0 +1,50 @@
+package service
+
+import "testing"
+
+func TestPassengerService_CreatePassengerContact(t *testing.T) {
+	s := &PassengerService{}
+	p, err := s.CreatePassenger(&CreatePassengerCmd{Name: "Alice", InitialScore: 4,
+		Phone: "+86 138-0013-8000", Email: " Alice@Example.com ", PreferredLanguage: "ZH-cn"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Phone != "+8613800138000" || p.Email != "alice@example.com" || p.PreferredLanguage != "zh-CN" {
+		t.Errorf("unexpected contact details %+v", p)
+	}
+
+	for name, cmd := range map[string]CreatePassengerCmd{
+		"local phone":  {Name: "Alice", Phone: "13800138000"},
+		"bad email":    {Name: "Alice", Email: "alice@"},
+		"display name": {Name: "Alice", Email: "Alice <alice@example.com>"},
+		"bad language": {Name: "Alice", PreferredLanguage: "chinese"},
+	} {
+		if _, err := s.CreatePassenger(&cmd); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestPassengerService_UpdateProfile(t *testing.T) {
+	s := &PassengerService{}
+	p, _ := s.CreatePassenger(&CreatePassengerCmd{Name: "Alice", Email: "alice@example.com"})
+
+	bad := "not-an-email"
+	if err := s.UpdateProfile(p, &UpdateProfileCmd{Email: &bad}); err == nil {
+		t.Fatalf("expected error")
+	}
+	if p.Email != "alice@example.com" {
+		t.Errorf("failed update must not change passenger, got %+v", p)
+	}
+
+	phone, lang, empty := "+44 20 7946 0958", "en-gb", ""
+	if err := s.UpdateProfile(p, &UpdateProfileCmd{Phone: &phone, PreferredLanguage: &lang, Email: &empty}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Name != "Alice" || p.Phone != "+442079460958" || p.PreferredLanguage != "en-GB" || p.Email != "" {
+		t.Errorf("unexpected profile %+v", p)
+	}
+	if err := s.UpdateProfile(p, &UpdateProfileCmd{Name: &empty}); err == nil {
+		t.Errorf("expected error clearing name")
+	}
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gavin/airport-pickup/internal/domain/user/entity"
+)
+
+// ErrCardDeclined 保险库拒绝保存该卡（卡号无效、已过期或不支持的卡组织）。
+var ErrCardDeclined = errors.New("card declined")
+
+// ErrUnknownToken 保险库中没有该令牌（未签发或已删除）。
+var ErrUnknownToken = errors.New("unknown payment token")
+
+// CardDetails 客户端提交给保险库的卡信息，平台从不接收。
+type CardDetails struct {
+	Number     string
+	ExpMonth   int
+	ExpYear    int
+	HolderName string
+}
+
+// VaultedCard 保险库中令牌对应的卡的可展示信息。
+type VaultedCard struct {
+	Token       string
+	PassengerID string // 令牌化时绑定的乘客，为空表示未绑定
+	Brand       string
+	Last4       string
+	ExpMonth    int
+	ExpYear     int
+}
+
+// PaymentMethodVault 支付服务商的卡片保险库（本地为 payments.LocalVault）。客户端直接向保险库提交卡号换取令牌，
+// 平台只凭令牌查询卡的展示信息。
+type PaymentMethodVault interface {
+	// 令牌不存在时返回 ErrUnknownToken
+	Lookup(token string) (VaultedCard, error)
+	// 删除令牌，之后不能再用于扣款；令牌不存在时不报错
+	Remove(token string) error
+}
+
+// PaymentMethodService 根据保险库返回的令牌创建乘客的支付方式。
+type PaymentMethodService struct{}
+
+// CreatePaymentMethod 创建可用的支付方式（不生成 ID，由上层负责）。
+func (s *PaymentMethodService) CreatePaymentMethod(passengerID string, card VaultedCard, at time.Time) (*entity.PaymentMethod, error) {
+	if passengerID == "" {
+		return nil, errors.New("passenger_id required")
+	}
+	if card.Token == "" {
+		return nil, errors.New("payment token required")
+	}
+	if card.ExpMonth < 1 || card.ExpMonth > 12 {
+		return nil, errors.New("invalid expiry month")
+	}
+	m := &entity.PaymentMethod{
+		PassengerID: passengerID,
+		Token:       card.Token,
+		Brand:       card.Brand,
+		Last4:       card.Last4,
+		ExpMonth:    card.ExpMonth,
+		ExpYear:     card.ExpYear,
+		Status:      entity.PaymentMethodActive,
+		CreatedAt:   at,
+	}
+	if m.ExpiredAt(at) {
+		return nil, errors.New("card expired")
+	}
+	return m, nil
+}
+
+// NextDefault 删除默认支付方式后选出新的默认：最近添加的仍可用的支付方式，没有时返回空字符串（改为钱包扣款）。
+func (s *PaymentMethodService) NextDefault(methods []*entity.PaymentMethod, at time.Time) string {
+	var next *entity.PaymentMethod
+	for _, m := range methods {
+		if m.UsableAt(at) && (next == nil || !m.CreatedAt.Before(next.CreatedAt)) {
+			next = m
+		}
+	}
+	if next == nil {
+		return ""
+	}
+	return next.ID
+}
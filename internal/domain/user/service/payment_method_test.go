@@ -0,0 +1,47 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gavin/airport-pickup/internal/domain/user/entity"
+)
+
+func TestPaymentMethodService_CreatePaymentMethod(t *testing.T) {
+	s := &PaymentMethodService{}
+	at := time.Date(2025, 11, 8, 10, 0, 0, 0, time.UTC)
+	card := VaultedCard{Token: "tok_1", Brand: "visa", Last4: "4242", ExpMonth: 11, ExpYear: 2025}
+	m, err := s.CreatePaymentMethod("p1", card, at)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if m.Status != entity.PaymentMethodActive || m.Token != "tok_1" || m.PassengerID != "p1" {
+		t.Errorf("unexpected payment method %+v", m)
+	}
+
+	expired := card
+	expired.ExpMonth = 10
+	if _, err := s.CreatePaymentMethod("p1", expired, at); err == nil {
+		t.Errorf("expected error for expired card")
+	}
+	if _, err := s.CreatePaymentMethod("p1", VaultedCard{ExpMonth: 1, ExpYear: 2030}, at); err == nil {
+		t.Errorf("expected error without token")
+	}
+}
+
+func TestPaymentMethodService_NextDefault(t *testing.T) {
+	s := &PaymentMethodService{}
+	at := time.Date(2025, 11, 8, 10, 0, 0, 0, time.UTC)
+	methods := []*entity.PaymentMethod{
+		{ID: "old", Status: entity.PaymentMethodActive, ExpMonth: 1, ExpYear: 2030, CreatedAt: at.Add(-3 * time.Hour)},
+		{ID: "newer", Status: entity.PaymentMethodActive, ExpMonth: 1, ExpYear: 2030, CreatedAt: at.Add(-2 * time.Hour)},
+		{ID: "expired", Status: entity.PaymentMethodActive, ExpMonth: 1, ExpYear: 2025, CreatedAt: at.Add(-time.Hour)},
+		{ID: "removed", Status: entity.PaymentMethodRemoved, ExpMonth: 1, ExpYear: 2030, CreatedAt: at},
+	}
+	if got := s.NextDefault(methods, at); got != "newer" {
+		t.Errorf("expected newest usable method, got %q", got)
+	}
+	if got := s.NextDefault(methods[2:], at); got != "" {
+		t.Errorf("expected no default, got %q", got)
+	}
+}
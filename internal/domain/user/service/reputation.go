@@ -0,0 +1,49 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/gavin/airport-pickup/internal/domain/user/entity"
+)
+
+// ReputationPolicy 按收到的评价计算声誉分：以 PriorMean 为先验、PriorWeight 为先验权重的贝叶斯平均，
+// 每条评价的权重按 HalfLife 随时间衰减，近期评价影响更大。评价很少时分数接近先验，避免少数评价造成极端分数。
+type ReputationPolicy struct {
+	PriorMean   float64       // 先验平均分（1~5），也是没有评价时的分数
+	PriorWeight float64       // 先验相当于多少条评价
+	HalfLife    time.Duration // 评价权重的半衰期，0 表示不衰减
+}
+
+// DefaultReputationPolicy 未配置时使用的声誉策略。
+var DefaultReputationPolicy = ReputationPolicy{PriorMean: 4.5, PriorWeight: 5, HalfLife: 180 * 24 * time.Hour}
+
+func (p ReputationPolicy) Validate() error {
+	if p.PriorMean < 1 || p.PriorMean > 5 {
+		return errors.New("prior_mean must be between 1 and 5")
+	}
+	if p.PriorWeight <= 0 {
+		return errors.New("prior_weight must be > 0")
+	}
+	if p.HalfLife < 0 {
+		return errors.New("half_life must be >= 0")
+	}
+	return nil
+}
+
+// Score 返回 at 时刻按 ratings 计算的声誉分，保留两位小数。
+func (p ReputationPolicy) Score(ratings []*entity.TripRating, at time.Time) float64 {
+	sum, weight := p.PriorMean*p.PriorWeight, p.PriorWeight
+	for _, r := range ratings {
+		w := 1.0
+		if p.HalfLife > 0 {
+			if age := at.Sub(r.CreatedAt); age > 0 {
+				w = math.Pow(0.5, float64(age)/float64(p.HalfLife))
+			}
+		}
+		sum += w * float64(r.Score)
+		weight += w
+	}
+	return math.Round(sum/weight*100) / 100
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gavin/airport-pickup/internal/domain/user/entity"
+)
+
+func TestReputationPolicy_Score(t *testing.T) {
+	now := time.Date(2025, 11, 8, 10, 0, 0, 0, time.UTC)
+	p := ReputationPolicy{PriorMean: 4.5, PriorWeight: 5}
+	if got := p.Score(nil, now); got != 4.5 {
+		t.Errorf("expected prior mean without ratings, got %v", got)
+	}
+	// 一条 1 星评价只把分数拉低到 (4.5*5+1)/6
+	one := []*entity.TripRating{{Score: 1, CreatedAt: now}}
+	if got := p.Score(one, now); got != 3.92 {
+		t.Errorf("expected 3.92, got %v", got)
+	}
+	five := make([]*entity.TripRating, 0, 45)
+	for i := 0; i < 45; i++ {
+		five = append(five, &entity.TripRating{Score: 5, CreatedAt: now})
+	}
+	if got := p.Score(five, now); got != 4.95 {
+		t.Errorf("expected 4.95, got %v", got)
+	}
+}
+
+func TestReputationPolicy_DecaysOldRatings(t *testing.T) {
+	now := time.Date(2025, 11, 8, 10, 0, 0, 0, time.UTC)
+	p := ReputationPolicy{PriorMean: 4, PriorWeight: 1, HalfLife: 30 * 24 * time.Hour}
+	ratings := []*entity.TripRating{
+		{Score: 1, CreatedAt: now.AddDate(0, 0, -60)}, // 两个半衰期，权重 0.25
+		{Score: 5, CreatedAt: now},
+	}
+	// (4*1 + 1*0.25 + 5*1) / 2.25
+	if got := p.Score(ratings, now); got != 4.11 {
+		t.Errorf("expected 4.11, got %v", got)
+	}
+	if got := (ReputationPolicy{PriorMean: 4, PriorWeight: 1}).Score(ratings, now); got != 3.33 {
+		t.Errorf("expected 3.33 without decay, got %v", got)
+	}
+}
+
+func TestReputationPolicy_Validate(t *testing.T) {
+	if err := DefaultReputationPolicy.Validate(); err != nil {
+		t.Fatalf("default policy invalid: %v", err)
+	}
+	for _, p := range []ReputationPolicy{{PriorMean: 0, PriorWeight: 1}, {PriorMean: 4, PriorWeight: 0}, {PriorMean: 4, PriorWeight: 1, HalfLife: -1}} {
+		if err := p.Validate(); err == nil {
+			t.Errorf("expected error for %+v", p)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gavin/airport-pickup/internal/domain/user/entity"
+)
+
+// VehicleService 校验并创建司机登记的车辆。
+type VehicleService struct{}
+
+type RegisterVehicleCmd struct {
+	DriverID        string
+	Plate           string
+	Make            string
+	Model           string
+	Colour          string
+	Class           string
+	Seats           int
+	LuggageCapacity int
+	CreatedAt       time.Time
+}
+
+// RegisterVehicle 校验并创建待核验的车辆（不生成 ID，由上层负责）。
+func (s *VehicleService) RegisterVehicle(cmd *RegisterVehicleCmd) (*entity.Vehicle, error) {
+	if cmd.DriverID == "" {
+		return nil, errors.New("driver_id required")
+	}
+	plate := NormalizePlate(cmd.Plate)
+	if n := utf8.RuneCountInString(plate); n < 2 || n > 16 {
+		return nil, errors.New("plate must be 2 to 16 characters")
+	}
+	brand, model, colour := strings.TrimSpace(cmd.Make), strings.TrimSpace(cmd.Model), strings.TrimSpace(cmd.Colour)
+	if brand == "" || model == "" || colour == "" {
+		return nil, errors.New("make, model and colour required")
+	}
+	class := strings.ToLower(strings.TrimSpace(cmd.Class))
+	if class == "" {
+		return nil, errors.New("class required")
+	}
+	if cmd.Seats < 1 || cmd.Seats > 20 {
+		return nil, errors.New("seats must be between 1 and 20")
+	}
+	if cmd.LuggageCapacity < 0 || cmd.LuggageCapacity > 20 {
+		return nil, errors.New("luggage_capacity must be between 0 and 20")
+	}
+	return &entity.Vehicle{
+		DriverID:        cmd.DriverID,
+		Plate:           plate,
+		Make:            brand,
+		Model:           model,
+		Colour:          colour,
+		Class:           class,
+		Seats:           cmd.Seats,
+		LuggageCapacity: cmd.LuggageCapacity,
+		Status:          entity.VehiclePendingVerification,
+		CreatedAt:       cmd.CreatedAt,
+	}, nil
+}
+
+// NormalizePlate 车牌统一为大写并去掉空白与连字符，"ab-123 c" 与 "AB123C" 视为同一车牌。
+func NormalizePlate(plate string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || r == '-' {
+			return -1
+		}
+		return unicode.ToUpper(r)
+	}, plate)
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gavin/airport-pickup/internal/domain/user/entity"
+)
+
+func TestVehicleService_RegisterVehicle(t *testing.T) {
+	s := &VehicleService{}
+	now := time.Date(2025, 11, 8, 10, 0, 0, 0, time.UTC)
+	valid := RegisterVehicleCmd{DriverID: "d1", Plate: " 沪a-12345 ", Make: "Toyota", Model: "Camry", Colour: "white",
+		Class: " Sedan", Seats: 4, LuggageCapacity: 3, CreatedAt: now}
+	v, err := s.RegisterVehicle(&valid)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v.Plate != "沪A12345" || v.Class != "sedan" || v.Status != entity.VehiclePendingVerification {
+		t.Errorf("unexpected vehicle %+v", v)
+	}
+
+	cases := map[string]func(c *RegisterVehicleCmd){
+		"missing owner":  func(c *RegisterVehicleCmd) { c.DriverID = "" },
+		"short plate":    func(c *RegisterVehicleCmd) { c.Plate = " a " },
+		"missing model":  func(c *RegisterVehicleCmd) { c.Model = " " },
+		"missing colour": func(c *RegisterVehicleCmd) { c.Colour = "" },
+		"missing class":  func(c *RegisterVehicleCmd) { c.Class = "" },
+		"no seats":       func(c *RegisterVehicleCmd) { c.Seats = 0 },
+		"luggage":        func(c *RegisterVehicleCmd) { c.LuggageCapacity = -1 },
+	}
+	for name, mutate := range cases {
+		cmd := valid
+		mutate(&cmd)
+		if _, err := s.RegisterVehicle(&cmd); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// DriverComplianceChecker 暂停证件即将到期的司机，返回暂停人数。
+type DriverComplianceChecker interface {
+	SuspendExpiringDrivers() (int, error)
+}
+
+// DriverComplianceWorker 按固定间隔检查司机证件有效期。
+type DriverComplianceWorker struct {
+	checker  DriverComplianceChecker
+	interval time.Duration
+}
+
+func NewDriverComplianceWorker(checker DriverComplianceChecker, interval time.Duration) *DriverComplianceWorker {
+	return &DriverComplianceWorker{checker: checker, interval: interval}
+}
+
+// Run 阻塞运行直到 ctx 结束；启动时先检查一轮，避免服务重启错过到期时间。
+func (w *DriverComplianceWorker) Run(ctx context.Context) {
+	w.RunOnce()
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.RunOnce()
+		}
+	}
+}
+
+// RunOnce 执行一轮检查。
+func (w *DriverComplianceWorker) RunOnce() {
+	n, err := w.checker.SuspendExpiringDrivers()
+	if err != nil {
+		log.Printf("[compliance] check finished with errors: suspended=%d: %v", n, err)
+		return
+	}
+	if n > 0 {
+		log.Printf("[compliance] check finished: suspended=%d", n)
+	}
+}
@@ -1,81 +1,206 @@
 package worker
 
 import (
+	"fmt"
 	evt "github.com/gavin/airport-pickup/internal/domain/eventbus"
 	"log"
 )
 
 type SettlementOrchestrator interface {
+	OnOrderMatched(bookingID string) error
 	OnOrderCompleted(bookingID string) error
+	OnOrderCancelled(bookingID, reason string) error
+	OnPickupRequestClosed(requestID string) error
+}
+
+// BookingCanceller 取消订单，用于预授权失败后的补偿。
+type BookingCanceller interface {
+	CancelBooking(id, reason string) error
 }
 
 type Consumer struct {
 	worker *OrderWorkerService
 }
 
-func NewEventConsumer(bus evt.EventBus, settlement SettlementOrchestrator, worker *OrderWorkerService) *Consumer {
+// NewEventConsumer 订阅领域事件。处理器返回错误时由事件总线负责重试与死信投递。
+func NewEventConsumer(bus evt.EventBus, settlement SettlementOrchestrator, worker *OrderWorkerService, orders BookingCanceller) *Consumer {
 	c := &Consumer{worker: worker}
+	SubscribeSettlement(bus, settlement)
+	SubscribeMatching(bus, worker)
+	SubscribePaymentFailures(bus, orders)
+	return c
+}
+
+// SubscribeSettlement 订阅结算相关事件。
+func SubscribeSettlement(bus evt.EventBus, settlement SettlementOrchestrator) {
+	// 支付预授权：订单匹配
+	bus.Subscribe(evt.EventOrderMatched, func(e evt.Event) error {
+		log.Printf("[event_consumer] handle event: %s, value: %+v", e.Name(), e)
+		ev, ok := e.(evt.OrderMatched)
+		if !ok {
+			return fmt.Errorf("event type assertion failed: %T", e)
+		}
+		if err := settlement.OnOrderMatched(ev.BookingID); err != nil {
+			log.Printf("[event_consumer] settlement OnOrderMatched failed: %v", err)
+			return err
+		}
+		log.Printf("[event_consumer] settlement OnOrderMatched success, bookingID=%s", ev.BookingID)
+		return nil
+	})
+	// 撤销预授权：订单取消
+	bus.Subscribe(evt.EventOrderCancelled, func(e evt.Event) error {
+		log.Printf("[event_consumer] handle event: %s, value: %+v", e.Name(), e)
+		ev, ok := e.(evt.OrderCancelled)
+		if !ok {
+			return fmt.Errorf("event type assertion failed: %T", e)
+		}
+		if err := settlement.OnOrderCancelled(ev.BookingID, ev.Reason); err != nil {
+			log.Printf("[event_consumer] OnOrderCancelled failed: %v", err)
+			return err
+		}
+		log.Printf("[event_consumer] OnOrderCancelled success, bookingID=%s", ev.BookingID)
+		return nil
+	})
 	// 结算编排：订单完成
-	bus.Subscribe(evt.EventOrderCompleted, func(e evt.Event) {
+	bus.Subscribe(evt.EventOrderCompleted, func(e evt.Event) error {
+		log.Printf("[event_consumer] handle event: %s, value: %+v", e.Name(), e)
+		oc, ok := e.(evt.OrderCompleted)
+		if !ok {
+			return fmt.Errorf("event type assertion failed: %T", e)
+		}
+		if err := settlement.OnOrderCompleted(oc.BookingID); err != nil {
+			log.Printf("[event_consumer] OnOrderCompleted failed: %v", err)
+			return err
+		}
+		log.Printf("[event_consumer] OnOrderCompleted success, bookingID=%s", oc.BookingID)
+		return nil
+	})
+	// 释放押金：接机请求被撤回或过期
+	bus.Subscribe(evt.EventPickupRequestClosed, func(e evt.Event) error {
+		ev, ok := e.(evt.PickupRequestClosed)
+		if !ok {
+			return fmt.Errorf("event type assertion failed: %T", e)
+		}
+		if err := settlement.OnPickupRequestClosed(ev.RequestID); err != nil {
+			log.Printf("[event_consumer] OnPickupRequestClosed failed: %v", err)
+			return err
+		}
+		return nil
+	})
+}
+
+// SubscribePaymentFailures 预授权失败时取消订单，释放乘客与司机。
+func SubscribePaymentFailures(bus evt.EventBus, orders BookingCanceller) {
+	bus.Subscribe(evt.EventPaymentAuthFailed, func(e evt.Event) error {
 		log.Printf("[event_consumer] handle event: %s, value: %+v", e.Name(), e)
-		if oc, ok := e.(evt.OrderCompleted); ok {
-			err := settlement.OnOrderCompleted(oc.BookingID)
-			if err != nil {
-				log.Printf("[event_consumer] OnOrderCompleted failed: %v", err)
-			} else {
-				log.Printf("[event_consumer] OnOrderCompleted success, bookingID=%s", oc.BookingID)
-			}
-		} else {
-			log.Printf("[event_consumer] event type assertion failed: %T", e)
+		ev, ok := e.(evt.PaymentAuthorizationFailed)
+		if !ok {
+			return fmt.Errorf("event type assertion failed: %T", e)
 		}
+		if err := orders.CancelBooking(ev.BookingID, "payment_authorization_failed"); err != nil {
+			log.Printf("[event_consumer] CancelBooking failed: %v", err)
+			return err
+		}
+		log.Printf("[event_consumer] CancelBooking success, bookingID=%s", ev.BookingID)
+		return nil
 	})
+}
+
+// SubscribeMatching 订阅撮合相关事件；worker 为 nil 时处理器直接返回。
+func SubscribeMatching(bus evt.EventBus, worker *OrderWorkerService) {
 	// 撮合：接机请求创建
-	bus.Subscribe(evt.EventPickupRequestCreated, func(e evt.Event) {
+	bus.Subscribe(evt.EventPickupRequestCreated, func(e evt.Event) error {
 		log.Printf("[event_consumer] handle event: %s, value: %+v", e.Name(), e)
-		if ev, ok := e.(evt.PickupRequestCreated); ok {
-			if c.worker != nil {
-				err := c.worker.OnPickupRequestCreated(ev)
-				if err != nil {
-					log.Printf("[event_consumer] OnPickupRequestCreated failed: %v", err)
-				} else {
-					log.Printf("[event_consumer] OnPickupRequestCreated success, requestID=%s", ev.RequestID)
-				}
-			}
-		} else {
-			log.Printf("[event_consumer] event type assertion failed: %T", e)
+		ev, ok := e.(evt.PickupRequestCreated)
+		if !ok {
+			return fmt.Errorf("event type assertion failed: %T", e)
 		}
+		if worker == nil {
+			return nil
+		}
+		if err := worker.OnPickupRequestCreated(ev); err != nil {
+			log.Printf("[event_consumer] OnPickupRequestCreated failed: %v", err)
+			return err
+		}
+		log.Printf("[event_consumer] OnPickupRequestCreated success, requestID=%s", ev.RequestID)
+		return nil
 	})
 	// 撮合：司机报价创建
-	bus.Subscribe(evt.EventDriverOfferCreated, func(e evt.Event) {
+	bus.Subscribe(evt.EventDriverOfferCreated, func(e evt.Event) error {
 		log.Printf("[event_consumer] handle event: %s, value: %+v", e.Name(), e)
-		if ev, ok := e.(evt.DriverOfferCreated); ok {
-			if c.worker != nil {
-				err := c.worker.OnDriverOfferCreated(ev)
-				if err != nil {
-					log.Printf("[event_consumer] OnDriverOfferCreated failed: %v", err)
-				} else {
-					log.Printf("[event_consumer] OnDriverOfferCreated success, offerID=%s", ev.OfferID)
-				}
-			}
-		} else {
-			log.Printf("[event_consumer] event type assertion failed: %T", e)
+		ev, ok := e.(evt.DriverOfferCreated)
+		if !ok {
+			return fmt.Errorf("event type assertion failed: %T", e)
+		}
+		if worker == nil {
+			return nil
+		}
+		if err := worker.OnDriverOfferCreated(ev); err != nil {
+			log.Printf("[event_consumer] OnDriverOfferCreated failed: %v", err)
+			return err
+		}
+		log.Printf("[event_consumer] OnDriverOfferCreated success, offerID=%s", ev.OfferID)
+		return nil
+	})
+	// 撮合：司机声誉分更新，同步内存订单簿中的报价评分
+	bus.Subscribe(evt.EventDriverRatingUpdated, func(e evt.Event) error {
+		ev, ok := e.(evt.DriverRatingUpdated)
+		if !ok {
+			return fmt.Errorf("event type assertion failed: %T", e)
+		}
+		if worker == nil {
+			return nil
+		}
+		return worker.OnDriverRatingUpdated(ev)
+	})
+	// 撮合：司机被暂停或退出平台，移除订单簿中的报价
+	bus.Subscribe(evt.EventDriverStatusChanged, func(e evt.Event) error {
+		ev, ok := e.(evt.DriverStatusChanged)
+		if !ok {
+			return fmt.Errorf("event type assertion failed: %T", e)
+		}
+		if worker == nil {
+			return nil
+		}
+		return worker.OnDriverStatusChanged(ev)
+	})
+	// 撮合：接机请求被撤回或过期，移除订单簿中的请求
+	bus.Subscribe(evt.EventPickupRequestClosed, func(e evt.Event) error {
+		ev, ok := e.(evt.PickupRequestClosed)
+		if !ok {
+			return fmt.Errorf("event type assertion failed: %T", e)
+		}
+		if worker == nil {
+			return nil
 		}
+		return worker.OnPickupRequestClosed(ev)
+	})
+	// 撮合：乘客声誉分更新，同步内存订单簿中的请求乘客分
+	bus.Subscribe(evt.EventPassengerRatingUpdated, func(e evt.Event) error {
+		ev, ok := e.(evt.PassengerRatingUpdated)
+		if !ok {
+			return fmt.Errorf("event type assertion failed: %T", e)
+		}
+		if worker == nil {
+			return nil
+		}
+		return worker.OnPassengerRatingUpdated(ev)
 	})
 	// 撮合：订单匹配完成，清理内存与 Redis
-	bus.Subscribe(evt.EventOrderMatched, func(e evt.Event) {
+	bus.Subscribe(evt.EventOrderMatched, func(e evt.Event) error {
 		log.Printf("[event_consumer] handle event: %s, value: %+v", e.Name(), e)
-		if ev, ok := e.(evt.OrderMatched); ok {
-			if c.worker != nil {
-				err := c.worker.OnOrderMatched(ev)
-				if err != nil {
-					log.Printf("[event_consumer] OnOrderMatched failed: %v", err)
-				} else {
-					log.Printf("[event_consumer] OnOrderMatched success, bookingID=%s", ev.BookingID)
-				}
-			}
-		} else {
-			log.Printf("[event_consumer] event type assertion failed: %T", e)
+		ev, ok := e.(evt.OrderMatched)
+		if !ok {
+			return fmt.Errorf("event type assertion failed: %T", e)
+		}
+		if worker == nil {
+			return nil
 		}
+		if err := worker.OnOrderMatched(ev); err != nil {
+			log.Printf("[event_consumer] OnOrderMatched failed: %v", err)
+			return err
+		}
+		log.Printf("[event_consumer] OnOrderMatched success, bookingID=%s", ev.BookingID)
+		return nil
 	})
-	return c
 }
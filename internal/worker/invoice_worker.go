@@ -0,0 +1,52 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/gavin/airport-pickup/internal/app/dto"
+)
+
+// InvoiceRunner 为企业客户开具上月发票。
+type InvoiceRunner interface {
+	RunMonthlyInvoices() (dto.InvoiceRunDTO, error)
+}
+
+// InvoiceWorker 按固定间隔检查并开具企业客户的月度发票；已开具的发票不会重复开具，
+// 间隔小于一个月即可保证每月开票。
+type InvoiceWorker struct {
+	runner   InvoiceRunner
+	interval time.Duration
+}
+
+func NewInvoiceWorker(runner InvoiceRunner, interval time.Duration) *InvoiceWorker {
+	return &InvoiceWorker{runner: runner, interval: interval}
+}
+
+// Run 启动时先执行一次，之后阻塞运行直到 ctx 结束。
+func (w *InvoiceWorker) Run(ctx context.Context) {
+	w.RunOnce()
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.RunOnce()
+		}
+	}
+}
+
+// RunOnce 执行一轮月度开票。
+func (w *InvoiceWorker) RunOnce() {
+	res, err := w.runner.RunMonthlyInvoices()
+	if err != nil {
+		log.Printf("[invoice] run for %s finished with errors: issued=%d: %v", res.Period, len(res.Invoices), err)
+		return
+	}
+	if len(res.Invoices) > 0 {
+		log.Printf("[invoice] run for %s finished: issued=%d", res.Period, len(res.Invoices))
+	}
+}
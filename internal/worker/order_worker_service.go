@@ -2,15 +2,17 @@ package worker
 
 import (
 	"context"
-	"fmt"
+	"errors"
 	"github.com/emirpasic/gods/trees/redblacktree"
 	evt "github.com/gavin/airport-pickup/internal/domain/eventbus"
+	"github.com/gavin/airport-pickup/internal/domain/money"
 	order "github.com/gavin/airport-pickup/internal/domain/order"
 	orderentity "github.com/gavin/airport-pickup/internal/domain/order/entity"
 	"github.com/gavin/airport-pickup/internal/domain/order/service"
-	"github.com/gavin/airport-pickup/pkg/redisstore"
+	userentity "github.com/gavin/airport-pickup/internal/domain/user/entity"
 	"github.com/gavin/airport-pickup/pkg/util"
 	"sync"
+	"time"
 )
 
 // 轻量级有序容器，模拟红黑树必要接口
@@ -23,6 +25,7 @@ type rbItem interface {
 
 type rbTree struct {
 	tree *redblacktree.Tree // key:int64, value:[]rbItem
+	size int                // 条目总数
 }
 
 func int64Comparator(a, b interface{}) int {
@@ -54,6 +57,7 @@ func (t *rbTree) ReplaceOrInsert(it rbItem) {
 	} else {
 		t.tree.Put(key, []rbItem{it})
 	}
+	t.size++
 }
 
 func (t *rbTree) Delete(it rbItem) {
@@ -77,6 +81,7 @@ func (t *rbTree) Delete(it rbItem) {
 		return
 	}
 	lst = append(lst[:idx], lst[idx+1:]...)
+	t.size--
 	if len(lst) == 0 {
 		t.tree.Remove(key)
 	} else {
@@ -84,20 +89,30 @@ func (t *rbTree) Delete(it rbItem) {
 	}
 }
 
+// OrderBookStore 是订单簿的外部存储（Redis ZSET），由 redisstore.Client 实现。
+type OrderBookStore interface {
+	AddPickupRequest(ctx context.Context, airport, vehicle string, req any, maxPrice money.Money) error
+	AddDriverOffer(ctx context.Context, airport, vehicle string, offer any, price money.Money) error
+	RemovePickupRequest(ctx context.Context, airport, vehicle, requestID string) error
+	RemoveDriverOffer(ctx context.Context, airport, vehicle, offerID string) error
+}
+
 // OrderWorkerService 串联 Redis、内存订单簿与领域撮合服务。
 // 线程安全：使用全局互斥锁保护内存结构。
 type OrderWorkerService struct {
 	orderRepo order.OrderRepository
 	matching  service.MatchingService
 	bus       evt.EventBus
-	redis     *redisstore.Client
+	redis     OrderBookStore
+	surge     *service.SurgeEngine // 为 nil 时不计算溢价，订单倍率记为 1
 
 	mu           sync.RWMutex
 	requestBooks map[string]*rbTree // key: airport:vehicle -> requests tree
 	offerBooks   map[string]*rbTree // key: airport:vehicle -> offers tree
 }
 
-func NewOrderWorkerService(orderRepo order.OrderRepository, matching service.MatchingService, bus evt.EventBus, redis *redisstore.Client) *OrderWorkerService {
+// NewOrderWorkerService 创建撮合 worker；redis 为 nil 时仅维护内存订单簿。
+func NewOrderWorkerService(orderRepo order.OrderRepository, matching service.MatchingService, bus evt.EventBus, redis OrderBookStore) *OrderWorkerService {
 	return &OrderWorkerService{
 		orderRepo:    orderRepo,
 		matching:     matching,
@@ -108,6 +123,12 @@ func NewOrderWorkerService(orderRepo order.OrderRepository, matching service.Mat
 	}
 }
 
+// WithSurge 在订单簿变化时按供需比更新溢价倍率，目标倍率升至通知阈值时发布 SurgeStarted。
+func (s *OrderWorkerService) WithSurge(engine *service.SurgeEngine) *OrderWorkerService {
+	s.surge = engine
+	return s
+}
+
 // —— 内存订单簿条目 ——
 
 type requestItem struct{ v *orderentity.PickupRequest }
@@ -126,8 +147,9 @@ func (a requestItem) Equal(b rbItem) bool {
 
 type offerItem struct{ v *orderentity.DriverOffer }
 
+// Key 以分为单位的每公里价格，金额精确，相同价格落在同一节点。
 func (a offerItem) Key() int64 {
-	return int64(a.v.PricePerKm * 100)
+	return a.v.PricePerKm.Cents()
 }
 
 func (a offerItem) Equal(b rbItem) bool {
@@ -138,7 +160,15 @@ func (a offerItem) Equal(b rbItem) bool {
 	return a.v.ID == bb.v.ID
 }
 
-func bookKey(airport, vehicle string) string { return fmt.Sprintf("%s:%s", airport, vehicle) }
+func bookKey(airport, vehicle string) string { return evt.BookKey(airport, vehicle) }
+
+// eventCurrency 旧版 JSON 事件没有币种字段，按默认结算币种处理。
+func eventCurrency(c string) string {
+	if c == "" {
+		return money.DefaultCurrency
+	}
+	return c
+}
 
 func (s *OrderWorkerService) getOrCreateTrees(key string) (reqTree, offerTree *rbTree) {
 	s.mu.Lock()
@@ -174,11 +204,14 @@ func (s *OrderWorkerService) OnPickupRequestCreated(e evt.PickupRequestCreated)
 	}
 	// 2. 更新内存请求订单簿（红黑树）
 	req := &orderentity.PickupRequest{ID: e.RequestID, PassengerID: e.PassengerID, AirportCode: e.AirportCode, VehicleType: e.VehicleType,
-		DesiredTime: e.DesiredTime, MaxPricePerKm: e.MaxPricePerKm, PreferHighRating: e.PreferHighRating, Status: e.Status}
+		DesiredTime: e.DesiredTime, MaxPricePerKm: e.MaxPricePerKm, Currency: eventCurrency(e.Currency), PreferHighRating: e.PreferHighRating,
+		PromoCode: e.PromoCode, QuoteID: e.QuoteID, PassengerScore: e.PassengerScore, DepositCents: e.DepositCents,
+		PaymentMethodID: e.PaymentMethodID, Status: e.Status}
 	reqTree, offerTree := s.getOrCreateTrees(key)
 	s.mu.Lock()
 	reqTree.ReplaceOrInsert(requestItem{v: req})
 	s.mu.Unlock()
+	defer s.observeSurge(e.AirportCode, e.VehicleType)
 	// 3. 获取内存中的司机报价单进行匹配（只收集可能匹配的报价单）
 	candidates := s.collectOffers(offerTree, req)
 	if len(candidates) == 0 {
@@ -188,8 +221,12 @@ func (s *OrderWorkerService) OnPickupRequestCreated(e evt.PickupRequestCreated)
 	if err != nil {
 		return nil // 未匹配到，保持订单簿中的记录
 	}
-	// 4. 匹配成功：保存订单、发布事件
+	// 4. 匹配成功：保存订单、发布事件；请求已被撤回或过期时只移出订单簿
 	if err := s.onMatched(req, offer); err != nil {
+		if errors.Is(err, order.ErrPickupRequestClosed) {
+			s.removeRequest(reqTree, req)
+			return nil
+		}
 		return err
 	}
 	// 5. 清除内存中的请求、司机报价订单
@@ -206,12 +243,14 @@ func (s *OrderWorkerService) OnDriverOfferCreated(e evt.DriverOfferCreated) erro
 		_ = s.redis.AddDriverOffer(context.Background(), e.AirportCode, e.VehicleType, e, e.PricePerKm)
 	}
 	// 2. 更新内存司机报价订单簿（红黑树）
-	offer := &orderentity.DriverOffer{ID: e.OfferID, DriverID: e.DriverID, AirportCode: e.AirportCode, VehicleType: e.VehicleType,
-		AvailableFrom: e.AvailableFrom, AvailableTo: e.AvailableTo, PricePerKm: e.PricePerKm, Rating: e.Rating, Status: e.Status}
+	offer := &orderentity.DriverOffer{ID: e.OfferID, DriverID: e.DriverID, AirportCode: e.AirportCode, VehicleType: e.VehicleType, VehicleID: e.VehicleID,
+		AvailableFrom: e.AvailableFrom, AvailableTo: e.AvailableTo, PricePerKm: e.PricePerKm, Currency: eventCurrency(e.Currency), Rating: e.Rating,
+		MinPassengerScore: e.MinPassengerScore, Status: e.Status}
 	reqTree, offerTree := s.getOrCreateTrees(key)
 	s.mu.Lock()
 	offerTree.ReplaceOrInsert(offerItem{v: offer})
 	s.mu.Unlock()
+	defer s.observeSurge(e.AirportCode, e.VehicleType)
 	// 3. 获取内存中的请求订单进行匹配（只收集可能匹配的请求单）
 	requests := s.collectRequests(reqTree, offer)
 	if len(requests) == 0 {
@@ -223,6 +262,10 @@ func (s *OrderWorkerService) OnDriverOfferCreated(e evt.DriverOfferCreated) erro
 		if err == nil && of != nil {
 			if e.AirportCode == req.AirportCode && e.VehicleType == req.VehicleType {
 				if err := s.onMatched(req, of); err != nil {
+					if errors.Is(err, order.ErrPickupRequestClosed) {
+						s.removeRequest(reqTree, req)
+						continue
+					}
 					return err
 				}
 				// 5. 清除内存中的请求、司机报价订单
@@ -235,6 +278,134 @@ func (s *OrderWorkerService) OnDriverOfferCreated(e evt.DriverOfferCreated) erro
 	return nil
 }
 
+// OnDriverRatingUpdated 把司机新的声誉分同步到内存订单簿中该司机的报价。
+// 替换为新对象而不是原地修改，撮合中已取出的候选报价不受影响。
+func (s *OrderWorkerService) OnDriverRatingUpdated(e evt.DriverRatingUpdated) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, tree := range s.offerBooks {
+		it := tree.tree.Iterator()
+		for it.Next() {
+			lst := it.Value().([]rbItem)
+			for i, item := range lst {
+				offer := item.(offerItem).v
+				if offer.DriverID != e.DriverID {
+					continue
+				}
+				updated := *offer
+				updated.Rating = e.Rating
+				lst[i] = offerItem{v: &updated}
+			}
+		}
+	}
+	return nil
+}
+
+// OnDriverStatusChanged 司机被暂停或退出平台时，从内存与 Redis 订单簿移除其报价（数据库中的 open 报价已由应用层取消）。
+func (s *OrderWorkerService) OnDriverStatusChanged(e evt.DriverStatusChanged) error {
+	if e.Status == userentity.DriverStatusActive || e.Status == userentity.DriverStatusPending {
+		return nil
+	}
+	var removed []*orderentity.DriverOffer
+	s.mu.Lock()
+	for _, tree := range s.offerBooks {
+		var drop []*orderentity.DriverOffer
+		it := tree.tree.Iterator()
+		for it.Next() {
+			for _, item := range it.Value().([]rbItem) {
+				if offer := item.(offerItem).v; offer.DriverID == e.DriverID {
+					drop = append(drop, offer)
+				}
+			}
+		}
+		for _, offer := range drop {
+			tree.Delete(offerItem{v: offer})
+		}
+		removed = append(removed, drop...)
+	}
+	s.mu.Unlock()
+	books := make(map[string]*orderentity.DriverOffer)
+	for _, offer := range removed {
+		if s.redis != nil {
+			_ = s.redis.RemoveDriverOffer(context.Background(), offer.AirportCode, offer.VehicleType, offer.ID)
+		}
+		books[bookKey(offer.AirportCode, offer.VehicleType)] = offer
+	}
+	for _, offer := range books {
+		s.observeSurge(offer.AirportCode, offer.VehicleType)
+	}
+	return nil
+}
+
+// OnPickupRequestClosed 请求被乘客撤回或过期时，从内存与 Redis 订单簿移除该请求（数据库中的状态已由应用层更新）。
+func (s *OrderWorkerService) OnPickupRequestClosed(e evt.PickupRequestClosed) error {
+	reqTree, _ := s.getTrees(bookKey(e.AirportCode, e.VehicleType))
+	if reqTree != nil {
+		s.mu.Lock()
+		var found *orderentity.PickupRequest
+		it := reqTree.tree.Iterator()
+		for found == nil && it.Next() {
+			for _, item := range it.Value().([]rbItem) {
+				if req := item.(requestItem).v; req.ID == e.RequestID {
+					found = req
+					break
+				}
+			}
+		}
+		if found != nil {
+			reqTree.Delete(requestItem{v: found})
+		}
+		s.mu.Unlock()
+	}
+	if s.redis != nil {
+		_ = s.redis.RemovePickupRequest(context.Background(), e.AirportCode, e.VehicleType, e.RequestID)
+	}
+	s.observeSurge(e.AirportCode, e.VehicleType)
+	return nil
+}
+
+// OnPassengerRatingUpdated 把乘客新的声誉分同步到内存订单簿中该乘客的请求，做法同 OnDriverRatingUpdated。
+func (s *OrderWorkerService) OnPassengerRatingUpdated(e evt.PassengerRatingUpdated) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, tree := range s.requestBooks {
+		it := tree.tree.Iterator()
+		for it.Next() {
+			lst := it.Value().([]rbItem)
+			for i, item := range lst {
+				req := item.(requestItem).v
+				if req.PassengerID != e.PassengerID {
+					continue
+				}
+				updated := *req
+				updated.PassengerScore = e.ReputationScore
+				lst[i] = requestItem{v: &updated}
+			}
+		}
+	}
+	return nil
+}
+
+// OpenDriverOffers 内存订单簿中该机场与车型的报价快照，按每公里价格升序；返回副本，调用方可自由读取。
+// 内存订单簿只包含本实例消费的分区，仅适用于单实例部署；多实例部署使用 SharedOfferBook。
+func (s *OrderWorkerService) OpenDriverOffers(airportCode, vehicleType string) ([]*orderentity.DriverOffer, error) {
+	_, tree := s.getTrees(bookKey(airportCode, vehicleType))
+	res := make([]*orderentity.DriverOffer, 0)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if tree == nil {
+		return res, nil
+	}
+	it := tree.tree.Iterator()
+	for it.Next() {
+		for _, item := range it.Value().([]rbItem) {
+			offer := *item.(offerItem).v
+			res = append(res, &offer)
+		}
+	}
+	return res, nil
+}
+
 // collectOffers 根据请求初步过滤报价单，提升撮合效率
 func (s *OrderWorkerService) collectOffers(tree *rbTree, req *orderentity.PickupRequest) []*orderentity.DriverOffer {
 	res := make([]*orderentity.DriverOffer, 0)
@@ -244,7 +415,7 @@ func (s *OrderWorkerService) collectOffers(tree *rbTree, req *orderentity.Pickup
 		return res
 	}
 	// 只遍历价格 <= req.MaxPricePerKm 的报价单
-	maxPriceKey := int64(req.MaxPricePerKm * 100)
+	maxPriceKey := req.MaxPricePerKm.Cents()
 	it := tree.tree.Iterator()
 	for it.Next() {
 		k := it.Key().(int64)
@@ -261,6 +432,10 @@ func (s *OrderWorkerService) collectOffers(tree *rbTree, req *orderentity.Pickup
 			if offer.VehicleType != req.VehicleType {
 				continue
 			}
+			// 价格键只在同一币种内可比
+			if offer.Currency != req.Currency {
+				continue
+			}
 			if req.DesiredTime.Before(offer.AvailableFrom) || req.DesiredTime.After(offer.AvailableTo) {
 				continue
 			}
@@ -326,7 +501,11 @@ func (s *OrderWorkerService) removeOffer(tree *rbTree, offer *orderentity.Driver
 
 // onMatched 保存 Booking、更新请求并发布事件
 func (s *OrderWorkerService) onMatched(req *orderentity.PickupRequest, offer *orderentity.DriverOffer) error {
-	b := s.matching.CreateBooking(req, offer, util.NewID)
+	b, err := s.matching.CreateBooking(req, offer, util.NewID)
+	if err != nil {
+		return err
+	}
+	b.SurgeMultiplier = s.currentSurge(req.AirportCode, req.VehicleType)
 	// 先变更领域对象状态
 	if err := req.MarkMatched(); err != nil {
 		return err
@@ -334,8 +513,9 @@ func (s *OrderWorkerService) onMatched(req *orderentity.PickupRequest, offer *or
 	if err := offer.MarkMatched(); err != nil {
 		return err
 	}
-	// 用事务保存三对象
+	// 用事务保存三对象；失败时恢复内存中的状态，报价仍可与其他请求撮合
 	if err := s.orderRepo.UpdateAllInTransaction(b, req, offer); err != nil {
+		req.Status, offer.Status = "open", "open"
 		return err
 	}
 	// 发送匹配成功消息
@@ -381,6 +561,9 @@ func (s *OrderWorkerService) OnOrderMatched(e evt.OrderMatched) error {
 			s.removeOffer(offerTree, offer)
 		}
 	}
+	if airport != "" && vehicle != "" {
+		s.observeSurge(airport, vehicle)
+	}
 	// Redis 清理（幂等）
 	if s.redis != nil && airport != "" && vehicle != "" {
 		_ = s.redis.RemovePickupRequest(context.Background(), airport, vehicle, e.RequestID)
@@ -388,3 +571,44 @@ func (s *OrderWorkerService) OnOrderMatched(e evt.OrderMatched) error {
 	}
 	return nil
 }
+
+// observeSurge 把订单簿当前的请求数、报价数与最低报价交给溢价引擎，目标倍率升至通知阈值时发布 SurgeStarted。
+func (s *OrderWorkerService) observeSurge(airport, vehicle string) {
+	if s.surge == nil {
+		return
+	}
+	key := bookKey(airport, vehicle)
+	reqTree, offerTree := s.getTrees(key)
+	var snap service.BookSnapshot
+	s.mu.RLock()
+	if reqTree != nil {
+		snap.OpenRequests = reqTree.size
+	}
+	if offerTree != nil {
+		snap.OpenOffers = offerTree.size
+		// 报价树按每公里价格排序，最左节点即最低报价
+		if n := offerTree.tree.Left(); n != nil {
+			if lst := n.Value.([]rbItem); len(lst) > 0 {
+				best := lst[0].(offerItem).v
+				snap.BestOfferPerKm, snap.Currency = best.PricePerKm, best.Currency
+			}
+		}
+	}
+	s.mu.RUnlock()
+	sg, notify := s.surge.Observe(key, snap)
+	if notify && s.bus != nil {
+		s.bus.Publish(evt.SurgeStarted{
+			AirportCode: airport, VehicleType: vehicle, Multiplier: sg.Multiplier, TargetMultiplier: sg.TargetMultiplier,
+			OpenRequests: sg.OpenRequests, OpenOffers: sg.OpenOffers, SuggestedBidPerKm: sg.SuggestedBidPerKm, Currency: sg.Currency,
+			OccurredAt: time.Now().UTC(),
+		})
+	}
+}
+
+// currentSurge 订单成交时生效的溢价倍率。
+func (s *OrderWorkerService) currentSurge(airport, vehicle string) float64 {
+	if s.surge == nil {
+		return 1
+	}
+	return s.surge.Current(bookKey(airport, vehicle)).Multiplier
+}
@@ -0,0 +1,65 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+
+	evt "github.com/gavin/airport-pickup/internal/domain/eventbus"
+	order "github.com/gavin/airport-pickup/internal/domain/order"
+)
+
+// OrderBookProjection 只维护 Redis 订单簿投影而不做撮合，用于事件重放时重建 Redis 状态。
+// 写入均为幂等操作，可重复重放。
+type OrderBookProjection struct {
+	orderRepo order.OrderRepository
+	store     OrderBookStore
+}
+
+func NewOrderBookProjection(orderRepo order.OrderRepository, store OrderBookStore) *OrderBookProjection {
+	return &OrderBookProjection{orderRepo: orderRepo, store: store}
+}
+
+// SubscribeOrderBookProjection 订阅订单簿投影相关事件。
+func SubscribeOrderBookProjection(bus evt.EventBus, p *OrderBookProjection) {
+	bus.Subscribe(evt.EventPickupRequestCreated, func(e evt.Event) error {
+		ev, ok := e.(evt.PickupRequestCreated)
+		if !ok {
+			return fmt.Errorf("event type assertion failed: %T", e)
+		}
+		return p.store.AddPickupRequest(context.Background(), ev.AirportCode, ev.VehicleType, ev, ev.MaxPricePerKm)
+	})
+	bus.Subscribe(evt.EventDriverOfferCreated, func(e evt.Event) error {
+		ev, ok := e.(evt.DriverOfferCreated)
+		if !ok {
+			return fmt.Errorf("event type assertion failed: %T", e)
+		}
+		return p.store.AddDriverOffer(context.Background(), ev.AirportCode, ev.VehicleType, ev, ev.PricePerKm)
+	})
+	bus.Subscribe(evt.EventPickupRequestClosed, func(e evt.Event) error {
+		ev, ok := e.(evt.PickupRequestClosed)
+		if !ok {
+			return fmt.Errorf("event type assertion failed: %T", e)
+		}
+		return p.store.RemovePickupRequest(context.Background(), ev.AirportCode, ev.VehicleType, ev.RequestID)
+	})
+	bus.Subscribe(evt.EventOrderMatched, func(e evt.Event) error {
+		ev, ok := e.(evt.OrderMatched)
+		if !ok {
+			return fmt.Errorf("event type assertion failed: %T", e)
+		}
+		return p.OnOrderMatched(ev)
+	})
+}
+
+// OnOrderMatched 从 Redis 订单簿移除已成交的请求与报价。
+func (p *OrderBookProjection) OnOrderMatched(e evt.OrderMatched) error {
+	req, err := p.orderRepo.GetPickupRequestByID(e.RequestID)
+	if err != nil {
+		return fmt.Errorf("get pickup request %s: %w", e.RequestID, err)
+	}
+	ctx := context.Background()
+	if err := p.store.RemovePickupRequest(ctx, req.AirportCode, req.VehicleType, e.RequestID); err != nil {
+		return err
+	}
+	return p.store.RemoveDriverOffer(ctx, req.AirportCode, req.VehicleType, e.DriverOfferID)
+}
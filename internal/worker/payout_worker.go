@@ -0,0 +1,50 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/gavin/airport-pickup/internal/app/dto"
+)
+
+// PayoutRunner 执行一轮司机打款。
+type PayoutRunner interface {
+	RunPayouts() (dto.PayoutRunDTO, error)
+}
+
+// PayoutWorker 按固定间隔执行司机打款。
+type PayoutWorker struct {
+	runner   PayoutRunner
+	interval time.Duration
+}
+
+func NewPayoutWorker(runner PayoutRunner, interval time.Duration) *PayoutWorker {
+	return &PayoutWorker{runner: runner, interval: interval}
+}
+
+// Run 阻塞运行直到 ctx 结束。
+func (w *PayoutWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.RunOnce()
+		}
+	}
+}
+
+// RunOnce 执行一轮打款。
+func (w *PayoutWorker) RunOnce() {
+	res, err := w.runner.RunPayouts()
+	if err != nil {
+		log.Printf("[payout] run finished with errors: paid=%d failed=%d pending=%d: %v", res.Paid, res.Failed, res.Pending, err)
+		return
+	}
+	if len(res.Payouts) > 0 {
+		log.Printf("[payout] run finished: paid=%d failed=%d pending=%d", res.Paid, res.Failed, res.Pending)
+	}
+}
@@ -0,0 +1,49 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// PickupRequestExpirer 关闭期望接机时间已过仍未撮合的请求，返回关闭的请求数。
+type PickupRequestExpirer interface {
+	ExpirePickupRequests() (int, error)
+}
+
+// RequestExpiryWorker 按固定间隔关闭过期的接机请求并释放其押金。
+type RequestExpiryWorker struct {
+	expirer  PickupRequestExpirer
+	interval time.Duration
+}
+
+func NewRequestExpiryWorker(expirer PickupRequestExpirer, interval time.Duration) *RequestExpiryWorker {
+	return &RequestExpiryWorker{expirer: expirer, interval: interval}
+}
+
+// Run 阻塞运行直到 ctx 结束；启动时先检查一轮，关闭停机期间过期的请求。
+func (w *RequestExpiryWorker) Run(ctx context.Context) {
+	w.RunOnce()
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.RunOnce()
+		}
+	}
+}
+
+// RunOnce 执行一轮检查。
+func (w *RequestExpiryWorker) RunOnce() {
+	n, err := w.expirer.ExpirePickupRequests()
+	if err != nil {
+		log.Printf("[request_expiry] check finished with errors: expired=%d: %v", n, err)
+		return
+	}
+	if n > 0 {
+		log.Printf("[request_expiry] check finished: expired=%d", n)
+	}
+}
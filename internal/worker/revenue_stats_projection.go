@@ -0,0 +1,41 @@
+package worker
+
+import (
+	"fmt"
+	"log"
+
+	evt "github.com/gavin/airport-pickup/internal/domain/eventbus"
+)
+
+// RevenueStatsProjector 把订单的结算记录计入收入统计聚合表，须可重复执行。
+type RevenueStatsProjector interface {
+	ProjectBooking(bookingID string) error
+}
+
+// SubscribeRevenueStats 订阅结算与收入变动事件，增量更新收入统计。
+// 两个事件均按订单重新检查未计入的结算记录，重复投递不会重复计入。
+func SubscribeRevenueStats(bus evt.EventBus, p RevenueStatsProjector) {
+	project := func(bookingID string) error {
+		if err := p.ProjectBooking(bookingID); err != nil {
+			log.Printf("[revenue_stats] project booking %s failed: %v", bookingID, err)
+			return err
+		}
+		return nil
+	}
+	// 订单结算完成
+	bus.Subscribe(evt.EventSettlementCreated, func(e evt.Event) error {
+		ev, ok := e.(evt.SettlementCreated)
+		if !ok {
+			return fmt.Errorf("event type assertion failed: %T", e)
+		}
+		return project(ev.BookingID)
+	})
+	// 退款冲减收入
+	bus.Subscribe(evt.EventRevenueUpdated, func(e evt.Event) error {
+		ev, ok := e.(evt.RevenueUpdated)
+		if !ok {
+			return fmt.Errorf("event type assertion failed: %T", e)
+		}
+		return project(ev.BookingID)
+	})
+}
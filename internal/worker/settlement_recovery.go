@@ -0,0 +1,52 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// SagaResumer 续跑卡住的结算 saga。
+type SagaResumer interface {
+	ResumeStuckSagas(before time.Time, limit int) (int, error)
+}
+
+// SettlementRecoveryWorker 定期续跑长时间未推进的结算 saga：
+// 进程在步骤之间崩溃、事件重试耗尽或退款失败的 saga 都由它继续推进。
+type SettlementRecoveryWorker struct {
+	resumer    SagaResumer
+	interval   time.Duration
+	staleAfter time.Duration
+	batch      int
+}
+
+// NewSettlementRecoveryWorker staleAfter 应明显大于单次结算耗时，避免与正在处理的消费者并发推进同一 saga。
+func NewSettlementRecoveryWorker(resumer SagaResumer, interval, staleAfter time.Duration) *SettlementRecoveryWorker {
+	return &SettlementRecoveryWorker{resumer: resumer, interval: interval, staleAfter: staleAfter, batch: 100}
+}
+
+// Run 阻塞运行直到 ctx 结束。
+func (w *SettlementRecoveryWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.RunOnce()
+		}
+	}
+}
+
+// RunOnce 执行一轮恢复。
+func (w *SettlementRecoveryWorker) RunOnce() {
+	n, err := w.resumer.ResumeStuckSagas(time.Now().Add(-w.staleAfter), w.batch)
+	if err != nil {
+		log.Printf("[settlement_recovery] resumed %d saga(s) with errors: %v", n, err)
+		return
+	}
+	if n > 0 {
+		log.Printf("[settlement_recovery] resumed %d saga(s)", n)
+	}
+}
@@ -0,0 +1,45 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	evt "github.com/gavin/airport-pickup/internal/domain/eventbus"
+	orderentity "github.com/gavin/airport-pickup/internal/domain/order/entity"
+)
+
+// OfferBookReader 读取外部订单簿存储中的报价成员，由 redisstore.Client 实现。
+type OfferBookReader interface {
+	ListDriverOffers(ctx context.Context, airport, vehicle string) ([]string, error)
+}
+
+// SharedOfferBook 从 Redis 订单簿读取报价，供报价接口使用。
+// 内存订单簿只包含本实例消费的分区，多实例部署时各实例的 worker 共同维护 Redis 订单簿，
+// 任一实例都能据此看到机场与车型的全部报价。
+type SharedOfferBook struct {
+	store OfferBookReader
+}
+
+func NewSharedOfferBook(store OfferBookReader) *SharedOfferBook {
+	return &SharedOfferBook{store: store}
+}
+
+// OpenDriverOffers 返回 Redis 订单簿中该机场与车型的报价，按每公里价格升序；无法解析的成员被跳过。
+func (b *SharedOfferBook) OpenDriverOffers(airportCode, vehicleType string) ([]*orderentity.DriverOffer, error) {
+	members, err := b.store.ListDriverOffers(context.Background(), airportCode, vehicleType)
+	if err != nil {
+		return nil, fmt.Errorf("read order book %s: %w", evt.BookKey(airportCode, vehicleType), err)
+	}
+	res := make([]*orderentity.DriverOffer, 0, len(members))
+	for _, m := range members {
+		var e evt.DriverOfferCreated
+		if err := json.Unmarshal([]byte(m), &e); err != nil || e.OfferID == "" {
+			continue
+		}
+		res = append(res, &orderentity.DriverOffer{ID: e.OfferID, DriverID: e.DriverID, AirportCode: e.AirportCode, VehicleType: e.VehicleType, VehicleID: e.VehicleID,
+			AvailableFrom: e.AvailableFrom, AvailableTo: e.AvailableTo, PricePerKm: e.PricePerKm, Currency: eventCurrency(e.Currency), Rating: e.Rating,
+			MinPassengerScore: e.MinPassengerScore, Status: e.Status})
+	}
+	return res, nil
+}
@@ -0,0 +1,43 @@
+package worker
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	evt "github.com/gavin/airport-pickup/internal/domain/eventbus"
+	order "github.com/gavin/airport-pickup/internal/domain/order"
+)
+
+// DriverNotifier 向司机推送消息，由推送渠道实现（本地为 notify.LogNotifier）。
+type DriverNotifier interface {
+	NotifyDriver(driverID, message string) error
+}
+
+// SubscribeSurgeNotifications 订阅 SurgeStarted，通知 lookback 内在该机场发布过同车型报价、当前空闲的司机。
+// 推送尽力而为：单个司机推送失败只记录日志，不重投事件，避免其他司机重复收到通知。
+func SubscribeSurgeNotifications(bus evt.EventBus, orders order.OrderRepository, notifier DriverNotifier, lookback time.Duration) {
+	bus.Subscribe(evt.EventSurgeStarted, func(e evt.Event) error {
+		ev, ok := e.(evt.SurgeStarted)
+		if !ok {
+			return fmt.Errorf("event type assertion failed: %T", e)
+		}
+		drivers, err := orders.ListIdleDriversAtAirport(ev.AirportCode, ev.VehicleType, ev.OccurredAt.Add(-lookback))
+		if err != nil {
+			log.Printf("[surge] list drivers for %s/%s failed: %v", ev.AirportCode, ev.VehicleType, err)
+			return err
+		}
+		msg := fmt.Sprintf("High demand at %s for %s: %d open requests, %d offers, surge x%.2f.",
+			ev.AirportCode, ev.VehicleType, ev.OpenRequests, ev.OpenOffers, ev.TargetMultiplier)
+		if !ev.SuggestedBidPerKm.IsZero() {
+			msg += fmt.Sprintf(" Suggested passenger bid: %s %s/km.", ev.SuggestedBidPerKm, ev.Currency)
+		}
+		for _, id := range drivers {
+			if err := notifier.NotifyDriver(id, msg); err != nil {
+				log.Printf("[surge] notify driver %s failed: %v", id, err)
+			}
+		}
+		log.Printf("[surge] %s/%s surge x%.2f, notified %d driver(s)", ev.AirportCode, ev.VehicleType, ev.TargetMultiplier, len(drivers))
+		return nil
+	})
+}
@@ -0,0 +1,145 @@
+package avro
+
+import (
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const offerV1 = `{
+  "type": "record", "name": "Offer", "namespace": "test",
+  "fields": [
+    {"name": "id", "type": "string"},
+    {"name": "price", "type": "float"},
+    {"name": "seats", "type": "int"},
+    {"name": "at", "type": {"type": "long", "logicalType": "timestamp-millis"}},
+    {"name": "note", "type": ["null", "string"], "default": null}
+  ]
+}`
+
+// v2: 新增带默认值的字段、price 提升为 double、删除带默认值的 note
+const offerV2 = `{
+  "type": "record", "name": "Offer", "namespace": "test",
+  "fields": [
+    {"name": "id", "type": "string"},
+    {"name": "price", "type": "double"},
+    {"name": "seats", "type": "int"},
+    {"name": "at", "type": {"type": "long", "logicalType": "timestamp-millis"}},
+    {"name": "currency", "type": "string", "default": "CNY"}
+  ]
+}`
+
+func mustParse(t *testing.T, src string) *Schema {
+	t.Helper()
+	s, err := Parse([]byte(src))
+	require.NoError(t, err)
+	return s
+}
+
+func TestEncodeDecode_RoundTrip(t *testing.T) {
+	s := mustParse(t, offerV1)
+	at := time.UnixMilli(1700000000123).UTC()
+	in := map[string]any{"id": "o1", "price": float32(3.5), "seats": int32(4), "at": at, "note": "vip"}
+	b, err := Encode(s, in)
+	require.NoError(t, err)
+
+	out, err := Decode(s, s, b)
+	require.NoError(t, err)
+	assert.Equal(t, in, out)
+
+	in["note"] = nil
+	b, err = Encode(s, in)
+	require.NoError(t, err)
+	out, err = Decode(s, s, b)
+	require.NoError(t, err)
+	assert.Nil(t, out.(map[string]any)["note"])
+}
+
+func TestEncode_MissingFieldWithoutDefault(t *testing.T) {
+	s := mustParse(t, offerV1)
+	_, err := Encode(s, map[string]any{"id": "o1"})
+	assert.Error(t, err)
+}
+
+func TestDecode_SchemaResolution(t *testing.T) {
+	v1, v2 := mustParse(t, offerV1), mustParse(t, offerV2)
+	at := time.UnixMilli(1700000000000).UTC()
+	b, err := Encode(v1, map[string]any{"id": "o1", "price": float32(3.5), "seats": int32(4), "at": at, "note": "vip"})
+	require.NoError(t, err)
+
+	// 新读者读旧数据：新增字段取默认值，数值提升，写入方独有字段被丢弃
+	out, err := Decode(v1, v2, b)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"id": "o1", "price": 3.5, "seats": int32(4), "at": at, "currency": "CNY"}, out)
+}
+
+func TestCheckCompatibility(t *testing.T) {
+	v1, v2 := mustParse(t, offerV1), mustParse(t, offerV2)
+	assert.NoError(t, CheckCompatibility(v2, v1))
+	// 旧读者无法把 double 读成 float
+	assert.Error(t, CheckCompatibility(v1, v2))
+
+	cases := map[string]string{
+		"field without default": `{"type":"record","name":"Offer","namespace":"test","fields":[
+			{"name":"id","type":"string"},{"name":"price","type":"float"},{"name":"seats","type":"int"},
+			{"name":"at","type":{"type":"long","logicalType":"timestamp-millis"}},{"name":"extra","type":"string"}]}`,
+		"type changed": `{"type":"record","name":"Offer","namespace":"test","fields":[
+			{"name":"id","type":"long"},{"name":"price","type":"float"},{"name":"seats","type":"int"},
+			{"name":"at","type":{"type":"long","logicalType":"timestamp-millis"}}]}`,
+		"logical type dropped": `{"type":"record","name":"Offer","namespace":"test","fields":[
+			{"name":"id","type":"string"},{"name":"price","type":"float"},{"name":"seats","type":"int"},
+			{"name":"at","type":"long"}]}`,
+		"renamed record": `{"type":"record","name":"Offer2","namespace":"test","fields":[]}`,
+	}
+	for name, src := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.Error(t, CheckCompatibility(mustParse(t, src), v1))
+		})
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, src := range []string{
+		`{"type":"record","fields":[]}`,
+		`{"type":"record","name":"A","fields":[{"name":"x","type":"decimal"}]}`,
+		`{"type":"record","name":"A","fields":[{"name":"x","type":"int"},{"name":"x","type":"int"}]}`,
+		`{"type":"record","name":"A","fields":[{"name":"x","type":"int","default":"1"}]}`,
+	} {
+		_, err := Parse([]byte(src))
+		assert.Error(t, err, src)
+	}
+}
+
+func TestRegistry_CheckAndLock(t *testing.T) {
+	fsys := fstest.MapFS{
+		"Offer/v1.avsc": {Data: []byte(offerV1)},
+		"Offer/v2.avsc": {Data: []byte(offerV2)},
+	}
+	reg, err := LoadRegistry(fsys)
+	require.NoError(t, err)
+	latest, ok := reg.Latest("Offer")
+	require.True(t, ok)
+	assert.Equal(t, "Offer/v2", latest.ID())
+	_, ok = reg.Lookup("Offer/v1")
+	assert.True(t, ok)
+
+	// v1 -> v2 只向后兼容，旧消费者读不了 double，完全兼容校验失败
+	assert.Error(t, reg.Check())
+
+	lock := reg.Lock()
+	assert.NoError(t, reg.VerifyLock(lock))
+
+	// 已发布版本被原地修改
+	fsys["Offer/v1.avsc"] = &fstest.MapFile{Data: []byte(offerV2)}
+	reg, err = LoadRegistry(fsys)
+	require.NoError(t, err)
+	assert.Error(t, reg.VerifyLock(lock))
+}
+
+func TestRegistry_NonContiguousVersions(t *testing.T) {
+	_, err := LoadRegistry(fstest.MapFS{"Offer/v2.avsc": {Data: []byte(offerV1)}})
+	assert.Error(t, err)
+}
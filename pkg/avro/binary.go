@@ -0,0 +1,397 @@
+package avro
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// 通用值与 Go 类型的对应关系：
+//
+//	null -> nil, boolean -> bool, int -> int32, long -> int64
+//	(timestamp-millis -> time.Time), float -> float32, double -> float64,
+//	string -> string, bytes -> []byte, record -> map[string]any
+//
+// union 直接使用所选分支的值，nil 对应 null 分支。
+
+// Encode 按 schema 将通用值编码为 Avro 二进制。
+func Encode(s *Schema, v any) ([]byte, error) {
+	return appendValue(nil, s, v)
+}
+
+func appendValue(buf []byte, s *Schema, v any) ([]byte, error) {
+	switch s.Type {
+	case Null:
+		if v != nil {
+			return nil, fmt.Errorf("avro: expected null, got %T", v)
+		}
+		return buf, nil
+	case Boolean:
+		b, ok := v.(bool)
+		if !ok {
+			return nil, fmt.Errorf("avro: expected boolean, got %T", v)
+		}
+		if b {
+			return append(buf, 1), nil
+		}
+		return append(buf, 0), nil
+	case Int:
+		n, ok := v.(int32)
+		if !ok {
+			return nil, fmt.Errorf("avro: expected int, got %T", v)
+		}
+		return binary.AppendVarint(buf, int64(n)), nil
+	case Long:
+		if t, ok := v.(time.Time); ok && s.LogicalType == LogicalTimestampMillis {
+			return binary.AppendVarint(buf, t.UnixMilli()), nil
+		}
+		n, ok := v.(int64)
+		if !ok {
+			return nil, fmt.Errorf("avro: expected long, got %T", v)
+		}
+		return binary.AppendVarint(buf, n), nil
+	case Float:
+		f, ok := v.(float32)
+		if !ok {
+			return nil, fmt.Errorf("avro: expected float, got %T", v)
+		}
+		return binary.LittleEndian.AppendUint32(buf, math.Float32bits(f)), nil
+	case Double:
+		f, ok := v.(float64)
+		if !ok {
+			return nil, fmt.Errorf("avro: expected double, got %T", v)
+		}
+		return binary.LittleEndian.AppendUint64(buf, math.Float64bits(f)), nil
+	case String:
+		str, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("avro: expected string, got %T", v)
+		}
+		buf = binary.AppendVarint(buf, int64(len(str)))
+		return append(buf, str...), nil
+	case Bytes:
+		b, ok := v.([]byte)
+		if !ok {
+			return nil, fmt.Errorf("avro: expected bytes, got %T", v)
+		}
+		buf = binary.AppendVarint(buf, int64(len(b)))
+		return append(buf, b...), nil
+	case Union:
+		for i, br := range s.Branches {
+			if !matches(br, v) {
+				continue
+			}
+			buf = binary.AppendVarint(buf, int64(i))
+			return appendValue(buf, br, v)
+		}
+		return nil, fmt.Errorf("avro: no union branch for %T", v)
+	case Record:
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("avro: expected record %s, got %T", s.Name, v)
+		}
+		var err error
+		for _, f := range s.Fields {
+			fv, ok := m[f.Name]
+			if !ok {
+				if !f.HasDefault {
+					return nil, fmt.Errorf("avro: missing field %s.%s", s.Name, f.Name)
+				}
+				if fv, err = defaultValue(f.Type, f.Default); err != nil {
+					return nil, err
+				}
+			}
+			if buf, err = appendValue(buf, f.Type, fv); err != nil {
+				return nil, fmt.Errorf("avro: field %s.%s: %w", s.Name, f.Name, err)
+			}
+		}
+		return buf, nil
+	}
+	return nil, fmt.Errorf("avro: cannot encode type %s", s.Type)
+}
+
+// matches 判断值是否可按 union 分支编码。
+func matches(s *Schema, v any) bool {
+	switch s.Type {
+	case Null:
+		return v == nil
+	case Boolean:
+		_, ok := v.(bool)
+		return ok
+	case Int:
+		_, ok := v.(int32)
+		return ok
+	case Long:
+		switch v.(type) {
+		case int64:
+			return true
+		case time.Time:
+			return s.LogicalType == LogicalTimestampMillis
+		}
+		return false
+	case Float:
+		_, ok := v.(float32)
+		return ok
+	case Double:
+		_, ok := v.(float64)
+		return ok
+	case String:
+		_, ok := v.(string)
+		return ok
+	case Bytes:
+		_, ok := v.([]byte)
+		return ok
+	case Record:
+		_, ok := v.(map[string]any)
+		return ok
+	}
+	return false
+}
+
+// Decode 用写入方 schema 解码数据，并按读取方 schema 解析：
+// 写入方独有的字段被丢弃，读取方新增的字段取默认值，数值按 Avro 规则提升。
+func Decode(writer, reader *Schema, data []byte) (any, error) {
+	if err := CheckCompatibility(reader, writer); err != nil {
+		return nil, err
+	}
+	d := &decoder{buf: data}
+	v, err := d.value(writer)
+	if err != nil {
+		return nil, err
+	}
+	if len(d.buf) != 0 {
+		return nil, fmt.Errorf("avro: %d trailing bytes", len(d.buf))
+	}
+	return resolve(writer, reader, v)
+}
+
+var errShortBuffer = errors.New("avro: unexpected end of data")
+
+type decoder struct{ buf []byte }
+
+func (d *decoder) long() (int64, error) {
+	n, k := binary.Varint(d.buf)
+	if k <= 0 {
+		return 0, errShortBuffer
+	}
+	d.buf = d.buf[k:]
+	return n, nil
+}
+
+func (d *decoder) take(n int) ([]byte, error) {
+	if n < 0 || n > len(d.buf) {
+		return nil, errShortBuffer
+	}
+	b := d.buf[:n]
+	d.buf = d.buf[n:]
+	return b, nil
+}
+
+func (d *decoder) value(s *Schema) (any, error) {
+	switch s.Type {
+	case Null:
+		return nil, nil
+	case Boolean:
+		b, err := d.take(1)
+		if err != nil {
+			return nil, err
+		}
+		return b[0] != 0, nil
+	case Int:
+		n, err := d.long()
+		if err != nil {
+			return nil, err
+		}
+		if n < math.MinInt32 || n > math.MaxInt32 {
+			return nil, fmt.Errorf("avro: int overflow %d", n)
+		}
+		return int32(n), nil
+	case Long:
+		n, err := d.long()
+		if err != nil {
+			return nil, err
+		}
+		if s.LogicalType == LogicalTimestampMillis {
+			return time.UnixMilli(n).UTC(), nil
+		}
+		return n, nil
+	case Float:
+		b, err := d.take(4)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float32frombits(binary.LittleEndian.Uint32(b)), nil
+	case Double:
+		b, err := d.take(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.LittleEndian.Uint64(b)), nil
+	case String, Bytes:
+		n, err := d.long()
+		if err != nil {
+			return nil, err
+		}
+		b, err := d.take(int(n))
+		if err != nil {
+			return nil, err
+		}
+		if s.Type == String {
+			return string(b), nil
+		}
+		return append([]byte(nil), b...), nil
+	case Union:
+		i, err := d.long()
+		if err != nil {
+			return nil, err
+		}
+		if i < 0 || int(i) >= len(s.Branches) {
+			return nil, fmt.Errorf("avro: union index %d out of range", i)
+		}
+		return d.value(s.Branches[i])
+	case Record:
+		m := make(map[string]any, len(s.Fields))
+		for _, f := range s.Fields {
+			v, err := d.value(f.Type)
+			if err != nil {
+				return nil, fmt.Errorf("avro: field %s.%s: %w", s.Name, f.Name, err)
+			}
+			m[f.Name] = v
+		}
+		return m, nil
+	}
+	return nil, fmt.Errorf("avro: cannot decode type %s", s.Type)
+}
+
+// resolve 将按 writer 解码的值转换为 reader 的形状；调用前已校验兼容性。
+func resolve(writer, reader *Schema, v any) (any, error) {
+	if reader.Type == Union {
+		for _, br := range reader.Branches {
+			if writer.Type == Union {
+				// 写入方 union 已被解码为具体值，按值挑选读取方分支
+				if matches(br, v) {
+					return v, nil
+				}
+				continue
+			}
+			if readable(br, writer) {
+				return resolve(writer, br, v)
+			}
+		}
+		return nil, fmt.Errorf("avro: value %T matches no union branch", v)
+	}
+	if writer.Type == Union {
+		for _, br := range writer.Branches {
+			if matches(br, v) {
+				return resolve(br, reader, v)
+			}
+		}
+		return nil, fmt.Errorf("avro: value %T matches no union branch", v)
+	}
+	switch reader.Type {
+	case Record:
+		wm, _ := v.(map[string]any)
+		out := make(map[string]any, len(reader.Fields))
+		for _, rf := range reader.Fields {
+			wf := writer.Field(rf.Name)
+			if wf == nil {
+				d, err := defaultValue(rf.Type, rf.Default)
+				if err != nil {
+					return nil, err
+				}
+				out[rf.Name] = d
+				continue
+			}
+			fv, err := resolve(wf.Type, rf.Type, wm[rf.Name])
+			if err != nil {
+				return nil, fmt.Errorf("avro: field %s.%s: %w", reader.Name, rf.Name, err)
+			}
+			out[rf.Name] = fv
+		}
+		return out, nil
+	case Long:
+		switch n := v.(type) {
+		case int32:
+			return int64(n), nil
+		case int64:
+			if reader.LogicalType == LogicalTimestampMillis {
+				return time.UnixMilli(n).UTC(), nil
+			}
+		case time.Time:
+			if reader.LogicalType != LogicalTimestampMillis {
+				return n.UnixMilli(), nil
+			}
+		}
+	case Float:
+		switch n := v.(type) {
+		case int32:
+			return float32(n), nil
+		case int64:
+			return float32(n), nil
+		}
+	case Double:
+		switch n := v.(type) {
+		case int32:
+			return float64(n), nil
+		case int64:
+			return float64(n), nil
+		case float32:
+			return float64(n), nil
+		}
+	case String:
+		if b, ok := v.([]byte); ok {
+			return string(b), nil
+		}
+	case Bytes:
+		if s, ok := v.(string); ok {
+			return []byte(s), nil
+		}
+	}
+	return v, nil
+}
+
+// defaultValue 将 schema 中的 JSON 默认值转换为通用值。
+func defaultValue(s *Schema, d any) (any, error) {
+	if s.Type == Union {
+		return defaultValue(s.Branches[0], d)
+	}
+	switch s.Type {
+	case Null:
+		return nil, nil
+	case Boolean:
+		return d.(bool), nil
+	case Int:
+		return int32(d.(float64)), nil
+	case Long:
+		if s.LogicalType == LogicalTimestampMillis {
+			return time.UnixMilli(int64(d.(float64))).UTC(), nil
+		}
+		return int64(d.(float64)), nil
+	case Float:
+		return float32(d.(float64)), nil
+	case Double:
+		return d.(float64), nil
+	case String:
+		return d.(string), nil
+	case Bytes:
+		return []byte(d.(string)), nil
+	case Record:
+		dm := d.(map[string]any)
+		out := make(map[string]any, len(s.Fields))
+		for _, f := range s.Fields {
+			fd, ok := dm[f.Name]
+			if !ok {
+				fd = f.Default
+			}
+			v, err := defaultValue(f.Type, fd)
+			if err != nil {
+				return nil, err
+			}
+			out[f.Name] = v
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("avro: no default for type %s", s.Type)
+}
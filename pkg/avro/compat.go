@@ -0,0 +1,97 @@
+package avro
+
+import (
+	"errors"
+	"fmt"
+)
+
+// CheckCompatibility 校验 reader 能否读取 writer 写入的数据（Avro schema resolution 规则）：
+//   - record 全名必须一致；
+//   - 双方同名字段的类型必须可解析（相同类型或合法的数值/字符串提升）；
+//   - reader 新增的字段必须带默认值；writer 独有的字段会被忽略。
+func CheckCompatibility(reader, writer *Schema) error {
+	return checkCompat(reader, writer, reader.Name)
+}
+
+func checkCompat(reader, writer *Schema, path string) error {
+	if writer.Type == Union {
+		// writer 的每个分支都必须能被 reader 读取
+		for _, br := range writer.Branches {
+			if err := checkCompat(reader, br, path); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if reader.Type == Union {
+		for _, br := range reader.Branches {
+			if checkCompat(br, writer, path) == nil {
+				return nil
+			}
+		}
+		return fmt.Errorf("%s: writer type %s matches no reader union branch", path, describe(writer))
+	}
+	if reader.Type == Record && writer.Type == Record {
+		if reader.FullName() != writer.FullName() {
+			return fmt.Errorf("%s: record name changed from %s to %s", path, writer.FullName(), reader.FullName())
+		}
+		var errs []error
+		for _, rf := range reader.Fields {
+			fp := path + "." + rf.Name
+			wf := writer.Field(rf.Name)
+			if wf == nil {
+				if !rf.HasDefault {
+					errs = append(errs, fmt.Errorf("%s: field missing in writer and has no default", fp))
+				}
+				continue
+			}
+			if err := checkCompat(rf.Type, wf.Type, fp); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return errors.Join(errs...)
+	}
+	if !promotable(writer, reader) {
+		return fmt.Errorf("%s: cannot read %s as %s", path, describe(writer), describe(reader))
+	}
+	return nil
+}
+
+// readable 为 resolve 选择 union 分支时使用的非递归判断。
+func readable(reader, writer *Schema) bool {
+	return checkCompat(reader, writer, "") == nil
+}
+
+// promotable 判断基本类型 writer 能否被 reader 读取。
+// 逻辑类型不同视为不兼容，避免时间戳被静默解释为普通数字。
+func promotable(writer, reader *Schema) bool {
+	if writer.LogicalType != reader.LogicalType {
+		return false
+	}
+	if writer.Type == reader.Type {
+		return true
+	}
+	switch writer.Type {
+	case Int:
+		return reader.Type == Long || reader.Type == Float || reader.Type == Double
+	case Long:
+		return reader.Type == Float || reader.Type == Double
+	case Float:
+		return reader.Type == Double
+	case String:
+		return reader.Type == Bytes
+	case Bytes:
+		return reader.Type == String
+	}
+	return false
+}
+
+func describe(s *Schema) string {
+	if s.LogicalType != "" {
+		return fmt.Sprintf("%s(%s)", s.Type, s.LogicalType)
+	}
+	if s.Type == Record {
+		return "record " + s.FullName()
+	}
+	return string(s.Type)
+}
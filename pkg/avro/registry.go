@@ -0,0 +1,202 @@
+package avro
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SchemaVersion 是注册表中某主题的一个 schema 版本。
+type SchemaVersion struct {
+	Subject string
+	Version int
+	Schema  *Schema
+	Source  []byte
+}
+
+// ID 返回 "<subject>/v<version>" 形式的标识，随消息一起发布。
+func (v *SchemaVersion) ID() string {
+	return fmt.Sprintf("%s/v%d", v.Subject, v.Version)
+}
+
+// Fingerprint 返回 schema 文件内容的 SHA-256，用于发现已发布版本被原地修改。
+func (v *SchemaVersion) Fingerprint() string {
+	sum := sha256.Sum256(v.Source)
+	return hex.EncodeToString(sum[:])
+}
+
+// Registry 是基于文件的 schema 注册表，目录结构为 <subject>/v<N>.avsc，
+// 版本号从 1 开始连续递增，已发布的版本文件不可修改。
+type Registry struct {
+	subjects map[string][]*SchemaVersion // 按版本升序
+}
+
+// LoadRegistry 从文件系统加载全部 schema 版本。
+func LoadRegistry(fsys fs.FS) (*Registry, error) {
+	r := &Registry{subjects: make(map[string][]*SchemaVersion)}
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		subject := e.Name()
+		files, err := fs.ReadDir(fsys, subject)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range files {
+			ver, ok := parseVersionFile(f.Name())
+			if !ok {
+				continue
+			}
+			src, err := fs.ReadFile(fsys, path.Join(subject, f.Name()))
+			if err != nil {
+				return nil, err
+			}
+			s, err := Parse(src)
+			if err != nil {
+				return nil, fmt.Errorf("%s/%s: %w", subject, f.Name(), err)
+			}
+			if s.Type != Record || s.Name != subject {
+				return nil, fmt.Errorf("%s/%s: expected record named %s", subject, f.Name(), subject)
+			}
+			r.subjects[subject] = append(r.subjects[subject], &SchemaVersion{Subject: subject, Version: ver, Schema: s, Source: src})
+		}
+		versions := r.subjects[subject]
+		sort.Slice(versions, func(i, j int) bool { return versions[i].Version < versions[j].Version })
+		for i, v := range versions {
+			if v.Version != i+1 {
+				return nil, fmt.Errorf("%s: versions must be contiguous from v1, found v%d", subject, v.Version)
+			}
+		}
+	}
+	return r, nil
+}
+
+func parseVersionFile(name string) (int, bool) {
+	if !strings.HasPrefix(name, "v") || !strings.HasSuffix(name, ".avsc") {
+		return 0, false
+	}
+	n, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(name, "v"), ".avsc"))
+	if err != nil || n <= 0 {
+		return 0, false
+	}
+	return n, true
+}
+
+// Subjects 返回按名称排序的全部主题。
+func (r *Registry) Subjects() []string {
+	out := make([]string, 0, len(r.subjects))
+	for s := range r.subjects {
+		out = append(out, s)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Latest 返回主题的最新版本。
+func (r *Registry) Latest(subject string) (*SchemaVersion, bool) {
+	vs := r.subjects[subject]
+	if len(vs) == 0 {
+		return nil, false
+	}
+	return vs[len(vs)-1], true
+}
+
+// Get 按主题与版本号查找。
+func (r *Registry) Get(subject string, version int) (*SchemaVersion, bool) {
+	vs := r.subjects[subject]
+	if version <= 0 || version > len(vs) {
+		return nil, false
+	}
+	return vs[version-1], true
+}
+
+// Lookup 按 ID（<subject>/v<N>）查找。
+func (r *Registry) Lookup(id string) (*SchemaVersion, bool) {
+	subject, ver, ok := strings.Cut(id, "/v")
+	if !ok {
+		return nil, false
+	}
+	n, err := strconv.Atoi(ver)
+	if err != nil {
+		return nil, false
+	}
+	return r.Get(subject, n)
+}
+
+// Check 校验每个主题的相邻版本之间完全兼容：
+// 新消费者能读旧消息（向后兼容），未升级的旧消费者能读新消息（向前兼容）。
+func (r *Registry) Check() error {
+	var errs []error
+	for _, subject := range r.Subjects() {
+		vs := r.subjects[subject]
+		for i := 1; i < len(vs); i++ {
+			prev, cur := vs[i-1], vs[i]
+			if err := CheckCompatibility(cur.Schema, prev.Schema); err != nil {
+				errs = append(errs, fmt.Errorf("%s -> %s backward incompatible: %w", prev.ID(), cur.ID(), err))
+			}
+			if err := CheckCompatibility(prev.Schema, cur.Schema); err != nil {
+				errs = append(errs, fmt.Errorf("%s -> %s forward incompatible: %w", prev.ID(), cur.ID(), err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Lock 生成锁文件内容：每行 "<id> <fingerprint>"，随 schema 一起提交。
+func (r *Registry) Lock() []byte {
+	var buf bytes.Buffer
+	for _, subject := range r.Subjects() {
+		for _, v := range r.subjects[subject] {
+			fmt.Fprintf(&buf, "%s %s\n", v.ID(), v.Fingerprint())
+		}
+	}
+	return buf.Bytes()
+}
+
+// VerifyLock 校验锁文件中记录的已发布版本仍然存在且内容未变。
+// 已发布版本只能通过新增版本演进；新增版本不在锁文件中时要求更新锁文件。
+func (r *Registry) VerifyLock(lock []byte) error {
+	var errs []error
+	locked := make(map[string]bool)
+	sc := bufio.NewScanner(bytes.NewReader(lock))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		id, sum, ok := strings.Cut(line, " ")
+		if !ok {
+			errs = append(errs, fmt.Errorf("lock: malformed line %q", line))
+			continue
+		}
+		locked[id] = true
+		v, ok := r.Lookup(id)
+		switch {
+		case !ok:
+			errs = append(errs, fmt.Errorf("%s: published schema was removed", id))
+		case v.Fingerprint() != strings.TrimSpace(sum):
+			errs = append(errs, fmt.Errorf("%s: published schema was modified, add a new version instead", id))
+		}
+	}
+	for _, subject := range r.Subjects() {
+		for _, v := range r.subjects[subject] {
+			if !locked[v.ID()] {
+				errs = append(errs, fmt.Errorf("%s: not in lock file, run the schema check with -write-lock", v.ID()))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
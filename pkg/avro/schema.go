@@ -0,0 +1,188 @@
+// Package avro 实现事件序列化所需的 Avro 子集：
+// 基本类型、record、union 与 timestamp-millis 逻辑类型的二进制编解码、
+// 读写 schema 解析（schema resolution）以及兼容性检查。
+package avro
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Type 为 Avro 类型名。
+type Type string
+
+const (
+	Null    Type = "null"
+	Boolean Type = "boolean"
+	Int     Type = "int"
+	Long    Type = "long"
+	Float   Type = "float"
+	Double  Type = "double"
+	String  Type = "string"
+	Bytes   Type = "bytes"
+	Record  Type = "record"
+	Union   Type = "union"
+)
+
+// LogicalTimestampMillis 标注以 long 存储的 UTC 毫秒时间戳。
+const LogicalTimestampMillis = "timestamp-millis"
+
+// Schema 是解析后的 Avro schema。
+type Schema struct {
+	Type        Type
+	LogicalType string
+	// record
+	Name      string
+	Namespace string
+	Doc       string
+	Fields    []*Field
+	// union
+	Branches []*Schema
+}
+
+// Field 是 record 字段。
+type Field struct {
+	Name       string
+	Doc        string
+	Type       *Schema
+	Default    any // JSON 解码后的默认值
+	HasDefault bool
+}
+
+// FullName 返回 record 的全名（namespace.name）。
+func (s *Schema) FullName() string {
+	if s.Namespace == "" {
+		return s.Name
+	}
+	return s.Namespace + "." + s.Name
+}
+
+// Field 按名称查找字段。
+func (s *Schema) Field(name string) *Field {
+	for _, f := range s.Fields {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// Parse 解析 JSON 格式的 Avro schema。
+func Parse(src []byte) (*Schema, error) {
+	var raw any
+	if err := json.Unmarshal(src, &raw); err != nil {
+		return nil, fmt.Errorf("avro: invalid schema json: %w", err)
+	}
+	return parse(raw, "")
+}
+
+func parse(raw any, namespace string) (*Schema, error) {
+	switch v := raw.(type) {
+	case string:
+		return primitive(Type(v))
+	case []any:
+		s := &Schema{Type: Union}
+		for _, b := range v {
+			bs, err := parse(b, namespace)
+			if err != nil {
+				return nil, err
+			}
+			if bs.Type == Union {
+				return nil, errors.New("avro: nested unions are not allowed")
+			}
+			s.Branches = append(s.Branches, bs)
+		}
+		return s, nil
+	case map[string]any:
+		t, _ := v["type"].(string)
+		if t != string(Record) {
+			s, err := parse(v["type"], namespace)
+			if err != nil {
+				return nil, err
+			}
+			if lt, ok := v["logicalType"].(string); ok {
+				s.LogicalType = lt
+			}
+			return s, nil
+		}
+		return parseRecord(v, namespace)
+	}
+	return nil, fmt.Errorf("avro: unsupported schema %v", raw)
+}
+
+func primitive(t Type) (*Schema, error) {
+	switch t {
+	case Null, Boolean, Int, Long, Float, Double, String, Bytes:
+		return &Schema{Type: t}, nil
+	}
+	return nil, fmt.Errorf("avro: unsupported type %q", t)
+}
+
+func parseRecord(v map[string]any, namespace string) (*Schema, error) {
+	s := &Schema{Type: Record}
+	s.Name, _ = v["name"].(string)
+	s.Doc, _ = v["doc"].(string)
+	if ns, ok := v["namespace"].(string); ok {
+		namespace = ns
+	}
+	s.Namespace = namespace
+	if s.Name == "" {
+		return nil, errors.New("avro: record name required")
+	}
+	fields, _ := v["fields"].([]any)
+	seen := make(map[string]bool, len(fields))
+	for _, rf := range fields {
+		fm, ok := rf.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("avro: invalid field in %s", s.Name)
+		}
+		f := &Field{}
+		f.Name, _ = fm["name"].(string)
+		f.Doc, _ = fm["doc"].(string)
+		if f.Name == "" {
+			return nil, fmt.Errorf("avro: field name required in %s", s.Name)
+		}
+		if seen[f.Name] {
+			return nil, fmt.Errorf("avro: duplicate field %s.%s", s.Name, f.Name)
+		}
+		seen[f.Name] = true
+		ft, err := parse(fm["type"], namespace)
+		if err != nil {
+			return nil, fmt.Errorf("avro: field %s.%s: %w", s.Name, f.Name, err)
+		}
+		f.Type = ft
+		if d, ok := fm["default"]; ok {
+			f.Default, f.HasDefault = d, true
+			if err := checkDefault(ft, d); err != nil {
+				return nil, fmt.Errorf("avro: field %s.%s: %w", s.Name, f.Name, err)
+			}
+		}
+		s.Fields = append(s.Fields, f)
+	}
+	return s, nil
+}
+
+// checkDefault 校验默认值与类型匹配；union 的默认值对应第一个分支。
+func checkDefault(s *Schema, d any) error {
+	if s.Type == Union {
+		return checkDefault(s.Branches[0], d)
+	}
+	ok := false
+	switch s.Type {
+	case Null:
+		ok = d == nil
+	case Boolean:
+		_, ok = d.(bool)
+	case Int, Long, Float, Double:
+		_, ok = d.(float64)
+	case String, Bytes:
+		_, ok = d.(string)
+	case Record:
+		_, ok = d.(map[string]any)
+	}
+	if !ok {
+		return fmt.Errorf("default %v does not match type %s", d, s.Type)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package blobstore
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	usersvc "github.com/gavin/airport-pickup/internal/domain/user/service"
+)
+
+// LocalStore 把 blob 保存为本地目录下的文件，实现 BlobStore，用于开发环境与单机部署。
+// key 按 / 分层映射为子目录；写入先落临时文件再改名，读取不会看到写了一半的内容。
+// 不保存 contentType，内容类型由调用方随业务记录保存。
+type LocalStore struct {
+	root string
+}
+
+var _ usersvc.BlobStore = (*LocalStore)(nil)
+
+// NewLocalStore 以 root 为根目录创建存储，目录不存在时自动创建。
+func NewLocalStore(root string) (*LocalStore, error) {
+	if root == "" {
+		return nil, errors.New("blobstore: root directory required")
+	}
+	if err := os.MkdirAll(root, 0o750); err != nil {
+		return nil, err
+	}
+	return &LocalStore{root: root}, nil
+}
+
+func (s *LocalStore) Put(key string, content []byte, contentType string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o750); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(p), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), p)
+}
+
+func (s *LocalStore) Get(key string) ([]byte, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	b, err := os.ReadFile(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, usersvc.ErrBlobNotFound
+	}
+	return b, err
+}
+
+// path 把 key 映射为根目录下的文件路径，拒绝绝对路径与 .. 等越出根目录的 key。
+func (s *LocalStore) path(key string) (string, error) {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return "", fmt.Errorf("blobstore: invalid key %q", key)
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return "", fmt.Errorf("blobstore: invalid key %q", key)
+		}
+	}
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
@@ -0,0 +1,44 @@
+package blobstore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	usersvc "github.com/gavin/airport-pickup/internal/domain/user/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStore_PutGet(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "blobs")
+	s, err := NewLocalStore(root)
+	require.NoError(t, err)
+
+	require.NoError(t, s.Put("drivers/d1/documents/doc1.pdf", []byte("%PDF-1.4 v1"), "application/pdf"))
+	got, err := s.Get("drivers/d1/documents/doc1.pdf")
+	require.NoError(t, err)
+	assert.Equal(t, "%PDF-1.4 v1", string(got))
+
+	// 覆盖写入，且不留下临时文件
+	require.NoError(t, s.Put("drivers/d1/documents/doc1.pdf", []byte("%PDF-1.4 v2"), "application/pdf"))
+	got, err = s.Get("drivers/d1/documents/doc1.pdf")
+	require.NoError(t, err)
+	assert.Equal(t, "%PDF-1.4 v2", string(got))
+	entries, err := os.ReadDir(filepath.Join(root, "drivers", "d1", "documents"))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	_, err = s.Get("drivers/d1/documents/missing.pdf")
+	assert.ErrorIs(t, err, usersvc.ErrBlobNotFound)
+}
+
+func TestLocalStore_RejectsKeysOutsideRoot(t *testing.T) {
+	s, err := NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	for _, key := range []string{"", "/etc/passwd", "../escape", "drivers/../../escape", "drivers//doc", "drivers\\doc"} {
+		assert.Error(t, s.Put(key, []byte("x"), "text/plain"), key)
+		_, err := s.Get(key)
+		assert.Error(t, err, key)
+	}
+}
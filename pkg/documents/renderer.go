@@ -0,0 +1,74 @@
+// Package documents 把收据与发票渲染为 HTML 和 PDF。
+// 模板见 templates/：HTML 使用 html/template，PDF 由纯文本模板逐行排版（等宽字体）。
+package documents
+
+import (
+	"bytes"
+	"embed"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gavin/airport-pickup/internal/domain/money"
+	settlemententity "github.com/gavin/airport-pickup/internal/domain/settlement/entity"
+)
+
+//go:embed templates/*
+var templates embed.FS
+
+// Renderer 渲染单据；模板在创建时解析一次，可并发使用。
+type Renderer struct {
+	html *htmltemplate.Template
+	text *texttemplate.Template
+}
+
+func NewRenderer() (*Renderer, error) {
+	funcs := map[string]any{
+		"amount":  func(cents int64) string { return money.FromCents(cents).String() },
+		"date":    func(t time.Time) string { return t.UTC().Format("2006-01-02") },
+		"lastDay": func(t time.Time) time.Time { return t.AddDate(0, 0, -1) }, // 账期结束为开区间
+		"pad":     func(n int, s string) string { return pad(s, n, false) },
+		"lpad":    func(n int, s string) string { return pad(s, n, true) },
+	}
+	h, err := htmltemplate.New("invoice.html").Funcs(funcs).ParseFS(templates, "templates/invoice.html")
+	if err != nil {
+		return nil, err
+	}
+	t, err := texttemplate.New("invoice.txt").Funcs(funcs).ParseFS(templates, "templates/invoice.txt")
+	if err != nil {
+		return nil, err
+	}
+	return &Renderer{html: h, text: t}, nil
+}
+
+// HTML 渲染为 HTML 文档。
+func (r *Renderer) HTML(inv *settlemententity.Invoice) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := r.html.Execute(&buf, inv); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// PDF 渲染为 PDF 文档。
+func (r *Renderer) PDF(inv *settlemententity.Invoice) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := r.text.Execute(&buf, inv); err != nil {
+		return nil, err
+	}
+	return writePDF(strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")), nil
+}
+
+// pad 按字符数补齐空格到 n 列，超长时截断并保留一个空格作为列分隔。
+func pad(s string, n int, left bool) string {
+	c := utf8.RuneCountInString(s)
+	switch {
+	case c >= n:
+		return string([]rune(s)[:n-1]) + " "
+	case left:
+		return strings.Repeat(" ", n-c) + s
+	}
+	return s + strings.Repeat(" ", n-c)
+}
@@ -0,0 +1,69 @@
+package documents
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	settlemententity "github.com/gavin/airport-pickup/internal/domain/settlement/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testReceipt() *settlemententity.Invoice {
+	paid := time.Date(2025, 11, 5, 10, 30, 0, 0, time.UTC)
+	inv := &settlemententity.Invoice{
+		Kind:      settlemententity.InvoiceReceipt,
+		Issuer:    settlemententity.LegalEntity{Code: "US01", Name: "Pickup <US> Inc.", Address: "1 Airport Blvd"},
+		Currency:  "USD",
+		BookingID: "bk1", PassengerID: "p1", PaymentID: "pay1", PaidAt: &paid,
+		IssuedAt: paid,
+	}
+	inv.AddLine(settlemententity.InvoiceLine{Kind: settlemententity.FareDistance, Description: "10 km x 2.00", AmountCents: 2000, Date: paid})
+	inv.AddLine(settlemententity.InvoiceLine{Kind: settlemententity.FareTax, Description: "Sales tax (7.25%)", AmountCents: 145, TaxCents: 145, Date: paid})
+	inv.AssignNumber(42)
+	return inv
+}
+
+func TestRenderer_HTML(t *testing.T) {
+	r, err := NewRenderer()
+	require.NoError(t, err)
+	out, err := r.HTML(testReceipt())
+	require.NoError(t, err)
+	s := string(out)
+	assert.Contains(t, s, "Receipt US01-000042")
+	assert.Contains(t, s, "Pickup &lt;US&gt; Inc.")
+	assert.Contains(t, s, "21.45 USD")
+	assert.Contains(t, s, "20.00") // 不含税金额
+}
+
+func TestRenderer_PDF(t *testing.T) {
+	r, err := NewRenderer()
+	require.NoError(t, err)
+	out, err := r.PDF(testReceipt())
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(out, []byte("%PDF-1.4")))
+	assert.True(t, bytes.HasSuffix(out, []byte("%%EOF\n")))
+	assert.Contains(t, string(out), "RECEIPT US01-000042")
+	assert.Contains(t, string(out), `Sales tax \(7.25%\)`)
+}
+
+func TestWritePDF_PagesAndXref(t *testing.T) {
+	lines := make([]string, linesPerPage+5)
+	for i := range lines {
+		lines[i] = "line"
+	}
+	out := string(writePDF(lines))
+	assert.Contains(t, out, "/Count 2")
+	// xref 中的偏移量须指向对应对象
+	xref := out[strings.Index(out, "xref\n"):]
+	entries := strings.Split(xref, "\n")[3:10]
+	for i, e := range entries {
+		off, err := strconv.Atoi(e[:10])
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(out[off:], strconv.Itoa(i+1)+" 0 obj"), "object %d", i+1)
+	}
+	assert.Equal(t, "caf\xe9 ?", pdfString("café 中"))
+}
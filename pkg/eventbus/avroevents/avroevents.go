@@ -0,0 +1,19 @@
+// Package avroevents 是事件的 Avro 线上类型（由 schemas/events 生成）及其与领域事件的转换。
+package avroevents
+
+//go:generate go run ../../../cmd/avrogen -dir ../../../schemas/events -pkg avroevents -out events_gen.go
+
+// Record 由生成的类型实现。
+type Record interface {
+	SchemaID() string
+	ToAvro() map[string]any
+	FromAvro(m map[string]any) error
+}
+
+// optional 将可选字段转换为 union 通用值：nil 指针对应 null。
+func optional[T any](p *T) any {
+	if p == nil {
+		return nil
+	}
+	return *p
+}
@@ -0,0 +1,178 @@
+package avroevents
+
+import (
+	"fmt"
+
+	evt "github.com/gavin/airport-pickup/internal/domain/eventbus"
+	"github.com/gavin/airport-pickup/internal/domain/money"
+)
+
+// FromEvent 将领域事件转换为 Avro 线上类型。
+func FromEvent(e evt.Event) (Record, error) {
+	switch v := e.(type) {
+	case evt.OrderMatched:
+		return &OrderMatched{BookingID: v.BookingID, RequestID: v.RequestID, DriverOfferID: v.DriverOfferID}, nil
+	case evt.OrderCompleted:
+		return &OrderCompleted{BookingID: v.BookingID}, nil
+	case evt.OrderCancelled:
+		return &OrderCancelled{BookingID: v.BookingID, Reason: v.Reason}, nil
+	case evt.PaymentAuthorizationFailed:
+		return &PaymentAuthorizationFailed{BookingID: v.BookingID, AmountCents: v.AmountCents, Reason: v.Reason}, nil
+	case evt.PaymentSucceeded:
+		return &PaymentSucceeded{BookingID: v.BookingID, AmountCents: v.AmountCents}, nil
+	case evt.SettlementCreated:
+		return &SettlementCreated{BookingID: v.BookingID}, nil
+	case evt.RevenueUpdated:
+		return &RevenueUpdated{BookingID: v.BookingID, DeltaCents: v.DeltaCents}, nil
+	case evt.PickupRequestCreated:
+		return &PickupRequestCreated{
+			RequestID:        v.RequestID,
+			PassengerID:      v.PassengerID,
+			AirportCode:      v.AirportCode,
+			VehicleType:      v.VehicleType,
+			MaxPricePerKm:    v.MaxPricePerKm.Float64(),
+			Currency:         v.Currency,
+			PreferHighRating: v.PreferHighRating,
+			DesiredTime:      v.DesiredTime,
+			Status:           v.Status,
+			PromoCode:        v.PromoCode,
+			QuoteID:          v.QuoteID,
+			PassengerScore:   v.PassengerScore,
+			DepositCents:     v.DepositCents,
+			PaymentMethodID:  v.PaymentMethodID,
+		}, nil
+	case evt.DriverOfferCreated:
+		return &DriverOfferCreated{
+			OfferID:           v.OfferID,
+			DriverID:          v.DriverID,
+			AirportCode:       v.AirportCode,
+			VehicleType:       v.VehicleType,
+			VehicleID:         v.VehicleID,
+			AvailableFrom:     v.AvailableFrom,
+			AvailableTo:       v.AvailableTo,
+			PricePerKm:        v.PricePerKm.Float64(),
+			Currency:          v.Currency,
+			Rating:            v.Rating,
+			Status:            v.Status,
+			MinPassengerScore: v.MinPassengerScore,
+		}, nil
+	case evt.SurgeStarted:
+		return &SurgeStarted{
+			AirportCode:       v.AirportCode,
+			VehicleType:       v.VehicleType,
+			Multiplier:        v.Multiplier,
+			TargetMultiplier:  v.TargetMultiplier,
+			OpenRequests:      int32(v.OpenRequests),
+			OpenOffers:        int32(v.OpenOffers),
+			SuggestedBidPerKm: v.SuggestedBidPerKm.Float64(),
+			Currency:          v.Currency,
+			OccurredAt:        v.OccurredAt,
+		}, nil
+	case evt.DriverRatingUpdated:
+		return &DriverRatingUpdated{
+			DriverID:    v.DriverID,
+			Rating:      v.Rating,
+			RatingCount: int32(v.RatingCount),
+			OccurredAt:  v.OccurredAt,
+		}, nil
+	case evt.PassengerRatingUpdated:
+		return &PassengerRatingUpdated{
+			PassengerID:     v.PassengerID,
+			ReputationScore: v.ReputationScore,
+			RatingCount:     int32(v.RatingCount),
+			NoShowCount:     int32(v.NoShowCount),
+			OccurredAt:      v.OccurredAt,
+		}, nil
+	case evt.DriverStatusChanged:
+		return &DriverStatusChanged{DriverID: v.DriverID, Status: v.Status, Reason: v.Reason, OccurredAt: v.OccurredAt}, nil
+	case evt.PickupRequestClosed:
+		return &PickupRequestClosed{RequestID: v.RequestID, PassengerID: v.PassengerID, AirportCode: v.AirportCode,
+			VehicleType: v.VehicleType, Status: v.Status, OccurredAt: v.OccurredAt}, nil
+	}
+	return nil, fmt.Errorf("avroevents: no schema for event %s", e.Name())
+}
+
+// ToEvent 将 Avro 线上类型转换回领域事件。
+func ToEvent(r Record) (evt.Event, error) {
+	switch v := r.(type) {
+	case *OrderMatched:
+		return evt.OrderMatched{BookingID: v.BookingID, RequestID: v.RequestID, DriverOfferID: v.DriverOfferID}, nil
+	case *OrderCompleted:
+		return evt.OrderCompleted{BookingID: v.BookingID}, nil
+	case *OrderCancelled:
+		return evt.OrderCancelled{BookingID: v.BookingID, Reason: v.Reason}, nil
+	case *PaymentAuthorizationFailed:
+		return evt.PaymentAuthorizationFailed{BookingID: v.BookingID, AmountCents: v.AmountCents, Reason: v.Reason}, nil
+	case *PaymentSucceeded:
+		return evt.PaymentSucceeded{BookingID: v.BookingID, AmountCents: v.AmountCents}, nil
+	case *SettlementCreated:
+		return evt.SettlementCreated{BookingID: v.BookingID}, nil
+	case *RevenueUpdated:
+		return evt.RevenueUpdated{BookingID: v.BookingID, DeltaCents: v.DeltaCents}, nil
+	case *PickupRequestCreated:
+		return evt.PickupRequestCreated{
+			RequestID:        v.RequestID,
+			PassengerID:      v.PassengerID,
+			AirportCode:      v.AirportCode,
+			VehicleType:      v.VehicleType,
+			MaxPricePerKm:    money.FromFloat(v.MaxPricePerKm),
+			Currency:         v.Currency,
+			PreferHighRating: v.PreferHighRating,
+			DesiredTime:      v.DesiredTime,
+			Status:           v.Status,
+			PromoCode:        v.PromoCode,
+			QuoteID:          v.QuoteID,
+			PassengerScore:   v.PassengerScore,
+			DepositCents:     v.DepositCents,
+			PaymentMethodID:  v.PaymentMethodID,
+		}, nil
+	case *DriverOfferCreated:
+		return evt.DriverOfferCreated{
+			OfferID:           v.OfferID,
+			DriverID:          v.DriverID,
+			AirportCode:       v.AirportCode,
+			VehicleType:       v.VehicleType,
+			VehicleID:         v.VehicleID,
+			AvailableFrom:     v.AvailableFrom,
+			AvailableTo:       v.AvailableTo,
+			PricePerKm:        money.FromFloat(v.PricePerKm),
+			Currency:          v.Currency,
+			Rating:            v.Rating,
+			Status:            v.Status,
+			MinPassengerScore: v.MinPassengerScore,
+		}, nil
+	case *SurgeStarted:
+		return evt.SurgeStarted{
+			AirportCode:       v.AirportCode,
+			VehicleType:       v.VehicleType,
+			Multiplier:        v.Multiplier,
+			TargetMultiplier:  v.TargetMultiplier,
+			OpenRequests:      int(v.OpenRequests),
+			OpenOffers:        int(v.OpenOffers),
+			SuggestedBidPerKm: money.FromFloat(v.SuggestedBidPerKm),
+			Currency:          v.Currency,
+			OccurredAt:        v.OccurredAt,
+		}, nil
+	case *DriverRatingUpdated:
+		return evt.DriverRatingUpdated{
+			DriverID:    v.DriverID,
+			Rating:      v.Rating,
+			RatingCount: int(v.RatingCount),
+			OccurredAt:  v.OccurredAt,
+		}, nil
+	case *PassengerRatingUpdated:
+		return evt.PassengerRatingUpdated{
+			PassengerID:     v.PassengerID,
+			ReputationScore: v.ReputationScore,
+			RatingCount:     int(v.RatingCount),
+			NoShowCount:     int(v.NoShowCount),
+			OccurredAt:      v.OccurredAt,
+		}, nil
+	case *DriverStatusChanged:
+		return evt.DriverStatusChanged{DriverID: v.DriverID, Status: v.Status, Reason: v.Reason, OccurredAt: v.OccurredAt}, nil
+	case *PickupRequestClosed:
+		return evt.PickupRequestClosed{RequestID: v.RequestID, PassengerID: v.PassengerID, AirportCode: v.AirportCode,
+			VehicleType: v.VehicleType, Status: v.Status, OccurredAt: v.OccurredAt}, nil
+	}
+	return nil, fmt.Errorf("avroevents: unsupported record %T", r)
+}
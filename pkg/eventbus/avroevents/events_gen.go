@@ -0,0 +1,799 @@
+// Code generated by avrogen. DO NOT EDIT.
+
+package avroevents
+
+import (
+	"fmt"
+	"time"
+)
+
+// New 按主题名返回空记录，未知主题返回 nil。
+func New(subject string) Record {
+	switch subject {
+	case "DriverOfferCreated":
+		return &DriverOfferCreated{}
+	case "DriverRatingUpdated":
+		return &DriverRatingUpdated{}
+	case "DriverStatusChanged":
+		return &DriverStatusChanged{}
+	case "OrderCancelled":
+		return &OrderCancelled{}
+	case "OrderCompleted":
+		return &OrderCompleted{}
+	case "OrderMatched":
+		return &OrderMatched{}
+	case "PassengerRatingUpdated":
+		return &PassengerRatingUpdated{}
+	case "PaymentAuthorizationFailed":
+		return &PaymentAuthorizationFailed{}
+	case "PaymentSucceeded":
+		return &PaymentSucceeded{}
+	case "PickupRequestClosed":
+		return &PickupRequestClosed{}
+	case "PickupRequestCreated":
+		return &PickupRequestCreated{}
+	case "RevenueUpdated":
+		return &RevenueUpdated{}
+	case "SettlementCreated":
+		return &SettlementCreated{}
+	case "SurgeStarted":
+		return &SurgeStarted{}
+	}
+	return nil
+}
+
+// DriverOfferCreated 由 schema DriverOfferCreated/v4 生成。
+// Emitted when a driver publishes an offer.
+type DriverOfferCreated struct {
+	OfferID       string    `avro:"offer_id"`
+	DriverID      string    `avro:"driver_id"`
+	AirportCode   string    `avro:"airport_code"`
+	VehicleType   string    `avro:"vehicle_type"`
+	AvailableFrom time.Time `avro:"available_from"`
+	AvailableTo   time.Time `avro:"available_to"`
+	PricePerKm    float64   `avro:"price_per_km"`
+	// ISO 4217 settlement currency of the airport
+	Currency string  `avro:"currency"`
+	Rating   float64 `avro:"rating"`
+	// open, matched, cancelled
+	Status string `avro:"status"`
+	// lowest passenger reputation score the driver accepts, 0 for any
+	MinPassengerScore float64 `avro:"min_passenger_score"`
+	// verified vehicle serving the offer; its class is the vehicle_type
+	VehicleID string `avro:"vehicle_id"`
+}
+
+// SchemaID 返回生成该类型所用的 schema 版本。
+func (*DriverOfferCreated) SchemaID() string { return "DriverOfferCreated/v4" }
+
+// ToAvro 转换为 Avro 通用值。
+func (r *DriverOfferCreated) ToAvro() map[string]any {
+	return map[string]any{
+		"offer_id":            r.OfferID,
+		"driver_id":           r.DriverID,
+		"airport_code":        r.AirportCode,
+		"vehicle_type":        r.VehicleType,
+		"available_from":      r.AvailableFrom,
+		"available_to":        r.AvailableTo,
+		"price_per_km":        r.PricePerKm,
+		"currency":            r.Currency,
+		"rating":              r.Rating,
+		"status":              r.Status,
+		"min_passenger_score": r.MinPassengerScore,
+		"vehicle_id":          r.VehicleID,
+	}
+}
+
+// FromAvro 从按本 schema 解析后的 Avro 通用值填充字段。
+func (r *DriverOfferCreated) FromAvro(m map[string]any) error {
+	if v, ok := m["offer_id"].(string); ok {
+		r.OfferID = v
+	} else {
+		return fmt.Errorf("DriverOfferCreated.offer_id: unexpected type %T", m["offer_id"])
+	}
+	if v, ok := m["driver_id"].(string); ok {
+		r.DriverID = v
+	} else {
+		return fmt.Errorf("DriverOfferCreated.driver_id: unexpected type %T", m["driver_id"])
+	}
+	if v, ok := m["airport_code"].(string); ok {
+		r.AirportCode = v
+	} else {
+		return fmt.Errorf("DriverOfferCreated.airport_code: unexpected type %T", m["airport_code"])
+	}
+	if v, ok := m["vehicle_type"].(string); ok {
+		r.VehicleType = v
+	} else {
+		return fmt.Errorf("DriverOfferCreated.vehicle_type: unexpected type %T", m["vehicle_type"])
+	}
+	if v, ok := m["available_from"].(time.Time); ok {
+		r.AvailableFrom = v
+	} else {
+		return fmt.Errorf("DriverOfferCreated.available_from: unexpected type %T", m["available_from"])
+	}
+	if v, ok := m["available_to"].(time.Time); ok {
+		r.AvailableTo = v
+	} else {
+		return fmt.Errorf("DriverOfferCreated.available_to: unexpected type %T", m["available_to"])
+	}
+	if v, ok := m["price_per_km"].(float64); ok {
+		r.PricePerKm = v
+	} else {
+		return fmt.Errorf("DriverOfferCreated.price_per_km: unexpected type %T", m["price_per_km"])
+	}
+	if v, ok := m["currency"].(string); ok {
+		r.Currency = v
+	} else {
+		return fmt.Errorf("DriverOfferCreated.currency: unexpected type %T", m["currency"])
+	}
+	if v, ok := m["rating"].(float64); ok {
+		r.Rating = v
+	} else {
+		return fmt.Errorf("DriverOfferCreated.rating: unexpected type %T", m["rating"])
+	}
+	if v, ok := m["status"].(string); ok {
+		r.Status = v
+	} else {
+		return fmt.Errorf("DriverOfferCreated.status: unexpected type %T", m["status"])
+	}
+	if v, ok := m["min_passenger_score"].(float64); ok {
+		r.MinPassengerScore = v
+	} else {
+		return fmt.Errorf("DriverOfferCreated.min_passenger_score: unexpected type %T", m["min_passenger_score"])
+	}
+	if v, ok := m["vehicle_id"].(string); ok {
+		r.VehicleID = v
+	} else {
+		return fmt.Errorf("DriverOfferCreated.vehicle_id: unexpected type %T", m["vehicle_id"])
+	}
+	return nil
+}
+
+// DriverRatingUpdated 由 schema DriverRatingUpdated/v1 生成。
+// Emitted when a driver's reputation score is recomputed after a passenger rating.
+type DriverRatingUpdated struct {
+	DriverID    string    `avro:"driver_id"`
+	Rating      float64   `avro:"rating"`
+	RatingCount int32     `avro:"rating_count"`
+	OccurredAt  time.Time `avro:"occurred_at"`
+}
+
+// SchemaID 返回生成该类型所用的 schema 版本。
+func (*DriverRatingUpdated) SchemaID() string { return "DriverRatingUpdated/v1" }
+
+// ToAvro 转换为 Avro 通用值。
+func (r *DriverRatingUpdated) ToAvro() map[string]any {
+	return map[string]any{
+		"driver_id":    r.DriverID,
+		"rating":       r.Rating,
+		"rating_count": r.RatingCount,
+		"occurred_at":  r.OccurredAt,
+	}
+}
+
+// FromAvro 从按本 schema 解析后的 Avro 通用值填充字段。
+func (r *DriverRatingUpdated) FromAvro(m map[string]any) error {
+	if v, ok := m["driver_id"].(string); ok {
+		r.DriverID = v
+	} else {
+		return fmt.Errorf("DriverRatingUpdated.driver_id: unexpected type %T", m["driver_id"])
+	}
+	if v, ok := m["rating"].(float64); ok {
+		r.Rating = v
+	} else {
+		return fmt.Errorf("DriverRatingUpdated.rating: unexpected type %T", m["rating"])
+	}
+	if v, ok := m["rating_count"].(int32); ok {
+		r.RatingCount = v
+	} else {
+		return fmt.Errorf("DriverRatingUpdated.rating_count: unexpected type %T", m["rating_count"])
+	}
+	if v, ok := m["occurred_at"].(time.Time); ok {
+		r.OccurredAt = v
+	} else {
+		return fmt.Errorf("DriverRatingUpdated.occurred_at: unexpected type %T", m["occurred_at"])
+	}
+	return nil
+}
+
+// DriverStatusChanged 由 schema DriverStatusChanged/v1 生成。
+// Emitted when a driver's account status changes during onboarding, suspension or offboarding.
+type DriverStatusChanged struct {
+	DriverID string `avro:"driver_id"`
+	// pending, active, suspended, offboarded
+	Status     string    `avro:"status"`
+	Reason     string    `avro:"reason"`
+	OccurredAt time.Time `avro:"occurred_at"`
+}
+
+// SchemaID 返回生成该类型所用的 schema 版本。
+func (*DriverStatusChanged) SchemaID() string { return "DriverStatusChanged/v1" }
+
+// ToAvro 转换为 Avro 通用值。
+func (r *DriverStatusChanged) ToAvro() map[string]any {
+	return map[string]any{
+		"driver_id":   r.DriverID,
+		"status":      r.Status,
+		"reason":      r.Reason,
+		"occurred_at": r.OccurredAt,
+	}
+}
+
+// FromAvro 从按本 schema 解析后的 Avro 通用值填充字段。
+func (r *DriverStatusChanged) FromAvro(m map[string]any) error {
+	if v, ok := m["driver_id"].(string); ok {
+		r.DriverID = v
+	} else {
+		return fmt.Errorf("DriverStatusChanged.driver_id: unexpected type %T", m["driver_id"])
+	}
+	if v, ok := m["status"].(string); ok {
+		r.Status = v
+	} else {
+		return fmt.Errorf("DriverStatusChanged.status: unexpected type %T", m["status"])
+	}
+	if v, ok := m["reason"].(string); ok {
+		r.Reason = v
+	} else {
+		return fmt.Errorf("DriverStatusChanged.reason: unexpected type %T", m["reason"])
+	}
+	if v, ok := m["occurred_at"].(time.Time); ok {
+		r.OccurredAt = v
+	} else {
+		return fmt.Errorf("DriverStatusChanged.occurred_at: unexpected type %T", m["occurred_at"])
+	}
+	return nil
+}
+
+// OrderCancelled 由 schema OrderCancelled/v1 生成。
+// Emitted when a booking is cancelled before completion.
+type OrderCancelled struct {
+	BookingID string `avro:"booking_id"`
+	Reason    string `avro:"reason"`
+}
+
+// SchemaID 返回生成该类型所用的 schema 版本。
+func (*OrderCancelled) SchemaID() string { return "OrderCancelled/v1" }
+
+// ToAvro 转换为 Avro 通用值。
+func (r *OrderCancelled) ToAvro() map[string]any {
+	return map[string]any{
+		"booking_id": r.BookingID,
+		"reason":     r.Reason,
+	}
+}
+
+// FromAvro 从按本 schema 解析后的 Avro 通用值填充字段。
+func (r *OrderCancelled) FromAvro(m map[string]any) error {
+	if v, ok := m["booking_id"].(string); ok {
+		r.BookingID = v
+	} else {
+		return fmt.Errorf("OrderCancelled.booking_id: unexpected type %T", m["booking_id"])
+	}
+	if v, ok := m["reason"].(string); ok {
+		r.Reason = v
+	} else {
+		return fmt.Errorf("OrderCancelled.reason: unexpected type %T", m["reason"])
+	}
+	return nil
+}
+
+// OrderCompleted 由 schema OrderCompleted/v1 生成。
+// Emitted when a booking is completed.
+type OrderCompleted struct {
+	BookingID string `avro:"booking_id"`
+}
+
+// SchemaID 返回生成该类型所用的 schema 版本。
+func (*OrderCompleted) SchemaID() string { return "OrderCompleted/v1" }
+
+// ToAvro 转换为 Avro 通用值。
+func (r *OrderCompleted) ToAvro() map[string]any {
+	return map[string]any{
+		"booking_id": r.BookingID,
+	}
+}
+
+// FromAvro 从按本 schema 解析后的 Avro 通用值填充字段。
+func (r *OrderCompleted) FromAvro(m map[string]any) error {
+	if v, ok := m["booking_id"].(string); ok {
+		r.BookingID = v
+	} else {
+		return fmt.Errorf("OrderCompleted.booking_id: unexpected type %T", m["booking_id"])
+	}
+	return nil
+}
+
+// OrderMatched 由 schema OrderMatched/v1 生成。
+// Emitted when an order is matched between a pickup request and a driver offer.
+type OrderMatched struct {
+	BookingID     string `avro:"booking_id"`
+	RequestID     string `avro:"request_id"`
+	DriverOfferID string `avro:"driver_offer_id"`
+}
+
+// SchemaID 返回生成该类型所用的 schema 版本。
+func (*OrderMatched) SchemaID() string { return "OrderMatched/v1" }
+
+// ToAvro 转换为 Avro 通用值。
+func (r *OrderMatched) ToAvro() map[string]any {
+	return map[string]any{
+		"booking_id":      r.BookingID,
+		"request_id":      r.RequestID,
+		"driver_offer_id": r.DriverOfferID,
+	}
+}
+
+// FromAvro 从按本 schema 解析后的 Avro 通用值填充字段。
+func (r *OrderMatched) FromAvro(m map[string]any) error {
+	if v, ok := m["booking_id"].(string); ok {
+		r.BookingID = v
+	} else {
+		return fmt.Errorf("OrderMatched.booking_id: unexpected type %T", m["booking_id"])
+	}
+	if v, ok := m["request_id"].(string); ok {
+		r.RequestID = v
+	} else {
+		return fmt.Errorf("OrderMatched.request_id: unexpected type %T", m["request_id"])
+	}
+	if v, ok := m["driver_offer_id"].(string); ok {
+		r.DriverOfferID = v
+	} else {
+		return fmt.Errorf("OrderMatched.driver_offer_id: unexpected type %T", m["driver_offer_id"])
+	}
+	return nil
+}
+
+// PassengerRatingUpdated 由 schema PassengerRatingUpdated/v1 生成。
+// Emitted when a passenger's reputation score is recomputed after a driver rating.
+type PassengerRatingUpdated struct {
+	PassengerID     string    `avro:"passenger_id"`
+	ReputationScore float64   `avro:"reputation_score"`
+	RatingCount     int32     `avro:"rating_count"`
+	NoShowCount     int32     `avro:"no_show_count"`
+	OccurredAt      time.Time `avro:"occurred_at"`
+}
+
+// SchemaID 返回生成该类型所用的 schema 版本。
+func (*PassengerRatingUpdated) SchemaID() string { return "PassengerRatingUpdated/v1" }
+
+// ToAvro 转换为 Avro 通用值。
+func (r *PassengerRatingUpdated) ToAvro() map[string]any {
+	return map[string]any{
+		"passenger_id":     r.PassengerID,
+		"reputation_score": r.ReputationScore,
+		"rating_count":     r.RatingCount,
+		"no_show_count":    r.NoShowCount,
+		"occurred_at":      r.OccurredAt,
+	}
+}
+
+// FromAvro 从按本 schema 解析后的 Avro 通用值填充字段。
+func (r *PassengerRatingUpdated) FromAvro(m map[string]any) error {
+	if v, ok := m["passenger_id"].(string); ok {
+		r.PassengerID = v
+	} else {
+		return fmt.Errorf("PassengerRatingUpdated.passenger_id: unexpected type %T", m["passenger_id"])
+	}
+	if v, ok := m["reputation_score"].(float64); ok {
+		r.ReputationScore = v
+	} else {
+		return fmt.Errorf("PassengerRatingUpdated.reputation_score: unexpected type %T", m["reputation_score"])
+	}
+	if v, ok := m["rating_count"].(int32); ok {
+		r.RatingCount = v
+	} else {
+		return fmt.Errorf("PassengerRatingUpdated.rating_count: unexpected type %T", m["rating_count"])
+	}
+	if v, ok := m["no_show_count"].(int32); ok {
+		r.NoShowCount = v
+	} else {
+		return fmt.Errorf("PassengerRatingUpdated.no_show_count: unexpected type %T", m["no_show_count"])
+	}
+	if v, ok := m["occurred_at"].(time.Time); ok {
+		r.OccurredAt = v
+	} else {
+		return fmt.Errorf("PassengerRatingUpdated.occurred_at: unexpected type %T", m["occurred_at"])
+	}
+	return nil
+}
+
+// PaymentAuthorizationFailed 由 schema PaymentAuthorizationFailed/v1 生成。
+// Emitted when the expected fare cannot be held on the passenger's wallet.
+type PaymentAuthorizationFailed struct {
+	BookingID   string `avro:"booking_id"`
+	AmountCents int64  `avro:"amount_cents"`
+	Reason      string `avro:"reason"`
+}
+
+// SchemaID 返回生成该类型所用的 schema 版本。
+func (*PaymentAuthorizationFailed) SchemaID() string { return "PaymentAuthorizationFailed/v1" }
+
+// ToAvro 转换为 Avro 通用值。
+func (r *PaymentAuthorizationFailed) ToAvro() map[string]any {
+	return map[string]any{
+		"booking_id":   r.BookingID,
+		"amount_cents": r.AmountCents,
+		"reason":       r.Reason,
+	}
+}
+
+// FromAvro 从按本 schema 解析后的 Avro 通用值填充字段。
+func (r *PaymentAuthorizationFailed) FromAvro(m map[string]any) error {
+	if v, ok := m["booking_id"].(string); ok {
+		r.BookingID = v
+	} else {
+		return fmt.Errorf("PaymentAuthorizationFailed.booking_id: unexpected type %T", m["booking_id"])
+	}
+	if v, ok := m["amount_cents"].(int64); ok {
+		r.AmountCents = v
+	} else {
+		return fmt.Errorf("PaymentAuthorizationFailed.amount_cents: unexpected type %T", m["amount_cents"])
+	}
+	if v, ok := m["reason"].(string); ok {
+		r.Reason = v
+	} else {
+		return fmt.Errorf("PaymentAuthorizationFailed.reason: unexpected type %T", m["reason"])
+	}
+	return nil
+}
+
+// PaymentSucceeded 由 schema PaymentSucceeded/v1 生成。
+// Emitted when payment succeeds for a booking.
+type PaymentSucceeded struct {
+	BookingID   string `avro:"booking_id"`
+	AmountCents int64  `avro:"amount_cents"`
+}
+
+// SchemaID 返回生成该类型所用的 schema 版本。
+func (*PaymentSucceeded) SchemaID() string { return "PaymentSucceeded/v1" }
+
+// ToAvro 转换为 Avro 通用值。
+func (r *PaymentSucceeded) ToAvro() map[string]any {
+	return map[string]any{
+		"booking_id":   r.BookingID,
+		"amount_cents": r.AmountCents,
+	}
+}
+
+// FromAvro 从按本 schema 解析后的 Avro 通用值填充字段。
+func (r *PaymentSucceeded) FromAvro(m map[string]any) error {
+	if v, ok := m["booking_id"].(string); ok {
+		r.BookingID = v
+	} else {
+		return fmt.Errorf("PaymentSucceeded.booking_id: unexpected type %T", m["booking_id"])
+	}
+	if v, ok := m["amount_cents"].(int64); ok {
+		r.AmountCents = v
+	} else {
+		return fmt.Errorf("PaymentSucceeded.amount_cents: unexpected type %T", m["amount_cents"])
+	}
+	return nil
+}
+
+// PickupRequestClosed 由 schema PickupRequestClosed/v1 生成。
+// Emitted when an unmatched pickup request is withdrawn by the passenger or expires.
+type PickupRequestClosed struct {
+	RequestID   string `avro:"request_id"`
+	PassengerID string `avro:"passenger_id"`
+	AirportCode string `avro:"airport_code"`
+	VehicleType string `avro:"vehicle_type"`
+	// cancelled, expired
+	Status     string    `avro:"status"`
+	OccurredAt time.Time `avro:"occurred_at"`
+}
+
+// SchemaID 返回生成该类型所用的 schema 版本。
+func (*PickupRequestClosed) SchemaID() string { return "PickupRequestClosed/v1" }
+
+// ToAvro 转换为 Avro 通用值。
+func (r *PickupRequestClosed) ToAvro() map[string]any {
+	return map[string]any{
+		"request_id":   r.RequestID,
+		"passenger_id": r.PassengerID,
+		"airport_code": r.AirportCode,
+		"vehicle_type": r.VehicleType,
+		"status":       r.Status,
+		"occurred_at":  r.OccurredAt,
+	}
+}
+
+// FromAvro 从按本 schema 解析后的 Avro 通用值填充字段。
+func (r *PickupRequestClosed) FromAvro(m map[string]any) error {
+	if v, ok := m["request_id"].(string); ok {
+		r.RequestID = v
+	} else {
+		return fmt.Errorf("PickupRequestClosed.request_id: unexpected type %T", m["request_id"])
+	}
+	if v, ok := m["passenger_id"].(string); ok {
+		r.PassengerID = v
+	} else {
+		return fmt.Errorf("PickupRequestClosed.passenger_id: unexpected type %T", m["passenger_id"])
+	}
+	if v, ok := m["airport_code"].(string); ok {
+		r.AirportCode = v
+	} else {
+		return fmt.Errorf("PickupRequestClosed.airport_code: unexpected type %T", m["airport_code"])
+	}
+	if v, ok := m["vehicle_type"].(string); ok {
+		r.VehicleType = v
+	} else {
+		return fmt.Errorf("PickupRequestClosed.vehicle_type: unexpected type %T", m["vehicle_type"])
+	}
+	if v, ok := m["status"].(string); ok {
+		r.Status = v
+	} else {
+		return fmt.Errorf("PickupRequestClosed.status: unexpected type %T", m["status"])
+	}
+	if v, ok := m["occurred_at"].(time.Time); ok {
+		r.OccurredAt = v
+	} else {
+		return fmt.Errorf("PickupRequestClosed.occurred_at: unexpected type %T", m["occurred_at"])
+	}
+	return nil
+}
+
+// PickupRequestCreated 由 schema PickupRequestCreated/v5 生成。
+// Emitted when a passenger submits a pickup request.
+type PickupRequestCreated struct {
+	RequestID     string  `avro:"request_id"`
+	PassengerID   string  `avro:"passenger_id"`
+	AirportCode   string  `avro:"airport_code"`
+	VehicleType   string  `avro:"vehicle_type"`
+	MaxPricePerKm float64 `avro:"max_price_per_km"`
+	// ISO 4217 settlement currency of the airport
+	Currency         string    `avro:"currency"`
+	PreferHighRating bool      `avro:"prefer_high_rating"`
+	DesiredTime      time.Time `avro:"desired_time"`
+	// open, matched, cancelled
+	Status string `avro:"status"`
+	// promo code redeemed at settlement, empty for none
+	PromoCode string `avro:"promo_code"`
+	// fare quote whose terms the request was created with, empty for none
+	QuoteID string `avro:"quote_id"`
+	// passenger reputation score, checked against offers' min_passenger_score
+	PassengerScore float64 `avro:"passenger_score"`
+	// deposit pre-authorized for low-reputation passengers, in minor units; 0 for none
+	DepositCents int64 `avro:"deposit_cents"`
+	// saved payment method charged at settlement, empty for the passenger wallet
+	PaymentMethodID string `avro:"payment_method_id"`
+}
+
+// SchemaID 返回生成该类型所用的 schema 版本。
+func (*PickupRequestCreated) SchemaID() string { return "PickupRequestCreated/v5" }
+
+// ToAvro 转换为 Avro 通用值。
+func (r *PickupRequestCreated) ToAvro() map[string]any {
+	return map[string]any{
+		"request_id":         r.RequestID,
+		"passenger_id":       r.PassengerID,
+		"airport_code":       r.AirportCode,
+		"vehicle_type":       r.VehicleType,
+		"max_price_per_km":   r.MaxPricePerKm,
+		"currency":           r.Currency,
+		"prefer_high_rating": r.PreferHighRating,
+		"desired_time":       r.DesiredTime,
+		"status":             r.Status,
+		"promo_code":         r.PromoCode,
+		"quote_id":           r.QuoteID,
+		"passenger_score":    r.PassengerScore,
+		"deposit_cents":      r.DepositCents,
+		"payment_method_id":  r.PaymentMethodID,
+	}
+}
+
+// FromAvro 从按本 schema 解析后的 Avro 通用值填充字段。
+func (r *PickupRequestCreated) FromAvro(m map[string]any) error {
+	if v, ok := m["request_id"].(string); ok {
+		r.RequestID = v
+	} else {
+		return fmt.Errorf("PickupRequestCreated.request_id: unexpected type %T", m["request_id"])
+	}
+	if v, ok := m["passenger_id"].(string); ok {
+		r.PassengerID = v
+	} else {
+		return fmt.Errorf("PickupRequestCreated.passenger_id: unexpected type %T", m["passenger_id"])
+	}
+	if v, ok := m["airport_code"].(string); ok {
+		r.AirportCode = v
+	} else {
+		return fmt.Errorf("PickupRequestCreated.airport_code: unexpected type %T", m["airport_code"])
+	}
+	if v, ok := m["vehicle_type"].(string); ok {
+		r.VehicleType = v
+	} else {
+		return fmt.Errorf("PickupRequestCreated.vehicle_type: unexpected type %T", m["vehicle_type"])
+	}
+	if v, ok := m["max_price_per_km"].(float64); ok {
+		r.MaxPricePerKm = v
+	} else {
+		return fmt.Errorf("PickupRequestCreated.max_price_per_km: unexpected type %T", m["max_price_per_km"])
+	}
+	if v, ok := m["currency"].(string); ok {
+		r.Currency = v
+	} else {
+		return fmt.Errorf("PickupRequestCreated.currency: unexpected type %T", m["currency"])
+	}
+	if v, ok := m["prefer_high_rating"].(bool); ok {
+		r.PreferHighRating = v
+	} else {
+		return fmt.Errorf("PickupRequestCreated.prefer_high_rating: unexpected type %T", m["prefer_high_rating"])
+	}
+	if v, ok := m["desired_time"].(time.Time); ok {
+		r.DesiredTime = v
+	} else {
+		return fmt.Errorf("PickupRequestCreated.desired_time: unexpected type %T", m["desired_time"])
+	}
+	if v, ok := m["status"].(string); ok {
+		r.Status = v
+	} else {
+		return fmt.Errorf("PickupRequestCreated.status: unexpected type %T", m["status"])
+	}
+	if v, ok := m["promo_code"].(string); ok {
+		r.PromoCode = v
+	} else {
+		return fmt.Errorf("PickupRequestCreated.promo_code: unexpected type %T", m["promo_code"])
+	}
+	if v, ok := m["quote_id"].(string); ok {
+		r.QuoteID = v
+	} else {
+		return fmt.Errorf("PickupRequestCreated.quote_id: unexpected type %T", m["quote_id"])
+	}
+	if v, ok := m["passenger_score"].(float64); ok {
+		r.PassengerScore = v
+	} else {
+		return fmt.Errorf("PickupRequestCreated.passenger_score: unexpected type %T", m["passenger_score"])
+	}
+	if v, ok := m["deposit_cents"].(int64); ok {
+		r.DepositCents = v
+	} else {
+		return fmt.Errorf("PickupRequestCreated.deposit_cents: unexpected type %T", m["deposit_cents"])
+	}
+	if v, ok := m["payment_method_id"].(string); ok {
+		r.PaymentMethodID = v
+	} else {
+		return fmt.Errorf("PickupRequestCreated.payment_method_id: unexpected type %T", m["payment_method_id"])
+	}
+	return nil
+}
+
+// RevenueUpdated 由 schema RevenueUpdated/v1 生成。
+// Emitted when platform revenue is updated.
+type RevenueUpdated struct {
+	BookingID  string `avro:"booking_id"`
+	DeltaCents int64  `avro:"delta_cents"`
+}
+
+// SchemaID 返回生成该类型所用的 schema 版本。
+func (*RevenueUpdated) SchemaID() string { return "RevenueUpdated/v1" }
+
+// ToAvro 转换为 Avro 通用值。
+func (r *RevenueUpdated) ToAvro() map[string]any {
+	return map[string]any{
+		"booking_id":  r.BookingID,
+		"delta_cents": r.DeltaCents,
+	}
+}
+
+// FromAvro 从按本 schema 解析后的 Avro 通用值填充字段。
+func (r *RevenueUpdated) FromAvro(m map[string]any) error {
+	if v, ok := m["booking_id"].(string); ok {
+		r.BookingID = v
+	} else {
+		return fmt.Errorf("RevenueUpdated.booking_id: unexpected type %T", m["booking_id"])
+	}
+	if v, ok := m["delta_cents"].(int64); ok {
+		r.DeltaCents = v
+	} else {
+		return fmt.Errorf("RevenueUpdated.delta_cents: unexpected type %T", m["delta_cents"])
+	}
+	return nil
+}
+
+// SettlementCreated 由 schema SettlementCreated/v1 生成。
+// Emitted when settlement is created for a booking.
+type SettlementCreated struct {
+	BookingID string `avro:"booking_id"`
+}
+
+// SchemaID 返回生成该类型所用的 schema 版本。
+func (*SettlementCreated) SchemaID() string { return "SettlementCreated/v1" }
+
+// ToAvro 转换为 Avro 通用值。
+func (r *SettlementCreated) ToAvro() map[string]any {
+	return map[string]any{
+		"booking_id": r.BookingID,
+	}
+}
+
+// FromAvro 从按本 schema 解析后的 Avro 通用值填充字段。
+func (r *SettlementCreated) FromAvro(m map[string]any) error {
+	if v, ok := m["booking_id"].(string); ok {
+		r.BookingID = v
+	} else {
+		return fmt.Errorf("SettlementCreated.booking_id: unexpected type %T", m["booking_id"])
+	}
+	return nil
+}
+
+// SurgeStarted 由 schema SurgeStarted/v1 生成。
+// Emitted when the target surge multiplier of an order book rises to the notify threshold.
+type SurgeStarted struct {
+	AirportCode       string  `avro:"airport_code"`
+	VehicleType       string  `avro:"vehicle_type"`
+	Multiplier        float64 `avro:"multiplier"`
+	TargetMultiplier  float64 `avro:"target_multiplier"`
+	OpenRequests      int32   `avro:"open_requests"`
+	OpenOffers        int32   `avro:"open_offers"`
+	SuggestedBidPerKm float64 `avro:"suggested_bid_per_km"`
+	// ISO 4217 currency of the suggested bid, empty when no offer was seen
+	Currency   string    `avro:"currency"`
+	OccurredAt time.Time `avro:"occurred_at"`
+}
+
+// SchemaID 返回生成该类型所用的 schema 版本。
+func (*SurgeStarted) SchemaID() string { return "SurgeStarted/v1" }
+
+// ToAvro 转换为 Avro 通用值。
+func (r *SurgeStarted) ToAvro() map[string]any {
+	return map[string]any{
+		"airport_code":         r.AirportCode,
+		"vehicle_type":         r.VehicleType,
+		"multiplier":           r.Multiplier,
+		"target_multiplier":    r.TargetMultiplier,
+		"open_requests":        r.OpenRequests,
+		"open_offers":          r.OpenOffers,
+		"suggested_bid_per_km": r.SuggestedBidPerKm,
+		"currency":             r.Currency,
+		"occurred_at":          r.OccurredAt,
+	}
+}
+
+// FromAvro 从按本 schema 解析后的 Avro 通用值填充字段。
+func (r *SurgeStarted) FromAvro(m map[string]any) error {
+	if v, ok := m["airport_code"].(string); ok {
+		r.AirportCode = v
+	} else {
+		return fmt.Errorf("SurgeStarted.airport_code: unexpected type %T", m["airport_code"])
+	}
+	if v, ok := m["vehicle_type"].(string); ok {
+		r.VehicleType = v
+	} else {
+		return fmt.Errorf("SurgeStarted.vehicle_type: unexpected type %T", m["vehicle_type"])
+	}
+	if v, ok := m["multiplier"].(float64); ok {
+		r.Multiplier = v
+	} else {
+		return fmt.Errorf("SurgeStarted.multiplier: unexpected type %T", m["multiplier"])
+	}
+	if v, ok := m["target_multiplier"].(float64); ok {
+		r.TargetMultiplier = v
+	} else {
+		return fmt.Errorf("SurgeStarted.target_multiplier: unexpected type %T", m["target_multiplier"])
+	}
+	if v, ok := m["open_requests"].(int32); ok {
+		r.OpenRequests = v
+	} else {
+		return fmt.Errorf("SurgeStarted.open_requests: unexpected type %T", m["open_requests"])
+	}
+	if v, ok := m["open_offers"].(int32); ok {
+		r.OpenOffers = v
+	} else {
+		return fmt.Errorf("SurgeStarted.open_offers: unexpected type %T", m["open_offers"])
+	}
+	if v, ok := m["suggested_bid_per_km"].(float64); ok {
+		r.SuggestedBidPerKm = v
+	} else {
+		return fmt.Errorf("SurgeStarted.suggested_bid_per_km: unexpected type %T", m["suggested_bid_per_km"])
+	}
+	if v, ok := m["currency"].(string); ok {
+		r.Currency = v
+	} else {
+		return fmt.Errorf("SurgeStarted.currency: unexpected type %T", m["currency"])
+	}
+	if v, ok := m["occurred_at"].(time.Time); ok {
+		r.OccurredAt = v
+	} else {
+		return fmt.Errorf("SurgeStarted.occurred_at: unexpected type %T", m["occurred_at"])
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package eventbus
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+
+	evt "github.com/gavin/airport-pickup/internal/domain/eventbus"
+	"github.com/gavin/airport-pickup/pkg/avro"
+	"github.com/gavin/airport-pickup/pkg/eventbus/avroevents"
+	"github.com/gavin/airport-pickup/schemas"
+)
+
+const (
+	EncodingJSON = "json"
+	EncodingAvro = "avro"
+
+	ContentTypeJSON = "application/json"
+	ContentTypeAvro = "application/avro"
+)
+
+// Codec 负责事件载荷的编解码。发布端按配置的编码写入，并在 content-type 中声明编码
+// （Avro 另带 schema=<Event>/v<N>）；消费端按消息自带的 content-type 解码，
+// 因此迁移期间 JSON 与 Avro 消息可以在同一 topic 中共存。
+type Codec struct {
+	encoding string
+	registry *avro.Registry
+}
+
+// NewCodec 创建编解码器，encoding 为 json（默认）或 avro。
+func NewCodec(encoding string) (*Codec, error) {
+	if encoding == "" {
+		encoding = EncodingJSON
+	}
+	if encoding != EncodingJSON && encoding != EncodingAvro {
+		return nil, fmt.Errorf("unknown event encoding %q", encoding)
+	}
+	reg, err := schemas.LoadEvents()
+	if err != nil {
+		return nil, err
+	}
+	return &Codec{encoding: encoding, registry: reg}, nil
+}
+
+// Encode 序列化事件，返回载荷与 content-type。
+func (c *Codec) Encode(e evt.Event) ([]byte, string, error) {
+	if c.encoding == EncodingJSON {
+		b, err := json.Marshal(e)
+		return b, ContentTypeJSON, err
+	}
+	rec, err := avroevents.FromEvent(e)
+	if err != nil {
+		return nil, "", err
+	}
+	sv, ok := c.registry.Lookup(rec.SchemaID())
+	if !ok {
+		return nil, "", fmt.Errorf("schema %s not registered", rec.SchemaID())
+	}
+	b, err := avro.Encode(sv.Schema, rec.ToAvro())
+	if err != nil {
+		return nil, "", err
+	}
+	return b, mime.FormatMediaType(ContentTypeAvro, map[string]string{"schema": sv.ID()}), nil
+}
+
+// Decode 按 content-type 反序列化事件；缺省 content-type 的旧消息按 JSON 处理。
+// Avro 消息以写入方 schema 解码，再解析为本进程生成代码所用的 schema 版本。
+func (c *Codec) Decode(name, contentType string, payload []byte) (evt.Event, error) {
+	if contentType == "" {
+		return DecodeEvent(name, payload)
+	}
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, fmt.Errorf("invalid content-type %q: %w", contentType, err)
+	}
+	switch mediaType {
+	case ContentTypeJSON:
+		return DecodeEvent(name, payload)
+	case ContentTypeAvro:
+		return c.decodeAvro(name, params["schema"], payload)
+	}
+	return nil, fmt.Errorf("unsupported content-type %q", contentType)
+}
+
+func (c *Codec) decodeAvro(name, schemaID string, payload []byte) (evt.Event, error) {
+	writer, ok := c.registry.Lookup(schemaID)
+	if !ok {
+		return nil, fmt.Errorf("unknown writer schema %q", schemaID)
+	}
+	if writer.Subject != name {
+		return nil, fmt.Errorf("schema %s does not match event %s", schemaID, name)
+	}
+	rec := avroevents.New(name)
+	if rec == nil {
+		return rawEvent(name), nil
+	}
+	reader, ok := c.registry.Lookup(rec.SchemaID())
+	if !ok {
+		return nil, fmt.Errorf("reader schema %s not registered", rec.SchemaID())
+	}
+	v, err := avro.Decode(writer.Schema, reader.Schema, payload)
+	if err != nil {
+		return nil, fmt.Errorf("decode %s: %w", schemaID, err)
+	}
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil, errors.New("decoded avro value is not a record")
+	}
+	if err := rec.FromAvro(m); err != nil {
+		return nil, err
+	}
+	return avroevents.ToEvent(rec)
+}
+
+// DecodeEvent 将 JSON 载荷按事件名反序列化为具体领域事件类型。
+// 未知事件返回仅带名称的事件（没有订阅者，便于新旧版本共存），载荷非法时返回错误。
+func DecodeEvent(name string, payload []byte) (evt.Event, error) {
+	var (
+		v   evt.Event
+		err error
+	)
+	switch name {
+	case evt.EventOrderMatched:
+		v, err = unmarshalAs[evt.OrderMatched](payload)
+	case evt.EventOrderCompleted:
+		v, err = unmarshalAs[evt.OrderCompleted](payload)
+	case evt.EventOrderCancelled:
+		v, err = unmarshalAs[evt.OrderCancelled](payload)
+	case evt.EventPaymentAuthFailed:
+		v, err = unmarshalAs[evt.PaymentAuthorizationFailed](payload)
+	case evt.EventPaymentSucceeded:
+		v, err = unmarshalAs[evt.PaymentSucceeded](payload)
+	case evt.EventSettlementCreated:
+		v, err = unmarshalAs[evt.SettlementCreated](payload)
+	case evt.EventRevenueUpdated:
+		v, err = unmarshalAs[evt.RevenueUpdated](payload)
+	case evt.EventPickupRequestCreated:
+		v, err = unmarshalAs[evt.PickupRequestCreated](payload)
+	case evt.EventDriverOfferCreated:
+		v, err = unmarshalAs[evt.DriverOfferCreated](payload)
+	case evt.EventSurgeStarted:
+		v, err = unmarshalAs[evt.SurgeStarted](payload)
+	case evt.EventDriverRatingUpdated:
+		v, err = unmarshalAs[evt.DriverRatingUpdated](payload)
+	case evt.EventPassengerRatingUpdated:
+		v, err = unmarshalAs[evt.PassengerRatingUpdated](payload)
+	case evt.EventDriverStatusChanged:
+		v, err = unmarshalAs[evt.DriverStatusChanged](payload)
+	case evt.EventPickupRequestClosed:
+		v, err = unmarshalAs[evt.PickupRequestClosed](payload)
+	default:
+		return rawEvent(name), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal %s: %w", name, err)
+	}
+	return v, nil
+}
+
+func unmarshalAs[T evt.Event](payload []byte) (evt.Event, error) {
+	var v T
+	if err := json.Unmarshal(payload, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+type rawEvent string
+
+func (r rawEvent) Name() string { return string(r) }
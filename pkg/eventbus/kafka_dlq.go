@@ -0,0 +1,217 @@
+package eventbus
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+// DeadLetter 是死信 topic 中的一条消息。
+type DeadLetter struct {
+	Partition     int32
+	Offset        int64
+	EventName     string
+	ContentType   string
+	Attempts      int
+	OriginalTopic string
+	LastError     string
+	Timestamp     time.Time
+	Key           []byte
+	Payload       []byte
+}
+
+// DeadLetterAdmin 提供死信消息的查看、重放与丢弃，供运维命令使用。
+// 处理进度记录在独立消费组的位点上：位点之前的死信视为已处理。
+type DeadLetterAdmin struct {
+	client   sarama.Client
+	producer sarama.SyncProducer
+	offsets  sarama.OffsetManager
+	topic    string
+}
+
+// NewDeadLetterAdmin 连接 Kafka；groupID 为记录处理进度的消费组。
+func NewDeadLetterAdmin(brokers []string, topic, groupID string) (*DeadLetterAdmin, error) {
+	cfg := sarama.NewConfig()
+	cfg.Version = sarama.V2_8_0_0
+	cfg.Producer.RequiredAcks = sarama.WaitForAll
+	cfg.Producer.Return.Successes = true
+	cfg.Consumer.Offsets.Initial = sarama.OffsetOldest
+	cfg.Consumer.Offsets.AutoCommit.Enable = false
+
+	client, err := sarama.NewClient(brokers, cfg)
+	if err != nil {
+		return nil, err
+	}
+	prod, err := sarama.NewSyncProducerFromClient(client)
+	if err != nil {
+		_ = client.Close()
+		return nil, err
+	}
+	om, err := sarama.NewOffsetManagerFromClient(groupID, client)
+	if err != nil {
+		_ = prod.Close()
+		_ = client.Close()
+		return nil, err
+	}
+	return &DeadLetterAdmin{client: client, producer: prod, offsets: om, topic: topic}, nil
+}
+
+// List 返回各分区尚未处理的死信，最多 limit 条（<=0 表示不限）。
+func (a *DeadLetterAdmin) List(limit int) ([]DeadLetter, error) {
+	partitions, err := a.client.Partitions(DeadLetterTopic(a.topic))
+	if err != nil {
+		return nil, err
+	}
+	res := make([]DeadLetter, 0)
+	for _, p := range partitions {
+		remain := -1
+		if limit > 0 {
+			remain = limit - len(res)
+			if remain <= 0 {
+				break
+			}
+		}
+		lst, pom, err := a.pending(p, -1, remain)
+		if err != nil {
+			return nil, err
+		}
+		_ = pom.Close()
+		res = append(res, lst...)
+	}
+	return res, nil
+}
+
+// Replay 将分区内截至 upToOffset（-1 表示全部）的未处理死信重新投递到原 topic，并推进处理位点。
+func (a *DeadLetterAdmin) Replay(partition int32, upToOffset int64) (int, error) {
+	lst, pom, err := a.pending(partition, upToOffset, -1)
+	if err != nil {
+		return 0, err
+	}
+	defer pom.Close()
+	for i, d := range lst {
+		target := d.OriginalTopic
+		if target == "" {
+			target = a.topic
+		}
+		msg := &sarama.ProducerMessage{
+			Topic: target,
+			Value: sarama.ByteEncoder(d.Payload),
+			Headers: []sarama.RecordHeader{
+				{Key: []byte(headerEventName), Value: []byte(d.EventName)},
+				{Key: []byte(headerContentType), Value: []byte(d.ContentType)},
+			},
+		}
+		if d.Key != nil {
+			msg.Key = sarama.ByteEncoder(d.Key)
+		}
+		if _, _, err := a.producer.SendMessage(msg); err != nil {
+			// 已重放的部分仍需提交，避免重复投递
+			return i, errors.Join(fmt.Errorf("replay offset %d: %w", d.Offset, err), a.commit(pom, lst[:i]))
+		}
+	}
+	return len(lst), a.commit(pom, lst)
+}
+
+// Discard 丢弃分区内截至 upToOffset（-1 表示全部）的未处理死信。
+func (a *DeadLetterAdmin) Discard(partition int32, upToOffset int64) (int, error) {
+	lst, pom, err := a.pending(partition, upToOffset, -1)
+	if err != nil {
+		return 0, err
+	}
+	defer pom.Close()
+	return len(lst), a.commit(pom, lst)
+}
+
+// Close 关闭连接。
+func (a *DeadLetterAdmin) Close() error {
+	return errors.Join(a.offsets.Close(), a.producer.Close(), a.client.Close())
+}
+
+// pending 读取分区内处理位点之后、截至 upToOffset 的死信。
+// 返回的 PartitionOffsetManager 由调用方关闭。
+func (a *DeadLetterAdmin) pending(partition int32, upToOffset int64, limit int) ([]DeadLetter, sarama.PartitionOffsetManager, error) {
+	topic := DeadLetterTopic(a.topic)
+	pom, err := a.offsets.ManagePartition(topic, partition)
+	if err != nil {
+		return nil, nil, err
+	}
+	closeOnErr := func(err error) ([]DeadLetter, sarama.PartitionOffsetManager, error) {
+		_ = pom.Close()
+		return nil, nil, err
+	}
+
+	start, _ := pom.NextOffset()
+	oldest, err := a.client.GetOffset(topic, partition, sarama.OffsetOldest)
+	if err != nil {
+		return closeOnErr(err)
+	}
+	if start < oldest {
+		start = oldest
+	}
+	end, err := a.client.GetOffset(topic, partition, sarama.OffsetNewest)
+	if err != nil {
+		return closeOnErr(err)
+	}
+	if upToOffset >= 0 && upToOffset+1 < end {
+		end = upToOffset + 1
+	}
+	res := make([]DeadLetter, 0)
+	if start >= end {
+		return res, pom, nil
+	}
+
+	consumer, err := sarama.NewConsumerFromClient(a.client)
+	if err != nil {
+		return closeOnErr(err)
+	}
+	defer consumer.Close()
+	pc, err := consumer.ConsumePartition(topic, partition, start)
+	if err != nil {
+		return closeOnErr(err)
+	}
+	defer pc.Close()
+
+	for msg := range pc.Messages() {
+		if msg.Offset >= end {
+			break
+		}
+		res = append(res, toDeadLetter(msg))
+		if msg.Offset == end-1 || (limit > 0 && len(res) >= limit) {
+			break
+		}
+	}
+	return res, pom, nil
+}
+
+func (a *DeadLetterAdmin) commit(pom sarama.PartitionOffsetManager, handled []DeadLetter) error {
+	if len(handled) == 0 {
+		return nil
+	}
+	pom.MarkOffset(handled[len(handled)-1].Offset+1, "")
+	a.offsets.Commit()
+	select {
+	case err := <-pom.Errors():
+		return err
+	default:
+		return nil
+	}
+}
+
+func toDeadLetter(msg *sarama.ConsumerMessage) DeadLetter {
+	attempts, _ := strconv.Atoi(headerValue(msg, headerRetryAttempt))
+	return DeadLetter{
+		Partition:     msg.Partition,
+		Offset:        msg.Offset,
+		EventName:     headerValue(msg, headerEventName),
+		ContentType:   headerValue(msg, headerContentType),
+		Attempts:      attempts,
+		OriginalTopic: headerValue(msg, headerOriginalTopic),
+		LastError:     headerValue(msg, headerLastError),
+		Timestamp:     msg.Timestamp,
+		Key:           msg.Key,
+		Payload:       msg.Value,
+	}
+}
@@ -2,9 +2,11 @@ package eventbus
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"runtime/debug"
+	"strconv"
 	"sync"
 	"time"
 
@@ -12,24 +14,69 @@ import (
 	evt "github.com/gavin/airport-pickup/internal/domain/eventbus"
 )
 
-const headerEventName = "event-name"
+const (
+	headerEventName      = "event-name"
+	headerContentType    = "content-type"
+	headerRetryAttempt   = "retry-attempt"
+	headerRetryNotBefore = "retry-not-before" // unix 毫秒
+	headerOriginalTopic  = "original-topic"
+	headerLastError      = "last-error"
+)
+
+// RetryPolicy 描述处理失败事件的重试策略。
+// 第 n 次重试投递到 <topic>.retry.<n>，延迟 BaseDelay * 2^(n-1)；
+// 超过 MaxAttempts 次仍失败则投递到 <topic>.dlq。
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+}
+
+// DefaultRetryPolicy 默认重试 3 次，延迟 1s、2s、4s。
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{MaxAttempts: 3, BaseDelay: time.Second}
+}
+
+// Delay 返回第 attempt 次重试的延迟。
+func (p RetryPolicy) Delay(attempt int) time.Duration {
+	if attempt <= 0 {
+		return 0
+	}
+	return p.BaseDelay << (attempt - 1)
+}
+
+// RetryTopic 返回第 attempt 次重试使用的 topic。
+// 每个重试级别使用独立 topic，保证同一 topic 内消息的到期时间单调递增。
+func RetryTopic(topic string, attempt int) string {
+	return fmt.Sprintf("%s.retry.%d", topic, attempt)
+}
+
+// DeadLetterTopic 返回死信 topic。
+func DeadLetterTopic(topic string) string { return topic + ".dlq" }
 
 // KafkaEventBus 基于 Kafka 的事件总线实现。
+//
+// 顺序保证：消息以聚合键（evt.AggregateKey：订单事件为 BookingID，订单簿事件为 airport:vehicle）
+// 作为 Kafka key，经哈希分区器写入同一分区，因此同一聚合的事件按发布顺序被消费；
+// 不同分区由消费组并发处理，分区内顺序处理。
+// 例外：进入重试 topic 的事件会晚于同一聚合后续发布的事件被处理。
 type KafkaEventBus struct {
 	producer sarama.SyncProducer
 	group    sarama.ConsumerGroup
 	topic    string
+	retry    RetryPolicy
+	codec    *Codec
 
 	mu        sync.RWMutex
-	handlers  map[string][]func(evt.Event)
+	handlers  map[string][]evt.Handler
 	ctx       context.Context
 	cancel    context.CancelFunc
 	startOnce sync.Once
 	closed    chan struct{}
 }
 
-// NewKafkaEventBus 初始化生产者和消费组。
-func NewKafkaEventBus(brokers []string, topic, groupID string) (*KafkaEventBus, error) {
+// NewKafkaEventBus 初始化生产者和消费组，并确保重试与死信 topic 存在。
+// codec 决定发布时的载荷编码，消费时按消息的 content-type 解码。
+func NewKafkaEventBus(brokers []string, topic, groupID string, retry RetryPolicy, codec *Codec) (*KafkaEventBus, error) {
 	version, err := sarama.ParseKafkaVersion("2.8.0")
 	if err != nil {
 		return nil, err
@@ -43,6 +90,7 @@ func NewKafkaEventBus(brokers []string, topic, groupID string) (*KafkaEventBus,
 	pcfg.Net.MaxOpenRequests = 1
 	pcfg.Producer.Retry.Max = 6
 	pcfg.Producer.Retry.Backoff = 100 * time.Millisecond
+	pcfg.Producer.Partitioner = sarama.NewHashPartitioner // 同一聚合键落在同一分区
 
 	ccfg := sarama.NewConfig()
 	ccfg.Version = version
@@ -50,6 +98,10 @@ func NewKafkaEventBus(brokers []string, topic, groupID string) (*KafkaEventBus,
 	ccfg.Consumer.Offsets.Initial = sarama.OffsetNewest
 	ccfg.Consumer.Return.Errors = true
 
+	if err := ensureTopics(brokers, version, topic, retry); err != nil {
+		log.Printf("[eventbus] ensure retry topics failed (rely on broker auto-create): %v", err)
+	}
+
 	prod, err := sarama.NewSyncProducer(brokers, pcfg)
 	if err != nil {
 		return nil, err
@@ -65,27 +117,66 @@ func NewKafkaEventBus(brokers []string, topic, groupID string) (*KafkaEventBus,
 		producer: prod,
 		group:    group,
 		topic:    topic,
-		handlers: make(map[string][]func(evt.Event)),
+		retry:    retry,
+		codec:    codec,
+		handlers: make(map[string][]evt.Handler),
 		ctx:      ctx,
 		cancel:   cancel,
 		closed:   make(chan struct{}),
 	}, nil
 }
 
-// Publish: 将事件名写入 header，并序列化事件为 JSON。
+// ensureTopics 创建缺失的重试与死信 topic，分区数与副本数沿用 broker 默认值。
+func ensureTopics(brokers []string, version sarama.KafkaVersion, topic string, retry RetryPolicy) error {
+	cfg := sarama.NewConfig()
+	cfg.Version = version
+	admin, err := sarama.NewClusterAdmin(brokers, cfg)
+	if err != nil {
+		return err
+	}
+	defer admin.Close()
+
+	existing, err := admin.ListTopics()
+	if err != nil {
+		return err
+	}
+	wanted := append(retryTopics(topic, retry), DeadLetterTopic(topic))
+	for _, t := range wanted {
+		if _, ok := existing[t]; ok {
+			continue
+		}
+		err := admin.CreateTopic(t, &sarama.TopicDetail{NumPartitions: -1, ReplicationFactor: -1}, false)
+		if err != nil && !errors.Is(err, sarama.ErrTopicAlreadyExists) {
+			return fmt.Errorf("create topic %s: %w", t, err)
+		}
+	}
+	return nil
+}
+
+func retryTopics(topic string, retry RetryPolicy) []string {
+	res := make([]string, 0, retry.MaxAttempts)
+	for i := 1; i <= retry.MaxAttempts; i++ {
+		res = append(res, RetryTopic(topic, i))
+	}
+	return res
+}
+
+// Publish: 以聚合键作为消息 key，将事件名与 content-type 写入 header，按 codec 序列化事件。
 func (k *KafkaEventBus) Publish(e evt.Event) {
-	log.Printf("[eventbus] publish event: %s, value: %+v", e.Name(), e)
-	b, err := json.Marshal(e)
+	key := evt.AggregateKey(e)
+	log.Printf("[eventbus] publish event: %s, key: %s, value: %+v", e.Name(), key, e)
+	b, contentType, err := k.codec.Encode(e)
 	if err != nil {
 		log.Printf("[eventbus] marshal event %s error: %v", e.Name(), err)
 		return
 	}
 	msg := &sarama.ProducerMessage{
 		Topic: k.topic,
-		Key:   sarama.StringEncoder(e.Name()),
+		Key:   sarama.StringEncoder(key),
 		Value: sarama.ByteEncoder(b),
 		Headers: []sarama.RecordHeader{
 			{Key: []byte(headerEventName), Value: []byte(e.Name())},
+			{Key: []byte(headerContentType), Value: []byte(contentType)},
 		},
 	}
 	partition, offset, err := k.producer.SendMessage(msg)
@@ -97,7 +188,7 @@ func (k *KafkaEventBus) Publish(e evt.Event) {
 }
 
 // Subscribe: 注册处理器，并在首次调用时启动消费循环。
-func (k *KafkaEventBus) Subscribe(eventName string, handler func(evt.Event)) {
+func (k *KafkaEventBus) Subscribe(eventName string, handler evt.Handler) {
 	log.Printf("[eventbus] subscribe event: %s", eventName)
 	k.mu.Lock()
 	k.handlers[eventName] = append(k.handlers[eventName], handler)
@@ -126,8 +217,9 @@ func (k *KafkaEventBus) Close() error {
 func (k *KafkaEventBus) consumeLoop() {
 	defer close(k.closed)
 	handler := &cgHandler{bus: k}
+	topics := append([]string{k.topic}, retryTopics(k.topic, k.retry)...)
 	for {
-		if err := k.group.Consume(k.ctx, []string{k.topic}, handler); err != nil {
+		if err := k.group.Consume(k.ctx, topics, handler); err != nil {
 			if k.ctx.Err() != nil {
 				return
 			}
@@ -140,6 +232,8 @@ func (k *KafkaEventBus) consumeLoop() {
 	}
 }
 
+// cgHandler 由 sarama 为每个分区分别调用 ConsumeClaim（各自独立的 goroutine），
+// 分区之间并发、分区内按 offset 顺序处理。
 type cgHandler struct{ bus *KafkaEventBus }
 
 func (h *cgHandler) Setup(s sarama.ConsumerGroupSession) error   { return nil }
@@ -147,90 +241,135 @@ func (h *cgHandler) Cleanup(s sarama.ConsumerGroupSession) error { return nil }
 
 func (h *cgHandler) ConsumeClaim(sess sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
 	for msg := range claim.Messages() {
-		name := ""
-		for _, hd := range msg.Headers {
-			if string(hd.Key) == headerEventName {
-				name = string(hd.Value)
-				break
-			}
-		}
-		if name == "" { // fallback to key
-			if msg.Key != nil {
-				name = string(msg.Key)
-			}
+		// 处理失败且无法转投重试 topic 时不提交位点，结束本次会话等待重新投递
+		if err := h.bus.handleMessage(sess.Context(), msg); err != nil {
+			log.Printf("[kafka] handle message failed, topic=%s, partition=%d, offset=%d: %v", msg.Topic, msg.Partition, msg.Offset, err)
+			return err
 		}
-		if name == "" {
-			sess.MarkMessage(msg, "")
-			continue
+		sess.MarkMessage(msg, "")
+	}
+	return nil
+}
+
+// errMissingEventName 消息缺少 event-name 头。
+var errMissingEventName = errors.New("missing event-name header")
+
+// handleMessage 等待重试消息到期、分发给处理器，失败时转投下一级重试 topic 或死信 topic。
+// 仅当消息既未处理成功也未成功转投时返回错误。
+func (k *KafkaEventBus) handleMessage(ctx context.Context, msg *sarama.ConsumerMessage) error {
+	name := headerValue(msg, headerEventName)
+	if name == "" {
+		// 消息 key 是聚合键而非事件名，缺少事件名头的消息无法解码，直接进入死信
+		return k.reroute(msg, name, k.retry.MaxAttempts+1, errMissingEventName)
+	}
+
+	attempt, _ := strconv.Atoi(headerValue(msg, headerRetryAttempt))
+	if attempt > 0 {
+		if err := waitUntil(ctx, headerValue(msg, headerRetryNotBefore)); err != nil {
+			return err
 		}
+	}
 
-		log.Printf("[eventbus] received event: %s, partition=%d, offset=%d, value=%s", name, msg.Partition, msg.Offset, string(msg.Value))
+	log.Printf("[eventbus] received event: %s, topic=%s, partition=%d, offset=%d, attempt=%d, bytes=%d", name, msg.Topic, msg.Partition, msg.Offset, attempt, len(msg.Value))
 
-		ev := decodeEvent(name, msg.Value)
+	ev, err := k.codec.Decode(name, headerValue(msg, headerContentType), msg.Value)
+	if err != nil {
+		// 载荷无法解码时重试没有意义，直接进入死信
+		return k.reroute(msg, name, k.retry.MaxAttempts+1, err)
+	}
+	if err := k.dispatch(name, ev); err != nil {
+		return k.reroute(msg, name, attempt+1, err)
+	}
+	return nil
+}
 
-		h.bus.mu.RLock()
-		handlers := append([]func(evt.Event){}, h.bus.handlers[name]...)
-		h.bus.mu.RUnlock()
+// dispatch 依次调用处理器，panic 视为处理失败。
+func (k *KafkaEventBus) dispatch(name string, ev evt.Event) error {
+	k.mu.RLock()
+	handlers := append([]evt.Handler{}, k.handlers[name]...)
+	k.mu.RUnlock()
+	return runHandlers(handlers, ev)
+}
 
-		for _, cb := range handlers {
-			func() {
-				defer func() {
-					if r := recover(); r != nil {
-						log.Printf("[kafka] handler panic: %v\n%s", r, debug.Stack())
-					}
-				}()
-				cb(ev)
+// runHandlers 调用全部处理器并合并错误，panic 转换为错误。
+func runHandlers(handlers []evt.Handler, ev evt.Event) error {
+	var errs []error
+	for _, cb := range handlers {
+		err := func() (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					log.Printf("[eventbus] handler panic: %v\n%s", r, debug.Stack())
+					err = fmt.Errorf("handler panic: %v", r)
+				}
 			}()
+			return cb(ev)
+		}()
+		if err != nil {
+			errs = append(errs, err)
 		}
+	}
+	return errors.Join(errs...)
+}
 
-		sess.MarkMessage(msg, "")
+// reroute 将失败消息投递到第 attempt 次重试 topic，超过上限则投递死信 topic。
+func (k *KafkaEventBus) reroute(msg *sarama.ConsumerMessage, name string, attempt int, cause error) error {
+	target := DeadLetterTopic(k.topic)
+	notBefore := time.Now()
+	if attempt <= k.retry.MaxAttempts {
+		target = RetryTopic(k.topic, attempt)
+		notBefore = notBefore.Add(k.retry.Delay(attempt))
+	}
+	original := headerValue(msg, headerOriginalTopic)
+	if original == "" {
+		original = msg.Topic
 	}
+	out := &sarama.ProducerMessage{
+		Topic: target,
+		Value: sarama.ByteEncoder(msg.Value),
+		Headers: []sarama.RecordHeader{
+			{Key: []byte(headerEventName), Value: []byte(name)},
+			{Key: []byte(headerContentType), Value: []byte(headerValue(msg, headerContentType))},
+			{Key: []byte(headerRetryAttempt), Value: []byte(strconv.Itoa(attempt))},
+			{Key: []byte(headerRetryNotBefore), Value: []byte(strconv.FormatInt(notBefore.UnixMilli(), 10))},
+			{Key: []byte(headerOriginalTopic), Value: []byte(original)},
+			{Key: []byte(headerLastError), Value: []byte(cause.Error())},
+		},
+	}
+	if msg.Key != nil {
+		out.Key = sarama.ByteEncoder(msg.Key)
+	}
+	if _, _, err := k.producer.SendMessage(out); err != nil {
+		return fmt.Errorf("reroute event %s to %s: %w", name, target, err)
+	}
+	log.Printf("[eventbus] event %s failed (%v), rerouted to %s", name, cause, target)
 	return nil
 }
 
-// decodeEvent 根据事件名反序列化为具体领域事件类型。
-func decodeEvent(name string, payload []byte) evt.Event {
-	switch name {
-	case evt.EventOrderMatched:
-		var v evt.OrderMatched
-		if err := json.Unmarshal(payload, &v); err == nil {
-			return v
-		}
-	case evt.EventOrderCompleted:
-		var v evt.OrderCompleted
-		if err := json.Unmarshal(payload, &v); err == nil {
-			return v
-		}
-	case evt.EventPaymentSucceeded:
-		var v evt.PaymentSucceeded
-		if err := json.Unmarshal(payload, &v); err == nil {
-			return v
-		}
-	case evt.EventSettlementCreated:
-		var v evt.SettlementCreated
-		if err := json.Unmarshal(payload, &v); err == nil {
-			return v
-		}
-	case evt.EventRevenueUpdated:
-		var v evt.RevenueUpdated
-		if err := json.Unmarshal(payload, &v); err == nil {
-			return v
-		}
-	case evt.EventPickupRequestCreated:
-		var v evt.PickupRequestCreated
-		if err := json.Unmarshal(payload, &v); err == nil {
-			return v
-		}
-	case evt.EventDriverOfferCreated:
-		var v evt.DriverOfferCreated
-		if err := json.Unmarshal(payload, &v); err == nil {
-			return v
-		}
+// waitUntil 阻塞至 notBefore（unix 毫秒）或 ctx 结束。
+func waitUntil(ctx context.Context, notBefore string) error {
+	ms, err := strconv.ParseInt(notBefore, 10, 64)
+	if err != nil {
+		return nil
+	}
+	d := time.Until(time.UnixMilli(ms))
+	if d <= 0 {
+		return nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
 	}
-	// 默认返回一个仅带名称的事件，避免丢失。
-	return rawEvent(name)
 }
 
-type rawEvent string
-
-func (r rawEvent) Name() string { return string(r) }
+func headerValue(msg *sarama.ConsumerMessage, key string) string {
+	for _, hd := range msg.Headers {
+		if hd != nil && string(hd.Key) == key {
+			return string(hd.Value)
+		}
+	}
+	return ""
+}
@@ -4,11 +4,13 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
 	"github.com/IBM/sarama"
 	evt "github.com/gavin/airport-pickup/internal/domain/eventbus"
+	"github.com/gavin/airport-pickup/internal/domain/money"
 )
 
 // mockSyncProducer 实现 sarama.SyncProducer 接口
@@ -88,7 +90,9 @@ func TestKafkaEventBus_Publish(t *testing.T) {
 		producer: prod,
 		group:    group,
 		topic:    "test-topic",
-		handlers: make(map[string][]func(evt.Event)),
+		retry:    DefaultRetryPolicy(),
+		codec:    mustCodec(EncodingJSON),
+		handlers: make(map[string][]evt.Handler),
 		ctx:      context.Background(),
 		cancel:   func() {},
 		closed:   make(chan struct{}),
@@ -100,8 +104,8 @@ func TestKafkaEventBus_Publish(t *testing.T) {
 	}
 	msg := prod.msgs[0]
 	keyBytes, _ := msg.Key.Encode()
-	if string(keyBytes) != e.Name() {
-		t.Errorf("expected key %s, got %s", e.Name(), string(keyBytes))
+	if string(keyBytes) != "bkid" {
+		t.Errorf("expected key bkid, got %s", string(keyBytes))
 	}
 	if msg.Topic != "test-topic" {
 		t.Errorf("expected topic 'test-topic', got %s", msg.Topic)
@@ -109,6 +113,9 @@ func TestKafkaEventBus_Publish(t *testing.T) {
 	if len(msg.Headers) == 0 || string(msg.Headers[0].Key) != "event-name" || string(msg.Headers[0].Value) != e.Name() {
 		t.Errorf("expected header 'event-name' with value %s, got %+v", e.Name(), msg.Headers)
 	}
+	if ct := producedHeader(msg, headerContentType); ct != ContentTypeJSON {
+		t.Errorf("expected content-type %s, got %q", ContentTypeJSON, ct)
+	}
 	valBytes, _ := msg.Value.Encode()
 	var got evt.OrderMatched
 	if err := json.Unmarshal(valBytes, &got); err != nil {
@@ -127,13 +134,15 @@ func TestKafkaEventBus_Subscribe_Start_Close(t *testing.T) {
 		producer: prod,
 		group:    group,
 		topic:    "test-topic",
-		handlers: make(map[string][]func(evt.Event)),
+		retry:    DefaultRetryPolicy(),
+		codec:    mustCodec(EncodingJSON),
+		handlers: make(map[string][]evt.Handler),
 		ctx:      ctx,
 		cancel:   cancel, // 修复：赋值 cancel 方法
 		closed:   make(chan struct{}),
 	}
-	bus.Subscribe("OrderMatched", func(e evt.Event) {}) // Subscribe 已启动消费循环
-	bus.Start()                                         // 再次调用不会重复启动
+	bus.Subscribe("OrderMatched", func(e evt.Event) error { return nil }) // Subscribe 已启动消费循环
+	bus.Start()                                                           // 再次调用不会重复启动
 
 	// 等待 goroutine 设置 consumeCalled，避免竞态
 	deadline := time.Now().Add(200 * time.Millisecond)
@@ -151,7 +160,10 @@ func TestKafkaEventBus_Subscribe_Start_Close(t *testing.T) {
 func TestDecodeEvent(t *testing.T) {
 	e := evt.OrderMatched{BookingID: "bkid", RequestID: "rid", DriverOfferID: "doid"}
 	b, _ := json.Marshal(e)
-	res := decodeEvent(evt.EventOrderMatched, b)
+	res, err := DecodeEvent(evt.EventOrderMatched, b)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
 	om, ok := res.(evt.OrderMatched)
 	if !ok {
 		t.Fatalf("expected OrderMatched type, got %T", res)
@@ -161,8 +173,245 @@ func TestDecodeEvent(t *testing.T) {
 	}
 
 	// 测试未知事件名
-	res2 := decodeEvent("UnknownEvent", []byte(`{"foo":"bar"}`))
-	if res2.Name() != "UnknownEvent" {
-		t.Errorf("expected rawEvent name 'UnknownEvent', got %s", res2.Name())
+	res2, err := DecodeEvent("UnknownEvent", []byte(`{"foo":"bar"}`))
+	if err != nil || res2.Name() != "UnknownEvent" {
+		t.Errorf("expected rawEvent name 'UnknownEvent', got %v, %v", res2, err)
+	}
+
+	// 已知事件载荷非法时返回错误，不再静默降级
+	if _, err := DecodeEvent(evt.EventOrderMatched, []byte(`{"BookingID":1}`)); err == nil {
+		t.Errorf("expected error for malformed payload, got nil")
+	}
+}
+
+func mustCodec(encoding string) *Codec {
+	c, err := NewCodec(encoding)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+func newTestBus(prod *mockSyncProducer) *KafkaEventBus {
+	return &KafkaEventBus{
+		producer: prod,
+		group:    &mockConsumerGroup{},
+		topic:    "test-topic",
+		retry:    RetryPolicy{MaxAttempts: 2, BaseDelay: time.Second},
+		codec:    mustCodec(EncodingJSON),
+		handlers: make(map[string][]evt.Handler),
+		ctx:      context.Background(),
+		cancel:   func() {},
+		closed:   make(chan struct{}),
+	}
+}
+
+func completedMessage(topic string, headers ...*sarama.RecordHeader) *sarama.ConsumerMessage {
+	b, _ := json.Marshal(evt.OrderCompleted{BookingID: "bk1"})
+	hs := append([]*sarama.RecordHeader{{Key: []byte(headerEventName), Value: []byte(evt.EventOrderCompleted)}}, headers...)
+	return &sarama.ConsumerMessage{Topic: topic, Key: []byte("bk1"), Value: b, Headers: hs}
+}
+
+func producedHeader(msg *sarama.ProducerMessage, key string) string {
+	for _, h := range msg.Headers {
+		if string(h.Key) == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+func TestKafkaEventBus_HandleMessage_Success(t *testing.T) {
+	prod := &mockSyncProducer{}
+	bus := newTestBus(prod)
+	var got string
+	bus.handlers[evt.EventOrderCompleted] = []evt.Handler{func(e evt.Event) error {
+		got = e.(evt.OrderCompleted).BookingID
+		return nil
+	}}
+	if err := bus.handleMessage(context.Background(), completedMessage("test-topic")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "bk1" {
+		t.Errorf("expected handler to receive bk1, got %q", got)
+	}
+	if len(prod.msgs) != 0 {
+		t.Errorf("expected no rerouted message, got %d", len(prod.msgs))
+	}
+}
+
+func TestKafkaEventBus_HandleMessage_FailureGoesToRetryTopic(t *testing.T) {
+	prod := &mockSyncProducer{}
+	bus := newTestBus(prod)
+	bus.handlers[evt.EventOrderCompleted] = []evt.Handler{func(e evt.Event) error { return errors.New("db down") }}
+
+	if err := bus.handleMessage(context.Background(), completedMessage("test-topic")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(prod.msgs) != 1 {
+		t.Fatalf("expected 1 rerouted message, got %d", len(prod.msgs))
+	}
+	out := prod.msgs[0]
+	if out.Topic != "test-topic.retry.1" {
+		t.Errorf("expected retry topic test-topic.retry.1, got %s", out.Topic)
+	}
+	if producedHeader(out, headerRetryAttempt) != "1" {
+		t.Errorf("expected attempt 1, got %q", producedHeader(out, headerRetryAttempt))
+	}
+	if producedHeader(out, headerOriginalTopic) != "test-topic" {
+		t.Errorf("expected original topic test-topic, got %q", producedHeader(out, headerOriginalTopic))
+	}
+	if producedHeader(out, headerLastError) != "db down" {
+		t.Errorf("expected last error 'db down', got %q", producedHeader(out, headerLastError))
+	}
+}
+
+func TestKafkaEventBus_HandleMessage_ExhaustedGoesToDLQ(t *testing.T) {
+	prod := &mockSyncProducer{}
+	bus := newTestBus(prod)
+	bus.handlers[evt.EventOrderCompleted] = []evt.Handler{func(e evt.Event) error { panic("boom") }}
+
+	msg := completedMessage("test-topic.retry.2",
+		&sarama.RecordHeader{Key: []byte(headerRetryAttempt), Value: []byte("2")},
+		&sarama.RecordHeader{Key: []byte(headerOriginalTopic), Value: []byte("test-topic")},
+	)
+	if err := bus.handleMessage(context.Background(), msg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(prod.msgs) != 1 || prod.msgs[0].Topic != "test-topic.dlq" {
+		t.Fatalf("expected message in test-topic.dlq, got %+v", prod.msgs)
+	}
+	if producedHeader(prod.msgs[0], headerRetryAttempt) != "3" {
+		t.Errorf("expected attempt 3, got %q", producedHeader(prod.msgs[0], headerRetryAttempt))
+	}
+}
+
+func TestKafkaEventBus_HandleMessage_RerouteFailure(t *testing.T) {
+	prod := &mockSyncProducer{fail: true}
+	bus := newTestBus(prod)
+	bus.handlers[evt.EventOrderCompleted] = []evt.Handler{func(e evt.Event) error { return errors.New("db down") }}
+	if err := bus.handleMessage(context.Background(), completedMessage("test-topic")); err == nil {
+		t.Errorf("expected error when reroute fails, got nil")
+	}
+}
+
+func TestKafkaEventBus_HandleMessage_WaitsForRetryDelay(t *testing.T) {
+	bus := newTestBus(&mockSyncProducer{})
+	notBefore := time.Now().Add(time.Hour).UnixMilli()
+	msg := completedMessage("test-topic.retry.1",
+		&sarama.RecordHeader{Key: []byte(headerRetryAttempt), Value: []byte("1")},
+		&sarama.RecordHeader{Key: []byte(headerRetryNotBefore), Value: []byte(fmt.Sprint(notBefore))},
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := bus.handleMessage(ctx, msg); err == nil {
+		t.Errorf("expected context error while waiting for retry delay, got nil")
+	}
+}
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 3, BaseDelay: time.Second}
+	if p.Delay(1) != time.Second || p.Delay(2) != 2*time.Second || p.Delay(3) != 4*time.Second {
+		t.Errorf("unexpected delays: %v %v %v", p.Delay(1), p.Delay(2), p.Delay(3))
+	}
+}
+
+func TestKafkaEventBus_Publish_AggregateKey(t *testing.T) {
+	prod := &mockSyncProducer{}
+	bus := newTestBus(prod)
+	bus.Publish(evt.OrderMatched{BookingID: "bk1"})
+	bus.Publish(evt.OrderCompleted{BookingID: "bk1"})
+	bus.Publish(evt.PickupRequestCreated{RequestID: "r1", AirportCode: "PVG", VehicleType: "sedan"})
+	bus.Publish(evt.DriverOfferCreated{OfferID: "o1", AirportCode: "PVG", VehicleType: "sedan"})
+
+	want := []string{"bk1", "bk1", "PVG:sedan", "PVG:sedan"}
+	if len(prod.msgs) != len(want) {
+		t.Fatalf("expected %d messages, got %d", len(want), len(prod.msgs))
+	}
+	for i, msg := range prod.msgs {
+		k, _ := msg.Key.Encode()
+		if string(k) != want[i] {
+			t.Errorf("message %d: expected key %s, got %s", i, want[i], string(k))
+		}
+	}
+}
+
+func TestKafkaEventBus_AvroRoundTrip(t *testing.T) {
+	prod := &mockSyncProducer{}
+	bus := newTestBus(prod)
+	bus.codec = mustCodec(EncodingAvro)
+	want := evt.DriverOfferCreated{
+		OfferID: "o1", DriverID: "d1", AirportCode: "PVG", VehicleType: "sedan",
+		AvailableFrom: time.UnixMilli(1700000000000).UTC(), AvailableTo: time.UnixMilli(1700003600000).UTC(),
+		PricePerKm: money.MustParse("3.5"), Currency: "USD", Rating: 4.8, Status: "open", MinPassengerScore: 4, VehicleID: "v1",
+	}
+	bus.Publish(want)
+	if len(prod.msgs) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(prod.msgs))
+	}
+	out := prod.msgs[0]
+	if ct := producedHeader(out, headerContentType); ct != `application/avro; schema="DriverOfferCreated/v4"` {
+		t.Errorf("unexpected content-type %q", ct)
+	}
+
+	// 以 JSON 编码配置的消费者同样能解码 Avro 消息
+	consumer := newTestBus(&mockSyncProducer{})
+	var got evt.Event
+	consumer.handlers[evt.EventDriverOfferCreated] = []evt.Handler{func(e evt.Event) error { got = e; return nil }}
+	val, _ := out.Value.Encode()
+	msg := &sarama.ConsumerMessage{Topic: "test-topic", Value: val}
+	for _, h := range out.Headers {
+		msg.Headers = append(msg.Headers, &sarama.RecordHeader{Key: h.Key, Value: h.Value})
+	}
+	if err := consumer.handleMessage(context.Background(), msg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != want {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+}
+
+func TestKafkaEventBus_HandleMessage_UndecodableGoesToDLQ(t *testing.T) {
+	prod := &mockSyncProducer{}
+	bus := newTestBus(prod)
+	called := false
+	bus.handlers[evt.EventOrderCompleted] = []evt.Handler{func(e evt.Event) error { called = true; return nil }}
+
+	msg := completedMessage("test-topic", &sarama.RecordHeader{Key: []byte(headerContentType), Value: []byte(ContentTypeJSON)})
+	msg.Value = []byte("not json")
+	if err := bus.handleMessage(context.Background(), msg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if called {
+		t.Errorf("handler should not be called for undecodable payload")
+	}
+	if len(prod.msgs) != 1 || prod.msgs[0].Topic != "test-topic.dlq" {
+		t.Fatalf("expected message in test-topic.dlq, got %+v", prod.msgs)
+	}
+	if producedHeader(prod.msgs[0], headerContentType) != ContentTypeJSON {
+		t.Errorf("expected content-type preserved, got %q", producedHeader(prod.msgs[0], headerContentType))
+	}
+}
+
+func TestKafkaEventBus_HandleMessage_MissingNameGoesToDLQ(t *testing.T) {
+	prod := &mockSyncProducer{}
+	bus := newTestBus(prod)
+	called := false
+	bus.handlers[evt.EventOrderCompleted] = []evt.Handler{func(e evt.Event) error { called = true; return nil }}
+
+	msg := completedMessage("test-topic")
+	msg.Headers = nil
+	msg.Key = []byte(evt.EventOrderCompleted) // key 不再作为事件名
+	if err := bus.handleMessage(context.Background(), msg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if called {
+		t.Errorf("handler should not be called without event-name header")
+	}
+	if len(prod.msgs) != 1 || prod.msgs[0].Topic != "test-topic.dlq" {
+		t.Fatalf("expected message in test-topic.dlq, got %+v", prod.msgs)
+	}
+	if got := producedHeader(prod.msgs[0], headerLastError); got != errMissingEventName.Error() {
+		t.Errorf("expected last error %q, got %q", errMissingEventName.Error(), got)
 	}
 }
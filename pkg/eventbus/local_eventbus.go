@@ -0,0 +1,45 @@
+package eventbus
+
+import (
+	"log"
+	"sync"
+
+	evt "github.com/gavin/airport-pickup/internal/domain/eventbus"
+)
+
+// LocalEventBus 进程内同步事件总线：Publish 立即在调用方 goroutine 中执行处理器。
+// 用于事件重放等离线工具，事件不会离开当前进程。
+type LocalEventBus struct {
+	mu       sync.RWMutex
+	handlers map[string][]evt.Handler
+	// OnPublish 若非空，在处理器执行前回调，可用于记录处理器派生的事件。
+	OnPublish func(evt.Event)
+}
+
+func NewLocalEventBus() *LocalEventBus {
+	return &LocalEventBus{handlers: make(map[string][]evt.Handler)}
+}
+
+func (b *LocalEventBus) Subscribe(eventName string, handler evt.Handler) {
+	b.mu.Lock()
+	b.handlers[eventName] = append(b.handlers[eventName], handler)
+	b.mu.Unlock()
+}
+
+// Publish 同步分发事件，错误仅记录日志。
+func (b *LocalEventBus) Publish(e evt.Event) {
+	if b.OnPublish != nil {
+		b.OnPublish(e)
+	}
+	if err := b.Dispatch(e); err != nil {
+		log.Printf("[eventbus] local dispatch %s error: %v", e.Name(), err)
+	}
+}
+
+// Dispatch 同步调用事件的全部处理器并返回合并后的错误。
+func (b *LocalEventBus) Dispatch(e evt.Event) error {
+	b.mu.RLock()
+	handlers := append([]evt.Handler{}, b.handlers[e.Name()]...)
+	b.mu.RUnlock()
+	return runHandlers(handlers, e)
+}
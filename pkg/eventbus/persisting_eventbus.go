@@ -0,0 +1,79 @@
+package eventbus
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+
+	evt "github.com/gavin/airport-pickup/internal/domain/eventbus"
+)
+
+// 追加事件存储失败时的默认尝试次数与首次重试间隔（之后每次翻倍）。
+const (
+	defaultAppendAttempts = 3
+	defaultAppendBackoff  = 100 * time.Millisecond
+)
+
+// PersistingEventBus 装饰任意事件总线：发布前先将事件追加到事件存储，供审计与重放。
+type PersistingEventBus struct {
+	evt.EventBus
+	store    evt.EventStore
+	attempts int
+	backoff  time.Duration
+}
+
+// NewPersistingEventBus 包装 next，所有经 Publish 发布的事件写入 store。
+func NewPersistingEventBus(next evt.EventBus, store evt.EventStore) *PersistingEventBus {
+	return &PersistingEventBus{EventBus: next, store: store, attempts: defaultAppendAttempts, backoff: defaultAppendBackoff}
+}
+
+// WithAppendRetry 设置追加事件存储的尝试次数与首次重试间隔。
+func (p *PersistingEventBus) WithAppendRetry(attempts int, backoff time.Duration) *PersistingEventBus {
+	if attempts > 0 {
+		p.attempts = attempts
+	}
+	if backoff >= 0 {
+		p.backoff = backoff
+	}
+	return p
+}
+
+// Publish: 追加到事件存储后转发，保证转发的事件都能审计与重放。
+// 追加失败时按退避重试，仍失败则不转发，并在日志中记录事件载荷供人工补发。
+func (p *PersistingEventBus) Publish(e evt.Event) {
+	se, err := ToStoredEvent(e, time.Now())
+	if err != nil {
+		log.Printf("[eventstore] marshal event %s error, event not published: %v", e.Name(), err)
+		return
+	}
+	if err := p.append(se); err != nil {
+		log.Printf("[eventstore] append event %s failed after %d attempts, event not published: %v payload=%s", e.Name(), p.attempts, err, se.Payload)
+		return
+	}
+	p.EventBus.Publish(e)
+}
+
+func (p *PersistingEventBus) append(se *evt.StoredEvent) error {
+	var err error
+	delay := p.backoff
+	for i := 0; i < p.attempts; i++ {
+		if i > 0 {
+			time.Sleep(delay)
+			delay *= 2
+		}
+		if err = p.store.Append(se); err == nil {
+			return nil
+		}
+		log.Printf("[eventstore] append event %s attempt %d error: %v", se.Name, i+1, err)
+	}
+	return err
+}
+
+// ToStoredEvent 将领域事件序列化为事件存储记录。
+func ToStoredEvent(e evt.Event, at time.Time) (*evt.StoredEvent, error) {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+	return &evt.StoredEvent{Name: e.Name(), AggregateKey: evt.AggregateKey(e), Payload: b, OccurredAt: at}, nil
+}
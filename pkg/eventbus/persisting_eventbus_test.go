@@ -0,0 +1,93 @@
+package eventbus
+
+import (
+	"errors"
+	"testing"
+
+	evt "github.com/gavin/airport-pickup/internal/domain/eventbus"
+)
+
+type memEventStore struct {
+	events   []*evt.StoredEvent
+	fail     bool
+	failures int // 前 failures 次追加失败
+	appends  int
+}
+
+func (m *memEventStore) Append(e *evt.StoredEvent) error {
+	m.appends++
+	if m.fail || m.appends <= m.failures {
+		return errors.New("mock append error")
+	}
+	e.Seq = int64(len(m.events) + 1)
+	m.events = append(m.events, e)
+	return nil
+}
+func (m *memEventStore) List(_ evt.EventFilter) ([]*evt.StoredEvent, error) { return m.events, nil }
+
+func TestPersistingEventBus_Publish(t *testing.T) {
+	store := &memEventStore{}
+	local := NewLocalEventBus()
+	var handled []string
+	local.Subscribe(evt.EventOrderCompleted, func(e evt.Event) error {
+		handled = append(handled, e.(evt.OrderCompleted).BookingID)
+		return nil
+	})
+	bus := NewPersistingEventBus(local, store)
+	bus.Publish(evt.OrderCompleted{BookingID: "bk1"})
+
+	if len(store.events) != 1 {
+		t.Fatalf("expected 1 stored event, got %d", len(store.events))
+	}
+	se := store.events[0]
+	if se.Name != evt.EventOrderCompleted || se.AggregateKey != "bk1" || se.OccurredAt.IsZero() {
+		t.Errorf("unexpected stored event: %+v", se)
+	}
+	if got, err := DecodeEvent(se.Name, se.Payload); err != nil || got != (evt.OrderCompleted{BookingID: "bk1"}) {
+		t.Errorf("stored payload does not round-trip: %+v", got)
+	}
+	if len(handled) != 1 {
+		t.Errorf("expected event forwarded to inner bus, got %v", handled)
+	}
+}
+
+func TestPersistingEventBus_RetriesAppend(t *testing.T) {
+	store := &memEventStore{failures: 2}
+	local := NewLocalEventBus()
+	called := false
+	local.Subscribe(evt.EventOrderCompleted, func(e evt.Event) error { called = true; return nil })
+	NewPersistingEventBus(local, store).WithAppendRetry(3, 0).Publish(evt.OrderCompleted{BookingID: "bk1"})
+	if store.appends != 3 || len(store.events) != 1 {
+		t.Errorf("expected append to succeed on the 3rd attempt, got appends=%d stored=%d", store.appends, len(store.events))
+	}
+	if !called {
+		t.Errorf("expected event to be published once stored")
+	}
+}
+
+func TestPersistingEventBus_StoreFailureNotPublished(t *testing.T) {
+	store := &memEventStore{fail: true}
+	local := NewLocalEventBus()
+	called := false
+	local.Subscribe(evt.EventOrderCompleted, func(e evt.Event) error { called = true; return nil })
+	NewPersistingEventBus(local, store).WithAppendRetry(3, 0).Publish(evt.OrderCompleted{BookingID: "bk1"})
+	if store.appends != 3 {
+		t.Errorf("expected 3 append attempts, got %d", store.appends)
+	}
+	if called {
+		t.Errorf("expected event not to be published when it cannot be stored")
+	}
+}
+
+func TestLocalEventBus_Dispatch(t *testing.T) {
+	bus := NewLocalEventBus()
+	bus.Subscribe(evt.EventOrderMatched, func(e evt.Event) error { return errors.New("fail") })
+	bus.Subscribe(evt.EventOrderMatched, func(e evt.Event) error { panic("boom") })
+	err := bus.Dispatch(evt.OrderMatched{BookingID: "bk1"})
+	if err == nil {
+		t.Fatalf("expected joined error, got nil")
+	}
+	if err := bus.Dispatch(evt.OrderCompleted{BookingID: "bk1"}); err != nil {
+		t.Errorf("expected nil error without handlers, got %v", err)
+	}
+}
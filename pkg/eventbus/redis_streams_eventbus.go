@@ -0,0 +1,357 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	evt "github.com/gavin/airport-pickup/internal/domain/eventbus"
+	redis "github.com/redis/go-redis/v9"
+)
+
+const (
+	fieldEventName   = "event-name"
+	fieldKey         = "key"
+	fieldContentType = "content-type"
+	fieldPayload     = "payload"
+	fieldAttempts    = "attempts"
+	fieldLastError   = "last-error"
+)
+
+// streamClient 是 RedisStreamsEventBus 使用的 go-redis 命令子集，便于测试替换。
+type streamClient interface {
+	XAdd(ctx context.Context, a *redis.XAddArgs) *redis.StringCmd
+	XGroupCreateMkStream(ctx context.Context, stream, group, start string) *redis.StatusCmd
+	XReadGroup(ctx context.Context, a *redis.XReadGroupArgs) *redis.XStreamSliceCmd
+	XAck(ctx context.Context, stream, group string, ids ...string) *redis.IntCmd
+	XPendingExt(ctx context.Context, a *redis.XPendingExtArgs) *redis.XPendingExtCmd
+	XClaim(ctx context.Context, a *redis.XClaimArgs) *redis.XMessageSliceCmd
+	Close() error
+}
+
+// RedisStreamsOptions 配置 Redis Streams 事件总线。
+type RedisStreamsOptions struct {
+	Addr     string
+	Password string
+	DB       int
+
+	Stream string
+	Group  string
+	// MaxLen 为 XADD 时近似裁剪的 stream 长度上限，<=0 表示不裁剪。
+	// 裁剪可能删除尚未确认的消息，应远大于积压量。
+	MaxLen int64
+	// ClaimMinIdle 为认领其他消费者待确认消息前要求的最小空闲时间，用于接管崩溃消费者的消息。
+	ClaimMinIdle time.Duration
+	Retry        RetryPolicy
+	// Codec 决定发布时的载荷编码，消费时按消息的 content-type 字段解码。
+	Codec *Codec
+}
+
+// RedisStreamsEventBus 基于 Redis Streams 消费组的事件总线实现，订阅语义与 KafkaEventBus 一致：
+// 处理成功后 XACK；失败的消息保持待确认状态，按 RetryPolicy 的退避延迟被重新认领处理，
+// 超过 MaxAttempts 次重试后写入 <stream>.dlq 并确认。
+//
+// 单 stream 无分区，同一消费者内按写入顺序处理；多个消费者实例之间不保证顺序。
+type RedisStreamsEventBus struct {
+	cli      streamClient
+	stream   string
+	group    string
+	consumer string
+	maxLen   int64
+	minIdle  time.Duration
+	retry    RetryPolicy
+	codec    *Codec
+
+	mu        sync.RWMutex
+	handlers  map[string][]evt.Handler
+	failedMu  sync.Mutex
+	failed    map[string]struct{} // 本消费者处理失败、等待认领重试的消息 ID
+	ctx       context.Context
+	cancel    context.CancelFunc
+	startOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// NewRedisStreamsEventBus 连接 Redis 并创建消费组（stream 不存在时自动创建）。
+func NewRedisStreamsEventBus(opt RedisStreamsOptions) (*RedisStreamsEventBus, error) {
+	if opt.Stream == "" || opt.Group == "" {
+		return nil, errors.New("redis streams: stream and group required")
+	}
+	addr := opt.Addr
+	if addr == "" {
+		addr = "127.0.0.1:6379"
+	}
+	cli := redis.NewClient(&redis.Options{Addr: addr, Password: opt.Password, DB: opt.DB})
+	if err := cli.Ping(context.Background()).Err(); err != nil {
+		_ = cli.Close()
+		return nil, err
+	}
+	b := newRedisStreamsEventBus(cli, opt)
+	if err := b.ensureGroup(context.Background()); err != nil {
+		_ = cli.Close()
+		return nil, err
+	}
+	return b, nil
+}
+
+func newRedisStreamsEventBus(cli streamClient, opt RedisStreamsOptions) *RedisStreamsEventBus {
+	minIdle := opt.ClaimMinIdle
+	if minIdle <= 0 {
+		minIdle = 30 * time.Second
+	}
+	host, _ := os.Hostname()
+	ctx, cancel := context.WithCancel(context.Background())
+	return &RedisStreamsEventBus{
+		cli:      cli,
+		stream:   opt.Stream,
+		group:    opt.Group,
+		consumer: fmt.Sprintf("%s-%d", host, os.Getpid()),
+		maxLen:   opt.MaxLen,
+		minIdle:  minIdle,
+		retry:    opt.Retry,
+		codec:    opt.Codec,
+		handlers: make(map[string][]evt.Handler),
+		failed:   make(map[string]struct{}),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+func (b *RedisStreamsEventBus) ensureGroup(ctx context.Context) error {
+	err := b.cli.XGroupCreateMkStream(ctx, b.stream, b.group, "$").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return err
+	}
+	return nil
+}
+
+// Publish: XADD 事件，字段包含事件名、聚合键、content-type 与载荷。
+func (b *RedisStreamsEventBus) Publish(e evt.Event) {
+	log.Printf("[eventbus] publish event: %s, value: %+v", e.Name(), e)
+	payload, contentType, err := b.codec.Encode(e)
+	if err != nil {
+		log.Printf("[eventbus] marshal event %s error: %v", e.Name(), err)
+		return
+	}
+	args := &redis.XAddArgs{
+		Stream: b.stream,
+		Values: map[string]any{
+			fieldEventName: e.Name(), fieldKey: evt.AggregateKey(e),
+			fieldContentType: contentType, fieldPayload: string(payload),
+		},
+	}
+	if b.maxLen > 0 {
+		args.MaxLen = b.maxLen
+		args.Approx = true
+	}
+	id, err := b.cli.XAdd(b.ctx, args).Result()
+	if err != nil {
+		log.Printf("[eventbus] xadd event %s error: %v", e.Name(), err)
+		return
+	}
+	log.Printf("[eventbus] event %s sent successfully, stream=%s, id=%s", e.Name(), b.stream, id)
+}
+
+// Subscribe: 注册处理器，并在首次调用时启动消费与认领循环。
+func (b *RedisStreamsEventBus) Subscribe(eventName string, handler evt.Handler) {
+	log.Printf("[eventbus] subscribe event: %s", eventName)
+	b.mu.Lock()
+	b.handlers[eventName] = append(b.handlers[eventName], handler)
+	b.mu.Unlock()
+
+	b.Start()
+}
+
+// Start: 显式启动消费循环（可选）。
+func (b *RedisStreamsEventBus) Start() {
+	b.startOnce.Do(func() {
+		b.wg.Add(2)
+		go b.readLoop()
+		go b.claimLoop()
+	})
+}
+
+// Close: 停止消费并关闭连接。
+func (b *RedisStreamsEventBus) Close() error {
+	b.cancel()
+	b.wg.Wait()
+	return b.cli.Close()
+}
+
+// readLoop 读取分配给本消费者的新消息。
+func (b *RedisStreamsEventBus) readLoop() {
+	defer b.wg.Done()
+	for b.ctx.Err() == nil {
+		streams, err := b.cli.XReadGroup(b.ctx, &redis.XReadGroupArgs{
+			Group:    b.group,
+			Consumer: b.consumer,
+			Streams:  []string{b.stream, ">"},
+			Count:    16,
+			Block:    2 * time.Second,
+		}).Result()
+		if err != nil {
+			if errors.Is(err, redis.Nil) || b.ctx.Err() != nil {
+				continue
+			}
+			log.Printf("[redis-streams] xreadgroup error: %v", err)
+			time.Sleep(time.Second)
+			continue
+		}
+		for _, s := range streams {
+			for _, msg := range s.Messages {
+				b.handleMessage(b.ctx, msg, 1)
+			}
+		}
+	}
+}
+
+// claimLoop 定期认领到期的待确认消息：本消费者处理失败的消息按退避延迟重试，
+// 其他消费者的消息在空闲超过 ClaimMinIdle 后接管（视为消费者崩溃）。
+func (b *RedisStreamsEventBus) claimLoop() {
+	defer b.wg.Done()
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-b.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := b.reclaim(b.ctx); err != nil && b.ctx.Err() == nil {
+				log.Printf("[redis-streams] reclaim error: %v", err)
+			}
+		}
+	}
+}
+
+// reclaimPageSize 为每次 XPENDING 查询的待确认消息条数。
+const reclaimPageSize = 100
+
+// reclaim 按 ID 分页遍历全部待确认消息并认领到期的消息。每页从上一页最后一条之后开始（"(" 排他区间，需 Redis 6.2+），
+// 前面积压的未到期消息不会挡住后面已到期的消息。
+func (b *RedisStreamsEventBus) reclaim(ctx context.Context) error {
+	start := "-"
+	for {
+		pending, err := b.cli.XPendingExt(ctx, &redis.XPendingExtArgs{
+			Stream: b.stream, Group: b.group, Start: start, End: "+", Count: reclaimPageSize,
+		}).Result()
+		if err != nil {
+			return err
+		}
+		for _, pe := range pending {
+			if !b.claimable(pe) {
+				continue
+			}
+			msgs, err := b.cli.XClaim(ctx, &redis.XClaimArgs{
+				Stream: b.stream, Group: b.group, Consumer: b.consumer, MinIdle: pe.Idle, Messages: []string{pe.ID},
+			}).Result()
+			if err != nil {
+				return err
+			}
+			for _, msg := range msgs {
+				b.handleMessage(ctx, msg, int(pe.RetryCount)+1)
+			}
+		}
+		if len(pending) < reclaimPageSize || ctx.Err() != nil {
+			return nil
+		}
+		start = "(" + pending[len(pending)-1].ID
+	}
+}
+
+// claimable 判断待确认消息是否到期：退避延迟按已投递次数计算。
+// 本消费者的消息只有 handleMessage 记录了处理失败才重试，仍在 readLoop 批次中排队或正在处理的消息
+// 也处于待确认状态，不能认领，否则同一事件会被并发处理两次；其他消费者持有的消息另需空闲超过 ClaimMinIdle。
+func (b *RedisStreamsEventBus) claimable(pe redis.XPendingExt) bool {
+	if pe.Idle < b.retry.Delay(int(pe.RetryCount)) {
+		return false
+	}
+	if pe.Consumer == b.consumer {
+		b.failedMu.Lock()
+		_, failed := b.failed[pe.ID]
+		b.failedMu.Unlock()
+		return failed
+	}
+	return pe.Idle >= b.minIdle
+}
+
+// markFailed 记录本消费者未能确认的消息，交给 reclaim 按退避延迟重试。
+func (b *RedisStreamsEventBus) markFailed(id string) {
+	b.failedMu.Lock()
+	b.failed[id] = struct{}{}
+	b.failedMu.Unlock()
+}
+
+// handleMessage 分发消息；deliveries 为含本次在内的投递次数。
+// 成功或转入死信后 XACK，否则保持待确认并记为失败，等待 reclaim 重试。
+func (b *RedisStreamsEventBus) handleMessage(ctx context.Context, msg redis.XMessage, deliveries int) {
+	name, _ := msg.Values[fieldEventName].(string)
+	payload, _ := msg.Values[fieldPayload].(string)
+	if name == "" {
+		// 消息已被裁剪或格式非法，直接确认避免反复认领
+		b.ack(ctx, msg.ID)
+		return
+	}
+	contentType, _ := msg.Values[fieldContentType].(string)
+	log.Printf("[eventbus] received event: %s, stream=%s, id=%s, deliveries=%d, bytes=%d", name, b.stream, msg.ID, deliveries, len(payload))
+
+	ev, err := b.codec.Decode(name, contentType, []byte(payload))
+	if err == nil {
+		if err = b.dispatch(name, ev); err == nil {
+			b.ack(ctx, msg.ID)
+			return
+		}
+		if deliveries-1 < b.retry.MaxAttempts {
+			log.Printf("[eventbus] event %s failed (%v), will retry after %v", name, err, b.retry.Delay(deliveries))
+			b.markFailed(msg.ID)
+			return
+		}
+	}
+	// 重试耗尽或载荷无法解码（重试无意义）时转入死信
+	if err := b.deadLetter(ctx, msg, name, payload, deliveries, err); err != nil {
+		log.Printf("[redis-streams] dead-letter event %s error: %v", name, err)
+		b.markFailed(msg.ID)
+		return
+	}
+	b.ack(ctx, msg.ID)
+}
+
+// dispatch 依次调用处理器，panic 视为处理失败。
+func (b *RedisStreamsEventBus) dispatch(name string, ev evt.Event) error {
+	b.mu.RLock()
+	handlers := append([]evt.Handler{}, b.handlers[name]...)
+	b.mu.RUnlock()
+	return runHandlers(handlers, ev)
+}
+
+func (b *RedisStreamsEventBus) deadLetter(ctx context.Context, msg redis.XMessage, name, payload string, deliveries int, cause error) error {
+	key, _ := msg.Values[fieldKey].(string)
+	contentType, _ := msg.Values[fieldContentType].(string)
+	err := b.cli.XAdd(ctx, &redis.XAddArgs{
+		Stream: DeadLetterTopic(b.stream),
+		Values: map[string]any{
+			fieldEventName: name, fieldKey: key, fieldContentType: contentType, fieldPayload: payload,
+			fieldAttempts: deliveries, fieldLastError: cause.Error(),
+		},
+	}).Err()
+	if err == nil {
+		log.Printf("[eventbus] event %s failed (%v), moved to %s", name, cause, DeadLetterTopic(b.stream))
+	}
+	return err
+}
+
+// ack 确认消息；确认失败时记为失败，消息会被重新处理（至少一次投递）。
+func (b *RedisStreamsEventBus) ack(ctx context.Context, id string) {
+	if err := b.cli.XAck(ctx, b.stream, b.group, id).Err(); err != nil {
+		log.Printf("[redis-streams] xack %s error: %v", id, err)
+		b.markFailed(id)
+		return
+	}
+	b.failedMu.Lock()
+	delete(b.failed, id)
+	b.failedMu.Unlock()
+}
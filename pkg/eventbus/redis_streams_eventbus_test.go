@@ -0,0 +1,257 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	evt "github.com/gavin/airport-pickup/internal/domain/eventbus"
+	redis "github.com/redis/go-redis/v9"
+)
+
+// fakeStreamClient 记录 XADD / XACK 调用，不连接 Redis
+
+type fakeStreamClient struct {
+	added   []*redis.XAddArgs
+	acked   []string
+	pending []redis.XPendingExt // XPENDING 按 Start 与 Count 返回其中一页
+	starts  []string
+	claimed []string
+}
+
+func (f *fakeStreamClient) XAdd(ctx context.Context, a *redis.XAddArgs) *redis.StringCmd {
+	f.added = append(f.added, a)
+	cmd := redis.NewStringCmd(ctx)
+	cmd.SetVal("1-0")
+	return cmd
+}
+func (f *fakeStreamClient) XGroupCreateMkStream(ctx context.Context, _, _, _ string) *redis.StatusCmd {
+	return redis.NewStatusCmd(ctx)
+}
+func (f *fakeStreamClient) XReadGroup(ctx context.Context, _ *redis.XReadGroupArgs) *redis.XStreamSliceCmd {
+	cmd := redis.NewXStreamSliceCmd(ctx)
+	cmd.SetErr(redis.Nil)
+	return cmd
+}
+func (f *fakeStreamClient) XAck(ctx context.Context, _, _ string, ids ...string) *redis.IntCmd {
+	f.acked = append(f.acked, ids...)
+	return redis.NewIntCmd(ctx)
+}
+func (f *fakeStreamClient) XPendingExt(ctx context.Context, a *redis.XPendingExtArgs) *redis.XPendingExtCmd {
+	f.starts = append(f.starts, a.Start)
+	i := 0
+	if after, ok := strings.CutPrefix(a.Start, "("); ok {
+		for i < len(f.pending) && f.pending[i].ID <= after {
+			i++
+		}
+	}
+	page := f.pending[i:min(i+int(a.Count), len(f.pending))]
+	cmd := redis.NewXPendingExtCmd(ctx)
+	cmd.SetVal(page)
+	return cmd
+}
+func (f *fakeStreamClient) XClaim(ctx context.Context, a *redis.XClaimArgs) *redis.XMessageSliceCmd {
+	f.claimed = append(f.claimed, a.Messages...)
+	cmd := redis.NewXMessageSliceCmd(ctx)
+	msgs := make([]redis.XMessage, 0, len(a.Messages))
+	for _, id := range a.Messages {
+		msgs = append(msgs, streamMessage(id))
+	}
+	cmd.SetVal(msgs)
+	return cmd
+}
+func (f *fakeStreamClient) Close() error { return nil }
+
+func newTestStreamsBus(cli *fakeStreamClient) *RedisStreamsEventBus {
+	return newRedisStreamsEventBus(cli, RedisStreamsOptions{
+		Stream: "events", Group: "g", MaxLen: 1000, ClaimMinIdle: time.Minute,
+		Retry: RetryPolicy{MaxAttempts: 2, BaseDelay: time.Second},
+		Codec: mustCodec(EncodingJSON),
+	})
+}
+
+func streamMessage(id string) redis.XMessage {
+	b, _ := json.Marshal(evt.OrderCompleted{BookingID: "bk1"})
+	return redis.XMessage{ID: id, Values: map[string]any{fieldEventName: evt.EventOrderCompleted, fieldKey: "bk1", fieldPayload: string(b)}}
+}
+
+func TestRedisStreamsEventBus_Publish(t *testing.T) {
+	cli := &fakeStreamClient{}
+	bus := newTestStreamsBus(cli)
+	bus.Publish(evt.OrderCompleted{BookingID: "bk1"})
+	if len(cli.added) != 1 {
+		t.Fatalf("expected 1 xadd, got %d", len(cli.added))
+	}
+	args := cli.added[0]
+	if args.Stream != "events" || args.MaxLen != 1000 || !args.Approx {
+		t.Errorf("unexpected xadd args: %+v", args)
+	}
+	vals := args.Values.(map[string]any)
+	if vals[fieldEventName] != evt.EventOrderCompleted || vals[fieldKey] != "bk1" || vals[fieldContentType] != ContentTypeJSON {
+		t.Errorf("unexpected xadd values: %+v", vals)
+	}
+}
+
+func TestRedisStreamsEventBus_HandleMessage_AckOnSuccess(t *testing.T) {
+	cli := &fakeStreamClient{}
+	bus := newTestStreamsBus(cli)
+	var got string
+	bus.handlers[evt.EventOrderCompleted] = []evt.Handler{func(e evt.Event) error {
+		got = e.(evt.OrderCompleted).BookingID
+		return nil
+	}}
+	bus.handleMessage(context.Background(), streamMessage("1-0"), 1)
+	if got != "bk1" {
+		t.Errorf("expected handler to receive bk1, got %q", got)
+	}
+	if len(cli.acked) != 1 || cli.acked[0] != "1-0" {
+		t.Errorf("expected ack of 1-0, got %v", cli.acked)
+	}
+}
+
+func TestRedisStreamsEventBus_HandleMessage_FailureStaysPending(t *testing.T) {
+	cli := &fakeStreamClient{}
+	bus := newTestStreamsBus(cli)
+	bus.handlers[evt.EventOrderCompleted] = []evt.Handler{func(e evt.Event) error { return errors.New("db down") }}
+	bus.handleMessage(context.Background(), streamMessage("1-0"), 2)
+	if len(cli.acked) != 0 || len(cli.added) != 0 {
+		t.Errorf("expected message to stay pending, acked=%v added=%d", cli.acked, len(cli.added))
+	}
+}
+
+func TestRedisStreamsEventBus_HandleMessage_ExhaustedGoesToDLQ(t *testing.T) {
+	cli := &fakeStreamClient{}
+	bus := newTestStreamsBus(cli)
+	bus.handlers[evt.EventOrderCompleted] = []evt.Handler{func(e evt.Event) error { return errors.New("db down") }}
+	bus.handleMessage(context.Background(), streamMessage("1-0"), 3)
+	if len(cli.added) != 1 || cli.added[0].Stream != "events.dlq" {
+		t.Fatalf("expected xadd to events.dlq, got %+v", cli.added)
+	}
+	vals := cli.added[0].Values.(map[string]any)
+	if vals[fieldLastError] != "db down" || vals[fieldAttempts] != 3 {
+		t.Errorf("unexpected dead-letter values: %+v", vals)
+	}
+	if len(cli.acked) != 1 {
+		t.Errorf("expected dead-lettered message to be acked, got %v", cli.acked)
+	}
+}
+
+func TestRedisStreamsEventBus_HandleMessage_UndecodableGoesToDLQ(t *testing.T) {
+	cli := &fakeStreamClient{}
+	bus := newTestStreamsBus(cli)
+	msg := streamMessage("1-0")
+	msg.Values[fieldContentType] = `application/avro; schema="OrderCompleted/v1"`
+	msg.Values[fieldPayload] = "\xff"
+	bus.handleMessage(context.Background(), msg, 1)
+	if len(cli.added) != 1 || cli.added[0].Stream != "events.dlq" {
+		t.Fatalf("expected xadd to events.dlq on first delivery, got %+v", cli.added)
+	}
+	if len(cli.acked) != 1 {
+		t.Errorf("expected dead-lettered message to be acked, got %v", cli.acked)
+	}
+}
+
+func TestRedisStreamsEventBus_Claimable(t *testing.T) {
+	bus := newTestStreamsBus(&fakeStreamClient{})
+	bus.markFailed("1-0")
+	cases := []struct {
+		pe   redis.XPendingExt
+		want bool
+	}{
+		{redis.XPendingExt{ID: "1-0", Consumer: bus.consumer, Idle: 500 * time.Millisecond, RetryCount: 1}, false}, // 退避未到期
+		{redis.XPendingExt{ID: "1-0", Consumer: bus.consumer, Idle: 2 * time.Second, RetryCount: 1}, true},
+		{redis.XPendingExt{ID: "2-0", Consumer: bus.consumer, Idle: 2 * time.Hour, RetryCount: 1}, false}, // 未记录失败，可能仍在处理
+		{redis.XPendingExt{Consumer: "other", Idle: 2 * time.Second, RetryCount: 1}, false},               // 其他消费者仍可能存活
+		{redis.XPendingExt{Consumer: "other", Idle: 2 * time.Minute, RetryCount: 1}, true},
+	}
+	for i, c := range cases {
+		if got := bus.claimable(c.pe); got != c.want {
+			t.Errorf("case %d: expected %v, got %v", i, c.want, got)
+		}
+	}
+}
+
+func TestRedisStreamsEventBus_ReclaimPagesThroughPending(t *testing.T) {
+	cli := &fakeStreamClient{}
+	// 前 250 条退避未到期，只有最后一条到期，须翻页才能认领
+	for i := 0; i < 250; i++ {
+		cli.pending = append(cli.pending, redis.XPendingExt{ID: fmt.Sprintf("1-%03d", i), Consumer: "c", Idle: time.Millisecond, RetryCount: 1})
+	}
+	cli.pending = append(cli.pending, redis.XPendingExt{ID: "2-000", Consumer: "c", Idle: time.Hour, RetryCount: 1})
+	bus := newTestStreamsBus(cli)
+	bus.consumer = "c"
+	bus.markFailed("2-000")
+	bus.handlers[evt.EventOrderCompleted] = []evt.Handler{func(evt.Event) error { return nil }}
+
+	if err := bus.reclaim(context.Background()); err != nil {
+		t.Fatalf("reclaim: %v", err)
+	}
+	if want := []string{"-", "(1-099", "(1-199"}; fmt.Sprint(cli.starts) != fmt.Sprint(want) {
+		t.Errorf("expected XPENDING starts %v, got %v", want, cli.starts)
+	}
+	if fmt.Sprint(cli.claimed) != "[2-000]" || fmt.Sprint(cli.acked) != "[2-000]" {
+		t.Errorf("expected 2-000 claimed and acked, got claimed=%v acked=%v", cli.claimed, cli.acked)
+	}
+}
+
+func TestRedisStreamsEventBus_ReclaimSkipsInFlightMessages(t *testing.T) {
+	cli := &fakeStreamClient{}
+	bus := newTestStreamsBus(cli)
+	// 消息仍在 readLoop 中处理，已空闲很久
+	cli.pending = []redis.XPendingExt{{ID: "1-0", Consumer: bus.consumer, Idle: time.Hour, RetryCount: 1}}
+	var dispatched atomic.Int32
+	started, release := make(chan struct{}), make(chan struct{})
+	bus.handlers[evt.EventOrderCompleted] = []evt.Handler{func(evt.Event) error {
+		dispatched.Add(1)
+		close(started)
+		<-release
+		return nil
+	}}
+	done := make(chan struct{})
+	go func() {
+		bus.handleMessage(context.Background(), streamMessage("1-0"), 1)
+		close(done)
+	}()
+	<-started
+	if err := bus.reclaim(context.Background()); err != nil {
+		t.Fatalf("reclaim: %v", err)
+	}
+	close(release)
+	<-done
+
+	if n := dispatched.Load(); n != 1 {
+		t.Errorf("expected exactly one dispatch, got %d", n)
+	}
+	if len(cli.claimed) != 0 || fmt.Sprint(cli.acked) != "[1-0]" {
+		t.Errorf("expected no claim and a single ack, got claimed=%v acked=%v", cli.claimed, cli.acked)
+	}
+}
+
+func TestRedisStreamsEventBus_ReclaimRetriesOwnFailures(t *testing.T) {
+	cli := &fakeStreamClient{}
+	bus := newTestStreamsBus(cli)
+	cli.pending = []redis.XPendingExt{{ID: "1-0", Consumer: bus.consumer, Idle: 2 * time.Second, RetryCount: 1}}
+	calls := 0
+	bus.handlers[evt.EventOrderCompleted] = []evt.Handler{func(evt.Event) error {
+		calls++
+		if calls == 1 {
+			return errors.New("db down")
+		}
+		return nil
+	}}
+	bus.handleMessage(context.Background(), streamMessage("1-0"), 1)
+	if err := bus.reclaim(context.Background()); err != nil {
+		t.Fatalf("reclaim: %v", err)
+	}
+	if calls != 2 || fmt.Sprint(cli.claimed) != "[1-0]" || fmt.Sprint(cli.acked) != "[1-0]" {
+		t.Errorf("expected failed message to be reclaimed and acked, calls=%d claimed=%v acked=%v", calls, cli.claimed, cli.acked)
+	}
+	if len(bus.failed) != 0 {
+		t.Errorf("expected failed set to be cleared after ack, got %v", bus.failed)
+	}
+}
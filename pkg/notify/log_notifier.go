@@ -0,0 +1,31 @@
+package notify
+
+import (
+	"log"
+	"sync"
+)
+
+// LogNotifier 是本地推送渠道模拟器：把发给司机的消息写入日志并按司机记录，便于开发环境与测试查看。
+type LogNotifier struct {
+	mu   sync.Mutex
+	sent map[string][]string // driverID -> 消息
+}
+
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{sent: make(map[string][]string)}
+}
+
+func (n *LogNotifier) NotifyDriver(driverID, message string) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.sent[driverID] = append(n.sent[driverID], message)
+	log.Printf("[notify] driver %s: %s", driverID, message)
+	return nil
+}
+
+// Sent 返回已发给该司机的消息，按发送顺序。
+func (n *LogNotifier) Sent(driverID string) []string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]string(nil), n.sent[driverID]...)
+}
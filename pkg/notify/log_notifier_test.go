@@ -0,0 +1,17 @@
+package notify
+
+import "testing"
+
+func TestLogNotifier_RecordsPerDriver(t *testing.T) {
+	n := NewLogNotifier()
+	_ = n.NotifyDriver("d1", "first")
+	_ = n.NotifyDriver("d2", "other")
+	_ = n.NotifyDriver("d1", "second")
+	got := n.Sent("d1")
+	if len(got) != 2 || got[0] != "first" || got[1] != "second" {
+		t.Errorf("unexpected messages for d1: %v", got)
+	}
+	if len(n.Sent("d3")) != 0 {
+		t.Errorf("expected no messages for d3")
+	}
+}
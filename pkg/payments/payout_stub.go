@@ -0,0 +1,66 @@
+package payments
+
+import (
+	"fmt"
+	"sync"
+
+	settlesvc "github.com/gavin/airport-pickup/internal/domain/settlement/service"
+)
+
+// LocalPayoutProvider 是本地打款渠道模拟器，实现 PayoutProvider：
+// 按 payoutID 幂等，按币种记录每个司机收到的金额；可通过 Reject 模拟收款账户异常。
+type LocalPayoutProvider struct {
+	mu       sync.Mutex
+	paid     map[string]string // payoutID -> 流水号
+	received map[string]int64  // driverID:currency -> 累计收款
+	rejected map[string]string // driverID -> 拒绝原因
+}
+
+var _ settlesvc.PayoutProvider = (*LocalPayoutProvider)(nil)
+
+func NewLocalPayoutProvider() *LocalPayoutProvider {
+	return &LocalPayoutProvider{
+		paid:     make(map[string]string),
+		received: make(map[string]int64),
+		rejected: make(map[string]string),
+	}
+}
+
+// Reject 之后对该司机的打款均被拒绝；reason 为空则恢复正常。
+func (p *LocalPayoutProvider) Reject(driverID, reason string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if reason == "" {
+		delete(p.rejected, driverID)
+		return
+	}
+	p.rejected[driverID] = reason
+}
+
+// Received 返回司机以 currency 累计收到的打款金额。
+func (p *LocalPayoutProvider) Received(driverID, currency string) int64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.received[driverID+":"+currency]
+}
+
+func (p *LocalPayoutProvider) Pay(payoutID, driverID string, amountCents int64, currency string) (string, error) {
+	if amountCents <= 0 {
+		return "", fmt.Errorf("invalid amount")
+	}
+	if currency == "" {
+		return "", fmt.Errorf("currency required")
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if ref, ok := p.paid[payoutID]; ok {
+		return ref, nil
+	}
+	if reason, ok := p.rejected[driverID]; ok {
+		return "", fmt.Errorf("%w: %s", settlesvc.ErrPayoutRejected, reason)
+	}
+	ref := "local-" + payoutID
+	p.paid[payoutID] = ref
+	p.received[driverID+":"+currency] += amountCents
+	return ref, nil
+}
@@ -0,0 +1,35 @@
+package payments
+
+import (
+	"testing"
+
+	settlesvc "github.com/gavin/airport-pickup/internal/domain/settlement/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalPayoutProvider_PayIdempotent(t *testing.T) {
+	p := NewLocalPayoutProvider()
+	ref, err := p.Pay("po1", "d1", 900, "CNY")
+	require.NoError(t, err)
+	assert.Equal(t, "local-po1", ref)
+	ref2, err := p.Pay("po1", "d1", 900, "CNY")
+	require.NoError(t, err)
+	assert.Equal(t, ref, ref2)
+	assert.Equal(t, int64(900), p.Received("d1", "CNY"))
+
+	_, err = p.Pay("po2", "d1", 0, "CNY")
+	assert.Error(t, err)
+}
+
+func TestLocalPayoutProvider_Reject(t *testing.T) {
+	p := NewLocalPayoutProvider()
+	p.Reject("d1", "account closed")
+	_, err := p.Pay("po1", "d1", 900, "CNY")
+	assert.ErrorIs(t, err, settlesvc.ErrPayoutRejected)
+	assert.Equal(t, int64(0), p.Received("d1", "CNY"))
+
+	p.Reject("d1", "")
+	_, err = p.Pay("po1", "d1", 900, "CNY")
+	assert.NoError(t, err)
+}
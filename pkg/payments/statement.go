@@ -0,0 +1,134 @@
+package payments
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/gavin/airport-pickup/internal/domain/money"
+	settlemententity "github.com/gavin/airport-pickup/internal/domain/settlement/entity"
+)
+
+// 钱包服务商对账单格式
+const (
+	StatementCSV  = "csv"
+	StatementJSON = "json"
+)
+
+// statementColumns 对账单字段；CSV 首行为表头，列顺序不限。
+var statementColumns = []string{"reference", "booking_id", "type", "amount", "currency", "settled_at"}
+
+type statementRecord struct {
+	Reference string      `json:"reference"`
+	BookingID string      `json:"booking_id"`
+	Type      string      `json:"type"`
+	Amount    money.Money `json:"amount"`
+	Currency  string      `json:"currency"`
+	SettledAt string      `json:"settled_at"` // RFC3339
+}
+
+// ParseStatement 解析钱包服务商对账单。金额为元（如 "12.50"），必须为正数；settled_at 为 RFC3339。
+// 出错时返回的错误包含出错的行号（不含表头，从 1 开始）。
+func ParseStatement(format string, r io.Reader) ([]settlemententity.StatementLine, error) {
+	switch strings.ToLower(format) {
+	case StatementCSV:
+		return parseCSVStatement(r)
+	case StatementJSON:
+		return parseJSONStatement(r)
+	}
+	return nil, fmt.Errorf("unsupported statement format %q", format)
+}
+
+func parseCSVStatement(r io.Reader) ([]settlemententity.StatementLine, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, errors.New("empty statement")
+	}
+	if err != nil {
+		return nil, err
+	}
+	idx := make(map[string]int, len(header))
+	for i, h := range header {
+		idx[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))] = i
+	}
+	for _, c := range statementColumns {
+		if _, ok := idx[c]; !ok {
+			return nil, fmt.Errorf("statement missing column %q", c)
+		}
+	}
+	var lines []settlemententity.StatementLine
+	for n := 1; ; n++ {
+		row, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		amount, err := money.Parse(strings.TrimSpace(row[idx["amount"]]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid amount", n)
+		}
+		rec := statementRecord{
+			Reference: row[idx["reference"]], BookingID: row[idx["booking_id"]], Type: row[idx["type"]],
+			Amount: amount, Currency: row[idx["currency"]], SettledAt: row[idx["settled_at"]],
+		}
+		l, err := rec.toLine(n)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, l)
+	}
+	return lines, nil
+}
+
+func parseJSONStatement(r io.Reader) ([]settlemententity.StatementLine, error) {
+	var recs []statementRecord
+	if err := json.NewDecoder(r).Decode(&recs); err != nil {
+		return nil, fmt.Errorf("invalid statement: %w", err)
+	}
+	lines := make([]settlemententity.StatementLine, 0, len(recs))
+	for i, rec := range recs {
+		l, err := rec.toLine(i + 1)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, l)
+	}
+	return lines, nil
+}
+
+func (rec statementRecord) toLine(n int) (settlemententity.StatementLine, error) {
+	l := settlemententity.StatementLine{
+		LineNo:      n,
+		Reference:   strings.TrimSpace(rec.Reference),
+		BookingID:   strings.TrimSpace(rec.BookingID),
+		Type:        strings.ToLower(strings.TrimSpace(rec.Type)),
+		AmountCents: rec.Amount.Cents(),
+		Currency:    money.NormalizeCurrency(rec.Currency),
+	}
+	if l.BookingID == "" {
+		return l, fmt.Errorf("line %d: booking_id required", n)
+	}
+	if l.Type != settlemententity.StatementPayment && l.Type != settlemententity.StatementRefund {
+		return l, fmt.Errorf("line %d: invalid type %q", n, rec.Type)
+	}
+	if !rec.Amount.IsPositive() {
+		return l, fmt.Errorf("line %d: amount must be positive", n)
+	}
+	if !money.ValidCurrency(l.Currency) {
+		return l, fmt.Errorf("line %d: invalid currency %q", n, rec.Currency)
+	}
+	at, err := time.Parse(time.RFC3339, strings.TrimSpace(rec.SettledAt))
+	if err != nil {
+		return l, fmt.Errorf("line %d: settled_at must be RFC3339", n)
+	}
+	l.SettledAt = at
+	return l, nil
+}
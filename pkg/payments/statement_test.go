@@ -0,0 +1,71 @@
+package payments
+
+import (
+	"strings"
+	"testing"
+
+	settlemententity "github.com/gavin/airport-pickup/internal/domain/settlement/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStatement_CSV(t *testing.T) {
+	in := "\ufeffreference,booking_id,type,amount,currency,settled_at\n" +
+		"w1,b1,payment,12.50,usd,2026-03-01T10:00:00Z\n" +
+		"w2, b1 ,REFUND,2,USD,2026-03-02T10:00:00+08:00\n"
+	lines, err := ParseStatement("csv", strings.NewReader(in))
+	require.NoError(t, err)
+	require.Len(t, lines, 2)
+	assert.Equal(t, settlemententity.StatementLine{
+		LineNo: 1, Reference: "w1", BookingID: "b1", Type: settlemententity.StatementPayment,
+		AmountCents: 1250, Currency: "USD", SettledAt: lines[0].SettledAt,
+	}, lines[0])
+	assert.Equal(t, "2026-03-01T10:00:00Z", lines[0].SettledAt.UTC().Format("2006-01-02T15:04:05Z"))
+	assert.Equal(t, "b1", lines[1].BookingID)
+	assert.Equal(t, settlemententity.StatementRefund, lines[1].Type)
+	assert.Equal(t, int64(200), lines[1].AmountCents)
+	assert.Equal(t, 2, lines[1].LineNo)
+}
+
+func TestParseStatement_CSVColumnOrder(t *testing.T) {
+	in := "settled_at,amount,currency,type,booking_id,reference\n2026-03-01T10:00:00Z,1.05,CNY,payment,b9,r9\n"
+	lines, err := ParseStatement("CSV", strings.NewReader(in))
+	require.NoError(t, err)
+	require.Len(t, lines, 1)
+	assert.Equal(t, "b9", lines[0].BookingID)
+	assert.Equal(t, int64(105), lines[0].AmountCents)
+}
+
+func TestParseStatement_JSON(t *testing.T) {
+	in := `[{"reference":"w1","booking_id":"b1","type":"payment","amount":12.5,"currency":"USD","settled_at":"2026-03-01T10:00:00Z"},
+		{"reference":"w2","booking_id":"b2","type":"refund","amount":"0.30","currency":"usd","settled_at":"2026-03-01T11:00:00Z"}]`
+	lines, err := ParseStatement("json", strings.NewReader(in))
+	require.NoError(t, err)
+	require.Len(t, lines, 2)
+	assert.Equal(t, int64(1250), lines[0].AmountCents)
+	assert.Equal(t, int64(30), lines[1].AmountCents)
+	assert.Equal(t, "USD", lines[1].Currency)
+}
+
+func TestParseStatement_Errors(t *testing.T) {
+	header := "reference,booking_id,type,amount,currency,settled_at\n"
+	cases := map[string]struct{ format, in, err string }{
+		"format":         {"xml", "", "unsupported statement format"},
+		"empty":          {"csv", "", "empty statement"},
+		"missing column": {"csv", "reference,booking_id,type,amount,currency\n", `missing column "settled_at"`},
+		"amount":         {"csv", header + "w1,b1,payment,abc,USD,2026-03-01T10:00:00Z\n", "line 1: invalid amount"},
+		"negative":       {"csv", header + "w1,b1,payment,1,USD,2026-03-01T10:00:00Z\nw2,b2,payment,-1,USD,2026-03-01T10:00:00Z\n", "line 2: amount must be positive"},
+		"type":           {"csv", header + "w1,b1,chargeback,1,USD,2026-03-01T10:00:00Z\n", "line 1: invalid type"},
+		"booking":        {"csv", header + "w1,,payment,1,USD,2026-03-01T10:00:00Z\n", "line 1: booking_id required"},
+		"currency":       {"csv", header + "w1,b1,payment,1,US,2026-03-01T10:00:00Z\n", "line 1: invalid currency"},
+		"settled_at":     {"json", `[{"booking_id":"b1","type":"payment","amount":1,"currency":"USD","settled_at":"2026-03-01"}]`, "line 1: settled_at must be RFC3339"},
+		"json":           {"json", `{"booking_id":"b1"}`, "invalid statement"},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseStatement(c.format, strings.NewReader(c.in))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), c.err)
+		})
+	}
+}
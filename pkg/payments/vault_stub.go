@@ -0,0 +1,139 @@
+package payments
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	usersvc "github.com/gavin/airport-pickup/internal/domain/user/service"
+)
+
+// LocalVault 是本地的支付方式保险库模拟器，实现 PaymentMethodVault。Tokenize 模拟客户端向保险库提交卡号：
+// 校验卡号（Luhn）、有效期与卡组织后返回随机令牌，卡号只保存在内存中，从不返回给调用方。
+// 本地联调可直接使用测试令牌 tok_test_<卡组织>[_<任意后缀>]（如 tok_test_visa_alice），无需先令牌化。
+type LocalVault struct {
+	mu    sync.Mutex
+	cards map[string]vaultedCard // token -> 卡
+	now   func() time.Time
+}
+
+type vaultedCard struct {
+	number string
+	card   usersvc.VaultedCard
+}
+
+// testCardLast4 测试令牌对应的各卡组织测试卡号后四位。
+var testCardLast4 = map[string]string{"visa": "4242", "mastercard": "4444", "amex": "0005", "unionpay": "0005"}
+
+var _ usersvc.PaymentMethodVault = (*LocalVault)(nil)
+
+func NewLocalVault() *LocalVault {
+	return &LocalVault{cards: make(map[string]vaultedCard), now: time.Now}
+}
+
+func (v *LocalVault) Tokenize(passengerID string, card usersvc.CardDetails) (usersvc.VaultedCard, error) {
+	number := strings.NewReplacer(" ", "", "-", "").Replace(card.Number)
+	if len(number) < 12 || len(number) > 19 || !luhnValid(number) {
+		return usersvc.VaultedCard{}, fmt.Errorf("%w: invalid card number", usersvc.ErrCardDeclined)
+	}
+	brand := cardBrand(number)
+	if brand == "" {
+		return usersvc.VaultedCard{}, fmt.Errorf("%w: unsupported card brand", usersvc.ErrCardDeclined)
+	}
+	if card.ExpMonth < 1 || card.ExpMonth > 12 {
+		return usersvc.VaultedCard{}, fmt.Errorf("%w: invalid expiry month", usersvc.ErrCardDeclined)
+	}
+	now := v.now().UTC()
+	if card.ExpYear < now.Year() || (card.ExpYear == now.Year() && card.ExpMonth < int(now.Month())) {
+		return usersvc.VaultedCard{}, fmt.Errorf("%w: card expired", usersvc.ErrCardDeclined)
+	}
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		return usersvc.VaultedCard{}, err
+	}
+	token := "tok_" + hex.EncodeToString(buf)
+	vc := usersvc.VaultedCard{
+		Token: token, PassengerID: passengerID, Brand: brand, Last4: number[len(number)-4:], ExpMonth: card.ExpMonth, ExpYear: card.ExpYear,
+	}
+	v.mu.Lock()
+	v.cards[token] = vaultedCard{number: number, card: vc}
+	v.mu.Unlock()
+	return vc, nil
+}
+
+// Lookup 返回令牌对应的卡；测试令牌不绑定乘客，有效期为三年后的 12 月。
+func (v *LocalVault) Lookup(token string) (usersvc.VaultedCard, error) {
+	v.mu.Lock()
+	c, ok := v.cards[token]
+	v.mu.Unlock()
+	if ok {
+		return c.card, nil
+	}
+	if rest, ok := strings.CutPrefix(token, "tok_test_"); ok {
+		brand, _, _ := strings.Cut(rest, "_")
+		if last4, ok := testCardLast4[brand]; ok {
+			return usersvc.VaultedCard{Token: token, Brand: brand, Last4: last4, ExpMonth: 12, ExpYear: v.now().UTC().Year() + 3}, nil
+		}
+	}
+	return usersvc.VaultedCard{}, usersvc.ErrUnknownToken
+}
+
+func (v *LocalVault) Remove(token string) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	delete(v.cards, token)
+	return nil
+}
+
+// Has 令牌是否仍保存在保险库中。
+func (v *LocalVault) Has(token string) bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	_, ok := v.cards[token]
+	return ok
+}
+
+func luhnValid(number string) bool {
+	sum := 0
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		d := int(number[i] - '0')
+		if d < 0 || d > 9 {
+			return false
+		}
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// cardBrand 按卡号前缀识别卡组织，不支持的返回空字符串。
+func cardBrand(number string) string {
+	prefix := func(n int) int {
+		v := 0
+		for _, c := range number[:n] {
+			v = v*10 + int(c-'0')
+		}
+		return v
+	}
+	switch {
+	case number[0] == '4':
+		return "visa"
+	case prefix(2) >= 51 && prefix(2) <= 55, prefix(4) >= 2221 && prefix(4) <= 2720:
+		return "mastercard"
+	case prefix(2) == 34 || prefix(2) == 37:
+		return "amex"
+	case prefix(2) == 62:
+		return "unionpay"
+	}
+	return ""
+}
@@ -0,0 +1,76 @@
+package payments
+
+import (
+	"testing"
+	"time"
+
+	usersvc "github.com/gavin/airport-pickup/internal/domain/user/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalVault_Tokenize(t *testing.T) {
+	v := NewLocalVault()
+	v.now = func() time.Time { return time.Date(2025, 11, 8, 10, 0, 0, 0, time.UTC) }
+
+	card, err := v.Tokenize("p1", usersvc.CardDetails{Number: "4242 4242 4242 4242", ExpMonth: 11, ExpYear: 2025, HolderName: "Alice"})
+	require.NoError(t, err)
+	assert.Equal(t, "visa", card.Brand)
+	assert.Equal(t, "4242", card.Last4)
+	assert.Contains(t, card.Token, "tok_")
+	assert.NotContains(t, card.Token, "4242424242424242")
+	assert.True(t, v.Has(card.Token))
+
+	for number, brand := range map[string]string{
+		"5555555555554444": "mastercard",
+		"2223003122003222": "mastercard",
+		"378282246310005":  "amex",
+		"6200000000000005": "unionpay",
+	} {
+		got, err := v.Tokenize("p1", usersvc.CardDetails{Number: number, ExpMonth: 1, ExpYear: 2030})
+		require.NoError(t, err, number)
+		assert.Equal(t, brand, got.Brand, number)
+	}
+
+	got, err := v.Lookup(card.Token)
+	require.NoError(t, err)
+	assert.Equal(t, card, got)
+	assert.Equal(t, "p1", got.PassengerID)
+
+	require.NoError(t, v.Remove(card.Token))
+	assert.False(t, v.Has(card.Token))
+	require.NoError(t, v.Remove(card.Token))
+	_, err = v.Lookup(card.Token)
+	assert.ErrorIs(t, err, usersvc.ErrUnknownToken)
+}
+
+func TestLocalVault_LookupTestTokens(t *testing.T) {
+	v := NewLocalVault()
+	v.now = func() time.Time { return time.Date(2025, 11, 8, 10, 0, 0, 0, time.UTC) }
+
+	card, err := v.Lookup("tok_test_mastercard_alice")
+	require.NoError(t, err)
+	assert.Equal(t, usersvc.VaultedCard{Token: "tok_test_mastercard_alice", Brand: "mastercard", Last4: "4444", ExpMonth: 12, ExpYear: 2028}, card)
+
+	for _, token := range []string{"tok_test_discover", "tok_abc", "4242424242424242", ""} {
+		_, err := v.Lookup(token)
+		assert.ErrorIs(t, err, usersvc.ErrUnknownToken, token)
+	}
+}
+
+func TestLocalVault_Declines(t *testing.T) {
+	v := NewLocalVault()
+	v.now = func() time.Time { return time.Date(2025, 11, 8, 10, 0, 0, 0, time.UTC) }
+	for name, card := range map[string]usersvc.CardDetails{
+		"luhn":        {Number: "4242424242424241", ExpMonth: 1, ExpYear: 2030},
+		"short":       {Number: "42424", ExpMonth: 1, ExpYear: 2030},
+		"letters":     {Number: "4242abcd42424242", ExpMonth: 1, ExpYear: 2030},
+		"brand":       {Number: "9000000000000008", ExpMonth: 1, ExpYear: 2030},
+		"month":       {Number: "4242424242424242", ExpMonth: 13, ExpYear: 2030},
+		"expired":     {Number: "4242424242424242", ExpMonth: 10, ExpYear: 2025},
+		"expired_old": {Number: "4242424242424242", ExpMonth: 12, ExpYear: 2024},
+	} {
+		_, err := v.Tokenize("p1", card)
+		assert.ErrorIs(t, err, usersvc.ErrCardDeclined, name)
+	}
+}
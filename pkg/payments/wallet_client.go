@@ -1,16 +1,168 @@
 package payments
 
-import "fmt"
+import (
+	"fmt"
+	"sync"
 
-// WalletClient is a simple stub implementing the PaymentService by charging successfully.
-type WalletClient struct{}
+	settlesvc "github.com/gavin/airport-pickup/internal/domain/settlement/service"
+)
 
-func NewWalletClient() *WalletClient { return &WalletClient{} }
+// DefaultInitialBalanceCents 为新乘客钱包的默认余额（本地模拟用）。
+const DefaultInitialBalanceCents int64 = 100000
 
-func (w *WalletClient) Charge(bookingID string, amountCents int64) error {
-	// In real world, call external gateway; here succeed if amount >= 0
+// WalletClient 是本地的有状态钱包模拟器，实现 PaymentService：
+// 每个资金来源一个余额（乘客钱包按乘客 ID，保存的卡按令牌，卡的余额即模拟的可用额度），
+// 预授权按订单冻结金额，可用余额 = 余额 - 冻结中的金额。
+// 所有操作按订单（退款按 refundID）幂等。
+type WalletClient struct {
+	mu             sync.Mutex
+	initialBalance int64
+	balances       map[string]int64 // 资金来源（passengerID 或卡令牌） -> 余额
+	holds          map[string]*hold // bookingID -> 预授权
+	refunds        map[string]int64 // refundID -> 金额
+}
+
+type hold struct {
+	source   string // 资金来源
+	amount   int64  // 预授权金额
+	captured int64
+	refunded int64
+	status   string // authorized, captured, voided
+}
+
+var _ settlesvc.PaymentService = (*WalletClient)(nil)
+
+func NewWalletClient() *WalletClient { return NewWalletClientWithBalance(DefaultInitialBalanceCents) }
+
+// NewWalletClientWithBalance 创建钱包模拟器，首次出现的乘客钱包或卡以 initialBalanceCents 开户。
+func NewWalletClientWithBalance(initialBalanceCents int64) *WalletClient {
+	return &WalletClient{
+		initialBalance: initialBalanceCents,
+		balances:       make(map[string]int64),
+		holds:          make(map[string]*hold),
+		refunds:        make(map[string]int64),
+	}
+}
+
+// TopUp 为乘客钱包充值。
+func (w *WalletClient) TopUp(passengerID string, amountCents int64) error {
+	if amountCents <= 0 {
+		return fmt.Errorf("invalid amount")
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.balances[passengerID] = w.balance(passengerID) + amountCents
+	return nil
+}
+
+// Balance 返回乘客的余额与可用余额（扣除冻结金额）。
+func (w *WalletClient) Balance(passengerID string) (balanceCents, availableCents int64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.balance(passengerID), w.available(passengerID)
+}
+
+func (w *WalletClient) Authorize(bookingID, passengerID, paymentMethod string, amountCents int64) error {
 	if amountCents < 0 {
 		return fmt.Errorf("invalid amount")
 	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	// 已撤销的预授权可以重新发起
+	if h, ok := w.holds[bookingID]; ok && h.status != "voided" {
+		return nil
+	}
+	source := passengerID
+	if paymentMethod != "" {
+		source = paymentMethod
+	}
+	if w.available(source) < amountCents {
+		return fmt.Errorf("%w: insufficient funds", settlesvc.ErrPaymentDeclined)
+	}
+	w.holds[bookingID] = &hold{source: source, amount: amountCents, status: "authorized"}
 	return nil
 }
+
+func (w *WalletClient) Capture(bookingID string, amountCents int64) error {
+	if amountCents < 0 {
+		return fmt.Errorf("invalid amount")
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	h, ok := w.holds[bookingID]
+	if !ok || h.status == "voided" {
+		return settlesvc.ErrAuthorizationNotFound
+	}
+	if h.status == "captured" {
+		return nil
+	}
+	if amountCents > h.amount {
+		return fmt.Errorf("capture amount %d exceeds authorized amount %d", amountCents, h.amount)
+	}
+	w.balances[h.source] = w.balance(h.source) - amountCents
+	h.captured = amountCents
+	h.status = "captured"
+	return nil
+}
+
+func (w *WalletClient) Void(bookingID string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	h, ok := w.holds[bookingID]
+	if !ok {
+		return nil
+	}
+	if h.status == "captured" {
+		return fmt.Errorf("booking %s already captured", bookingID)
+	}
+	h.status = "voided"
+	return nil
+}
+
+func (w *WalletClient) Refund(bookingID, refundID string, amountCents int64) error {
+	if amountCents < 0 {
+		return fmt.Errorf("invalid amount")
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, ok := w.refunds[refundID]; ok {
+		return nil
+	}
+	h, ok := w.holds[bookingID]
+	if !ok || h.status != "captured" {
+		return fmt.Errorf("booking %s has no captured payment", bookingID)
+	}
+	if h.refunded+amountCents > h.captured {
+		return fmt.Errorf("refund amount %d exceeds refundable amount %d", amountCents, h.captured-h.refunded)
+	}
+	h.refunded += amountCents
+	w.balances[h.source] = w.balance(h.source) + amountCents
+	w.refunds[refundID] = amountCents
+	return nil
+}
+
+// Charge 一步完成预授权与扣款。
+func (w *WalletClient) Charge(bookingID, passengerID, paymentMethod string, amountCents int64) error {
+	if err := w.Authorize(bookingID, passengerID, paymentMethod, amountCents); err != nil {
+		return err
+	}
+	return w.Capture(bookingID, amountCents)
+}
+
+func (w *WalletClient) balance(source string) int64 {
+	if b, ok := w.balances[source]; ok {
+		return b
+	}
+	w.balances[source] = w.initialBalance
+	return w.initialBalance
+}
+
+func (w *WalletClient) available(source string) int64 {
+	avail := w.balance(source)
+	for _, h := range w.holds {
+		if h.source == source && h.status == "authorized" {
+			avail -= h.amount
+		}
+	}
+	return avail
+}
@@ -1,19 +1,93 @@
 package payments
 
 import (
-	"github.com/stretchr/testify/assert"
 	"testing"
+
+	settlesvc "github.com/gavin/airport-pickup/internal/domain/settlement/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestWalletClient_Charge_Success(t *testing.T) {
 	client := NewWalletClient()
-	err := client.Charge("booking123", 1000)
+	err := client.Charge("booking123", "p1", "", 1000)
 	assert.NoError(t, err)
+	balance, _ := client.Balance("p1")
+	assert.Equal(t, DefaultInitialBalanceCents-1000, balance)
 }
 
 func TestWalletClient_Charge_InvalidAmount(t *testing.T) {
 	client := NewWalletClient()
-	err := client.Charge("booking123", -100)
+	err := client.Charge("booking123", "p1", "", -100)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "invalid amount")
 }
+
+func TestWalletClient_AuthorizeHoldsFunds(t *testing.T) {
+	client := NewWalletClientWithBalance(1000)
+	require.NoError(t, client.Authorize("b1", "p1", "", 700))
+	// 重复预授权幂等
+	require.NoError(t, client.Authorize("b1", "p1", "", 700))
+	balance, available := client.Balance("p1")
+	assert.Equal(t, int64(1000), balance)
+	assert.Equal(t, int64(300), available)
+
+	err := client.Authorize("b2", "p1", "", 500)
+	assert.ErrorIs(t, err, settlesvc.ErrPaymentDeclined)
+
+	require.NoError(t, client.TopUp("p1", 200))
+	require.NoError(t, client.Authorize("b2", "p1", "", 500))
+}
+
+func TestWalletClient_CaptureReleasesRemainder(t *testing.T) {
+	client := NewWalletClientWithBalance(1000)
+	require.NoError(t, client.Authorize("b1", "p1", "", 700))
+	assert.Error(t, client.Capture("b1", 800))
+	require.NoError(t, client.Capture("b1", 600))
+	require.NoError(t, client.Capture("b1", 600))
+	balance, available := client.Balance("p1")
+	assert.Equal(t, int64(400), balance)
+	assert.Equal(t, int64(400), available)
+
+	assert.ErrorIs(t, client.Capture("unknown", 100), settlesvc.ErrAuthorizationNotFound)
+	assert.Error(t, client.Void("b1"))
+}
+
+func TestWalletClient_Void(t *testing.T) {
+	client := NewWalletClientWithBalance(1000)
+	require.NoError(t, client.Authorize("b1", "p1", "", 700))
+	require.NoError(t, client.Void("b1"))
+	require.NoError(t, client.Void("b1"))
+	_, available := client.Balance("p1")
+	assert.Equal(t, int64(1000), available)
+	assert.ErrorIs(t, client.Capture("b1", 700), settlesvc.ErrAuthorizationNotFound)
+}
+
+func TestWalletClient_Refund(t *testing.T) {
+	client := NewWalletClientWithBalance(1000)
+	assert.Error(t, client.Refund("b1", "r1", 100))
+	require.NoError(t, client.Charge("b1", "p1", "", 600))
+	require.NoError(t, client.Refund("b1", "r1", 400))
+	// 同一 refundID 只生效一次
+	require.NoError(t, client.Refund("b1", "r1", 400))
+	assert.Error(t, client.Refund("b1", "r2", 300))
+	assert.Error(t, client.Refund("b1", "r3", -100))
+	balance, _ := client.Balance("p1")
+	assert.Equal(t, int64(800), balance)
+}
+
+func TestWalletClient_ChargesPaymentMethod(t *testing.T) {
+	client := NewWalletClientWithBalance(1000)
+	require.NoError(t, client.Authorize("b1", "p1", "tok_card", 900))
+	// 卡的冻结不占用乘客钱包
+	_, walletAvailable := client.Balance("p1")
+	assert.Equal(t, int64(1000), walletAvailable)
+	assert.ErrorIs(t, client.Authorize("b2", "p1", "tok_card", 200), settlesvc.ErrPaymentDeclined)
+
+	require.NoError(t, client.Capture("b1", 800))
+	require.NoError(t, client.Refund("b1", "r1", 300))
+	cardBalance, _ := client.Balance("tok_card")
+	assert.Equal(t, int64(500), cardBalance)
+	walletBalance, _ := client.Balance("p1")
+	assert.Equal(t, int64(1000), walletBalance)
+}
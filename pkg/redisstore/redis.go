@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/gavin/airport-pickup/internal/domain/money"
+
 	redis "github.com/redis/go-redis/v9"
 )
 
@@ -31,20 +33,28 @@ func (c *Client) Ping(ctx context.Context) error {
 	return c.cli.Ping(ctx).Err()
 }
 
-func (c *Client) AddPickupRequest(ctx context.Context, airport, vehicle string, req any, maxPrice float64) error {
+// AddPickupRequest 以每公里最高价（元）为分数写入请求订单簿
+func (c *Client) AddPickupRequest(ctx context.Context, airport, vehicle string, req any, maxPrice money.Money) error {
 	key := fmt.Sprintf("orderbook:requests:%s:%s", airport, vehicle)
 	b, _ := json.Marshal(req)
-	z := &redis.Z{Score: maxPrice, Member: b}
+	z := &redis.Z{Score: maxPrice.Float64(), Member: b}
 	return c.cli.ZAdd(ctx, key, *z).Err()
 }
 
-func (c *Client) AddDriverOffer(ctx context.Context, airport, vehicle string, offer any, price float64) error {
+// AddDriverOffer 以每公里价格（元）为分数写入报价订单簿
+func (c *Client) AddDriverOffer(ctx context.Context, airport, vehicle string, offer any, price money.Money) error {
 	key := fmt.Sprintf("orderbook:offers:%s:%s", airport, vehicle)
 	b, _ := json.Marshal(offer)
-	z := &redis.Z{Score: price, Member: b}
+	z := &redis.Z{Score: price.Float64(), Member: b}
 	return c.cli.ZAdd(ctx, key, *z).Err()
 }
 
+// ListDriverOffers 按每公里价格升序返回报价订单簿中的成员（写入时的 JSON）
+func (c *Client) ListDriverOffers(ctx context.Context, airport, vehicle string) ([]string, error) {
+	key := fmt.Sprintf("orderbook:offers:%s:%s", airport, vehicle)
+	return c.cli.ZRange(ctx, key, 0, -1).Result()
+}
+
 // RemovePickupRequest 删除 ZSET 中匹配 requestID 的成员
 func (c *Client) RemovePickupRequest(ctx context.Context, airport, vehicle, requestID string) error {
 	if requestID == "" {
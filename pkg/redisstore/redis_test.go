@@ -4,6 +4,8 @@ import (
 	"context"
 	"testing"
 
+	"github.com/gavin/airport-pickup/internal/domain/money"
+
 	redis "github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
 )
@@ -24,13 +26,13 @@ func TestPing(t *testing.T) {
 
 func TestAddPickupRequest(t *testing.T) {
 	c := newMockClient()
-	err := c.AddPickupRequest(context.Background(), "PVG", "Sedan", map[string]string{"id": "req1"}, 100)
+	err := c.AddPickupRequest(context.Background(), "PVG", "Sedan", map[string]string{"id": "req1"}, money.MustParse("100"))
 	assert.NoError(t, err)
 }
 
 func TestAddDriverOffer(t *testing.T) {
 	c := newMockClient()
-	err := c.AddDriverOffer(context.Background(), "PVG", "Sedan", map[string]string{"id": "offer1"}, 80)
+	err := c.AddDriverOffer(context.Background(), "PVG", "Sedan", map[string]string{"id": "offer1"}, money.MustParse("80"))
 	assert.NoError(t, err)
 }
 
@@ -39,3 +41,17 @@ func TestRemovePickupRequest_EmptyID(t *testing.T) {
 	err := c.RemovePickupRequest(context.Background(), "PVG", "Sedan", "")
 	assert.Error(t, err)
 }
+
+func TestListDriverOffers(t *testing.T) {
+	c := newMockClient()
+	ctx := context.Background()
+	assert.NoError(t, c.AddDriverOffer(ctx, "TST", "Sedan", map[string]string{"OfferID": "offer2"}, money.MustParse("9")))
+	assert.NoError(t, c.AddDriverOffer(ctx, "TST", "Sedan", map[string]string{"OfferID": "offer1"}, money.MustParse("8")))
+	defer func() {
+		_ = c.RemoveDriverOffer(ctx, "TST", "Sedan", "offer1")
+		_ = c.RemoveDriverOffer(ctx, "TST", "Sedan", "offer2")
+	}()
+	got, err := c.ListDriverOffers(ctx, "TST", "Sedan")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{`{"OfferID":"offer1"}`, `{"OfferID":"offer2"}`}, got)
+}
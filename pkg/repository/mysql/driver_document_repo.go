@@ -0,0 +1,66 @@
+package mysqlrepo
+
+import (
+	"time"
+
+	user "github.com/gavin/airport-pickup/internal/domain/user"
+	userentity "github.com/gavin/airport-pickup/internal/domain/user/entity"
+	"gorm.io/gorm"
+)
+
+type DriverDocumentRepository struct{ db *gorm.DB }
+
+func NewDriverDocumentRepository(db *gorm.DB) user.DriverDocumentRepository {
+	return &DriverDocumentRepository{db: db}
+}
+
+func (r *DriverDocumentRepository) SaveDocument(d *userentity.DriverDocument) error {
+	return r.db.Save(&DriverDocument{
+		ID: d.ID, DriverID: d.DriverID, Type: d.Type, BlobKey: d.BlobKey, FileName: d.FileName, ContentType: d.ContentType,
+		SizeBytes: d.SizeBytes, ExpiresAt: d.ExpiresAt, Status: d.Status,
+		ReviewedBy: d.ReviewedBy, ReviewNote: d.ReviewNote, ReviewedAt: d.ReviewedAt, UploadedAt: d.UploadedAt,
+	}).Error
+}
+
+func (r *DriverDocumentRepository) GetDocument(id string) (*userentity.DriverDocument, error) {
+	var ms []DriverDocument
+	if err := r.db.Where("id = ?", id).Limit(1).Find(&ms).Error; err != nil {
+		return nil, err
+	}
+	if len(ms) == 0 {
+		return nil, nil
+	}
+	return toDriverDocumentEntity(&ms[0]), nil
+}
+
+func (r *DriverDocumentRepository) ListDocuments(driverID string) ([]*userentity.DriverDocument, error) {
+	return r.list(r.db.Where("driver_id = ?", driverID))
+}
+
+func (r *DriverDocumentRepository) ListPendingReview() ([]*userentity.DriverDocument, error) {
+	return r.list(r.db.Where("status = ?", userentity.DocumentPendingReview))
+}
+
+func (r *DriverDocumentRepository) ListApprovedExpiringBefore(before time.Time) ([]*userentity.DriverDocument, error) {
+	return r.list(r.db.Where("status = ? AND expires_at < ?", userentity.DocumentApproved, before))
+}
+
+func (r *DriverDocumentRepository) list(q *gorm.DB) ([]*userentity.DriverDocument, error) {
+	var ms []DriverDocument
+	if err := q.Order("uploaded_at, id").Find(&ms).Error; err != nil {
+		return nil, err
+	}
+	res := make([]*userentity.DriverDocument, 0, len(ms))
+	for i := range ms {
+		res = append(res, toDriverDocumentEntity(&ms[i]))
+	}
+	return res, nil
+}
+
+func toDriverDocumentEntity(m *DriverDocument) *userentity.DriverDocument {
+	return &userentity.DriverDocument{
+		ID: m.ID, DriverID: m.DriverID, Type: m.Type, BlobKey: m.BlobKey, FileName: m.FileName, ContentType: m.ContentType,
+		SizeBytes: m.SizeBytes, ExpiresAt: m.ExpiresAt, Status: m.Status,
+		ReviewedBy: m.ReviewedBy, ReviewNote: m.ReviewNote, ReviewedAt: m.ReviewedAt, UploadedAt: m.UploadedAt,
+	}
+}
@@ -0,0 +1,68 @@
+package mysqlrepo
+
+import (
+	"testing"
+	"time"
+
+	userentity "github.com/gavin/airport-pickup/internal/domain/user/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func newTestDBDocuments(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&DriverDocument{}))
+	return db
+}
+
+func TestDriverDocumentRepository_SaveAndQuery(t *testing.T) {
+	repo := NewDriverDocumentRepository(newTestDBDocuments(t))
+	base := time.Date(2025, 11, 8, 10, 0, 0, 0, time.UTC)
+	reviewed := base.Add(time.Hour)
+	docs := []*userentity.DriverDocument{
+		{ID: "doc2", DriverID: "d1", Type: userentity.DocumentTypeInsurance, BlobKey: "drivers/d1/documents/doc2.pdf", ContentType: "application/pdf",
+			SizeBytes: 10, ExpiresAt: base.AddDate(0, 0, 10), Status: userentity.DocumentApproved, ReviewedBy: "admin", ReviewedAt: &reviewed, UploadedAt: base},
+		{ID: "doc1", DriverID: "d1", Type: userentity.DocumentTypeLicence, BlobKey: "drivers/d1/documents/doc1.png", ContentType: "image/png",
+			SizeBytes: 20, ExpiresAt: base.AddDate(2, 0, 0), Status: userentity.DocumentPendingReview, UploadedAt: base.Add(-time.Hour)},
+		{ID: "doc3", DriverID: "d2", Type: userentity.DocumentTypeLicence, BlobKey: "drivers/d2/documents/doc3.pdf", ContentType: "application/pdf",
+			SizeBytes: 30, ExpiresAt: base.AddDate(0, 0, 1), Status: userentity.DocumentPendingReview, UploadedAt: base.Add(time.Minute)},
+	}
+	for _, d := range docs {
+		require.NoError(t, repo.SaveDocument(d))
+	}
+
+	got, err := repo.GetDocument("doc2")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, "admin", got.ReviewedBy)
+	assert.True(t, got.ReviewedAt.Equal(reviewed))
+	missing, err := repo.GetDocument("nope")
+	assert.NoError(t, err)
+	assert.Nil(t, missing)
+
+	list, err := repo.ListDocuments("d1")
+	require.NoError(t, err)
+	if assert.Len(t, list, 2) {
+		assert.Equal(t, "doc1", list[0].ID)
+	}
+
+	queue, err := repo.ListPendingReview()
+	require.NoError(t, err)
+	if assert.Len(t, queue, 2) {
+		assert.Equal(t, "doc1", queue[0].ID)
+		assert.Equal(t, "doc3", queue[1].ID)
+	}
+
+	// 只返回审核通过的证件
+	expiring, err := repo.ListApprovedExpiringBefore(base.AddDate(0, 0, 30))
+	require.NoError(t, err)
+	if assert.Len(t, expiring, 1) {
+		assert.Equal(t, "doc2", expiring[0].ID)
+	}
+	expiring, err = repo.ListApprovedExpiringBefore(base.AddDate(0, 0, 5))
+	require.NoError(t, err)
+	assert.Empty(t, expiring)
+}
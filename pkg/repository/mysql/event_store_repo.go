@@ -0,0 +1,45 @@
+package mysqlrepo
+
+import (
+	evt "github.com/gavin/airport-pickup/internal/domain/eventbus"
+	"gorm.io/gorm"
+)
+
+// EventStoreRepository 基于 domain_events 表的追加写事件存储，不提供更新与删除。
+type EventStoreRepository struct{ db *gorm.DB }
+
+func NewEventStoreRepository(db *gorm.DB) evt.EventStore { return &EventStoreRepository{db: db} }
+
+func (r *EventStoreRepository) Append(e *evt.StoredEvent) error {
+	m := &DomainEvent{Name: e.Name, AggregateKey: e.AggregateKey, Payload: string(e.Payload), OccurredAt: e.OccurredAt}
+	if err := r.db.Create(m).Error; err != nil {
+		return err
+	}
+	e.Seq = m.Seq
+	return nil
+}
+
+func (r *EventStoreRepository) List(f evt.EventFilter) ([]*evt.StoredEvent, error) {
+	q := r.db.Model(&DomainEvent{})
+	if !f.From.IsZero() {
+		q = q.Where("occurred_at >= ?", f.From)
+	}
+	if !f.To.IsZero() {
+		q = q.Where("occurred_at < ?", f.To)
+	}
+	if len(f.Names) > 0 {
+		q = q.Where("name IN ?", f.Names)
+	}
+	if f.AggregateKey != "" {
+		q = q.Where("aggregate_key = ?", f.AggregateKey)
+	}
+	var ms []DomainEvent
+	if err := q.Order("seq").Find(&ms).Error; err != nil {
+		return nil, err
+	}
+	res := make([]*evt.StoredEvent, 0, len(ms))
+	for _, m := range ms {
+		res = append(res, &evt.StoredEvent{Seq: m.Seq, Name: m.Name, AggregateKey: m.AggregateKey, Payload: []byte(m.Payload), OccurredAt: m.OccurredAt})
+	}
+	return res, nil
+}
@@ -0,0 +1,48 @@
+package mysqlrepo
+
+import (
+	evt "github.com/gavin/airport-pickup/internal/domain/eventbus"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+func newTestDBEventStore() *gorm.DB {
+	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	db.AutoMigrate(&DomainEvent{})
+	return db
+}
+
+func TestEventStore_AppendAndList(t *testing.T) {
+	repo := NewEventStoreRepository(newTestDBEventStore())
+	base := time.Date(2025, 11, 8, 10, 0, 0, 0, time.UTC)
+	events := []*evt.StoredEvent{
+		{Name: evt.EventOrderMatched, AggregateKey: "bk1", Payload: []byte(`{"BookingID":"bk1"}`), OccurredAt: base},
+		{Name: evt.EventOrderCompleted, AggregateKey: "bk1", Payload: []byte(`{"BookingID":"bk1"}`), OccurredAt: base.Add(time.Hour)},
+		{Name: evt.EventOrderMatched, AggregateKey: "bk2", Payload: []byte(`{"BookingID":"bk2"}`), OccurredAt: base.Add(2 * time.Hour)},
+	}
+	for _, e := range events {
+		assert.NoError(t, repo.Append(e))
+	}
+	assert.True(t, events[0].Seq < events[1].Seq && events[1].Seq < events[2].Seq)
+
+	all, err := repo.List(evt.EventFilter{})
+	assert.NoError(t, err)
+	assert.Len(t, all, 3)
+	assert.Equal(t, `{"BookingID":"bk1"}`, string(all[0].Payload))
+
+	byKey, err := repo.List(evt.EventFilter{AggregateKey: "bk1"})
+	assert.NoError(t, err)
+	assert.Len(t, byKey, 2)
+
+	byName, err := repo.List(evt.EventFilter{Names: []string{evt.EventOrderMatched}})
+	assert.NoError(t, err)
+	assert.Len(t, byName, 2)
+
+	byTime, err := repo.List(evt.EventFilter{From: base.Add(30 * time.Minute), To: base.Add(2 * time.Hour)})
+	assert.NoError(t, err)
+	assert.Len(t, byTime, 1)
+	assert.Equal(t, evt.EventOrderCompleted, byTime[0].Name)
+}
@@ -0,0 +1,162 @@
+package mysqlrepo
+
+import (
+	"time"
+
+	settlement "github.com/gavin/airport-pickup/internal/domain/settlement"
+	settlemententity "github.com/gavin/airport-pickup/internal/domain/settlement/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type InvoiceRepository struct{ db *gorm.DB }
+
+func NewInvoiceRepository(db *gorm.DB) settlement.InvoiceRepository {
+	return &InvoiceRepository{db: db}
+}
+
+func (r *InvoiceRepository) ListSettlementRecordsByBooking(bookingID string) ([]*settlemententity.SettlementRecord, error) {
+	var ms []SettlementRecord
+	if err := r.db.Where("booking_id = ?", bookingID).Order("created_at, id").Find(&ms).Error; err != nil {
+		return nil, err
+	}
+	return withFareItems(r.db, ms)
+}
+
+func (r *InvoiceRepository) ListCorporateSettlementRecords(corporateAccount string, from, to time.Time) ([]*settlemententity.SettlementRecord, error) {
+	var ms []SettlementRecord
+	err := r.db.Where("passenger_id IN (?) AND created_at >= ? AND created_at < ?",
+		r.db.Model(&Passenger{}).Select("id").Where("corporate_account = ?", corporateAccount), from, to).
+		Order("created_at, id").Find(&ms).Error
+	if err != nil {
+		return nil, err
+	}
+	return withFareItems(r.db, ms)
+}
+
+func (r *InvoiceRepository) ListCorporateAccounts() ([]string, error) {
+	var res []string
+	err := r.db.Model(&Passenger{}).Distinct("corporate_account").Where("corporate_account <> ''").Order("corporate_account").Pluck("corporate_account", &res).Error
+	return res, err
+}
+
+func (r *InvoiceRepository) CreateInvoice(inv *settlemententity.Invoice) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		key := invoiceUniqueKey(inv)
+		var n int64
+		if err := tx.Model(&Invoice{}).Where("unique_key = ?", key).Count(&n).Error; err != nil {
+			return err
+		}
+		if n > 0 {
+			return settlement.ErrInvoiceExists
+		}
+		// 插入或递增（ON DUPLICATE KEY UPDATE）一条语句完成，开票主体首次开票并发时也不会主键冲突；
+		// 行锁保证同一开票主体的编号串行分配，事务回滚时编号一并回滚，不留空号
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "legal_entity"}},
+			DoUpdates: clause.Assignments(map[string]any{"last_seq": gorm.Expr("last_seq + 1")}),
+		}).Create(&InvoiceSequence{LegalEntity: inv.Issuer.Code, LastSeq: 1}).Error; err != nil {
+			return err
+		}
+		var seq InvoiceSequence
+		if err := tx.First(&seq, "legal_entity = ?", inv.Issuer.Code).Error; err != nil {
+			return err
+		}
+		inv.AssignNumber(seq.LastSeq)
+		if inv.IssuedAt.IsZero() {
+			inv.IssuedAt = time.Now()
+		}
+		if err := tx.Create(toInvoiceModel(inv, key)).Error; err != nil {
+			return err
+		}
+		if len(inv.Lines) == 0 {
+			return nil
+		}
+		lines := make([]InvoiceLine, 0, len(inv.Lines))
+		for i, l := range inv.Lines {
+			lines = append(lines, InvoiceLine{
+				InvoiceID: inv.ID, Seq: i, Kind: l.Kind, BookingID: l.BookingID, Description: truncate(l.Description, 255),
+				AmountCents: l.AmountCents, TaxCents: l.TaxCents, Date: l.Date,
+			})
+		}
+		return tx.Create(&lines).Error
+	})
+}
+
+func (r *InvoiceRepository) GetInvoice(id string) (*settlemententity.Invoice, error) {
+	return r.first(r.db.Where("id = ?", id))
+}
+
+func (r *InvoiceRepository) GetReceiptByBookingID(bookingID string) (*settlemententity.Invoice, error) {
+	return r.first(r.db.Where("kind = ? AND booking_id = ?", settlemententity.InvoiceReceipt, bookingID))
+}
+
+func (r *InvoiceRepository) ListCorporateInvoices(corporateAccount string) ([]*settlemententity.Invoice, error) {
+	var ms []Invoice
+	err := r.db.Where("kind = ? AND corporate_account = ?", settlemententity.InvoiceCorporate, corporateAccount).
+		Order("period_start DESC, seq").Find(&ms).Error
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*settlemententity.Invoice, 0, len(ms))
+	for i := range ms {
+		res = append(res, toInvoiceEntity(&ms[i]))
+	}
+	return res, nil
+}
+
+func (r *InvoiceRepository) first(q *gorm.DB) (*settlemententity.Invoice, error) {
+	var ms []Invoice
+	if err := q.Limit(1).Find(&ms).Error; err != nil {
+		return nil, err
+	}
+	if len(ms) == 0 {
+		return nil, nil
+	}
+	inv := toInvoiceEntity(&ms[0])
+	var lines []InvoiceLine
+	if err := r.db.Where("invoice_id = ?", inv.ID).Order("seq").Find(&lines).Error; err != nil {
+		return nil, err
+	}
+	for _, l := range lines {
+		inv.Lines = append(inv.Lines, settlemententity.InvoiceLine{
+			Kind: l.Kind, BookingID: l.BookingID, Description: l.Description, AmountCents: l.AmountCents, TaxCents: l.TaxCents, Date: l.Date,
+		})
+	}
+	return inv, nil
+}
+
+// invoiceUniqueKey 收据每单一张；企业发票每个企业客户、账期、开票主体与币种一张。
+func invoiceUniqueKey(inv *settlemententity.Invoice) string {
+	if inv.Kind == settlemententity.InvoiceReceipt {
+		return "receipt:" + inv.BookingID
+	}
+	return "corporate:" + inv.CorporateAccount + ":" + inv.PeriodStart.UTC().Format("2006-01-02") + ":" + inv.Issuer.Code + ":" + inv.Currency
+}
+
+func toInvoiceModel(inv *settlemententity.Invoice, key string) *Invoice {
+	m := &Invoice{
+		ID: inv.ID, Kind: inv.Kind, Number: inv.Number, Seq: inv.Seq, UniqueKey: key,
+		LegalEntity: inv.Issuer.Code, IssuerName: inv.Issuer.Name, IssuerAddress: inv.Issuer.Address, IssuerTaxID: inv.Issuer.TaxID,
+		Currency: inv.Currency, BookingID: inv.BookingID, PassengerID: inv.PassengerID, PaymentID: inv.PaymentID, PaidAt: inv.PaidAt,
+		CorporateAccount: inv.CorporateAccount, TotalCents: inv.TotalCents, TaxCents: inv.TaxCents, IssuedAt: inv.IssuedAt,
+	}
+	if !inv.PeriodStart.IsZero() {
+		start, end := inv.PeriodStart, inv.PeriodEnd
+		m.PeriodStart, m.PeriodEnd = &start, &end
+	}
+	return m
+}
+
+func toInvoiceEntity(m *Invoice) *settlemententity.Invoice {
+	inv := &settlemententity.Invoice{
+		ID: m.ID, Kind: m.Kind, Number: m.Number, Seq: m.Seq,
+		Issuer:   settlemententity.LegalEntity{Code: m.LegalEntity, Name: m.IssuerName, Address: m.IssuerAddress, TaxID: m.IssuerTaxID},
+		Currency: m.Currency, BookingID: m.BookingID, PassengerID: m.PassengerID, PaymentID: m.PaymentID, PaidAt: m.PaidAt,
+		CorporateAccount: m.CorporateAccount, TotalCents: m.TotalCents, TaxCents: m.TaxCents, IssuedAt: m.IssuedAt,
+	}
+	if m.PeriodStart != nil && m.PeriodEnd != nil {
+		inv.PeriodStart, inv.PeriodEnd = *m.PeriodStart, *m.PeriodEnd
+	}
+	return inv
+}
@@ -0,0 +1,111 @@
+package mysqlrepo
+
+import (
+	"testing"
+	"time"
+
+	settlement "github.com/gavin/airport-pickup/internal/domain/settlement"
+	settlemententity "github.com/gavin/airport-pickup/internal/domain/settlement/entity"
+	userentity "github.com/gavin/airport-pickup/internal/domain/user/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func newTestDBInvoice() *gorm.DB {
+	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	db.AutoMigrate(&Passenger{}, &SettlementRecord{}, &SettlementFareItem{}, &Invoice{}, &InvoiceLine{}, &InvoiceSequence{})
+	return db
+}
+
+var testIssuer = settlemententity.LegalEntity{Code: "CN01", Name: "Pickup Co."}
+
+func newTestReceipt(bookingID string) *settlemententity.Invoice {
+	inv := &settlemententity.Invoice{ID: "inv-" + bookingID, Kind: settlemententity.InvoiceReceipt, Issuer: testIssuer, Currency: "CNY", BookingID: bookingID}
+	inv.AddLine(settlemententity.InvoiceLine{Kind: settlemententity.FareDistance, Description: "10 km", AmountCents: 2000, Date: time.Now()})
+	inv.AddLine(settlemententity.InvoiceLine{Kind: settlemententity.FareTax, Description: "VAT", AmountCents: 120, TaxCents: 120, Date: time.Now()})
+	return inv
+}
+
+func TestInvoiceRepository_SequentialNumbersPerLegalEntity(t *testing.T) {
+	repo := NewInvoiceRepository(newTestDBInvoice())
+	a, b := newTestReceipt("b1"), newTestReceipt("b2")
+	require.NoError(t, repo.CreateInvoice(a))
+	require.NoError(t, repo.CreateInvoice(b))
+	assert.Equal(t, "CN01-000001", a.Number)
+	assert.Equal(t, "CN01-000002", b.Number)
+
+	// 其他主体独立编号
+	c := newTestReceipt("b3")
+	c.Issuer = settlemententity.LegalEntity{Code: "US01", Name: "Pickup US", NumberPrefix: "US-"}
+	require.NoError(t, repo.CreateInvoice(c))
+	assert.Equal(t, "US-000001", c.Number)
+
+	// 同一订单的收据不重复开具，也不占用编号
+	assert.ErrorIs(t, repo.CreateInvoice(newTestReceipt("b1")), settlement.ErrInvoiceExists)
+	d := newTestReceipt("b4")
+	require.NoError(t, repo.CreateInvoice(d))
+	assert.Equal(t, "CN01-000003", d.Number)
+}
+
+func TestInvoiceRepository_GetReceipt(t *testing.T) {
+	repo := NewInvoiceRepository(newTestDBInvoice())
+	require.NoError(t, repo.CreateInvoice(newTestReceipt("b1")))
+
+	got, err := repo.GetReceiptByBookingID("b1")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, "CN01-000001", got.Number)
+	assert.Equal(t, "Pickup Co.", got.Issuer.Name)
+	assert.Equal(t, int64(2120), got.TotalCents)
+	assert.Equal(t, int64(120), got.TaxCents)
+	require.Len(t, got.Lines, 2)
+	assert.Equal(t, settlemententity.FareTax, got.Lines[1].Kind)
+
+	missing, err := repo.GetReceiptByBookingID("nope")
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+}
+
+func TestInvoiceRepository_CorporateRecordsAndInvoices(t *testing.T) {
+	db := newTestDBInvoice()
+	require.NoError(t, NewPassengerRepository(db).Save(&userentity.Passenger{ID: "p1", Name: "Alice", CorporateAccount: "ACME"}))
+	require.NoError(t, NewPassengerRepository(db).Save(&userentity.Passenger{ID: "p2", Name: "Bob"}))
+	srepo := NewSettlementRepository(db)
+	nov := time.Date(2025, 11, 10, 0, 0, 0, 0, time.UTC)
+	for _, sr := range []struct {
+		id, passenger string
+		at            time.Time
+	}{{"sr1", "p1", nov}, {"sr2", "p1", nov.AddDate(0, 1, 0)}, {"sr3", "p2", nov}} {
+		require.NoError(t, srepo.SaveSettlementRecord(&settlemententity.SettlementRecord{
+			ID: sr.id, BookingID: "b-" + sr.id, DriverID: "d1", PassengerID: sr.passenger, AmountCents: 1000, Currency: "CNY",
+			FareItems: []settlemententity.FareLineItem{{Kind: settlemententity.FareDistance, AmountCents: 1000, DriverCents: 1000}},
+		}))
+		require.NoError(t, db.Model(&SettlementRecord{}).Where("id = ?", sr.id).Update("created_at", sr.at).Error)
+	}
+	repo := NewInvoiceRepository(db)
+
+	accounts, err := repo.ListCorporateAccounts()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ACME"}, accounts)
+
+	start := time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC)
+	records, err := repo.ListCorporateSettlementRecords("ACME", start, start.AddDate(0, 1, 0))
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, "sr1", records[0].ID)
+	assert.Len(t, records[0].FareItems, 1)
+
+	inv := &settlemententity.Invoice{ID: "inv1", Kind: settlemententity.InvoiceCorporate, Issuer: testIssuer, Currency: "CNY",
+		CorporateAccount: "ACME", PeriodStart: start, PeriodEnd: start.AddDate(0, 1, 0)}
+	require.NoError(t, repo.CreateInvoice(inv))
+	dup := *inv
+	dup.ID = "inv2"
+	assert.ErrorIs(t, repo.CreateInvoice(&dup), settlement.ErrInvoiceExists)
+
+	list, err := repo.ListCorporateInvoices("ACME")
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.True(t, list[0].PeriodStart.Equal(start))
+}
@@ -4,72 +4,201 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+
+	"github.com/gavin/airport-pickup/internal/domain/money"
 )
 
 // GORM models
 
 type Passenger struct {
-	ID        string    `gorm:"primaryKey;size:64"`
-	Name      string    `gorm:"size:200;not null"`
-	CreatedAt time.Time `gorm:"not null"`
-	UpdatedAt time.Time `gorm:"not null"`
+	ID                     string    `gorm:"primaryKey;size:64"`
+	Name                   string    `gorm:"size:200;not null"`
+	CorporateAccount       string    `gorm:"index;size:64;not null;default:''"`
+	ReputationScore        float64   `gorm:"not null;default:0"`
+	RatingCount            int       `gorm:"not null;default:0"`
+	NoShowCount            int       `gorm:"not null;default:0"`
+	Phone                  string    `gorm:"size:20;not null;default:''"`
+	Email                  string    `gorm:"size:254;not null;default:''"`
+	PreferredLanguage      string    `gorm:"size:35;not null;default:''"`
+	DefaultPaymentMethodID string    `gorm:"size:64;not null;default:''"`
+	CreatedAt              time.Time `gorm:"not null"`
+	UpdatedAt              time.Time `gorm:"not null"`
 }
 
 type Driver struct {
+	ID           string    `gorm:"primaryKey;size:64"`
+	Name         string    `gorm:"size:200;not null"`
+	Rating       float64   `gorm:"not null"`
+	RatingCount  int       `gorm:"not null;default:0"`
+	Status       string    `gorm:"size:20;index;not null;default:'active'"` // 入驻流程上线前的司机视为已激活
+	StatusReason string    `gorm:"size:200"`
+	CreatedAt    time.Time `gorm:"not null"`
+	UpdatedAt    time.Time `gorm:"not null"`
+}
+
+// DriverDocument is an uploaded driver document; the file itself lives in blob storage under BlobKey.
+type DriverDocument struct {
+	ID          string    `gorm:"primaryKey;size:64"`
+	DriverID    string    `gorm:"size:64;index;not null"`
+	Type        string    `gorm:"size:32;not null"`
+	BlobKey     string    `gorm:"size:255;not null"`
+	FileName    string    `gorm:"size:255"`
+	ContentType string    `gorm:"size:100;not null"`
+	SizeBytes   int64     `gorm:"not null"`
+	ExpiresAt   time.Time `gorm:"index:idx_document_status_expires,priority:2;not null"`
+	Status      string    `gorm:"size:20;index:idx_document_status_expires,priority:1;not null"`
+	ReviewedBy  string    `gorm:"size:64"`
+	ReviewNote  string    `gorm:"size:500"`
+	ReviewedAt  *time.Time
+	UploadedAt  time.Time `gorm:"not null"`
+}
+
+// Vehicle is a vehicle registered by a driver; plates are unique among vehicles that were not rejected.
+type Vehicle struct {
+	ID              string `gorm:"primaryKey;size:64"`
+	DriverID        string `gorm:"size:64;index;not null"`
+	Plate           string `gorm:"size:16;index;not null"`
+	Make            string `gorm:"size:50;not null"`
+	Model           string `gorm:"size:50;not null"`
+	Colour          string `gorm:"size:30;not null"`
+	Class           string `gorm:"size:50;not null"`
+	Seats           int    `gorm:"not null"`
+	LuggageCapacity int    `gorm:"not null"`
+	Status          string `gorm:"size:20;index;not null"`
+	VerifiedBy      string `gorm:"size:64"`
+	ReviewNote      string `gorm:"size:500"`
+	ReviewedAt      *time.Time
+	CreatedAt       time.Time `gorm:"not null"`
+}
+
+// PaymentMethod 乘客保存的支付方式，卡号只保存在保险库，这里只有令牌
+type PaymentMethod struct {
+	ID          string    `gorm:"primaryKey;size:64"`
+	PassengerID string    `gorm:"size:64;index;not null"`
+	Token       string    `gorm:"size:128;uniqueIndex;not null"`
+	Brand       string    `gorm:"size:20;not null"`
+	Last4       string    `gorm:"size:4;not null"`
+	ExpMonth    int       `gorm:"not null"`
+	ExpYear     int       `gorm:"not null"`
+	Status      string    `gorm:"size:20;not null"`
+	CreatedAt   time.Time `gorm:"not null"`
+	RemovedAt   *time.Time
+}
+
+// TripRating is a post-trip rating; (booking_id, target) is unique so each side rates a booking once.
+type TripRating struct {
 	ID        string    `gorm:"primaryKey;size:64"`
-	Name      string    `gorm:"size:200;not null"`
-	Rating    float64   `gorm:"not null"`
+	BookingID string    `gorm:"size:64;uniqueIndex:idx_rating_booking_target;not null"`
+	Target    string    `gorm:"size:20;uniqueIndex:idx_rating_booking_target;index:idx_rating_ratee;not null"`
+	RaterID   string    `gorm:"size:64;not null"`
+	RateeID   string    `gorm:"size:64;index:idx_rating_ratee;not null"`
+	Score     int       `gorm:"not null"`
+	Comment   string    `gorm:"size:2000"`
+	NoShow    bool      `gorm:"not null;default:false"`
 	CreatedAt time.Time `gorm:"not null"`
-	UpdatedAt time.Time `gorm:"not null"`
 }
 
 type PickupRequest struct {
-	ID               string    `gorm:"primaryKey;size:64"`
-	PassengerID      string    `gorm:"index:idx_pickup_passenger_status;size:64;not null"`
-	AirportCode      string    `gorm:"size:10;not null"`
-	VehicleType      string    `gorm:"size:50;not null"`
-	DesiredTime      time.Time `gorm:"not null"`
-	MaxPricePerKm    float64   `gorm:"not null"`
-	PreferHighRating bool      `gorm:"not null"`
-	Status           string    `gorm:"size:20;index:idx_pickup_passenger_status;not null"`
-	CreatedAt        time.Time `gorm:"not null"`
-	UpdatedAt        time.Time `gorm:"not null"`
+	ID               string      `gorm:"primaryKey;size:64"`
+	PassengerID      string      `gorm:"index:idx_pickup_passenger_status;size:64;not null"`
+	AirportCode      string      `gorm:"size:10;not null"`
+	VehicleType      string      `gorm:"size:50;not null"`
+	DesiredTime      time.Time   `gorm:"not null"`
+	MaxPricePerKm    money.Money `gorm:"type:decimal(10,2);not null"`
+	Currency         string      `gorm:"size:3;not null;default:'CNY'"`
+	PreferHighRating bool        `gorm:"not null"`
+	PromoCode        string      `gorm:"size:32"`
+	QuoteID          string      `gorm:"size:64"`
+	PassengerScore   float64     `gorm:"not null;default:0"`
+	DepositCents     int64       `gorm:"not null;default:0"`
+	PaymentMethodID  string      `gorm:"size:64;not null;default:''"`
+	Status           string      `gorm:"size:20;index:idx_pickup_passenger_status;not null"`
+	CreatedAt        time.Time   `gorm:"not null"`
+	UpdatedAt        time.Time   `gorm:"not null"`
 }
 
 type DriverOffer struct {
-	ID            string    `gorm:"primaryKey;size:64"`
-	DriverID      string    `gorm:"index:idx_offer_driver_status;size:64;not null"`
-	AirportCode   string    `gorm:"size:10;not null"`
-	VehicleType   string    `gorm:"size:50;not null"`
-	AvailableFrom time.Time `gorm:"not null"`
-	AvailableTo   time.Time `gorm:"not null"`
-	PricePerKm    float64   `gorm:"not null"`
-	Rating        float64   `gorm:"not null"`
-	Status        string    `gorm:"size:20;index:idx_offer_driver_status;not null"`
-	CreatedAt     time.Time `gorm:"not null"`
-	UpdatedAt     time.Time `gorm:"not null"`
+	ID                string      `gorm:"primaryKey;size:64"`
+	DriverID          string      `gorm:"index:idx_offer_driver_status;size:64;not null"`
+	AirportCode       string      `gorm:"size:10;not null"`
+	VehicleType       string      `gorm:"size:50;not null"`
+	VehicleID         string      `gorm:"size:64;not null;default:''"`
+	AvailableFrom     time.Time   `gorm:"not null"`
+	AvailableTo       time.Time   `gorm:"not null"`
+	PricePerKm        money.Money `gorm:"type:decimal(10,2);not null"`
+	Currency          string      `gorm:"size:3;not null;default:'CNY'"`
+	Rating            float64     `gorm:"not null"`
+	MinPassengerScore float64     `gorm:"not null;default:0"`
+	Status            string      `gorm:"size:20;index:idx_offer_driver_status;not null"`
+	CreatedAt         time.Time   `gorm:"not null"`
+	UpdatedAt         time.Time   `gorm:"not null"`
 }
 
 type Booking struct {
-	ID                  string    `gorm:"primaryKey;size:64"`
-	RequestID           string    `gorm:"size:64;not null"`
-	OfferID             string    `gorm:"size:64;not null"`
-	PassengerID         string    `gorm:"size:64;not null"`
-	DriverID            string    `gorm:"size:64;not null"`
-	PricePerKm          float64   `gorm:"not null"`
-	PlatformMarginPerKm float64   `gorm:"not null"`
-	Status              string    `gorm:"size:20;not null"`
-	CreatedAt           time.Time `gorm:"not null"`
-	UpdatedAt           time.Time `gorm:"not null"`
+	ID                  string      `gorm:"primaryKey;size:64"`
+	RequestID           string      `gorm:"size:64;not null"`
+	OfferID             string      `gorm:"size:64;not null"`
+	PassengerID         string      `gorm:"size:64;not null"`
+	DriverID            string      `gorm:"size:64;not null"`
+	VehicleID           string      `gorm:"size:64;not null;default:''"`
+	PaymentMethodID     string      `gorm:"size:64;not null;default:''"`
+	PricePerKm          money.Money `gorm:"type:decimal(10,2);not null"`
+	FarePerKm           money.Money `gorm:"type:decimal(10,2);not null;default:0"`
+	PlatformMarginPerKm money.Money `gorm:"type:decimal(10,2);not null"`
+	PricingTerms        string      `gorm:"type:text"` // 计价策略快照（JSON）
+	SurgeMultiplier     float64     `gorm:"not null;default:1"`
+	Currency            string      `gorm:"size:3;not null;default:'CNY'"`
+	AirportCode         string      `gorm:"size:10;not null;default:''"`
+	Status              string      `gorm:"size:20;not null"`
+	DistanceKm          float64     `gorm:"not null;default:0"`
+	WaitingMinutes      int         `gorm:"not null;default:0"`
+	Tolls               money.Money `gorm:"type:decimal(10,2);not null;default:0"`
+	CreatedAt           time.Time   `gorm:"not null"`
+	UpdatedAt           time.Time   `gorm:"not null"`
 }
 
+// Quote is a fare quote from the live order book; requests created with its id use the locked bid and pricing terms.
+type Quote struct {
+	ID                  string      `gorm:"primaryKey;size:64"`
+	PassengerID         string      `gorm:"size:64;not null;default:''"`
+	AirportCode         string      `gorm:"size:10;not null"`
+	VehicleType         string      `gorm:"size:50;not null"`
+	DesiredTime         time.Time   `gorm:"not null"`
+	DistanceKm          float64     `gorm:"not null"`
+	Currency            string      `gorm:"size:3;not null"`
+	MaxPricePerKm       money.Money `gorm:"type:decimal(10,2);not null"`
+	BestOfferPerKm      money.Money `gorm:"type:decimal(10,2);not null"`
+	Percentiles         string      `gorm:"type:text;not null"` // 价格分位数（JSON）
+	AvailableOffers     int         `gorm:"not null"`
+	MatchProbability    float64     `gorm:"not null"`
+	SurgeMultiplier     float64     `gorm:"not null;default:1"`
+	FarePerKm           money.Money `gorm:"type:decimal(10,2);not null"`
+	PlatformMarginPerKm money.Money `gorm:"type:decimal(10,2);not null"`
+	EstimatedFare       money.Money `gorm:"type:decimal(10,2);not null"`
+	PricingTerms        string      `gorm:"type:text;not null"` // 锁定的计价策略（JSON）
+	CreatedAt           time.Time   `gorm:"not null"`
+	ExpiresAt           time.Time   `gorm:"index;not null"`
+}
+
+// PaymentTransaction tracks the authorize/capture/void/refund lifecycle, one payment row per booking plus one row per refund and per pickup-request deposit.
 type PaymentTransaction struct {
-	ID          string    `gorm:"primaryKey;size:64"`
-	BookingID   string    `gorm:"size:64;not null"`
-	AmountCents int64     `gorm:"not null"`
-	Status      string    `gorm:"size:20;not null"`
-	CreatedAt   time.Time `gorm:"not null"`
-	UpdatedAt   time.Time `gorm:"not null"`
+	ID            string `gorm:"primaryKey;size:64"`
+	BookingID     string `gorm:"index;size:64;not null"`
+	Kind          string `gorm:"size:20;not null;default:payment"`
+	AmountCents   int64  `gorm:"not null"`
+	CapturedCents int64  `gorm:"not null;default:0"`
+	RefundedCents int64  `gorm:"not null;default:0"`
+	Currency      string `gorm:"size:3;not null;default:'CNY'"`
+	Status        string `gorm:"size:20;not null"`
+	FailureReason string `gorm:"size:255"`
+	ReasonCode    string `gorm:"size:50"`
+	AuthorizedAt  *time.Time
+	CapturedAt    *time.Time
+	VoidedAt      *time.Time
+	RefundedAt    *time.Time
+	FailedAt      *time.Time
+	CreatedAt     time.Time `gorm:"not null"`
+	UpdatedAt     time.Time `gorm:"not null"`
 }
 
 type SettlementRecord struct {
@@ -79,23 +208,261 @@ type SettlementRecord struct {
 	PassengerID          string    `gorm:"size:64;not null"`
 	AmountCents          int64     `gorm:"not null"`
 	PlatformRevenueCents int64     `gorm:"not null"`
+	TaxCents             int64     `gorm:"not null;default:0"` // 代收税费，不计入平台收入
+	Currency             string    `gorm:"size:3;not null;default:'CNY'"`
+	PayoutID             string    `gorm:"index;size:64;not null;default:''"` // 为空表示尚未打款
 	CreatedAt            time.Time `gorm:"not null"`
 	UpdatedAt            time.Time `gorm:"not null"`
 }
 
+// SettlementFareItem is one line of the fare breakdown stored with a settlement record.
+type SettlementFareItem struct {
+	ID                 int64     `gorm:"primaryKey;autoIncrement"`
+	SettlementRecordID string    `gorm:"index;size:64;not null"`
+	BookingID          string    `gorm:"index;size:64;not null"`
+	Seq                int       `gorm:"not null"`
+	Kind               string    `gorm:"size:20;not null"`
+	Description        string    `gorm:"size:100"`
+	AmountCents        int64     `gorm:"not null"`
+	DriverCents        int64     `gorm:"not null"`
+	PlatformCents      int64     `gorm:"not null"`
+	TaxCents           int64     `gorm:"not null;default:0"`
+	CreatedAt          time.Time `gorm:"not null"`
+}
+
 type RevenueRecord struct {
 	ID         string    `gorm:"primaryKey;size:64"`
 	BookingID  string    `gorm:"size:64;not null"`
 	DeltaCents int64     `gorm:"not null"`
+	Currency   string    `gorm:"size:3;not null;default:'CNY'"`
 	CreatedAt  time.Time `gorm:"not null"`
 	UpdatedAt  time.Time `gorm:"not null"`
 }
 
+// SettlementSaga tracks settlement progress, one row per booking.
+type SettlementSaga struct {
+	ID                   string    `gorm:"primaryKey;size:64"`
+	BookingID            string    `gorm:"uniqueIndex;size:64;not null"`
+	DriverID             string    `gorm:"size:64;not null"`
+	PassengerID          string    `gorm:"size:64;not null"`
+	PaymentMethod        string    `gorm:"size:128;not null;default:''"` // 支付方式令牌，为空从乘客钱包扣款
+	AmountCents          int64     `gorm:"not null"`
+	PlatformRevenueCents int64     `gorm:"not null"`
+	TaxCents             int64     `gorm:"not null;default:0"`
+	Currency             string    `gorm:"size:3;not null;default:'CNY'"`
+	Status               string    `gorm:"size:20;index:idx_saga_status_updated;not null"`
+	Attempts             int       `gorm:"not null"`
+	LastError            string    `gorm:"size:500"`
+	FareItems            string    `gorm:"type:text"` // JSON，落库前的车费明细
+	CreatedAt            time.Time `gorm:"not null"`
+	UpdatedAt            time.Time `gorm:"index:idx_saga_status_updated;not null"`
+}
+
+// JournalEntry is a balanced double-entry ledger posting.
+type JournalEntry struct {
+	ID        string    `gorm:"primaryKey;size:64"`
+	BookingID string    `gorm:"index;size:64;not null"`
+	PayoutID  string    `gorm:"index;size:64;not null;default:''"`
+	Kind      string    `gorm:"size:20;not null"`
+	Currency  string    `gorm:"size:3;not null;default:'CNY'"`
+	CreatedAt time.Time `gorm:"not null"`
+}
+
+// JournalLine is one debit or credit of a journal entry. Currency is copied
+// from the entry so balances can be grouped per currency without a join.
+type JournalLine struct {
+	ID          int64     `gorm:"primaryKey;autoIncrement"`
+	EntryID     string    `gorm:"index;size:64;not null"`
+	Account     string    `gorm:"index;size:100;not null"`
+	Currency    string    `gorm:"size:3;not null;default:'CNY'"`
+	DebitCents  int64     `gorm:"not null"`
+	CreditCents int64     `gorm:"not null"`
+	CreatedAt   time.Time `gorm:"not null"`
+}
+
+// Payout is a transfer to a driver covering several settlement records.
+type Payout struct {
+	ID              string `gorm:"primaryKey;size:64"`
+	DriverID        string `gorm:"index;size:64;not null"`
+	Status          string `gorm:"size:20;index;not null"`
+	GrossCents      int64  `gorm:"not null"`
+	CommissionCents int64  `gorm:"not null"`
+	FeeCents        int64  `gorm:"not null"`
+	NetCents        int64  `gorm:"not null"`
+	Currency        string `gorm:"size:3;not null;default:'CNY'"`
+	ProviderRef     string `gorm:"size:128"`
+	FailureReason   string `gorm:"size:255"`
+	PaidAt          *time.Time
+	FailedAt        *time.Time
+	CreatedAt       time.Time `gorm:"not null"`
+	UpdatedAt       time.Time `gorm:"not null"`
+}
+
+// PayoutLine links a payout to one settlement record.
+type PayoutLine struct {
+	ID                 int64     `gorm:"primaryKey;autoIncrement"`
+	PayoutID           string    `gorm:"index;size:64;not null"`
+	SettlementRecordID string    `gorm:"size:64;not null"`
+	BookingID          string    `gorm:"size:64;not null"`
+	AmountCents        int64     `gorm:"not null"`
+	CommissionCents    int64     `gorm:"not null"`
+	NetCents           int64     `gorm:"not null"`
+	CreatedAt          time.Time `gorm:"not null"`
+}
+
+// Invoice is an issued receipt or corporate invoice. UniqueKey prevents issuing the same document twice.
+type Invoice struct {
+	ID               string `gorm:"primaryKey;size:64"`
+	Kind             string `gorm:"size:20;not null"`
+	Number           string `gorm:"uniqueIndex;size:64;not null"`
+	Seq              int64  `gorm:"not null"`
+	UniqueKey        string `gorm:"uniqueIndex;size:255;not null"`
+	LegalEntity      string `gorm:"size:32;not null"`
+	IssuerName       string `gorm:"size:200;not null"`
+	IssuerAddress    string `gorm:"size:255"`
+	IssuerTaxID      string `gorm:"size:64"`
+	Currency         string `gorm:"size:3;not null;default:'CNY'"`
+	BookingID        string `gorm:"index;size:64"`
+	PassengerID      string `gorm:"size:64"`
+	PaymentID        string `gorm:"size:64"`
+	PaidAt           *time.Time
+	CorporateAccount string `gorm:"index;size:64"`
+	PeriodStart      *time.Time
+	PeriodEnd        *time.Time
+	TotalCents       int64     `gorm:"not null"`
+	TaxCents         int64     `gorm:"not null"`
+	IssuedAt         time.Time `gorm:"not null"`
+}
+
+// InvoiceLine is one line of an issued document.
+type InvoiceLine struct {
+	ID          int64     `gorm:"primaryKey;autoIncrement"`
+	InvoiceID   string    `gorm:"index;size:64;not null"`
+	Seq         int       `gorm:"not null"`
+	Kind        string    `gorm:"size:20;not null"`
+	BookingID   string    `gorm:"size:64"`
+	Description string    `gorm:"size:255"`
+	AmountCents int64     `gorm:"not null"`
+	TaxCents    int64     `gorm:"not null"`
+	Date        time.Time `gorm:"not null"`
+}
+
+// InvoiceSequence holds the next document number per legal entity.
+type InvoiceSequence struct {
+	LegalEntity string `gorm:"primaryKey;size:32"`
+	LastSeq     int64  `gorm:"not null"`
+}
+
+// ReconciliationReport is the result of importing one provider statement.
+type ReconciliationReport struct {
+	ID               string    `gorm:"primaryKey;size:64"`
+	Source           string    `gorm:"size:255"`
+	Status           string    `gorm:"size:20;index;not null"`
+	PeriodStart      time.Time `gorm:"not null"`
+	PeriodEnd        time.Time `gorm:"not null"`
+	StatementLines   int       `gorm:"not null"`
+	Transactions     int       `gorm:"not null"`
+	MatchedLines     int       `gorm:"not null"`
+	StatementCents   int64     `gorm:"not null"`
+	TransactionCents int64     `gorm:"not null"`
+	CreatedAt        time.Time `gorm:"not null"`
+	UpdatedAt        time.Time `gorm:"not null"`
+}
+
+// ReconciliationException is one mismatch found by a reconciliation.
+type ReconciliationException struct {
+	ID              string `gorm:"primaryKey;size:64"`
+	ReportID        string `gorm:"index;size:64;not null"`
+	Seq             int    `gorm:"not null"`
+	Kind            string `gorm:"size:30;not null"`
+	BookingID       string `gorm:"index;size:64;not null"`
+	Type            string `gorm:"size:20;not null"`
+	TransactionID   string `gorm:"size:64"`
+	StatementRef    string `gorm:"size:128"`
+	StatementLineNo int
+	ExpectedCents   int64
+	StatementCents  int64
+	Currency        string `gorm:"size:3;not null;default:'CNY'"`
+	Status          string `gorm:"size:20;index;not null"`
+	Resolution      string `gorm:"size:30"`
+	Note            string `gorm:"size:500"`
+	ResolvedAt      *time.Time
+}
+
+// RevenueDailyStat is the revenue aggregate of one UTC day, airport, vehicle type and currency.
+type RevenueDailyStat struct {
+	Day          time.Time `gorm:"primaryKey"`
+	AirportCode  string    `gorm:"primaryKey;size:10"`
+	VehicleType  string    `gorm:"primaryKey;size:50"`
+	Currency     string    `gorm:"primaryKey;size:3"`
+	Trips        int64     `gorm:"not null"`
+	GrossCents   int64     `gorm:"not null"`
+	RevenueCents int64     `gorm:"not null"`
+	UpdatedAt    time.Time `gorm:"not null"`
+}
+
+// RevenueStatSource marks a settlement record already added to revenue_daily_stats.
+type RevenueStatSource struct {
+	SettlementRecordID string    `gorm:"primaryKey;size:64"`
+	Day                time.Time `gorm:"not null"`
+	CreatedAt          time.Time `gorm:"not null"`
+}
+
+// Promotion is a promo code campaign; redeemed_count and spent_cents are updated conditionally on redemption.
+type Promotion struct {
+	Code              string    `gorm:"primaryKey;size:32"`
+	Description       string    `gorm:"size:255"`
+	Kind              string    `gorm:"size:20;not null"`
+	Rate              float64   `gorm:"not null"`
+	AmountCents       int64     `gorm:"not null"`
+	MaxDiscountCents  int64     `gorm:"not null"`
+	Currency          string    `gorm:"size:3;not null"`
+	AirportCodes      string    `gorm:"size:255"` // 逗号分隔，为空表示所有机场
+	StartsAt          time.Time `gorm:"not null"`
+	EndsAt            time.Time `gorm:"not null"`
+	PerPassengerLimit int       `gorm:"not null"`
+	TotalLimit        int       `gorm:"not null"`
+	BudgetCents       int64     `gorm:"not null"`
+	RedeemedCount     int       `gorm:"not null"`
+	SpentCents        int64     `gorm:"not null"`
+	CreatedAt         time.Time `gorm:"not null"`
+	UpdatedAt         time.Time `gorm:"not null"`
+}
+
+// PromoRedemption is one promotion redemption; booking_id is unique so a booking redeems at most once.
+type PromoRedemption struct {
+	ID          string    `gorm:"primaryKey;size:64"`
+	Code        string    `gorm:"size:32;index:idx_redemption_code_passenger;not null"`
+	BookingID   string    `gorm:"size:64;uniqueIndex;not null"`
+	PassengerID string    `gorm:"size:64;index:idx_redemption_code_passenger;not null"`
+	AmountCents int64     `gorm:"not null"`
+	Currency    string    `gorm:"size:3;not null"`
+	Status      string    `gorm:"size:20;not null"`
+	CreatedAt   time.Time `gorm:"not null"`
+	ReleasedAt  *time.Time
+}
+
+// DomainEvent is an append-only event store row.
+type DomainEvent struct {
+	Seq          int64     `gorm:"primaryKey;autoIncrement"`
+	Name         string    `gorm:"size:100;index:idx_event_name;not null"`
+	AggregateKey string    `gorm:"size:128;index:idx_event_aggregate;not null"`
+	Payload      string    `gorm:"type:text;not null"`
+	OccurredAt   time.Time `gorm:"index:idx_event_occurred;not null"`
+}
+
 // AutoMigrate migrates all tables.
 func AutoMigrate(db *gorm.DB) error {
 	return db.AutoMigrate(
-		&Passenger{}, &Driver{},
-		&PickupRequest{}, &DriverOffer{}, &Booking{},
-		&PaymentTransaction{}, &SettlementRecord{}, &RevenueRecord{},
+		&Passenger{}, &Driver{}, &TripRating{}, &DriverDocument{}, &Vehicle{}, &PaymentMethod{},
+		&PickupRequest{}, &DriverOffer{}, &Booking{}, &Quote{},
+		&PaymentTransaction{}, &SettlementRecord{}, &SettlementFareItem{}, &RevenueRecord{}, &SettlementSaga{},
+		&JournalEntry{}, &JournalLine{},
+		&Payout{}, &PayoutLine{},
+		&Invoice{}, &InvoiceLine{}, &InvoiceSequence{},
+		&ReconciliationReport{}, &ReconciliationException{},
+		&RevenueDailyStat{}, &RevenueStatSource{},
+		&Promotion{}, &PromoRedemption{},
+		&DomainEvent{},
 	)
 }
@@ -4,6 +4,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"testing"
 	"time"
+
+	"github.com/gavin/airport-pickup/internal/domain/money"
 )
 
 func TestPassengerInit(t *testing.T) {
@@ -20,7 +22,7 @@ func TestDriverInit(t *testing.T) {
 }
 
 func TestPickupRequestInit(t *testing.T) {
-	r := PickupRequest{ID: "r1", PassengerID: "p1", AirportCode: "PVG", VehicleType: "Sedan", DesiredTime: time.Now(), MaxPricePerKm: 10, PreferHighRating: true, Status: "open", CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	r := PickupRequest{ID: "r1", PassengerID: "p1", AirportCode: "PVG", VehicleType: "Sedan", DesiredTime: time.Now(), MaxPricePerKm: money.MustParse("10"), PreferHighRating: true, Status: "open", CreatedAt: time.Now(), UpdatedAt: time.Now()}
 	assert.Equal(t, "r1", r.ID)
 	assert.Equal(t, "p1", r.PassengerID)
 	assert.Equal(t, "PVG", r.AirportCode)
@@ -29,7 +31,7 @@ func TestPickupRequestInit(t *testing.T) {
 }
 
 func TestDriverOfferInit(t *testing.T) {
-	do := DriverOffer{ID: "o1", DriverID: "d1", AirportCode: "PVG", VehicleType: "SUV", AvailableFrom: time.Now(), AvailableTo: time.Now(), PricePerKm: 12, Rating: 4.9, Status: "active", CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	do := DriverOffer{ID: "o1", DriverID: "d1", AirportCode: "PVG", VehicleType: "SUV", AvailableFrom: time.Now(), AvailableTo: time.Now(), PricePerKm: money.MustParse("12"), Rating: 4.9, Status: "active", CreatedAt: time.Now(), UpdatedAt: time.Now()}
 	assert.Equal(t, "o1", do.ID)
 	assert.Equal(t, "d1", do.DriverID)
 	assert.Equal(t, "SUV", do.VehicleType)
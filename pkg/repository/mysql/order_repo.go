@@ -1,6 +1,8 @@
 package mysqlrepo
 
 import (
+	"encoding/json"
+
 	order "github.com/gavin/airport-pickup/internal/domain/order"
 	orderentity "github.com/gavin/airport-pickup/internal/domain/order/entity"
 	"gorm.io/gorm"
@@ -15,7 +17,8 @@ func NewOrderRepository(db *gorm.DB) order.OrderRepository { return &OrderReposi
 func (r *OrderRepository) SavePickupRequest(p *orderentity.PickupRequest) error {
 	m := &PickupRequest{
 		ID: p.ID, PassengerID: p.PassengerID, AirportCode: p.AirportCode, VehicleType: p.VehicleType,
-		DesiredTime: p.DesiredTime, MaxPricePerKm: p.MaxPricePerKm, PreferHighRating: p.PreferHighRating, Status: p.Status,
+		DesiredTime: p.DesiredTime, MaxPricePerKm: p.MaxPricePerKm, Currency: p.Currency, PreferHighRating: p.PreferHighRating, PromoCode: p.PromoCode, QuoteID: p.QuoteID,
+		PassengerScore: p.PassengerScore, DepositCents: p.DepositCents, PaymentMethodID: p.PaymentMethodID, Status: p.Status,
 	}
 	now := time.Now()
 	m.CreatedAt = now
@@ -30,7 +33,8 @@ func (r *OrderRepository) GetPickupRequestByID(id string) (*orderentity.PickupRe
 	}
 	return &orderentity.PickupRequest{
 		ID: m.ID, PassengerID: m.PassengerID, AirportCode: m.AirportCode, VehicleType: m.VehicleType,
-		DesiredTime: m.DesiredTime, MaxPricePerKm: m.MaxPricePerKm, PreferHighRating: m.PreferHighRating, Status: m.Status,
+		DesiredTime: m.DesiredTime, MaxPricePerKm: m.MaxPricePerKm, Currency: m.Currency, PreferHighRating: m.PreferHighRating, PromoCode: m.PromoCode, QuoteID: m.QuoteID,
+		PassengerScore: m.PassengerScore, DepositCents: m.DepositCents, PaymentMethodID: m.PaymentMethodID, Status: m.Status,
 		CreatedAt: m.CreatedAt, UpdatedAt: m.UpdatedAt,
 	}, nil
 }
@@ -44,7 +48,8 @@ func (r *OrderRepository) ListPickupRequests() ([]*orderentity.PickupRequest, er
 	for _, m := range ms {
 		res = append(res, &orderentity.PickupRequest{
 			ID: m.ID, PassengerID: m.PassengerID, AirportCode: m.AirportCode, VehicleType: m.VehicleType,
-			DesiredTime: m.DesiredTime, MaxPricePerKm: m.MaxPricePerKm, PreferHighRating: m.PreferHighRating, Status: m.Status,
+			DesiredTime: m.DesiredTime, MaxPricePerKm: m.MaxPricePerKm, Currency: m.Currency, PreferHighRating: m.PreferHighRating, PromoCode: m.PromoCode, QuoteID: m.QuoteID,
+			PassengerScore: m.PassengerScore, DepositCents: m.DepositCents, PaymentMethodID: m.PaymentMethodID, Status: m.Status,
 			CreatedAt: m.CreatedAt, UpdatedAt: m.UpdatedAt,
 		})
 	}
@@ -58,9 +63,9 @@ func (r *OrderRepository) UpdatePickupRequest(p *orderentity.PickupRequest) erro
 // DriverOffer
 func (r *OrderRepository) SaveDriverOffer(o *orderentity.DriverOffer) error {
 	m := &DriverOffer{
-		ID: o.ID, DriverID: o.DriverID, AirportCode: o.AirportCode, VehicleType: o.VehicleType,
-		AvailableFrom: o.AvailableFrom, AvailableTo: o.AvailableTo, PricePerKm: o.PricePerKm,
-		Rating: o.Rating, Status: o.Status,
+		ID: o.ID, DriverID: o.DriverID, AirportCode: o.AirportCode, VehicleType: o.VehicleType, VehicleID: o.VehicleID,
+		AvailableFrom: o.AvailableFrom, AvailableTo: o.AvailableTo, PricePerKm: o.PricePerKm, Currency: o.Currency,
+		Rating: o.Rating, MinPassengerScore: o.MinPassengerScore, Status: o.Status,
 	}
 	now := time.Now()
 	m.CreatedAt = now
@@ -74,9 +79,9 @@ func (r *OrderRepository) GetDriverOfferByID(id string) (*orderentity.DriverOffe
 		return nil, err
 	}
 	return &orderentity.DriverOffer{
-		ID: m.ID, DriverID: m.DriverID, AirportCode: m.AirportCode, VehicleType: m.VehicleType,
-		AvailableFrom: m.AvailableFrom, AvailableTo: m.AvailableTo, PricePerKm: m.PricePerKm,
-		Rating: m.Rating, Status: m.Status,
+		ID: m.ID, DriverID: m.DriverID, AirportCode: m.AirportCode, VehicleType: m.VehicleType, VehicleID: m.VehicleID,
+		AvailableFrom: m.AvailableFrom, AvailableTo: m.AvailableTo, PricePerKm: m.PricePerKm, Currency: m.Currency,
+		Rating: m.Rating, MinPassengerScore: m.MinPassengerScore, Status: m.Status,
 		CreatedAt: m.CreatedAt, UpdatedAt: m.UpdatedAt,
 	}, nil
 }
@@ -86,16 +91,20 @@ func (r *OrderRepository) ListDriverOffers() ([]*orderentity.DriverOffer, error)
 	if err := r.db.Find(&ms).Error; err != nil {
 		return nil, err
 	}
+	return toDriverOfferEntities(ms), nil
+}
+
+func toDriverOfferEntities(ms []DriverOffer) []*orderentity.DriverOffer {
 	res := make([]*orderentity.DriverOffer, 0, len(ms))
 	for _, m := range ms {
 		res = append(res, &orderentity.DriverOffer{
-			ID: m.ID, DriverID: m.DriverID, AirportCode: m.AirportCode, VehicleType: m.VehicleType,
-			AvailableFrom: m.AvailableFrom, AvailableTo: m.AvailableTo, PricePerKm: m.PricePerKm,
-			Rating: m.Rating, Status: m.Status,
+			ID: m.ID, DriverID: m.DriverID, AirportCode: m.AirportCode, VehicleType: m.VehicleType, VehicleID: m.VehicleID,
+			AvailableFrom: m.AvailableFrom, AvailableTo: m.AvailableTo, PricePerKm: m.PricePerKm, Currency: m.Currency,
+			Rating: m.Rating, MinPassengerScore: m.MinPassengerScore, Status: m.Status,
 			CreatedAt: m.CreatedAt, UpdatedAt: m.UpdatedAt,
 		})
 	}
-	return res, nil
+	return res
 }
 
 func (r *OrderRepository) UpdateDriverOffer(o *orderentity.DriverOffer) error {
@@ -104,10 +113,7 @@ func (r *OrderRepository) UpdateDriverOffer(o *orderentity.DriverOffer) error {
 
 // Booking
 func (r *OrderRepository) SaveBooking(b *orderentity.Booking) error {
-	m := &Booking{
-		ID: b.ID, RequestID: b.RequestID, OfferID: b.OfferID, PassengerID: b.PassengerID, DriverID: b.DriverID,
-		PricePerKm: b.PricePerKm, PlatformMarginPerKm: b.PlatformMarginPerKm, Status: b.Status,
-	}
+	m := toBookingModel(b)
 	now := time.Now()
 	m.CreatedAt = now
 	m.UpdatedAt = now
@@ -119,11 +125,7 @@ func (r *OrderRepository) GetBookingByID(id string) (*orderentity.Booking, error
 	if err := r.db.First(&m, "id = ?", id).Error; err != nil {
 		return nil, err
 	}
-	return &orderentity.Booking{
-		ID: m.ID, RequestID: m.RequestID, OfferID: m.OfferID, PassengerID: m.PassengerID, DriverID: m.DriverID,
-		PricePerKm: m.PricePerKm, PlatformMarginPerKm: m.PlatformMarginPerKm, Status: m.Status,
-		CreatedAt: m.CreatedAt, UpdatedAt: m.UpdatedAt,
-	}, nil
+	return toBookingEntity(&m), nil
 }
 
 func (r *OrderRepository) ListBookings() ([]*orderentity.Booking, error) {
@@ -132,16 +134,93 @@ func (r *OrderRepository) ListBookings() ([]*orderentity.Booking, error) {
 		return nil, err
 	}
 	res := make([]*orderentity.Booking, 0, len(ms))
-	for _, m := range ms {
-		res = append(res, &orderentity.Booking{
-			ID: m.ID, RequestID: m.RequestID, OfferID: m.OfferID, PassengerID: m.PassengerID, DriverID: m.DriverID,
-			PricePerKm: m.PricePerKm, PlatformMarginPerKm: m.PlatformMarginPerKm, Status: m.Status,
-			CreatedAt: m.CreatedAt, UpdatedAt: m.UpdatedAt,
-		})
+	for i := range ms {
+		res = append(res, toBookingEntity(&ms[i]))
 	}
 	return res, nil
 }
 
+func toBookingModel(b *orderentity.Booking) *Booking {
+	m := &Booking{
+		ID: b.ID, RequestID: b.RequestID, OfferID: b.OfferID, PassengerID: b.PassengerID, DriverID: b.DriverID, VehicleID: b.VehicleID, PaymentMethodID: b.PaymentMethodID,
+		PricePerKm: b.PricePerKm, FarePerKm: b.FarePerKm, PlatformMarginPerKm: b.PlatformMarginPerKm, Currency: b.Currency, AirportCode: b.AirportCode,
+		SurgeMultiplier: b.SurgeMultiplier, Status: b.Status, DistanceKm: b.DistanceKm, WaitingMinutes: b.WaitingMinutes, Tolls: b.Tolls,
+	}
+	if b.Pricing.Policy != "" {
+		// PricingTerms 只含数值与字符串字段，序列化不会失败
+		raw, _ := json.Marshal(b.Pricing)
+		m.PricingTerms = string(raw)
+	}
+	return m
+}
+
+func toBookingEntity(m *Booking) *orderentity.Booking {
+	b := &orderentity.Booking{
+		ID: m.ID, RequestID: m.RequestID, OfferID: m.OfferID, PassengerID: m.PassengerID, DriverID: m.DriverID, VehicleID: m.VehicleID, PaymentMethodID: m.PaymentMethodID,
+		PricePerKm: m.PricePerKm, FarePerKm: m.FarePerKm, PlatformMarginPerKm: m.PlatformMarginPerKm, Currency: m.Currency, AirportCode: m.AirportCode,
+		SurgeMultiplier: m.SurgeMultiplier, Status: m.Status, DistanceKm: m.DistanceKm, WaitingMinutes: m.WaitingMinutes, Tolls: m.Tolls,
+		CreatedAt: m.CreatedAt, UpdatedAt: m.UpdatedAt,
+	}
+	if m.PricingTerms != "" {
+		// 快照由本仓库写入；解析失败时保留空策略，结算按 FarePerKm 与 PlatformMarginPerKm 进行，不受影响
+		_ = json.Unmarshal([]byte(m.PricingTerms), &b.Pricing)
+	}
+	return b
+}
+
+// Quote
+func (r *OrderRepository) SaveQuote(q *orderentity.Quote) error {
+	percentiles, err := json.Marshal(q.Percentiles)
+	if err != nil {
+		return err
+	}
+	pricing, err := json.Marshal(q.Pricing)
+	if err != nil {
+		return err
+	}
+	return r.db.Save(&Quote{
+		ID: q.ID, PassengerID: q.PassengerID, AirportCode: q.AirportCode, VehicleType: q.VehicleType, DesiredTime: q.DesiredTime,
+		DistanceKm: q.DistanceKm, Currency: q.Currency, MaxPricePerKm: q.MaxPricePerKm, BestOfferPerKm: q.BestOfferPerKm,
+		Percentiles: string(percentiles), AvailableOffers: q.AvailableOffers, MatchProbability: q.MatchProbability,
+		SurgeMultiplier: q.SurgeMultiplier, FarePerKm: q.FarePerKm, PlatformMarginPerKm: q.PlatformMarginPerKm,
+		EstimatedFare: q.EstimatedFare, PricingTerms: string(pricing), CreatedAt: q.CreatedAt, ExpiresAt: q.ExpiresAt,
+	}).Error
+}
+
+func (r *OrderRepository) GetQuoteByID(id string) (*orderentity.Quote, error) {
+	var ms []Quote
+	if err := r.db.Where("id = ?", id).Limit(1).Find(&ms).Error; err != nil {
+		return nil, err
+	}
+	if len(ms) == 0 {
+		return nil, nil
+	}
+	m := ms[0]
+	q := &orderentity.Quote{
+		ID: m.ID, PassengerID: m.PassengerID, AirportCode: m.AirportCode, VehicleType: m.VehicleType, DesiredTime: m.DesiredTime,
+		DistanceKm: m.DistanceKm, Currency: m.Currency, MaxPricePerKm: m.MaxPricePerKm, BestOfferPerKm: m.BestOfferPerKm,
+		AvailableOffers: m.AvailableOffers, MatchProbability: m.MatchProbability, SurgeMultiplier: m.SurgeMultiplier,
+		FarePerKm: m.FarePerKm, PlatformMarginPerKm: m.PlatformMarginPerKm, EstimatedFare: m.EstimatedFare,
+		CreatedAt: m.CreatedAt, ExpiresAt: m.ExpiresAt,
+	}
+	if err := json.Unmarshal([]byte(m.Percentiles), &q.Percentiles); err != nil {
+		return nil, err
+	}
+	// 报价锁定的计价条款在成交时使用，解析失败不能静默退回默认策略
+	if err := json.Unmarshal([]byte(m.PricingTerms), &q.Pricing); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+func (r *OrderRepository) CountCompletedBookingsByDriver(driverID string, since time.Time) (int, error) {
+	var n int64
+	err := r.db.Model(&Booking{}).
+		Where("driver_id = ? AND status = ? AND updated_at >= ?", driverID, "completed", since).
+		Count(&n).Error
+	return int(n), err
+}
+
 func (r *OrderRepository) UpdateBooking(b *orderentity.Booking) error { return r.SaveBooking(b) }
 
 func (r *OrderRepository) HasOngoingPickupRequest(passengerID string) (bool, error) {
@@ -152,6 +231,38 @@ func (r *OrderRepository) HasOngoingPickupRequest(passengerID string) (bool, err
 	return cnt > 0, err
 }
 
+func (r *OrderRepository) HasOngoingPickupRequestWithPaymentMethod(paymentMethodID string) (bool, error) {
+	var cnt int64
+	err := r.db.Model(&PickupRequest{}).
+		Where("payment_method_id = ? AND status IN ?", paymentMethodID, []string{"open", "matched"}).
+		Count(&cnt).Error
+	return cnt > 0, err
+}
+
+func (r *OrderRepository) ClosePickupRequest(p *orderentity.PickupRequest) (bool, error) {
+	res := r.db.Model(&PickupRequest{}).Where("id = ? AND status = ?", p.ID, "open").
+		Updates(map[string]any{"status": p.Status, "updated_at": time.Now()})
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *OrderRepository) ListExpiredPickupRequests(desiredBefore time.Time, limit int) ([]*orderentity.PickupRequest, error) {
+	var ms []PickupRequest
+	if err := r.db.Where("status = ? AND desired_time < ?", "open", desiredBefore).
+		Order("desired_time, id").Limit(limit).Find(&ms).Error; err != nil {
+		return nil, err
+	}
+	res := make([]*orderentity.PickupRequest, 0, len(ms))
+	for _, m := range ms {
+		res = append(res, &orderentity.PickupRequest{
+			ID: m.ID, PassengerID: m.PassengerID, AirportCode: m.AirportCode, VehicleType: m.VehicleType,
+			DesiredTime: m.DesiredTime, MaxPricePerKm: m.MaxPricePerKm, Currency: m.Currency, PreferHighRating: m.PreferHighRating, PromoCode: m.PromoCode, QuoteID: m.QuoteID,
+			PassengerScore: m.PassengerScore, DepositCents: m.DepositCents, PaymentMethodID: m.PaymentMethodID, Status: m.Status,
+			CreatedAt: m.CreatedAt, UpdatedAt: m.UpdatedAt,
+		})
+	}
+	return res, nil
+}
+
 func (r *OrderRepository) HasOngoingDriverOffer(driverID string) (bool, error) {
 	var cnt int64
 	err := r.db.Model(&DriverOffer{}).
@@ -160,6 +271,30 @@ func (r *OrderRepository) HasOngoingDriverOffer(driverID string) (bool, error) {
 	return cnt > 0, err
 }
 
+func (r *OrderRepository) UpdateOpenRequestPassengerScores(passengerID string, score float64) error {
+	return r.db.Model(&PickupRequest{}).Where("passenger_id = ? AND status = ?", passengerID, "open").
+		Updates(map[string]any{"passenger_score": score, "updated_at": time.Now()}).Error
+}
+
+func (r *OrderRepository) UpdateOpenOfferRatings(driverID string, rating float64) error {
+	return r.db.Model(&DriverOffer{}).Where("driver_id = ? AND status = ?", driverID, "open").
+		Updates(map[string]any{"rating": rating, "updated_at": time.Now()}).Error
+}
+
+func (r *OrderRepository) CancelOpenDriverOffers(driverID string) error {
+	return r.db.Model(&DriverOffer{}).Where("driver_id = ? AND status = ?", driverID, "open").
+		Updates(map[string]any{"status": "cancelled", "updated_at": time.Now()}).Error
+}
+
+func (r *OrderRepository) ListIdleDriversAtAirport(airportCode, vehicleType string, since time.Time) ([]string, error) {
+	var ids []string
+	busy := r.db.Model(&DriverOffer{}).Select("driver_id").Where("status IN ?", []string{"open", "matched"})
+	err := r.db.Model(&DriverOffer{}).Distinct("driver_id").
+		Where("airport_code = ? AND vehicle_type = ? AND created_at >= ? AND driver_id NOT IN (?)", airportCode, vehicleType, since, busy).
+		Order("driver_id").Pluck("driver_id", &ids).Error
+	return ids, err
+}
+
 func (r *OrderRepository) UpdateAllInTransaction(b *orderentity.Booking, req *orderentity.PickupRequest, ofr *orderentity.DriverOffer) error {
 	return r.db.Transaction(func(tx *gorm.DB) error {
 		now := time.Now()
@@ -168,23 +303,33 @@ func (r *OrderRepository) UpdateAllInTransaction(b *orderentity.Booking, req *or
 			if createdAt.IsZero() {
 				createdAt = now
 			}
-			mB := &Booking{
-				ID: b.ID, RequestID: b.RequestID, OfferID: b.OfferID, PassengerID: b.PassengerID, DriverID: b.DriverID,
-				PricePerKm: b.PricePerKm, PlatformMarginPerKm: b.PlatformMarginPerKm, Status: b.Status, CreatedAt: createdAt,
-			}
+			mB := toBookingModel(b)
+			mB.CreatedAt = createdAt
 			mB.UpdatedAt = now
 			if err := tx.Save(mB).Error; err != nil {
 				return err
 			}
 		}
 		if req != nil {
+			if req.Status == "matched" {
+				// 撮合基于内存订单簿，请求可能已被乘客撤回或过期：条件更新锁定仍未关闭的请求行
+				res := tx.Model(&PickupRequest{}).Where("id = ? AND status IN ?", req.ID, []string{"open", "matched"}).
+					Update("updated_at", now)
+				if res.Error != nil {
+					return res.Error
+				}
+				if res.RowsAffected == 0 {
+					return order.ErrPickupRequestClosed
+				}
+			}
 			createdAt := req.CreatedAt
 			if createdAt.IsZero() {
 				createdAt = now
 			}
 			mReq := &PickupRequest{
 				ID: req.ID, PassengerID: req.PassengerID, AirportCode: req.AirportCode, VehicleType: req.VehicleType,
-				DesiredTime: req.DesiredTime, MaxPricePerKm: req.MaxPricePerKm, PreferHighRating: req.PreferHighRating,
+				DesiredTime: req.DesiredTime, MaxPricePerKm: req.MaxPricePerKm, Currency: req.Currency, PreferHighRating: req.PreferHighRating, PromoCode: req.PromoCode, QuoteID: req.QuoteID,
+				PassengerScore: req.PassengerScore, DepositCents: req.DepositCents, PaymentMethodID: req.PaymentMethodID,
 				Status: req.Status, CreatedAt: createdAt,
 			}
 			mReq.UpdatedAt = now
@@ -198,9 +343,9 @@ func (r *OrderRepository) UpdateAllInTransaction(b *orderentity.Booking, req *or
 				createdAt = now
 			}
 			mOfr := &DriverOffer{
-				ID: ofr.ID, DriverID: ofr.DriverID, AirportCode: ofr.AirportCode, VehicleType: ofr.VehicleType,
-				AvailableFrom: ofr.AvailableFrom, AvailableTo: ofr.AvailableTo, PricePerKm: ofr.PricePerKm,
-				Rating: ofr.Rating, Status: ofr.Status, CreatedAt: createdAt,
+				ID: ofr.ID, DriverID: ofr.DriverID, AirportCode: ofr.AirportCode, VehicleType: ofr.VehicleType, VehicleID: ofr.VehicleID,
+				AvailableFrom: ofr.AvailableFrom, AvailableTo: ofr.AvailableTo, PricePerKm: ofr.PricePerKm, Currency: ofr.Currency,
+				Rating: ofr.Rating, MinPassengerScore: ofr.MinPassengerScore, Status: ofr.Status, CreatedAt: createdAt,
 			}
 			mOfr.UpdatedAt = now
 			if err := tx.Save(mOfr).Error; err != nil {
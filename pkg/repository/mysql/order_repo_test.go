@@ -1,6 +1,8 @@
 package mysqlrepo
 
 import (
+	"github.com/gavin/airport-pickup/internal/domain/money"
+	order "github.com/gavin/airport-pickup/internal/domain/order"
 	orderentity "github.com/gavin/airport-pickup/internal/domain/order/entity"
 	"github.com/stretchr/testify/assert"
 	"gorm.io/driver/sqlite"
@@ -19,7 +21,8 @@ func TestSaveAndGetPickupRequest(t *testing.T) {
 	db := newTestDB()
 	repo := NewOrderRepository(db)
 	pr := &orderentity.PickupRequest{
-		ID: "r1", PassengerID: "p1", AirportCode: "PVG", VehicleType: "Sedan", DesiredTime: time.Now(), MaxPricePerKm: 10, PreferHighRating: true, Status: "open",
+		ID: "r1", PassengerID: "p1", AirportCode: "PVG", VehicleType: "Sedan", DesiredTime: time.Now(), MaxPricePerKm: money.MustParse("10"), PreferHighRating: true,
+		PaymentMethodID: "pm1", Status: "open",
 	}
 	err := repo.SavePickupRequest(pr)
 	assert.NoError(t, err)
@@ -28,6 +31,24 @@ func TestSaveAndGetPickupRequest(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "r1", got.ID)
 	assert.Equal(t, "p1", got.PassengerID)
+	assert.Equal(t, "pm1", got.PaymentMethodID)
+}
+
+func TestHasOngoingPickupRequestWithPaymentMethod(t *testing.T) {
+	db := newTestDB()
+	repo := NewOrderRepository(db)
+	for _, pr := range []*orderentity.PickupRequest{
+		{ID: "r1", PassengerID: "p1", PaymentMethodID: "pm1", Status: "completed"},
+		{ID: "r2", PassengerID: "p1", PaymentMethodID: "pm2", Status: "matched"},
+	} {
+		assert.NoError(t, repo.SavePickupRequest(pr))
+	}
+	ok, err := repo.HasOngoingPickupRequestWithPaymentMethod("pm1")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	ok, err = repo.HasOngoingPickupRequestWithPaymentMethod("pm2")
+	assert.NoError(t, err)
+	assert.True(t, ok)
 }
 
 func TestGetPickupRequestByID_NotFound(t *testing.T) {
@@ -40,10 +61,226 @@ func TestGetPickupRequestByID_NotFound(t *testing.T) {
 func TestListPickupRequests(t *testing.T) {
 	db := newTestDB()
 	repo := NewOrderRepository(db)
-	pr := &orderentity.PickupRequest{ID: "r2", PassengerID: "p2", AirportCode: "SHA", VehicleType: "SUV", DesiredTime: time.Now(), MaxPricePerKm: 20, PreferHighRating: false, Status: "open"}
+	pr := &orderentity.PickupRequest{ID: "r2", PassengerID: "p2", AirportCode: "SHA", VehicleType: "SUV", DesiredTime: time.Now(), MaxPricePerKm: money.MustParse("20"), PreferHighRating: false, Status: "open"}
 	repo.SavePickupRequest(pr)
 	list, err := repo.ListPickupRequests()
 	assert.NoError(t, err)
 	assert.Len(t, list, 1)
 	assert.Equal(t, "r2", list[0].ID)
 }
+
+func TestBookingMoneyRoundTrip(t *testing.T) {
+	db := newTestDB()
+	db.AutoMigrate(&Booking{})
+	repo := NewOrderRepository(db)
+	b := &orderentity.Booking{ID: "b1", RequestID: "r1", OfferID: "o1", PassengerID: "p1", DriverID: "d1",
+		PricePerKm: money.MustParse("2.3"), PlatformMarginPerKm: money.MustParse("0.15"), Status: "created"}
+	assert.NoError(t, repo.SaveBooking(b))
+	got, err := repo.GetBookingByID("b1")
+	assert.NoError(t, err)
+	// DECIMAL 列读回后金额精确，不会截断为 2.29
+	assert.Equal(t, int64(230), got.PricePerKm.Cents())
+	assert.Equal(t, int64(15), got.PlatformMarginPerKm.Cents())
+}
+
+func TestBookingPricingTermsRoundTrip(t *testing.T) {
+	db := newTestDB()
+	db.AutoMigrate(&Booking{})
+	repo := NewOrderRepository(db)
+	terms := orderentity.PricingTerms{
+		Policy: orderentity.PricingTieredCommission, CommissionRate: 0.15, VolumeWindowDays: 30, DriverTrips: 12,
+		Tiers: []orderentity.CommissionTier{{MinTrips: 0, Rate: 0.2}, {MinTrips: 10, Rate: 0.15}},
+	}
+	b := &orderentity.Booking{ID: "b1", RequestID: "r1", OfferID: "o1", PassengerID: "p1", DriverID: "d1", VehicleID: "v1", PaymentMethodID: "pm1",
+		PricePerKm: money.MustParse("8"), FarePerKm: money.MustParse("8"), PlatformMarginPerKm: money.MustParse("1.2"), Pricing: terms, SurgeMultiplier: 1.4, Status: "created"}
+	assert.NoError(t, repo.SaveBooking(b))
+	got, err := repo.GetBookingByID("b1")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(800), got.FarePerKm.Cents())
+	assert.Equal(t, terms, got.Pricing)
+	assert.Equal(t, 1.4, got.SurgeMultiplier)
+	assert.Equal(t, "v1", got.VehicleID)
+	assert.Equal(t, "pm1", got.PaymentMethodID)
+
+	// 计价策略上线前的订单没有快照，乘客价格按司机报价
+	legacy := &orderentity.Booking{ID: "b2", RequestID: "r2", OfferID: "o2", PassengerID: "p1", DriverID: "d1",
+		PricePerKm: money.MustParse("5"), Status: "created"}
+	assert.NoError(t, repo.SaveBooking(legacy))
+	got, err = repo.GetBookingByID("b2")
+	assert.NoError(t, err)
+	assert.Equal(t, orderentity.PricingTerms{}, got.Pricing)
+	assert.Equal(t, int64(500), got.PassengerPricePerKm().Cents())
+}
+
+func TestCountCompletedBookingsByDriver(t *testing.T) {
+	db := newTestDB()
+	db.AutoMigrate(&Booking{})
+	repo := NewOrderRepository(db)
+	for _, b := range []*orderentity.Booking{
+		{ID: "b1", DriverID: "d1", Status: "completed"},
+		{ID: "b2", DriverID: "d1", Status: "completed"},
+		{ID: "b3", DriverID: "d1", Status: "cancelled"},
+		{ID: "b4", DriverID: "d2", Status: "completed"},
+	} {
+		assert.NoError(t, repo.SaveBooking(b))
+	}
+	// 窗口之前完成的订单不计入
+	assert.NoError(t, db.Model(&Booking{}).Where("id = ?", "b2").Update("updated_at", time.Now().AddDate(0, 0, -40)).Error)
+
+	n, err := repo.CountCompletedBookingsByDriver("d1", time.Now().AddDate(0, 0, -30))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	n, err = repo.CountCompletedBookingsByDriver("d3", time.Now().AddDate(0, 0, -30))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+}
+
+func TestListIdleDriversAtAirport(t *testing.T) {
+	db := newTestDB()
+	db.AutoMigrate(&DriverOffer{})
+	repo := NewOrderRepository(db)
+	for _, o := range []*orderentity.DriverOffer{
+		{ID: "o1", DriverID: "d1", AirportCode: "PVG", VehicleType: "sedan", Status: "cancelled"},
+		{ID: "o2", DriverID: "d1", AirportCode: "PVG", VehicleType: "sedan", Status: "cancelled"},
+		{ID: "o3", DriverID: "d2", AirportCode: "PVG", VehicleType: "sedan", Status: "cancelled"},
+		{ID: "o4", DriverID: "d2", AirportCode: "SHA", VehicleType: "sedan", Status: "open"}, // 在其他机场接单中
+		{ID: "o5", DriverID: "d3", AirportCode: "PVG", VehicleType: "van", Status: "cancelled"},
+		{ID: "o6", DriverID: "d4", AirportCode: "PVG", VehicleType: "sedan", Status: "cancelled"},
+	} {
+		assert.NoError(t, repo.SaveDriverOffer(o))
+	}
+	// 窗口之前的报价不计入
+	assert.NoError(t, db.Model(&DriverOffer{}).Where("id = ?", "o6").Update("created_at", time.Now().AddDate(0, 0, -2)).Error)
+
+	ids, err := repo.ListIdleDriversAtAirport("PVG", "sedan", time.Now().Add(-24*time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"d1"}, ids)
+}
+
+func TestQuoteRoundTrip(t *testing.T) {
+	db := newTestDB()
+	db.AutoMigrate(&Quote{})
+	repo := NewOrderRepository(db)
+	now := time.Now().UTC().Truncate(time.Second)
+	q := &orderentity.Quote{
+		ID: "q1", PassengerID: "p1", AirportCode: "PVG", VehicleType: "sedan", DesiredTime: now.Add(time.Hour), DistanceKm: 35,
+		Currency: "CNY", MaxPricePerKm: money.MustParse("8.4"), BestOfferPerKm: money.MustParse("7"),
+		Percentiles:     orderentity.PricePercentiles{P10: money.MustParse("7"), P25: money.MustParse("7"), P50: money.MustParse("8"), P75: money.MustParse("9"), P90: money.MustParse("9.5")},
+		AvailableOffers: 5, MatchProbability: 0.6, SurgeMultiplier: 1.2,
+		FarePerKm: money.MustParse("8.4"), PlatformMarginPerKm: money.MustParse("1.4"), EstimatedFare: money.MustParse("314"),
+		Pricing:   orderentity.PricingTerms{Policy: orderentity.PricingOfferPlusFee, FeePerKm: money.MustParse("0.5"), FeeRate: 0.05},
+		CreatedAt: now, ExpiresAt: now.Add(5 * time.Minute),
+	}
+	assert.NoError(t, repo.SaveQuote(q))
+	got, err := repo.GetQuoteByID("q1")
+	assert.NoError(t, err)
+	if assert.NotNil(t, got) {
+		assert.Equal(t, q.Percentiles, got.Percentiles)
+		assert.Equal(t, q.Pricing, got.Pricing)
+		assert.Equal(t, int64(31400), got.EstimatedFare.Cents())
+		assert.True(t, got.ExpiresAt.Equal(q.ExpiresAt))
+		assert.False(t, got.Expired(now))
+		assert.True(t, got.Expired(q.ExpiresAt))
+	}
+
+	got, err = repo.GetQuoteByID("missing")
+	assert.NoError(t, err)
+	assert.Nil(t, got)
+}
+
+func TestUpdateOpenOfferRatings(t *testing.T) {
+	db := newTestDB()
+	db.AutoMigrate(&DriverOffer{})
+	repo := NewOrderRepository(db)
+	for _, o := range []*orderentity.DriverOffer{
+		{ID: "o1", DriverID: "d1", Rating: 5, Status: "open"},
+		{ID: "o2", DriverID: "d1", Rating: 5, Status: "completed"},
+		{ID: "o3", DriverID: "d2", Rating: 5, Status: "open"},
+	} {
+		assert.NoError(t, repo.SaveDriverOffer(o))
+	}
+	assert.NoError(t, repo.UpdateOpenOfferRatings("d1", 4.2))
+	for id, want := range map[string]float64{"o1": 4.2, "o2": 5, "o3": 5} {
+		o, err := repo.GetDriverOfferByID(id)
+		assert.NoError(t, err)
+		assert.Equal(t, want, o.Rating, id)
+	}
+}
+
+func TestUpdateOpenRequestPassengerScores(t *testing.T) {
+	db := newTestDB()
+	db.AutoMigrate(&PickupRequest{})
+	repo := NewOrderRepository(db)
+	for _, r := range []*orderentity.PickupRequest{
+		{ID: "r1", PassengerID: "p1", PassengerScore: 4.5, DepositCents: 5000, Status: "open"},
+		{ID: "r2", PassengerID: "p1", PassengerScore: 4.5, Status: "completed"},
+		{ID: "r3", PassengerID: "p2", PassengerScore: 4.5, Status: "open"},
+	} {
+		assert.NoError(t, repo.SavePickupRequest(r))
+	}
+	assert.NoError(t, repo.UpdateOpenRequestPassengerScores("p1", 3.1))
+	for id, want := range map[string]float64{"r1": 3.1, "r2": 4.5, "r3": 4.5} {
+		r, err := repo.GetPickupRequestByID(id)
+		assert.NoError(t, err)
+		assert.Equal(t, want, r.PassengerScore, id)
+	}
+	r, err := repo.GetPickupRequestByID("r1")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5000), r.DepositCents)
+}
+
+func TestCancelOpenDriverOffers(t *testing.T) {
+	db := newTestDB()
+	db.AutoMigrate(&DriverOffer{})
+	repo := NewOrderRepository(db)
+	for _, o := range []*orderentity.DriverOffer{
+		{ID: "o1", DriverID: "d1", Status: "open"},
+		{ID: "o2", DriverID: "d1", Status: "matched"},
+		{ID: "o3", DriverID: "d2", Status: "open"},
+	} {
+		assert.NoError(t, repo.SaveDriverOffer(o))
+	}
+	assert.NoError(t, repo.CancelOpenDriverOffers("d1"))
+	for id, want := range map[string]string{"o1": "cancelled", "o2": "matched", "o3": "open"} {
+		o, err := repo.GetDriverOfferByID(id)
+		assert.NoError(t, err)
+		assert.Equal(t, want, o.Status, id)
+	}
+}
+
+func TestClosePickupRequest(t *testing.T) {
+	db := newTestDB()
+	db.AutoMigrate(&DriverOffer{}, &Booking{})
+	repo := NewOrderRepository(db)
+	now := time.Now()
+	for _, pr := range []*orderentity.PickupRequest{
+		{ID: "r1", PassengerID: "p1", DesiredTime: now.Add(-2 * time.Hour), Status: "open"},
+		{ID: "r2", PassengerID: "p2", DesiredTime: now.Add(-3 * time.Hour), Status: "matched"},
+		{ID: "r3", PassengerID: "p3", DesiredTime: now.Add(time.Hour), Status: "open"},
+	} {
+		assert.NoError(t, repo.SavePickupRequest(pr))
+	}
+	expired, err := repo.ListExpiredPickupRequests(now.Add(-time.Hour), 10)
+	assert.NoError(t, err)
+	assert.Len(t, expired, 1)
+	assert.Equal(t, "r1", expired[0].ID)
+
+	ok, err := repo.ClosePickupRequest(&orderentity.PickupRequest{ID: "r1", Status: "expired"})
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	// 已撮合的请求不能关闭
+	ok, err = repo.ClosePickupRequest(&orderentity.PickupRequest{ID: "r2", Status: "cancelled"})
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	got, _ := repo.GetPickupRequestByID("r2")
+	assert.Equal(t, "matched", got.Status)
+
+	// 已关闭的请求不能再被撮合
+	err = repo.UpdateAllInTransaction(&orderentity.Booking{ID: "b1", RequestID: "r1", Status: "created"},
+		&orderentity.PickupRequest{ID: "r1", Status: "matched"}, nil)
+	assert.ErrorIs(t, err, order.ErrPickupRequestClosed)
+	got, _ = repo.GetPickupRequestByID("r1")
+	assert.Equal(t, "expired", got.Status)
+	assert.NoError(t, repo.UpdateAllInTransaction(&orderentity.Booking{ID: "b3", RequestID: "r3", Status: "created"},
+		&orderentity.PickupRequest{ID: "r3", Status: "matched"}, nil))
+}
@@ -0,0 +1,50 @@
+package mysqlrepo
+
+import (
+	user "github.com/gavin/airport-pickup/internal/domain/user"
+	userentity "github.com/gavin/airport-pickup/internal/domain/user/entity"
+	"gorm.io/gorm"
+)
+
+type PaymentMethodRepository struct{ db *gorm.DB }
+
+func NewPaymentMethodRepository(db *gorm.DB) user.PaymentMethodRepository {
+	return &PaymentMethodRepository{db: db}
+}
+
+func (r *PaymentMethodRepository) SavePaymentMethod(m *userentity.PaymentMethod) error {
+	return r.db.Save(&PaymentMethod{
+		ID: m.ID, PassengerID: m.PassengerID, Token: m.Token, Brand: m.Brand, Last4: m.Last4,
+		ExpMonth: m.ExpMonth, ExpYear: m.ExpYear, Status: m.Status, CreatedAt: m.CreatedAt, RemovedAt: m.RemovedAt,
+	}).Error
+}
+
+func (r *PaymentMethodRepository) GetPaymentMethod(id string) (*userentity.PaymentMethod, error) {
+	var ms []PaymentMethod
+	if err := r.db.Where("id = ?", id).Limit(1).Find(&ms).Error; err != nil {
+		return nil, err
+	}
+	if len(ms) == 0 {
+		return nil, nil
+	}
+	return toPaymentMethodEntity(&ms[0]), nil
+}
+
+func (r *PaymentMethodRepository) ListPaymentMethods(passengerID string) ([]*userentity.PaymentMethod, error) {
+	var ms []PaymentMethod
+	if err := r.db.Where("passenger_id = ?", passengerID).Order("created_at, id").Find(&ms).Error; err != nil {
+		return nil, err
+	}
+	res := make([]*userentity.PaymentMethod, 0, len(ms))
+	for i := range ms {
+		res = append(res, toPaymentMethodEntity(&ms[i]))
+	}
+	return res, nil
+}
+
+func toPaymentMethodEntity(m *PaymentMethod) *userentity.PaymentMethod {
+	return &userentity.PaymentMethod{
+		ID: m.ID, PassengerID: m.PassengerID, Token: m.Token, Brand: m.Brand, Last4: m.Last4,
+		ExpMonth: m.ExpMonth, ExpYear: m.ExpYear, Status: m.Status, CreatedAt: m.CreatedAt, RemovedAt: m.RemovedAt,
+	}
+}
@@ -0,0 +1,58 @@
+package mysqlrepo
+
+import (
+	"testing"
+	"time"
+
+	userentity "github.com/gavin/airport-pickup/internal/domain/user/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func newTestDBPaymentMethods(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&PaymentMethod{}))
+	return db
+}
+
+func TestPaymentMethodRepository_SaveAndQuery(t *testing.T) {
+	repo := NewPaymentMethodRepository(newTestDBPaymentMethods(t))
+	base := time.Date(2025, 11, 8, 10, 0, 0, 0, time.UTC)
+	methods := []*userentity.PaymentMethod{
+		{ID: "pm1", PassengerID: "p1", Token: "tok_1", Brand: "visa", Last4: "4242", ExpMonth: 12, ExpYear: 2030,
+			Status: userentity.PaymentMethodActive, CreatedAt: base},
+		{ID: "pm2", PassengerID: "p1", Token: "tok_2", Brand: "mastercard", Last4: "4444", ExpMonth: 1, ExpYear: 2029,
+			Status: userentity.PaymentMethodActive, CreatedAt: base.Add(-time.Hour)},
+		{ID: "pm3", PassengerID: "p2", Token: "tok_3", Brand: "amex", Last4: "0005", ExpMonth: 6, ExpYear: 2028,
+			Status: userentity.PaymentMethodActive, CreatedAt: base},
+	}
+	for _, m := range methods {
+		require.NoError(t, repo.SavePaymentMethod(m))
+	}
+
+	removed := base.Add(time.Hour)
+	require.NoError(t, methods[1].Remove(removed))
+	require.NoError(t, repo.SavePaymentMethod(methods[1]))
+
+	got, err := repo.GetPaymentMethod("pm2")
+	require.NoError(t, err)
+	assert.Equal(t, "tok_2", got.Token)
+	assert.Equal(t, "4444", got.Last4)
+	assert.Equal(t, 2029, got.ExpYear)
+	assert.Equal(t, userentity.PaymentMethodRemoved, got.Status)
+	require.NotNil(t, got.RemovedAt)
+	assert.True(t, got.RemovedAt.Equal(removed))
+
+	missing, err := repo.GetPaymentMethod("nope")
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+
+	list, err := repo.ListPaymentMethods("p1")
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, "pm2", list[0].ID)
+	assert.Equal(t, "pm1", list[1].ID)
+}
@@ -0,0 +1,192 @@
+package mysqlrepo
+
+import (
+	"time"
+
+	settlement "github.com/gavin/airport-pickup/internal/domain/settlement"
+	settlemententity "github.com/gavin/airport-pickup/internal/domain/settlement/entity"
+	"gorm.io/gorm"
+)
+
+type PayoutRepository struct{ db *gorm.DB }
+
+func NewPayoutRepository(db *gorm.DB) settlement.PayoutRepository {
+	return &PayoutRepository{db: db}
+}
+
+func (r *PayoutRepository) ListUnpaidSettlementRecords(before time.Time) ([]*settlemententity.SettlementRecord, error) {
+	var ms []SettlementRecord
+	if err := r.db.Where("payout_id = '' AND created_at < ?", before).Order("driver_id, currency, created_at, id").Find(&ms).Error; err != nil {
+		return nil, err
+	}
+	res := make([]*settlemententity.SettlementRecord, 0, len(ms))
+	for i := range ms {
+		res = append(res, toSettlementRecordEntity(&ms[i]))
+	}
+	return res, nil
+}
+
+func (r *PayoutRepository) CreatePayout(p *settlemententity.Payout) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if p.CreatedAt.IsZero() {
+			p.CreatedAt = now
+		}
+		p.UpdatedAt = now
+		if err := tx.Create(toPayoutModel(p)).Error; err != nil {
+			return err
+		}
+		if len(p.Lines) == 0 {
+			return nil
+		}
+		lines := make([]PayoutLine, 0, len(p.Lines))
+		ids := make([]string, 0, len(p.Lines))
+		for _, l := range p.Lines {
+			lines = append(lines, PayoutLine{
+				PayoutID: p.ID, SettlementRecordID: l.SettlementRecordID, BookingID: l.BookingID,
+				AmountCents: l.AmountCents, CommissionCents: l.CommissionCents, NetCents: l.NetCents, CreatedAt: p.CreatedAt,
+			})
+			ids = append(ids, l.SettlementRecordID)
+		}
+		if err := tx.Create(&lines).Error; err != nil {
+			return err
+		}
+		// 仅占用仍未打款的记录；数量不符说明已被并发的打款任务占用
+		res := tx.Model(&SettlementRecord{}).Where("id IN ? AND payout_id = ''", ids).
+			Updates(map[string]any{"payout_id": p.ID, "updated_at": now})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected != int64(len(ids)) {
+			return settlement.ErrSettlementRecordClaimed
+		}
+		return nil
+	})
+}
+
+func (r *PayoutRepository) CompletePayout(p *settlemententity.Payout, entries []*settlemententity.JournalEntry) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		p.UpdatedAt = time.Now()
+		res := tx.Model(&Payout{}).Where("id = ? AND status = ?", p.ID, settlemententity.PayoutPending).Updates(map[string]any{
+			"status":       p.Status,
+			"provider_ref": truncate(p.ProviderRef, 128),
+			"paid_at":      p.PaidAt,
+			"updated_at":   p.UpdatedAt,
+		})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return settlement.ErrPayoutNotPending
+		}
+		return saveJournalEntries(tx, entries)
+	})
+}
+
+func (r *PayoutRepository) FailPayout(p *settlemententity.Payout) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		p.UpdatedAt = time.Now()
+		res := tx.Model(&Payout{}).Where("id = ? AND status = ?", p.ID, settlemententity.PayoutPending).Updates(map[string]any{
+			"status":         p.Status,
+			"failure_reason": truncate(p.FailureReason, 255),
+			"failed_at":      p.FailedAt,
+			"updated_at":     p.UpdatedAt,
+		})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return settlement.ErrPayoutNotPending
+		}
+		return tx.Model(&SettlementRecord{}).Where("payout_id = ?", p.ID).
+			Updates(map[string]any{"payout_id": "", "updated_at": p.UpdatedAt}).Error
+	})
+}
+
+func (r *PayoutRepository) GetPayout(id string) (*settlemententity.Payout, error) {
+	var ms []Payout
+	if err := r.db.Where("id = ?", id).Limit(1).Find(&ms).Error; err != nil {
+		return nil, err
+	}
+	if len(ms) == 0 {
+		return nil, nil
+	}
+	res, err := r.withLines(ms)
+	if err != nil {
+		return nil, err
+	}
+	return res[0], nil
+}
+
+func (r *PayoutRepository) ListPayouts(driverID string, limit int) ([]*settlemententity.Payout, error) {
+	var ms []Payout
+	q := r.db.Order("created_at DESC")
+	if driverID != "" {
+		q = q.Where("driver_id = ?", driverID)
+	}
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	if err := q.Find(&ms).Error; err != nil {
+		return nil, err
+	}
+	res := make([]*settlemententity.Payout, 0, len(ms))
+	for i := range ms {
+		res = append(res, toPayoutEntity(&ms[i]))
+	}
+	return res, nil
+}
+
+func (r *PayoutRepository) ListPendingPayouts(before time.Time) ([]*settlemententity.Payout, error) {
+	var ms []Payout
+	if err := r.db.Where("status = ? AND created_at < ?", settlemententity.PayoutPending, before).Order("created_at").Find(&ms).Error; err != nil {
+		return nil, err
+	}
+	return r.withLines(ms)
+}
+
+func (r *PayoutRepository) withLines(ms []Payout) ([]*settlemententity.Payout, error) {
+	if len(ms) == 0 {
+		return nil, nil
+	}
+	ids := make([]string, 0, len(ms))
+	for _, m := range ms {
+		ids = append(ids, m.ID)
+	}
+	var lines []PayoutLine
+	if err := r.db.Where("payout_id IN ?", ids).Order("id").Find(&lines).Error; err != nil {
+		return nil, err
+	}
+	byPayout := make(map[string][]settlemententity.PayoutLine, len(ms))
+	for _, l := range lines {
+		byPayout[l.PayoutID] = append(byPayout[l.PayoutID], settlemententity.PayoutLine{
+			SettlementRecordID: l.SettlementRecordID, BookingID: l.BookingID,
+			AmountCents: l.AmountCents, CommissionCents: l.CommissionCents, NetCents: l.NetCents,
+		})
+	}
+	res := make([]*settlemententity.Payout, 0, len(ms))
+	for i := range ms {
+		p := toPayoutEntity(&ms[i])
+		p.Lines = byPayout[p.ID]
+		res = append(res, p)
+	}
+	return res, nil
+}
+
+func toPayoutModel(p *settlemententity.Payout) *Payout {
+	return &Payout{
+		ID: p.ID, DriverID: p.DriverID, Status: p.Status,
+		GrossCents: p.GrossCents, CommissionCents: p.CommissionCents, FeeCents: p.FeeCents, NetCents: p.NetCents, Currency: p.Currency,
+		ProviderRef: truncate(p.ProviderRef, 128), FailureReason: truncate(p.FailureReason, 255),
+		PaidAt: p.PaidAt, FailedAt: p.FailedAt, CreatedAt: p.CreatedAt, UpdatedAt: p.UpdatedAt,
+	}
+}
+
+func toPayoutEntity(m *Payout) *settlemententity.Payout {
+	return &settlemententity.Payout{
+		ID: m.ID, DriverID: m.DriverID, Status: m.Status,
+		GrossCents: m.GrossCents, CommissionCents: m.CommissionCents, FeeCents: m.FeeCents, NetCents: m.NetCents, Currency: m.Currency,
+		ProviderRef: m.ProviderRef, FailureReason: m.FailureReason,
+		PaidAt: m.PaidAt, FailedAt: m.FailedAt, CreatedAt: m.CreatedAt, UpdatedAt: m.UpdatedAt,
+	}
+}
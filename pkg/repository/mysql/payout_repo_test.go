@@ -0,0 +1,124 @@
+package mysqlrepo
+
+import (
+	"testing"
+	"time"
+
+	settlement "github.com/gavin/airport-pickup/internal/domain/settlement"
+	settlemententity "github.com/gavin/airport-pickup/internal/domain/settlement/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func newTestDBPayout() *gorm.DB {
+	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	db.AutoMigrate(&SettlementRecord{}, &SettlementFareItem{}, &JournalEntry{}, &JournalLine{}, &Payout{}, &PayoutLine{})
+	return db
+}
+
+func seedSettlementRecords(t *testing.T, db *gorm.DB) {
+	repo := NewSettlementRepository(db)
+	for _, sr := range []*settlemententity.SettlementRecord{
+		{ID: "sr1", BookingID: "b1", DriverID: "d1", PassengerID: "p1", AmountCents: 3000, PlatformRevenueCents: 500, Currency: "CNY"},
+		{ID: "sr2", BookingID: "b2", DriverID: "d1", PassengerID: "p1", AmountCents: 2000, PlatformRevenueCents: 300, Currency: "CNY"},
+		{ID: "sr3", BookingID: "b3", DriverID: "d2", PassengerID: "p2", AmountCents: 1000, PlatformRevenueCents: 100, Currency: "CNY"},
+	} {
+		require.NoError(t, repo.SaveSettlementRecord(sr))
+	}
+}
+
+func newTestPayout(id string, recordIDs ...string) *settlemententity.Payout {
+	p := &settlemententity.Payout{ID: id, DriverID: "d1", Status: settlemententity.PayoutPending, GrossCents: 5000, CommissionCents: 800, NetCents: 4200, Currency: "CNY"}
+	for _, rid := range recordIDs {
+		p.Lines = append(p.Lines, settlemententity.PayoutLine{SettlementRecordID: rid, BookingID: "b-" + rid})
+	}
+	return p
+}
+
+func TestPayoutRepository_CreateAndComplete(t *testing.T) {
+	db := newTestDBPayout()
+	seedSettlementRecords(t, db)
+	repo := NewPayoutRepository(db)
+
+	unpaid, err := repo.ListUnpaidSettlementRecords(time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.Len(t, unpaid, 3)
+	assert.Equal(t, "d1", unpaid[0].DriverID)
+
+	p := newTestPayout("po1", "sr1", "sr2")
+	require.NoError(t, repo.CreatePayout(p))
+	unpaid, _ = repo.ListUnpaidSettlementRecords(time.Now().Add(time.Minute))
+	assert.Len(t, unpaid, 1)
+	sr, _ := NewSettlementRepository(db).GetSettlementRecordByID("sr1")
+	assert.Equal(t, "po1", sr.PayoutID)
+
+	pending, err := repo.ListPendingPayouts(time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Len(t, pending[0].Lines, 2)
+
+	require.NoError(t, p.MarkPaid("ref-1", time.Now()))
+	entry, err := settlemententity.NewPayoutJournalEntry("j1", "po1", settlemententity.JournalPayout,
+		settlemententity.Debit(settlemententity.DriverPayableAccount("d1"), 4200),
+		settlemententity.Credit(settlemententity.AccountPaymentClearing, 4200))
+	require.NoError(t, err)
+	entry.Currency = p.Currency
+	require.NoError(t, repo.CompletePayout(p, []*settlemententity.JournalEntry{entry}))
+
+	// 重复完成不会重复记账
+	assert.ErrorIs(t, repo.CompletePayout(p, []*settlemententity.JournalEntry{entry}), settlement.ErrPayoutNotPending)
+
+	got, err := repo.GetPayout("po1")
+	require.NoError(t, err)
+	assert.Equal(t, settlemententity.PayoutPaid, got.Status)
+	assert.Equal(t, "ref-1", got.ProviderRef)
+	assert.Len(t, got.Lines, 2)
+	pending, _ = repo.ListPendingPayouts(time.Now().Add(time.Minute))
+	assert.Empty(t, pending)
+
+	bal, err := NewSettlementRepository(db).GetAccountBalances(settlemententity.DriverPayableAccount("d1"))
+	require.NoError(t, err)
+	require.Len(t, bal, 1)
+	assert.Equal(t, "CNY", bal[0].Currency)
+	assert.Equal(t, int64(4200), bal[0].DebitCents)
+
+	list, err := repo.ListPayouts("d1", 10)
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+	none, _ := repo.GetPayout("missing")
+	assert.Nil(t, none)
+}
+
+func TestPayoutRepository_RecordClaimedOnce(t *testing.T) {
+	db := newTestDBPayout()
+	seedSettlementRecords(t, db)
+	repo := NewPayoutRepository(db)
+
+	require.NoError(t, repo.CreatePayout(newTestPayout("po1", "sr1")))
+	err := repo.CreatePayout(newTestPayout("po2", "sr1", "sr2"))
+	assert.ErrorIs(t, err, settlement.ErrSettlementRecordClaimed)
+	// 事务回滚：po2 未写入，sr2 仍未打款
+	got, _ := repo.GetPayout("po2")
+	assert.Nil(t, got)
+	sr, _ := NewSettlementRepository(db).GetSettlementRecordByID("sr2")
+	assert.Equal(t, "", sr.PayoutID)
+}
+
+func TestPayoutRepository_FailReleasesRecords(t *testing.T) {
+	db := newTestDBPayout()
+	seedSettlementRecords(t, db)
+	repo := NewPayoutRepository(db)
+
+	p := newTestPayout("po1", "sr1", "sr2")
+	require.NoError(t, repo.CreatePayout(p))
+	require.NoError(t, p.MarkFailed("account closed", time.Now()))
+	require.NoError(t, repo.FailPayout(p))
+
+	got, _ := repo.GetPayout("po1")
+	assert.Equal(t, settlemententity.PayoutFailed, got.Status)
+	assert.Equal(t, "account closed", got.FailureReason)
+	unpaid, _ := repo.ListUnpaidSettlementRecords(time.Now().Add(time.Minute))
+	assert.Len(t, unpaid, 3)
+}
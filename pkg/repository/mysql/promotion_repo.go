@@ -0,0 +1,185 @@
+package mysqlrepo
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	promotion "github.com/gavin/airport-pickup/internal/domain/promotion"
+	promotionentity "github.com/gavin/airport-pickup/internal/domain/promotion/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PromotionRepository struct{ db *gorm.DB }
+
+func NewPromotionRepository(db *gorm.DB) promotion.PromotionRepository {
+	return &PromotionRepository{db: db}
+}
+
+func (r *PromotionRepository) CreatePromotion(p *promotionentity.Promotion) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var n int64
+		if err := tx.Model(&Promotion{}).Where("code = ?", p.Code).Count(&n).Error; err != nil {
+			return err
+		}
+		if n > 0 {
+			return promotion.ErrPromotionExists
+		}
+		now := time.Now()
+		p.CreatedAt, p.UpdatedAt = now, now
+		return tx.Create(&Promotion{
+			Code: p.Code, Description: p.Description, Kind: p.Kind, Rate: p.Rate, AmountCents: p.AmountCents,
+			MaxDiscountCents: p.MaxDiscountCents, Currency: p.Currency, AirportCodes: strings.Join(p.AirportCodes, ","),
+			StartsAt: p.StartsAt, EndsAt: p.EndsAt, PerPassengerLimit: p.PerPassengerLimit, TotalLimit: p.TotalLimit,
+			BudgetCents: p.BudgetCents, RedeemedCount: p.RedeemedCount, SpentCents: p.SpentCents,
+			CreatedAt: now, UpdatedAt: now,
+		}).Error
+	})
+}
+
+func (r *PromotionRepository) GetPromotion(code string) (*promotionentity.Promotion, error) {
+	return getPromotion(r.db, code)
+}
+
+func (r *PromotionRepository) ListPromotions() ([]*promotionentity.Promotion, error) {
+	var ms []Promotion
+	if err := r.db.Order("created_at DESC, code").Find(&ms).Error; err != nil {
+		return nil, err
+	}
+	res := make([]*promotionentity.Promotion, 0, len(ms))
+	for i := range ms {
+		res = append(res, toPromotionEntity(&ms[i]))
+	}
+	return res, nil
+}
+
+func (r *PromotionRepository) CountPassengerRedemptions(code, passengerID string) (int, error) {
+	return countPassengerRedemptions(r.db, code, passengerID)
+}
+
+func (r *PromotionRepository) GetRedemptionByBooking(bookingID string) (*promotionentity.Redemption, error) {
+	var ms []PromoRedemption
+	if err := r.db.Where("booking_id = ?", bookingID).Limit(1).Find(&ms).Error; err != nil {
+		return nil, err
+	}
+	if len(ms) == 0 {
+		return nil, nil
+	}
+	m := ms[0]
+	return &promotionentity.Redemption{
+		ID: m.ID, Code: m.Code, BookingID: m.BookingID, PassengerID: m.PassengerID, AmountCents: m.AmountCents,
+		Currency: m.Currency, Status: m.Status, CreatedAt: m.CreatedAt, ReleasedAt: m.ReleasedAt,
+	}, nil
+}
+
+func (r *PromotionRepository) Redeem(red *promotionentity.Redemption) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var n int64
+		if err := tx.Model(&PromoRedemption{}).Where("booking_id = ?", red.BookingID).Count(&n).Error; err != nil {
+			return err
+		}
+		if n > 0 {
+			return promotion.ErrRedemptionExists
+		}
+		// 条件更新同时锁定活动行，并发核销在此串行，次数与预算不会超出上限
+		res := tx.Model(&Promotion{}).
+			Where("code = ? AND (total_limit = 0 OR redeemed_count < total_limit) AND (budget_cents = 0 OR spent_cents + ? <= budget_cents)", red.Code, red.AmountCents).
+			Updates(map[string]any{
+				"redeemed_count": gorm.Expr("redeemed_count + 1"),
+				"spent_cents":    gorm.Expr("spent_cents + ?", red.AmountCents),
+				"updated_at":     time.Now(),
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		p, err := getPromotion(tx, red.Code)
+		if err != nil {
+			return err
+		}
+		if p == nil {
+			return errors.New("promotion not found")
+		}
+		if res.RowsAffected == 0 {
+			if p.TotalLimit > 0 && p.RedeemedCount >= p.TotalLimit {
+				return promotionentity.ErrPromotionLimitReached
+			}
+			return promotionentity.ErrPromotionBudgetExceeded
+		}
+		if p.PerPassengerLimit > 0 {
+			// 同一活动的核销已在活动行上串行；计数须用加锁读取读到已提交的最新核销，
+			// 快照读仍停留在事务开始时，并发核销会同时通过每位乘客的次数上限
+			used, err := countPassengerRedemptions(tx.Clauses(clause.Locking{Strength: "UPDATE"}), red.Code, red.PassengerID)
+			if err != nil {
+				return err
+			}
+			if used >= p.PerPassengerLimit {
+				return promotionentity.ErrPassengerLimitReached
+			}
+		}
+		if red.CreatedAt.IsZero() {
+			red.CreatedAt = time.Now()
+		}
+		return tx.Create(&PromoRedemption{
+			ID: red.ID, Code: red.Code, BookingID: red.BookingID, PassengerID: red.PassengerID,
+			AmountCents: red.AmountCents, Currency: red.Currency, Status: red.Status, CreatedAt: red.CreatedAt,
+		}).Error
+	})
+}
+
+func (r *PromotionRepository) ReleaseRedemption(bookingID string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var ms []PromoRedemption
+		if err := tx.Where("booking_id = ? AND status = ?", bookingID, promotionentity.RedemptionRedeemed).Limit(1).Find(&ms).Error; err != nil {
+			return err
+		}
+		if len(ms) == 0 {
+			return nil
+		}
+		m := ms[0]
+		now := time.Now()
+		// 按原状态条件更新，并发撤销时只有一次退回预算
+		res := tx.Model(&PromoRedemption{}).Where("id = ? AND status = ?", m.ID, promotionentity.RedemptionRedeemed).
+			Updates(map[string]any{"status": promotionentity.RedemptionReleased, "released_at": now})
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		return tx.Model(&Promotion{}).Where("code = ?", m.Code).Updates(map[string]any{
+			"redeemed_count": gorm.Expr("redeemed_count - 1"),
+			"spent_cents":    gorm.Expr("spent_cents - ?", m.AmountCents),
+			"updated_at":     now,
+		}).Error
+	})
+}
+
+func getPromotion(db *gorm.DB, code string) (*promotionentity.Promotion, error) {
+	var ms []Promotion
+	if err := db.Where("code = ?", code).Limit(1).Find(&ms).Error; err != nil {
+		return nil, err
+	}
+	if len(ms) == 0 {
+		return nil, nil
+	}
+	return toPromotionEntity(&ms[0]), nil
+}
+
+func countPassengerRedemptions(db *gorm.DB, code, passengerID string) (int, error) {
+	var n int64
+	err := db.Model(&PromoRedemption{}).
+		Where("code = ? AND passenger_id = ? AND status = ?", code, passengerID, promotionentity.RedemptionRedeemed).
+		Count(&n).Error
+	return int(n), err
+}
+
+func toPromotionEntity(m *Promotion) *promotionentity.Promotion {
+	p := &promotionentity.Promotion{
+		Code: m.Code, Description: m.Description, Kind: m.Kind, Rate: m.Rate, AmountCents: m.AmountCents,
+		MaxDiscountCents: m.MaxDiscountCents, Currency: m.Currency, StartsAt: m.StartsAt, EndsAt: m.EndsAt,
+		PerPassengerLimit: m.PerPassengerLimit, TotalLimit: m.TotalLimit, BudgetCents: m.BudgetCents,
+		RedeemedCount: m.RedeemedCount, SpentCents: m.SpentCents, CreatedAt: m.CreatedAt, UpdatedAt: m.UpdatedAt,
+	}
+	if m.AirportCodes != "" {
+		p.AirportCodes = strings.Split(m.AirportCodes, ",")
+	}
+	return p
+}
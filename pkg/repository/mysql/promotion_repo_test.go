@@ -0,0 +1,90 @@
+package mysqlrepo
+
+import (
+	"testing"
+	"time"
+
+	promotion "github.com/gavin/airport-pickup/internal/domain/promotion"
+	promotionentity "github.com/gavin/airport-pickup/internal/domain/promotion/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func newTestDBPromotion() *gorm.DB {
+	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	db.AutoMigrate(&Promotion{}, &PromoRedemption{})
+	return db
+}
+
+func newTestRedemption(id, bookingID, passengerID string, amountCents int64) *promotionentity.Redemption {
+	return &promotionentity.Redemption{
+		ID: id, Code: "LAUNCH", BookingID: bookingID, PassengerID: passengerID, AmountCents: amountCents,
+		Currency: "CNY", Status: promotionentity.RedemptionRedeemed,
+	}
+}
+
+func TestPromotionRepository_CreateAndGet(t *testing.T) {
+	repo := NewPromotionRepository(newTestDBPromotion())
+	start := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	p := &promotionentity.Promotion{
+		Code: "LAUNCH", Kind: promotionentity.PromotionFixed, AmountCents: 500, Currency: "CNY",
+		AirportCodes: []string{"PVG", "SHA"}, StartsAt: start, EndsAt: start.AddDate(0, 1, 0), BudgetCents: 1000,
+	}
+	require.NoError(t, repo.CreatePromotion(p))
+	assert.ErrorIs(t, repo.CreatePromotion(p), promotion.ErrPromotionExists)
+
+	got, err := repo.GetPromotion("LAUNCH")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, []string{"PVG", "SHA"}, got.AirportCodes)
+	assert.Equal(t, int64(1000), got.BudgetCents)
+
+	missing, err := repo.GetPromotion("NOPE")
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+
+	list, err := repo.ListPromotions()
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+}
+
+func TestPromotionRepository_RedeemEnforcesLimits(t *testing.T) {
+	repo := NewPromotionRepository(newTestDBPromotion())
+	start := time.Now().Add(-time.Hour)
+	require.NoError(t, repo.CreatePromotion(&promotionentity.Promotion{
+		Code: "LAUNCH", Kind: promotionentity.PromotionFixed, AmountCents: 500, Currency: "CNY",
+		StartsAt: start, EndsAt: start.AddDate(0, 1, 0), PerPassengerLimit: 1, TotalLimit: 3, BudgetCents: 1200,
+	}))
+
+	require.NoError(t, repo.Redeem(newTestRedemption("r1", "b1", "p1", 500)))
+	assert.ErrorIs(t, repo.Redeem(newTestRedemption("r2", "b1", "p2", 500)), promotion.ErrRedemptionExists)
+	assert.ErrorIs(t, repo.Redeem(newTestRedemption("r3", "b2", "p1", 500)), promotionentity.ErrPassengerLimitReached)
+	require.NoError(t, repo.Redeem(newTestRedemption("r4", "b3", "p2", 500)))
+	// 剩余预算 200，不足 500
+	assert.ErrorIs(t, repo.Redeem(newTestRedemption("r5", "b4", "p3", 500)), promotionentity.ErrPromotionBudgetExceeded)
+	require.NoError(t, repo.Redeem(newTestRedemption("r6", "b4", "p3", 200)))
+	assert.ErrorIs(t, repo.Redeem(newTestRedemption("r7", "b5", "p4", 1)), promotionentity.ErrPromotionLimitReached)
+
+	p, err := repo.GetPromotion("LAUNCH")
+	require.NoError(t, err)
+	assert.Equal(t, 3, p.RedeemedCount)
+	assert.Equal(t, int64(1200), p.SpentCents)
+	n, err := repo.CountPassengerRedemptions("LAUNCH", "p1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	// 撤销后退回次数与预算，乘客可再次使用；重复撤销不重复退回
+	require.NoError(t, repo.ReleaseRedemption("b1"))
+	require.NoError(t, repo.ReleaseRedemption("b1"))
+	p, err = repo.GetPromotion("LAUNCH")
+	require.NoError(t, err)
+	assert.Equal(t, 2, p.RedeemedCount)
+	assert.Equal(t, int64(700), p.SpentCents)
+	red, err := repo.GetRedemptionByBooking("b1")
+	require.NoError(t, err)
+	assert.Equal(t, promotionentity.RedemptionReleased, red.Status)
+	assert.NotNil(t, red.ReleasedAt)
+	require.NoError(t, repo.Redeem(newTestRedemption("r8", "b6", "p1", 500)))
+}
@@ -0,0 +1,137 @@
+package mysqlrepo
+
+import (
+	"time"
+
+	settlement "github.com/gavin/airport-pickup/internal/domain/settlement"
+	settlemententity "github.com/gavin/airport-pickup/internal/domain/settlement/entity"
+	"gorm.io/gorm"
+)
+
+type ReconciliationRepository struct{ db *gorm.DB }
+
+func NewReconciliationRepository(db *gorm.DB) settlement.ReconciliationRepository {
+	return &ReconciliationRepository{db: db}
+}
+
+func (r *ReconciliationRepository) ListSettledPaymentTransactions(from, to time.Time) ([]*settlemententity.PaymentTransaction, error) {
+	var ms []PaymentTransaction
+	err := r.db.Where("(kind IN ? AND captured_at >= ? AND captured_at < ?) OR (kind = ? AND created_at >= ? AND created_at < ?)",
+		[]string{settlemententity.PaymentKindPayment, settlemententity.PaymentKindDeposit}, from, to, settlemententity.PaymentKindRefund, from, to).
+		Order("created_at, id").Find(&ms).Error
+	if err != nil {
+		return nil, err
+	}
+	return toPaymentTransactionEntities(ms), nil
+}
+
+func (r *ReconciliationRepository) ListPaymentTransactionsByBookings(bookingIDs []string) ([]*settlemententity.PaymentTransaction, error) {
+	if len(bookingIDs) == 0 {
+		return nil, nil
+	}
+	var ms []PaymentTransaction
+	err := r.db.Where("booking_id IN ? AND (kind = ? OR captured_at IS NOT NULL)", bookingIDs, settlemententity.PaymentKindRefund).
+		Order("created_at, id").Find(&ms).Error
+	if err != nil {
+		return nil, err
+	}
+	return toPaymentTransactionEntities(ms), nil
+}
+
+func (r *ReconciliationRepository) SaveReconciliationReport(rep *settlemententity.ReconciliationReport) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if rep.CreatedAt.IsZero() {
+			rep.CreatedAt = now
+		}
+		rep.UpdatedAt = now
+		m := &ReconciliationReport{
+			ID: rep.ID, Source: truncate(rep.Source, 255), Status: rep.Status, PeriodStart: rep.PeriodStart, PeriodEnd: rep.PeriodEnd,
+			StatementLines: rep.StatementLines, Transactions: rep.Transactions, MatchedLines: rep.MatchedLines,
+			StatementCents: rep.StatementCents, TransactionCents: rep.TransactionCents, CreatedAt: rep.CreatedAt, UpdatedAt: rep.UpdatedAt,
+		}
+		if err := tx.Create(m).Error; err != nil {
+			return err
+		}
+		if len(rep.Exceptions) == 0 {
+			return nil
+		}
+		ms := make([]ReconciliationException, 0, len(rep.Exceptions))
+		for i, e := range rep.Exceptions {
+			ms = append(ms, ReconciliationException{
+				ID: e.ID, ReportID: rep.ID, Seq: i, Kind: e.Kind, BookingID: e.BookingID, Type: e.Type,
+				TransactionID: e.TransactionID, StatementRef: truncate(e.StatementRef, 128), StatementLineNo: e.StatementLineNo,
+				ExpectedCents: e.ExpectedCents, StatementCents: e.StatementCents, Currency: e.Currency,
+				Status: e.Status, Resolution: e.Resolution, Note: truncate(e.Note, 500), ResolvedAt: e.ResolvedAt,
+			})
+		}
+		return tx.Create(&ms).Error
+	})
+}
+
+func (r *ReconciliationRepository) GetReconciliationReport(id string) (*settlemententity.ReconciliationReport, error) {
+	var ms []ReconciliationReport
+	if err := r.db.Where("id = ?", id).Limit(1).Find(&ms).Error; err != nil {
+		return nil, err
+	}
+	if len(ms) == 0 {
+		return nil, nil
+	}
+	rep := toReconciliationReportEntity(&ms[0])
+	var es []ReconciliationException
+	if err := r.db.Where("report_id = ?", id).Order("seq").Find(&es).Error; err != nil {
+		return nil, err
+	}
+	for _, e := range es {
+		rep.Exceptions = append(rep.Exceptions, settlemententity.ReconciliationException{
+			ID: e.ID, Kind: e.Kind, BookingID: e.BookingID, Type: e.Type, TransactionID: e.TransactionID,
+			StatementRef: e.StatementRef, StatementLineNo: e.StatementLineNo, ExpectedCents: e.ExpectedCents, StatementCents: e.StatementCents,
+			Currency: e.Currency, Status: e.Status, Resolution: e.Resolution, Note: e.Note, ResolvedAt: e.ResolvedAt,
+		})
+	}
+	return rep, nil
+}
+
+func (r *ReconciliationRepository) ListReconciliationReports(limit int) ([]*settlemententity.ReconciliationReport, error) {
+	var ms []ReconciliationReport
+	if err := r.db.Order("created_at DESC, id").Limit(limit).Find(&ms).Error; err != nil {
+		return nil, err
+	}
+	res := make([]*settlemententity.ReconciliationReport, 0, len(ms))
+	for i := range ms {
+		res = append(res, toReconciliationReportEntity(&ms[i]))
+	}
+	return res, nil
+}
+
+func (r *ReconciliationRepository) ResolveReconciliationException(rep *settlemententity.ReconciliationReport, e *settlemententity.ReconciliationException) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&ReconciliationException{}).Where("id = ? AND report_id = ? AND status = ?", e.ID, rep.ID, settlemententity.ExceptionOpen).
+			Updates(map[string]any{"status": e.Status, "resolution": e.Resolution, "note": truncate(e.Note, 500), "resolved_at": e.ResolvedAt})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return settlement.ErrExceptionAlreadyResolved
+		}
+		rep.UpdatedAt = time.Now()
+		return tx.Model(&ReconciliationReport{}).Where("id = ?", rep.ID).
+			Updates(map[string]any{"status": rep.Status, "updated_at": rep.UpdatedAt}).Error
+	})
+}
+
+func toReconciliationReportEntity(m *ReconciliationReport) *settlemententity.ReconciliationReport {
+	return &settlemententity.ReconciliationReport{
+		ID: m.ID, Source: m.Source, Status: m.Status, PeriodStart: m.PeriodStart, PeriodEnd: m.PeriodEnd,
+		StatementLines: m.StatementLines, Transactions: m.Transactions, MatchedLines: m.MatchedLines,
+		StatementCents: m.StatementCents, TransactionCents: m.TransactionCents, CreatedAt: m.CreatedAt, UpdatedAt: m.UpdatedAt,
+	}
+}
+
+func toPaymentTransactionEntities(ms []PaymentTransaction) []*settlemententity.PaymentTransaction {
+	res := make([]*settlemententity.PaymentTransaction, 0, len(ms))
+	for i := range ms {
+		res = append(res, toPaymentTransactionEntity(&ms[i]))
+	}
+	return res
+}
@@ -0,0 +1,102 @@
+package mysqlrepo
+
+import (
+	"testing"
+	"time"
+
+	settlement "github.com/gavin/airport-pickup/internal/domain/settlement"
+	settlemententity "github.com/gavin/airport-pickup/internal/domain/settlement/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func newTestDBReconciliation() *gorm.DB {
+	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	db.AutoMigrate(&PaymentTransaction{}, &ReconciliationReport{}, &ReconciliationException{})
+	return db
+}
+
+func TestReconciliationRepository_ListSettledPaymentTransactions(t *testing.T) {
+	db := newTestDBReconciliation()
+	repo := NewReconciliationRepository(db)
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	inDay, before := day.Add(10*time.Hour), day.Add(-time.Hour)
+	for _, tx := range []*settlemententity.PaymentTransaction{
+		{ID: "p1", BookingID: "b1", Kind: settlemententity.PaymentKindPayment, AmountCents: 2000, CapturedCents: 1800, Status: settlemententity.PaymentCaptured, CapturedAt: &inDay, CreatedAt: before},
+		{ID: "p2", BookingID: "b2", Kind: settlemententity.PaymentKindPayment, AmountCents: 2000, CapturedCents: 2000, Status: settlemententity.PaymentCaptured, CapturedAt: &before, CreatedAt: before},
+		{ID: "p3", BookingID: "b3", Kind: settlemententity.PaymentKindPayment, AmountCents: 2000, Status: settlemententity.PaymentAuthorized, CreatedAt: inDay},
+		{ID: "r1", BookingID: "b2", Kind: settlemententity.PaymentKindRefund, AmountCents: 300, Status: settlemententity.PaymentRefunded, CreatedAt: inDay},
+		{ID: "d1", BookingID: "deposit-q1", Kind: settlemententity.PaymentKindDeposit, AmountCents: 2000, CapturedCents: 2000, Status: settlemententity.PaymentCaptured, CapturedAt: &inDay, CreatedAt: before},
+		{ID: "d2", BookingID: "deposit-q2", Kind: settlemententity.PaymentKindDeposit, AmountCents: 2000, Status: settlemententity.PaymentVoided, CreatedAt: inDay},
+	} {
+		require.NoError(t, savePaymentTransaction(db, tx))
+	}
+
+	got, err := repo.ListSettledPaymentTransactions(day, day.AddDate(0, 0, 1))
+	require.NoError(t, err)
+	ids := make([]string, 0, len(got))
+	for _, tx := range got {
+		ids = append(ids, tx.ID)
+	}
+	assert.ElementsMatch(t, []string{"p1", "r1", "d1"}, ids)
+
+	// 按订单查询时不限时间，但未扣款的支付流水不参与对账
+	got, err = repo.ListPaymentTransactionsByBookings([]string{"b2", "b3"})
+	require.NoError(t, err)
+	ids = ids[:0]
+	for _, tx := range got {
+		ids = append(ids, tx.ID)
+	}
+	assert.ElementsMatch(t, []string{"p2", "r1"}, ids)
+}
+
+func TestReconciliationRepository_SaveAndResolve(t *testing.T) {
+	repo := NewReconciliationRepository(newTestDBReconciliation())
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	rep := &settlemententity.ReconciliationReport{
+		ID: "rec1", Source: "wallet.csv", Status: settlemententity.ReconciliationOpen, PeriodStart: start, PeriodEnd: start.AddDate(0, 0, 1),
+		StatementLines: 3, Transactions: 2, MatchedLines: 1, StatementCents: 3000, TransactionCents: 2500,
+		Exceptions: []settlemententity.ReconciliationException{
+			{ID: "e1", Kind: settlemententity.ExceptionAmountMismatch, BookingID: "b1", Type: settlemententity.StatementPayment, TransactionID: "p1", StatementRef: "w1", StatementLineNo: 1, ExpectedCents: 1500, StatementCents: 1800, Currency: "USD", Status: settlemententity.ExceptionOpen},
+			{ID: "e2", Kind: settlemententity.ExceptionMissingInLedger, BookingID: "b9", Type: settlemententity.StatementPayment, StatementRef: "w3", StatementLineNo: 3, StatementCents: 200, Currency: "USD", Status: settlemententity.ExceptionOpen},
+		},
+	}
+	require.NoError(t, repo.SaveReconciliationReport(rep))
+
+	got, err := repo.GetReconciliationReport("rec1")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, "wallet.csv", got.Source)
+	assert.Equal(t, 3, got.StatementLines)
+	require.Len(t, got.Exceptions, 2)
+	assert.Equal(t, "e1", got.Exceptions[0].ID)
+	assert.Equal(t, int64(1800), got.Exceptions[0].StatementCents)
+
+	missing, err := repo.GetReconciliationReport("nope")
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+
+	for i := range got.Exceptions {
+		e := &got.Exceptions[i]
+		require.NoError(t, e.Resolve(settlemententity.ResolutionWriteOff, "ok", time.Now()))
+		got.RefreshStatus()
+		require.NoError(t, repo.ResolveReconciliationException(got, e))
+	}
+	// 并发处理同一差异时只有一次成功
+	stale := &settlemententity.ReconciliationException{ID: "e1", Status: settlemententity.ExceptionResolved, Resolution: settlemententity.ResolutionFalsePositive}
+	assert.ErrorIs(t, repo.ResolveReconciliationException(got, stale), settlement.ErrExceptionAlreadyResolved)
+
+	list, err := repo.ListReconciliationReports(10)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, settlemententity.ReconciliationReconciled, list[0].Status)
+	assert.Empty(t, list[0].Exceptions)
+
+	got, err = repo.GetReconciliationReport("rec1")
+	require.NoError(t, err)
+	assert.Equal(t, settlemententity.ResolutionWriteOff, got.Exceptions[0].Resolution)
+	assert.Equal(t, "ok", got.Exceptions[0].Note)
+	assert.NotNil(t, got.Exceptions[0].ResolvedAt)
+}
@@ -0,0 +1,116 @@
+package mysqlrepo
+
+import (
+	"time"
+
+	settlement "github.com/gavin/airport-pickup/internal/domain/settlement"
+	settlemententity "github.com/gavin/airport-pickup/internal/domain/settlement/entity"
+	"gorm.io/gorm"
+)
+
+type RevenueStatsRepository struct{ db *gorm.DB }
+
+func NewRevenueStatsRepository(db *gorm.DB) settlement.RevenueStatsRepository {
+	return &RevenueStatsRepository{db: db}
+}
+
+func (r *RevenueStatsRepository) ListSettlementRecordsByBooking(bookingID string) ([]*settlemententity.SettlementRecord, error) {
+	var ms []SettlementRecord
+	if err := r.db.Where("booking_id = ?", bookingID).Order("created_at, id").Find(&ms).Error; err != nil {
+		return nil, err
+	}
+	return toSettlementRecordEntities(ms), nil
+}
+
+func (r *RevenueStatsRepository) ListUnprojectedSettlementRecords(limit int) ([]*settlemententity.SettlementRecord, error) {
+	var ms []SettlementRecord
+	err := r.db.Where("id NOT IN (?)", r.db.Model(&RevenueStatSource{}).Select("settlement_record_id")).
+		Order("created_at, id").Limit(limit).Find(&ms).Error
+	if err != nil {
+		return nil, err
+	}
+	return toSettlementRecordEntities(ms), nil
+}
+
+func (r *RevenueStatsRepository) ApplyRevenueFacts(facts []*settlemententity.RevenueFact) (int, error) {
+	applied := 0
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		applied = 0
+		now := time.Now()
+		for _, f := range facts {
+			var n int64
+			if err := tx.Model(&RevenueStatSource{}).Where("settlement_record_id = ?", f.RecordID).Count(&n).Error; err != nil {
+				return err
+			}
+			if n > 0 {
+				continue
+			}
+			// 并发计入同一记录时主键冲突使事务回滚，由事件重试再次计入
+			if err := tx.Create(&RevenueStatSource{SettlementRecordID: f.RecordID, Day: f.Day, CreatedAt: now}).Error; err != nil {
+				return err
+			}
+			res := tx.Model(&RevenueDailyStat{}).
+				Where("day = ? AND airport_code = ? AND vehicle_type = ? AND currency = ?", f.Day, f.AirportCode, f.VehicleType, f.Currency).
+				Updates(map[string]any{
+					"trips":         gorm.Expr("trips + ?", f.Trips),
+					"gross_cents":   gorm.Expr("gross_cents + ?", f.GrossCents),
+					"revenue_cents": gorm.Expr("revenue_cents + ?", f.RevenueCents),
+					"updated_at":    now,
+				})
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				err := tx.Create(&RevenueDailyStat{
+					Day: f.Day, AirportCode: f.AirportCode, VehicleType: f.VehicleType, Currency: f.Currency,
+					Trips: f.Trips, GrossCents: f.GrossCents, RevenueCents: f.RevenueCents, UpdatedAt: now,
+				}).Error
+				if err != nil {
+					return err
+				}
+			}
+			applied++
+		}
+		return nil
+	})
+	return applied, err
+}
+
+func (r *RevenueStatsRepository) ListDailyRevenueStats(f settlement.RevenueStatsFilter) ([]settlemententity.RevenueStat, error) {
+	q := r.db.Model(&RevenueDailyStat{})
+	if !f.From.IsZero() {
+		q = q.Where("day >= ?", f.From)
+	}
+	if !f.To.IsZero() {
+		q = q.Where("day < ?", f.To)
+	}
+	if f.AirportCode != "" {
+		q = q.Where("airport_code = ?", f.AirportCode)
+	}
+	if f.VehicleType != "" {
+		q = q.Where("vehicle_type = ?", f.VehicleType)
+	}
+	if f.Currency != "" {
+		q = q.Where("currency = ?", f.Currency)
+	}
+	var ms []RevenueDailyStat
+	if err := q.Order("day, airport_code, vehicle_type, currency").Find(&ms).Error; err != nil {
+		return nil, err
+	}
+	res := make([]settlemententity.RevenueStat, 0, len(ms))
+	for _, m := range ms {
+		res = append(res, settlemententity.RevenueStat{
+			Period: m.Day.UTC(), AirportCode: m.AirportCode, VehicleType: m.VehicleType, Currency: m.Currency,
+			Trips: m.Trips, GrossCents: m.GrossCents, RevenueCents: m.RevenueCents,
+		})
+	}
+	return res, nil
+}
+
+func toSettlementRecordEntities(ms []SettlementRecord) []*settlemententity.SettlementRecord {
+	res := make([]*settlemententity.SettlementRecord, 0, len(ms))
+	for i := range ms {
+		res = append(res, toSettlementRecordEntity(&ms[i]))
+	}
+	return res
+}
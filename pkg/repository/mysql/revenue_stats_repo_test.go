@@ -0,0 +1,81 @@
+package mysqlrepo
+
+import (
+	"testing"
+	"time"
+
+	settlement "github.com/gavin/airport-pickup/internal/domain/settlement"
+	settlemententity "github.com/gavin/airport-pickup/internal/domain/settlement/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func newTestDBRevenueStats() *gorm.DB {
+	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	db.AutoMigrate(&SettlementRecord{}, &RevenueDailyStat{}, &RevenueStatSource{})
+	return db
+}
+
+func TestRevenueStatsRepository_ApplyIsIdempotent(t *testing.T) {
+	db := newTestDBRevenueStats()
+	repo := NewRevenueStatsRepository(db)
+	day := time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC)
+	now := time.Now()
+	for _, m := range []SettlementRecord{
+		{ID: "sr1", BookingID: "b1", DriverID: "d1", PassengerID: "p1", AmountCents: 2000, PlatformRevenueCents: 400, Currency: "USD", CreatedAt: now, UpdatedAt: now},
+		{ID: "sr2", BookingID: "b1", DriverID: "d1", PassengerID: "p1", AmountCents: -500, PlatformRevenueCents: -100, Currency: "USD", CreatedAt: now.Add(time.Second), UpdatedAt: now},
+	} {
+		require.NoError(t, db.Create(&m).Error)
+	}
+	pending, err := repo.ListUnprojectedSettlementRecords(10)
+	require.NoError(t, err)
+	assert.Len(t, pending, 2)
+
+	f1 := &settlemententity.RevenueFact{RecordID: "sr1", BookingID: "b1", Day: day, AirportCode: "SFO", VehicleType: "sedan", Currency: "USD", Trips: 1, GrossCents: 2000, RevenueCents: 400}
+	f2 := &settlemententity.RevenueFact{RecordID: "sr2", BookingID: "b1", Day: day, AirportCode: "SFO", VehicleType: "sedan", Currency: "USD", GrossCents: -500, RevenueCents: -100}
+	n, err := repo.ApplyRevenueFacts([]*settlemententity.RevenueFact{f1})
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	// 事件重复投递：已计入的记录跳过
+	n, err = repo.ApplyRevenueFacts([]*settlemententity.RevenueFact{f1, f2})
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	pending, err = repo.ListUnprojectedSettlementRecords(10)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+
+	stats, err := repo.ListDailyRevenueStats(settlement.RevenueStatsFilter{From: day, To: day.AddDate(0, 0, 1)})
+	require.NoError(t, err)
+	require.Len(t, stats, 1)
+	assert.Equal(t, settlemententity.RevenueStat{Period: day, AirportCode: "SFO", VehicleType: "sedan", Currency: "USD", Trips: 1, GrossCents: 1500, RevenueCents: 300}, stats[0])
+}
+
+func TestRevenueStatsRepository_ListDailyFilters(t *testing.T) {
+	repo := NewRevenueStatsRepository(newTestDBRevenueStats())
+	d1 := time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC)
+	d2 := d1.AddDate(0, 0, 1)
+	_, err := repo.ApplyRevenueFacts([]*settlemententity.RevenueFact{
+		{RecordID: "a", Day: d1, AirportCode: "SFO", VehicleType: "sedan", Currency: "USD", Trips: 1, GrossCents: 100, RevenueCents: 10},
+		{RecordID: "b", Day: d1, AirportCode: "SFO", VehicleType: "sedan", Currency: "USD", Trips: 1, GrossCents: 200, RevenueCents: 20},
+		{RecordID: "c", Day: d1, AirportCode: "PEK", VehicleType: "sedan", Currency: "CNY", Trips: 1, GrossCents: 300, RevenueCents: 30},
+		{RecordID: "d", Day: d2, AirportCode: "SFO", VehicleType: "suv", Currency: "USD", Trips: 1, GrossCents: 400, RevenueCents: 40},
+	})
+	require.NoError(t, err)
+
+	all, err := repo.ListDailyRevenueStats(settlement.RevenueStatsFilter{})
+	require.NoError(t, err)
+	assert.Len(t, all, 3)
+
+	sfo, err := repo.ListDailyRevenueStats(settlement.RevenueStatsFilter{AirportCode: "SFO", From: d1, To: d2})
+	require.NoError(t, err)
+	require.Len(t, sfo, 1)
+	assert.Equal(t, int64(2), sfo[0].Trips)
+
+	suv, err := repo.ListDailyRevenueStats(settlement.RevenueStatsFilter{VehicleType: "suv", Currency: "USD"})
+	require.NoError(t, err)
+	require.Len(t, suv, 1)
+	assert.True(t, suv[0].Period.Equal(d2))
+}
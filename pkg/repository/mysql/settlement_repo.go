@@ -1,6 +1,7 @@
 package mysqlrepo
 
 import (
+	"encoding/json"
 	settlement "github.com/gavin/airport-pickup/internal/domain/settlement"
 	settlemententity "github.com/gavin/airport-pickup/internal/domain/settlement/entity"
 	"gorm.io/gorm"
@@ -14,11 +15,27 @@ func NewSettlementRepository(db *gorm.DB) settlement.SettlementRepository {
 }
 
 func (r *SettlementRepository) SavePaymentTransaction(t *settlemententity.PaymentTransaction) error {
+	return savePaymentTransaction(r.db, t)
+}
+
+func savePaymentTransaction(db *gorm.DB, t *settlemententity.PaymentTransaction) error {
 	now := time.Now()
-	m := &PaymentTransaction{ID: t.ID, BookingID: t.BookingID, AmountCents: t.AmountCents, Status: t.Status}
-	m.CreatedAt = now
-	m.UpdatedAt = now
-	return r.db.Save(m).Error
+	if t.CreatedAt.IsZero() {
+		t.CreatedAt = now
+	}
+	t.UpdatedAt = now
+	if t.Kind == "" {
+		t.Kind = settlemententity.PaymentKindPayment
+	}
+	m := &PaymentTransaction{
+		ID: t.ID, BookingID: t.BookingID, Kind: t.Kind, AmountCents: t.AmountCents,
+		CapturedCents: t.CapturedCents, RefundedCents: t.RefundedCents, Currency: t.Currency,
+		Status: t.Status, FailureReason: truncate(t.FailureReason, 255), ReasonCode: t.ReasonCode,
+		AuthorizedAt: t.AuthorizedAt, CapturedAt: t.CapturedAt, VoidedAt: t.VoidedAt,
+		RefundedAt: t.RefundedAt, FailedAt: t.FailedAt,
+		CreatedAt: t.CreatedAt, UpdatedAt: t.UpdatedAt,
+	}
+	return db.Save(m).Error
 }
 
 func (r *SettlementRepository) GetPaymentTransactionByID(id string) (*settlemententity.PaymentTransaction, error) {
@@ -26,15 +43,170 @@ func (r *SettlementRepository) GetPaymentTransactionByID(id string) (*settlement
 	if err := r.db.First(&m, "id = ?", id).Error; err != nil {
 		return nil, err
 	}
-	return &settlemententity.PaymentTransaction{ID: m.ID, BookingID: m.BookingID, AmountCents: m.AmountCents, Status: m.Status, CreatedAt: m.CreatedAt, UpdatedAt: m.UpdatedAt}, nil
+	return toPaymentTransactionEntity(&m), nil
+}
+
+func (r *SettlementRepository) GetPaymentTransactionByBookingID(bookingID string) (*settlemententity.PaymentTransaction, error) {
+	var ms []PaymentTransaction
+	if err := r.db.Where("booking_id = ? AND kind = ?", bookingID, settlemententity.PaymentKindPayment).Order("created_at DESC").Limit(1).Find(&ms).Error; err != nil {
+		return nil, err
+	}
+	if len(ms) == 0 {
+		return nil, nil
+	}
+	return toPaymentTransactionEntity(&ms[0]), nil
+}
+
+func (r *SettlementRepository) ListRefundTransactions(bookingID string) ([]*settlemententity.PaymentTransaction, error) {
+	var ms []PaymentTransaction
+	if err := r.db.Where("booking_id = ? AND kind = ?", bookingID, settlemententity.PaymentKindRefund).Order("created_at").Find(&ms).Error; err != nil {
+		return nil, err
+	}
+	res := make([]*settlemententity.PaymentTransaction, 0, len(ms))
+	for i := range ms {
+		res = append(res, toPaymentTransactionEntity(&ms[i]))
+	}
+	return res, nil
+}
+
+func (r *SettlementRepository) ReserveRefund(paymentID string, amountCents int64) (int64, error) {
+	var refundedBefore int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// 在数据库中累加退款金额并校验上限，避免并发退款超出已扣款金额；
+		// 更新后该行被锁定到事务结束，随后读到的即本次预占后的金额
+		res := tx.Model(&PaymentTransaction{}).
+			Where("id = ? AND status IN ? AND captured_cents - refunded_cents >= ?", paymentID,
+				[]string{settlemententity.PaymentCaptured, settlemententity.PaymentRefunded}, amountCents).
+			Updates(map[string]any{
+				"refunded_cents": gorm.Expr("refunded_cents + ?", amountCents),
+				"updated_at":     time.Now(),
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return settlement.ErrRefundExceedsCaptured
+		}
+		var m PaymentTransaction
+		if err := tx.Select("refunded_cents").First(&m, "id = ?", paymentID).Error; err != nil {
+			return err
+		}
+		refundedBefore = m.RefundedCents - amountCents
+		return nil
+	})
+	return refundedBefore, err
+}
+
+func (r *SettlementRepository) ReleaseRefund(paymentID string, amountCents int64) error {
+	return r.db.Model(&PaymentTransaction{}).
+		Where("id = ? AND refunded_cents >= ?", paymentID, amountCents).
+		Updates(map[string]any{
+			"refunded_cents": gorm.Expr("refunded_cents - ?", amountCents),
+			"updated_at":     time.Now(),
+		}).Error
+}
+
+func (r *SettlementRepository) SaveRefund(payment, refund *settlemententity.PaymentTransaction, sr *settlemententity.SettlementRecord, rr *settlemententity.RevenueRecord, entries []*settlemententity.JournalEntry) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		// 退款金额已由 ReserveRefund 累加，这里只更新状态
+		if err := tx.Model(&PaymentTransaction{}).Where("id = ?", payment.ID).
+			Updates(map[string]any{
+				"status":      payment.Status,
+				"refunded_at": payment.RefundedAt,
+				"updated_at":  now,
+			}).Error; err != nil {
+			return err
+		}
+		if err := savePaymentTransaction(tx, refund); err != nil {
+			return err
+		}
+		if sr != nil {
+			mSR := &SettlementRecord{ID: sr.ID, BookingID: sr.BookingID, DriverID: sr.DriverID, PassengerID: sr.PassengerID, AmountCents: sr.AmountCents, PlatformRevenueCents: sr.PlatformRevenueCents, TaxCents: sr.TaxCents, Currency: sr.Currency}
+			mSR.CreatedAt = now
+			mSR.UpdatedAt = now
+			if err := tx.Save(mSR).Error; err != nil {
+				return err
+			}
+		}
+		if rr != nil {
+			mRR := &RevenueRecord{ID: rr.ID, BookingID: rr.BookingID, DeltaCents: rr.DeltaCents, Currency: rr.Currency}
+			mRR.CreatedAt = now
+			mRR.UpdatedAt = now
+			if err := tx.Save(mRR).Error; err != nil {
+				return err
+			}
+		}
+		return saveJournalEntries(tx, entries)
+	})
+}
+
+func (r *SettlementRepository) GetDepositTransaction(holdKey string) (*settlemententity.PaymentTransaction, error) {
+	var ms []PaymentTransaction
+	if err := r.db.Where("booking_id = ? AND kind = ?", holdKey, settlemententity.PaymentKindDeposit).Order("created_at DESC").Limit(1).Find(&ms).Error; err != nil {
+		return nil, err
+	}
+	if len(ms) == 0 {
+		return nil, nil
+	}
+	return toPaymentTransactionEntity(&ms[0]), nil
+}
+
+func (r *SettlementRepository) SaveDepositCapture(ptx *settlemententity.PaymentTransaction, rr *settlemententity.RevenueRecord, entries []*settlemententity.JournalEntry) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := savePaymentTransaction(tx, ptx); err != nil {
+			return err
+		}
+		mRR := &RevenueRecord{ID: rr.ID, BookingID: rr.BookingID, DeltaCents: rr.DeltaCents, Currency: rr.Currency}
+		mRR.CreatedAt = now
+		mRR.UpdatedAt = now
+		if err := tx.Save(mRR).Error; err != nil {
+			return err
+		}
+		return saveJournalEntries(tx, entries)
+	})
+}
+
+func toPaymentTransactionEntity(m *PaymentTransaction) *settlemententity.PaymentTransaction {
+	return &settlemententity.PaymentTransaction{
+		ID: m.ID, BookingID: m.BookingID, Kind: m.Kind, AmountCents: m.AmountCents,
+		CapturedCents: m.CapturedCents, RefundedCents: m.RefundedCents, Currency: m.Currency,
+		Status: m.Status, FailureReason: m.FailureReason, ReasonCode: m.ReasonCode,
+		AuthorizedAt: m.AuthorizedAt, CapturedAt: m.CapturedAt, VoidedAt: m.VoidedAt,
+		RefundedAt: m.RefundedAt, FailedAt: m.FailedAt,
+		CreatedAt: m.CreatedAt, UpdatedAt: m.UpdatedAt,
+	}
 }
 
 func (r *SettlementRepository) SaveSettlementRecord(s *settlemententity.SettlementRecord) error {
-	now := time.Now()
-	m := &SettlementRecord{ID: s.ID, BookingID: s.BookingID, DriverID: s.DriverID, PassengerID: s.PassengerID, AmountCents: s.AmountCents, PlatformRevenueCents: s.PlatformRevenueCents}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return saveSettlementRecord(tx, s, time.Now())
+	})
+}
+
+// saveSettlementRecord 保存结算记录及其车费明细（整体替换）。
+func saveSettlementRecord(db *gorm.DB, s *settlemententity.SettlementRecord, now time.Time) error {
+	m := &SettlementRecord{ID: s.ID, BookingID: s.BookingID, DriverID: s.DriverID, PassengerID: s.PassengerID, AmountCents: s.AmountCents, PlatformRevenueCents: s.PlatformRevenueCents, TaxCents: s.TaxCents, Currency: s.Currency}
 	m.CreatedAt = now
 	m.UpdatedAt = now
-	return r.db.Save(m).Error
+	if err := db.Save(m).Error; err != nil {
+		return err
+	}
+	if err := db.Where("settlement_record_id = ?", s.ID).Delete(&SettlementFareItem{}).Error; err != nil {
+		return err
+	}
+	if len(s.FareItems) == 0 {
+		return nil
+	}
+	items := make([]SettlementFareItem, 0, len(s.FareItems))
+	for i, it := range s.FareItems {
+		items = append(items, SettlementFareItem{
+			SettlementRecordID: s.ID, BookingID: s.BookingID, Seq: i, Kind: it.Kind, Description: truncate(it.Description, 100),
+			AmountCents: it.AmountCents, DriverCents: it.DriverCents, PlatformCents: it.PlatformCents, TaxCents: it.TaxCents, CreatedAt: now,
+		})
+	}
+	return db.Create(&items).Error
 }
 
 func (r *SettlementRepository) GetSettlementRecordByID(id string) (*settlemententity.SettlementRecord, error) {
@@ -42,12 +214,52 @@ func (r *SettlementRepository) GetSettlementRecordByID(id string) (*settlementen
 	if err := r.db.First(&m, "id = ?", id).Error; err != nil {
 		return nil, err
 	}
-	return &settlemententity.SettlementRecord{ID: m.ID, BookingID: m.BookingID, DriverID: m.DriverID, PassengerID: m.PassengerID, AmountCents: m.AmountCents, PlatformRevenueCents: m.PlatformRevenueCents, CreatedAt: m.CreatedAt, UpdatedAt: m.UpdatedAt}, nil
+	res, err := withFareItems(r.db, []SettlementRecord{m})
+	if err != nil {
+		return nil, err
+	}
+	return res[0], nil
+}
+
+// withFareItems 转换结算记录并加载各自的车费明细。
+func withFareItems(db *gorm.DB, ms []SettlementRecord) ([]*settlemententity.SettlementRecord, error) {
+	res := make([]*settlemententity.SettlementRecord, 0, len(ms))
+	if len(ms) == 0 {
+		return res, nil
+	}
+	ids := make([]string, 0, len(ms))
+	for i := range ms {
+		ids = append(ids, ms[i].ID)
+	}
+	var items []SettlementFareItem
+	if err := db.Where("settlement_record_id IN ?", ids).Order("settlement_record_id, seq").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	byRecord := make(map[string][]settlemententity.FareLineItem, len(ms))
+	for _, it := range items {
+		byRecord[it.SettlementRecordID] = append(byRecord[it.SettlementRecordID], settlemententity.FareLineItem{
+			Kind: it.Kind, Description: it.Description, AmountCents: it.AmountCents, DriverCents: it.DriverCents, PlatformCents: it.PlatformCents, TaxCents: it.TaxCents,
+		})
+	}
+	for i := range ms {
+		sr := toSettlementRecordEntity(&ms[i])
+		sr.FareItems = byRecord[sr.ID]
+		res = append(res, sr)
+	}
+	return res, nil
+}
+
+func toSettlementRecordEntity(m *SettlementRecord) *settlemententity.SettlementRecord {
+	return &settlemententity.SettlementRecord{
+		ID: m.ID, BookingID: m.BookingID, DriverID: m.DriverID, PassengerID: m.PassengerID,
+		AmountCents: m.AmountCents, PlatformRevenueCents: m.PlatformRevenueCents, TaxCents: m.TaxCents, Currency: m.Currency, PayoutID: m.PayoutID,
+		CreatedAt: m.CreatedAt, UpdatedAt: m.UpdatedAt,
+	}
 }
 
 func (r *SettlementRepository) SaveRevenueRecord(rr *settlemententity.RevenueRecord) error {
 	now := time.Now()
-	m := &RevenueRecord{ID: rr.ID, BookingID: rr.BookingID, DeltaCents: rr.DeltaCents}
+	m := &RevenueRecord{ID: rr.ID, BookingID: rr.BookingID, DeltaCents: rr.DeltaCents, Currency: rr.Currency}
 	m.CreatedAt = now
 	m.UpdatedAt = now
 	return r.db.Save(m).Error
@@ -60,32 +272,234 @@ func (r *SettlementRepository) ListRevenueRecords() ([]*settlemententity.Revenue
 	}
 	res := make([]*settlemententity.RevenueRecord, 0, len(ms))
 	for _, m := range ms {
-		res = append(res, &settlemententity.RevenueRecord{ID: m.ID, BookingID: m.BookingID, DeltaCents: m.DeltaCents, CreatedAt: m.CreatedAt, UpdatedAt: m.UpdatedAt})
+		res = append(res, &settlemententity.RevenueRecord{ID: m.ID, BookingID: m.BookingID, DeltaCents: m.DeltaCents, Currency: m.Currency, CreatedAt: m.CreatedAt, UpdatedAt: m.UpdatedAt})
 	}
 	return res, nil
 }
 
-func (r *SettlementRepository) SaveAllInTransaction(ptx *settlemententity.PaymentTransaction, sr *settlemententity.SettlementRecord, rr *settlemententity.RevenueRecord) error {
+func (r *SettlementRepository) SaveAllInTransaction(ptx *settlemententity.PaymentTransaction, sr *settlemententity.SettlementRecord, rr *settlemententity.RevenueRecord, entries []*settlemententity.JournalEntry) error {
 	return r.db.Transaction(func(tx *gorm.DB) error {
 		now := time.Now()
-		mPTX := &PaymentTransaction{ID: ptx.ID, BookingID: ptx.BookingID, AmountCents: ptx.AmountCents, Status: ptx.Status}
-		mPTX.CreatedAt = now
-		mPTX.UpdatedAt = now
-		if err := tx.Save(mPTX).Error; err != nil {
+		if err := savePaymentTransaction(tx, ptx); err != nil {
 			return err
 		}
-		mSR := &SettlementRecord{ID: sr.ID, BookingID: sr.BookingID, DriverID: sr.DriverID, PassengerID: sr.PassengerID, AmountCents: sr.AmountCents, PlatformRevenueCents: sr.PlatformRevenueCents}
-		mSR.CreatedAt = now
-		mSR.UpdatedAt = now
-		if err := tx.Save(mSR).Error; err != nil {
+		if err := saveSettlementRecord(tx, sr, now); err != nil {
 			return err
 		}
-		mRR := &RevenueRecord{ID: rr.ID, BookingID: rr.BookingID, DeltaCents: rr.DeltaCents}
+		mRR := &RevenueRecord{ID: rr.ID, BookingID: rr.BookingID, DeltaCents: rr.DeltaCents, Currency: rr.Currency}
 		mRR.CreatedAt = now
 		mRR.UpdatedAt = now
 		if err := tx.Save(mRR).Error; err != nil {
 			return err
 		}
-		return nil
+		return saveJournalEntries(tx, entries)
+	})
+}
+
+func (r *SettlementRepository) SaveSettlementSaga(s *settlemententity.SettlementSaga) error {
+	return saveSaga(r.db, s)
+}
+
+func saveSaga(db *gorm.DB, s *settlemententity.SettlementSaga) error {
+	now := time.Now()
+	if s.CreatedAt.IsZero() {
+		s.CreatedAt = now
+	}
+	s.UpdatedAt = now
+	m := &SettlementSaga{
+		ID: s.ID, BookingID: s.BookingID, DriverID: s.DriverID, PassengerID: s.PassengerID, PaymentMethod: s.PaymentMethod,
+		AmountCents: s.AmountCents, PlatformRevenueCents: s.PlatformRevenueCents, TaxCents: s.TaxCents, Currency: s.Currency,
+		Status: s.Status, Attempts: s.Attempts, LastError: truncate(s.LastError, 500),
+		CreatedAt: s.CreatedAt, UpdatedAt: s.UpdatedAt,
+	}
+	if len(s.FareItems) > 0 {
+		b, err := json.Marshal(toFareItemsJSON(s.FareItems))
+		if err != nil {
+			return err
+		}
+		m.FareItems = string(b)
+	}
+	return db.Save(m).Error
+}
+
+func (r *SettlementRepository) GetSettlementSagaByBookingID(bookingID string) (*settlemententity.SettlementSaga, error) {
+	var ms []SettlementSaga
+	if err := r.db.Where("booking_id = ?", bookingID).Limit(1).Find(&ms).Error; err != nil {
+		return nil, err
+	}
+	if len(ms) == 0 {
+		return nil, nil
+	}
+	return toSagaEntity(&ms[0]), nil
+}
+
+func (r *SettlementRepository) ListStuckSettlementSagas(before time.Time, limit int) ([]*settlemententity.SettlementSaga, error) {
+	var ms []SettlementSaga
+	q := r.db.Where("status NOT IN ? AND updated_at < ?", []string{
+		settlemententity.SagaCompleted, settlemententity.SagaRefunded, settlemententity.SagaFailed,
+	}, before).Order("updated_at")
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	if err := q.Find(&ms).Error; err != nil {
+		return nil, err
+	}
+	res := make([]*settlemententity.SettlementSaga, 0, len(ms))
+	for i := range ms {
+		res = append(res, toSagaEntity(&ms[i]))
+	}
+	return res, nil
+}
+
+func (r *SettlementRepository) SaveRecordsWithSaga(saga *settlemententity.SettlementSaga, ptx *settlemententity.PaymentTransaction, sr *settlemententity.SettlementRecord, rr *settlemententity.RevenueRecord, entries []*settlemententity.JournalEntry) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := (&SettlementRepository{db: tx}).SaveAllInTransaction(ptx, sr, rr, entries); err != nil {
+			return err
+		}
+		return saveSaga(tx, saga)
 	})
 }
+
+func saveJournalEntries(db *gorm.DB, entries []*settlemententity.JournalEntry) error {
+	now := time.Now()
+	for _, e := range entries {
+		if e.CreatedAt.IsZero() {
+			e.CreatedAt = now
+		}
+		if err := db.Create(&JournalEntry{ID: e.ID, BookingID: e.BookingID, PayoutID: e.PayoutID, Kind: e.Kind, Currency: e.Currency, CreatedAt: e.CreatedAt}).Error; err != nil {
+			return err
+		}
+		lines := make([]JournalLine, 0, len(e.Lines))
+		for _, l := range e.Lines {
+			lines = append(lines, JournalLine{EntryID: e.ID, Account: l.Account, Currency: e.Currency, DebitCents: l.DebitCents, CreditCents: l.CreditCents, CreatedAt: e.CreatedAt})
+		}
+		if len(lines) > 0 {
+			if err := db.Create(&lines).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (r *SettlementRepository) ListJournalEntries(bookingID string) ([]*settlemententity.JournalEntry, error) {
+	var ms []JournalEntry
+	if err := r.db.Where("booking_id = ?", bookingID).Order("created_at").Find(&ms).Error; err != nil {
+		return nil, err
+	}
+	if len(ms) == 0 {
+		return nil, nil
+	}
+	ids := make([]string, 0, len(ms))
+	for _, m := range ms {
+		ids = append(ids, m.ID)
+	}
+	var lines []JournalLine
+	if err := r.db.Where("entry_id IN ?", ids).Order("id").Find(&lines).Error; err != nil {
+		return nil, err
+	}
+	byEntry := make(map[string][]settlemententity.JournalLine, len(ms))
+	for _, l := range lines {
+		byEntry[l.EntryID] = append(byEntry[l.EntryID], settlemententity.JournalLine{Account: l.Account, DebitCents: l.DebitCents, CreditCents: l.CreditCents})
+	}
+	res := make([]*settlemententity.JournalEntry, 0, len(ms))
+	for _, m := range ms {
+		res = append(res, &settlemententity.JournalEntry{ID: m.ID, BookingID: m.BookingID, PayoutID: m.PayoutID, Kind: m.Kind, Currency: m.Currency, Lines: byEntry[m.ID], CreatedAt: m.CreatedAt})
+	}
+	return res, nil
+}
+
+type accountSums struct {
+	Account  string
+	Currency string
+	Debits   int64
+	Credits  int64
+}
+
+func (r *SettlementRepository) GetAccountBalances(account string) ([]settlemententity.AccountBalance, error) {
+	return r.accountBalances(r.db.Where("account = ?", account))
+}
+
+func (r *SettlementRepository) ListAccountBalances(accountType string) ([]settlemententity.AccountBalance, error) {
+	q := r.db
+	if accountType != "" {
+		q = q.Where("account = ? OR account LIKE ?", accountType, accountType+":%")
+	}
+	return r.accountBalances(q)
+}
+
+// accountBalances 按账户与币种汇总分录行，不同币种的发生额不合并。
+func (r *SettlementRepository) accountBalances(q *gorm.DB) ([]settlemententity.AccountBalance, error) {
+	var rows []accountSums
+	err := q.Model(&JournalLine{}).
+		Select("account, currency, COALESCE(SUM(debit_cents), 0) AS debits, COALESCE(SUM(credit_cents), 0) AS credits").
+		Group("account, currency").Order("account, currency").Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	res := make([]settlemententity.AccountBalance, 0, len(rows))
+	for _, row := range rows {
+		res = append(res, settlemententity.AccountBalance{Account: row.Account, Currency: row.Currency, DebitCents: row.Debits, CreditCents: row.Credits})
+	}
+	return res, nil
+}
+
+func (r *SettlementRepository) LedgerTotals() (debitCents, creditCents int64, err error) {
+	var sums accountSums
+	err = r.db.Model(&JournalLine{}).
+		Select("COALESCE(SUM(debit_cents), 0) AS debits, COALESCE(SUM(credit_cents), 0) AS credits").
+		Scan(&sums).Error
+	return sums.Debits, sums.Credits, err
+}
+
+func (r *SettlementRepository) ListUnbalancedJournalEntries() ([]string, error) {
+	var ids []string
+	err := r.db.Model(&JournalLine{}).
+		Select("entry_id").Group("entry_id").
+		Having("SUM(debit_cents) <> SUM(credit_cents)").
+		Order("entry_id").Pluck("entry_id", &ids).Error
+	return ids, err
+}
+
+func toSagaEntity(m *SettlementSaga) *settlemententity.SettlementSaga {
+	s := &settlemententity.SettlementSaga{
+		ID: m.ID, BookingID: m.BookingID, DriverID: m.DriverID, PassengerID: m.PassengerID, PaymentMethod: m.PaymentMethod,
+		AmountCents: m.AmountCents, PlatformRevenueCents: m.PlatformRevenueCents, TaxCents: m.TaxCents, Currency: m.Currency,
+		Status: m.Status, Attempts: m.Attempts, LastError: m.LastError,
+		CreatedAt: m.CreatedAt, UpdatedAt: m.UpdatedAt,
+	}
+	if m.FareItems != "" {
+		var items []fareItemJSON
+		if err := json.Unmarshal([]byte(m.FareItems), &items); err == nil {
+			for _, it := range items {
+				s.FareItems = append(s.FareItems, settlemententity.FareLineItem(it))
+			}
+		}
+	}
+	return s
+}
+
+// fareItemJSON saga 中车费明细的存储格式
+type fareItemJSON struct {
+	Kind          string `json:"kind"`
+	Description   string `json:"description"`
+	AmountCents   int64  `json:"amount_cents"`
+	DriverCents   int64  `json:"driver_cents"`
+	PlatformCents int64  `json:"platform_cents"`
+	TaxCents      int64  `json:"tax_cents,omitempty"`
+}
+
+func toFareItemsJSON(items []settlemententity.FareLineItem) []fareItemJSON {
+	res := make([]fareItemJSON, 0, len(items))
+	for _, it := range items {
+		res = append(res, fareItemJSON(it))
+	}
+	return res
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
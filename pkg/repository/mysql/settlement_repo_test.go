@@ -1,8 +1,11 @@
 package mysqlrepo
 
 import (
+	"fmt"
+	settlement "github.com/gavin/airport-pickup/internal/domain/settlement"
 	settlemententity "github.com/gavin/airport-pickup/internal/domain/settlement/entity"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"testing"
@@ -11,7 +14,7 @@ import (
 
 func newTestDBSettlement() *gorm.DB {
 	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
-	db.AutoMigrate(&PaymentTransaction{}, &SettlementRecord{}, &RevenueRecord{})
+	db.AutoMigrate(&PaymentTransaction{}, &SettlementRecord{}, &SettlementFareItem{}, &RevenueRecord{}, &SettlementSaga{}, &JournalEntry{}, &JournalLine{})
 	return db
 }
 
@@ -34,6 +37,138 @@ func TestGetPaymentTransactionByID_NotFound(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestGetPaymentTransactionByBookingID(t *testing.T) {
+	db := newTestDBSettlement()
+	repo := NewSettlementRepository(db)
+	got, err := repo.GetPaymentTransactionByBookingID("b1")
+	assert.NoError(t, err)
+	assert.Nil(t, got)
+
+	now := time.Now()
+	pt := &settlemententity.PaymentTransaction{ID: "pt1", BookingID: "b1", AmountCents: 1000, Status: settlemententity.PaymentAuthorized, AuthorizedAt: &now}
+	assert.NoError(t, repo.SavePaymentTransaction(pt))
+	assert.NoError(t, pt.MarkCaptured(800, now))
+	assert.NoError(t, repo.SavePaymentTransaction(pt))
+
+	got, err = repo.GetPaymentTransactionByBookingID("b1")
+	assert.NoError(t, err)
+	assert.Equal(t, settlemententity.PaymentCaptured, got.Status)
+	assert.Equal(t, int64(800), got.CapturedCents)
+	assert.NotNil(t, got.AuthorizedAt)
+	assert.NotNil(t, got.CapturedAt)
+	assert.Nil(t, got.VoidedAt)
+}
+
+func TestSaveRefund(t *testing.T) {
+	db := newTestDBSettlement()
+	repo := NewSettlementRepository(db)
+	now := time.Now()
+	payment := &settlemententity.PaymentTransaction{ID: "pt1", BookingID: "b1", AmountCents: 1000, Status: settlemententity.PaymentAuthorized}
+	assert.NoError(t, payment.MarkCaptured(1000, now))
+	assert.NoError(t, repo.SavePaymentTransaction(payment))
+
+	before, err := repo.ReserveRefund("pt1", 600)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), before)
+	assert.NoError(t, payment.MarkRefunded(600, now))
+	refund := &settlemententity.PaymentTransaction{ID: "rf1", BookingID: "b1", Kind: settlemententity.PaymentKindRefund, AmountCents: 600, RefundedCents: 600, Status: settlemententity.PaymentRefunded, ReasonCode: settlemententity.RefundReasonOvercharge, RefundedAt: &now}
+	sr := &settlemententity.SettlementRecord{ID: "sr1", BookingID: "b1", DriverID: "d1", PassengerID: "p1", AmountCents: -600, PlatformRevenueCents: -60}
+	rr := &settlemententity.RevenueRecord{ID: "rr1", BookingID: "b1", DeltaCents: -60}
+	assert.NoError(t, repo.SaveRefund(payment, refund, sr, rr, nil))
+
+	got, err := repo.GetPaymentTransactionByBookingID("b1")
+	assert.NoError(t, err)
+	assert.Equal(t, "pt1", got.ID)
+	assert.Equal(t, int64(600), got.RefundedCents)
+	assert.Equal(t, settlemententity.PaymentRefunded, got.Status)
+
+	refunds, err := repo.ListRefundTransactions("b1")
+	assert.NoError(t, err)
+	assert.Len(t, refunds, 1)
+	assert.Equal(t, settlemententity.RefundReasonOvercharge, refunds[0].ReasonCode)
+
+	revenue, err := repo.ListRevenueRecords()
+	assert.NoError(t, err)
+	assert.Len(t, revenue, 1)
+	assert.Equal(t, int64(-60), revenue[0].DeltaCents)
+}
+
+func TestSaveDepositCapture(t *testing.T) {
+	db := newTestDBSettlement()
+	repo := NewSettlementRepository(db)
+	got, err := repo.GetDepositTransaction("deposit-r1")
+	assert.NoError(t, err)
+	assert.Nil(t, got)
+
+	now := time.Now()
+	deposit := &settlemententity.PaymentTransaction{ID: "dp1", BookingID: "deposit-r1", Kind: settlemententity.PaymentKindDeposit, AmountCents: 2000, Currency: "CNY", Status: settlemententity.PaymentAuthorized, AuthorizedAt: &now}
+	require.NoError(t, deposit.MarkCaptured(2000, now))
+	rr := &settlemententity.RevenueRecord{ID: "rr1", BookingID: "b1", DeltaCents: 2000, Currency: "CNY"}
+	entry, err := settlemententity.NewJournalEntry("je1", "b1", settlemententity.JournalDeposit,
+		settlemententity.Debit(settlemententity.AccountPaymentClearing, 2000),
+		settlemententity.Credit(settlemententity.AccountPlatformRevenue, 2000))
+	require.NoError(t, err)
+	entry.Currency = "CNY"
+	require.NoError(t, repo.SaveDepositCapture(deposit, rr, []*settlemententity.JournalEntry{entry}))
+
+	got, err = repo.GetDepositTransaction("deposit-r1")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, settlemententity.PaymentKindDeposit, got.Kind)
+	assert.Equal(t, settlemententity.PaymentCaptured, got.Status)
+	assert.Equal(t, int64(2000), got.CapturedCents)
+	assert.NotNil(t, got.CapturedAt)
+
+	// 押金流水不是订单的支付流水
+	payment, err := repo.GetPaymentTransactionByBookingID("deposit-r1")
+	assert.NoError(t, err)
+	assert.Nil(t, payment)
+
+	revenue, err := repo.ListRevenueRecords()
+	require.NoError(t, err)
+	require.Len(t, revenue, 1)
+	assert.Equal(t, int64(2000), revenue[0].DeltaCents)
+
+	entries, err := repo.ListJournalEntries("b1")
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, settlemententity.JournalDeposit, entries[0].Kind)
+	balances, err := repo.GetAccountBalances(settlemententity.AccountPlatformRevenue)
+	require.NoError(t, err)
+	require.Len(t, balances, 1)
+	assert.Equal(t, int64(2000), balances[0].BalanceCents())
+}
+
+func TestReserveRefund_CapsAndReleases(t *testing.T) {
+	db := newTestDBSettlement()
+	repo := NewSettlementRepository(db)
+	payment := &settlemententity.PaymentTransaction{ID: "pt1", BookingID: "b1", AmountCents: 1000, Status: settlemententity.PaymentAuthorized}
+	assert.NoError(t, payment.MarkCaptured(1000, time.Now()))
+	assert.NoError(t, repo.SavePaymentTransaction(payment))
+
+	_, err := repo.ReserveRefund("pt1", 600)
+	assert.NoError(t, err)
+	// 剩余可退 400，再预占 500 被拒绝
+	_, err = repo.ReserveRefund("pt1", 500)
+	assert.ErrorIs(t, err, settlement.ErrRefundExceedsCaptured)
+	before, err := repo.ReserveRefund("pt1", 400)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(600), before)
+
+	// 支付服务退款失败后释放预占，额度可再次使用
+	assert.NoError(t, repo.ReleaseRefund("pt1", 400))
+	got, _ := repo.GetPaymentTransactionByID("pt1")
+	assert.Equal(t, int64(600), got.RefundedCents)
+	_, err = repo.ReserveRefund("pt1", 400)
+	assert.NoError(t, err)
+
+	// 未扣款的支付流水不能预占
+	authorized := &settlemententity.PaymentTransaction{ID: "pt2", BookingID: "b2", AmountCents: 1000, Status: settlemententity.PaymentAuthorized}
+	assert.NoError(t, repo.SavePaymentTransaction(authorized))
+	_, err = repo.ReserveRefund("pt2", 100)
+	assert.ErrorIs(t, err, settlement.ErrRefundExceedsCaptured)
+}
+
 func TestSaveAndGetSettlementRecord(t *testing.T) {
 	db := newTestDBSettlement()
 	repo := NewSettlementRepository(db)
@@ -46,6 +181,32 @@ func TestSaveAndGetSettlementRecord(t *testing.T) {
 	assert.Equal(t, int64(2000), got.AmountCents)
 }
 
+func TestSettlementRecord_FareItemsRoundTrip(t *testing.T) {
+	db := newTestDBSettlement()
+	repo := NewSettlementRepository(db)
+	items := []settlemententity.FareLineItem{
+		{Kind: settlemententity.FareDistance, Description: "10 km x 2.00", AmountCents: 2000, DriverCents: 1800, PlatformCents: 200},
+		{Kind: settlemententity.FareTax, Description: "VAT 6%", AmountCents: 120, TaxCents: 120},
+	}
+	saga, _ := settlemententity.NewSettlementSaga("s1", "b1", "d1", "p1", 2120, 200)
+	saga.TaxCents = 120
+	saga.FareItems = items
+	require.NoError(t, repo.SaveSettlementSaga(saga))
+	gotSaga, err := repo.GetSettlementSagaByBookingID("b1")
+	require.NoError(t, err)
+	assert.Equal(t, items, gotSaga.FareItems)
+	assert.Equal(t, int64(120), gotSaga.TaxCents)
+
+	sr := &settlemententity.SettlementRecord{ID: "sr1", BookingID: "b1", DriverID: "d1", PassengerID: "p1", AmountCents: 2120, PlatformRevenueCents: 200, TaxCents: 120, FareItems: items}
+	require.NoError(t, repo.SaveSettlementRecord(sr))
+	// 重复保存不会重复写入明细
+	require.NoError(t, repo.SaveSettlementRecord(sr))
+	got, err := repo.GetSettlementRecordByID("sr1")
+	require.NoError(t, err)
+	assert.Equal(t, items, got.FareItems)
+	assert.Equal(t, int64(120), got.TaxCents)
+}
+
 func TestSaveRevenueRecord(t *testing.T) {
 	db := newTestDBSettlement()
 	repo := NewSettlementRepository(db)
@@ -53,3 +214,152 @@ func TestSaveRevenueRecord(t *testing.T) {
 	err := repo.SaveRevenueRecord(rr)
 	assert.NoError(t, err)
 }
+
+func TestSettlementSaga_SaveAndGetByBookingID(t *testing.T) {
+	db := newTestDBSettlement()
+	repo := NewSettlementRepository(db)
+	got, err := repo.GetSettlementSagaByBookingID("b1")
+	assert.NoError(t, err)
+	assert.Nil(t, got)
+
+	saga, _ := settlemententity.NewSettlementSaga("s1", "b1", "d1", "p1", 1000, 100)
+	saga.PaymentMethod = "tok_1"
+	assert.NoError(t, repo.SaveSettlementSaga(saga))
+	_ = saga.MarkCharged()
+	assert.NoError(t, repo.SaveSettlementSaga(saga))
+
+	got, err = repo.GetSettlementSagaByBookingID("b1")
+	assert.NoError(t, err)
+	assert.Equal(t, settlemententity.SagaCharged, got.Status)
+	assert.Equal(t, int64(1000), got.AmountCents)
+	assert.Equal(t, "tok_1", got.PaymentMethod)
+}
+
+func TestSettlementSaga_SaveRecordsWithSaga(t *testing.T) {
+	db := newTestDBSettlement()
+	repo := NewSettlementRepository(db)
+	saga, _ := settlemententity.NewSettlementSaga("s1", "b1", "d1", "p1", 1000, 100)
+	_ = saga.MarkCharged()
+	_ = saga.MarkRecordsSaved()
+	ptx := &settlemententity.PaymentTransaction{ID: "pt1", BookingID: "b1", AmountCents: 1000, Status: "success"}
+	sr := &settlemententity.SettlementRecord{ID: "sr1", BookingID: "b1", DriverID: "d1", PassengerID: "p1", AmountCents: 1000, PlatformRevenueCents: 100}
+	rr := &settlemententity.RevenueRecord{ID: "rr1", BookingID: "b1", DeltaCents: 100}
+	entry, _ := settlemententity.NewJournalEntry("j1", "b1", settlemententity.JournalSettlement,
+		settlemententity.Debit(settlemententity.PassengerReceivableAccount("p1"), 1000),
+		settlemententity.Credit(settlemententity.DriverPayableAccount("d1"), 1000))
+	assert.NoError(t, repo.SaveRecordsWithSaga(saga, ptx, sr, rr, []*settlemententity.JournalEntry{entry}))
+
+	got, _ := repo.GetSettlementSagaByBookingID("b1")
+	assert.Equal(t, settlemententity.SagaRecordsSaved, got.Status)
+	_, err := repo.GetSettlementRecordByID("sr1")
+	assert.NoError(t, err)
+	entries, err := repo.ListJournalEntries("b1")
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+	assert.Len(t, entries[0].Lines, 2)
+}
+
+func TestLedger_BalancesAndInvariant(t *testing.T) {
+	db := newTestDBSettlement()
+	repo := NewSettlementRepository(db)
+	post := func(id, bookingID, driverID string, amount, fee int64) *settlemententity.JournalEntry {
+		e, err := settlemententity.NewJournalEntry(id, bookingID, settlemententity.JournalSettlement,
+			settlemententity.Debit(settlemententity.AccountPaymentClearing, amount),
+			settlemententity.Credit(settlemententity.DriverPayableAccount(driverID), amount-fee),
+			settlemententity.Credit(settlemententity.AccountPlatformRevenue, fee))
+		assert.NoError(t, err)
+		e.Currency = "CNY"
+		return e
+	}
+	ptx := func(id, bookingID string) *settlemententity.PaymentTransaction {
+		return &settlemententity.PaymentTransaction{ID: id, BookingID: bookingID, Status: settlemententity.PaymentCaptured}
+	}
+	assert.NoError(t, repo.SaveAllInTransaction(ptx("pt1", "b1"),
+		&settlemententity.SettlementRecord{ID: "sr1", BookingID: "b1", DriverID: "d1", PassengerID: "p1"},
+		&settlemententity.RevenueRecord{ID: "rr1", BookingID: "b1"},
+		[]*settlemententity.JournalEntry{post("j1", "b1", "d1", 1000, 100)}))
+	assert.NoError(t, repo.SaveAllInTransaction(ptx("pt2", "b2"),
+		&settlemententity.SettlementRecord{ID: "sr2", BookingID: "b2", DriverID: "d2", PassengerID: "p1"},
+		&settlemententity.RevenueRecord{ID: "rr2", BookingID: "b2"},
+		[]*settlemententity.JournalEntry{post("j2", "b2", "d2", 500, 50)}))
+
+	bal, err := repo.GetAccountBalances(settlemententity.AccountPaymentClearing)
+	assert.NoError(t, err)
+	require.Len(t, bal, 1)
+	assert.Equal(t, int64(1500), bal[0].BalanceCents())
+
+	drivers, err := repo.ListAccountBalances(settlemententity.AccountDriverPayable)
+	assert.NoError(t, err)
+	assert.Len(t, drivers, 2)
+	assert.Equal(t, settlemententity.DriverPayableAccount("d1"), drivers[0].Account)
+	assert.Equal(t, int64(900), drivers[0].BalanceCents())
+
+	all, err := repo.ListAccountBalances("")
+	assert.NoError(t, err)
+	assert.Len(t, all, 4)
+
+	debits, credits, err := repo.LedgerTotals()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1500), debits)
+	assert.Equal(t, debits, credits)
+	unbalanced, err := repo.ListUnbalancedJournalEntries()
+	assert.NoError(t, err)
+	assert.Empty(t, unbalanced)
+
+	// 绕过实体校验直接写入不平的分录行
+	db.Create(&JournalLine{EntryID: "j2", Account: settlemententity.AccountPlatformRevenue, CreditCents: 1, CreatedAt: time.Now()})
+	unbalanced, err = repo.ListUnbalancedJournalEntries()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"j2"}, unbalanced)
+}
+
+func TestSettlementSaga_ListStuck(t *testing.T) {
+	db := newTestDBSettlement()
+	repo := NewSettlementRepository(db)
+	pending, _ := settlemententity.NewSettlementSaga("s1", "b1", "d1", "p1", 1000, 100)
+	done, _ := settlemententity.NewSettlementSaga("s2", "b2", "d1", "p1", 1000, 100)
+	done.Status = settlemententity.SagaCompleted
+	assert.NoError(t, repo.SaveSettlementSaga(pending))
+	assert.NoError(t, repo.SaveSettlementSaga(done))
+
+	lst, err := repo.ListStuckSettlementSagas(time.Now().Add(time.Minute), 10)
+	assert.NoError(t, err)
+	assert.Len(t, lst, 1)
+	assert.Equal(t, "b1", lst[0].BookingID)
+
+	lst, err = repo.ListStuckSettlementSagas(time.Now().Add(-time.Minute), 10)
+	assert.NoError(t, err)
+	assert.Empty(t, lst)
+}
+
+func TestLedger_BalancesPerCurrency(t *testing.T) {
+	db := newTestDBSettlement()
+	repo := NewSettlementRepository(db)
+	for i, c := range []string{"CNY", "USD", "CNY"} {
+		e, err := settlemententity.NewJournalEntry(fmt.Sprintf("j%d", i), fmt.Sprintf("b%d", i), settlemententity.JournalPayment,
+			settlemententity.Debit(settlemententity.AccountPaymentClearing, 1000),
+			settlemententity.Credit(settlemententity.PassengerReceivableAccount("p1"), 1000))
+		require.NoError(t, err)
+		e.Currency = c
+		require.NoError(t, repo.SaveAllInTransaction(
+			&settlemententity.PaymentTransaction{ID: fmt.Sprintf("pt%d", i), BookingID: e.BookingID, Currency: c, Status: settlemententity.PaymentCaptured},
+			&settlemententity.SettlementRecord{ID: fmt.Sprintf("sr%d", i), BookingID: e.BookingID, DriverID: "d1", PassengerID: "p1", Currency: c},
+			&settlemententity.RevenueRecord{ID: fmt.Sprintf("rr%d", i), BookingID: e.BookingID, Currency: c},
+			[]*settlemententity.JournalEntry{e}))
+	}
+
+	bal, err := repo.GetAccountBalances(settlemententity.AccountPaymentClearing)
+	require.NoError(t, err)
+	require.Len(t, bal, 2)
+	assert.Equal(t, "CNY", bal[0].Currency)
+	assert.Equal(t, int64(2000), bal[0].BalanceCents())
+	assert.Equal(t, "USD", bal[1].Currency)
+	assert.Equal(t, int64(1000), bal[1].BalanceCents())
+
+	rrs, err := repo.ListRevenueRecords()
+	require.NoError(t, err)
+	require.Len(t, rrs, 3)
+	ptx, err := repo.GetPaymentTransactionByBookingID("b1")
+	require.NoError(t, err)
+	assert.Equal(t, "USD", ptx.Currency)
+}
@@ -23,9 +23,13 @@ func (r *PassengerRepository) Save(p *userentity.Passenger) error {
 	if p == nil || p.ID == "" {
 		return errors.New("invalid passenger")
 	}
-	m := &Passenger{ID: p.ID, Name: p.Name}
+	m := &Passenger{ID: p.ID, Name: p.Name, CorporateAccount: p.CorporateAccount,
+		ReputationScore: p.ReputationScore, RatingCount: p.RatingCount, NoShowCount: p.NoShowCount,
+		Phone: p.Phone, Email: p.Email, PreferredLanguage: p.PreferredLanguage, DefaultPaymentMethodID: p.DefaultPaymentMethodID, CreatedAt: p.CreatedAt}
 	now := time.Now()
-	m.CreatedAt = now
+	if m.CreatedAt.IsZero() {
+		m.CreatedAt = now
+	}
 	m.UpdatedAt = now
 	return r.db.Save(m).Error
 }
@@ -35,7 +39,10 @@ func (r *PassengerRepository) GetByID(id string) (*userentity.Passenger, error)
 	if err := r.db.First(&m, "id = ?", id).Error; err != nil {
 		return nil, err
 	}
-	return &userentity.Passenger{ID: m.ID, Name: m.Name, CreatedAt: m.CreatedAt, UpdatedAt: m.UpdatedAt}, nil
+	return &userentity.Passenger{ID: m.ID, Name: m.Name, CorporateAccount: m.CorporateAccount,
+		ReputationScore: m.ReputationScore, RatingCount: m.RatingCount, NoShowCount: m.NoShowCount,
+		Phone: m.Phone, Email: m.Email, PreferredLanguage: m.PreferredLanguage, DefaultPaymentMethodID: m.DefaultPaymentMethodID,
+		CreatedAt: m.CreatedAt, UpdatedAt: m.UpdatedAt}, nil
 }
 
 // Driver
@@ -43,9 +50,11 @@ func (r *DriverRepository) Save(d *userentity.Driver) error {
 	if d == nil || d.ID == "" {
 		return errors.New("invalid driver")
 	}
-	m := &Driver{ID: d.ID, Name: d.Name, Rating: d.Rating}
+	m := &Driver{ID: d.ID, Name: d.Name, Rating: d.Rating, RatingCount: d.RatingCount, Status: d.Status, StatusReason: d.StatusReason, CreatedAt: d.CreatedAt}
 	now := time.Now()
-	m.CreatedAt = now
+	if m.CreatedAt.IsZero() {
+		m.CreatedAt = now
+	}
 	m.UpdatedAt = now
 	return r.db.Save(m).Error
 }
@@ -55,5 +64,57 @@ func (r *DriverRepository) GetByID(id string) (*userentity.Driver, error) {
 	if err := r.db.First(&m, "id = ?", id).Error; err != nil {
 		return nil, err
 	}
-	return &userentity.Driver{ID: m.ID, Name: m.Name, Rating: m.Rating, CreatedAt: m.CreatedAt, UpdatedAt: m.UpdatedAt}, nil
+	return &userentity.Driver{ID: m.ID, Name: m.Name, Rating: m.Rating, RatingCount: m.RatingCount, Status: m.Status, StatusReason: m.StatusReason,
+		CreatedAt: m.CreatedAt, UpdatedAt: m.UpdatedAt}, nil
+}
+
+// TripRating
+type RatingRepository struct{ db *gorm.DB }
+
+func NewRatingRepository(db *gorm.DB) user.RatingRepository { return &RatingRepository{db: db} }
+
+func (r *RatingRepository) SaveRating(rt *userentity.TripRating) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var n int64
+		if err := tx.Model(&TripRating{}).Where("booking_id = ? AND target = ?", rt.BookingID, rt.Target).Count(&n).Error; err != nil {
+			return err
+		}
+		if n > 0 {
+			return user.ErrAlreadyRated
+		}
+		return tx.Create(&TripRating{
+			ID: rt.ID, BookingID: rt.BookingID, Target: rt.Target, RaterID: rt.RaterID, RateeID: rt.RateeID,
+			Score: rt.Score, Comment: rt.Comment, NoShow: rt.NoShow, CreatedAt: rt.CreatedAt,
+		}).Error
+	})
+}
+
+func (r *RatingRepository) GetRating(bookingID, target string) (*userentity.TripRating, error) {
+	var ms []TripRating
+	if err := r.db.Where("booking_id = ? AND target = ?", bookingID, target).Limit(1).Find(&ms).Error; err != nil {
+		return nil, err
+	}
+	if len(ms) == 0 {
+		return nil, nil
+	}
+	return toTripRatingEntity(&ms[0]), nil
+}
+
+func (r *RatingRepository) ListRatings(rateeID, target string) ([]*userentity.TripRating, error) {
+	var ms []TripRating
+	if err := r.db.Where("ratee_id = ? AND target = ?", rateeID, target).Order("created_at, id").Find(&ms).Error; err != nil {
+		return nil, err
+	}
+	res := make([]*userentity.TripRating, 0, len(ms))
+	for i := range ms {
+		res = append(res, toTripRatingEntity(&ms[i]))
+	}
+	return res, nil
+}
+
+func toTripRatingEntity(m *TripRating) *userentity.TripRating {
+	return &userentity.TripRating{
+		ID: m.ID, BookingID: m.BookingID, Target: m.Target, RaterID: m.RaterID, RateeID: m.RateeID,
+		Score: m.Score, Comment: m.Comment, NoShow: m.NoShow, CreatedAt: m.CreatedAt,
+	}
 }
@@ -1,6 +1,7 @@
 package mysqlrepo
 
 import (
+	user "github.com/gavin/airport-pickup/internal/domain/user"
 	userentity "github.com/gavin/airport-pickup/internal/domain/user/entity"
 	"github.com/stretchr/testify/assert"
 	"gorm.io/driver/sqlite"
@@ -11,7 +12,7 @@ import (
 
 func newTestDBUser() *gorm.DB {
 	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
-	db.AutoMigrate(&Passenger{}, &Driver{})
+	db.AutoMigrate(&Passenger{}, &Driver{}, &TripRating{})
 	return db
 }
 
@@ -60,3 +61,102 @@ func TestDriverRepository_Save_Invalid(t *testing.T) {
 	err = repo.Save(&userentity.Driver{})
 	assert.Error(t, err)
 }
+
+func TestDriverRepository_RatingCountRoundTrip(t *testing.T) {
+	db := newTestDBUser()
+	repo := NewDriverRepository(db)
+	created := time.Now().Add(-time.Hour).Truncate(time.Second)
+	assert.NoError(t, repo.Save(&userentity.Driver{ID: "d1", Name: "Bob", Rating: 4.62, RatingCount: 12, CreatedAt: created}))
+	got, err := repo.GetByID("d1")
+	assert.NoError(t, err)
+	assert.Equal(t, 4.62, got.Rating)
+	assert.Equal(t, 12, got.RatingCount)
+	// 更新评分不改变创建时间
+	assert.True(t, got.CreatedAt.Equal(created))
+}
+
+func TestPassengerRepository_ReputationRoundTrip(t *testing.T) {
+	db := newTestDBUser()
+	repo := NewPassengerRepository(db)
+	created := time.Now().Add(-time.Hour).Truncate(time.Second)
+	assert.NoError(t, repo.Save(&userentity.Passenger{ID: "p1", Name: "Alice", ReputationScore: 3.87, RatingCount: 6, NoShowCount: 2, CreatedAt: created}))
+	got, err := repo.GetByID("p1")
+	assert.NoError(t, err)
+	assert.Equal(t, 3.87, got.ReputationScore)
+	assert.Equal(t, 6, got.RatingCount)
+	assert.Equal(t, 2, got.NoShowCount)
+	assert.True(t, got.CreatedAt.Equal(created))
+}
+
+func TestPassengerRepository_ProfileRoundTrip(t *testing.T) {
+	db := newTestDBUser()
+	repo := NewPassengerRepository(db)
+	assert.NoError(t, repo.Save(&userentity.Passenger{ID: "p1", Name: "Alice", Phone: "+8613800138000", Email: "alice@example.com",
+		PreferredLanguage: "zh-CN", DefaultPaymentMethodID: "pm1"}))
+	got, err := repo.GetByID("p1")
+	assert.NoError(t, err)
+	assert.Equal(t, "+8613800138000", got.Phone)
+	assert.Equal(t, "alice@example.com", got.Email)
+	assert.Equal(t, "zh-CN", got.PreferredLanguage)
+	assert.Equal(t, "pm1", got.DefaultPaymentMethodID)
+}
+
+func TestDriverRepository_StatusRoundTrip(t *testing.T) {
+	db := newTestDBUser()
+	repo := NewDriverRepository(db)
+	assert.NoError(t, repo.Save(&userentity.Driver{ID: "d1", Name: "Bob", Status: userentity.DriverStatusSuspended, StatusReason: userentity.SuspendReasonDocumentsExpiring}))
+	got, err := repo.GetByID("d1")
+	assert.NoError(t, err)
+	assert.Equal(t, userentity.DriverStatusSuspended, got.Status)
+	assert.Equal(t, userentity.SuspendReasonDocumentsExpiring, got.StatusReason)
+
+	// 入驻流程上线前的司机没有状态，按已激活处理
+	assert.NoError(t, db.Exec("INSERT INTO drivers (id, name, rating, created_at, updated_at) VALUES ('d0', 'Old', 4.5, ?, ?)", time.Now(), time.Now()).Error)
+	got, err = repo.GetByID("d0")
+	assert.NoError(t, err)
+	assert.True(t, got.IsActive())
+}
+
+func TestRatingRepository_SaveAndList(t *testing.T) {
+	db := newTestDBUser()
+	repo := NewRatingRepository(db)
+	base := time.Date(2025, 11, 8, 10, 0, 0, 0, time.UTC)
+	ratings := []*userentity.TripRating{
+		{ID: "rt2", BookingID: "b2", Target: userentity.RatingTargetDriver, RaterID: "p2", RateeID: "d1", Score: 3, CreatedAt: base.Add(time.Hour)},
+		{ID: "rt1", BookingID: "b1", Target: userentity.RatingTargetDriver, RaterID: "p1", RateeID: "d1", Score: 5, Comment: "准时", CreatedAt: base},
+		{ID: "rt3", BookingID: "b3", Target: userentity.RatingTargetDriver, RaterID: "p1", RateeID: "d2", Score: 4, CreatedAt: base},
+	}
+	for _, r := range ratings {
+		assert.NoError(t, repo.SaveRating(r))
+	}
+	// 同一订单同一方向只能评价一次
+	dup := *ratings[1]
+	dup.ID = "rt4"
+	assert.ErrorIs(t, repo.SaveRating(&dup), user.ErrAlreadyRated)
+
+	list, err := repo.ListRatings("d1", userentity.RatingTargetDriver)
+	assert.NoError(t, err)
+	if assert.Len(t, list, 2) {
+		assert.Equal(t, "rt1", list[0].ID)
+		assert.Equal(t, "准时", list[0].Comment)
+		assert.Equal(t, "rt2", list[1].ID)
+	}
+
+	got, err := repo.GetRating("b1", userentity.RatingTargetDriver)
+	assert.NoError(t, err)
+	if assert.NotNil(t, got) {
+		assert.Equal(t, 5, got.Score)
+	}
+	got, err = repo.GetRating("b9", userentity.RatingTargetDriver)
+	assert.NoError(t, err)
+	assert.Nil(t, got)
+
+	// 同一订单的另一方向独立评价
+	assert.NoError(t, repo.SaveRating(&userentity.TripRating{ID: "rt5", BookingID: "b1", Target: userentity.RatingTargetPassenger,
+		RaterID: "d1", RateeID: "p1", Score: 1, NoShow: true, CreatedAt: base}))
+	got, err = repo.GetRating("b1", userentity.RatingTargetPassenger)
+	assert.NoError(t, err)
+	if assert.NotNil(t, got) {
+		assert.True(t, got.NoShow)
+	}
+}
@@ -0,0 +1,71 @@
+package mysqlrepo
+
+import (
+	user "github.com/gavin/airport-pickup/internal/domain/user"
+	userentity "github.com/gavin/airport-pickup/internal/domain/user/entity"
+	"gorm.io/gorm"
+)
+
+type VehicleRepository struct{ db *gorm.DB }
+
+func NewVehicleRepository(db *gorm.DB) user.VehicleRepository { return &VehicleRepository{db: db} }
+
+func (r *VehicleRepository) SaveVehicle(v *userentity.Vehicle) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// 驳回的登记不占用车牌，司机可以更正后重新登记
+		if v.Status != userentity.VehicleRejected {
+			var n int64
+			if err := tx.Model(&Vehicle{}).Where("plate = ? AND id <> ? AND status <> ?", v.Plate, v.ID, userentity.VehicleRejected).
+				Count(&n).Error; err != nil {
+				return err
+			}
+			if n > 0 {
+				return user.ErrPlateRegistered
+			}
+		}
+		return tx.Save(&Vehicle{
+			ID: v.ID, DriverID: v.DriverID, Plate: v.Plate, Make: v.Make, Model: v.Model, Colour: v.Colour, Class: v.Class,
+			Seats: v.Seats, LuggageCapacity: v.LuggageCapacity, Status: v.Status,
+			VerifiedBy: v.VerifiedBy, ReviewNote: v.ReviewNote, ReviewedAt: v.ReviewedAt, CreatedAt: v.CreatedAt,
+		}).Error
+	})
+}
+
+func (r *VehicleRepository) GetVehicle(id string) (*userentity.Vehicle, error) {
+	var ms []Vehicle
+	if err := r.db.Where("id = ?", id).Limit(1).Find(&ms).Error; err != nil {
+		return nil, err
+	}
+	if len(ms) == 0 {
+		return nil, nil
+	}
+	return toVehicleEntity(&ms[0]), nil
+}
+
+func (r *VehicleRepository) ListVehicles(driverID string) ([]*userentity.Vehicle, error) {
+	return r.list(r.db.Where("driver_id = ?", driverID))
+}
+
+func (r *VehicleRepository) ListPendingVerification() ([]*userentity.Vehicle, error) {
+	return r.list(r.db.Where("status = ?", userentity.VehiclePendingVerification))
+}
+
+func (r *VehicleRepository) list(q *gorm.DB) ([]*userentity.Vehicle, error) {
+	var ms []Vehicle
+	if err := q.Order("created_at, id").Find(&ms).Error; err != nil {
+		return nil, err
+	}
+	res := make([]*userentity.Vehicle, 0, len(ms))
+	for i := range ms {
+		res = append(res, toVehicleEntity(&ms[i]))
+	}
+	return res, nil
+}
+
+func toVehicleEntity(m *Vehicle) *userentity.Vehicle {
+	return &userentity.Vehicle{
+		ID: m.ID, DriverID: m.DriverID, Plate: m.Plate, Make: m.Make, Model: m.Model, Colour: m.Colour, Class: m.Class,
+		Seats: m.Seats, LuggageCapacity: m.LuggageCapacity, Status: m.Status,
+		VerifiedBy: m.VerifiedBy, ReviewNote: m.ReviewNote, ReviewedAt: m.ReviewedAt, CreatedAt: m.CreatedAt,
+	}
+}
@@ -0,0 +1,77 @@
+package mysqlrepo
+
+import (
+	"testing"
+	"time"
+
+	user "github.com/gavin/airport-pickup/internal/domain/user"
+	userentity "github.com/gavin/airport-pickup/internal/domain/user/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func newTestDBVehicles(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&Vehicle{}))
+	return db
+}
+
+func TestVehicleRepository_SaveAndQuery(t *testing.T) {
+	repo := NewVehicleRepository(newTestDBVehicles(t))
+	base := time.Date(2025, 11, 8, 10, 0, 0, 0, time.UTC)
+	reviewed := base.Add(time.Hour)
+	vehicles := []*userentity.Vehicle{
+		{ID: "v2", DriverID: "d1", Plate: "SFO123", Make: "Toyota", Model: "Sienna", Colour: "silver", Class: "van", Seats: 7, LuggageCapacity: 5,
+			Status: userentity.VehicleVerified, VerifiedBy: "ops", ReviewedAt: &reviewed, CreatedAt: base},
+		{ID: "v1", DriverID: "d1", Plate: "SFO456", Make: "Toyota", Model: "Camry", Colour: "white", Class: "sedan", Seats: 4, LuggageCapacity: 3,
+			Status: userentity.VehiclePendingVerification, CreatedAt: base.Add(-time.Hour)},
+		{ID: "v3", DriverID: "d2", Plate: "SFO789", Make: "Tesla", Model: "Model Y", Colour: "black", Class: "suv", Seats: 4, LuggageCapacity: 4,
+			Status: userentity.VehiclePendingVerification, CreatedAt: base.Add(time.Minute)},
+	}
+	for _, v := range vehicles {
+		require.NoError(t, repo.SaveVehicle(v))
+	}
+
+	got, err := repo.GetVehicle("v2")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, "Sienna", got.Model)
+	assert.Equal(t, 7, got.Seats)
+	assert.True(t, got.ReviewedAt.Equal(reviewed))
+	missing, err := repo.GetVehicle("nope")
+	assert.NoError(t, err)
+	assert.Nil(t, missing)
+
+	list, err := repo.ListVehicles("d1")
+	require.NoError(t, err)
+	if assert.Len(t, list, 2) {
+		assert.Equal(t, "v1", list[0].ID)
+	}
+	pending, err := repo.ListPendingVerification()
+	require.NoError(t, err)
+	if assert.Len(t, pending, 2) {
+		assert.Equal(t, "v1", pending[0].ID)
+		assert.Equal(t, "v3", pending[1].ID)
+	}
+}
+
+func TestVehicleRepository_PlateUniqueness(t *testing.T) {
+	repo := NewVehicleRepository(newTestDBVehicles(t))
+	v := &userentity.Vehicle{ID: "v1", DriverID: "d1", Plate: "SFO123", Make: "Toyota", Model: "Camry", Colour: "white", Class: "sedan",
+		Seats: 4, Status: userentity.VehiclePendingVerification, CreatedAt: time.Now()}
+	require.NoError(t, repo.SaveVehicle(v))
+	// 重复保存同一车辆不算冲突
+	require.NoError(t, repo.SaveVehicle(v))
+
+	dup := *v
+	dup.ID, dup.DriverID = "v2", "d2"
+	assert.ErrorIs(t, repo.SaveVehicle(&dup), user.ErrPlateRegistered)
+
+	// 驳回后车牌释放
+	v.Status = userentity.VehicleRejected
+	require.NoError(t, repo.SaveVehicle(v))
+	assert.NoError(t, repo.SaveVehicle(&dup))
+}
@@ -0,0 +1,31 @@
+// Package schemas 内嵌事件 schema 注册表（events/<Event>/v<N>.avsc）及其锁文件。
+//
+// 演进规则：已发布的版本文件不可修改，变更通过新增版本完成，
+// 且相邻版本之间必须双向兼容（新增字段带默认值、不删除无默认值的字段、只做合法的类型提升）。
+// 修改后运行 go run ./cmd/schemacheck 校验，并以 -write-lock 更新 events.lock。
+package schemas
+
+import (
+	"embed"
+	"io/fs"
+
+	"github.com/gavin/airport-pickup/pkg/avro"
+)
+
+//go:embed events
+var files embed.FS
+
+//go:embed events.lock
+var eventsLock []byte
+
+// Events 返回事件 schema 目录。
+func Events() fs.FS {
+	sub, _ := fs.Sub(files, "events")
+	return sub
+}
+
+// EventsLock 返回已发布版本的锁文件内容。
+func EventsLock() []byte { return eventsLock }
+
+// LoadEvents 加载内嵌的事件 schema 注册表。
+func LoadEvents() (*avro.Registry, error) { return avro.LoadRegistry(Events()) }
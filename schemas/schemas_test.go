@@ -0,0 +1,17 @@
+package schemas
+
+import "testing"
+
+// TestEventSchemasCompatible 在 CI 中阻止破坏现有消费者的 schema 变更。
+func TestEventSchemasCompatible(t *testing.T) {
+	reg, err := LoadEvents()
+	if err != nil {
+		t.Fatalf("load registry: %v", err)
+	}
+	if err := reg.Check(); err != nil {
+		t.Errorf("incompatible schema change: %v", err)
+	}
+	if err := reg.VerifyLock(EventsLock()); err != nil {
+		t.Errorf("lock check failed: %v", err)
+	}
+}